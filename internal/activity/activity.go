@@ -0,0 +1,76 @@
+// Package activity tracks user input and running builds so background polling
+// can slow down on dashboards left open and idle.
+package activity
+
+import "time"
+
+const (
+	// DefaultIdleAfter is how long the dashboard waits without input or running builds before slowing down.
+	DefaultIdleAfter = 10 * time.Minute
+	// IdleSlowdown multiplies poll intervals while idle.
+	IdleSlowdown = 10
+	// CheckInterval is how often the app re-evaluates idleness.
+	CheckInterval = 30 * time.Second
+)
+
+// IdleChangedMsg is broadcast when the dashboard enters or leaves idle mode.
+type IdleChangedMsg struct {
+	Idle bool
+}
+
+// PausedChangedMsg is broadcast when background polling stops because the
+// dashboard cannot be shown (the terminal is too small), and when it resumes.
+type PausedChangedMsg struct {
+	Paused bool
+}
+
+// PollInterval returns base, stretched by IdleSlowdown while idle.
+func PollInterval(base time.Duration, idle bool) time.Duration {
+	if idle {
+		return base * IdleSlowdown
+	}
+	return base
+}
+
+// Tracker decides when the dashboard is idle. A zero or negative threshold disables idling.
+type Tracker struct {
+	threshold  time.Duration
+	lastActive time.Time
+	idle       bool
+}
+
+// NewTracker creates a tracker that counts now as the last activity.
+func NewTracker(threshold time.Duration, now time.Time) Tracker {
+	return Tracker{threshold: threshold, lastActive: now}
+}
+
+// Idle reports whether the tracker is currently idle.
+func (t *Tracker) Idle() bool {
+	return t.idle
+}
+
+// Touch records user input and reports whether this ended an idle period.
+func (t *Tracker) Touch(now time.Time) bool {
+	t.lastActive = now
+	if !t.idle {
+		return false
+	}
+	t.idle = false
+	return true
+}
+
+// Check re-evaluates idleness; busy (e.g. builds running) counts as activity.
+// It reports whether the tracker just became idle.
+func (t *Tracker) Check(now time.Time, busy bool) bool {
+	if busy {
+		t.lastActive = now
+	}
+	if t.idle || t.threshold <= 0 {
+		return false
+	}
+	if now.Sub(t.lastActive) < t.threshold {
+		return false
+	}
+	t.idle = true
+	return true
+}
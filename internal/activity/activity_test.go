@@ -0,0 +1,95 @@
+package activity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	start := time.Date(2024, 3, 1, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		steps     func(tr *Tracker) bool
+		wantIdle  bool
+	}{
+		{
+			name:      "becomes idle after threshold",
+			threshold: 10 * time.Minute,
+			steps: func(tr *Tracker) bool {
+				return tr.Check(start.Add(10*time.Minute), false)
+			},
+			wantIdle: true,
+		},
+		{
+			name:      "stays active before threshold",
+			threshold: 10 * time.Minute,
+			steps: func(tr *Tracker) bool {
+				return !tr.Check(start.Add(9*time.Minute), false)
+			},
+			wantIdle: false,
+		},
+		{
+			name:      "running builds keep it active",
+			threshold: 10 * time.Minute,
+			steps: func(tr *Tracker) bool {
+				return !tr.Check(start.Add(30*time.Minute), true) && !tr.Check(start.Add(35*time.Minute), false)
+			},
+			wantIdle: false,
+		},
+		{
+			name:      "keypress resumes",
+			threshold: 10 * time.Minute,
+			steps: func(tr *Tracker) bool {
+				return tr.Check(start.Add(time.Hour), false) && tr.Touch(start.Add(2*time.Hour))
+			},
+			wantIdle: false,
+		},
+		{
+			name:      "keypress while active is not a resume",
+			threshold: 10 * time.Minute,
+			steps: func(tr *Tracker) bool {
+				return !tr.Touch(start.Add(time.Minute))
+			},
+			wantIdle: false,
+		},
+		{
+			name:      "idle reported once",
+			threshold: 10 * time.Minute,
+			steps: func(tr *Tracker) bool {
+				return tr.Check(start.Add(time.Hour), false) && !tr.Check(start.Add(2*time.Hour), false)
+			},
+			wantIdle: true,
+		},
+		{
+			name:      "disabled threshold never idles",
+			threshold: 0,
+			steps: func(tr *Tracker) bool {
+				return !tr.Check(start.Add(24*time.Hour), false)
+			},
+			wantIdle: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewTracker(tt.threshold, start)
+			if !tt.steps(&tr) {
+				t.Fatalf("unexpected transition result")
+			}
+			if got := tr.Idle(); got != tt.wantIdle {
+				t.Errorf("Idle() = %v, want %v", got, tt.wantIdle)
+			}
+		})
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	if got := PollInterval(3*time.Second, false); got != 3*time.Second {
+		t.Errorf("PollInterval(active) = %v, want 3s", got)
+	}
+	if got := PollInterval(3*time.Second, true); got != 30*time.Second {
+		t.Errorf("PollInterval(idle) = %v, want 30s", got)
+	}
+}
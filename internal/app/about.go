@@ -0,0 +1,48 @@
+package app
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/release"
+	"github.com/gorbach/jdash/internal/statusbar"
+)
+
+// updateCheckedMsg carries the releases newer than the running version.
+type updateCheckedMsg struct {
+	newer []release.Release
+	err   error
+}
+
+// checkForUpdatesCmd asks GitHub for newer releases, at most once a day.
+func checkForUpdatesCmd(current string) tea.Cmd {
+	return func() tea.Msg {
+		newer, err := release.NewChecker().Newer(context.Background(), current)
+		return updateCheckedMsg{newer: newer, err: err}
+	}
+}
+
+// handleUpdateChecked points out a newer release in the status bar. A failed
+// check stays quiet; the About overlay says why.
+func (m Model) handleUpdateChecked(msg updateCheckedMsg) (Model, tea.Cmd) {
+	m.newReleases, m.updateErr = msg.newer, msg.err
+	if len(msg.newer) == 0 {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.statusBar, cmd = m.statusBar.Update(statusbar.UpdateAvailableMsg{Version: msg.newer[0].Tag})
+	return m, cmd
+}
+
+func (m Model) openAbout() (Model, tea.Cmd) {
+	m.modal = m.modal.Clear()
+	modal := release.New(m.version, m.serverURL, m.newReleases, m.updateErr)
+	m.modal = m.modal.Set(modalAbout, modal)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		return m, sizeCmd
+	}
+	return m, nil
+}
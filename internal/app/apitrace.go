@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+// apiTraceSize is how many Jenkins requests the debug overlay remembers.
+const apiTraceSize = 100
+
+// apiTraceEntry records one finished Jenkins request.
+type apiTraceEntry struct {
+	at   time.Time
+	info jenkins.RequestInfo
+}
+
+func (e apiTraceEntry) String() string {
+	result := fmt.Sprintf("%d", e.info.Status)
+	if e.info.Err != nil {
+		result = "ERR"
+	}
+	line := fmt.Sprintf("%s  %s %s", e.at.Format("15:04:05.000"), result, e.info.Latency.Round(time.Millisecond))
+	if e.info.Size >= 0 {
+		line += " " + utils.FormatBytes(e.info.Size)
+	}
+	return fmt.Sprintf("%s %s %s", line, e.info.Method, e.info.Path)
+}
+
+// apiTrace is a bounded log of Jenkins requests for the debug overlay. The
+// client reports requests from its command goroutines, so unlike busTrace it
+// is shared by pointer and locked.
+type apiTrace struct {
+	mu       sync.Mutex
+	entries  []apiTraceEntry
+	inFlight int
+}
+
+func (t *apiTrace) OnRequestStart(jenkins.RequestInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight++
+}
+
+func (t *apiTrace) OnRequestEnd(info jenkins.RequestInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight--
+	t.entries = append(t.entries, apiTraceEntry{at: time.Now(), info: info})
+	if len(t.entries) > apiTraceSize {
+		t.entries = t.entries[len(t.entries)-apiTraceSize:]
+	}
+}
+
+// snapshot returns a copy of the recorded requests and the number still
+// waiting for a response.
+func (t *apiTrace) snapshot() ([]apiTraceEntry, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]apiTraceEntry(nil), t.entries...), t.inFlight
+}
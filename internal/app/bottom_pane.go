@@ -1,23 +1,36 @@
 package app
 
 import (
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/bookmarks"
+	"github.com/gorbach/jdash/internal/confirm"
 	"github.com/gorbach/jdash/internal/console"
 	"github.com/gorbach/jdash/internal/details"
+	"github.com/gorbach/jdash/internal/inflight"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/notes"
+	"github.com/gorbach/jdash/internal/queueitem"
+	"github.com/gorbach/jdash/internal/ui"
 )
 
 type bottomPane struct {
-	active  bottomView
-	details details.Model
-	console console.Model
+	active    bottomView
+	details   details.Model
+	console   console.Model
+	queueItem queueitem.Model
 }
 
-func newBottomPane(client jenkins.JenkinsClient) bottomPane {
+func newBottomPane(client jenkins.JenkinsClient, notesStore *notes.Store, bookmarkStore *bookmarks.Store, selectionDebounce time.Duration, policy confirm.Policy, actions *inflight.Tracker) bottomPane {
 	return bottomPane{
-		active:  bottomViewDetails,
-		details: details.New(client),
-		console: console.New(client),
+		active: bottomViewDetails,
+		details: details.New(client, notesStore).
+			WithSelectionDebounce(selectionDebounce).
+			WithConfirmPolicy(policy).
+			WithTracker(actions),
+		console:   console.New(client).WithBookmarks(bookmarkStore),
+		queueItem: queueitem.New(client).WithConfirmPolicy(policy).WithTracker(actions),
 	}
 }
 
@@ -36,10 +49,34 @@ func (b bottomPane) IsConsoleActive() bool {
 	return b.active == bottomViewConsole
 }
 
+// CapturesInput reports whether the visible view is collecting free text.
+func (b bottomPane) CapturesInput() bool {
+	switch b.active {
+	case bottomViewDetails:
+		return b.details.CapturesInput()
+	case bottomViewQueueItem:
+		return b.queueItem.CapturesInput()
+	}
+	return false
+}
+
+func (b bottomPane) TitleBar() ui.TitleBar {
+	switch b.active {
+	case bottomViewConsole:
+		return b.console.TitleBar()
+	case bottomViewQueueItem:
+		return b.queueItem.TitleBar()
+	default:
+		return b.details.TitleBar()
+	}
+}
+
 func (b bottomPane) View() string {
 	switch b.active {
 	case bottomViewConsole:
 		return b.console.View()
+	case bottomViewQueueItem:
+		return b.queueItem.View()
 	default:
 		return b.details.View()
 	}
@@ -49,11 +86,19 @@ func (b bottomPane) UpdateActive(msg tea.Msg) (bottomPane, tea.Cmd) {
 	switch b.active {
 	case bottomViewConsole:
 		return b.updateConsole(msg)
+	case bottomViewQueueItem:
+		return b.UpdateQueueItem(msg)
 	default:
 		return b.updateDetails(msg)
 	}
 }
 
+func (b bottomPane) UpdateQueueItem(msg tea.Msg) (bottomPane, tea.Cmd) {
+	var cmd tea.Cmd
+	b.queueItem, cmd = b.queueItem.Update(msg)
+	return b, cmd
+}
+
 func (b bottomPane) UpdateDetails(msg tea.Msg) (bottomPane, tea.Cmd) {
 	return b.updateDetails(msg)
 }
@@ -88,6 +133,11 @@ func (b bottomPane) Broadcast(msg tea.Msg) (bottomPane, []tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	b.queueItem, cmd = b.queueItem.Update(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
 	return b, cmds
 }
 
@@ -106,6 +156,11 @@ func (b bottomPane) Resize(width, height int) (bottomPane, []tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
+	b.queueItem, cmd = b.queueItem.Update(sizeMsg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
 	return b, cmds
 }
 
@@ -119,7 +174,24 @@ func (b bottomPane) ShowDetails() (bottomPane, tea.Cmd) {
 		return b, nil
 	}
 	var cmd tea.Cmd
-	b.console, cmd = b.console.Update(console.DeactivateMsg{})
+	if b.active == bottomViewConsole {
+		b.console, cmd = b.console.Update(console.DeactivateMsg{})
+	}
 	b.active = bottomViewDetails
 	return b, cmd
 }
+
+// ShowQueueItem switches to the view of a queued item, leaving the console
+// as ShowDetails does.
+func (b bottomPane) ShowQueueItem(item jenkins.QueueItem) (bottomPane, tea.Cmd) {
+	var cmds []tea.Cmd
+	if b.active == bottomViewConsole {
+		var cmd tea.Cmd
+		b.console, cmd = b.console.Update(console.DeactivateMsg{})
+		cmds = append(cmds, cmd)
+	}
+	var cmd tea.Cmd
+	b.queueItem, cmd = b.queueItem.Show(item)
+	b.active = bottomViewQueueItem
+	return b, tea.Batch(append(cmds, cmd)...)
+}
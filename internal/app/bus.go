@@ -0,0 +1,207 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/activity"
+	"github.com/gorbach/jdash/internal/console"
+	"github.com/gorbach/jdash/internal/details"
+	"github.com/gorbach/jdash/internal/jobs"
+	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/queueitem"
+	"github.com/gorbach/jdash/internal/statusbar"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+// topic is the set of panels a message is delivered to.
+type topic uint8
+
+const (
+	topicJobs topic = 1 << iota
+	topicQueue
+	topicBottom
+	topicStatus
+
+	topicAll = topicJobs | topicQueue | topicBottom | topicStatus
+)
+
+func (t topic) String() string {
+	if t == topicAll {
+		return "all"
+	}
+	var names []string
+	for _, named := range []struct {
+		topic topic
+		name  string
+	}{
+		{topicJobs, "jobs"},
+		{topicQueue, "queue"},
+		{topicBottom, "bottom"},
+		{topicStatus, "status"},
+	} {
+		if t&named.topic != 0 {
+			names = append(names, named.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, "|")
+}
+
+// messageTopics routes the message types only some panels react to. Anything
+// not listed, including each panel's own unexported messages, goes to every
+// panel, so a panel that starts handling a listed type must be added here.
+var messageTopics = map[reflect.Type]topic{
+	reflect.TypeFor[jobs.JobsFetchedMsg]():            topicJobs,
+	reflect.TypeFor[jobs.JobsErrorMsg]():              topicJobs,
+	reflect.TypeFor[jobs.JobsRenamedMsg]():            0,
+	reflect.TypeFor[jobs.BuildPermissionMsg]():        topicJobs,
+	reflect.TypeFor[jobs.JobSelectedMsg]():            topicBottom,
+	reflect.TypeFor[jobs.JobSelectionClearedMsg]():    topicBottom,
+	reflect.TypeFor[details.NotesUpdatedMsg]():        topicBottom,
+	reflect.TypeFor[details.ParameterSubmissionMsg](): topicBottom,
+	reflect.TypeFor[details.ParameterCancelledMsg]():  topicBottom,
+	reflect.TypeFor[details.RunActionMsg]():           topicBottom,
+	reflect.TypeFor[jobs.JobActivatedMsg]():           0,
+	reflect.TypeFor[jobs.JobActionRequestedMsg]():     0,
+	reflect.TypeFor[queue.SnapshotMsg]():              topicJobs | topicBottom,
+	reflect.TypeFor[queue.ItemSelectedMsg]():          0,
+	reflect.TypeFor[queueitem.ClosedMsg]():            0,
+	reflect.TypeFor[console.OpenRequestMsg]():         topicBottom,
+	reflect.TypeFor[console.DeactivateMsg]():          topicBottom,
+	reflect.TypeFor[statusbar.FeedbackMsg]():          topicStatus,
+	reflect.TypeFor[statusbar.RefreshStartedMsg]():    topicStatus,
+	reflect.TypeFor[statusbar.RefreshFinishedMsg]():   topicStatus,
+	reflect.TypeFor[activity.IdleChangedMsg]():        topicQueue | topicBottom | topicStatus,
+	reflect.TypeFor[activity.PausedChangedMsg]():      topicQueue | topicBottom,
+}
+
+// topicsFor returns the panels interested in msg.
+func topicsFor(msg tea.Msg) topic {
+	if t, ok := messageTopics[reflect.TypeOf(msg)]; ok {
+		return t
+	}
+	return topicAll
+}
+
+// busTraceSize is how many routed messages the debug overlay remembers.
+const busTraceSize = 100
+
+// busTraceEntry records where one message was delivered.
+type busTraceEntry struct {
+	at     time.Time
+	msg    string
+	topics topic
+}
+
+// busTrace is a bounded log of routed messages, kept only in debug mode.
+type busTrace struct {
+	entries []busTraceEntry
+}
+
+func (t busTrace) record(msg tea.Msg, topics topic) busTrace {
+	entries := append(t.entries, busTraceEntry{at: time.Now(), msg: fmt.Sprintf("%T", msg), topics: topics})
+	if len(entries) > busTraceSize {
+		// Copy so the trimmed slice does not share its array with older models.
+		entries = append([]busTraceEntry(nil), entries[len(entries)-busTraceSize:]...)
+	}
+	t.entries = entries
+	return t
+}
+
+// dispatch delivers msg to the panels subscribed to its topics, then runs the
+// app-level reactions to panel messages.
+func (m Model) dispatch(msg tea.Msg) (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+
+	topics := topicsFor(msg)
+	if utils.DebugEnabled() {
+		m.trace = m.trace.record(msg, topics)
+	}
+
+	if topics&topicJobs != 0 {
+		m.jobsPanel, cmd = m.jobsPanel.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	if topics&topicQueue != 0 {
+		m.queuePanel, cmd = m.queuePanel.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	if topics&topicBottom != 0 {
+		var bottomCmds []tea.Cmd
+		m.bottom, bottomCmds = m.bottom.Broadcast(msg)
+		cmds = append(cmds, bottomCmds...)
+	}
+
+	if topics&topicStatus != 0 {
+		m.statusBar, cmd = m.statusBar.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	m, cmd = m.handlePanelMsg(msg)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// debugOverlayView lists the most recent routed messages and Jenkins
+// requests, newest first.
+func (m Model) debugOverlayView() string {
+	rows := maxInt(m.height-8, 1)
+	width := clampInt(m.width-10, 20, 70)
+	if m.api != nil {
+		// Share the height between the two lists.
+		rows = maxInt((rows-3)/2, 1)
+	}
+
+	var b strings.Builder
+	b.WriteString("Message bus (newest first)\n\n")
+	entries := m.trace.entries
+	for i := len(entries) - 1; i >= 0 && len(entries)-i <= rows; i-- {
+		entry := entries[i]
+		line := fmt.Sprintf("%s  %s → %s", entry.at.Format("15:04:05.000"), entry.msg, entry.topics)
+		b.WriteString(utils.TruncateString(line, width))
+		b.WriteString("\n")
+	}
+	if len(entries) == 0 {
+		b.WriteString("No messages yet\n")
+	}
+	if m.api != nil {
+		requests, inFlight := m.api.snapshot()
+		fmt.Fprintf(&b, "\nJenkins requests (%d in flight)\n\n", inFlight)
+		for i := len(requests) - 1; i >= 0 && len(requests)-i <= rows; i-- {
+			b.WriteString(utils.TruncateString(requests[i].String(), width))
+			b.WriteString("\n")
+		}
+		if len(requests) == 0 {
+			b.WriteString("No requests yet\n")
+		}
+	}
+	b.WriteString("\n[F12 to close]")
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorAccent).
+		Background(ui.ColorOverlay).
+		Padding(0, 1).
+		Width(width + 2).
+		Render(b.String())
+}
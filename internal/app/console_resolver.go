@@ -1,14 +1,17 @@
 package app
 
 import (
+	"context"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/gorbach/jdash/internal/console"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ticket"
 )
 
 type consoleTargetResolvedMsg struct {
+	Ticket      uint64
 	JobFullName string
 	BuildNumber int
 	BuildURL    string
@@ -20,22 +23,30 @@ type consoleTargetTracker struct {
 	jobName     string
 	buildURL    string
 	buildNumber int
+	pipeline    bool
+	// requests drops resolutions of targets replaced since they were asked for.
+	requests ticket.Counter
 }
 
 func (t consoleTargetTracker) Reset() consoleTargetTracker {
+	t.requests.Invalidate()
 	t.jobFullName = ""
 	t.jobName = ""
 	t.buildURL = ""
 	t.buildNumber = 0
+	t.pipeline = false
 	return t
 }
 
-func (t consoleTargetTracker) WithTarget(jobFullName, jobName, buildURL string, buildNumber int) consoleTargetTracker {
+// WithTarget follows the latest build of a job and returns the ticket its
+// resolution must carry.
+func (t consoleTargetTracker) WithTarget(jobFullName, jobName, buildURL string, buildNumber int, pipeline bool) (consoleTargetTracker, uint64) {
 	t.jobFullName = jobFullName
 	t.jobName = jobName
 	t.buildURL = buildURL
 	t.buildNumber = buildNumber
-	return t
+	t.pipeline = pipeline
+	return t, t.requests.Next()
 }
 
 func (t consoleTargetTracker) JobFullName() string {
@@ -43,7 +54,7 @@ func (t consoleTargetTracker) JobFullName() string {
 }
 
 func (t consoleTargetTracker) ApplyResolution(msg consoleTargetResolvedMsg) (consoleTargetTracker, *console.OpenRequestMsg) {
-	if msg.Err != nil || msg.JobFullName == "" || msg.JobFullName != t.jobFullName {
+	if msg.Err != nil || !t.requests.IsCurrent(msg.Ticket) || msg.JobFullName != t.jobFullName {
 		return t, nil
 	}
 
@@ -69,26 +80,29 @@ func (t consoleTargetTracker) ApplyResolution(msg consoleTargetResolvedMsg) (con
 		JobFullName: t.jobFullName,
 		BuildNumber: number,
 		BuildURL:    url,
+		Pipeline:    t.pipeline,
 	}
 
 	return t, &open
 }
 
-func resolveConsoleTargetCmd(client jenkins.JenkinsClient, jobFullName string) tea.Cmd {
+func resolveConsoleTargetCmd(client jenkins.JenkinsClient, jobFullName string, ticket uint64) tea.Cmd {
 	if client == nil || jobFullName == "" {
 		return nil
 	}
 
 	return func() tea.Msg {
-		build, err := client.GetBuild(jobFullName, -1)
+		build, err := client.GetBuild(context.Background(), jobFullName, -1)
 		if err != nil {
 			return consoleTargetResolvedMsg{
+				Ticket:      ticket,
 				JobFullName: jobFullName,
 				Err:         err,
 			}
 		}
 
 		msg := consoleTargetResolvedMsg{
+			Ticket:      ticket,
 			JobFullName: jobFullName,
 		}
 
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/deeplink"
+	"github.com/gorbach/jdash/internal/details"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/statusbar"
+)
+
+// openLaunchTarget selects the job named on the command line and, when asked,
+// opens its console. It runs once, after the first job list arrives.
+func (m Model) openLaunchTarget() (Model, tea.Cmd) {
+	target := m.launch
+	m.launch = deeplink.Target{}
+
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+	var job *jenkins.Job
+	m.jobsPanel, job, cmd = m.jobsPanel.SelectJob(target.Job)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	if job == nil {
+		cmds = append(cmds, func() tea.Msg {
+			return statusbar.FeedbackMsg{Text: fmt.Sprintf("Job %s not found", target.Job), IsError: true}
+		})
+		return m, tea.Batch(cmds...)
+	}
+
+	m.activePanel = PanelJobs
+	if !target.OpensConsole() {
+		return m, tea.Batch(cmds...)
+	}
+
+	req := details.ActionRequestMsg{Kind: details.ActionKindViewLogs, Job: *job}
+	if target.Build > 0 {
+		req.Build = &jenkins.Build{
+			Number: target.Build,
+			URL:    fmt.Sprintf("%s/%d/", strings.TrimSuffix(job.URL, "/"), target.Build),
+		}
+	}
+	m, cmd = m.openConsole(req, target.Build == 0)
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
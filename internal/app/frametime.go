@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+// frameWindow is how many recent frames the frame-time overlay averages.
+const frameWindow = 120
+
+// frameSamples is a ring of recent durations.
+type frameSamples struct {
+	durations [frameWindow]time.Duration
+	next      int
+	count     int
+}
+
+func (s *frameSamples) add(d time.Duration) {
+	s.durations[s.next] = d
+	s.next = (s.next + 1) % frameWindow
+	if s.count < frameWindow {
+		s.count++
+	}
+}
+
+// stats returns the latest, average and longest duration in the window.
+func (s *frameSamples) stats() (last, avg, max time.Duration) {
+	if s.count == 0 {
+		return 0, 0, 0
+	}
+	var total time.Duration
+	for i := 0; i < s.count; i++ {
+		d := s.durations[i]
+		total += d
+		if d > max {
+			max = d
+		}
+	}
+	last = s.durations[(s.next+frameWindow-1)%frameWindow]
+	return last, total / time.Duration(s.count), max
+}
+
+// frameTimes measures how long Update and View take for the frame-time
+// overlay. View has a value receiver and can't keep state in the model, so
+// like apiTrace it is shared by pointer.
+type frameTimes struct {
+	mu      sync.Mutex
+	updates frameSamples
+	views   frameSamples
+}
+
+func (f *frameTimes) recordUpdate(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates.add(d)
+}
+
+func (f *frameTimes) recordView(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.views.add(d)
+}
+
+// String summarizes both, e.g. "update 0.21ms (avg 0.18, max 1.40) · view
+// 3.05ms (avg 2.90, max 8.12)".
+func (f *frameTimes) String() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	format := func(name string, s *frameSamples) string {
+		last, avg, max := s.stats()
+		return fmt.Sprintf("%s %.2fms (avg %.2f, max %.2f)", name, ms(last), ms(avg), ms(max))
+	}
+	return format("update", &f.updates) + " · " + format("view", &f.views)
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// frameTimesView draws the frame times over the bottom right corner, just
+// above the status bar. The View time shown is the previous frame's.
+func (m Model) frameTimesView(base string) string {
+	box := lipgloss.NewStyle().
+		Foreground(ui.ColorTextInverse).
+		Background(ui.ColorAccent).
+		Padding(0, 1).
+		Render(utils.TruncateString(m.frames.String(), maxInt(m.width-4, 1)))
+	x := maxInt(m.width-lipgloss.Width(box)-1, 0)
+	y := maxInt(m.height-2, 0)
+	return utils.Overlay(base, box, x, y)
+}
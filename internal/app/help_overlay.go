@@ -56,8 +56,6 @@ func (h helpOverlay) Handle(msg tea.Msg) (helpOverlay, tea.Cmd, bool) {
 		case "esc":
 			h.visible = false
 			return h, nil, true
-		case "ctrl+c", "q":
-			return h, tea.Quit, true
 		default:
 			var cmd tea.Cmd
 			h.viewport, cmd = h.viewport.Update(msg)
@@ -1,5 +1,68 @@
 package app
 
+import (
+	"fmt"
+	"io"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/details"
+	"github.com/gorbach/jdash/internal/export"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jobcache"
+	"github.com/gorbach/jdash/internal/notes"
+	"github.com/gorbach/jdash/internal/statusbar"
+)
+
+// saveJobCacheCmd refreshes the job names used by shell completion in the background.
+func saveJobCacheCmd(jobs []jenkins.Job) tea.Cmd {
+	return func() tea.Msg {
+		// Completion falls back to fetching from Jenkins if the cache cannot be written.
+		_ = jobcache.Save(jobs)
+		return nil
+	}
+}
+
+// saveNoteCmd persists a job note and reports the outcome to the details panel.
+func saveNoteCmd(store *notes.Store, jobFullName, text string) tea.Cmd {
+	return func() tea.Msg {
+		return details.NotesUpdatedMsg{
+			JobFullName: jobFullName,
+			Err:         store.Set(jobFullName, text),
+		}
+	}
+}
+
+// followRenamesCmd moves the notes of renamed jobs to their new names and
+// tells the user once about the rename.
+func followRenamesCmd(store *notes.Store, renames []jenkins.JobRename) tea.Cmd {
+	return func() tea.Msg {
+		for _, rename := range renames {
+			if err := store.Rename(rename.From, rename.To); err != nil {
+				return statusbar.FeedbackMsg{Text: fmt.Sprintf("Failed to move notes of %s: %v", rename.From, err), IsError: true}
+			}
+		}
+
+		first := renames[0]
+		text := fmt.Sprintf("%s was renamed to %s", first.From, first.To)
+		if len(renames) > 1 {
+			text += fmt.Sprintf(" (and %d more)", len(renames)-1)
+		}
+		return statusbar.FeedbackMsg{Text: text}
+	}
+}
+
+// exportCSVCmd writes a CSV snapshot into the working directory and reports the file in the status bar.
+func exportCSVCmd(kind string, write func(io.Writer) error) tea.Cmd {
+	return func() tea.Msg {
+		path, err := export.WriteFile(".", kind, time.Now(), write)
+		if err != nil {
+			return statusbar.FeedbackMsg{Text: fmt.Sprintf("Export failed: %v", err), IsError: true}
+		}
+		return statusbar.FeedbackMsg{Text: fmt.Sprintf("✓ Exported %s to %s", kind, path)}
+	}
+}
+
 func cloneParameterValues(src map[string]string) map[string]string {
 	if len(src) == 0 {
 		return nil
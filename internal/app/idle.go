@@ -0,0 +1,32 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/activity"
+)
+
+type idleCheckMsg time.Time
+
+func idleCheckCmd() tea.Cmd {
+	return tea.Tick(activity.CheckInterval, func(t time.Time) tea.Msg {
+		return idleCheckMsg(t)
+	})
+}
+
+// handleIdleCheck slows all polling once nobody has pressed a key and no
+// builds have run for the configured period. Keypresses resume it in Update.
+func (m Model) handleIdleCheck(msg idleCheckMsg) (tea.Model, tea.Cmd) {
+	cmds := []tea.Cmd{idleCheckCmd()}
+
+	if m.activity.Check(time.Time(msg), m.queuePanel.HasRunningBuilds()) {
+		var cmd tea.Cmd
+		m, cmd = m.dispatch(activity.IdleChangedMsg{Idle: true})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
@@ -2,6 +2,11 @@
 
 import tea "github.com/charmbracelet/bubbletea"
 
+// inputCapturer is implemented by modals that consume plain keys (such as q) as text input.
+type inputCapturer interface {
+	CapturesInput() bool
+}
+
 type modalController struct {
 	kind  modalType
 	model tea.Model
@@ -11,6 +16,15 @@ func (mc modalController) Active() bool {
 	return mc.kind != modalNone && mc.model != nil
 }
 
+// CapturesInput reports whether the active modal consumes plain keys as text input.
+func (mc modalController) CapturesInput() bool {
+	if !mc.Active() {
+		return false
+	}
+	capturer, ok := mc.model.(inputCapturer)
+	return ok && capturer.CapturesInput()
+}
+
 func (mc modalController) Clear() modalController {
 	mc.kind = modalNone
 	mc.model = nil
@@ -35,13 +49,6 @@ func (mc modalController) Update(msg tea.Msg) (modalController, tea.Cmd, bool) {
 		return mc, nil, false
 	}
 
-	if key, ok := msg.(tea.KeyMsg); ok {
-		switch key.String() {
-		case "ctrl+c", "q":
-			return mc, tea.Quit, true
-		}
-	}
-
 	var cmd tea.Cmd
 	mc.model, cmd = mc.model.Update(msg)
 	return mc, cmd, true
@@ -1,12 +1,30 @@
 package app
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/activity"
+	"github.com/gorbach/jdash/internal/bookmarks"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/deeplink"
+	"github.com/gorbach/jdash/internal/favorites"
+	"github.com/gorbach/jdash/internal/inflight"
 	"github.com/gorbach/jdash/internal/jenkins"
 	"github.com/gorbach/jdash/internal/jobs"
+	"github.com/gorbach/jdash/internal/logtransform"
+	"github.com/gorbach/jdash/internal/notes"
 	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/queuelog"
+	"github.com/gorbach/jdash/internal/release"
 	"github.com/gorbach/jdash/internal/statusbar"
+	"github.com/gorbach/jdash/internal/termstatus"
+	"github.com/gorbach/jdash/internal/treestate"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
 )
 
 // PanelID represents which panel is active.
@@ -23,6 +41,16 @@
 const (
 	modalNone modalType = iota
 	modalParameters
+	modalDependencies
+	modalNotes
+	modalTokenRotation
+	modalJobCopy
+	modalHistory
+	modalBuildInfo
+	modalQueueHistory
+	modalAbout
+	modalBulk
+	modalRunning
 )
 
 type bottomView int
@@ -30,9 +58,14 @@
 const (
 	bottomViewDetails bottomView = iota
 	bottomViewConsole
+	bottomViewQueueItem
 )
 
-var dimContentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+// dimContentStyle greys out the panels behind an overlay. It is built on
+// use to follow the theme chosen at startup.
+func dimContentStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(ui.ColorDim)
+}
 
 const (
 	helpViewportMinWidth  = 30
@@ -44,10 +77,13 @@
 
 Global
   q        quit application
-  r        refresh all data
+  %-8s refresh focused panel
+  %-8s refresh everything
   ?        toggle this help
   Tab      next panel
   1-3      jump to panel
+  Ctrl+t   rotate API token
+  A        about jdash / changelog
 
 Jobs List (Panel 1)
   Up/k     move up
@@ -57,17 +93,62 @@
   Space    toggle expand
   Enter    view details
   g/G      top/bottom
-  /        search
+  /        search (Ctrl+r: fuzzy/substring/regex)
   b        build now
+  f        add/remove favorite
+  s        sort by name/last build/duration/status
+  F        filter by status (failed, building...)
+  d        disable/enable job
+  D        folder dependency graph
+  E        export jobs to CSV
+  o        open in browser
+  v        visual select
+
+Visual Select (Panel 1)
+  Space    mark/unmark job or folder
+  b        build marked jobs
+  d/e      disable/enable marked jobs
+  f        add marked jobs to favorites
+  v/Esc    leave visual select
+
+Build Queue (Panel 2)
+  Up/k     select previous queued item
+  Down/j   select next queued item
+  Enter    open selected item in bottom panel
+  x        remove selected item from queue
+  E        export queue to CSV
+  H        queue wait history
 
 Build Info (Panel 3)
-  b        build now / configure
+  j/k, J/K scroll (also Ctrl+e/Ctrl+y)
+  ^d/^u    scroll half a page
+  PgDn/Up  scroll a page
+  Home/End scroll to top/bottom
+  b        build now / configure (scan branches on multibranch)
   l        view logs
   p        parameters (if available)
   c        view config
-  r        refresh details
   H        build history
   a        abort running build
+  B        running builds (concurrent jobs)
+  N        edit job notes
+  e        name/describe last build
+  y        copy status snippet
+  o        open last build in browser
+  d        disable/enable job
+  C        copy job (e.g. from a template)
+  X        delete job
+
+Console Logs
+  s        toggle auto-scroll
+  S        smart tail (pause on failure lines)
+  Space    resume tailing
+  m        bookmark top line
+  [/]      previous/next bookmark
+  {/}      previous/next stage (Pipeline)
+  z/Z      fold stage / all stages (Pipeline)
+  /        search
+  Esc      back to details
 
 [Press ? or Esc to close]
 `
@@ -81,31 +162,180 @@ type Model struct {
 
 	serverURL string
 	client    jenkins.JenkinsClient
+	notes     *notes.Store
 
 	jobsPanel  jobs.Model
 	queuePanel queue.Model
 	bottom     bottomPane
 	statusBar  statusbar.Model
 
-	help  helpOverlay
-	modal modalController
-	async consoleTargetTracker
+	help     helpOverlay
+	modal    modalController
+	async    consoleTargetTracker
+	activity activity.Tracker
+
+	// launch is the deep link still waiting for the job list; cleared once applied.
+	launch deeplink.Target
+
+	paneMode bool
+	// hideQueue and hideDetails leave the queue and bottom panels out of the
+	// layout; the bottom one still opens, full size, for consoles.
+	hideQueue     bool
+	hideDetails   bool
+	terminalTitle bool
+	tmuxStatus    bool
+	// terminal is the summary last published to the title and tmux.
+	terminal termstatus.Status
+
+	defaultAction func(fullName string) string
+	// promotionParameter marks deployments in the build history.
+	promotionParameter string
+	// user is the configured Jenkins user, marked in the build history.
+	user string
+
+	// trace records message routing for the debug overlay (debug mode only).
+	trace busTrace
+	// api records Jenkins requests for the debug overlay (debug mode only).
+	api          *apiTrace
+	debugOverlay bool
+	// frames measures Update and View for the frame-time overlay (profiling
+	// only).
+	frames *frameTimes
+	// paused is set while the terminal is too small for the dashboard.
+	paused bool
+
+	// actions follows changes sent to Jenkins so quitting can wait for them.
+	actions *inflight.Tracker
+	// stopPolling cancels the polls and fetches still running on quit.
+	stopPolling context.CancelFunc
+	// confirmPolicy is passed on to modals that act on builds.
+	confirmPolicy confirm.Policy
+	quit          quitState
+
+	version         release.Info
+	checkForUpdates bool
+	// newReleases are the releases newer than version found by the last
+	// check, newest first, and updateErr why it failed.
+	newReleases []release.Release
+	updateErr   error
+
+	refreshKeys refreshKeys
+}
+
+// Options tunes the dashboard's polling and fetching behaviour.
+type Options struct {
+	// IdleAfter slows polling down after this long without input or running
+	// builds; zero or negative disables the backoff.
+	IdleAfter time.Duration
+	// SelectionDebounce delays the details fetch after the job cursor moves;
+	// zero fetches immediately.
+	SelectionDebounce time.Duration
+	// QueuePollInterval is the time between queue polls; zero keeps the
+	// queue panel's default.
+	QueuePollInterval time.Duration
+	// Launch is the job (and build console) to open once jobs first load.
+	Launch deeplink.Target
+	// PaneMode shows one borderless panel at a time for small tmux panes.
+	PaneMode bool
+	// HideQueue and HideDetails leave the queue panel and the job details
+	// out of the layout, giving their room to the other panels.
+	HideQueue   bool
+	HideDetails bool
+	// ReadOnly marks the server as read-only in the status bar.
+	ReadOnly bool
+	// TerminalTitle keeps the terminal title on the selected job and failing count.
+	TerminalTitle bool
+	// TmuxStatus mirrors that summary into a tmux option for status lines.
+	TmuxStatus bool
+	// DefaultAction names what Enter does on a job ("" or "details" just
+	// shows it); nil keeps the default everywhere.
+	DefaultAction func(fullName string) string
+	// ExclusiveExpand collapses a folder's siblings whenever it is expanded.
+	ExclusiveExpand bool
+	// PromotionParameter is a build parameter whose value marks a build as
+	// promoted in the history, for teams without the promoted-builds plugin.
+	PromotionParameter string
+	// ConfirmPolicy decides which actions ask before running; the zero value
+	// uses confirm.Default.
+	ConfirmPolicy confirm.Policy
+	// Version identifies the running binary in the About overlay.
+	Version release.Info
+	// CheckForUpdates looks for a newer release on GitHub once a day.
+	CheckForUpdates bool
+	// LogTransforms rewrite the console output of the jobs they apply to.
+	LogTransforms logtransform.Set
+	// RefreshKey refreshes the focused panel and RefreshAllKey every panel;
+	// empty keeps r and R.
+	RefreshKey    string
+	RefreshAllKey string
+	// User is the configured Jenkins user; the builds and queue items they
+	// started get a "me" badge.
+	User string
+	// FrameTimes shows how long each Update and View takes, for profiling.
+	FrameTimes bool
 }
 
 // New creates a new application model.
-func New(serverURL string, client jenkins.JenkinsClient) Model {
-	help := newHelpOverlay(helpContent)
-	bottom := newBottomPane(client)
+func New(serverURL string, client jenkins.JenkinsClient, opts Options) Model {
+	keys := newRefreshKeys(opts.RefreshKey, opts.RefreshAllKey)
+	help := newHelpOverlay(fmt.Sprintf(helpContent, keys.panel, keys.all))
+
+	// A broken state file should not keep the dashboard from starting: the
+	// stores are usable either way, and a corrupt file is moved aside rather
+	// than saved over.
+	notesStore, _ := notes.Load()
+	bookmarkStore, _ := bookmarks.Load()
+	favoriteStore, _ := favorites.Load()
+	treeStore, _ := treestate.Load()
+	queueLog, _ := queuelog.Load()
+	actions := inflight.New()
+	polling, stopPolling := context.WithCancel(context.Background())
+	bottom := newBottomPane(client, notesStore, bookmarkStore, opts.SelectionDebounce, opts.ConfirmPolicy, actions)
+	bottom.console = bottom.console.WithTransforms(opts.LogTransforms)
+	bottom.queueItem = bottom.queueItem.WithContext(polling)
+	bottom.details = bottom.details.WithUser(opts.User)
+
+	var frames *frameTimes
+	if opts.FrameTimes {
+		frames = &frameTimes{}
+	}
+
+	var api *apiTrace
+	if utils.DebugEnabled() && client != nil {
+		api = &apiTrace{}
+		client.SetRequestObserver(api)
+	}
 
 	return Model{
 		activePanel: PanelJobs,
 		serverURL:   serverURL,
 		client:      client,
-		jobsPanel:   jobs.New(client),
-		queuePanel:  queue.New(client),
+		notes:       notesStore,
+		jobsPanel:   jobs.New(client).WithContext(polling).WithExclusiveExpand(opts.ExclusiveExpand).WithFavorites(favoriteStore).WithTreeState(treeStore),
+		queuePanel:  queue.New(client).WithContext(polling).WithTracker(actions).WithPollInterval(opts.QueuePollInterval).WithConfirmPolicy(opts.ConfirmPolicy).WithLog(queueLog).WithUser(opts.User),
 		bottom:      bottom,
-		statusBar:   statusbar.New(serverURL),
+		statusBar:   statusbar.New(serverURL).WithReadOnly(opts.ReadOnly),
 		help:        help,
+		activity:    activity.NewTracker(opts.IdleAfter, time.Now()),
+		launch:      opts.Launch,
+
+		paneMode:      opts.PaneMode,
+		hideQueue:     opts.HideQueue,
+		hideDetails:   opts.HideDetails,
+		terminalTitle: opts.TerminalTitle,
+		tmuxStatus:    opts.TmuxStatus,
+		defaultAction: opts.DefaultAction,
+
+		promotionParameter: opts.PromotionParameter,
+		user:               opts.User,
+		api:                api,
+		frames:             frames,
+		actions:            actions,
+		stopPolling:        stopPolling,
+		confirmPolicy:      opts.ConfirmPolicy,
+		version:            opts.Version,
+		checkForUpdates:    opts.CheckForUpdates,
+		refreshKeys:        keys,
 	}
 }
 
@@ -118,7 +348,11 @@ func (m Model) Init() tea.Cmd {
 		m.queuePanel.Init(),
 		m.statusBar.Init(),
 		m.help.InitCmd(),
+		idleCheckCmd(),
 	)
+	if m.checkForUpdates {
+		cmds = append(cmds, checkForUpdatesCmd(m.version.Version))
+	}
 
 	for _, cmd := range m.bottom.InitCmds() {
 		if cmd != nil {
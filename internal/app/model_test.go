@@ -0,0 +1,101 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/console"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+	"github.com/gorbach/jdash/internal/jobs"
+	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/statusbar"
+)
+
+// newTestModel returns a sized dashboard talking to client, with its local
+// state kept in a temporary directory.
+func newTestModel(t *testing.T, client jenkins.JenkinsClient) Model {
+	t.Helper()
+	saved := auth.ConfigDir()
+	t.Cleanup(func() { auth.UseConfigDir(saved) })
+	auth.UseConfigDir(t.TempDir())
+
+	m := New("https://jenkins.example.com", client, Options{})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return updated.(Model)
+}
+
+func TestMessageTopicsRouting(t *testing.T) {
+	tests := []struct {
+		msg  tea.Msg
+		want topic
+	}{
+		{msg: jobs.JobsFetchedMsg{}, want: topicJobs},
+		{msg: jobs.JobSelectedMsg{}, want: topicBottom},
+		{msg: queue.SnapshotMsg{}, want: topicJobs | topicBottom},
+		{msg: statusbar.FeedbackMsg{}, want: topicStatus},
+		{msg: console.OpenRequestMsg{}, want: topicBottom},
+		// Handled by the app alone.
+		{msg: queue.ItemSelectedMsg{}, want: 0},
+		// Unlisted messages reach every panel.
+		{msg: tea.WindowSizeMsg{}, want: topicAll},
+	}
+	for _, tt := range tests {
+		if got := topicsFor(tt.msg); got != tt.want {
+			t.Errorf("topicsFor(%T) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+	if got := (topicJobs | topicStatus).String(); got != "jobs|status" {
+		t.Errorf("String() = %q, want jobs|status", got)
+	}
+
+	// Fetched jobs reach the jobs panel.
+	m := newTestModel(t, &jenkinstest.Client{})
+	m, _ = m.dispatch(jobs.JobsFetchedMsg{Jobs: []jenkins.Job{{Name: "api", FullName: "api", Color: "blue"}}})
+	if _, job, _ := m.jobsPanel.SelectJob("api"); job == nil {
+		t.Error("the jobs panel did not get the fetched jobs")
+	}
+	// Ctrl+d stays with the panels, which page down with it.
+	if handled, _, _ := m.handleGlobalKeys(tea.KeyMsg{Type: tea.KeyCtrlD}); handled {
+		t.Error("ctrl+d is taken by a global shortcut")
+	}
+}
+
+func TestHiddenPanelsLeaveTheLayout(t *testing.T) {
+	saved := auth.ConfigDir()
+	t.Cleanup(func() { auth.UseConfigDir(saved) })
+	auth.UseConfigDir(t.TempDir())
+
+	m := New("https://jenkins.example.com", &jenkinstest.Client{}, Options{HideQueue: true, HideDetails: true, ReadOnly: true})
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+
+	if dims := m.calculatePanelDimensions(); dims.jobsWidth != 116 || dims.jobsHeight != 35 {
+		t.Errorf("jobs panel = %dx%d, want it to fill the screen (116x35)", dims.jobsWidth, dims.jobsHeight)
+	}
+	for _, key := range []string{"tab", "2", "3"} {
+		m, _ = press(m, key)
+		if m.activePanel != PanelJobs {
+			t.Errorf("%s focused hidden panel %v", key, m.activePanel)
+		}
+	}
+	view := m.View()
+	if strings.Contains(view, m.queuePanel.TitleBar().Name) {
+		t.Error("the hidden queue panel is drawn")
+	}
+	if !strings.Contains(view, "Read-only") {
+		t.Error("the status bar does not say the server is read-only")
+	}
+
+	// A console still opens, in place of the jobs, and closing it goes back.
+	m.activePanel = PanelBottom
+	if view := m.View(); strings.Contains(view, m.jobsPanel.TitleBar().Name) {
+		t.Error("the jobs panel is drawn under a full-screen console")
+	}
+	m, _ = m.handleConsoleExit()
+	if m.activePanel != PanelJobs {
+		t.Errorf("closing the console focused panel %v, want the jobs", m.activePanel)
+	}
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+	"github.com/gorbach/jdash/internal/queue"
+)
+
+func TestQueueItemOpensInTheBottomPanelOnEnter(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetBuildQueueFunc: func(context.Context) ([]jenkins.QueueItem, error) {
+			items := make([]jenkins.QueueItem, 2)
+			for i := range items {
+				items[i].ID = 40 + i
+				items[i].Task.Name = "api"
+			}
+			return items, nil
+		},
+	}
+	m := newTestModel(t, client)
+	// The queue panel's first poll; the batch also holds its timers.
+	updated, _ := m.Update(m.queuePanel.Init()().(tea.BatchMsg)[1]())
+	m = updated.(Model)
+	m, _ = press(m, "2")
+	if m.activePanel != PanelQueue {
+		t.Fatalf("2 focused panel %v, want the queue", m.activePanel)
+	}
+
+	m, cmd := press(m, "j")
+	if cmd != nil {
+		if _, ok := cmd().(queue.ItemSelectedMsg); ok {
+			t.Error("moving the cursor opened the item")
+		}
+	}
+	if m.bottom.Active() == bottomViewQueueItem {
+		t.Error("moving the cursor switched the bottom panel")
+	}
+
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("Enter did not open the item")
+	}
+	msg, ok := cmd().(queue.ItemSelectedMsg)
+	if !ok || msg.Item.ID != 41 {
+		t.Fatalf("Enter sent %+v, want the selected item", msg)
+	}
+	updated, _ = m.Update(msg)
+	m = updated.(Model)
+	if m.bottom.Active() != bottomViewQueueItem || m.activePanel != PanelBottom {
+		t.Errorf("bottom panel %v focused %v, want the item shown and focused", m.bottom.Active(), m.activePanel)
+	}
+}
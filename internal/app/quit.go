@@ -0,0 +1,124 @@
+package app
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+// quitState is how far quitting with actions still in flight has got.
+type quitState int
+
+const (
+	quitNone quitState = iota
+	// quitAsking shows the pending actions and asks whether to wait for them.
+	quitAsking
+	// quitWaiting quits as soon as Jenkins has answered every pending action.
+	quitWaiting
+)
+
+// isQuitKey reports whether key would quit the dashboard right now, i.e. it
+// isn't text typed into a prompt or search.
+func (m Model) isQuitKey(key tea.KeyMsg) bool {
+	switch key.String() {
+	case "ctrl+c":
+		return true
+	case "q":
+		if m.modal.Active() {
+			return !m.modal.CapturesInput()
+		}
+		return m.help.Active() || !m.activePanelCapturesInput()
+	}
+	return false
+}
+
+// interceptQuit asks before quitting while actions wait for Jenkins, and
+// owns every key until the question is answered.
+func (m Model) interceptQuit(key tea.KeyMsg) (Model, tea.Cmd, bool) {
+	switch m.quit {
+	case quitNone:
+		if !m.isQuitKey(key) || len(m.actions.Pending()) == 0 {
+			return m, nil, false
+		}
+		m.quit = quitAsking
+		return m, nil, true
+
+	case quitAsking:
+		switch key.String() {
+		case "w", "enter":
+			m.quit = quitWaiting
+			m.actions.Drain()
+			return m, nil, true
+		case "q", "ctrl+c":
+			return m.quitNow()
+		case "esc", "n":
+			m.quit = quitNone
+		}
+		return m, nil, true
+
+	default:
+		switch key.String() {
+		case "q", "ctrl+c":
+			return m.quitNow()
+		case "esc":
+			m.quit = quitNone
+		}
+		return m, nil, true
+	}
+}
+
+// quitNow cancels the pending actions' requests and quits.
+func (m Model) quitNow() (Model, tea.Cmd, bool) {
+	m.actions.Abandon()
+	return m, m.quitCmd(), true
+}
+
+// quitIfDrained quits once every action waited for has been answered.
+func (m Model) quitIfDrained() (Model, tea.Cmd, bool) {
+	if m.quit != quitWaiting || len(m.actions.Pending()) > 0 {
+		return m, nil, false
+	}
+	return m, m.quitCmd(), true
+}
+
+// quitCmd saves what the next session restores, the tree's folders and
+// selection and the console position, then quits. Every way out of the
+// dashboard goes through it.
+func (m Model) quitCmd() tea.Cmd {
+	m.stopPolling()
+	return tea.Sequence(m.jobsPanel.SaveTreeState(), m.bottom.console.SavePosition(), tea.Quit)
+}
+
+// ExitSummary lists what became of the actions that were in flight when the
+// user quit, for printing once the terminal is restored.
+func (m Model) ExitSummary() []string {
+	return m.actions.Summary()
+}
+
+// quitPromptView renders the quit question or the wait for Jenkins.
+func (m Model) quitPromptView() string {
+	var b strings.Builder
+	if m.quit == quitAsking {
+		b.WriteString(ui.TitleStyle.Render("Jenkins hasn't answered yet"))
+	} else {
+		b.WriteString(ui.TitleStyle.Render("Quitting once Jenkins answers..."))
+	}
+	b.WriteString("\n\n")
+	for _, label := range m.actions.Pending() {
+		b.WriteString("  • " + label + "\n")
+	}
+	b.WriteString("\n")
+	if m.quit == quitAsking {
+		b.WriteString(ui.SubtleStyle.Render("[w] Wait, then quit  [q] Quit now  [Esc] Stay"))
+	} else {
+		b.WriteString(ui.SubtleStyle.Render("[q] Quit now  [Esc] Stay"))
+	}
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(b.String())
+}
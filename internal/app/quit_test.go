@@ -0,0 +1,127 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+	"github.com/gorbach/jdash/internal/jobs"
+	"github.com/gorbach/jdash/internal/statusbar"
+	"github.com/gorbach/jdash/internal/treestate"
+)
+
+// quits reports whether cmd ends the program, running the commands of
+// batches and sequences it returns.
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	msg := cmd()
+	if _, ok := msg.(tea.QuitMsg); ok {
+		return true
+	}
+	// tea.Sequence returns an unexported slice of commands.
+	if v := reflect.ValueOf(msg); v.Kind() == reflect.Slice {
+		for i := 0; i < v.Len(); i++ {
+			if next, ok := v.Index(i).Interface().(tea.Cmd); ok && quits(next) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func press(m Model, key string) (Model, tea.Cmd) {
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	return updated.(Model), cmd
+}
+
+func TestQuitWithoutPendingActions(t *testing.T) {
+	m := newTestModel(t, &jenkinstest.Client{})
+	m, cmd := press(m, "q")
+	if m.quit != quitNone || !quits(cmd) {
+		t.Errorf("q with nothing in flight: quit state %v, quits = %v; want to quit at once", m.quit, quits(cmd))
+	}
+}
+
+func TestQuitSavesTreeState(t *testing.T) {
+	m := newTestModel(t, &jenkinstest.Client{})
+	updated, _ := m.Update(jobs.JobsFetchedMsg{Jobs: []jenkins.Job{
+		{Name: "team", FullName: "team", Class: "com.cloudbees.hudson.plugins.folder.Folder", Jobs: []jenkins.Job{
+			{Name: "web", FullName: "team/web", Color: "blue"},
+		}},
+	}})
+	m = updated.(Model)
+	m, _ = press(m, "l")
+	m, _ = press(m, "j")
+
+	_, cmd := press(m, "q")
+	if store, _ := treestate.Load(); len(store.State().Expanded) > 0 {
+		t.Fatal("tree state written in Update")
+	}
+	if !quits(cmd) {
+		t.Fatal("q did not quit")
+	}
+	store, _ := treestate.Load()
+	if state := store.State(); strings.Join(state.Expanded, " ") != "team" || state.Selected != "team/web" {
+		t.Errorf("saved %+v, want team expanded with team/web selected", state)
+	}
+}
+
+func TestQuitWaitsForPendingActions(t *testing.T) {
+	m := newTestModel(t, &jenkinstest.Client{})
+	_, done := m.actions.Start("Trigger a build of api")
+
+	m, cmd := press(m, "q")
+	if m.quit != quitAsking || cmd != nil {
+		t.Fatalf("q with an action in flight: quit state %v, cmd %v; want the question", m.quit, cmd)
+	}
+	if view := m.View(); !strings.Contains(view, "Jenkins hasn't answered yet") || !strings.Contains(view, "Trigger a build of api") {
+		t.Errorf("view does not ask about the pending action:\n%s", view)
+	}
+	m, _ = press(m, "n")
+	if m.quit != quitNone {
+		t.Fatalf("n left the quit state at %v", m.quit)
+	}
+
+	m, _ = press(m, "q")
+	m, cmd = press(m, "w")
+	if m.quit != quitWaiting || quits(cmd) {
+		t.Fatalf("w: quit state %v, quits = %v; want to wait", m.quit, quits(cmd))
+	}
+	// Other keys are swallowed while waiting.
+	if m, cmd = press(m, "j"); m.quit != quitWaiting || quits(cmd) {
+		t.Fatal("a key while waiting quit or stopped the wait")
+	}
+
+	done(nil)
+	updated, cmd := m.Update(statusbar.FeedbackMsg{Text: "✓ Triggered api"})
+	if !quits(cmd) {
+		t.Fatal("did not quit once Jenkins answered")
+	}
+	summary := updated.(Model).ExitSummary()
+	if len(summary) != 1 || summary[0] != "✓ Trigger a build of api: confirmed by Jenkins" {
+		t.Errorf("ExitSummary() = %q", summary)
+	}
+}
+
+func TestQuitNowAbandonsPendingActions(t *testing.T) {
+	m := newTestModel(t, &jenkinstest.Client{})
+	ctx, _ := m.actions.Start("Abort api #7")
+
+	m, _ = press(m, "q")
+	m, cmd := press(m, "q")
+	if !quits(cmd) {
+		t.Fatal("q at the question did not quit")
+	}
+	if ctx.Err() == nil {
+		t.Error("the abandoned action's request was not cancelled")
+	}
+	summary := m.ExitSummary()
+	if len(summary) != 1 || !strings.HasPrefix(summary[0], "? Abort api #7: quit before Jenkins answered") {
+		t.Errorf("ExitSummary() = %q", summary)
+	}
+}
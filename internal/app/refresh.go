@@ -0,0 +1,84 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/console"
+	"github.com/gorbach/jdash/internal/details"
+	"github.com/gorbach/jdash/internal/jobs"
+	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/queueitem"
+	"github.com/gorbach/jdash/internal/statusbar"
+)
+
+// refreshKeys are the keys that refresh the focused panel and everything.
+type refreshKeys struct {
+	panel string
+	all   string
+}
+
+// newRefreshKeys fills in r and R for keys left unset. Both set to the same
+// key would make one unreachable, so that key refreshes the focused panel and
+// R everything.
+func newRefreshKeys(panel, all string) refreshKeys {
+	keys := refreshKeys{panel: panel, all: all}
+	if keys.panel == "" {
+		keys.panel = "r"
+	}
+	if keys.all == "" || keys.all == keys.panel {
+		keys.all = "R"
+	}
+	if keys.all == keys.panel {
+		keys.panel = "r"
+	}
+	return keys
+}
+
+// startPanelRefresh reloads only the focused panel.
+func (m Model) startPanelRefresh() (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+
+	scope := ""
+	switch m.activePanel {
+	case PanelJobs:
+		scope = "jobs"
+		m.jobsPanel, cmd = m.jobsPanel.Update(jobs.RefreshRequestedMsg{})
+	case PanelQueue:
+		scope = "queue"
+		m.queuePanel, cmd = m.queuePanel.Update(queue.RefreshRequestedMsg{})
+	case PanelBottom:
+		switch m.bottom.Active() {
+		case bottomViewConsole:
+			scope = "logs"
+		case bottomViewQueueItem:
+			scope = "queue item"
+		default:
+			scope = "details"
+		}
+		m, cmd = m.refreshBottom()
+	}
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+
+	m.statusBar, cmd = m.statusBar.Update(statusbar.RefreshStartedMsg{Scope: scope})
+	if cmd != nil {
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// refreshBottom reloads whichever of details, console logs and the queued
+// item is showing.
+func (m Model) refreshBottom() (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch m.bottom.Active() {
+	case bottomViewConsole:
+		m.bottom, cmd = m.bottom.UpdateConsole(console.RefreshRequestedMsg{})
+	case bottomViewQueueItem:
+		m.bottom, cmd = m.bottom.UpdateQueueItem(queueitem.RefreshRequestedMsg{})
+	default:
+		m.bottom, cmd = m.bottom.UpdateDetails(details.RefreshRequestedMsg{})
+	}
+	return m, cmd
+}
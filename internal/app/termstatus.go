@@ -0,0 +1,30 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/termstatus"
+)
+
+// syncTerminalStatus republishes the terminal title and tmux summary when the
+// selected job or the failing count changed.
+func (m Model) syncTerminalStatus(selectedJob string) (Model, tea.Cmd) {
+	status := termstatus.Status{Job: selectedJob, Failing: m.jobsPanel.FailingCount()}
+	if status == m.terminal {
+		return m, nil
+	}
+	m.terminal = status
+
+	var cmds []tea.Cmd
+	if m.terminalTitle {
+		cmds = append(cmds, tea.SetWindowTitle(status.Title()))
+	}
+	if m.tmuxStatus {
+		text := status.Tmux()
+		cmds = append(cmds, func() tea.Msg {
+			// A missing or old tmux only costs the status line.
+			_ = termstatus.PublishTmux(text)
+			return nil
+		})
+	}
+	return m, tea.Batch(cmds...)
+}
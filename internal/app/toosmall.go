@@ -0,0 +1,57 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/activity"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+// The smallest terminals the panels are laid out for. Pane mode shows one
+// panel without borders and so makes do with less.
+const (
+	minWidth      = 80
+	minHeight     = 24
+	paneMinWidth  = 40
+	paneMinHeight = 10
+)
+
+// minSize returns the smallest terminal the current layout fits.
+func (m Model) minSize() (width, height int) {
+	if m.paneMode {
+		return paneMinWidth, paneMinHeight
+	}
+	return minWidth, minHeight
+}
+
+// tooSmall reports whether the terminal is below minSize.
+func (m Model) tooSmall() bool {
+	width, height := m.minSize()
+	return m.width < width || m.height < height
+}
+
+// updatePaused pauses background polling while the terminal is too small to
+// show the dashboard, and resumes it once it is resized to fit.
+func (m Model) updatePaused() (Model, tea.Cmd) {
+	small := m.tooSmall()
+	if small == m.paused {
+		return m, nil
+	}
+	m.paused = small
+	return m.dispatch(activity.PausedChangedMsg{Paused: small})
+}
+
+// tooSmallView replaces the dashboard while the terminal is too small, as
+// the panels would otherwise wrap into each other.
+func (m Model) tooSmallView() string {
+	width, height := m.minSize()
+	lines := []string{
+		ui.TitleStyle.Render("Terminal too small"),
+		fmt.Sprintf("need %dx%d, have %dx%d", width, height, m.width, m.height),
+		ui.SubtleStyle.Render("Polling is paused until you resize · q quits"),
+	}
+	content := lipgloss.JoinVertical(lipgloss.Center, lines...)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
@@ -2,15 +2,32 @@
 
 import (
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/activity"
+	"github.com/gorbach/jdash/internal/buildinfo"
+	"github.com/gorbach/jdash/internal/bulk"
+	"github.com/gorbach/jdash/internal/concurrent"
 	"github.com/gorbach/jdash/internal/console"
+	"github.com/gorbach/jdash/internal/depgraph"
 	"github.com/gorbach/jdash/internal/details"
+	"github.com/gorbach/jdash/internal/export"
+	"github.com/gorbach/jdash/internal/history"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jobcopy"
 	"github.com/gorbach/jdash/internal/jobs"
+	"github.com/gorbach/jdash/internal/notes"
 	"github.com/gorbach/jdash/internal/parameters"
 	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/queueitem"
+	"github.com/gorbach/jdash/internal/queuelog"
+	"github.com/gorbach/jdash/internal/release"
 	"github.com/gorbach/jdash/internal/statusbar"
+	"github.com/gorbach/jdash/internal/tokenrotate"
+	"github.com/gorbach/jdash/internal/utils"
 )
 
 type panelDimensions struct {
@@ -20,6 +37,16 @@ type panelDimensions struct {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.frames == nil {
+		return m.update(msg)
+	}
+	start := time.Now()
+	model, cmd := m.update(msg)
+	m.frames.recordUpdate(time.Since(start))
+	return model, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		cmd     tea.Cmd
 		handled bool
@@ -33,6 +60,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if resizeCmd != nil {
 			cmds = append(cmds, resizeCmd)
 		}
+		m, cmd = m.updatePaused()
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 
 		if m.modal.Active() {
 			var modalCmd tea.Cmd
@@ -45,12 +76,46 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Batch(cmds...)
 	}
 
-	m.help, cmd, handled = m.help.Handle(msg)
-	if cmd != nil {
-		cmds = append(cmds, cmd)
+	if drained, quitCmd, done := m.quitIfDrained(); done {
+		return drained, quitCmd
 	}
-	if handled {
-		return m, tea.Batch(cmds...)
+	if key, ok := msg.(tea.KeyMsg); ok {
+		var quitCmd tea.Cmd
+		if m, quitCmd, handled = m.interceptQuit(key); handled {
+			return m, quitCmd
+		}
+		// Quitting from a modal, the help or a panel all saves the same state.
+		if m.isQuitKey(key) {
+			m, quitCmd, _ = m.quitNow()
+			return m, quitCmd
+		}
+		// Keys would act on panels nobody can see.
+		if m.paused {
+			return m, nil
+		}
+	}
+
+	// Idle tracking runs ahead of the modal so polling resumes even while a modal has focus.
+	switch typed := msg.(type) {
+	case idleCheckMsg:
+		return m.handleIdleCheck(typed)
+	case tea.KeyMsg:
+		if m.activity.Touch(time.Now()) {
+			m, cmd = m.dispatch(activity.IdleChangedMsg{Idle: false})
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	}
+
+	if !m.modal.CapturesInput() {
+		m.help, cmd, handled = m.help.Handle(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if handled {
+			return m, tea.Batch(cmds...)
+		}
 	}
 
 	m.modal, cmd, handled = m.modal.Update(msg)
@@ -59,7 +124,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	if handled {
 		switch msg.(type) {
-		case parameters.SubmittedMsg, parameters.CancelledMsg:
+		case parameters.SubmittedMsg, parameters.CancelledMsg, depgraph.ClosedMsg,
+			notes.SavedMsg, notes.CancelledMsg, tokenrotate.ClosedMsg, jobcopy.ClosedMsg,
+			history.ClosedMsg, history.OpenLogsMsg, buildinfo.ClosedMsg,
+			queuelog.ClosedMsg, queuelog.ExportRequestedMsg, release.ClosedMsg, bulk.ClosedMsg,
+			concurrent.ClosedMsg, concurrent.OpenLogsMsg:
 			handled = false
 		}
 	}
@@ -69,6 +138,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch typed := msg.(type) {
 	case tea.KeyMsg:
+		// A type-the-name confirmation owns every key but Ctrl+C.
+		if m.activePanelCapturesInput() && typed.String() != "ctrl+c" {
+			var keyCmd tea.Cmd
+			m, keyCmd = m.routeKeyToActivePanel(typed)
+			return m, tea.Batch(append(cmds, keyCmd)...)
+		}
 		if handled, updated, keyCmd := m.handleGlobalKeys(typed); handled {
 			if keyCmd != nil {
 				cmds = append(cmds, keyCmd)
@@ -107,6 +182,138 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, tea.Batch(cmds...)
 
+	case jobs.DependencyGraphRequestedMsg:
+		var graphCmd tea.Cmd
+		m, graphCmd = m.openDependencyGraph(typed.FolderFullName)
+		if graphCmd != nil {
+			cmds = append(cmds, graphCmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case jobs.BulkActionRequestedMsg:
+		var bulkCmd tea.Cmd
+		m, bulkCmd = m.openBulk(typed)
+		if bulkCmd != nil {
+			cmds = append(cmds, bulkCmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case jobs.ExportRequestedMsg:
+		jobsSnapshot := typed.Jobs
+		cmds = append(cmds, exportCSVCmd("jobs", func(w io.Writer) error {
+			return export.Jobs(w, jobsSnapshot)
+		}))
+		return m, tea.Batch(cmds...)
+
+	case queue.ExportRequestedMsg:
+		running, queued := typed.Running, typed.Queued
+		now := time.Now()
+		cmds = append(cmds, exportCSVCmd("queue", func(w io.Writer) error {
+			return export.Queue(w, running, queued, now)
+		}))
+		return m, tea.Batch(cmds...)
+
+	case queue.HistoryRequestedMsg:
+		var historyCmd tea.Cmd
+		m, historyCmd = m.openQueueHistory(typed.Entries)
+		if historyCmd != nil {
+			cmds = append(cmds, historyCmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case updateCheckedMsg:
+		var checkedCmd tea.Cmd
+		m, checkedCmd = m.handleUpdateChecked(typed)
+		if checkedCmd != nil {
+			cmds = append(cmds, checkedCmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case queuelog.ExportRequestedMsg:
+		entries := typed.Entries
+		cmds = append(cmds, exportCSVCmd("queue-history", func(w io.Writer) error {
+			return export.QueueHistory(w, entries)
+		}))
+		return m, tea.Batch(cmds...)
+
+	case depgraph.ClosedMsg, notes.CancelledMsg, tokenrotate.ClosedMsg, history.ClosedMsg, queuelog.ClosedMsg,
+		release.ClosedMsg:
+		m.modal = m.modal.Clear()
+		return m, tea.Batch(cmds...)
+
+	case history.OpenLogsMsg:
+		m.modal = m.modal.Clear()
+		build := typed.Build
+		var consoleCmd tea.Cmd
+		m, consoleCmd = m.openConsole(details.ActionRequestMsg{
+			Kind:  details.ActionKindViewLogs,
+			Job:   typed.Job,
+			Build: &build,
+		}, false)
+		if consoleCmd != nil {
+			cmds = append(cmds, consoleCmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case concurrent.OpenLogsMsg:
+		m.modal = m.modal.Clear()
+		build := typed.Build
+		var consoleCmd tea.Cmd
+		m, consoleCmd = m.openConsole(details.ActionRequestMsg{
+			Kind:  details.ActionKindViewLogs,
+			Job:   typed.Job,
+			Build: &build,
+		}, false)
+		if consoleCmd != nil {
+			cmds = append(cmds, consoleCmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case concurrent.ClosedMsg:
+		m.modal = m.modal.Clear()
+		if typed.Aborted {
+			var refreshCmd tea.Cmd
+			m.bottom, refreshCmd = m.bottom.UpdateDetails(details.RefreshRequestedMsg{})
+			cmds = append(cmds, refreshCmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case buildinfo.ClosedMsg:
+		m.modal = m.modal.Clear()
+		if typed.Saved {
+			var refreshCmd tea.Cmd
+			m.bottom, refreshCmd = m.bottom.UpdateDetails(details.RefreshRequestedMsg{})
+			cmds = append(cmds, refreshCmd, func() tea.Msg {
+				return statusbar.FeedbackMsg{Text: fmt.Sprintf("✓ Updated %s #%d", typed.JobFullName, typed.BuildNumber)}
+			})
+		}
+		return m, tea.Batch(cmds...)
+
+	case jobcopy.ClosedMsg:
+		m.modal = m.modal.Clear()
+		if typed.Created != "" {
+			var refreshCmd tea.Cmd
+			m.jobsPanel, refreshCmd = m.jobsPanel.Update(jobs.RefreshRequestedMsg{})
+			cmds = append(cmds, refreshCmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case bulk.ClosedMsg:
+		m.modal = m.modal.Clear()
+		var bulkCmd tea.Cmd
+		m.jobsPanel, bulkCmd = m.jobsPanel.Update(typed)
+		cmds = append(cmds, bulkCmd)
+		if typed.Confirmed && len(typed.Done) > 0 {
+			feedback := statusbar.FeedbackMsg{Text: bulkFeedback(typed)}
+			cmds = append(cmds, func() tea.Msg { return feedback })
+		}
+		return m, tea.Batch(cmds...)
+
+	case notes.SavedMsg:
+		m.modal = m.modal.Clear()
+		cmds = append(cmds, saveNoteCmd(m.notes, typed.JobFullName, typed.Text))
+		return m, tea.Batch(cmds...)
+
 	case console.ExitRequestedMsg:
 		var exitCmd tea.Cmd
 		m, exitCmd = m.handleConsoleExit()
@@ -125,7 +332,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	var broadcastCmd tea.Cmd
-	m, broadcastCmd = m.broadcastToAllPanels(msg)
+	m, broadcastCmd = m.dispatch(msg)
 	if broadcastCmd != nil {
 		cmds = append(cmds, broadcastCmd)
 	}
@@ -174,12 +381,20 @@ func (m Model) handleWindowResize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
 }
 
 func (m Model) calculatePanelDimensions() panelDimensions {
-	statusBarHeight := 1
-	topPanelHeight := (m.height - statusBarHeight) * 2 / 3
-	bottomPanelHeight := (m.height - statusBarHeight) - topPanelHeight
-	leftPanelWidth := m.width / 2
-	rightPanelWidth := m.width - leftPanelWidth
+	if m.paneMode {
+		// One panel fills the pane below its title bar, with a column of padding.
+		width, height := m.width-2, m.height-2
+		return panelDimensions{
+			jobsWidth:    width,
+			jobsHeight:   height,
+			queueWidth:   width,
+			queueHeight:  height,
+			bottomWidth:  width,
+			bottomHeight: height,
+		}
+	}
 
+	topPanelHeight, bottomPanelHeight, leftPanelWidth, rightPanelWidth := m.panelLayout()
 	return panelDimensions{
 		jobsWidth:    leftPanelWidth - 4,
 		jobsHeight:   topPanelHeight - 4,
@@ -190,33 +405,116 @@ func (m Model) calculatePanelDimensions() panelDimensions {
 	}
 }
 
+// panelLayout splits the screen above the status bar between the panels,
+// outer borders included. A hidden bottom panel gives its rows to the top
+// ones and, when focused, takes the whole screen; a hidden queue gives its
+// columns to the jobs.
+func (m Model) panelLayout() (topHeight, bottomHeight, leftWidth, rightWidth int) {
+	statusBarHeight := 1
+	topHeight = (m.height - statusBarHeight) * 2 / 3
+	bottomHeight = (m.height - statusBarHeight) - topHeight
+	if m.hideDetails {
+		topHeight = m.height - statusBarHeight
+		bottomHeight = topHeight
+	}
+	leftWidth = m.width / 2
+	if m.hideQueue {
+		leftWidth = m.width
+	}
+	return topHeight, bottomHeight, leftWidth, m.width - leftWidth
+}
+
+// panelShown reports whether id is part of the layout, and so reachable
+// with Tab and the number keys.
+func (m Model) panelShown(id PanelID) bool {
+	switch id {
+	case PanelQueue:
+		return !m.hideQueue
+	case PanelBottom:
+		return !m.hideDetails
+	default:
+		return true
+	}
+}
+
+// cyclePanel focuses the next shown panel in direction step (1 or -1).
+func (m Model) cyclePanel(step PanelID) Model {
+	next := m.activePanel
+	for range 3 {
+		next = (next + step + 3) % 3
+		if m.panelShown(next) {
+			break
+		}
+	}
+	m.activePanel = next
+	return m
+}
+
+// activePanelCapturesInput reports whether the focused panel is collecting
+// free text that global shortcuts must not steal.
+func (m Model) activePanelCapturesInput() bool {
+	switch m.activePanel {
+	case PanelQueue:
+		return m.queuePanel.CapturesInput()
+	case PanelBottom:
+		return m.bottom.CapturesInput()
+	default:
+		return false
+	}
+}
+
 func (m Model) handleGlobalKeys(msg tea.KeyMsg) (bool, Model, tea.Cmd) {
 	switch msg.String() {
-	case "ctrl+c", "q":
-		return true, m, tea.Quit
-
 	case "tab":
-		m.activePanel = (m.activePanel + 1) % 3
-		return true, m, nil
+		return true, m.cyclePanel(1), nil
 
 	case "shift+tab":
-		m.activePanel = (m.activePanel - 1 + 3) % 3
-		return true, m, nil
+		return true, m.cyclePanel(-1), nil
 
 	case "1":
 		m.activePanel = PanelJobs
 		return true, m, nil
 
 	case "2":
-		m.activePanel = PanelQueue
+		if m.panelShown(PanelQueue) {
+			m.activePanel = PanelQueue
+		}
 		return true, m, nil
 
 	case "3":
-		m.activePanel = PanelBottom
+		if m.panelShown(PanelBottom) {
+			m.activePanel = PanelBottom
+		}
 		return true, m, nil
 
-	case "r":
-		refreshModel, refreshCmd := m.startGlobalRefresh()
+	case "ctrl+t":
+		rotateModel, rotateCmd := m.openTokenRotation()
+		return true, rotateModel, rotateCmd
+
+	case "A":
+		// Typed into the jobs search rather than a shortcut there.
+		if m.activePanel == PanelJobs && m.jobsPanel.InSearchMode() {
+			break
+		}
+		aboutModel, aboutCmd := m.openAbout()
+		return true, aboutModel, aboutCmd
+
+	case "f12":
+		if utils.DebugEnabled() {
+			m.debugOverlay = !m.debugOverlay
+			return true, m, nil
+		}
+
+	case m.refreshKeys.panel, m.refreshKeys.all:
+		// Typed into the jobs search rather than a shortcut there.
+		if m.activePanel == PanelJobs && m.jobsPanel.InSearchMode() {
+			break
+		}
+		if msg.String() == m.refreshKeys.all {
+			refreshModel, refreshCmd := m.startGlobalRefresh()
+			return true, refreshModel, refreshCmd
+		}
+		refreshModel, refreshCmd := m.startPanelRefresh()
 		return true, refreshModel, refreshCmd
 	}
 	return false, m, nil
@@ -241,11 +539,7 @@ func (m Model) startGlobalRefresh() (Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 	}
 
-	if m.bottom.IsConsoleActive() {
-		m.bottom, cmd = m.bottom.UpdateConsole(console.RefreshRequestedMsg{})
-	} else {
-		m.bottom, cmd = m.bottom.UpdateDetails(details.RefreshRequestedMsg{})
-	}
+	m, cmd = m.refreshBottom()
 	if cmd != nil {
 		cmds = append(cmds, cmd)
 	}
@@ -273,30 +567,42 @@ func (m Model) routeKeyToActivePanel(msg tea.KeyMsg) (Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) broadcastToAllPanels(msg tea.Msg) (Model, tea.Cmd) {
+// handlePanelMsg runs the app-level reactions to messages panels emit.
+func (m Model) handlePanelMsg(msg tea.Msg) (Model, tea.Cmd) {
 	var cmds []tea.Cmd
 	var cmd tea.Cmd
 
-	m.jobsPanel, cmd = m.jobsPanel.Update(msg)
-	if cmd != nil {
-		cmds = append(cmds, cmd)
-	}
-
-	m.queuePanel, cmd = m.queuePanel.Update(msg)
-	if cmd != nil {
-		cmds = append(cmds, cmd)
-	}
-
-	var bottomCmds []tea.Cmd
-	m.bottom, bottomCmds = m.bottom.Broadcast(msg)
-	cmds = append(cmds, bottomCmds...)
-
-	m.statusBar, cmd = m.statusBar.Update(msg)
-	if cmd != nil {
-		cmds = append(cmds, cmd)
-	}
-
 	switch t := msg.(type) {
+	case jobs.JobSelectedMsg:
+		m, cmd = m.syncTerminalStatus(t.Job.FullName)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if m.bottom.Active() == bottomViewQueueItem {
+			m.bottom, cmd = m.bottom.ShowDetails()
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	case queue.ItemSelectedMsg:
+		m.bottom, cmd = m.bottom.ShowQueueItem(t.Item)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		m.activePanel = PanelBottom
+	case queueitem.ClosedMsg:
+		m.bottom, cmd = m.bottom.ShowDetails()
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if !m.panelShown(PanelBottom) {
+			m.activePanel = PanelJobs
+		}
+	case jobs.JobSelectionClearedMsg:
+		m, cmd = m.syncTerminalStatus("")
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 	case jobs.JobsFetchedMsg:
 		m.statusBar, cmd = m.statusBar.Update(statusbar.RefreshFinishedMsg{
 			JobCount: len(t.Jobs),
@@ -304,6 +610,40 @@ func (m Model) broadcastToAllPanels(msg tea.Msg) (Model, tea.Cmd) {
 		if cmd != nil {
 			cmds = append(cmds, cmd)
 		}
+		cmds = append(cmds, saveJobCacheCmd(t.Jobs))
+		m, cmd = m.syncTerminalStatus(m.terminal.Job)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+		if !m.launch.IsZero() {
+			m, cmd = m.openLaunchTarget()
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	case jobs.JobsRenamedMsg:
+		cmds = append(cmds, followRenamesCmd(m.notes, t.Renames))
+	case jobs.JobActivatedMsg:
+		if m.defaultAction == nil {
+			break
+		}
+		if action := m.defaultAction(t.Job.FullName); action != "" && action != "details" {
+			if action == "build" {
+				// The trigger may ask for confirmation in the details panel.
+				m.activePanel = PanelBottom
+			}
+			m, cmd = m.dispatch(details.RunActionMsg{JobFullName: t.Job.FullName, Action: action})
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+	case jobs.JobActionRequestedMsg:
+		// The details panel runs the action and may ask for confirmation there.
+		m.activePanel = PanelBottom
+		m, cmd = m.dispatch(details.RunActionMsg{JobFullName: t.Job.FullName, Action: t.Action})
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 	case jobs.JobsErrorMsg:
 		m.statusBar, cmd = m.statusBar.Update(statusbar.RefreshFinishedMsg{
 			JobCount: -1,
@@ -323,8 +663,21 @@ func (m Model) handleActionRequest(msg details.ActionRequestMsg) (Model, tea.Cmd
 		return m.openParametersModal(msg)
 	case details.ActionKindViewLogs:
 		return m.openConsoleView(msg)
+	case details.ActionKindEditNotes:
+		return m.openNotesEditor(msg)
+	case details.ActionKindCopyJob:
+		return m.openJobCopy(msg.Job.FullName)
+	case details.ActionKindViewHistory:
+		return m.openHistory(msg.Job)
+	case details.ActionKindViewRunning:
+		return m.openRunning(msg.Job)
+	case details.ActionKindEditBuild:
+		if msg.Build == nil {
+			return m, nil
+		}
+		return m.openBuildInfo(msg.Job.FullName, *msg.Build)
 	default:
-		return m.broadcastToAllPanels(msg)
+		return m.dispatch(msg)
 	}
 }
 
@@ -335,12 +688,12 @@ func (m Model) handleParameterSubmit(msg parameters.SubmittedMsg) (Model, tea.Cm
 		JobName:     msg.JobName,
 		Values:      cloneParameterValues(msg.Values),
 	}
-	return m.broadcastToAllPanels(submission)
+	return m.dispatch(submission)
 }
 
 func (m Model) handleParameterCancel(msg parameters.CancelledMsg) (Model, tea.Cmd) {
 	m.modal = m.modal.Clear()
-	return m.broadcastToAllPanels(details.ParameterCancelledMsg{JobFullName: msg.JobFullName})
+	return m.dispatch(details.ParameterCancelledMsg{JobFullName: msg.JobFullName})
 }
 
 func (m Model) openParametersModal(req details.ActionRequestMsg) (Model, tea.Cmd) {
@@ -349,7 +702,7 @@ func (m Model) openParametersModal(req details.ActionRequestMsg) (Model, tea.Cmd
 	}
 
 	m.modal = m.modal.Clear()
-	modal := parameters.New(req.Job.Name, req.Job.FullName, req.ParameterDefinitions)
+	modal := parameters.New(m.client, req.Job.Name, req.Job.FullName, req.ParameterDefinitions)
 
 	var cmds []tea.Cmd
 	if initCmd := modal.Init(); initCmd != nil {
@@ -369,7 +722,218 @@ func (m Model) openParametersModal(req details.ActionRequestMsg) (Model, tea.Cmd
 	return m, tea.Batch(cmds...)
 }
 
+func (m Model) openNotesEditor(req details.ActionRequestMsg) (Model, tea.Cmd) {
+	if m.notes == nil {
+		return m, nil
+	}
+
+	note, _ := m.notes.Get(req.Job.FullName)
+	m.modal = m.modal.Clear()
+	editor := notes.NewEditor(req.Job.Name, req.Job.FullName, note.Text)
+
+	var cmds []tea.Cmd
+	if initCmd := editor.Init(); initCmd != nil {
+		cmds = append(cmds, initCmd)
+	}
+
+	m.modal = m.modal.Set(modalNotes, editor)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		if sizeCmd != nil {
+			cmds = append(cmds, sizeCmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) openDependencyGraph(folder string) (Model, tea.Cmd) {
+	m.modal = m.modal.Clear()
+	modal := depgraph.New(m.client, folder)
+
+	var cmds []tea.Cmd
+	if initCmd := modal.Init(); initCmd != nil {
+		cmds = append(cmds, initCmd)
+	}
+
+	m.modal = m.modal.Set(modalDependencies, modal)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		if sizeCmd != nil {
+			cmds = append(cmds, sizeCmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) openJobCopy(fromFullName string) (Model, tea.Cmd) {
+	m.modal = m.modal.Clear()
+	modal := jobcopy.New(m.client, m.actions, fromFullName)
+
+	var cmds []tea.Cmd
+	if initCmd := modal.Init(); initCmd != nil {
+		cmds = append(cmds, initCmd)
+	}
+
+	m.modal = m.modal.Set(modalJobCopy, modal)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		if sizeCmd != nil {
+			cmds = append(cmds, sizeCmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) openBulk(req jobs.BulkActionRequestedMsg) (Model, tea.Cmd) {
+	m.modal = m.modal.Clear()
+	modal := bulk.New(m.client, m.actions, req.Action, req.Jobs).WithConfirmPolicy(m.confirmPolicy)
+	m.modal = m.modal.Set(modalBulk, modal)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		return m, sizeCmd
+	}
+	return m, nil
+}
+
+// bulkFeedback sums up a bulk action for the status bar, e.g. "✓ Disabled 3
+// jobs".
+func bulkFeedback(msg bulk.ClosedMsg) string {
+	jobs := fmt.Sprintf("%d jobs", len(msg.Done))
+	if len(msg.Done) == 1 {
+		jobs = msg.Done[0]
+	}
+	switch msg.Action {
+	case bulk.ActionBuild:
+		return "✓ Triggered builds of " + jobs
+	case bulk.ActionDisable:
+		return "✓ Disabled " + jobs
+	case bulk.ActionEnable:
+		return "✓ Enabled " + jobs
+	default:
+		return "✓ Added " + jobs + " to favorites"
+	}
+}
+
+func (m Model) openHistory(job jenkins.Job) (Model, tea.Cmd) {
+	m.modal = m.modal.Clear()
+	modal := history.New(m.client, job, m.promotionParameter).WithUser(m.user).WithConfirmPolicy(m.confirmPolicy).WithTracker(m.actions)
+
+	var cmds []tea.Cmd
+	if initCmd := modal.Init(); initCmd != nil {
+		cmds = append(cmds, initCmd)
+	}
+
+	m.modal = m.modal.Set(modalHistory, modal)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		if sizeCmd != nil {
+			cmds = append(cmds, sizeCmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) openRunning(job jenkins.Job) (Model, tea.Cmd) {
+	m.modal = m.modal.Clear()
+	modal := concurrent.New(m.client, m.actions, m.confirmPolicy, job)
+
+	var cmds []tea.Cmd
+	if initCmd := modal.Init(); initCmd != nil {
+		cmds = append(cmds, initCmd)
+	}
+
+	m.modal = m.modal.Set(modalRunning, modal)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		if sizeCmd != nil {
+			cmds = append(cmds, sizeCmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) openQueueHistory(entries []queuelog.Entry) (Model, tea.Cmd) {
+	m.modal = m.modal.Clear()
+	modal := queuelog.New(entries)
+	m.modal = m.modal.Set(modalQueueHistory, modal)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		return m, sizeCmd
+	}
+	return m, nil
+}
+
+func (m Model) openBuildInfo(jobFullName string, build jenkins.Build) (Model, tea.Cmd) {
+	m.modal = m.modal.Clear()
+	modal := buildinfo.New(m.client, m.actions, jobFullName, build)
+
+	var cmds []tea.Cmd
+	if initCmd := modal.Init(); initCmd != nil {
+		cmds = append(cmds, initCmd)
+	}
+
+	m.modal = m.modal.Set(modalBuildInfo, modal)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		if sizeCmd != nil {
+			cmds = append(cmds, sizeCmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+func (m Model) openTokenRotation() (Model, tea.Cmd) {
+	m.modal = m.modal.Clear()
+	modal := tokenrotate.New(m.client)
+
+	var cmds []tea.Cmd
+	if initCmd := modal.Init(); initCmd != nil {
+		cmds = append(cmds, initCmd)
+	}
+
+	m.modal = m.modal.Set(modalTokenRotation, modal)
+
+	if m.width > 0 && m.height > 0 {
+		var sizeCmd tea.Cmd
+		m.modal, sizeCmd = m.modal.Dispatch(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+		if sizeCmd != nil {
+			cmds = append(cmds, sizeCmd)
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
 func (m Model) openConsoleView(req details.ActionRequestMsg) (Model, tea.Cmd) {
+	return m.openConsole(req, true)
+}
+
+// openConsole streams the build in req. With followLatest the target is
+// re-resolved to the job's newest build, which may have started since the
+// details were fetched; otherwise the requested build is kept.
+func (m Model) openConsole(req details.ActionRequestMsg, followLatest bool) (Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	jobName := req.Job.Name
@@ -403,6 +967,7 @@ func (m Model) openConsoleView(req details.ActionRequestMsg) (Model, tea.Cmd) {
 		JobFullName: req.Job.FullName,
 		BuildNumber: buildNumber,
 		BuildURL:    buildURL,
+		Pipeline:    req.Job.IsPipeline(),
 	}
 
 	var consoleCmd tea.Cmd
@@ -418,10 +983,15 @@ func (m Model) openConsoleView(req details.ActionRequestMsg) (Model, tea.Cmd) {
 		cmds = append(cmds, sizeCmds...)
 	}
 
-	m.async = m.async.WithTarget(req.Job.FullName, jobName, buildURL, buildNumber)
 	m.activePanel = PanelBottom
+	if !followLatest {
+		m.async = m.async.Reset()
+		return m, tea.Batch(cmds...)
+	}
 
-	if resolveCmd := resolveConsoleTargetCmd(m.client, req.Job.FullName); resolveCmd != nil {
+	var resolveTicket uint64
+	m.async, resolveTicket = m.async.WithTarget(req.Job.FullName, jobName, buildURL, buildNumber, openMsg.Pipeline)
+	if resolveCmd := resolveConsoleTargetCmd(m.client, req.Job.FullName, resolveTicket); resolveCmd != nil {
 		cmds = append(cmds, resolveCmd)
 	}
 
@@ -432,6 +1002,10 @@ func (m Model) handleConsoleExit() (Model, tea.Cmd) {
 	var cmd tea.Cmd
 	m.bottom, cmd = m.bottom.ShowDetails()
 	m.activePanel = PanelBottom
+	if !m.panelShown(PanelBottom) {
+		// Back to the jobs rather than to details kept out of the layout.
+		m.activePanel = PanelJobs
+	}
 	return m, cmd
 }
 
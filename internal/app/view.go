@@ -1,45 +1,64 @@
 package app
 
 import (
-	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
 )
 
 func (m Model) View() string {
 	if m.width == 0 || m.height == 0 {
 		return "Loading..."
 	}
+	if m.frames == nil {
+		return m.view()
+	}
+	start := time.Now()
+	view := m.frameTimesView(m.view())
+	m.frames.recordView(time.Since(start))
+	return view
+}
 
-	statusBarHeight := 1
-	topPanelHeight := (m.height - statusBarHeight) * 2 / 3
-	bottomPanelHeight := (m.height - statusBarHeight) - topPanelHeight
-	leftPanelWidth := m.width / 2
-	rightPanelWidth := m.width - leftPanelWidth
-
-	jobsPanel := m.renderPanel(PanelJobs, m.jobsPanel.View(), leftPanelWidth, topPanelHeight)
-	queuePanel := m.renderPanel(PanelQueue, m.queuePanel.View(), rightPanelWidth, topPanelHeight)
-	topPanels := lipgloss.JoinHorizontal(lipgloss.Top, jobsPanel, queuePanel)
+func (m Model) view() string {
+	var view string
+	if m.paused {
+		view = m.tooSmallView()
+	} else {
+		view = m.dashboardView()
+	}
+	if m.quit != quitNone {
+		return utils.OverlayCenter(view, m.quitPromptView(), m.width, m.height)
+	}
+	return view
+}
 
-	bottomPanel := m.renderPanel(PanelBottom, m.bottom.View(), m.width, bottomPanelHeight)
-	statusBarView := m.statusBar.View()
+// dashboardView renders the panels with any overlay or modal on top.
+func (m Model) dashboardView() string {
 
-	baseContent := lipgloss.JoinVertical(
-		lipgloss.Left,
-		topPanels,
-		bottomPanel,
-		statusBarView,
-	)
+	var baseContent string
+	if m.paneMode {
+		baseContent = m.renderPaneMode()
+	} else {
+		baseContent = m.renderPanels()
+	}
 
 	if m.help.Active() {
 		baseContent = m.renderHelpOverlay(baseContent)
 	}
 
+	if m.debugOverlay {
+		baseContent = utils.OverlayCenter(baseContent, m.debugOverlayView(), m.width, m.height)
+	}
+
 	if !m.modal.Active() {
 		return baseContent
 	}
 
-	dimmed := dimContentStyle.Render(baseContent)
+	// Strip the panels' own colors so the dim style applies uniformly underneath.
+	dimmed := dimContentStyle().Render(ansi.Strip(baseContent))
 	baseView := lipgloss.NewStyle().
 		Width(m.width).
 		Height(m.height).
@@ -50,27 +69,79 @@ func (m Model) View() string {
 		return baseView
 	}
 
-	if m.width > 0 && m.height > 0 {
-		modalView = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modalView)
+	return utils.OverlayCenter(baseView, modalView, m.width, m.height)
+}
+
+// renderPanels lays out the jobs and queue panels side by side above the
+// bottom pane and the status bar, leaving out the hidden ones.
+func (m Model) renderPanels() string {
+	topPanelHeight, bottomPanelHeight, leftPanelWidth, rightPanelWidth := m.panelLayout()
+
+	if !m.panelShown(PanelBottom) && m.activePanel == PanelBottom {
+		// A console opened with the details hidden takes the whole screen.
+		bottomPanel := m.renderPanel(PanelBottom, m.bottom.TitleBar(), m.bottom.View(), m.width, bottomPanelHeight)
+		return lipgloss.JoinVertical(lipgloss.Left, bottomPanel, m.statusBar.View())
+	}
+
+	topPanels := m.renderPanel(PanelJobs, m.jobsPanel.TitleBar(), m.jobsPanel.View(), leftPanelWidth, topPanelHeight)
+	if m.panelShown(PanelQueue) {
+		queuePanel := m.renderPanel(PanelQueue, m.queuePanel.TitleBar(), m.queuePanel.View(), rightPanelWidth, topPanelHeight)
+		topPanels = lipgloss.JoinHorizontal(lipgloss.Top, topPanels, queuePanel)
+	}
+
+	panels := []string{topPanels}
+	if m.panelShown(PanelBottom) {
+		panels = append(panels, m.renderPanel(PanelBottom, m.bottom.TitleBar(), m.bottom.View(), m.width, bottomPanelHeight))
+	}
+	panels = append(panels, m.statusBar.View())
+	return lipgloss.JoinVertical(lipgloss.Left, panels...)
+}
+
+// renderPaneMode shows only the active panel, without borders, so jdash stays
+// readable in a small tmux pane. Tab and 1-3 switch panels as usual.
+func (m Model) renderPaneMode() string {
+	var title ui.TitleBar
+	var content string
+	switch m.activePanel {
+	case PanelQueue:
+		title, content = m.queuePanel.TitleBar(), m.queuePanel.View()
+	case PanelBottom:
+		title, content = m.bottom.TitleBar(), m.bottom.View()
+	default:
+		title, content = m.jobsPanel.TitleBar(), m.jobsPanel.View()
 	}
 
-	return overlayStrings(baseView, modalView)
+	body := lipgloss.NewStyle().
+		Width(m.width).
+		Height(maxInt(m.height-2, 0)).
+		MaxHeight(maxInt(m.height-2, 0)).
+		Padding(0, 1).
+		Render(content)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title.Render(m.width),
+		body,
+		m.statusBar.View(),
+	)
 }
 
-func (m Model) renderPanel(id PanelID, content string, width, height int) string {
-	borderColor := lipgloss.Color("8")
+// renderPanel draws a bordered panel with its title bar above the content.
+func (m Model) renderPanel(id PanelID, title ui.TitleBar, content string, width, height int) string {
+	borderColor := ui.ColorBorder
 	if m.activePanel == id {
-		borderColor = lipgloss.Color("10")
+		borderColor = ui.ColorBorderActive
 	}
 
 	style := lipgloss.NewStyle().
-		Width(width - 2).
-		Height(height - 2).
+		Width(width-2).
+		Height(height-2).
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(borderColor).
 		Padding(0, 1)
 
-	return style.Render(content)
+	header := title.Render(width - 4)
+	return style.Render(header + "\n" + content)
 }
 
 func (m Model) renderHelpOverlay(baseContent string) string {
@@ -78,7 +149,7 @@ func (m Model) renderHelpOverlay(baseContent string) string {
 		return baseContent
 	}
 
-	dimmed := dimContentStyle.Render(baseContent)
+	dimmed := dimContentStyle().Render(ansi.Strip(baseContent))
 	baseView := lipgloss.NewStyle().
 		Width(m.width).
 		Height(m.height).
@@ -89,8 +160,7 @@ func (m Model) renderHelpOverlay(baseContent string) string {
 		return baseView
 	}
 
-	helpView = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpView)
-	return overlayStrings(baseView, helpView)
+	return utils.OverlayCenter(baseView, helpView, m.width, m.height)
 }
 
 func (m Model) helpBoxView() string {
@@ -105,8 +175,8 @@ func (m Model) helpBoxView() string {
 
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("12")).
-		Background(lipgloss.Color("235")).
+		BorderForeground(ui.ColorTitle).
+		Background(ui.ColorOverlay).
 		Width(m.help.viewport.Width + 4)
 
 	return boxStyle.Render(body)
@@ -136,53 +206,3 @@ func (m Model) updateHelpViewportSize() Model {
 
 	return m
 }
-
-func overlayStrings(base, overlay string) string {
-	if overlay == "" {
-		return base
-	}
-	baseLines := strings.Split(base, "\n")
-	overlayLines := strings.Split(overlay, "\n")
-	maxLines := len(baseLines)
-	if len(overlayLines) > maxLines {
-		maxLines = len(overlayLines)
-	}
-	var builder strings.Builder
-	for i := 0; i < maxLines; i++ {
-		var baseLine, overlayLine string
-		if i < len(baseLines) {
-			baseLine = baseLines[i]
-		}
-		if i < len(overlayLines) {
-			overlayLine = overlayLines[i]
-		}
-		if overlayLine == "" {
-			builder.WriteString(baseLine)
-		} else if baseLine == "" {
-			builder.WriteString(overlayLine)
-		} else {
-			baseRunes := []rune(baseLine)
-			overlayRunes := []rune(overlayLine)
-			width := len(baseRunes)
-			if len(overlayRunes) > width {
-				width = len(overlayRunes)
-			}
-			merged := make([]rune, width)
-			for j := 0; j < width; j++ {
-				switch {
-				case j < len(overlayRunes):
-					merged[j] = overlayRunes[j]
-				case j < len(baseRunes):
-					merged[j] = baseRunes[j]
-				default:
-					merged[j] = ' '
-				}
-			}
-			builder.WriteString(string(merged))
-		}
-		if i < maxLines-1 {
-			builder.WriteRune('\n')
-		}
-	}
-	return builder.String()
-}
@@ -2,10 +2,20 @@
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/gorbach/jdash/internal/activity"
+	"github.com/gorbach/jdash/internal/confirm"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/logtransform"
+	"github.com/gorbach/jdash/internal/utils"
 )
 
 // ServerConfig holds Jenkins server credentials
@@ -13,28 +23,304 @@ type ServerConfig struct {
 	URL      string `json:"url"`
 	Username string `json:"username"`
 	Token    string `json:"token"`
+	// TokenUUID identifies Token in Jenkins when jdash generated it, so the
+	// next rotation can revoke it.
+	TokenUUID string `json:"tokenUuid,omitempty"`
+	// Proxy reaches this server through a proxy, e.g. socks5://127.0.0.1:1080,
+	// instead of the one in HTTPS_PROXY; "direct" bypasses that one.
+	Proxy string `json:"proxy,omitempty"`
+	// CACertFile is a PEM bundle of CAs trusted besides the system ones, for
+	// servers with certificates from a corporate CA.
+	CACertFile string `json:"caCertFile,omitempty"`
+	// InsecureSkipVerify accepts any server certificate; prefer CACertFile.
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// ClientCertFile and ClientKeyFile authenticate with mutual TLS.
+	ClientCertFile string `json:"clientCertFile,omitempty"`
+	ClientKeyFile  string `json:"clientKeyFile,omitempty"`
+	// RateLimit caps requests per second to the server; zero means the
+	// default, negative disables the limit. RateLimitBurst is how many may go
+	// out at once, by default twice the rate.
+	RateLimit      float64 `json:"rateLimit,omitempty"`
+	RateLimitBurst int     `json:"rateLimitBurst,omitempty"`
+	// RefreshInterval is the seconds between queue polls; zero means the
+	// one in the "ui" section, e.g. 15 polls a busy controller more gently.
+	RefreshInterval int `json:"refreshInterval,omitempty"`
+	// TreeDepth is how many levels of folders the job list fetches in one
+	// request; zero means the default. Deeper folders load when expanded.
+	TreeDepth int `json:"treeDepth,omitempty"`
+	// ReadOnly refuses to trigger, abort or change anything on the server.
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Panels lists the panels shown for the server, out of "jobs", "queue"
+	// and "details"; empty shows them all. The job list is always shown.
+	Panels []string `json:"panels,omitempty"`
+	// SSH triggers builds, reads console logs and checks the connection
+	// through the Jenkins SSH CLI, for servers behind reverse proxies that
+	// block those requests. Everything else still goes over HTTP.
+	SSH *SSHConfig `json:"ssh,omitempty"`
+}
+
+// SSHConfig reaches the Jenkins SSH CLI as the server's Username, with a key
+// registered in that user's Jenkins profile.
+type SSHConfig struct {
+	// Endpoint is the SSH server's host:port; empty asks Jenkins for it.
+	Endpoint string `json:"endpoint,omitempty"`
+	// IdentityFile is the private key to use instead of ssh's default.
+	IdentityFile string `json:"identityFile,omitempty"`
+}
+
+// defaultRateLimit is well above what the dashboard's polling needs, so it
+// only throttles runaway request bursts.
+const defaultRateLimit = 10
+
+// RequestRate returns the request rate limit and burst for the server; a
+// zero rate means unlimited.
+func (s ServerConfig) RequestRate() (perSecond float64, burst int) {
+	switch {
+	case s.RateLimit < 0:
+		return 0, 0
+	case s.RateLimit == 0:
+		perSecond = defaultRateLimit
+	default:
+		perSecond = s.RateLimit
+	}
+	burst = s.RateLimitBurst
+	if burst <= 0 {
+		burst = int(math.Ceil(2 * perSecond))
+	}
+	return perSecond, burst
+}
+
+// PollInterval returns how often the queue of the server is polled: its own
+// refreshInterval, or else the one in ui. Zero keeps the dashboard's default.
+func (s ServerConfig) PollInterval(ui UIConfig) time.Duration {
+	seconds := s.RefreshInterval
+	if seconds <= 0 {
+		seconds = ui.RefreshInterval
+	}
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// PanelSet says which of the optional panels beside the job list are shown.
+type PanelSet struct {
+	Queue   bool
+	Details bool
+}
+
+// EnabledPanels returns the panels Panels turns on, all of them when it is
+// empty. Unknown names are reported and skipped.
+func (s ServerConfig) EnabledPanels() (PanelSet, error) {
+	if len(s.Panels) == 0 {
+		return PanelSet{Queue: true, Details: true}, nil
+	}
+	var panels PanelSet
+	var unknown []string
+	for _, name := range s.Panels {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "jobs":
+		case "queue":
+			panels.Queue = true
+		case "details":
+			panels.Details = true
+		default:
+			unknown = append(unknown, strconv.Quote(name))
+		}
+	}
+	if len(unknown) > 0 {
+		return panels, fmt.Errorf("unknown panels %s; the panels are jobs, queue and details", strings.Join(unknown, ", "))
+	}
+	return panels, nil
+}
+
+// Credentials returns what the Jenkins client needs to reach the server.
+func (s ServerConfig) Credentials() jenkins.Credentials {
+	rate, burst := s.RequestRate()
+	return jenkins.Credentials{
+		URL:      s.URL,
+		Username: s.Username,
+		Token:    s.Token,
+		Proxy:    s.Proxy,
+		TLS: jenkins.TLSOptions{
+			CAFile:             s.CACertFile,
+			InsecureSkipVerify: s.InsecureSkipVerify,
+			CertFile:           s.ClientCertFile,
+			KeyFile:            s.ClientKeyFile,
+		},
+		RateLimit: rate,
+		RateBurst: burst,
+		TreeDepth: s.TreeDepth,
+		ReadOnly:  s.ReadOnly,
+		SSH:       s.SSH.options(),
+	}
+}
+
+func (c *SSHConfig) options() *jenkins.SSHOptions {
+	if c == nil {
+		return nil
+	}
+	return &jenkins.SSHOptions{Endpoint: c.Endpoint, IdentityFile: c.IdentityFile}
 }
 
 // UIConfig holds UI preferences
 type UIConfig struct {
-	RefreshInterval int    `json:"refreshInterval"`
-	Theme           string `json:"theme"`
-	CompactMode     bool   `json:"compactMode"`
+	// RefreshInterval is the seconds between queue polls unless the server
+	// section sets its own.
+	RefreshInterval int `json:"refreshInterval"`
+	// Theme names the color palette: dark, high-contrast, deuteranopia or
+	// protanopia.
+	Theme            string `json:"theme"`
+	CompactMode      bool   `json:"compactMode"`
+	IdleAfterMinutes int    `json:"idleAfterMinutes"`
+	// SelectionDebounceMs delays the details fetch after the job cursor moves.
+	SelectionDebounceMs int `json:"selectionDebounceMs"`
+	// PaneMode starts with minimal chrome, as with --pane-mode.
+	PaneMode bool `json:"paneMode"`
+	// DisableTerminalTitle stops jdash from setting the terminal window title.
+	DisableTerminalTitle bool `json:"disableTerminalTitle"`
+	// TmuxStatus publishes a summary to the @jdash_status tmux option.
+	TmuxStatus bool `json:"tmuxStatus"`
+	// ExclusiveExpand collapses a folder's siblings when it is expanded.
+	ExclusiveExpand bool `json:"exclusiveExpand"`
+	// PromotionParameter names a build parameter that marks deployments,
+	// shown like promoted-builds plugin promotions in the build history.
+	PromotionParameter string `json:"promotionParameter"`
+	// DefaultActions choose what Enter does on a job, by full-name pattern.
+	DefaultActions []DefaultActionRule `json:"defaultActions"`
+	// CompatMode is "on", "off" or empty to detect terminals that need ASCII
+	// icons, like the Windows console host.
+	CompatMode string `json:"compatMode"`
+	// CheckForUpdates looks for a newer jdash on GitHub once a day.
+	CheckForUpdates bool `json:"checkForUpdates"`
+	// AbsoluteTimeAfterHours shows dates instead of "3 days ago" for builds
+	// older than this; zero keeps relative times.
+	AbsoluteTimeAfterHours int `json:"absoluteTimeAfterHours"`
+	// DateFormat is the Go time layout of those dates, e.g. "02.01.2006 15:04".
+	DateFormat string `json:"dateFormat"`
+	// Language words relative times: "en" (the default), "de", "es" or "fr".
+	Language string `json:"language"`
+}
+
+// DefaultActionRule maps jobs matching Pattern to the action Enter runs on
+// them: "details" (the default), "build", "parameters", "logs", "history" or
+// "config". Pattern is a path.Match glob against the job full name; a pattern
+// ending in "/" matches every job below that folder.
+type DefaultActionRule struct {
+	Pattern string `json:"pattern"`
+	Action  string `json:"action"`
+}
+
+// DefaultAction returns the action of the first rule matching the job, or ""
+// when none does. Malformed patterns never match.
+func (c UIConfig) DefaultAction(fullName string) string {
+	for _, rule := range c.DefaultActions {
+		if strings.HasSuffix(rule.Pattern, "/") {
+			if strings.HasPrefix(fullName, rule.Pattern) {
+				return rule.Action
+			}
+			continue
+		}
+		if ok, err := path.Match(rule.Pattern, fullName); err == nil && ok {
+			return rule.Action
+		}
+	}
+	return ""
+}
+
+// IdleAfter returns how long the dashboard may sit without input or running
+// builds before polling slows down. Zero means the default; negative disables it.
+func (c UIConfig) IdleAfter() time.Duration {
+	switch {
+	case c.IdleAfterMinutes < 0:
+		return 0
+	case c.IdleAfterMinutes == 0:
+		return activity.DefaultIdleAfter
+	default:
+		return time.Duration(c.IdleAfterMinutes) * time.Minute
+	}
+}
+
+// Compat reports whether to run in compatibility mode, given whether the
+// terminal was detected as needing it. Unknown values fall back to detection.
+func (c UIConfig) Compat(detected bool) bool {
+	switch strings.ToLower(c.CompatMode) {
+	case "on":
+		return true
+	case "off":
+		return false
+	default:
+		return detected
+	}
+}
+
+// RelativeTime returns when timestamps switch from relative times to dates.
+func (c UIConfig) RelativeTime() utils.RelativeTimeOptions {
+	opts := utils.DefaultRelativeTimeOptions
+	if c.AbsoluteTimeAfterHours > 0 {
+		opts.AbsoluteAfter = time.Duration(c.AbsoluteTimeAfterHours) * time.Hour
+	}
+	opts.DateLayout = c.DateFormat
+	opts.Language = c.Language
+	return opts
+}
+
+// defaultSelectionDebounce is long enough to skip jobs passed while holding a
+// navigation key but short enough to feel immediate when stopping on one.
+const defaultSelectionDebounce = 250 * time.Millisecond
+
+// SelectionDebounce returns how long the job cursor must rest before details
+// are fetched. Zero means the default; negative fetches immediately.
+func (c UIConfig) SelectionDebounce() time.Duration {
+	switch {
+	case c.SelectionDebounceMs < 0:
+		return 0
+	case c.SelectionDebounceMs == 0:
+		return defaultSelectionDebounce
+	default:
+		return time.Duration(c.SelectionDebounceMs) * time.Millisecond
+	}
+}
+
+// LogsConfig holds console log preferences
+type LogsConfig struct {
+	// RedactPatterns are extra regular expressions masked in streamed and
+	// exported logs; a group named "secret" limits the mask to that group.
+	RedactPatterns []string `json:"redactPatterns"`
+	// DisableDefaultRedaction drops the built-in AWS/bearer/GitHub/Slack rules.
+	DisableDefaultRedaction bool `json:"disableDefaultRedaction"`
+	// Transforms rewrite noisy console output of matching jobs before it is
+	// shown, e.g. to collapse Maven download lines.
+	Transforms []logtransform.Rule `json:"transforms"`
+}
+
+// RedactionPatterns returns the full list of redaction regexes to apply.
+func (c LogsConfig) RedactionPatterns() []string {
+	var patterns []string
+	if !c.DisableDefaultRedaction {
+		patterns = append(patterns, utils.DefaultRedactionPatterns...)
+	}
+	return append(patterns, c.RedactPatterns...)
 }
 
 // KeyBindings holds custom key bindings
 type KeyBindings struct {
-	Quit    string `json:"quit"`
-	Refresh string `json:"refresh"`
-	Search  string `json:"search"`
-	Build   string `json:"build"`
+	Quit string `json:"quit"`
+	// Refresh reloads the focused panel and RefreshAll everything.
+	Refresh    string `json:"refresh"`
+	RefreshAll string `json:"refreshAll"`
+	Search     string `json:"search"`
+	Build      string `json:"build"`
 }
 
 // Config holds the complete application configuration
 type Config struct {
-	Server      *ServerConfig `json:"server"`
-	UI          UIConfig      `json:"ui"`
-	Keybindings KeyBindings   `json:"keybindings"`
+	Server *ServerConfig `json:"server"`
+	UI     UIConfig      `json:"ui"`
+	Logs   LogsConfig    `json:"logs"`
+	// Confirm chooses which actions ask before running.
+	Confirm     confirm.Config `json:"confirm"`
+	Keybindings KeyBindings    `json:"keybindings"`
 }
 
 var (
@@ -51,20 +337,33 @@ func init() {
 	configFile = filepath.Join(configDir, "config.json")
 }
 
+// ConfigDir returns the directory holding jdash configuration and local state.
+func ConfigDir() string {
+	return configDir
+}
+
+// UseConfigDir keeps configuration and local state in dir instead of
+// ~/.jdash, e.g. a temporary directory in tests.
+func UseConfigDir(dir string) {
+	configDir = dir
+	configFile = filepath.Join(dir, "config.json")
+}
+
 // DefaultConfig returns the default configuration
 func DefaultConfig() Config {
 	return Config{
 		Server: nil,
 		UI: UIConfig{
-			RefreshInterval: 5,
+			RefreshInterval: 3,
 			Theme:           "dark",
 			CompactMode:     false,
 		},
 		Keybindings: KeyBindings{
-			Quit:    "q",
-			Refresh: "r",
-			Search:  "/",
-			Build:   "b",
+			Quit:       "q",
+			Refresh:    "r",
+			RefreshAll: "R",
+			Search:     "/",
+			Build:      "b",
 		},
 	}
 }
@@ -93,12 +392,23 @@ func LoadConfig() (Config, error) {
 
 	// Merge with defaults to ensure all fields exist
 	defaultCfg := DefaultConfig()
+	// Fill in only the unset fields so a partial "ui" section keeps the
+	// user's other settings.
 	if config.UI.RefreshInterval == 0 {
-		config.UI = defaultCfg.UI
+		config.UI.RefreshInterval = defaultCfg.UI.RefreshInterval
+	}
+	if config.UI.Theme == "" {
+		config.UI.Theme = defaultCfg.UI.Theme
 	}
 	if config.Keybindings.Quit == "" {
 		config.Keybindings = defaultCfg.Keybindings
 	}
+	if config.Keybindings.Refresh == "" {
+		config.Keybindings.Refresh = defaultCfg.Keybindings.Refresh
+	}
+	if config.Keybindings.RefreshAll == "" {
+		config.Keybindings.RefreshAll = defaultCfg.Keybindings.RefreshAll
+	}
 
 	return config, nil
 }
@@ -128,13 +438,14 @@ func SaveServerConfig(server ServerConfig) error {
 	return SaveConfig(config)
 }
 
-// HasServerConfig checks if server config exists
+// HasServerConfig checks if server config exists. A server section with
+// only connection settings (proxy, certificates) still needs the auth screen.
 func HasServerConfig() bool {
 	config, err := LoadConfig()
 	if err != nil {
 		return false
 	}
-	return config.Server != nil
+	return config.Server != nil && config.Server.URL != ""
 }
 
 // GetServerConfig retrieves the server config
@@ -148,9 +459,10 @@ func GetServerConfig() (*ServerConfig, error) {
 
 // CreateJenkinsClient creates a Jenkins client from server config
 func CreateJenkinsClient(config *ServerConfig) jenkins.JenkinsClient {
-	return jenkins.NewClient(jenkins.Credentials{
-		URL:      config.URL,
-		Username: config.Username,
-		Token:    config.Token,
-	})
+	return jenkins.NewClient(config.Credentials())
+}
+
+// TokenName returns the name given to API tokens jdash generates at now.
+func TokenName(now time.Time) string {
+	return "jdash-" + now.Format("2006-01-02-1504")
 }
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUIConfigDefaultAction(t *testing.T) {
+	config := UIConfig{DefaultActions: []DefaultActionRule{
+		{Pattern: "Deployments/", Action: "parameters"},
+		{Pattern: "*/nightly-*", Action: "logs"},
+		{Pattern: "[", Action: "build"},
+	}}
+
+	tests := []struct {
+		fullName string
+		want     string
+	}{
+		{fullName: "Deployments/api", want: "parameters"},
+		{fullName: "Deployments/eu/web", want: "parameters"},
+		{fullName: "Team/nightly-e2e", want: "logs"},
+		{fullName: "Team/sub/nightly-e2e", want: ""},
+		{fullName: "DeploymentsOld/api", want: ""},
+		{fullName: "[", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fullName, func(t *testing.T) {
+			if got := config.DefaultAction(tt.fullName); got != tt.want {
+				t.Errorf("DefaultAction(%q) = %q, want %q", tt.fullName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigKeepsPartialUISettings(t *testing.T) {
+	saved := configFile
+	t.Cleanup(func() { configFile = saved })
+	configFile = filepath.Join(t.TempDir(), "config.json")
+
+	// Only the idle period is set; refreshInterval comes from the defaults.
+	if err := os.WriteFile(configFile, []byte(`{"ui":{"idleAfterMinutes":5}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.UI.IdleAfterMinutes != 5 {
+		t.Errorf("IdleAfterMinutes = %d, want 5", config.UI.IdleAfterMinutes)
+	}
+	if want := DefaultConfig().UI.RefreshInterval; config.UI.RefreshInterval != want {
+		t.Errorf("RefreshInterval = %d, want the default %d", config.UI.RefreshInterval, want)
+	}
+}
+
+func TestUIConfigCompat(t *testing.T) {
+	tests := []struct {
+		mode     string
+		detected bool
+		want     bool
+	}{
+		{mode: "", detected: true, want: true},
+		{mode: "", detected: false, want: false},
+		{mode: "on", detected: false, want: true},
+		{mode: "Off", detected: true, want: false},
+		{mode: "sometimes", detected: true, want: true},
+	}
+
+	for _, tt := range tests {
+		config := UIConfig{CompatMode: tt.mode}
+		if got := config.Compat(tt.detected); got != tt.want {
+			t.Errorf("Compat(%v) with compatMode %q = %v, want %v", tt.detected, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestServerConfigRequestRate(t *testing.T) {
+	tests := []struct {
+		config    ServerConfig
+		wantRate  float64
+		wantBurst int
+	}{
+		{config: ServerConfig{}, wantRate: defaultRateLimit, wantBurst: 2 * defaultRateLimit},
+		{config: ServerConfig{RateLimit: 0.5}, wantRate: 0.5, wantBurst: 1},
+		{config: ServerConfig{RateLimit: 3, RateLimitBurst: 10}, wantRate: 3, wantBurst: 10},
+		{config: ServerConfig{RateLimit: -1, RateLimitBurst: 10}, wantRate: 0, wantBurst: 0},
+	}
+
+	for _, tt := range tests {
+		rate, burst := tt.config.RequestRate()
+		if rate != tt.wantRate || burst != tt.wantBurst {
+			t.Errorf("RequestRate() for %+v = %v, %d; want %v, %d", tt.config, rate, burst, tt.wantRate, tt.wantBurst)
+		}
+	}
+}
+
+func TestServerConfigOverrides(t *testing.T) {
+	ui := DefaultConfig().UI
+	server := ServerConfig{URL: "https://jenkins.example.com", RefreshInterval: 15, TreeDepth: 2, ReadOnly: true, Panels: []string{"jobs", "Queue"}}
+	if got := server.PollInterval(ui); got != 15*time.Second {
+		t.Errorf("PollInterval() = %v, want 15s", got)
+	}
+	if got := (ServerConfig{}).PollInterval(UIConfig{RefreshInterval: 10}); got != 10*time.Second {
+		t.Errorf("PollInterval() without an override = %v, want ui.refreshInterval (10s)", got)
+	}
+	if got := (ServerConfig{}).PollInterval(UIConfig{}); got != 0 {
+		t.Errorf("PollInterval() with nothing set = %v, want the default (0)", got)
+	}
+	creds := server.Credentials()
+	if creds.TreeDepth != 2 || !creds.ReadOnly {
+		t.Errorf("Credentials() = depth %d, read-only %v; want 2, true", creds.TreeDepth, creds.ReadOnly)
+	}
+
+	panels, err := server.EnabledPanels()
+	if err != nil || panels != (PanelSet{Queue: true}) {
+		t.Errorf("EnabledPanels() = %+v, %v; want the queue alone beside the jobs", panels, err)
+	}
+	if panels, _ := (ServerConfig{}).EnabledPanels(); panels != (PanelSet{Queue: true, Details: true}) {
+		t.Errorf("EnabledPanels() without a list = %+v, want every panel", panels)
+	}
+	panels, err = ServerConfig{Panels: []string{"details", "console"}}.EnabledPanels()
+	if err == nil || !strings.Contains(err.Error(), `"console"`) || panels != (PanelSet{Details: true}) {
+		t.Errorf("EnabledPanels() with an unknown panel = %+v, %v; want it reported and skipped", panels, err)
+	}
+}
+
+func TestLoadConfigKeybindingDefaults(t *testing.T) {
+	saved := configFile
+	t.Cleanup(func() { configFile = saved })
+	configFile = filepath.Join(t.TempDir(), "config.json")
+
+	// Written before refreshAll existed.
+	data := `{"keybindings":{"quit":"q","refresh":"g","search":"/","build":"b"}}`
+	if err := os.WriteFile(configFile, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Keybindings.Refresh != "g" || config.Keybindings.RefreshAll != "R" {
+		t.Errorf("Keybindings = %+v, want the custom refresh key and the default refreshAll", config.Keybindings)
+	}
+}
@@ -1,14 +1,18 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
 )
 
 // FocusField represents which field is currently focused
@@ -35,12 +39,20 @@ type Model struct {
 	width         int
 	height        int
 	onSuccess     func()
+
+	// usePassword takes a password in the token field and logs in with it
+	// once, to create a dedicated API token that is saved instead.
+	usePassword bool
+	// created is the API token created with the password, once tested.
+	created *jenkins.APIToken
 }
 
 // testResultMsg is sent when connection test completes
 type testResultMsg struct {
 	success bool
 	err     error
+	// created is the API token created in password mode.
+	created *jenkins.APIToken
 }
 
 // saveCompleteMsg is sent when config save completes
@@ -117,6 +129,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
+		case "ctrl+p":
+			m.togglePassword()
+			return m, nil
+
 		case "enter":
 			if m.focusedField == FocusTestButton {
 				return m, m.testConnection()
@@ -128,6 +144,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case testResultMsg:
 		m.testing = false
 		m.testSuccess = msg.success
+		if msg.created != nil {
+			m.created = msg.created
+		}
 		if msg.err != nil {
 			m.error = msg.err.Error()
 		} else {
@@ -237,6 +256,23 @@ func (m *Model) setFocus(field FocusField) {
 	}
 }
 
+// togglePassword switches the token field between an API token and a password
+// to create one with. The field is cleared so neither ends up in the other.
+func (m *Model) togglePassword() {
+	m.usePassword = !m.usePassword
+	m.tokenInput.SetValue("")
+	m.testSuccess = false
+	m.error = ""
+	if m.usePassword {
+		m.tokenInput.Placeholder = "your-password"
+	} else {
+		m.tokenInput.Placeholder = "your-api-token"
+	}
+	if m.focusedField == FocusOkButton {
+		m.setFocus(FocusTestButton)
+	}
+}
+
 // testConnection tests the Jenkins connection
 func (m *Model) testConnection() tea.Cmd {
 	url := strings.TrimSpace(m.urlInput.Value())
@@ -257,14 +293,25 @@ func (m *Model) testConnection() tea.Cmd {
 	m.testing = true
 	m.error = ""
 
+	if m.usePassword {
+		var previous string
+		if m.created != nil {
+			previous = m.created.UUID
+		}
+		return func() tea.Msg {
+			server := connectionSettings()
+			server.URL, server.Username = url, username
+			created, err := createAPIToken(context.Background(), server, token, previous, time.Now())
+			return testResultMsg{success: err == nil, err: err, created: created}
+		}
+	}
+
 	return func() tea.Msg {
-		client := jenkins.NewClient(jenkins.Credentials{
-			URL:      url,
-			Username: username,
-			Token:    token,
-		})
+		server := connectionSettings()
+		server.URL, server.Username, server.Token = url, username, token
+		client := CreateJenkinsClient(&server)
 
-		err := client.TestConnection()
+		err := client.TestConnection(context.Background())
 		return testResultMsg{
 			success: err == nil,
 			err:     err,
@@ -277,17 +324,67 @@ func (m *Model) saveConfig() tea.Cmd {
 	url := strings.TrimSpace(m.urlInput.Value())
 	username := strings.TrimSpace(m.usernameInput.Value())
 	token := strings.TrimSpace(m.tokenInput.Value())
+	var tokenUUID string
+	if m.usePassword && m.created != nil {
+		// The password itself is never saved.
+		token, tokenUUID = m.created.Value, m.created.UUID
+	}
 
 	return func() tea.Msg {
-		err := SaveServerConfig(ServerConfig{
-			URL:      url,
-			Username: username,
-			Token:    token,
-		})
+		server := connectionSettings()
+		server.URL, server.Username, server.Token = url, username, token
+		server.TokenUUID = tokenUUID
+		err := SaveServerConfig(server)
 		return saveCompleteMsg{err: err}
 	}
 }
 
+// createAPIToken logs in to server with password, creates an API token for
+// jdash and checks that it works. The token created by an earlier attempt,
+// identified by previous, is revoked so retries don't leave tokens behind.
+func createAPIToken(ctx context.Context, server ServerConfig, password, previous string, now time.Time) (*jenkins.APIToken, error) {
+	server.Token, server.TokenUUID = password, ""
+	client := CreateJenkinsClient(&server)
+	if previous != "" {
+		_ = client.RevokeAPIToken(ctx, previous)
+	}
+
+	created, err := client.GenerateAPIToken(ctx, TokenName(now))
+	if err != nil {
+		if errors.Is(err, jenkins.ErrUnauthorized) {
+			return nil, fmt.Errorf("authentication failed. Please check your username and password")
+		}
+		return nil, fmt.Errorf("failed to create an API token: %w", err)
+	}
+	if created.Name == "" {
+		created.Name = TokenName(now)
+	}
+
+	server.Token = created.Value
+	if err := CreateJenkinsClient(&server).TestConnection(ctx); err != nil {
+		_ = client.RevokeAPIToken(ctx, created.UUID)
+		return nil, fmt.Errorf("new token failed verification: %w", err)
+	}
+	return created, nil
+}
+
+// connectionSettings returns the proxy, TLS and SSH settings already written to
+// the config, so a Jenkins that needs them can be reached on first login.
+func connectionSettings() ServerConfig {
+	server, err := GetServerConfig()
+	if err != nil || server == nil {
+		return ServerConfig{}
+	}
+	return ServerConfig{
+		Proxy:              server.Proxy,
+		CACertFile:         server.CACertFile,
+		InsecureSkipVerify: server.InsecureSkipVerify,
+		ClientCertFile:     server.ClientCertFile,
+		ClientKeyFile:      server.ClientKeyFile,
+		SSH:                server.SSH,
+	}
+}
+
 // View renders the authentication screen
 func (m Model) View() string {
 	if m.width == 0 {
@@ -297,71 +394,66 @@ func (m Model) View() string {
 	return renderAuthModal(m)
 }
 
-// Style definitions
-var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("12")).
-			MarginBottom(1)
-
-	labelStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("7"))
-
-	labelFocusedStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("14")).
-				Bold(true)
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("9")).
-			Bold(true)
-
-	successStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("10")).
-			Bold(true)
-
-	buttonStyle = lipgloss.NewStyle().
-			Padding(0, 2).
-			Foreground(lipgloss.Color("15"))
-
-	buttonFocusedStyle = lipgloss.NewStyle().
-				Padding(0, 2).
-				Background(lipgloss.Color("14")).
-				Foreground(lipgloss.Color("0")).
-				Bold(true)
-
-	buttonSuccessStyle = lipgloss.NewStyle().
-				Padding(0, 2).
-				Background(lipgloss.Color("10")).
-				Foreground(lipgloss.Color("0")).
-				Bold(true)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("8")).
-			MarginTop(1)
+// formStyles are the styles of the setup form. They are built on use to
+// follow the theme chosen at startup.
+type formStyles struct {
+	title, label, labelFocused, errorText, success    lipgloss.Style
+	button, buttonFocused, buttonSuccess, help, modal lipgloss.Style
+}
 
-	modalStyle = lipgloss.NewStyle().
+func newFormStyles() formStyles {
+	button := lipgloss.NewStyle().Padding(0, 2)
+	return formStyles{
+		title: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(ui.ColorTitle).
+			MarginBottom(1),
+		label: lipgloss.NewStyle().
+			Foreground(ui.ColorText),
+		labelFocused: lipgloss.NewStyle().
+			Foreground(ui.ColorHighlight).
+			Bold(true),
+		errorText: ui.ErrorStyle,
+		success: lipgloss.NewStyle().
+			Foreground(ui.ColorSuccess).
+			Bold(true),
+		button: button.
+			Foreground(ui.ColorText),
+		buttonFocused: button.
+			Background(ui.ColorHighlight).
+			Foreground(ui.ColorTextInverse).
+			Bold(true),
+		buttonSuccess: button.
+			Background(ui.ColorSuccess).
+			Foreground(ui.ColorTextInverse),
+		help: lipgloss.NewStyle().
+			Foreground(ui.ColorSubtle).
+			MarginTop(1),
+		modal: lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("12")).
+			BorderForeground(ui.ColorTitle).
 			Padding(1, 2).
-			Width(70)
-)
+			Width(70),
+	}
+}
 
 // renderAuthModal renders the authentication modal
 func renderAuthModal(m Model) string {
+	styles := newFormStyles()
 	var b strings.Builder
 
 	// Title
-	b.WriteString(titleStyle.Render("Jenkins Authentication"))
+	b.WriteString(styles.title.Render("Jenkins Authentication"))
 	b.WriteString("\n\n")
 
 	// URL field
 	urlLabel := "Server URL"
 	if m.focusedField == FocusURL {
-		b.WriteString(labelFocusedStyle.Render(urlLabel))
+		b.WriteString(styles.labelFocused.Render(urlLabel))
 	} else {
-		b.WriteString(labelStyle.Render(urlLabel))
+		b.WriteString(styles.label.Render(urlLabel))
 	}
-	b.WriteString(labelStyle.Render(" (e.g., https://jenkins.example.com)"))
+	b.WriteString(styles.label.Render(" (e.g., https://jenkins.example.com)"))
 	b.WriteString("\n")
 	b.WriteString(m.urlInput.View())
 	b.WriteString("\n\n")
@@ -369,9 +461,9 @@ func renderAuthModal(m Model) string {
 	// Username field
 	usernameLabel := "Username"
 	if m.focusedField == FocusUsername {
-		b.WriteString(labelFocusedStyle.Render(usernameLabel))
+		b.WriteString(styles.labelFocused.Render(usernameLabel))
 	} else {
-		b.WriteString(labelStyle.Render(usernameLabel))
+		b.WriteString(styles.label.Render(usernameLabel))
 	}
 	b.WriteString("\n")
 	b.WriteString(m.usernameInput.View())
@@ -379,10 +471,16 @@ func renderAuthModal(m Model) string {
 
 	// Token field
 	tokenLabel := "API Token"
+	if m.usePassword {
+		tokenLabel = "Password"
+	}
 	if m.focusedField == FocusToken {
-		b.WriteString(labelFocusedStyle.Render(tokenLabel))
+		b.WriteString(styles.labelFocused.Render(tokenLabel))
 	} else {
-		b.WriteString(labelStyle.Render(tokenLabel))
+		b.WriteString(styles.label.Render(tokenLabel))
+	}
+	if m.usePassword {
+		b.WriteString(styles.label.Render(" (used once to create a jdash API token)"))
 	}
 	b.WriteString("\n")
 	b.WriteString(m.tokenInput.View())
@@ -391,11 +489,18 @@ func renderAuthModal(m Model) string {
 	// Status messages
 	if m.testing {
 		b.WriteString(m.spinner.View())
-		b.WriteString(" Testing connection...")
+		if m.usePassword {
+			b.WriteString(" Creating API token...")
+		} else {
+			b.WriteString(" Testing connection...")
+		}
 	} else if m.error != "" {
-		b.WriteString(errorStyle.Render("✗ " + m.error))
+		// Outcomes are told apart by icon and wording, not only by color.
+		b.WriteString(styles.errorText.Render(ui.IconFailed + " Error: " + m.error))
+	} else if m.testSuccess && m.usePassword && m.created != nil {
+		b.WriteString(styles.success.Render(ui.IconSuccess + " Created API token " + m.created.Name))
 	} else if m.testSuccess {
-		b.WriteString(successStyle.Render("✓ Connection successful!"))
+		b.WriteString(styles.success.Render(ui.IconSuccess + " Connection successful!"))
 	}
 	b.WriteString("\n\n")
 
@@ -403,29 +508,25 @@ func renderAuthModal(m Model) string {
 	buttonRow := ""
 	if !m.testSuccess {
 		if m.focusedField == FocusTestButton {
-			buttonRow = buttonFocusedStyle.Render("[ Test Connection ]")
+			buttonRow = styles.buttonFocused.Render("[ Test Connection ]")
 		} else {
-			buttonRow = buttonStyle.Render("[ Test Connection ]")
+			buttonRow = styles.button.Render("[ Test Connection ]")
 		}
 	} else {
 		if m.focusedField == FocusOkButton {
-			buttonRow = buttonSuccessStyle.Render("[ OK ]")
+			buttonRow = styles.buttonSuccess.Bold(true).Render("[ OK ]")
 		} else {
-			buttonStyle := lipgloss.NewStyle().
-				Padding(0, 2).
-				Background(lipgloss.Color("10")).
-				Foreground(lipgloss.Color("15"))
-			buttonRow = buttonStyle.Render("[ OK ]")
+			buttonRow = styles.buttonSuccess.Render("[ OK ]")
 		}
 	}
 	b.WriteString(lipgloss.NewStyle().Width(70).Align(lipgloss.Center).Render(buttonRow))
 	b.WriteString("\n")
 
 	// Help text
-	b.WriteString(helpStyle.Render("Tab: Navigate | Enter: Select | Esc: Quit"))
+	b.WriteString(styles.help.Render("Tab: Navigate | Enter: Select | Ctrl+P: Password/token | Esc: Quit"))
 
 	// Wrap in modal
-	content := modalStyle.Render(b.String())
+	content := styles.modal.Render(b.String())
 
 	// Center on screen
 	return lipgloss.Place(
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestCreateAPIToken(t *testing.T) {
+	var names, revoked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, secret, _ := r.BasicAuth()
+		switch {
+		case user != "alice":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/me/descriptorByName/jenkins.security.ApiTokenProperty/generateNewToken" && secret == "hunter2":
+			names = append(names, r.FormValue("newTokenName"))
+			fmt.Fprintf(w, `{"status":"ok","data":{"tokenName":%q,"tokenUuid":"uuid-%d","tokenValue":"11token%d"}}`,
+				r.FormValue("newTokenName"), len(names), len(names))
+		case r.URL.Path == "/me/descriptorByName/jenkins.security.ApiTokenProperty/revoke" && secret == "hunter2":
+			revoked = append(revoked, r.FormValue("tokenUuid"))
+		case r.URL.Path == "/api/json" && secret == "11token2":
+			fmt.Fprint(w, `{}`)
+		case r.URL.Path == "/crumbIssuer/api/json":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	now := time.Date(2026, 3, 14, 9, 26, 0, 0, time.UTC)
+	config := ServerConfig{URL: server.URL, Username: "alice"}
+
+	if _, err := createAPIToken(ctx, config, "wrong", "", now); err == nil {
+		t.Fatal("createAPIToken() with a wrong password returned no error")
+	}
+
+	// The first token fails verification and is revoked again.
+	if _, err := createAPIToken(ctx, config, "hunter2", "", now); err == nil {
+		t.Fatal("createAPIToken() returned a token that does not work")
+	}
+	if len(revoked) != 1 || revoked[0] != "uuid-1" {
+		t.Fatalf("revoked = %v, want the unverified token", revoked)
+	}
+
+	created, err := createAPIToken(ctx, config, "hunter2", "uuid-0", now)
+	if err != nil {
+		t.Fatalf("createAPIToken() error = %v", err)
+	}
+	if created.Name != "jdash-2026-03-14-0926" || created.UUID != "uuid-2" || created.Value != "11token2" {
+		t.Errorf("createAPIToken() = %+v", created)
+	}
+	if len(revoked) != 2 || revoked[1] != "uuid-0" {
+		t.Errorf("revoked = %v, want the earlier attempt's token", revoked)
+	}
+}
+
+func TestFormOutcomesDoNotRelyOnColor(t *testing.T) {
+	updated, _ := New().Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m := updated.(Model)
+	m.error = "connection refused"
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "✗ Error: connection refused") {
+		t.Errorf("failed test not labeled as an error:\n%s", view)
+	}
+	m.error, m.testSuccess = "", true
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "✓ Connection successful!") || !strings.Contains(view, "[ OK ]") {
+		t.Errorf("successful test not labeled as such:\n%s", view)
+	}
+}
@@ -0,0 +1,144 @@
+// Package bookmarks persists marked lines and the last read position of
+// build console logs, so a long log can be analyzed over several sittings.
+package bookmarks
+
+import (
+	"maps"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jsonstore"
+)
+
+const fileName = "bookmarks.json"
+
+// maxBuilds caps how many logs are remembered; the least recently touched
+// are forgotten first, as their builds are usually rotated away by then.
+const maxBuilds = 500
+
+// Log is what is remembered about one build log. Lines are 1-based.
+type Log struct {
+	// Marks are the bookmarked lines, in ascending order.
+	Marks []int `json:"marks,omitempty"`
+	// Line is where reading stopped; zero means the log was being tailed.
+	Line    int       `json:"line,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// Store keeps bookmarks per build (keyed by build URL) in memory and
+// persists them to the config directory. It is safe for concurrent use.
+type Store struct {
+	mu   sync.RWMutex
+	file *jsonstore.File
+	logs map[string]Log
+}
+
+type storeFile struct {
+	Builds map[string]Log `json:"builds"`
+}
+
+// Load reads bookmarks from bookmarks.json in the config directory.
+func Load() (*Store, error) {
+	return LoadFrom(filepath.Join(auth.ConfigDir(), fileName))
+}
+
+// LoadFrom reads bookmarks from path, starting empty when the file is
+// missing or unreadable.
+func LoadFrom(path string) (*Store, error) {
+	var saved storeFile
+	file, err := jsonstore.Load(path, &saved)
+	store := &Store{file: file, logs: make(map[string]Log, len(saved.Builds))}
+	maps.Copy(store.logs, saved.Builds)
+	return store, err
+}
+
+// Get returns what is remembered about a build log.
+func (s *Store) Get(key string) Log {
+	if s == nil {
+		return Log{}
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	log := s.logs[key]
+	log.Marks = slices.Clone(log.Marks)
+	return log
+}
+
+// SetMarks replaces the bookmarked lines of a build log and persists the store.
+func (s *Store) SetMarks(key string, marks []int) error {
+	marks = slices.Clone(marks)
+	sort.Ints(marks)
+	return s.update(key, func(log *Log) bool {
+		if slices.Equal(log.Marks, marks) {
+			return false
+		}
+		log.Marks = marks
+		return true
+	})
+}
+
+// SetLine records where reading of a build log stopped (zero while tailing)
+// and persists the store if it moved.
+func (s *Store) SetLine(key string, line int) error {
+	return s.update(key, func(log *Log) bool {
+		if log.Line == line {
+			return false
+		}
+		log.Line = line
+		return true
+	})
+}
+
+// update applies change to a build log and saves the store when it reports
+// a change. Logs left without marks or position are dropped.
+func (s *Store) update(key string, change func(*Log) bool) error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	log := s.logs[key]
+	if !change(&log) {
+		s.mu.Unlock()
+		return nil
+	}
+	if len(log.Marks) == 0 && log.Line == 0 {
+		delete(s.logs, key)
+	} else {
+		log.Updated = time.Now()
+		s.logs[key] = log
+		s.prune()
+	}
+	s.mu.Unlock()
+	return s.save()
+}
+
+// prune forgets the least recently updated logs beyond maxBuilds. The
+// caller holds the write lock.
+func (s *Store) prune() {
+	if len(s.logs) <= maxBuilds {
+		return
+	}
+	keys := make([]string, 0, len(s.logs))
+	for key := range s.logs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.logs[keys[i]].Updated.After(s.logs[keys[j]].Updated)
+	})
+	for _, key := range keys[maxBuilds:] {
+		delete(s.logs, key)
+	}
+}
+
+func (s *Store) save() error {
+	return s.file.Save(func() any {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return storeFile{Builds: maps.Clone(s.logs)}
+	})
+}
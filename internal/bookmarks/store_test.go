@@ -0,0 +1,63 @@
+package bookmarks
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.json")
+	const build = "https://jenkins.example.com/job/api/42/"
+
+	store, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() on missing file error: %v", err)
+	}
+	if err := store.SetMarks(build, []int{812, 40}); err != nil {
+		t.Fatalf("SetMarks() error: %v", err)
+	}
+	if err := store.SetLine(build, 790); err != nil {
+		t.Fatalf("SetLine() error: %v", err)
+	}
+	if err := store.SetLine("https://jenkins.example.com/job/web/7/", 12); err != nil {
+		t.Fatalf("SetLine() error: %v", err)
+	}
+	if err := store.SetLine("https://jenkins.example.com/job/web/7/", 0); err != nil {
+		t.Fatalf("SetLine() back to tailing error: %v", err)
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error: %v", err)
+	}
+	log := reloaded.Get(build)
+	if !slices.Equal(log.Marks, []int{40, 812}) || log.Line != 790 {
+		t.Errorf("Get() = marks %v, line %d; want [40 812], 790", log.Marks, log.Line)
+	}
+	if len(reloaded.logs) != 1 {
+		t.Errorf("store kept %d logs, want the tailed one dropped", len(reloaded.logs))
+	}
+}
+
+func TestStorePrunesOldestLogs(t *testing.T) {
+	store, err := LoadFrom(filepath.Join(t.TempDir(), "bookmarks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i <= maxBuilds; i++ {
+		if err := store.SetLine(fmt.Sprintf("build-%d", i), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(store.logs) != maxBuilds {
+		t.Fatalf("store holds %d logs, want %d", len(store.logs), maxBuilds)
+	}
+	if store.Get("build-0").Line != 0 {
+		t.Error("the oldest log was kept")
+	}
+	if store.Get(fmt.Sprintf("build-%d", maxBuilds)).Line != 1 {
+		t.Error("the newest log was dropped")
+	}
+}
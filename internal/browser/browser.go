@@ -0,0 +1,34 @@
+// Package browser opens Jenkins pages in the user's web browser.
+package browser
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+)
+
+// Open opens rawURL with the platform's opener. It returns once the opener
+// started rather than waiting for it, as some only exit with the browser.
+func Open(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("not a web address: %q", rawURL)
+	}
+
+	// openerCommand is defined per platform.
+	name, args := openerCommand(u.String())
+	if name == "" {
+		return fmt.Errorf("no browser opener available on %s", runtime.GOOS)
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("browser opener %q not found: %w", name, err)
+	}
+	// Output is discarded so the opener cannot draw over the dashboard.
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
@@ -0,0 +1,13 @@
+package browser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOpenerCommandDarwin(t *testing.T) {
+	name, args := openerCommand("https://ci.example.com/job/api/?a=1&b=2")
+	if name != "open" || !slices.Equal(args, []string{"https://ci.example.com/job/api/?a=1&b=2"}) {
+		t.Errorf("opener = %q %q, want open with the URL as is", name, args)
+	}
+}
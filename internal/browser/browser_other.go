@@ -0,0 +1,7 @@
+//go:build !darwin && !windows && !linux && !freebsd && !openbsd && !netbsd
+
+package browser
+
+func openerCommand(url string) (string, []string) {
+	return "", nil
+}
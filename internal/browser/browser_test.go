@@ -0,0 +1,11 @@
+package browser
+
+import "testing"
+
+func TestOpenRejectsNonWebAddresses(t *testing.T) {
+	for _, rawURL := range []string{"", "file:///etc/passwd", "javascript:alert(1)", "ci.example.com/job/api/"} {
+		if err := Open(rawURL); err == nil {
+			t.Errorf("Open(%q) succeeded, want it rejected", rawURL)
+		}
+	}
+}
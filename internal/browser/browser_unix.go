@@ -0,0 +1,7 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package browser
+
+func openerCommand(url string) (string, []string) {
+	return "xdg-open", []string{url}
+}
@@ -0,0 +1,15 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package browser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOpenerCommandUnix(t *testing.T) {
+	name, args := openerCommand("https://ci.example.com/job/api/?a=1&b=2")
+	if name != "xdg-open" || !slices.Equal(args, []string{"https://ci.example.com/job/api/?a=1&b=2"}) {
+		t.Errorf("opener = %q %q, want xdg-open with the URL as is", name, args)
+	}
+}
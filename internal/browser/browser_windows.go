@@ -0,0 +1,10 @@
+package browser
+
+import "strings"
+
+// openerCommand runs start through cmd, which would otherwise take the & of
+// a query string for a command separator; the empty argument is the title
+// start expects before a quoted target.
+func openerCommand(url string) (string, []string) {
+	return "cmd", []string{"/c", "start", "", strings.ReplaceAll(url, "&", "^&")}
+}
@@ -0,0 +1,18 @@
+package browser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOpenerCommandWindows(t *testing.T) {
+	name, args := openerCommand("https://ci.example.com/job/api/?a=1&b=2")
+	if name != "cmd" {
+		t.Fatalf("opener = %q, want cmd", name)
+	}
+	// The & would end the start command; the empty title keeps the URL the target.
+	want := []string{"/c", "start", "", "https://ci.example.com/job/api/?a=1^&b=2"}
+	if !slices.Equal(args, want) {
+		t.Errorf("args = %q, want %q", args, want)
+	}
+}
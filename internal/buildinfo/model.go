@@ -0,0 +1,252 @@
+// Package buildinfo is a modal that sets the display name and description of
+// a build, so it can be annotated (e.g. "hotfix for prod incident").
+package buildinfo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/inflight"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+const modalWidth = 72
+
+// descriptionHeight is how many lines of the description are shown at once.
+const descriptionHeight = 5
+
+// ClosedMsg is emitted when the modal is dismissed. Saved is set when the
+// build was changed, so the details can be reloaded.
+type ClosedMsg struct {
+	JobFullName string
+	BuildNumber int
+	Saved       bool
+}
+
+type savedMsg struct {
+	err error
+}
+
+const (
+	fieldName = iota
+	fieldDescription
+	fieldCount
+)
+
+// Model edits the display name and description of one build. Descriptions
+// often span lines, so theirs is a text area.
+type Model struct {
+	client      jenkins.JenkinsClient
+	tracker     *inflight.Tracker
+	job         string
+	build       int
+	name        textinput.Model
+	description textarea.Model
+	focus       int
+	spinner     spinner.Model
+	saving      bool
+	err         error
+
+	// The values the build had, to skip requests for unchanged fields.
+	origName        string
+	origDescription string
+
+	width  int
+	height int
+}
+
+// New creates a modal for build of the job jobFullName, pre-filled with its
+// custom display name and description. Saving is registered with tracker so
+// quitting can wait for it.
+func New(client jenkins.JenkinsClient, tracker *inflight.Tracker, jobFullName string, build jenkins.Build) *Model {
+	name := textinput.New()
+	name.Prompt = "Name: "
+	name.Placeholder = fmt.Sprintf("#%d", build.Number)
+	name.CharLimit = 0
+	name.Width = modalWidth - 22
+	name.SetValue(build.CustomDisplayName())
+
+	description := textarea.New()
+	description.Placeholder = "e.g. hotfix for prod incident"
+	description.ShowLineNumbers = false
+	description.CharLimit = 0
+	description.SetWidth(modalWidth - 6)
+	description.SetHeight(descriptionHeight)
+	description.SetValue(build.Description)
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.HighlightStyle
+
+	return &Model{
+		client:          client,
+		job:             jobFullName,
+		build:           build.Number,
+		name:            name,
+		description:     description,
+		focus:           fieldDescription,
+		spinner:         s,
+		origName:        name.Value(),
+		origDescription: description.Value(),
+	}
+}
+
+// CapturesInput reports that plain keys are typed into the fields unless a
+// save is in flight.
+func (m *Model) CapturesInput() bool {
+	return !m.saving
+}
+
+// Init focuses the description, the field most often edited.
+func (m *Model) Init() tea.Cmd {
+	return m.description.Focus()
+}
+
+// Update handles TEA messages for the modal.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case savedMsg:
+		m.saving = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		return m, m.closeCmd(true)
+
+	case spinner.TickMsg:
+		if !m.saving {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		if m.saving {
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			return m, m.closeCmd(false)
+		case "tab":
+			return m, m.setFocus((m.focus + 1) % fieldCount)
+		case "shift+tab":
+			return m, m.setFocus((m.focus + fieldCount - 1) % fieldCount)
+		case "ctrl+s":
+			return m, m.save()
+		case "enter", "down":
+			// In the description these start or move between lines.
+			if m.focus == fieldName {
+				if msg.String() == "enter" {
+					return m, m.save()
+				}
+				return m, m.setFocus(fieldDescription)
+			}
+		case "up":
+			if m.focus == fieldDescription && m.description.Line() == 0 {
+				return m, m.setFocus(fieldName)
+			}
+		}
+	}
+
+	if m.saving {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	if m.focus == fieldName {
+		m.name, cmd = m.name.Update(msg)
+	} else {
+		m.description, cmd = m.description.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m *Model) setFocus(field int) tea.Cmd {
+	m.focus = field
+	if field == fieldName {
+		m.description.Blur()
+		return m.name.Focus()
+	}
+	m.name.Blur()
+	return m.description.Focus()
+}
+
+// save sends only what changed: a description alone goes through
+// SetBuildDescription, a new name through SetBuildDisplayName, which saves
+// both.
+func (m *Model) save() tea.Cmd {
+	name := strings.TrimSpace(m.name.Value())
+	description := m.description.Value()
+	if name == m.origName && description == m.origDescription {
+		return m.closeCmd(false)
+	}
+
+	m.saving = true
+	m.err = nil
+	client, job, build := m.client, m.job, m.build
+	nameChanged := name != m.origName
+	ctx, done := m.tracker.Start(fmt.Sprintf("Edit %s #%d", job, build))
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		var err error
+		switch {
+		case client == nil:
+			err = fmt.Errorf("Jenkins client not configured")
+		case nameChanged:
+			err = client.SetBuildDisplayName(ctx, job, build, name, description)
+		default:
+			err = client.SetBuildDescription(ctx, job, build, description)
+		}
+		done(err)
+		return savedMsg{err: err}
+	})
+}
+
+func (m *Model) closeCmd(saved bool) tea.Cmd {
+	closed := ClosedMsg{JobFullName: m.job, BuildNumber: m.build, Saved: saved}
+	return func() tea.Msg { return closed }
+}
+
+// View renders the modal.
+func (m *Model) View() string {
+	var content strings.Builder
+	content.WriteString(ui.TitleStyle.Render(fmt.Sprintf("Edit Build #%d", m.build)))
+	content.WriteString("\n\n")
+	content.WriteString(fmt.Sprintf("Job: %s\n\n", m.job))
+	content.WriteString(m.name.View())
+	content.WriteString("\n\nDescription:\n")
+	content.WriteString(m.description.View())
+	content.WriteString("\n\n")
+
+	if m.saving {
+		content.WriteString(fmt.Sprintf("%s Saving...", m.spinner.View()))
+	} else {
+		if m.err != nil {
+			content.WriteString(ui.ErrorStyle.Render("Save failed: " + m.err.Error()))
+			content.WriteString("\n\n")
+		}
+		content.WriteString(ui.SubtleStyle.Render("[Tab] Next field  [Ctrl+S] Save  [Esc] Cancel"))
+	}
+
+	panel := lipgloss.NewStyle().
+		Width(modalWidth).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(content.String())
+
+	if m.width == 0 || m.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
+}
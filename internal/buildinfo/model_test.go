@@ -0,0 +1,112 @@
+package buildinfo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// saveAndClose saves the modal and feeds it the save's result, returning the
+// message it closes with.
+func saveAndClose(t *testing.T, m *Model) ClosedMsg {
+	t.Helper()
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd == nil {
+		t.Fatal("Ctrl+S did nothing")
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		// The batch holds the spinner and the save; run the save.
+		_, cmd = m.Update(batch[1]())
+		if cmd == nil {
+			t.Fatalf("save failed:\n%s", m.View())
+		}
+		msg = cmd()
+	}
+	closed, ok := msg.(ClosedMsg)
+	if !ok {
+		t.Fatalf("modal sent %T, want ClosedMsg", msg)
+	}
+	return closed
+}
+
+func TestDescriptionKeepsItsLines(t *testing.T) {
+	client := &jenkinstest.Client{}
+	build := jenkins.Build{Number: 7, Description: "Deployed to prod\nTicket: OPS-12"}
+	m := New(client, nil, "team/api", build)
+	m.Init()
+
+	// Enter in the description starts a line rather than saving.
+	m.description.CursorEnd()
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.saving || m.description.LineCount() != 3 {
+		t.Fatalf("Enter in the description saved or kept %d lines, want a new line", m.description.LineCount())
+	}
+	m.Update(runes("Rolled back"))
+
+	if closed := saveAndClose(t, m); !closed.Saved || closed.BuildNumber != 7 {
+		t.Errorf("closed with %+v, want build 7 saved", closed)
+	}
+	calls := client.CallsTo("SetBuildDescription")
+	if len(calls) != 1 || calls[0].Args[2] != "Deployed to prod\nTicket: OPS-12\nRolled back" {
+		t.Errorf("SetBuildDescription calls = %q, want the three lines", calls)
+	}
+}
+
+func TestRenamingKeepsTheDescription(t *testing.T) {
+	client := &jenkinstest.Client{}
+	build := jenkins.Build{Number: 7, Description: "Deployed to prod\nTicket: OPS-12"}
+	m := New(client, nil, "team/api", build)
+	m.Init()
+
+	m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	m.Update(runes("hotfix"))
+	saveAndClose(t, m)
+
+	calls := client.CallsTo("SetBuildDisplayName")
+	if len(calls) != 1 || calls[0].Args[2] != "hotfix" || calls[0].Args[3] != build.Description {
+		t.Errorf("SetBuildDisplayName calls = %q, want hotfix with the description as it was", calls)
+	}
+	if calls := client.CallsTo("SetBuildDescription"); len(calls) != 0 {
+		t.Errorf("SetBuildDescription calls = %v; the rename saves both", calls)
+	}
+}
+
+func TestUnchangedBuildClosesWithoutSaving(t *testing.T) {
+	client := &jenkinstest.Client{}
+	m := New(client, nil, "api", jenkins.Build{Number: 3, Description: "nightly"})
+	m.Init()
+
+	if closed := saveAndClose(t, m); closed.Saved {
+		t.Error("closing an unchanged build reports a save")
+	}
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Errorf("unchanged build called Jenkins: %v", calls)
+	}
+}
+
+func TestFailedSaveStaysOpen(t *testing.T) {
+	client := &jenkinstest.Client{
+		SetBuildDescriptionFunc: func(context.Context, string, int, string) error { return errors.New("status 403") },
+	}
+	m := New(client, nil, "api", jenkins.Build{Number: 3})
+	m.Init()
+	m.Update(runes("flaky"))
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if _, cmd = m.Update(cmd().(tea.BatchMsg)[1]()); cmd != nil {
+		t.Fatal("a failed save closed the modal")
+	}
+	if view := m.View(); !strings.Contains(view, "Save failed: status 403") {
+		t.Errorf("view does not show the failure:\n%s", view)
+	}
+}
@@ -0,0 +1,369 @@
+// Package bulk is a modal that applies one action to the jobs marked in the
+// jobs panel: it lists them for confirmation, runs the action job by job and
+// sums up what worked.
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/inflight"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+const modalWidth = 64
+
+// listedJobs is how many jobs the summary names before "and N more".
+const listedJobs = 8
+
+// Action is what the modal does to every job.
+type Action string
+
+const (
+	// ActionBuild triggers a build, with the default parameter values of
+	// parameterized jobs.
+	ActionBuild Action = "build"
+	// ActionDisable and ActionEnable stop and resume building.
+	ActionDisable Action = "disable"
+	ActionEnable  Action = "enable"
+	// ActionFavorite adds the jobs to the favorites, which the jobs panel
+	// keeps; the modal only asks.
+	ActionFavorite Action = "favorite"
+)
+
+// ClosedMsg is emitted when the modal is dismissed. Confirmed is false when
+// the user said no; Done lists the jobs the action succeeded on.
+type ClosedMsg struct {
+	Action    Action
+	Confirmed bool
+	Done      []string
+}
+
+// jobDoneMsg reports the outcome of the action on jobs[index].
+type jobDoneMsg struct {
+	index int
+	err   error
+}
+
+type state int
+
+const (
+	stateConfirm state = iota
+	stateRunning
+	stateDone
+)
+
+// Model confirms a bulk action and runs it one job at a time, so a slow or
+// overloaded Jenkins sees one request at a time.
+type Model struct {
+	client  jenkins.JenkinsClient
+	tracker *inflight.Tracker
+	action  Action
+	jobs    []jenkins.Job
+	spinner spinner.Model
+	state   state
+	// protected are the jobs the confirmation policy wants typed out before
+	// the action runs, and prompt asks for protected[typed].
+	protected []jenkins.Job
+	typed     int
+	prompt    confirm.Prompt
+	// errs holds the outcome of each job run so far, nil for success.
+	errs []error
+
+	width  int
+	height int
+}
+
+// New creates the modal for action on jobs. Requests are registered with
+// tracker so quitting can wait for them.
+func New(client jenkins.JenkinsClient, tracker *inflight.Tracker, action Action, jobs []jenkins.Job) *Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.HighlightStyle
+	return &Model{client: client, tracker: tracker, action: action, jobs: jobs, spinner: s}
+}
+
+// WithConfirmPolicy returns the modal asking for the names of the jobs the
+// policy makes the user type before the action; the others only need the
+// modal's y/N. Without it the default policy applies.
+func (m *Model) WithConfirmPolicy(policy confirm.Policy) *Model {
+	m.protected = nil
+	if action, ok := m.action.confirmAction(); ok {
+		for _, job := range m.jobs {
+			if policy.LevelFor(action, job.FullName) == confirm.TypeName {
+				m.protected = append(m.protected, job)
+			}
+		}
+	}
+	m.typed = 0
+	if len(m.protected) > 0 {
+		m.prompt = m.namePrompt(0)
+	}
+	return m
+}
+
+// namePrompt asks for the name of protected[i].
+func (m *Model) namePrompt(i int) confirm.Prompt {
+	job := m.protected[i]
+	question := fmt.Sprintf("%s is protected (%d of %d).", job.FullName, i+1, len(m.protected))
+	return confirm.NewPrompt(confirm.TypeName, question, job.Name)
+}
+
+// CapturesInput reports whether a job name is being typed, so global
+// shortcuts must not fire.
+func (m *Model) CapturesInput() bool {
+	return m.state == stateConfirm && len(m.protected) > 0
+}
+
+// Init implements tea.Model.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles TEA messages for the modal.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case jobDoneMsg:
+		m.errs = append(m.errs, msg.err)
+		if next := msg.index + 1; next < len(m.jobs) {
+			return m, m.runCmd(next)
+		}
+		m.state = stateDone
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state != stateRunning {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		switch m.state {
+		case stateConfirm:
+			if len(m.protected) > 0 {
+				return m.typeName(msg)
+			}
+			switch msg.String() {
+			case "y", "Y":
+				return m.start()
+			case "n", "N", "esc":
+				return m, m.closeCmd(false, nil)
+			}
+		case stateDone:
+			switch msg.String() {
+			case "enter", "esc":
+				return m, m.closeCmd(true, m.succeeded())
+			}
+		}
+	}
+	return m, nil
+}
+
+// typeName feeds a key to the prompt for the next protected job name and
+// starts the action once all of them are typed.
+func (m *Model) typeName(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	prompt, result := m.prompt.HandleKey(msg)
+	m.prompt = prompt
+	switch result {
+	case confirm.Cancelled:
+		return m, m.closeCmd(false, nil)
+	case confirm.Confirmed:
+		m.typed++
+		if m.typed < len(m.protected) {
+			m.prompt = m.namePrompt(m.typed)
+			return m, nil
+		}
+		return m.start()
+	}
+	return m, nil
+}
+
+// start runs the confirmed action, one job at a time.
+func (m *Model) start() (tea.Model, tea.Cmd) {
+	if m.action == ActionFavorite || len(m.jobs) == 0 {
+		return m, m.closeCmd(true, m.names())
+	}
+	m.state = stateRunning
+	return m, tea.Batch(m.spinner.Tick, m.runCmd(0))
+}
+
+// runCmd applies the action to jobs[index].
+func (m *Model) runCmd(index int) tea.Cmd {
+	client, action, job := m.client, m.action, m.jobs[index]
+	ctx, done := m.tracker.Start(fmt.Sprintf("%s %s", action.verb(), job.FullName))
+	return func() tea.Msg {
+		err := run(ctx, client, action, job.FullName)
+		done(err)
+		return jobDoneMsg{index: index, err: err}
+	}
+}
+
+func run(ctx context.Context, client jenkins.JenkinsClient, action Action, fullName string) error {
+	if client == nil {
+		return fmt.Errorf("Jenkins client not configured")
+	}
+	switch action {
+	case ActionDisable:
+		return client.DisableJob(ctx, fullName)
+	case ActionEnable:
+		return client.EnableJob(ctx, fullName)
+	case ActionBuild:
+		// A plain build request fails for parameterized jobs; without values,
+		// buildWithParameters takes the defaults.
+		details, err := client.GetJobDetails(ctx, fullName, 1)
+		if err != nil {
+			return err
+		}
+		if len(details.ParameterDefinitions) > 0 {
+			_, err = client.TriggerBuildWithParameters(ctx, fullName, nil)
+		} else {
+			_, err = client.TriggerBuild(ctx, fullName)
+		}
+		return err
+	}
+	return fmt.Errorf("unknown action %q", action)
+}
+
+func (m *Model) closeCmd(confirmed bool, done []string) tea.Cmd {
+	msg := ClosedMsg{Action: m.action, Confirmed: confirmed, Done: done}
+	return func() tea.Msg { return msg }
+}
+
+func (m *Model) names() []string {
+	names := make([]string, len(m.jobs))
+	for i, job := range m.jobs {
+		names[i] = job.FullName
+	}
+	return names
+}
+
+func (m *Model) succeeded() []string {
+	var names []string
+	for i, err := range m.errs {
+		if err == nil {
+			names = append(names, m.jobs[i].FullName)
+		}
+	}
+	return names
+}
+
+// confirmAction is a as the confirmation policy names it. Enabling
+// and favoriting have none: the modal's y/N is enough for them.
+func (a Action) confirmAction() (confirm.Action, bool) {
+	switch a {
+	case ActionBuild:
+		return confirm.ActionTrigger, true
+	case ActionDisable:
+		return confirm.ActionDisable, true
+	default:
+		return "", false
+	}
+}
+
+// verb names the action for one job, e.g. "Disable".
+func (a Action) verb() string {
+	switch a {
+	case ActionBuild:
+		return "Build"
+	case ActionDisable:
+		return "Disable"
+	case ActionEnable:
+		return "Enable"
+	default:
+		return "Add to favorites:"
+	}
+}
+
+// title names the action for n jobs, e.g. "Disable 3 jobs".
+func (a Action) title(n int) string {
+	jobs := fmt.Sprintf("%d jobs", n)
+	if n == 1 {
+		jobs = "1 job"
+	}
+	if a == ActionFavorite {
+		return fmt.Sprintf("Add %s to favorites", jobs)
+	}
+	return a.verb() + " " + jobs
+}
+
+// View renders the modal.
+func (m *Model) View() string {
+	var content strings.Builder
+	content.WriteString(ui.TitleStyle.Render(m.action.title(len(m.jobs))))
+	content.WriteString("\n\n")
+
+	switch m.state {
+	case stateConfirm:
+		for i, job := range m.jobs {
+			if i == listedJobs {
+				content.WriteString(ui.SubtleStyle.Render(fmt.Sprintf("  ... and %d more", len(m.jobs)-listedJobs)))
+				content.WriteString("\n")
+				break
+			}
+			content.WriteString("  " + job.FullName + "\n")
+		}
+		content.WriteString("\n")
+		content.WriteString(ui.HighlightStyle.Render(m.action.title(len(m.jobs)) + "?"))
+		content.WriteString("\n")
+		if len(m.protected) > 0 {
+			content.WriteString(ui.ErrorStyle.Render(m.prompt.View()))
+			break
+		}
+		content.WriteString(ui.SubtleStyle.Render("[y] Yes  [n/Esc] No"))
+
+	case stateRunning:
+		current := m.jobs[len(m.errs)].FullName
+		content.WriteString(fmt.Sprintf("%s %d/%d %s", m.spinner.View(), len(m.errs)+1, len(m.jobs), current))
+		content.WriteString("\n")
+		content.WriteString(m.resultsView())
+
+	case stateDone:
+		failed := len(m.jobs) - len(m.succeeded())
+		summary := ui.SuccessStyle.Render(fmt.Sprintf("%s Done for all %d", ui.IconSuccess, len(m.jobs)))
+		if failed > 0 {
+			summary = ui.ErrorStyle.Render(fmt.Sprintf("%s Failed for %d of %d", ui.IconFailed, failed, len(m.jobs)))
+		}
+		content.WriteString(summary)
+		content.WriteString("\n")
+		content.WriteString(m.resultsView())
+		content.WriteString("\n")
+		content.WriteString(ui.SubtleStyle.Render("[Enter] Close"))
+	}
+
+	panel := lipgloss.NewStyle().
+		Width(modalWidth).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(content.String())
+
+	if m.width == 0 || m.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
+}
+
+// resultsView lists the failures so far; successes are only counted.
+func (m *Model) resultsView() string {
+	var b strings.Builder
+	for i, err := range m.errs {
+		if err != nil {
+			b.WriteString(ui.FailedStyle.Render(fmt.Sprintf("%s %s: %v", ui.IconFailed, m.jobs[i].FullName, err)))
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
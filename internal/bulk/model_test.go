@@ -0,0 +1,130 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+// drive feeds the modal the results of its commands until it stops issuing
+// any, skipping spinner ticks.
+func drive(t *testing.T, m *Model, cmd tea.Cmd) tea.Msg {
+	t.Helper()
+	for cmd != nil {
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			msg = batch[len(batch)-1]()
+		}
+		if _, ok := msg.(ClosedMsg); ok {
+			return msg
+		}
+		_, cmd = m.Update(msg)
+	}
+	return nil
+}
+
+func TestDisableRunsJobByJobAndReportsFailures(t *testing.T) {
+	client := &jenkinstest.Client{
+		DisableJobFunc: func(_ context.Context, fullName string) error {
+			if fullName == "team/web" {
+				return errors.New("forbidden")
+			}
+			return nil
+		},
+	}
+	jobs := []jenkins.Job{{Name: "api", FullName: "team/api"}, {Name: "web", FullName: "team/web"}, {Name: "docs", FullName: "docs"}}
+	m := New(client, nil, ActionDisable, jobs)
+	if view := m.View(); !strings.Contains(view, "Disable 3 jobs?") || !strings.Contains(view, "team/web") {
+		t.Fatalf("summary does not list the jobs:\n%s", view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	drive(t, m, cmd)
+	if calls := client.CallsTo("DisableJob"); len(calls) != 3 {
+		t.Fatalf("DisableJob called %d times, want 3", len(calls))
+	}
+	if view := m.View(); !strings.Contains(view, "Failed for 1 of 3") || !strings.Contains(view, "team/web: forbidden") {
+		t.Errorf("results do not show the failure:\n%s", view)
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	closed := cmd().(ClosedMsg)
+	if !closed.Confirmed || !slices.Equal(closed.Done, []string{"team/api", "docs"}) {
+		t.Errorf("closed with %+v, want the two jobs disabled", closed)
+	}
+}
+
+func TestBuildUsesDefaultsOfParameterizedJobs(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetJobDetailsFunc: func(_ context.Context, fullName string, _ int) (*jenkins.JobDetails, error) {
+			details := &jenkins.JobDetails{}
+			if fullName == "deploy" {
+				details.ParameterDefinitions = []jenkins.ParameterDefinition{{Name: "ENV"}}
+			}
+			return details, nil
+		},
+	}
+	m := New(client, nil, ActionBuild, []jenkins.Job{{FullName: "deploy"}, {FullName: "lint"}})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	drive(t, m, cmd)
+
+	if calls := client.CallsTo("TriggerBuildWithParameters"); len(calls) != 1 {
+		t.Errorf("TriggerBuildWithParameters called %d times, want once for deploy", len(calls))
+	}
+	if calls := client.CallsTo("TriggerBuild"); len(calls) != 1 {
+		t.Errorf("TriggerBuild called %d times, want once for lint", len(calls))
+	}
+}
+
+func TestCancelKeepsJobsUntouched(t *testing.T) {
+	client := &jenkinstest.Client{}
+	m := New(client, nil, ActionEnable, []jenkins.Job{{FullName: "api"}})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if closed := cmd().(ClosedMsg); closed.Confirmed {
+		t.Errorf("Esc confirmed the action: %+v", closed)
+	}
+	if calls := client.CallsTo("EnableJob"); len(calls) != 0 {
+		t.Errorf("EnableJob called %d times after cancelling", len(calls))
+	}
+}
+
+func TestProtectedJobsNeedTheirNamesTyped(t *testing.T) {
+	policy, err := confirm.Config{Rules: []confirm.Rule{
+		{Pattern: "*prod*", Actions: []confirm.Action{confirm.ActionDisable}, Level: "typeName"},
+	}}.Policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &jenkinstest.Client{}
+	jobs := []jenkins.Job{{Name: "api", FullName: "team/api"}, {Name: "prod-eu", FullName: "deploy/prod-eu"}}
+	m := New(client, nil, ActionDisable, jobs).WithConfirmPolicy(policy)
+	if !m.CapturesInput() {
+		t.Error("the name prompt does not capture input")
+	}
+
+	// y is part of the name being typed, not a yes.
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil || m.state != stateConfirm {
+		t.Fatal("a wrong name confirmed the bulk disable")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("prod-eu")})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	drive(t, m, cmd)
+	if calls := client.CallsTo("DisableJob"); len(calls) != 2 {
+		t.Errorf("DisableJob called %d times after typing the name, want 2", len(calls))
+	}
+
+	// Enabling is not covered by the rule and keeps the y/N question.
+	m = New(client, nil, ActionEnable, jobs).WithConfirmPolicy(policy)
+	if m.CapturesInput() || !strings.Contains(m.View(), "[y] Yes") {
+		t.Error("enabling protected jobs asks for their names")
+	}
+}
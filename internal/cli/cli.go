@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+// command describes a headless jdash subcommand.
+type command struct {
+	name    string
+	summary string
+	hidden  bool
+	run     func(env *Env, args []string) int
+}
+
+// Env carries the output streams used by commands and a context that is
+// cancelled on Ctrl-C so in-flight Jenkins requests are abandoned.
+type Env struct {
+	Ctx    context.Context
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "build", summary: "Trigger a build for a job, optionally waiting for its result", run: runBuild},
+		{name: "jobs", summary: "List job full names", run: runJobs},
+		{name: "grep", summary: "Search recent console logs across jobs", run: runGrep},
+		{name: "follow", summary: "Wait for a successful build before a deadline", run: runFollow},
+		{name: "handoff", summary: "Summarize the last hours of jobs for a shift handoff", run: runHandoff},
+		{name: "fingerprint", summary: "Find the builds that produced and used a file", run: runFingerprint},
+		{name: "export", summary: "Export jobs, the queue or its wait history as CSV", run: runExport},
+		{name: "completion", summary: "Print a shell completion script (bash, zsh, fish)", run: runCompletion},
+		{name: "__complete", hidden: true, run: runComplete},
+	}
+}
+
+// Run executes a headless subcommand when args name one. It reports whether
+// the arguments were handled and the exit code the process should use.
+func Run(args []string) (bool, int) {
+	if len(args) == 0 {
+		return false, 0
+	}
+
+	cmd := lookup(args[0])
+	if cmd == nil {
+		return false, 0
+	}
+
+	applySettings(os.Stderr)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	env := &Env{Ctx: ctx, Stdout: os.Stdout, Stderr: os.Stderr}
+	return true, cmd.run(env, args[1:])
+}
+
+// applySettings applies the config's log redaction and time formats, as the
+// dashboard does, warning on stderr about settings it cannot use.
+func applySettings(stderr io.Writer) {
+	config, err := auth.LoadConfig()
+	if err != nil {
+		return
+	}
+	if err := utils.SetRedactionPatterns(config.Logs.RedactionPatterns()); err != nil {
+		fmt.Fprintf(stderr, "Warning: %v; using default log redaction\n", err)
+	}
+	if err := utils.SetRelativeTimeOptions(config.UI.RelativeTime()); err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	}
+}
+
+func lookup(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// visibleCommands returns the commands offered to users in help and completion.
+func visibleCommands() []command {
+	var visible []command
+	for _, cmd := range commands {
+		if !cmd.hidden {
+			visible = append(visible, cmd)
+		}
+	}
+	return visible
+}
+
+var (
+	// errConfig wraps failures to read the saved configuration.
+	errConfig = errors.New("failed to load server config")
+	// errNoServer means jdash was never set up, which calls for logging in
+	// rather than fixing a file.
+	errNoServer = errors.New("no server configured; run jdash once to authenticate")
+)
+
+// newClient builds a Jenkins client from the saved server configuration.
+// Tests replace it with a fake.
+var newClient = func() (jenkins.JenkinsClient, error) {
+	serverConfig, err := auth.GetServerConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConfig, err)
+	}
+	if serverConfig == nil {
+		return nil, errNoServer
+	}
+	return auth.CreateJenkinsClient(serverConfig), nil
+}
+
+// buildWaitInterval is how often `jdash build --wait` checks a running build.
+// Tests shorten it.
+var buildWaitInterval = 5 * time.Second
+
+// runBuild triggers a build. With --wait it follows the build to the end and
+// exits with its result, e.g. `jdash build --wait Production/api || rollback`.
+func runBuild(env *Env, args []string) int {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	wait := fs.Bool("wait", false, "wait for the build to finish and exit with its result")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: jdash build [--wait] <job-full-name>")
+		fs.PrintDefaults()
+	}
+
+	fullName, flagArgs := splitPositional(args)
+	if err := fs.Parse(flagArgs); err != nil {
+		return exitUsage
+	}
+	if fullName == "" && fs.NArg() > 0 {
+		fullName = fs.Arg(0)
+	}
+	fullName = strings.TrimSpace(fullName)
+	if fullName == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+	// Refuse before triggering a build that could not be waited for.
+	if *wait && !jenkins.ReportsQueueItems(client) {
+		fmt.Fprintln(env.Stderr, "Error: --wait needs the HTTP API; builds triggered through the SSH CLI report no queue item")
+		return exitUsage
+	}
+
+	queueID, err := client.TriggerBuild(env.Ctx, fullName)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+
+	if queueID > 0 {
+		fmt.Fprintf(env.Stdout, "Build triggered for %s (queue item %d)\n", fullName, queueID)
+	} else {
+		fmt.Fprintf(env.Stdout, "Build triggered for %s\n", fullName)
+	}
+	if !*wait {
+		return exitOK
+	}
+	if queueID <= 0 {
+		fmt.Fprintln(env.Stderr, "Error: Jenkins did not report a queue item to wait for")
+		return exitFailure
+	}
+
+	number, err := client.WaitForBuildNumber(env.Ctx, queueID)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+	fmt.Fprintf(env.Stdout, "Started %s #%d\n", fullName, number)
+
+	for {
+		build, err := client.GetBuild(env.Ctx, fullName, number)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+			return exitCodeForError(env.Ctx, err)
+		}
+		if !build.Building {
+			status := build.GetStatus()
+			fmt.Fprintf(env.Stdout, "%s #%d finished: %s\n", fullName, number, status)
+			return exitCodeForStatus(status)
+		}
+		select {
+		case <-env.Ctx.Done():
+			fmt.Fprintln(env.Stderr, "Interrupted")
+			return exitInterrupted
+		case <-time.After(buildWaitInterval):
+		}
+	}
+}
+
+func runJobs(env *Env, args []string) int {
+	names, err := jobNames(env.Ctx, true)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+	for _, name := range names {
+		fmt.Fprintln(env.Stdout, name)
+	}
+	return exitOK
+}
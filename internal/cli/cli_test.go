@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+func TestBuildWaitExitsWithTheResult(t *testing.T) {
+	saved := buildWaitInterval
+	buildWaitInterval = time.Millisecond
+	t.Cleanup(func() { buildWaitInterval = saved })
+
+	polls := 0
+	client := &jenkinstest.Client{
+		TriggerBuildFunc:       func(context.Context, string) (int, error) { return 31, nil },
+		WaitForBuildNumberFunc: func(context.Context, int) (int, error) { return 12, nil },
+		GetBuildFunc: func(_ context.Context, _ string, number int) (*jenkins.Build, error) {
+			polls++
+			if polls < 3 {
+				return &jenkins.Build{Number: number, Building: true}, nil
+			}
+			return &jenkins.Build{Number: number, Result: "UNSTABLE"}, nil
+		},
+	}
+	useClient(t, client)
+
+	var stdout, stderr bytes.Buffer
+	env := &Env{Ctx: context.Background(), Stdout: &stdout, Stderr: &stderr}
+	if code := runBuild(env, []string{"team/api", "--wait"}); code != exitUnstable {
+		t.Fatalf("runBuild() = %d, want %d; stderr: %s", code, exitUnstable, stderr.String())
+	}
+	for _, want := range []string{"queue item 31", "Started team/api #12", "team/api #12 finished: UNSTABLE"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("stdout = %q, want %q", stdout.String(), want)
+		}
+	}
+	if calls := client.CallsTo("WaitForBuildNumber"); len(calls) != 1 || calls[0].Args[0] != 31 {
+		t.Errorf("WaitForBuildNumber calls = %v, want queue item 31", calls)
+	}
+	if polls != 3 {
+		t.Errorf("polled the build %d times, want until it finished after 3", polls)
+	}
+}
+
+func TestBuildWaitNeedsAQueueItem(t *testing.T) {
+	client := &jenkinstest.Client{
+		TriggerBuildFunc: func(context.Context, string) (int, error) { return 0, nil },
+	}
+	useClient(t, client)
+
+	var stdout, stderr bytes.Buffer
+	env := &Env{Ctx: context.Background(), Stdout: &stdout, Stderr: &stderr}
+	if code := runBuild(env, []string{"--wait", "api"}); code != exitFailure {
+		t.Fatalf("runBuild() = %d, want %d", code, exitFailure)
+	}
+	if !strings.Contains(stderr.String(), "did not report a queue item") {
+		t.Errorf("stderr = %q, want the missing queue item", stderr.String())
+	}
+	if calls := client.CallsTo("WaitForBuildNumber"); len(calls) != 0 {
+		t.Errorf("waited without a queue item: %v", calls)
+	}
+}
+
+func TestBuildWaitIsRefusedOverSSH(t *testing.T) {
+	useClient(t, jenkins.NewClient(jenkins.Credentials{URL: "https://ci.example.com", SSH: &jenkins.SSHOptions{Endpoint: "ci.example.com:2222"}}))
+
+	var stdout, stderr bytes.Buffer
+	env := &Env{Ctx: context.Background(), Stdout: &stdout, Stderr: &stderr}
+	if code := runBuild(env, []string{"--wait", "api"}); code != exitUsage {
+		t.Fatalf("runBuild() = %d, want %d", code, exitUsage)
+	}
+	if !strings.Contains(stderr.String(), "SSH CLI") || stdout.Len() != 0 {
+		t.Errorf("stdout = %q, stderr = %q; want --wait refused before triggering", stdout.String(), stderr.String())
+	}
+}
+
+func TestSettingsApplyToCommands(t *testing.T) {
+	saved := auth.ConfigDir()
+	t.Cleanup(func() {
+		auth.UseConfigDir(saved)
+		utils.SetRelativeTimeOptions(utils.DefaultRelativeTimeOptions)
+	})
+	dir := t.TempDir()
+	auth.UseConfigDir(dir)
+	config := `{"ui": {"language": "de", "dateFormat": "DD.MM.YYYY"}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stderr bytes.Buffer
+	applySettings(&stderr)
+	if got := utils.FormatRelativeTime(time.Now().Add(-2 * time.Hour)); got != "vor 2 Stunden" {
+		t.Errorf("relative time = %q, want it in the configured language", got)
+	}
+	if !strings.Contains(stderr.String(), "Warning: date format \"DD.MM.YYYY\"") {
+		t.Errorf("stderr = %q, want a warning about the date format", stderr.String())
+	}
+}
+
+func TestClientErrorsExitCodes(t *testing.T) {
+	saved := auth.ConfigDir()
+	t.Cleanup(func() { auth.UseConfigDir(saved) })
+
+	tests := []struct {
+		name   string
+		config string
+		want   int
+	}{
+		{name: "never set up", want: exitAuth},
+		{name: "unreadable config", config: `{"server": `, want: exitConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			auth.UseConfigDir(dir)
+			if tt.config != "" {
+				if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var stdout, stderr bytes.Buffer
+			env := &Env{Ctx: context.Background(), Stdout: &stdout, Stderr: &stderr}
+			if code := runBuild(env, []string{"api"}); code != tt.want {
+				t.Errorf("runBuild() = %d, want %d; stderr: %s", code, tt.want, stderr.String())
+			}
+		})
+	}
+}
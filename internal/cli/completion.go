@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jobcache"
+)
+
+// jobCacheTTL controls how long cached job names are trusted before completion refetches them.
+const jobCacheTTL = time.Hour
+
+func runCompletion(env *Env, args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(env.Stderr, "usage: jdash completion <bash|zsh|fish>")
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Fprint(env.Stdout, bashCompletion())
+	case "zsh":
+		fmt.Fprint(env.Stdout, zshCompletion())
+	case "fish":
+		fmt.Fprint(env.Stdout, fishCompletion())
+	default:
+		fmt.Fprintf(env.Stderr, "unsupported shell %q (expected bash, zsh or fish)\n", args[0])
+		return exitUsage
+	}
+	return exitOK
+}
+
+// runComplete backs the shell scripts: `jdash __complete jobs <prefix>` prints
+// matching job full names, one per line. `jdash __complete grep <arg>...`
+// does the same for the words typed after `jdash grep`, the last being the
+// prefix, unless that word is the search pattern. Errors are silent so a
+// broken network never pollutes the user's command line.
+func runComplete(env *Env, args []string) int {
+	if len(args) == 0 {
+		return exitUsage
+	}
+	prefix := ""
+	if len(args) > 1 {
+		prefix = args[len(args)-1]
+	}
+	switch args[0] {
+	case "jobs":
+	case "grep":
+		if completingGrepPattern(args[1:]) {
+			return exitOK
+		}
+	default:
+		return exitUsage
+	}
+
+	names, err := jobNames(env.Ctx, false)
+	if err != nil {
+		return exitCodeForError(env.Ctx, err)
+	}
+	for _, name := range filterPrefix(names, prefix) {
+		fmt.Fprintln(env.Stdout, name)
+	}
+	return exitOK
+}
+
+// completingGrepPattern reports whether the last of the grep arguments is
+// the search pattern rather than a job.
+func completingGrepPattern(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	fs, _ := grepFlags()
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args[:len(args)-1]); err != nil {
+		return false
+	}
+	return fs.NArg() == 0
+}
+
+// jobNames returns job full names from the local cache, refetching from Jenkins
+// when the cache is missing, stale, or a fresh list was explicitly requested.
+func jobNames(ctx context.Context, forceRefresh bool) ([]string, error) {
+	if !forceRefresh {
+		names, written, err := jobcache.Load()
+		if err == nil && time.Since(written) < jobCacheTTL {
+			return names, nil
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := client.GetAllJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// A failed cache write only costs a refetch next time.
+	_ = jobcache.Save(jobs)
+	return jobcache.FullNames(jobs), nil
+}
+
+func filterPrefix(names []string, prefix string) []string {
+	if prefix == "" {
+		return names
+	}
+	lower := strings.ToLower(prefix)
+	var matches []string
+	for _, name := range names {
+		if strings.HasPrefix(strings.ToLower(name), lower) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+func commandNames() string {
+	var names []string
+	for _, cmd := range visibleCommands() {
+		names = append(names, cmd.name)
+	}
+	return strings.Join(names, " ")
+}
+
+func bashCompletion() string {
+	return fmt.Sprintf(`# bash completion for jdash
+_jdash() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    if [ "$COMP_CWORD" -eq 1 ]; then
+        COMPREPLY=( $(compgen -W "%s" -- "$cur") )
+        return
+    fi
+    case "${COMP_WORDS[1]}" in
+        build|follow|handoff)
+            mapfile -t COMPREPLY < <(jdash __complete jobs "$cur" 2>/dev/null)
+            ;;
+        grep)
+            mapfile -t COMPREPLY < <(jdash __complete grep "${COMP_WORDS[@]:2:COMP_CWORD-1}" 2>/dev/null)
+            ;;
+        completion)
+            COMPREPLY=( $(compgen -W "bash zsh fish" -- "$cur") )
+            ;;
+        export)
+            COMPREPLY=( $(compgen -W "jobs queue queue-history" -- "$cur") )
+            ;;
+        fingerprint)
+            COMPREPLY=( $(compgen -f -- "$cur") )
+            ;;
+    esac
+}
+complete -F _jdash jdash
+`, commandNames())
+}
+
+func zshCompletion() string {
+	var described []string
+	for _, cmd := range visibleCommands() {
+		described = append(described, fmt.Sprintf("'%s:%s'", cmd.name, cmd.summary))
+	}
+
+	return fmt.Sprintf(`#compdef jdash
+_jdash() {
+    local -a commands
+    commands=(%s)
+    if (( CURRENT == 2 )); then
+        _describe 'command' commands
+        return
+    fi
+    case "$words[2]" in
+        build|follow|handoff)
+            local -a jobs
+            jobs=("${(@f)$(jdash __complete jobs "$words[CURRENT]" 2>/dev/null)}")
+            compadd -a jobs
+            ;;
+        grep)
+            local -a jobs
+            jobs=("${(@f)$(jdash __complete grep "${(@)words[3,CURRENT]}" 2>/dev/null)}")
+            compadd -a jobs
+            ;;
+        completion)
+            compadd bash zsh fish
+            ;;
+        export)
+            compadd jobs queue queue-history
+            ;;
+        fingerprint)
+            _files
+            ;;
+    esac
+}
+compdef _jdash jdash
+`, strings.Join(described, " "))
+}
+
+func fishCompletion() string {
+	var b strings.Builder
+	b.WriteString("# fish completion for jdash\n")
+	b.WriteString("complete -c jdash -f\n")
+	for _, cmd := range visibleCommands() {
+		fmt.Fprintf(&b, "complete -c jdash -n '__fish_use_subcommand' -a %s -d '%s'\n", cmd.name, cmd.summary)
+	}
+	b.WriteString("complete -c jdash -n '__fish_seen_subcommand_from build follow handoff' -a '(jdash __complete jobs (commandline -ct))'\n")
+	b.WriteString("complete -c jdash -n '__fish_seen_subcommand_from grep' -a '(jdash __complete grep (commandline -opc)[3..-1] (commandline -ct))'\n")
+	b.WriteString("complete -c jdash -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n")
+	b.WriteString("complete -c jdash -n '__fish_seen_subcommand_from export' -a 'jobs queue queue-history'\n")
+	b.WriteString("complete -c jdash -n '__fish_seen_subcommand_from fingerprint' -F\n")
+	return b.String()
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+// Exit codes of the headless commands, documented in the README so scripts
+// can branch on them. Commands that don't wait for a build use exitOK and
+// exitFailure for their own outcome.
+const (
+	exitOK = 0
+	// exitFailure is a failed build, or a command that failed for any reason
+	// without a more specific code.
+	exitFailure    = 1
+	exitUnstable   = 2
+	exitAborted    = 3
+	exitConnection = 10
+	exitAuth       = 11
+	// exitUsage follows sysexits.h (EX_USAGE) so it can't be mistaken for a build result.
+	exitUsage = 64
+	// exitConfig follows sysexits.h (EX_CONFIG): the saved configuration is unreadable.
+	exitConfig = 78
+	// exitInterrupted is the conventional exit status after Ctrl-C (128 + SIGINT).
+	exitInterrupted = 130
+)
+
+// exitCodeForError maps an error from talking to Jenkins to an exit code.
+func exitCodeForError(ctx context.Context, err error) int {
+	var urlErr *url.Error
+	switch {
+	case err == nil:
+		return exitOK
+	case ctx.Err() != nil:
+		return exitInterrupted
+	case errors.Is(err, jenkins.ErrUnauthorized), errors.Is(err, errNoServer):
+		return exitAuth
+	case errors.Is(err, errConfig):
+		return exitConfig
+	case errors.As(err, &urlErr):
+		// The request never got an HTTP response: DNS, refused, timeout, TLS.
+		return exitConnection
+	default:
+		return exitFailure
+	}
+}
+
+// exitCodeForStatus maps a finished build's status to an exit code.
+func exitCodeForStatus(status string) int {
+	switch status {
+	case jenkins.StatusSuccess:
+		return exitOK
+	case jenkins.StatusUnstable:
+		return exitUnstable
+	case jenkins.StatusAborted:
+		return exitAborted
+	default:
+		return exitFailure
+	}
+}
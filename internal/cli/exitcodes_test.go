@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+func TestExitCodeForError(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want int
+	}{
+		{name: "no error", ctx: context.Background(), want: exitOK},
+		{name: "unauthorized", ctx: context.Background(), err: fmt.Errorf("failed to fetch jobs: %w", jenkins.ErrUnauthorized), want: exitAuth},
+		{name: "unreachable", ctx: context.Background(), err: &url.Error{Op: "Get", URL: "https://ci", Err: errors.New("connection refused")}, want: exitConnection},
+		{name: "http status", ctx: context.Background(), err: errors.New("failed to trigger build: status 500"), want: exitFailure},
+		{name: "interrupted", ctx: cancelled, err: &url.Error{Op: "Get", URL: "https://ci", Err: context.Canceled}, want: exitInterrupted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeForError(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("exitCodeForError() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCodeForStatus(t *testing.T) {
+	tests := map[string]int{
+		jenkins.StatusSuccess:  exitOK,
+		jenkins.StatusFailed:   exitFailure,
+		jenkins.StatusUnstable: exitUnstable,
+		jenkins.StatusAborted:  exitAborted,
+	}
+	for status, want := range tests {
+		if got := exitCodeForStatus(status); got != want {
+			t.Errorf("exitCodeForStatus(%q) = %d, want %d", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/gorbach/jdash/internal/export"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/queuelog"
+)
+
+// runExport dumps the current jobs or queue snapshot as CSV, e.g.
+// `jdash export jobs -o ci-health.csv`, or the queue waits the dashboard has
+// logged locally. Output goes to stdout by default.
+func runExport(env *Env, args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	output := fs.String("o", "", "write CSV to this file instead of stdout")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: jdash export [flags] <jobs|queue|queue-history>")
+		fs.PrintDefaults()
+	}
+
+	kind, rest := splitPositional(args)
+	if err := fs.Parse(rest); err != nil {
+		return exitUsage
+	}
+	if kind == "" && fs.NArg() > 0 {
+		kind = fs.Arg(0)
+	}
+	if kind != "jobs" && kind != "queue" && kind != "queue-history" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	var write func(io.Writer) error
+	if kind == "queue-history" {
+		// The log is local; no Jenkins connection is needed.
+		log, err := queuelog.Load()
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+			return exitFailure
+		}
+		entries := log.Entries()
+		write = func(w io.Writer) error { return export.QueueHistory(w, entries) }
+	} else {
+		client, err := newClient()
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+			return exitCodeForError(env.Ctx, err)
+		}
+
+		write, err = exportWriter(env.Ctx, client, kind)
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+			return exitCodeForError(env.Ctx, err)
+		}
+	}
+
+	if *output == "" {
+		if err := write(env.Stdout); err != nil {
+			fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+	fmt.Fprintf(env.Stderr, "Exported %s to %s\n", kind, *output)
+	return exitOK
+}
+
+// exportWriter fetches the snapshot for kind and returns a function that renders it.
+func exportWriter(ctx context.Context, client jenkins.JenkinsClient, kind string) (func(io.Writer) error, error) {
+	if kind == "jobs" {
+		jobs, err := client.GetAllJobs(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return func(w io.Writer) error { return export.Jobs(w, jobs) }, nil
+	}
+
+	running, err := client.GetRunningBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queued, err := client.GetBuildQueue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return func(w io.Writer) error { return export.Queue(w, running, queued, now) }, nil
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+var checksumPattern = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// runFingerprint traces a file through Jenkins by its MD5 checksum, e.g.
+// `jdash fingerprint ./app.jar`: which build produced it and which builds
+// (deployments, tests) used it since.
+func runFingerprint(env *Env, args []string) int {
+	if len(args) != 1 || strings.TrimSpace(args[0]) == "" {
+		fmt.Fprintln(env.Stderr, "usage: jdash fingerprint <md5|file>")
+		return exitUsage
+	}
+
+	checksum, err := fingerprintChecksum(args[0])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+
+	fingerprint, err := client.GetFingerprint(env.Ctx, checksum)
+	if errors.Is(err, jenkins.ErrFingerprintNotFound) {
+		fmt.Fprintf(env.Stderr, "No build recorded %s; the file may not be fingerprinted\n", checksum)
+		return exitFailure
+	}
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+
+	writeFingerprint(env.Stdout, fingerprint)
+	return exitOK
+}
+
+// fingerprintChecksum returns arg itself when it is an MD5 checksum, and
+// otherwise the checksum of the file it names.
+func fingerprintChecksum(arg string) (string, error) {
+	if checksumPattern.MatchString(arg) {
+		if _, err := os.Stat(arg); err != nil {
+			return strings.ToLower(arg), nil
+		}
+	}
+
+	file, err := os.Open(arg)
+	if err != nil {
+		return "", fmt.Errorf("%q is neither an MD5 checksum nor a readable file", arg)
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", arg, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func writeFingerprint(w io.Writer, fingerprint *jenkins.Fingerprint) {
+	fmt.Fprintf(w, "%s (md5 %s), first seen %s\n",
+		fingerprint.FileName, fingerprint.Hash, utils.FormatRelativeTime(fingerprint.GetTimestamp()))
+	if origin := fingerprint.Original; origin != nil {
+		fmt.Fprintf(w, "Produced by %s #%d\n", origin.Name, origin.Number)
+	} else {
+		fmt.Fprintln(w, "Produced outside Jenkins, or by a build that no longer exists")
+	}
+
+	if len(fingerprint.Usage) == 0 {
+		fmt.Fprintln(w, "Not used by any build")
+		return
+	}
+	fmt.Fprintln(w, "Used by:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, usage := range fingerprint.Usage {
+		ranges := make([]string, 0, len(usage.Ranges.Ranges))
+		for _, r := range usage.Ranges.Ranges {
+			ranges = append(ranges, r.String())
+		}
+		fmt.Fprintf(tw, "  %s\t%s\n", usage.Name, strings.Join(ranges, ", "))
+	}
+	tw.Flush()
+}
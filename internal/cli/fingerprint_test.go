@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+func TestFingerprintChecksum(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.jar")
+	if err := os.WriteFile(file, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{arg: "B1946AC92492D2347C6235B4D2611184", want: "b1946ac92492d2347c6235b4d2611184"},
+		{arg: file, want: "b1946ac92492d2347c6235b4d2611184"},
+		{arg: filepath.Join(t.TempDir(), "missing.jar"), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := fingerprintChecksum(tt.arg)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("fingerprintChecksum(%q) = %q, %v; want %q", tt.arg, got, err, tt.want)
+		}
+	}
+}
+
+func TestWriteFingerprint(t *testing.T) {
+	fingerprint := &jenkins.Fingerprint{
+		FileName:  "app.jar",
+		Hash:      "b1946ac92492d2347c6235b4d2611184",
+		Timestamp: time.Now().Add(-2 * time.Hour).UnixMilli(),
+		Original:  &jenkins.FingerprintOrigin{Name: "Production/api", Number: 42},
+		Usage: []jenkins.FingerprintUsage{
+			{Name: "Production/api"},
+			{Name: "Deploy/staging"},
+		},
+	}
+	fingerprint.Usage[0].Ranges.Ranges = []jenkins.BuildRange{{Start: 42, End: 43}}
+	fingerprint.Usage[1].Ranges.Ranges = []jenkins.BuildRange{{Start: 17, End: 20}, {Start: 25, End: 26}}
+
+	var out strings.Builder
+	writeFingerprint(&out, fingerprint)
+
+	want := "app.jar (md5 b1946ac92492d2347c6235b4d2611184), first seen 2 hours ago\n" +
+		"Produced by Production/api #42\n" +
+		"Used by:\n" +
+		"  Production/api  #42\n" +
+		"  Deploy/staging  #17-#19, #25\n"
+	if out.String() != want {
+		t.Errorf("writeFingerprint() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/notify"
+)
+
+const (
+	defaultFollowInterval = time.Minute
+	minFollowInterval     = 5 * time.Second
+)
+
+// sendNotification raises the desktop notification for a missed deadline.
+// Tests replace it so they don't pop one up.
+var sendNotification = notify.Send
+
+// runFollow watches a job until a successful build finishes or the deadline
+// passes, e.g. `jdash follow Nightly/e2e --until 07:00`. It polls the job's
+// last successful build, so a success followed by a newer failing or running
+// build still counts. A missed deadline raises a desktop notification and
+// exits with the code for the last finished build's result (or the last
+// error) so it can be scheduled from cron.
+func runFollow(env *Env, args []string) int {
+	fs := flag.NewFlagSet("follow", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	until := fs.String("until", "", "deadline as HH:MM (next occurrence), RFC3339 time, or duration like 2h")
+	since := fs.Duration("since", 0, "also accept successful builds that finished this long before the watch started")
+	interval := fs.Duration("interval", defaultFollowInterval, "polling interval")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: jdash follow <job-full-name> --until <deadline> [--since 12h] [--interval 1m]")
+		fs.PrintDefaults()
+	}
+
+	jobName, flagArgs := splitPositional(args)
+	if err := fs.Parse(flagArgs); err != nil {
+		return exitUsage
+	}
+	if jobName == "" && fs.NArg() > 0 {
+		jobName = fs.Arg(0)
+	}
+	if jobName == "" || *until == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	now := time.Now()
+	deadline, err := parseDeadline(*until, now)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if *interval < minFollowInterval {
+		*interval = minFollowInterval
+	}
+	cutoff := now.Add(-*since)
+
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+
+	fmt.Fprintf(env.Stdout, "Following %s until %s\n", jobName, deadline.Format("2006-01-02 15:04"))
+
+	// missedCode is returned if the deadline passes; it tracks the latest poll.
+	missedCode := exitFailure
+	for {
+		success, err := client.GetLastSuccessfulBuild(env.Ctx, jobName)
+		if err == nil && succeededSince(success, cutoff) {
+			fmt.Fprintf(env.Stdout, "✓ %s #%d succeeded\n", jobName, success.Number)
+			return exitOK
+		}
+		// The newest build decides the exit code if the deadline passes.
+		var build *jenkins.Build
+		if err == nil {
+			build, err = client.GetBuild(env.Ctx, jobName, -1)
+		}
+		switch {
+		case err != nil:
+			fmt.Fprintf(env.Stderr, "Warning: %v\n", err)
+			missedCode = exitCodeForError(env.Ctx, err)
+		case !build.Building:
+			// A successful build that finished before the cutoff doesn't count.
+			missedCode = exitCodeForStatus(build.GetStatus())
+			if missedCode == exitOK {
+				missedCode = exitFailure
+			}
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			break
+		}
+		select {
+		case <-env.Ctx.Done():
+			fmt.Fprintln(env.Stderr, "Interrupted")
+			return exitInterrupted
+		case <-time.After(minDuration(*interval, remaining)):
+		}
+	}
+
+	message := fmt.Sprintf("No successful build of %s by %s", jobName, deadline.Format("15:04"))
+	fmt.Fprintf(env.Stderr, "✗ %s\n", message)
+	if err := sendNotification("jdash: deadline missed", message); err != nil {
+		fmt.Fprintf(env.Stderr, "Warning: %v\n", err)
+	}
+	return missedCode
+}
+
+// succeededSince reports whether build finished successfully at or after cutoff.
+func succeededSince(build *jenkins.Build, cutoff time.Time) bool {
+	if build == nil || build.Building || build.GetStatus() != jenkins.StatusSuccess {
+		return false
+	}
+	finished := build.GetTimestamp().Add(build.GetDuration())
+	return !finished.Before(cutoff)
+}
+
+// parseDeadline accepts "HH:MM" (the next occurrence of that wall-clock time),
+// an RFC3339 timestamp, or a Go duration relative to now.
+func parseDeadline(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if clock, err := time.ParseInLocation("15:04", value, now.Location()); err == nil {
+		deadline := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !deadline.After(now) {
+			deadline = deadline.AddDate(0, 0, 1)
+		}
+		return deadline, nil
+	}
+
+	if ts, err := time.Parse(time.RFC3339, value); err == nil {
+		if !ts.After(now) {
+			return time.Time{}, fmt.Errorf("deadline %s is in the past", value)
+		}
+		return ts, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("deadline duration must be positive")
+		}
+		return now.Add(d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid deadline %q (use HH:MM, RFC3339, or a duration like 2h)", value)
+}
+
+// splitPositional pulls a leading positional argument out so flags may follow it.
+func splitPositional(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+func TestParseDeadline(t *testing.T) {
+	now := time.Date(2024, 3, 10, 22, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "clock time later today", value: "23:15", want: time.Date(2024, 3, 10, 23, 15, 0, 0, time.UTC)},
+		{name: "clock time rolls to tomorrow", value: "07:00", want: time.Date(2024, 3, 11, 7, 0, 0, 0, time.UTC)},
+		{name: "clock time equal to now rolls over", value: "22:30", want: time.Date(2024, 3, 11, 22, 30, 0, 0, time.UTC)},
+		{name: "duration", value: "90m", want: now.Add(90 * time.Minute)},
+		{name: "rfc3339", value: "2024-03-11T06:00:00Z", want: time.Date(2024, 3, 11, 6, 0, 0, 0, time.UTC)},
+		{name: "rfc3339 in the past", value: "2024-03-09T06:00:00Z", wantErr: true},
+		{name: "negative duration", value: "-1h", wantErr: true},
+		{name: "garbage", value: "tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDeadline(tt.value, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseDeadline(%q) expected error, got %v", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseDeadline(%q) unexpected error: %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseDeadline(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSucceededSince(t *testing.T) {
+	cutoff := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	started := cutoff.Add(-10 * time.Minute).UnixMilli()
+
+	tests := []struct {
+		name  string
+		build *jenkins.Build
+		want  bool
+	}{
+		{name: "nil build", build: nil, want: false},
+		{name: "finished after cutoff", build: &jenkins.Build{Result: "SUCCESS", Timestamp: started, Duration: int64(20 * time.Minute / time.Millisecond)}, want: true},
+		{name: "finished before cutoff", build: &jenkins.Build{Result: "SUCCESS", Timestamp: started, Duration: int64(5 * time.Minute / time.Millisecond)}, want: false},
+		{name: "failed after cutoff", build: &jenkins.Build{Result: "FAILURE", Timestamp: started, Duration: int64(20 * time.Minute / time.Millisecond)}, want: false},
+		{name: "still building", build: &jenkins.Build{Building: true, Timestamp: started}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := succeededSince(tt.build, cutoff); got != tt.want {
+				t.Errorf("succeededSince() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFollowCountsSuccessBeforeNewerBuild(t *testing.T) {
+	now := time.Now()
+	client := &jenkinstest.Client{
+		GetLastSuccessfulBuildFunc: func(context.Context, string) (*jenkins.Build, error) {
+			return &jenkins.Build{Number: 7, Result: jenkins.StatusSuccess, Timestamp: now.UnixMilli()}, nil
+		},
+		// The newest build failed after the success.
+		GetBuildFunc: func(context.Context, string, int) (*jenkins.Build, error) {
+			return &jenkins.Build{Number: 8, Result: "FAILURE", Timestamp: now.UnixMilli()}, nil
+		},
+	}
+	useClient(t, client)
+
+	var stdout, stderr bytes.Buffer
+	env := &Env{Ctx: context.Background(), Stdout: &stdout, Stderr: &stderr}
+	if code := runFollow(env, []string{"nightly", "--until", "1h", "--since", "1h"}); code != exitOK {
+		t.Fatalf("runFollow() = %d, want %d; stderr: %s", code, exitOK, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "#7 succeeded") {
+		t.Errorf("stdout = %q, want the successful build", stdout.String())
+	}
+}
+
+func TestFollowMissedDeadlineExitsWithTheNewestResult(t *testing.T) {
+	saved := sendNotification
+	t.Cleanup(func() { sendNotification = saved })
+	var notified string
+	sendNotification = func(_, body string) error {
+		notified = body
+		return nil
+	}
+
+	old := time.Now().Add(-48 * time.Hour).UnixMilli()
+	tests := []struct {
+		name   string
+		newest *jenkins.Build
+		want   int
+	}{
+		{name: "unstable", newest: &jenkins.Build{Number: 9, Result: "UNSTABLE", Timestamp: old}, want: exitUnstable},
+		{name: "aborted", newest: &jenkins.Build{Number: 9, Result: "ABORTED", Timestamp: old}, want: exitAborted},
+		// Succeeding before --since doesn't meet the deadline.
+		{name: "old success", newest: &jenkins.Build{Number: 9, Result: "SUCCESS", Timestamp: old}, want: exitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useClient(t, &jenkinstest.Client{
+				GetLastSuccessfulBuildFunc: func(context.Context, string) (*jenkins.Build, error) {
+					return &jenkins.Build{Number: 4, Result: "SUCCESS", Timestamp: old}, nil
+				},
+				GetBuildFunc: func(context.Context, string, int) (*jenkins.Build, error) { return tt.newest, nil },
+			})
+			notified = ""
+
+			var stdout, stderr bytes.Buffer
+			env := &Env{Ctx: context.Background(), Stdout: &stdout, Stderr: &stderr}
+			if code := runFollow(env, []string{"nightly", "--until", "1ms", "--since", "1h"}); code != tt.want {
+				t.Fatalf("runFollow() = %d, want %d; stderr: %s", code, tt.want, stderr.String())
+			}
+			if !strings.Contains(notified, "No successful build of nightly") {
+				t.Errorf("notification = %q, want the missed deadline", notified)
+			}
+		})
+	}
+}
+
+// useClient makes the commands talk to client for the rest of the test.
+func useClient(t *testing.T, client jenkins.JenkinsClient) {
+	t.Helper()
+	saved := newClient
+	t.Cleanup(func() { newClient = saved })
+	newClient = func() (jenkins.JenkinsClient, error) { return client, nil }
+}
@@ -0,0 +1,285 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+const (
+	defaultGrepBuilds     = 1
+	maxGrepBuilds         = 10
+	defaultGrepMaxJobs    = 25
+	defaultGrepMaxMatches = 20
+	defaultGrepDelay      = 250 * time.Millisecond
+	// maxGrepLogBytes caps how much of one console log is read, so a build
+	// that logged gigabytes doesn't stall the search or exhaust memory.
+	maxGrepLogBytes = 64 << 20
+)
+
+// logMatch is a single matching console line.
+type logMatch struct {
+	line int
+	text string
+}
+
+// runGrep searches the console logs of the most recent builds of the given jobs,
+// e.g. `jdash grep "OutOfMemoryError" Production/ api-gateway`. Arguments ending
+// in "/" expand to every cached job inside that folder. Requests are issued
+// sequentially with a delay so a wide search does not hammer the controller.
+func runGrep(env *Env, args []string) int {
+	fs, opts := grepFlags()
+	fs.SetOutput(env.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: jdash grep [flags] <pattern> <job|folder/>...")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	match, err := newLineMatcher(fs.Arg(0), *opts.regex, *opts.ignoreCase)
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	if *opts.builds <= 0 {
+		*opts.builds = defaultGrepBuilds
+	}
+	if *opts.builds > maxGrepBuilds {
+		*opts.builds = maxGrepBuilds
+	}
+
+	targets, err := expandJobTargets(env.Ctx, fs.Args()[1:])
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+	if *opts.maxJobs > 0 && len(targets) > *opts.maxJobs {
+		fmt.Fprintf(env.Stderr, "Warning: searching only the first %d of %d jobs (see --max-jobs)\n", *opts.maxJobs, len(targets))
+		targets = targets[:*opts.maxJobs]
+	}
+
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+
+	// throttle waits out --delay between requests. It reports false when the
+	// command is interrupted while waiting.
+	throttle := func() bool {
+		if *opts.delay <= 0 {
+			return env.Ctx.Err() == nil
+		}
+		timer := time.NewTimer(*opts.delay)
+		defer timer.Stop()
+		select {
+		case <-env.Ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		}
+	}
+
+	matchedBuilds := 0
+	for _, fullName := range targets {
+		if env.Ctx.Err() != nil {
+			fmt.Fprintln(env.Stderr, "Interrupted")
+			return exitInterrupted
+		}
+		details, err := client.GetJobDetails(env.Ctx, fullName, *opts.builds)
+		if !throttle() {
+			fmt.Fprintln(env.Stderr, "Interrupted")
+			return exitInterrupted
+		}
+		if err != nil {
+			fmt.Fprintf(env.Stderr, "Warning: %s: %v\n", fullName, err)
+			continue
+		}
+
+		for i := range details.Builds {
+			build := &details.Builds[i]
+			matches, truncated, err := grepLog(env.Ctx, client, build, fullName, match, *opts.maxMatches)
+			if !throttle() {
+				fmt.Fprintln(env.Stderr, "Interrupted")
+				return exitInterrupted
+			}
+			if err != nil {
+				fmt.Fprintf(env.Stderr, "Warning: %s #%d: %v\n", fullName, build.Number, err)
+				continue
+			}
+			if truncated {
+				fmt.Fprintf(env.Stderr, "Warning: %s #%d: searched only the first %s of the log\n", fullName, build.Number, utils.FormatBytes(maxGrepLogBytes))
+			}
+			if len(matches) == 0 {
+				continue
+			}
+			matchedBuilds++
+			for _, m := range matches {
+				fmt.Fprintf(env.Stdout, "%s #%d:%d: %s\n", fullName, build.Number, m.line, m.text)
+			}
+		}
+	}
+
+	if matchedBuilds == 0 {
+		fmt.Fprintln(env.Stderr, "No matches")
+		return exitFailure
+	}
+	return exitOK
+}
+
+// expandJobTargets resolves folder arguments ("Folder/") against the job cache.
+func expandJobTargets(ctx context.Context, args []string) ([]string, error) {
+	var targets []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			targets = append(targets, name)
+		}
+	}
+
+	var names []string
+	for _, arg := range args {
+		arg = strings.TrimSpace(arg)
+		if arg == "" {
+			continue
+		}
+		if !strings.HasSuffix(arg, "/") {
+			add(arg)
+			continue
+		}
+		if names == nil {
+			var err error
+			if names, err = jobNames(ctx, false); err != nil {
+				return nil, err
+			}
+		}
+		for _, name := range names {
+			if strings.HasPrefix(name, arg) {
+				add(name)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no jobs matched %s", strings.Join(args, " "))
+	}
+	return targets, nil
+}
+
+// grepOptions are the flags of `jdash grep`.
+type grepOptions struct {
+	builds, maxJobs, maxMatches *int
+	delay                       *time.Duration
+	regex, ignoreCase           *bool
+}
+
+// grepFlags defines the flags of `jdash grep`, shared with shell completion
+// so it knows which argument is the pattern.
+func grepFlags() (*flag.FlagSet, grepOptions) {
+	fs := flag.NewFlagSet("grep", flag.ContinueOnError)
+	return fs, grepOptions{
+		builds:     fs.Int("builds", defaultGrepBuilds, fmt.Sprintf("number of recent builds to search per job (max %d)", maxGrepBuilds)),
+		maxJobs:    fs.Int("max-jobs", defaultGrepMaxJobs, "maximum number of jobs to search"),
+		maxMatches: fs.Int("max-matches", defaultGrepMaxMatches, "maximum matching lines reported per build"),
+		delay:      fs.Duration("delay", defaultGrepDelay, "pause between Jenkins requests"),
+		regex:      fs.Bool("regex", false, "treat the pattern as a regular expression"),
+		ignoreCase: fs.Bool("i", false, "case-insensitive match"),
+	}
+}
+
+// grepLog streams the console output of a build, finished or running, and
+// returns up to limit matching lines (limit <= 0 means unbounded). It stops
+// reading once the limit is reached, and reports truncated when the log was
+// longer than maxGrepLogBytes.
+func grepLog(ctx context.Context, client jenkins.JenkinsClient, build *jenkins.Build, fullName string, match func(string) bool, limit int) (matches []logMatch, truncated bool, err error) {
+	var offset int64
+	var sanitizer utils.LogSanitizer
+	lines := 0
+	search := func(text string) {
+		found := searchLog(text, match, limit-len(matches))
+		for _, m := range found {
+			m.line += lines
+			matches = append(matches, m)
+		}
+		lines += strings.Count(text, "\n") + 1
+	}
+	for {
+		chunk, next, more, err := client.GetProgressiveLog(ctx, build.URL, fullName, build.Number, offset)
+		if err != nil {
+			return nil, false, err
+		}
+		// The sanitizer passes on complete lines only.
+		if clean := sanitizer.Write(chunk); clean != "" {
+			search(strings.TrimSuffix(clean, "\n"))
+		}
+		if limit > 0 && len(matches) >= limit {
+			return matches, false, nil
+		}
+		if !more || next <= offset {
+			break
+		}
+		if next >= maxGrepLogBytes {
+			truncated = true
+			break
+		}
+		offset = next
+	}
+	if rest := sanitizer.Flush(); rest != "" {
+		search(rest)
+	}
+	return matches, truncated, nil
+}
+
+func newLineMatcher(pattern string, useRegex, ignoreCase bool) (func(string) bool, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("pattern must not be empty")
+	}
+	if useRegex {
+		if ignoreCase {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		return re.MatchString, nil
+	}
+	if ignoreCase {
+		lower := strings.ToLower(pattern)
+		return func(line string) bool {
+			return strings.Contains(strings.ToLower(line), lower)
+		}, nil
+	}
+	return func(line string) bool {
+		return strings.Contains(line, pattern)
+	}, nil
+}
+
+// searchLog returns up to limit matching lines (1-based line numbers); limit <= 0 means unbounded.
+func searchLog(text string, match func(string) bool, limit int) []logMatch {
+	var matches []logMatch
+	for i, line := range strings.Split(text, "\n") {
+		if !match(line) {
+			continue
+		}
+		matches = append(matches, logMatch{line: i + 1, text: strings.TrimRight(line, " \t")})
+		if limit > 0 && len(matches) >= limit {
+			break
+		}
+	}
+	return matches
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+func TestSearchLog(t *testing.T) {
+	log := "Started by timer\nBuilding...\nERROR: disk full   \nretrying\nerror: disk full\n"
+
+	tests := []struct {
+		name       string
+		pattern    string
+		regex      bool
+		ignoreCase bool
+		limit      int
+		want       []logMatch
+	}{
+		{
+			name:    "substring",
+			pattern: "ERROR",
+			want:    []logMatch{{line: 3, text: "ERROR: disk full"}},
+		},
+		{
+			name:       "ignore case",
+			pattern:    "error",
+			ignoreCase: true,
+			want:       []logMatch{{line: 3, text: "ERROR: disk full"}, {line: 5, text: "error: disk full"}},
+		},
+		{
+			name:       "limit",
+			pattern:    "disk",
+			ignoreCase: true,
+			limit:      1,
+			want:       []logMatch{{line: 3, text: "ERROR: disk full"}},
+		},
+		{
+			name:    "regex",
+			pattern: `^(Started|retry)`,
+			regex:   true,
+			want:    []logMatch{{line: 1, text: "Started by timer"}, {line: 4, text: "retrying"}},
+		},
+		{
+			name:    "no match",
+			pattern: "OutOfMemory",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := newLineMatcher(tt.pattern, tt.regex, tt.ignoreCase)
+			if err != nil {
+				t.Fatalf("newLineMatcher(%q) error: %v", tt.pattern, err)
+			}
+			got := searchLog(log, match, tt.limit)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searchLog() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrepLogAcrossChunks(t *testing.T) {
+	// The log arrives in chunks that split lines.
+	chunks := []string{"Started\nERR", "OR: disk full\nretry", "ing\nERROR: again"}
+	var fetched []int64
+	client := &jenkinstest.Client{
+		GetProgressiveLogFunc: func(_ context.Context, _, _ string, _ int, start int64) (string, int64, bool, error) {
+			fetched = append(fetched, start)
+			i := len(fetched) - 1
+			return chunks[i], start + int64(len(chunks[i])), i < len(chunks)-1, nil
+		},
+	}
+	match, _ := newLineMatcher("ERROR", false, false)
+
+	matches, truncated, err := grepLog(context.Background(), client, &jenkins.Build{Number: 1}, "api", match, 0)
+	if err != nil || truncated {
+		t.Fatalf("grepLog() truncated %v, error %v", truncated, err)
+	}
+	want := []logMatch{{line: 2, text: "ERROR: disk full"}, {line: 4, text: "ERROR: again"}}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("grepLog() = %+v, want %+v", matches, want)
+	}
+
+	// With a limit, reading stops at the chunk with the last match needed.
+	fetched = nil
+	matches, _, _ = grepLog(context.Background(), client, &jenkins.Build{Number: 1}, "api", match, 1)
+	if len(matches) != 1 || len(fetched) != 2 {
+		t.Errorf("limit 1: %d matches from %d chunks, want 1 from 2", len(matches), len(fetched))
+	}
+}
+
+func TestCompletingGrepPattern(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: nil, want: true},
+		{args: []string{""}, want: true},
+		{args: []string{"-i", "--builds", "3", "OOM"}, want: true},
+		{args: []string{"OOM", ""}, want: false},
+		{args: []string{"--regex", "OOM", "api", "Prod"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := completingGrepPattern(tt.args); got != tt.want {
+			t.Errorf("completingGrepPattern(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
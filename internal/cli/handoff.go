@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/queuelog"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+const (
+	defaultHandoffHours     = 8
+	defaultHandoffBuilds    = 30
+	defaultHandoffLong      = time.Hour
+	defaultHandoffQueueWait = 15 * time.Minute
+	defaultHandoffMaxJobs   = 100
+)
+
+// handoffBuild is a build worth mentioning in a handoff.
+type handoffBuild struct {
+	job   string
+	build jenkins.Build
+}
+
+// handoffReport is what happened to the watched jobs during a shift.
+type handoffReport struct {
+	since time.Time
+	now   time.Time
+	jobs  int
+
+	failed      []handoffBuild
+	aborted     []handoffBuild
+	longRunning []handoffBuild
+	// stuck are the watched jobs' items in the queue now that Jenkins marks
+	// stuck or that have waited longer than the queue wait threshold.
+	stuck []jenkins.QueueItem
+	// longWaits are the logged queue waits of the shift over that threshold.
+	longWaits []queuelog.Entry
+	// unread are the jobs whose history could not be fetched.
+	unread []string
+}
+
+// handoffOptions are the thresholds of a handoff report.
+type handoffOptions struct {
+	builds    int
+	long      time.Duration
+	queueWait time.Duration
+}
+
+// runHandoff summarizes the last hours of the given jobs for a shift handoff,
+// e.g. `jdash handoff --hours 12 Production/ Nightly/e2e`: failed, aborted and
+// long-running builds, items stuck in the queue and, from the local queue
+// log, the builds that waited long for an executor. Arguments ending in "/"
+// expand to every cached job inside that folder.
+func runHandoff(env *Env, args []string) int {
+	fs := flag.NewFlagSet("handoff", flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+	hours := fs.Int("hours", defaultHandoffHours, "how many hours back the report covers")
+	builds := fs.Int("builds", defaultHandoffBuilds, "recent builds read per job")
+	long := fs.Duration("long", defaultHandoffLong, "running builds older than this are reported as long-running")
+	queueWait := fs.Duration("queue-wait", defaultHandoffQueueWait, "queue waits longer than this are reported")
+	maxJobs := fs.Int("max-jobs", defaultHandoffMaxJobs, "maximum number of jobs to read")
+	delay := fs.Duration("delay", defaultGrepDelay, "pause between Jenkins requests")
+	fs.Usage = func() {
+		fmt.Fprintln(env.Stderr, "usage: jdash handoff [flags] <job|folder/>...")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 || *hours <= 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	if *builds <= 0 {
+		*builds = defaultHandoffBuilds
+	}
+
+	targets, err := expandJobTargets(env.Ctx, fs.Args())
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+	if *maxJobs > 0 && len(targets) > *maxJobs {
+		fmt.Fprintf(env.Stderr, "Warning: reading only the first %d of %d jobs (see --max-jobs)\n", *maxJobs, len(targets))
+		targets = targets[:*maxJobs]
+	}
+
+	client, err := newClient()
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+
+	// The queue log is only kept while the dashboard runs; without it the
+	// report just lacks past waits.
+	var entries []queuelog.Entry
+	if log, err := queuelog.Load(); err != nil {
+		fmt.Fprintf(env.Stderr, "Warning: queue log: %v\n", err)
+	} else {
+		entries = log.Entries()
+	}
+
+	now := time.Now()
+	opts := handoffOptions{builds: *builds, long: *long, queueWait: *queueWait}
+	report, err := collectHandoff(env.Ctx, client, targets, now.Add(-time.Duration(*hours)*time.Hour), now, opts, entries, func() {
+		if *delay > 0 {
+			time.Sleep(*delay)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(env.Stderr, "Error: %v\n", err)
+		return exitCodeForError(env.Ctx, err)
+	}
+	report.write(env.Stdout)
+	return exitOK
+}
+
+// collectHandoff reads the recent builds of targets and the queue, calling
+// throttle between requests.
+func collectHandoff(ctx context.Context, client jenkins.JenkinsClient, targets []string, since, now time.Time, opts handoffOptions, entries []queuelog.Entry, throttle func()) (handoffReport, error) {
+	report := handoffReport{since: since, now: now, jobs: len(targets)}
+	watched := make(map[string]bool, len(targets))
+
+	for _, fullName := range targets {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		watched[fullName] = true
+		builds, err := client.GetBuilds(ctx, fullName, 0, opts.builds)
+		throttle()
+		if err != nil {
+			report.unread = append(report.unread, fullName)
+			continue
+		}
+		for _, build := range builds {
+			started := build.GetTimestamp()
+			if build.Building {
+				if now.Sub(started) >= opts.long {
+					report.longRunning = append(report.longRunning, handoffBuild{fullName, build})
+				}
+				continue
+			}
+			if started.Add(build.GetDuration()).Before(since) {
+				// Builds are newest first; the rest ended before the shift.
+				break
+			}
+			switch build.Result {
+			case "FAILURE", "UNSTABLE":
+				report.failed = append(report.failed, handoffBuild{fullName, build})
+			case "ABORTED":
+				report.aborted = append(report.aborted, handoffBuild{fullName, build})
+			}
+		}
+	}
+
+	queue, err := client.GetBuildQueue(ctx)
+	if err != nil {
+		return report, err
+	}
+	for _, item := range queue {
+		if !watched[item.GetJobFullName()] {
+			continue
+		}
+		if item.Stuck || item.GetInQueueDuration() >= opts.queueWait {
+			report.stuck = append(report.stuck, item)
+		}
+	}
+	sort.Slice(report.stuck, func(i, j int) bool { return report.stuck[i].InQueueSince < report.stuck[j].InQueueSince })
+
+	for _, entry := range entries {
+		if watched[entry.Job] && !entry.StartedAt.Before(since) && entry.Outcome == queuelog.Started && entry.Wait() >= opts.queueWait {
+			report.longWaits = append(report.longWaits, entry)
+		}
+	}
+	return report, nil
+}
+
+// write prints the report as plain text that pastes well into chat.
+func (r handoffReport) write(w io.Writer) {
+	fmt.Fprintf(w, "Handoff for %d jobs, %s to %s\n", r.jobs, r.since.Format("Jan 2 15:04"), r.now.Format("Jan 2 15:04"))
+
+	quiet := true
+	section := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		quiet = false
+		fmt.Fprintf(w, "\n%s (%d)\n", title, len(lines))
+		for _, line := range lines {
+			fmt.Fprintf(w, "  - %s\n", line)
+		}
+	}
+
+	section("Failed or unstable", finishedLines(r.failed))
+	section("Aborted", finishedLines(r.aborted))
+
+	var lines []string
+	for _, b := range r.longRunning {
+		lines = append(lines, fmt.Sprintf("%s #%d running for %s", b.job, b.build.Number, utils.FormatDuration(r.now.Sub(b.build.GetTimestamp()))))
+	}
+	section("Long-running", lines)
+
+	lines = nil
+	for _, item := range r.stuck {
+		line := fmt.Sprintf("%s waiting for %s", item.GetJobFullName(), utils.FormatDuration(item.GetInQueueDuration()))
+		if item.Stuck {
+			line += " (stuck)"
+		}
+		if why := strings.TrimSpace(item.Why); why != "" {
+			line += ": " + why
+		}
+		lines = append(lines, line)
+	}
+	section("In the queue", lines)
+
+	lines = nil
+	for _, entry := range r.longWaits {
+		lines = append(lines, fmt.Sprintf("%s #%d waited %s, started %s", entry.Job, entry.Build, utils.FormatDuration(entry.Wait()), entry.StartedAt.Local().Format("15:04")))
+	}
+	section("Long queue waits", lines)
+
+	if quiet {
+		fmt.Fprintln(w, "\nAll quiet: no failed, aborted or long-running builds and nothing stuck in the queue.")
+	}
+	if len(r.unread) > 0 {
+		fmt.Fprintf(w, "\nCould not read: %s\n", strings.Join(r.unread, ", "))
+	}
+}
+
+// finishedLines reads e.g. "Production/api #482 FAILURE at 14:02 after 12m 3s".
+func finishedLines(builds []handoffBuild) []string {
+	lines := make([]string, 0, len(builds))
+	for _, b := range builds {
+		finished := b.build.GetTimestamp().Add(b.build.GetDuration())
+		lines = append(lines, fmt.Sprintf("%s #%d %s at %s after %s", b.job, b.build.Number, b.build.Result,
+			finished.Local().Format("15:04"), utils.FormatDuration(b.build.GetDuration())))
+	}
+	return lines
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+	"github.com/gorbach/jdash/internal/queuelog"
+)
+
+func TestHandoffReport(t *testing.T) {
+	now := time.Now()
+	since := now.Add(-8 * time.Hour)
+	ms := func(ago time.Duration) int64 { return now.Add(-ago).UnixMilli() }
+	minutes := func(n int) int64 { return int64(time.Duration(n) * time.Minute / time.Millisecond) }
+
+	client := &jenkinstest.Client{
+		GetBuildsFunc: func(ctx context.Context, fullName string, offset, limit int) ([]jenkins.Build, error) {
+			switch fullName {
+			case "Production/api":
+				return []jenkins.Build{
+					{Number: 484, Building: true, Timestamp: ms(90 * time.Minute)},
+					{Number: 483, Result: "FAILURE", Timestamp: ms(3 * time.Hour), Duration: minutes(12)},
+					{Number: 482, Result: "ABORTED", Timestamp: ms(5 * time.Hour), Duration: minutes(2)},
+					{Number: 481, Result: "FAILURE", Timestamp: ms(20 * time.Hour), Duration: minutes(12)},
+				}, nil
+			case "Production/web":
+				return []jenkins.Build{{Number: 7, Result: "SUCCESS", Timestamp: ms(time.Hour)}}, nil
+			}
+			return nil, errors.New("failed to fetch builds: status 404")
+		},
+		GetBuildQueueFunc: func(ctx context.Context) ([]jenkins.QueueItem, error) {
+			stuck := jenkins.QueueItem{ID: 1, Stuck: true, Why: "There are no nodes with the label 'arm64'", InQueueSince: ms(40 * time.Minute)}
+			stuck.Task.URL = "https://ci.example.com/job/Production/job/web/"
+			other := jenkins.QueueItem{ID: 2, Stuck: true, InQueueSince: ms(time.Hour)}
+			other.Task.URL = "https://ci.example.com/job/Sandbox/job/toy/"
+			return []jenkins.QueueItem{stuck, other}, nil
+		},
+	}
+	entries := []queuelog.Entry{
+		{Job: "Production/web", Build: 6, EnqueuedAt: now.Add(-4 * time.Hour), StartedAt: now.Add(-3 * time.Hour), Outcome: queuelog.Started},
+		{Job: "Production/web", Build: 5, EnqueuedAt: now.Add(-150 * time.Minute), StartedAt: now.Add(-149 * time.Minute), Outcome: queuelog.Started},
+		{Job: "Production/web", Build: 4, EnqueuedAt: now.Add(-12 * time.Hour), StartedAt: now.Add(-10 * time.Hour), Outcome: queuelog.Started},
+	}
+
+	opts := handoffOptions{builds: 30, long: time.Hour, queueWait: 15 * time.Minute}
+	report, err := collectHandoff(context.Background(), client, []string{"Production/api", "Production/web", "Production/gone"}, since, now, opts, entries, func() {})
+	if err != nil {
+		t.Fatalf("collectHandoff() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	report.write(&out)
+	text := out.String()
+	for _, want := range []string{
+		"Handoff for 3 jobs",
+		"Failed or unstable (1)\n  - Production/api #483 FAILURE",
+		"Aborted (1)\n  - Production/api #482 ABORTED",
+		"Long-running (1)\n  - Production/api #484 running for 1h 30m",
+		"In the queue (1)\n  - Production/web waiting for 40m",
+		"(stuck): There are no nodes with the label 'arm64'",
+		"Long queue waits (1)\n  - Production/web #6 waited 1h",
+		"Could not read: Production/gone",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report does not contain %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "#481") || strings.Contains(text, "Sandbox") {
+		t.Errorf("report mentions builds before the shift or unwatched jobs:\n%s", text)
+	}
+}
+
+func TestHandoffReportAllQuiet(t *testing.T) {
+	now := time.Now()
+	var out bytes.Buffer
+	handoffReport{since: now.Add(-time.Hour), now: now, jobs: 2}.write(&out)
+	if !strings.Contains(out.String(), "All quiet") {
+		t.Errorf("empty report = %q, want it to say all is quiet", out.String())
+	}
+}
@@ -0,0 +1,322 @@
+// Package concurrent is a modal listing every running build of a job that
+// allows concurrent builds, where the details panel only acts on the last.
+package concurrent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/inflight"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+const modalWidth = 72
+
+// ScanBuilds is how many of the newest builds are looked through for running
+// ones. Builds older than that are very unlikely to still run.
+const ScanBuilds = 50
+
+// ClosedMsg is emitted when the modal is dismissed. Aborted is set when a
+// build was aborted from it, so the job's details are out of date.
+type ClosedMsg struct {
+	Aborted bool
+}
+
+// OpenLogsMsg asks for the console of a running build.
+type OpenLogsMsg struct {
+	Job   jenkins.Job
+	Build jenkins.Build
+}
+
+type buildsFetchedMsg struct {
+	builds []jenkins.Build
+	err    error
+}
+
+type abortedMsg struct {
+	number int
+	err    error
+}
+
+// Running returns the builds still running among builds.
+func Running(builds []jenkins.Build) []jenkins.Build {
+	var running []jenkins.Build
+	for _, build := range builds {
+		if build.Building {
+			running = append(running, build)
+		}
+	}
+	return running
+}
+
+// Model lists the running builds of a job, newest first, to open their
+// consoles or abort them.
+type Model struct {
+	client  jenkins.JenkinsClient
+	tracker *inflight.Tracker
+	policy  confirm.Policy
+	job     jenkins.Job
+	// ctx is cancelled once the modal closes, dropping a fetch still
+	// running; aborts run on the tracker's context instead.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	spinner spinner.Model
+	builds  []jenkins.Build
+	loading bool
+	err     error
+	cursor  int
+
+	// prompt asks before aborting build number abort, as the policy requires.
+	prompt *confirm.Prompt
+	abort  int
+	// aborting holds the builds whose abort Jenkins has not answered yet.
+	aborting map[int]bool
+	aborted  bool
+	// notice reports the last abort, e.g. "✓ Aborted #42".
+	notice      string
+	noticeIsErr bool
+
+	width  int
+	height int
+}
+
+// New creates the modal for job. Aborts ask as policy requires and are
+// registered with tracker so quitting can wait for them.
+func New(client jenkins.JenkinsClient, tracker *inflight.Tracker, policy confirm.Policy, job jenkins.Job) *Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.HighlightStyle
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Model{
+		client:   client,
+		tracker:  tracker,
+		policy:   policy,
+		job:      job,
+		ctx:      ctx,
+		cancel:   cancel,
+		spinner:  s,
+		aborting: make(map[int]bool),
+	}
+}
+
+// CapturesInput reports whether a prompt wants the job name typed.
+func (m *Model) CapturesInput() bool {
+	return m.prompt != nil && m.prompt.CapturesInput()
+}
+
+// Init fetches the running builds.
+func (m *Model) Init() tea.Cmd {
+	return m.fetchCmd()
+}
+
+// Update handles TEA messages for the modal.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case buildsFetchedMsg:
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.builds = Running(msg.builds)
+			m.cursor = min(m.cursor, max(len(m.builds)-1, 0))
+		}
+		return m, nil
+
+	case abortedMsg:
+		delete(m.aborting, msg.number)
+		if msg.err != nil {
+			m.setNotice(fmt.Sprintf("✗ Failed to abort #%d: %v", msg.number, msg.err), true)
+			return m, nil
+		}
+		m.aborted = true
+		m.setNotice(fmt.Sprintf("✓ Aborted #%d", msg.number), false)
+		return m, m.fetchCmd()
+
+	case spinner.TickMsg:
+		if !m.loading && len(m.aborting) == 0 {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.prompt != nil {
+		prompt, result := m.prompt.HandleKey(msg)
+		m.prompt = &prompt
+		switch result {
+		case confirm.Confirmed:
+			m.prompt = nil
+			return m, m.abortCmd(m.abort)
+		case confirm.Cancelled:
+			m.prompt = nil
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "B":
+		m.cancel()
+		aborted := m.aborted
+		return m, func() tea.Msg { return ClosedMsg{Aborted: aborted} }
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.builds)-1, 0))
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "enter", "l":
+		if build := m.selected(); build != nil {
+			m.cancel()
+			job, build := m.job, *build
+			return m, func() tea.Msg { return OpenLogsMsg{Job: job, Build: build} }
+		}
+	case "a":
+		build := m.selected()
+		if build == nil || m.aborting[build.Number] {
+			return m, nil
+		}
+		level := m.policy.LevelFor(confirm.ActionAbort, m.job.FullName)
+		if level == confirm.None {
+			return m, m.abortCmd(build.Number)
+		}
+		prompt := confirm.NewPrompt(level, fmt.Sprintf("Abort running build #%d?", build.Number), m.job.Name)
+		m.prompt, m.abort = &prompt, build.Number
+		m.notice = ""
+	case "r":
+		if !m.loading {
+			return m, m.fetchCmd()
+		}
+	}
+	return m, nil
+}
+
+func (m *Model) selected() *jenkins.Build {
+	if m.cursor < 0 || m.cursor >= len(m.builds) {
+		return nil
+	}
+	return &m.builds[m.cursor]
+}
+
+func (m *Model) setNotice(text string, isErr bool) {
+	m.notice = text
+	m.noticeIsErr = isErr
+}
+
+func (m *Model) fetchCmd() tea.Cmd {
+	m.loading = true
+	client, ctx := m.client, m.ctx
+	fullName := m.job.FullName
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		if client == nil {
+			return buildsFetchedMsg{err: fmt.Errorf("Jenkins client not configured")}
+		}
+		builds, err := client.GetBuilds(ctx, fullName, 0, ScanBuilds)
+		return buildsFetchedMsg{builds: builds, err: err}
+	})
+}
+
+func (m *Model) abortCmd(number int) tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+	m.aborting[number] = true
+	client := m.client
+	fullName := m.job.FullName
+	ctx, done := m.tracker.Start(fmt.Sprintf("Abort %s #%d", fullName, number))
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		err := client.AbortBuild(ctx, fullName, number)
+		done(err)
+		return abortedMsg{number: number, err: err}
+	})
+}
+
+// View renders the modal.
+func (m *Model) View() string {
+	var content strings.Builder
+	content.WriteString(ui.TitleStyle.Render("Running builds: " + m.job.FullName))
+	content.WriteString("\n\n")
+
+	for i := range m.builds {
+		line := m.renderBuild(&m.builds[i])
+		if i == m.cursor {
+			line = ui.SelectedStyle.Render(line)
+		}
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+
+	switch {
+	case m.err != nil:
+		content.WriteString(ui.ErrorStyle.Render("✗ Failed to load builds: " + m.err.Error()))
+		content.WriteString("\n")
+	case m.loading && len(m.builds) == 0:
+		content.WriteString(fmt.Sprintf("%s Loading builds...\n", m.spinner.View()))
+	case len(m.builds) == 0:
+		content.WriteString(ui.SubtleStyle.Render("No builds running"))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	switch {
+	case m.prompt != nil:
+		content.WriteString(ui.HighlightStyle.Render(m.prompt.View()))
+	default:
+		if m.notice != "" {
+			style := ui.SuccessStyle
+			if m.noticeIsErr {
+				style = ui.ErrorStyle
+			}
+			content.WriteString(style.Render(m.notice))
+			content.WriteString("\n")
+		}
+		content.WriteString(ui.SubtleStyle.Render("[j/k] Move  [Enter] Logs  [a] Abort  [r] Reload  [Esc] Close"))
+	}
+
+	panel := lipgloss.NewStyle().
+		Width(modalWidth).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(content.String())
+
+	if m.width == 0 || m.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
+}
+
+// renderBuild renders one row: how long the build has run and who started it.
+func (m *Model) renderBuild(build *jenkins.Build) string {
+	status := build.GetStatus()
+	line := fmt.Sprintf("%s #%-6d %8s  %s",
+		ui.GetStatusStyle(status).Render(ui.GetStatusIcon(status)),
+		build.Number,
+		utils.FormatDuration(time.Since(build.GetTimestamp())),
+		ui.SubtleStyle.Render(utils.TruncateString(build.GetTriggeredBy(), 32)),
+	)
+	if branch := build.GetBranch(); branch != "" {
+		line += "  " + ui.SubtleStyle.Render(branch)
+	}
+	if m.aborting[build.Number] {
+		line += "  " + m.spinner.View() + " aborting"
+	}
+	return line
+}
@@ -0,0 +1,78 @@
+package concurrent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/inflight"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+// run executes cmd, skipping the spinner tick batched with the request.
+func run(cmd tea.Cmd) tea.Msg {
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		return batch[len(batch)-1]()
+	}
+	return msg
+}
+
+func key(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestListsRunningBuildsAndAbortsOne(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetBuildsFunc: func(context.Context, string, int, int) ([]jenkins.Build, error) {
+			return []jenkins.Build{{Number: 14, Building: true}, {Number: 13, Result: "SUCCESS"}, {Number: 12, Building: true}}, nil
+		},
+	}
+	tracker := inflight.New()
+	m := New(client, tracker, confirm.Default(), jenkins.Job{Name: "api", FullName: "team/api"})
+	m.Update(run(m.Init()))
+	view := m.View()
+	if !strings.Contains(view, "#14") || !strings.Contains(view, "#12") || strings.Contains(view, "#13") {
+		t.Fatalf("view does not list just the running builds:\n%s", view)
+	}
+
+	// Abort the second one, answering the default y/N prompt.
+	m.Update(key("j"))
+	m.Update(key("a"))
+	if !strings.Contains(m.View(), "Abort running build #12?") {
+		t.Fatalf("a did not ask before aborting:\n%s", m.View())
+	}
+	_, cmd := m.Update(key("y"))
+	if got := tracker.Pending(); len(got) != 1 || got[0] != "Abort team/api #12" {
+		t.Errorf("Pending() = %v, want the abort tracked", got)
+	}
+	m.Update(run(cmd))
+	calls := client.CallsTo("AbortBuild")
+	if len(calls) != 1 || calls[0].Args[1] != 12 {
+		t.Fatalf("AbortBuild calls = %+v, want #12", calls)
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if closed := cmd().(ClosedMsg); !closed.Aborted {
+		t.Error("closed without reporting the abort")
+	}
+}
+
+func TestEnterOpensConsoleOfSelectedBuild(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetBuildsFunc: func(context.Context, string, int, int) ([]jenkins.Build, error) {
+			return []jenkins.Build{{Number: 9, Building: true}, {Number: 8, Building: true}}, nil
+		},
+	}
+	m := New(client, nil, confirm.Default(), jenkins.Job{Name: "api", FullName: "api"})
+	m.Update(run(m.Init()))
+	m.Update(key("j"))
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if open, ok := cmd().(OpenLogsMsg); !ok || open.Build.Number != 8 || open.Job.FullName != "api" {
+		t.Errorf("enter emitted %+v, want the console of api #8", open)
+	}
+}
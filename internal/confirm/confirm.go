@@ -0,0 +1,176 @@
+// Package confirm decides which actions need confirmation before they run.
+package confirm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Level is how much confirmation an action needs.
+type Level int
+
+const (
+	// None runs the action straight away.
+	None Level = iota
+	// YesNo asks a y/N question.
+	YesNo
+	// TypeName makes the user type the job name before the action runs.
+	TypeName
+)
+
+// Action is a confirmable action.
+type Action string
+
+const (
+	ActionTrigger     Action = "trigger"
+	ActionAbort       Action = "abort"
+	ActionCancelQueue Action = "cancelQueue"
+	ActionDisable     Action = "disable"
+	ActionDelete      Action = "delete"
+	ActionPromote     Action = "promote"
+)
+
+// Rule overrides the level of some actions for jobs matching a pattern.
+type Rule struct {
+	// Pattern is a case-insensitive glob on the job full name; * and ? also
+	// match "/", so "*prod*" covers "Deploy/prod-eu".
+	Pattern string   `json:"pattern"`
+	Actions []Action `json:"actions"`
+	// Level is "none", "yes" or "typeName".
+	Level string `json:"level"`
+}
+
+// Config is the "confirm" section of the config file. Each action takes a
+// level name; empty keeps the default.
+type Config struct {
+	Trigger     string `json:"trigger"`
+	Abort       string `json:"abort"`
+	CancelQueue string `json:"cancelQueue"`
+	Disable     string `json:"disable"`
+	Delete      string `json:"delete"`
+	Promote     string `json:"promote"`
+	Rules       []Rule `json:"rules"`
+}
+
+// Policy answers how much confirmation an action on a job needs.
+type Policy struct {
+	base  map[Action]Level
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	pattern *regexp.Regexp
+	actions map[Action]bool
+	level   Level
+}
+
+// Default asks before aborting, cancelling a queued build or disabling a job,
+// makes the user type the name of a job to delete or to force a promotion on,
+// and triggers builds without asking.
+func Default() Policy {
+	return Policy{base: map[Action]Level{
+		ActionTrigger:     None,
+		ActionAbort:       YesNo,
+		ActionCancelQueue: YesNo,
+		ActionDisable:     YesNo,
+		ActionDelete:      TypeName,
+		ActionPromote:     TypeName,
+	}}
+}
+
+// Policy validates the config and builds the policy it describes. On error
+// the returned policy is Default.
+func (c Config) Policy() (Policy, error) {
+	policy := Default()
+	for action, name := range map[Action]string{
+		ActionTrigger:     c.Trigger,
+		ActionAbort:       c.Abort,
+		ActionCancelQueue: c.CancelQueue,
+		ActionDisable:     c.Disable,
+		ActionDelete:      c.Delete,
+		ActionPromote:     c.Promote,
+	} {
+		if name == "" {
+			continue
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return Default(), fmt.Errorf("confirm.%s: %w", action, err)
+		}
+		policy.base[action] = level
+	}
+
+	for i, rule := range c.Rules {
+		level, err := ParseLevel(rule.Level)
+		if err != nil {
+			return Default(), fmt.Errorf("confirm.rules[%d]: %w", i, err)
+		}
+		if rule.Pattern == "" {
+			return Default(), fmt.Errorf("confirm.rules[%d]: pattern must not be empty", i)
+		}
+		actions := make(map[Action]bool, len(rule.Actions))
+		for _, action := range rule.Actions {
+			if _, ok := policy.base[action]; !ok {
+				return Default(), fmt.Errorf("confirm.rules[%d]: unknown action %q", i, action)
+			}
+			actions[action] = true
+		}
+		policy.rules = append(policy.rules, compiledRule{
+			pattern: globToRegexp(rule.Pattern),
+			actions: actions,
+			level:   level,
+		})
+	}
+	return policy, nil
+}
+
+// ParseLevel converts a level name from the config file.
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "none", "never":
+		return None, nil
+	case "yes", "yesno", "confirm":
+		return YesNo, nil
+	case "typename":
+		return TypeName, nil
+	default:
+		return None, fmt.Errorf("unknown confirmation level %q (want none, yes or typeName)", name)
+	}
+}
+
+// LevelFor returns the confirmation level of action on the job. The first rule
+// matching both the action and the job wins; a rule without actions applies
+// to all of them.
+func (p Policy) LevelFor(action Action, jobFullName string) Level {
+	for _, rule := range p.rules {
+		if len(rule.actions) > 0 && !rule.actions[action] {
+			continue
+		}
+		if rule.pattern.MatchString(jobFullName) {
+			return rule.level
+		}
+	}
+	if p.base == nil {
+		return Default().base[action]
+	}
+	return p.base[action]
+}
+
+// globToRegexp compiles a glob in which * and ? match any characters.
+func globToRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("(?i)^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,117 @@
+package confirm
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPolicyLevelFor(t *testing.T) {
+	policy, err := Config{
+		Abort: "none",
+		Rules: []Rule{
+			{Pattern: "*prod*", Actions: []Action{ActionTrigger, ActionDisable}, Level: "typeName"},
+			{Pattern: "Sandbox/*", Level: "none"},
+		},
+	}.Policy()
+	if err != nil {
+		t.Fatalf("Policy() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		action Action
+		job    string
+		want   Level
+	}{
+		{name: "trigger defaults to none", action: ActionTrigger, job: "Team/api", want: None},
+		{name: "trigger on prod job", action: ActionTrigger, job: "Deploy/PROD-eu", want: TypeName},
+		{name: "disable on prod job", action: ActionDisable, job: "Deploy/prod-eu", want: TypeName},
+		{name: "abort overridden globally", action: ActionAbort, job: "Deploy/prod-eu", want: None},
+		{name: "cancel queue keeps default", action: ActionCancelQueue, job: "Team/api", want: YesNo},
+		{name: "delete defaults to typing the name", action: ActionDelete, job: "Team/api", want: TypeName},
+		{name: "promote defaults to typing the name", action: ActionPromote, job: "Team/api", want: TypeName},
+		{name: "rule without actions covers all", action: ActionCancelQueue, job: "Sandbox/try", want: None},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.LevelFor(tt.action, tt.job); got != tt.want {
+				t.Errorf("LevelFor(%q, %q) = %v, want %v", tt.action, tt.job, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigPolicyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{name: "unknown level", config: Config{Trigger: "maybe"}},
+		{name: "unknown rule action", config: Config{Rules: []Rule{{Pattern: "*", Actions: []Action{"rename"}, Level: "yes"}}}},
+		{name: "empty pattern", config: Config{Rules: []Rule{{Level: "yes"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := tt.config.Policy()
+			if err == nil {
+				t.Fatal("Policy() error = nil, want an error")
+			}
+			if got := policy.LevelFor(ActionAbort, "job"); got != YesNo {
+				t.Errorf("fallback policy abort level = %v, want YesNo", got)
+			}
+		})
+	}
+}
+
+func TestPromptTypeName(t *testing.T) {
+	prompt := NewPrompt(TypeName, "Trigger prod-eu?", "prod-eu")
+	if !prompt.CapturesInput() {
+		t.Fatal("CapturesInput() = false for a TypeName prompt")
+	}
+
+	var result Result
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("prod-e")},
+		{Type: tea.KeyEnter},
+	} {
+		prompt, result = prompt.HandleKey(key)
+	}
+	if result != Pending {
+		t.Fatalf("Enter on a partial name = %v, want Pending", result)
+	}
+
+	for _, key := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("x")},
+		{Type: tea.KeyBackspace},
+		{Type: tea.KeyRunes, Runes: []rune("u")},
+		{Type: tea.KeyEnter},
+	} {
+		prompt, result = prompt.HandleKey(key)
+	}
+	if result != Confirmed {
+		t.Errorf("Enter on the full name = %v, want Confirmed", result)
+	}
+}
+
+func TestPromptYesNo(t *testing.T) {
+	tests := []struct {
+		key  string
+		want Result
+	}{
+		{key: "y", want: Confirmed},
+		{key: "n", want: Cancelled},
+		{key: "q", want: Cancelled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			_, got := NewPrompt(YesNo, "Abort?", "").HandleKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+			if got != tt.want {
+				t.Errorf("HandleKey(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package confirm
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Result is the outcome of a key press on a prompt.
+type Result int
+
+const (
+	Pending Result = iota
+	Confirmed
+	Cancelled
+)
+
+// Prompt is a confirmation waiting for an answer. The zero value is not useful;
+// use NewPrompt.
+type Prompt struct {
+	question string
+	level    Level
+	// name is what the user must type at the TypeName level.
+	name  string
+	typed []rune
+}
+
+// NewPrompt asks question at the given level; name is the text to type for
+// TypeName prompts, usually the job name.
+func NewPrompt(level Level, question, name string) Prompt {
+	return Prompt{question: question, level: level, name: name}
+}
+
+// CapturesInput reports whether the prompt consumes all typing, so global
+// shortcuts must not fire while it is open.
+func (p Prompt) CapturesInput() bool {
+	return p.level == TypeName
+}
+
+// HandleKey feeds one key press to the prompt.
+func (p Prompt) HandleKey(msg tea.KeyMsg) (Prompt, Result) {
+	if p.level != TypeName {
+		switch msg.String() {
+		case "y", "Y", "enter":
+			return p, Confirmed
+		default:
+			return p, Cancelled
+		}
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		return p, Cancelled
+	case tea.KeyEnter:
+		if string(p.typed) == p.name {
+			return p, Confirmed
+		}
+		return p, Pending
+	case tea.KeyBackspace:
+		if len(p.typed) > 0 {
+			p.typed = p.typed[:len(p.typed)-1]
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.typed = append(append([]rune(nil), p.typed...), msg.Runes...)
+	}
+	return p, Pending
+}
+
+// View renders the question and, for TypeName prompts, the text typed so far.
+func (p Prompt) View() string {
+	if p.level != TypeName {
+		return p.question + " (y/N)"
+	}
+	return fmt.Sprintf("%s Type %q and press Enter (Esc cancels): %s▌", p.question, p.name, string(p.typed))
+}
@@ -0,0 +1,34 @@
+package console
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// failurePattern matches the console lines smart tail stops on: error and
+// fatal markers of common build tools (Maven, Gradle, npm, go test, pytest),
+// exceptions and tracebacks, and panics. Summary lines such as "0 errors" are
+// deliberately not matched.
+var failurePattern = regexp.MustCompile(
+	`^\s*(\[(ERROR|FATAL)\]|(?i:error|fatal|panic):|npm ERR!|--- FAIL:|FAILED\b|FAILURE:|Traceback \(most recent call last\)|Exception in thread)` +
+		`|\bBUILD FAILED\b|\bERROR:|\b[A-Z]\w*(Exception|Error):`)
+
+// findFailure scans the complete lines of content from byte offset from and
+// returns the byte range of the first failure line, or ok=false. scanned is
+// where the next scan has to start: after the last complete line, so a line
+// still being streamed is scanned once it ends.
+func findFailure(content []byte, from int) (start, end, scanned int, ok bool) {
+	last := bytes.LastIndexByte(content, '\n')
+	if last < from {
+		return 0, 0, from, false
+	}
+	scanned = last + 1
+
+	for start = from; start < scanned; start = end + 1 {
+		end = start + bytes.IndexByte(content[start:], '\n')
+		if failurePattern.Match(content[start:end]) {
+			return start, end, end + 1, true
+		}
+	}
+	return 0, 0, scanned, false
+}
@@ -0,0 +1,61 @@
+package console
+
+import "testing"
+
+func TestFailurePattern(t *testing.T) {
+	failures := []string{
+		"[ERROR] Failed to execute goal org.apache.maven.plugins:maven-compiler-plugin",
+		"ERROR: script returned exit code 1",
+		"error: cannot find symbol",
+		"npm ERR! code ELIFECYCLE",
+		"--- FAIL: TestCheckout (0.01s)",
+		"FAILED tests/test_api.py::test_login - AssertionError",
+		"FAILURE: Build failed with an exception.",
+		"Traceback (most recent call last):",
+		`Exception in thread "main" java.lang.IllegalStateException`,
+		"panic: runtime error: index out of range",
+		"java.lang.NullPointerException: Cannot invoke \"String.length()\"",
+		"TypeError: undefined is not a function",
+		"BUILD FAILED in 12s",
+	}
+	for _, line := range failures {
+		if !failurePattern.MatchString(line) {
+			t.Errorf("failurePattern does not match %q", line)
+		}
+	}
+
+	ordinary := []string{
+		"[INFO] BUILD SUCCESS",
+		"Tests run: 42, Failures: 0, Errors: 0, Skipped: 1",
+		"0 errors, 3 warnings",
+		"[Pipeline] { (Error handling)",
+		"Compiling errors.go",
+		"+ echo no errors found",
+	}
+	for _, line := range ordinary {
+		if failurePattern.MatchString(line) {
+			t.Errorf("failurePattern matches %q", line)
+		}
+	}
+}
+
+func TestFindFailure(t *testing.T) {
+	content := []byte("Building\n[ERROR] compile failed\nmore\nERROR: partial")
+
+	start, end, scanned, ok := findFailure(content, 0)
+	if !ok || string(content[start:end]) != "[ERROR] compile failed" {
+		t.Fatalf("findFailure() = %q, %v, want the [ERROR] line", content[start:end], ok)
+	}
+
+	// The next scan resumes after the pinned line and skips the unfinished one.
+	_, _, scanned, ok = findFailure(content, scanned)
+	if ok || scanned != len("Building\n[ERROR] compile failed\nmore\n") {
+		t.Errorf("findFailure() after the pin = scanned %d, %v, want the end of the last complete line", scanned, ok)
+	}
+
+	content = append(content, " line\n"...)
+	start, end, _, ok = findFailure(content, scanned)
+	if !ok || string(content[start:end]) != "ERROR: partial line" {
+		t.Errorf("findFailure() once the line ends = %q, %v, want the completed ERROR line", content[start:end], ok)
+	}
+}
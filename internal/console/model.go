@@ -1,6 +1,8 @@
 package console
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -9,7 +11,11 @@
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/activity"
+	"github.com/gorbach/jdash/internal/bookmarks"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/logtransform"
+	"github.com/gorbach/jdash/internal/ticket"
 	"github.com/gorbach/jdash/internal/ui"
 	"github.com/gorbach/jdash/internal/utils"
 )
@@ -31,6 +37,9 @@ type OpenRequestMsg struct {
 	JobFullName string
 	BuildNumber int
 	BuildURL    string
+	// Pipeline reads the log with its annotations, so each line can be
+	// attributed to its stage.
+	Pipeline bool
 }
 
 // DeactivateMsg signals that the console view is no longer visible and should pause background work.
@@ -49,6 +58,10 @@ type logsChunkMsg struct {
 	nextOffset int64
 	more       bool
 	err        error
+	// segments and nodes are set for Pipeline builds, whose content comes
+	// split by the flow node that wrote it.
+	segments []jenkins.LogSegment
+	nodes    []jenkins.LogNode
 }
 
 // RefreshRequestedMsg asks the console view to fetch the latest logs.
@@ -67,20 +80,61 @@ type Model struct {
 	buildNumber int
 	hasTarget   bool
 
-	autoScroll    bool
-	shouldPoll    bool
+	autoScroll bool
+	shouldPoll bool
+	// smartTail pauses auto-scroll on the first failure line that arrives;
+	// see failurePattern.
+	smartTail     bool
 	pollInterval  time.Duration
+	idle          bool
+	paused        bool
 	fetchInFlight bool
-	session       uint64
+	session       ticket.Counter
 	nextOffset    int64
 	buildURL      string
 
-	content       []byte
-	hasContent    bool
-	idlePolls     int
-	lastUpdated   time.Time
-	err           error
-	concealActive bool
+	// cancel aborts the HTTP request of the current session when the target
+	// changes or the console is hidden.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	content    []byte
+	hasContent bool
+	// scanOffset is where smart tail looks for failures next, and pin the
+	// byte range of the line it stopped on, if any.
+	scanOffset int
+	pin        *failurePin
+
+	// bookmarks persists marks and the read position per build; marks holds
+	// the current log's (0-based lines), restoreLine the position to return
+	// to once the log is long enough (0 for none).
+	bookmarks   *bookmarks.Store
+	marks       map[int]bool
+	restoreLine int
+	idlePolls   int
+	lastUpdated time.Time
+	err         error
+	// sanitizer strips and redacts the log, holding back an unfinished last
+	// line; sanitizedNode is the flow node that line came from.
+	sanitizer     utils.LogSanitizer
+	sanitizedNode string
+
+	// pipeline builds are read with their annotations: lineNodes holds the
+	// flow node that wrote each line (0-based) and stages resolves those to
+	// the stages they ran in.
+	pipeline  bool
+	lineNodes []string
+	stages    jenkins.StageIndex
+	// folded holds the labels of the stages shown as one line each, and
+	// rows the log line each row of the view starts with while any are
+	// (nil while none are, when rows and lines match).
+	folded map[string]bool
+	rows   []int
+
+	// transforms are the configured log transforms and transform those of
+	// them that apply to the current job, or nil.
+	transforms logtransform.Set
+	transform  *logtransform.Transformer
 
 	searchInput   textinput.Model
 	searchActive  bool
@@ -89,6 +143,33 @@ type Model struct {
 	statusMessage string
 }
 
+// failurePin is a failure line smart tail paused on.
+type failurePin struct {
+	start, end int
+	line       int
+}
+
+// failureLineStyle and bookmarkLineStyle mark pinned lines. They are built
+// on use to follow the theme chosen at startup.
+func failureLineStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(ui.ColorFailed).
+		Bold(true).
+		Reverse(true)
+}
+
+func bookmarkLineStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(ui.ColorSearchHighlight).
+		Reverse(true)
+}
+
+func foldLineStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(ui.ColorHighlight).
+		Italic(true)
+}
+
 // New creates a new console model.
 func New(client jenkins.JenkinsClient) Model {
 	vp := viewport.New(0, 0)
@@ -107,6 +188,12 @@ func New(client jenkins.JenkinsClient) Model {
 	}
 }
 
+// WithBookmarks keeps bookmarks and read positions of logs in store.
+func (m Model) WithBookmarks(store *bookmarks.Store) Model {
+	m.bookmarks = store
+	return m
+}
+
 // Init initializes the console model.
 func (m Model) Init() tea.Cmd {
 	return nil
@@ -135,7 +222,25 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 
 	case DeactivateMsg:
-		m = m.handleDeactivate()
+		var cmd tea.Cmd
+		m, cmd = m.handleDeactivate()
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case activity.IdleChangedMsg:
+		m.idle = msg.Idle
+
+	case activity.PausedChangedMsg:
+		m.paused = msg.Paused
+		// The poll dropped while paused ended the chain; start it again.
+		if !m.paused && m.hasTarget && m.shouldPoll && !m.fetchInFlight {
+			var cmd tea.Cmd
+			m, cmd = m.startFetch()
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
 
 	case RefreshRequestedMsg:
 		if m.hasTarget {
@@ -149,7 +254,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 
 	case pollLogsMsg:
-		if msg.session == m.session && m.shouldPoll && !m.fetchInFlight {
+		if m.session.IsCurrent(msg.session) && m.shouldPoll && !m.fetchInFlight && !m.paused {
 			var cmd tea.Cmd
 			m, cmd = m.startFetch()
 			if cmd != nil {
@@ -158,7 +263,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 
 	case logsChunkMsg:
-		if msg.session == m.session {
+		if m.session.IsCurrent(msg.session) {
 			var cmd tea.Cmd
 			m, cmd = m.handleLogsChunk(msg)
 			if cmd != nil {
@@ -186,17 +291,37 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// TitleBar describes the console panel header for the streamed build.
+func (m Model) TitleBar() ui.TitleBar {
+	bar := ui.TitleBar{Name: "Console"}
+	if !m.hasTarget {
+		return bar
+	}
+	bar.Name = fmt.Sprintf("Console: %s #%d", m.jobName, m.buildNumber)
+	if m.shouldPoll || m.fetchInFlight {
+		bar.Chips = append(bar.Chips, "streaming")
+	}
+	switch {
+	case m.pin != nil:
+		bar.Chips = append(bar.Chips, "paused on failure")
+	case !m.autoScroll:
+		bar.Chips = append(bar.Chips, "paused")
+	case m.smartTail:
+		bar.Chips = append(bar.Chips, "smart tail")
+	}
+	if stage := m.stageLabel(m.topLine()); stage != "" {
+		bar.Chips = append(bar.Chips, stage)
+	}
+	return bar
+}
+
 // View renders the console view.
 func (m Model) View() string {
-	title := ui.TitleStyle.Render(fmt.Sprintf("Console: %s #%d", m.jobName, m.buildNumber))
-
 	if !m.hasTarget {
-		notice := ui.SubtleStyle.Render("No build selected. Trigger a build to view console logs.")
-		return lipgloss.JoinVertical(lipgloss.Left, title, notice)
+		return ui.SubtleStyle.Render("No build selected. Trigger a build to view console logs.")
 	}
 
 	var sections []string
-	sections = append(sections, title)
 
 	if m.err != nil {
 		sections = append(sections, ui.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
@@ -224,7 +349,11 @@ func (m Model) View() string {
 func (m Model) renderStatusLine() string {
 	auto := "OFF"
 	autoStyle := ui.SubtleStyle
-	if m.autoScroll {
+	switch {
+	case m.autoScroll && m.smartTail:
+		auto = "SMART"
+		autoStyle = ui.HighlightStyle
+	case m.autoScroll:
 		auto = "ON"
 		autoStyle = ui.HighlightStyle
 	}
@@ -241,10 +370,29 @@ func (m Model) renderStatusLine() string {
 
 	parts := []string{
 		autoStyle.Render(fmt.Sprintf("[Auto-scroll: %s]", auto)),
+	}
+	if m.pipeline && len(m.lineNodes) > 0 {
+		parts = append(parts, ui.SubtleStyle.Render("[{/}: Stage, z/Z: Fold]"))
+	}
+	if m.pin != nil {
+		parts = append(parts, ui.ErrorStyle.Render(fmt.Sprintf("[Failure at line %d, Space: Resume]", m.pin.line+1)))
+	}
+	if len(m.marks) > 0 {
+		parts = append(parts, ui.HighlightStyle.Render(fmt.Sprintf("[%d marked, [/]: Jump]", len(m.marks))))
+	}
+	parts = append(parts,
 		ui.SubtleStyle.Render("[s: Toggle]"),
+		ui.SubtleStyle.Render("[S: Smart]"),
+		ui.SubtleStyle.Render("[m: Mark]"),
 		ui.SubtleStyle.Render("[Esc: Back]"),
 		ui.SubtleStyle.Render("[/: Search]"),
 		stream,
+	)
+	if len(m.content) > 0 {
+		parts = append(parts, ui.SubtleStyle.Render(utils.FormatBytes(int64(len(m.content)))))
+	}
+	if n := m.transform.Collapsing(); n > 0 {
+		parts = append(parts, ui.SubtleStyle.Render(fmt.Sprintf("Collapsing %d lines...", n)))
 	}
 	if updated != "" {
 		parts = append(parts, updated)
@@ -279,11 +427,33 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	case "esc":
 		return m, emitExitRequested()
 	case "s":
-		m.autoScroll = !m.autoScroll
 		if m.autoScroll {
-			m.viewport.GotoBottom()
+			m.autoScroll = false
+			return m, nil
+		}
+		return m.resumeTail(), nil
+	case "S":
+		m.smartTail = !m.smartTail
+		return m.resumeTail(), nil
+	case " ":
+		if !m.autoScroll {
+			return m.resumeTail(), nil
 		}
 		return m, nil
+	case "m":
+		return m.toggleMark()
+	case "]":
+		return m.jumpToMark(1), nil
+	case "[":
+		return m.jumpToMark(-1), nil
+	case "}":
+		return m.jumpToStage(1), nil
+	case "{":
+		return m.jumpToStage(-1), nil
+	case "z":
+		return m.toggleFold(), nil
+	case "Z":
+		return m.toggleAllFolds(), nil
 	case "/":
 		m.searchActive = true
 		m.searchMessage = ""
@@ -342,7 +512,8 @@ func (m Model) SearchActive() bool {
 }
 
 func (m Model) handleOpenRequest(msg OpenRequestMsg) (Model, tea.Cmd) {
-	m.session++
+	saveCmd := m.SavePosition()
+	m = m.newSession()
 	m.jobName = msg.JobName
 	m.jobFullName = msg.JobFullName
 	m.buildNumber = msg.BuildNumber
@@ -360,26 +531,59 @@ func (m Model) handleOpenRequest(msg OpenRequestMsg) (Model, tea.Cmd) {
 	m.searchInput.SetValue("")
 	m.hasContent = false
 	m.idlePolls = 0
-	m.concealActive = false
+	m.sanitizer = utils.LogSanitizer{}
+	m.sanitizedNode = ""
 	m.content = m.content[:0]
+	m.scanOffset = 0
+	m.pin = nil
+	m.marks = nil
+	m.restoreLine = 0
+	m.pipeline = msg.Pipeline
+	m.lineNodes = nil
+	m.stages = jenkins.StageIndex{}
+	m.folded = nil
+	m.rows = nil
+	m.transform = m.transforms.ForJob(m.jobFullName)
+	if m.hasTarget {
+		saved := m.bookmarks.Get(m.bookmarkKey())
+		m.marks = make(map[int]bool, len(saved.Marks))
+		for _, line := range saved.Marks {
+			m.marks[line-1] = true
+		}
+		m.restoreLine = saved.Line
+	}
 	m.viewport.SetContent("")
 	m.viewport.GotoTop()
 
 	if !m.hasTarget {
 		m.statusMessage = "Build has no console output yet."
-		return m, nil
+		return m, saveCmd
 	}
 
 	var cmd tea.Cmd
 	m.shouldPoll = true
 	m, cmd = m.startFetch()
-	return m, cmd
+	return m, tea.Batch(saveCmd, cmd)
 }
 
-func (m Model) handleDeactivate() Model {
+func (m Model) handleDeactivate() (Model, tea.Cmd) {
+	saveCmd := m.SavePosition()
+	m = m.newSession()
+	m.fetchInFlight = false
 	m.shouldPoll = false
 	m.searchActive = false
 	m.searchInput.Blur()
+	return m, saveCmd
+}
+
+// newSession cancels any in-flight request and starts a new session, so late
+// responses from the previous one are ignored.
+func (m Model) newSession() Model {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.session.Next()
+	m.ctx, m.cancel = context.WithCancel(context.Background())
 	return m
 }
 
@@ -393,12 +597,29 @@ func (m Model) startFetch() (Model, tea.Cmd) {
 	number := m.buildNumber
 	offset := m.nextOffset
 	buildURL := m.buildURL
-	session := m.session
+	pipeline := m.pipeline
+	session := m.session.Current()
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
 
 	m.fetchInFlight = true
 
 	return m, func() tea.Msg {
-		chunk, next, more, err := client.GetProgressiveLog(buildURL, fullName, number, offset)
+		if pipeline {
+			log, err := client.GetAnnotatedLog(ctx, buildURL, fullName, number, offset)
+			return logsChunkMsg{
+				session:    session,
+				content:    log.Text(),
+				nextOffset: log.Next,
+				more:       log.More,
+				err:        err,
+				segments:   log.Segments,
+				nodes:      log.Nodes,
+			}
+		}
+		chunk, next, more, err := client.GetProgressiveLog(ctx, buildURL, fullName, number, offset)
 		return logsChunkMsg{
 			session:    session,
 			content:    chunk,
@@ -423,9 +644,10 @@ func (m Model) handleLogsChunk(msg logsChunkMsg) (Model, tea.Cmd) {
 	prevOffset := m.nextOffset
 
 	hasProgress := false
+	wasPinned := m.pin != nil
 
-	sanitized, conceal := utils.StripANSISecrets(msg.content, m.concealActive)
-	m.concealActive = conceal
+	var sanitized string
+	m, sanitized = m.sanitizeChunk(msg)
 	chunkLen := len(sanitized)
 
 	if chunkLen > 0 {
@@ -434,7 +656,6 @@ func (m Model) handleLogsChunk(msg logsChunkMsg) (Model, tea.Cmd) {
 			preview = sanitized[:120] + "…"
 		}
 		m.content = append(m.content, []byte(sanitized)...)
-		m.viewport.SetContent(string(m.content))
 		m.hasContent = true
 		hasProgress = true
 	}
@@ -457,8 +678,20 @@ func (m Model) handleLogsChunk(msg logsChunkMsg) (Model, tea.Cmd) {
 	}
 	m.lastUpdated = time.Now()
 
+	if chunkLen > 0 {
+		m = m.restorePosition()
+		m = m.scanForFailure()
+		if m.pin != nil && !wasPinned {
+			m = m.unfold(m.pin.line)
+		}
+		m = m.refreshContent()
+	}
+
 	if m.autoScroll {
 		m.viewport.GotoBottom()
+	} else if m.pin != nil && !wasPinned {
+		// Just pinned: show the failure with the lines that led up to it.
+		m.viewport.SetYOffset(m.rowOf(m.pin.line) - m.viewport.Height*2/3)
 	}
 
 	if !m.shouldPoll && m.idlePolls < maxIdlePollIterations {
@@ -485,9 +718,223 @@ func (m Model) handleLogsChunk(msg logsChunkMsg) (Model, tea.Cmd) {
 	return m, nil
 }
 
+// scanForFailure pauses auto-scroll on the first failure line that arrived
+// while smart tailing. Lines that arrive otherwise are skipped, so resuming
+// never stops on output that was already on screen.
+func (m Model) scanForFailure() Model {
+	if !m.smartTail || !m.autoScroll {
+		m.scanOffset = len(m.content)
+		return m
+	}
+	start, end, scanned, ok := findFailure(m.content, m.scanOffset)
+	m.scanOffset = scanned
+	if !ok {
+		return m
+	}
+	line := bytes.Count(m.content[:start], []byte{'\n'})
+	m.pin = &failurePin{start: start, end: end, line: line}
+	m.autoScroll = false
+	return m
+}
+
+// resumeTail turns auto-scroll back on and drops the failure pin.
+func (m Model) resumeTail() Model {
+	m.autoScroll = true
+	m.scanOffset = len(m.content)
+	if m.pin != nil {
+		m.pin = nil
+		m = m.refreshContent()
+	}
+	m.viewport.GotoBottom()
+	return m
+}
+
+// refreshContent shows the log in the viewport.
+func (m Model) refreshContent() Model {
+	content, rows := m.renderContent()
+	m.rows = rows
+	m.viewport.SetContent(content)
+	return m
+}
+
+// renderContent returns the log with the pinned failure line and the
+// bookmarked lines highlighted, and each run of lines of a folded stage
+// replaced by one line. rows is the first log line of each row, or nil when
+// nothing is folded.
+func (m Model) renderContent() (content string, rows []int) {
+	if m.pin == nil && len(m.marks) == 0 && len(m.folded) == 0 {
+		return string(m.content), nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(m.content))
+	fold := m.foldFunc()
+	// foldStart and foldLines describe the run of folded lines being
+	// skipped; its row is written once the run ends.
+	foldStart, foldLines, foldLabel := -1, 0, ""
+	first := true
+	writeRow := func(line int, text string) {
+		if !first {
+			b.WriteByte('\n')
+		}
+		first = false
+		if fold != nil {
+			rows = append(rows, line)
+		}
+		b.WriteString(text)
+	}
+	endFold := func() {
+		if foldStart < 0 {
+			return
+		}
+		writeRow(foldStart, foldLineStyle().Render(foldSummary(foldLabel, foldLines)))
+		foldStart = -1
+	}
+	if fold != nil {
+		rows = make([]int, 0, len(m.lineNodes))
+	}
+	for start, line := 0, 0; start <= len(m.content); line++ {
+		end := bytes.IndexByte(m.content[start:], '\n')
+		if end < 0 {
+			end = len(m.content)
+		} else {
+			end += start
+		}
+
+		label := ""
+		if fold != nil {
+			label = fold(line)
+		}
+		switch {
+		case label != "" && foldStart >= 0 && label == foldLabel:
+			foldLines++
+		case label != "":
+			endFold()
+			foldStart, foldLines, foldLabel = line, 1, label
+		default:
+			endFold()
+			text := string(m.content[start:end])
+			switch {
+			case m.pin != nil && m.pin.start == start:
+				text = failureLineStyle().Render(text)
+			case m.marks[line]:
+				text = bookmarkLineStyle().Render(text)
+			}
+			writeRow(line, text)
+		}
+
+		if end == len(m.content) {
+			break
+		}
+		start = end + 1
+	}
+	endFold()
+	return b.String(), rows
+}
+
+// bookmarkKey identifies the log in the bookmark store: by build URL, which
+// also tells servers apart, or by job and number when the URL is unknown.
+func (m Model) bookmarkKey() string {
+	if m.buildURL != "" {
+		return m.buildURL
+	}
+	return fmt.Sprintf("%s#%d", m.jobFullName, m.buildNumber)
+}
+
+// toggleMark bookmarks the top line of the view, or removes its bookmark.
+func (m Model) toggleMark() (Model, tea.Cmd) {
+	if !m.hasTarget || !m.hasContent {
+		return m, nil
+	}
+	line := m.topLine()
+	if m.marks == nil {
+		m.marks = make(map[int]bool)
+	}
+	if m.marks[line] {
+		delete(m.marks, line)
+		m.statusMessage = fmt.Sprintf("Removed bookmark at line %d", line+1)
+	} else {
+		m.marks[line] = true
+		m.statusMessage = fmt.Sprintf("Bookmarked line %d", line+1)
+	}
+	m = m.refreshContent()
+
+	store, key := m.bookmarks, m.bookmarkKey()
+	marks := make([]int, 0, len(m.marks))
+	for line := range m.marks {
+		marks = append(marks, line+1)
+	}
+	return m, func() tea.Msg {
+		// Losing a bookmark to a failed write is not worth interrupting for.
+		_ = store.SetMarks(key, marks)
+		return nil
+	}
+}
+
+// jumpToMark scrolls to the next (dir 1) or previous (dir -1) bookmark.
+func (m Model) jumpToMark(dir int) Model {
+	current := m.topLine()
+	target := -1
+	for line := range m.marks {
+		if dir > 0 && line > current && (target < 0 || line < target) {
+			target = line
+		}
+		if dir < 0 && line < current && line > target {
+			target = line
+		}
+	}
+	if target < 0 {
+		if len(m.marks) == 0 {
+			m.statusMessage = "No bookmarks. Press m to mark the top line."
+		} else if dir > 0 {
+			m.statusMessage = "No bookmark below"
+		} else {
+			m.statusMessage = "No bookmark above"
+		}
+		return m
+	}
+	m.autoScroll = false
+	m = m.scrollToLine(target)
+	m.statusMessage = fmt.Sprintf("Bookmark at line %d", target+1)
+	return m
+}
+
+// restorePosition returns to where reading stopped last time, once enough of
+// the log has streamed in.
+func (m Model) restorePosition() Model {
+	if m.restoreLine == 0 || bytes.Count(m.content, []byte{'\n'}) < m.restoreLine {
+		return m
+	}
+	m.autoScroll = false
+	m = m.refreshContent()
+	m = m.scrollToLine(m.restoreLine - 1)
+	m.statusMessage = fmt.Sprintf("Back at line %d where you left off. Space resumes tailing.", m.restoreLine)
+	m.restoreLine = 0
+	return m
+}
+
+// SavePosition returns a command remembering where the log is being read, or
+// that it is being tailed, for the next time it is opened; nil when there is
+// nothing to remember.
+func (m Model) SavePosition() tea.Cmd {
+	if m.bookmarks == nil || !m.hasTarget || !m.hasContent || m.restoreLine != 0 {
+		return nil
+	}
+	line := 0
+	if !m.autoScroll {
+		line = m.topLine() + 1
+	}
+	store, key := m.bookmarks, m.bookmarkKey()
+	return func() tea.Msg {
+		// Best effort, like the other local state files.
+		_ = store.SetLine(key, line)
+		return nil
+	}
+}
+
 func (m Model) scheduleNextPoll() tea.Cmd {
-	session := m.session
-	interval := m.pollInterval
+	session := m.session.Current()
+	interval := activity.PollInterval(m.pollInterval, m.idle)
 	return tea.Tick(interval, func(time.Time) tea.Msg {
 		return pollLogsMsg{session: session}
 	})
@@ -500,7 +947,7 @@ func (m Model) performSearch(query string) (Model, tea.Cmd) {
 		return m, nil
 	}
 
-	currentLine := m.viewport.YOffset
+	currentLine := m.topLine()
 	startIdx := byteOffsetForLine(text, currentLine)
 
 	idx := strings.Index(text[startIdx:], query)
@@ -520,7 +967,7 @@ func (m Model) performSearch(query string) (Model, tea.Cmd) {
 	}
 
 	line := strings.Count(text[:idx], "\n")
-	m.viewport.SetYOffset(line)
+	m = m.scrollToLine(line)
 	m.autoScroll = false
 	m.searchActive = false
 	m.searchInput.Blur()
@@ -0,0 +1,122 @@
+package console
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/bookmarks"
+)
+
+const bookmarkedBuild = "https://jenkins.example.com/job/api/42/"
+
+func logLines(from, to int) string {
+	var b strings.Builder
+	for i := from; i <= to; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func openBookmarkedLog(t *testing.T, store *bookmarks.Store) Model {
+	t.Helper()
+	m := New(nil).WithBookmarks(store)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 12})
+	m, _ = m.Update(OpenRequestMsg{JobName: "api", JobFullName: "Team/api", BuildNumber: 42, BuildURL: bookmarkedBuild})
+	return m
+}
+
+func (m Model) receive(content string) Model {
+	m, _ = m.Update(logsChunkMsg{session: m.session.Current(), content: content, nextOffset: m.nextOffset + int64(len(content))})
+	return m
+}
+
+func TestMarksAndPositionAreSavedPerBuild(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.json")
+	store, err := bookmarks.LoadFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := openBookmarkedLog(t, store).receive(logLines(1, 100))
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	m.viewport.SetYOffset(39)
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if cmd == nil {
+		t.Fatal("m did not save the bookmark")
+	}
+	if got := store.Get(bookmarkedBuild).Marks; len(got) != 0 {
+		t.Fatalf("bookmark written in Update: %v", got)
+	}
+	cmd()
+	if got := store.Get(bookmarkedBuild).Marks; !slices.Equal(got, []int{40}) {
+		t.Errorf("saved marks = %v, want [40]", got)
+	}
+
+	m.viewport.SetYOffset(54)
+	m, cmd = m.Update(DeactivateMsg{})
+	if cmd == nil {
+		t.Fatal("leaving the console did not save the position")
+	}
+	if got := store.Get(bookmarkedBuild).Line; got != 0 {
+		t.Fatalf("position written in Update: line %d", got)
+	}
+	cmd()
+
+	reloaded, err := bookmarks.LoadFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Get(bookmarkedBuild); got.Line != 55 || !slices.Equal(got.Marks, []int{40}) {
+		t.Errorf("reloaded = line %d, marks %v; want line 55, marks [40]", got.Line, got.Marks)
+	}
+}
+
+func TestReopeningRestoresMarksAndPosition(t *testing.T) {
+	store, err := bookmarks.LoadFrom(filepath.Join(t.TempDir(), "bookmarks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetMarks(bookmarkedBuild, []int{40}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetLine(bookmarkedBuild, 55); err != nil {
+		t.Fatal(err)
+	}
+
+	m := openBookmarkedLog(t, store)
+	if !m.marks[39] {
+		t.Errorf("marks = %v, want line 40 marked", m.marks)
+	}
+
+	// The saved line hasn't streamed in yet: keep tailing, and don't
+	// overwrite the position with the bottom of the partial log.
+	m = m.receive(logLines(1, 30))
+	if !m.autoScroll {
+		t.Fatal("stopped tailing before the saved line arrived")
+	}
+	if cmd := m.SavePosition(); cmd != nil {
+		t.Error("SavePosition() would overwrite the position still being restored")
+	}
+
+	m = m.receive(logLines(31, 100))
+	if m.autoScroll || m.viewport.YOffset != 54 {
+		t.Errorf("autoScroll = %v at offset %d, want paused at line 55", m.autoScroll, m.viewport.YOffset)
+	}
+	if !strings.Contains(m.statusMessage, "line 55") {
+		t.Errorf("status = %q, want it to say where reading resumed", m.statusMessage)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("]")})
+	if m.viewport.YOffset != 54 || m.statusMessage != "No bookmark below" {
+		t.Errorf("] moved to offset %d (%q), want to stay below the only mark", m.viewport.YOffset, m.statusMessage)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("[")})
+	if m.viewport.YOffset != 39 {
+		t.Errorf("[ left the view at offset %d, want the restored mark at 39", m.viewport.YOffset)
+	}
+}
@@ -0,0 +1,257 @@
+package console
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// stageSeparator joins nested stages and parallel branches in labels.
+const stageSeparator = " › "
+
+// sanitizeChunk strips the secrets and control sequences from a chunk of
+// log and applies the job's log transforms. An unfinished last line is held
+// back until the next chunk completes it or the log ends. For Pipeline builds
+// it also records the flow node of each line the chunk starts, so lines can
+// be attributed to stages.
+func (m Model) sanitizeChunk(msg logsChunkMsg) (Model, string) {
+	if m.transform != nil {
+		return m.transformChunk(msg)
+	}
+	if !m.pipeline {
+		sanitized := m.sanitizer.Write(msg.content)
+		if !msg.more {
+			sanitized += m.sanitizer.Flush()
+		}
+		return m, sanitized
+	}
+
+	m.stages.Add(msg.nodes...)
+	var b strings.Builder
+	atLineStart := len(m.content) == 0 || m.content[len(m.content)-1] == '\n'
+	write := func(text, node string) {
+		for _, line := range strings.SplitAfter(text, "\n") {
+			if line == "" {
+				continue
+			}
+			if atLineStart {
+				m.lineNodes = append(m.lineNodes, node)
+			}
+			atLineStart = strings.HasSuffix(line, "\n")
+		}
+		b.WriteString(text)
+	}
+	for _, segment := range msg.segments {
+		if segment.NodeID != m.sanitizedNode {
+			// A line never runs on from one flow node into another.
+			write(m.sanitizer.Flush(), m.sanitizedNode)
+			m.sanitizedNode = segment.NodeID
+		}
+		write(m.sanitizer.Write(segment.Text), segment.NodeID)
+	}
+	if !msg.more {
+		write(m.sanitizer.Flush(), m.sanitizedNode)
+	}
+	return m, b.String()
+}
+
+// stageLabel names the stage line ran in, e.g. "Tests › unit", or returns
+// "" when it is unknown or outside any stage.
+func (m Model) stageLabel(line int) string {
+	if !m.pipeline || line < 0 || line >= len(m.lineNodes) {
+		return ""
+	}
+	return strings.Join(m.stages.Stage(m.lineNodes[line]), stageSeparator)
+}
+
+// jumpToStage scrolls to the first line of the next (dir 1) or current or
+// previous (dir -1) stage.
+func (m Model) jumpToStage(dir int) Model {
+	if !m.pipeline {
+		m.statusMessage = "Stages are only known for Pipeline builds"
+		return m
+	}
+
+	// Lines of one node share a stage, so labels are resolved once per node.
+	labels := make(map[string]string)
+	label := func(line int) string {
+		node := m.lineNodes[line]
+		if cached, ok := labels[node]; ok {
+			return cached
+		}
+		labels[node] = m.stageLabel(line)
+		return labels[node]
+	}
+	startsStage := func(line int) bool {
+		current := label(line)
+		return current != "" && (line == 0 || label(line-1) != current)
+	}
+
+	top := m.topLine()
+	target := -1
+	if dir > 0 {
+		for line := top + 1; line < len(m.lineNodes); line++ {
+			if startsStage(line) {
+				target = line
+				break
+			}
+		}
+	} else {
+		for line := min(top, len(m.lineNodes)) - 1; line >= 0; line-- {
+			if startsStage(line) {
+				target = line
+				break
+			}
+		}
+	}
+	if target < 0 {
+		if dir > 0 {
+			m.statusMessage = "No stage below"
+		} else {
+			m.statusMessage = "No stage above"
+		}
+		return m
+	}
+	m.autoScroll = false
+	// A folded stage is jumped to as its folded line.
+	m.viewport.SetYOffset(m.rowOf(target))
+	m.statusMessage = fmt.Sprintf("Stage %s at line %d", label(target), target+1)
+	return m
+}
+
+// foldFunc returns a function naming the folded stage a line ran in, or ""
+// when it is shown; nil when no stage is folded.
+func (m Model) foldFunc() func(line int) string {
+	if len(m.folded) == 0 {
+		return nil
+	}
+	// Lines of one node share a stage, so labels are resolved once per node.
+	labels := make(map[string]string)
+	return func(line int) string {
+		if line >= len(m.lineNodes) {
+			return ""
+		}
+		node := m.lineNodes[line]
+		label, ok := labels[node]
+		if !ok {
+			label = m.stageLabel(line)
+			if !m.folded[label] {
+				label = ""
+			}
+			labels[node] = label
+		}
+		return label
+	}
+}
+
+// foldSummary is the line standing for lines of a folded stage.
+func foldSummary(label string, lines int) string {
+	noun := "lines"
+	if lines == 1 {
+		noun = "line"
+	}
+	return fmt.Sprintf("▸ %s · %d %s folded (z unfolds)", label, lines, noun)
+}
+
+// toggleFold folds the stage at the top of the view into one line, or
+// unfolds it.
+func (m Model) toggleFold() Model {
+	if !m.pipeline {
+		m.statusMessage = "Stages are only known for Pipeline builds"
+		return m
+	}
+	line := m.topLine()
+	label := m.stageLabel(line)
+	if label == "" {
+		m.statusMessage = "No stage here to fold"
+		return m
+	}
+	if m.folded[label] {
+		delete(m.folded, label)
+		m.statusMessage = "Unfolded " + label
+	} else {
+		if m.folded == nil {
+			m.folded = make(map[string]bool)
+		}
+		m.folded[label] = true
+		m.statusMessage = "Folded " + label
+	}
+	m.autoScroll = false
+	m = m.refreshContent()
+	m.viewport.SetYOffset(m.rowOf(line))
+	return m
+}
+
+// toggleAllFolds unfolds every stage if any is folded, and folds them all
+// otherwise.
+func (m Model) toggleAllFolds() Model {
+	if !m.pipeline {
+		m.statusMessage = "Stages are only known for Pipeline builds"
+		return m
+	}
+	line := m.topLine()
+	if len(m.folded) > 0 {
+		m.folded = nil
+		m.statusMessage = "Unfolded all stages"
+	} else {
+		m.folded = make(map[string]bool)
+		for i := range m.lineNodes {
+			if label := m.stageLabel(i); label != "" {
+				m.folded[label] = true
+			}
+		}
+		if len(m.folded) == 0 {
+			m.statusMessage = "No stages to fold"
+			return m
+		}
+		m.statusMessage = fmt.Sprintf("Folded %d stages", len(m.folded))
+	}
+	m.autoScroll = false
+	m = m.refreshContent()
+	m.viewport.SetYOffset(m.rowOf(line))
+	return m
+}
+
+// unfold shows the lines of the stage line ran in if it is folded, e.g.
+// before jumping to a match in it. The caller refreshes the content.
+func (m Model) unfold(line int) Model {
+	if label := m.stageLabel(line); m.folded[label] {
+		delete(m.folded, label)
+	}
+	return m
+}
+
+// topLine returns the log line at the top of the view.
+func (m Model) topLine() int {
+	row := m.viewport.YOffset
+	if m.rows == nil {
+		return row
+	}
+	if row >= len(m.rows) {
+		row = len(m.rows) - 1
+	}
+	if row < 0 {
+		return 0
+	}
+	return m.rows[row]
+}
+
+// rowOf returns the row of the view showing line, or the folded line
+// standing for it.
+func (m Model) rowOf(line int) int {
+	if m.rows == nil {
+		return line
+	}
+	row := sort.Search(len(m.rows), func(i int) bool { return m.rows[i] > line }) - 1
+	return max(row, 0)
+}
+
+// scrollToLine unfolds the stage of line if needed and scrolls it to the
+// top of the view.
+func (m Model) scrollToLine(line int) Model {
+	if label := m.stageLabel(line); m.folded[label] {
+		m = m.unfold(line).refreshContent()
+	}
+	m.viewport.SetYOffset(m.rowOf(line))
+	return m
+}
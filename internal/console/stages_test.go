@@ -0,0 +1,138 @@
+package console
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+func TestPipelineLinesAreAttributedToStages(t *testing.T) {
+	m := New(nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 7})
+	m, _ = m.Update(OpenRequestMsg{JobName: "app", JobFullName: "app", BuildNumber: 3, Pipeline: true})
+
+	chunks := []logsChunkMsg{
+		{
+			nodes: []jenkins.LogNode{
+				{ID: "4", EnclosingID: "2", Label: "Build"},
+				{ID: "6", EnclosingID: "4"},
+			},
+			segments: []jenkins.LogSegment{
+				{Text: "Started by user admin\n"},
+				{NodeID: "4", Text: "[Pipeline] { (Build)\n"},
+				{NodeID: "6", Text: "make\nmake: ok"},
+			},
+		},
+		{
+			nodes: []jenkins.LogNode{{ID: "9", EnclosingID: "2", Label: "Test"}},
+			segments: []jenkins.LogSegment{
+				// Continues the line "make: ok" started.
+				{NodeID: "6", Text: " (2s)\n"},
+				{NodeID: "9", Text: "[Pipeline] { (Test)\ngo test\n"},
+			},
+		},
+	}
+	for i, chunk := range chunks {
+		chunk.session = m.session.Current()
+		chunk.content = jenkins.AnnotatedLog{Segments: chunk.segments}.Text()
+		chunk.nextOffset = int64(i + 1)
+		m, _ = m.Update(chunk)
+	}
+
+	wantStages := []string{"", "Build", "Build", "Build", "Test", "Test"}
+	if len(m.lineNodes) != len(wantStages) {
+		t.Fatalf("attributed %d lines, want %d", len(m.lineNodes), len(wantStages))
+	}
+	for line, want := range wantStages {
+		if got := m.stageLabel(line); got != want {
+			t.Errorf("stageLabel(%d) = %q, want %q", line, got, want)
+		}
+	}
+
+	m.viewport.GotoTop()
+	m = m.jumpToStage(1)
+	if m.viewport.YOffset != 1 {
+		t.Errorf("} from the top went to line %d, want 1", m.viewport.YOffset)
+	}
+	m = m.jumpToStage(1)
+	if m.viewport.YOffset != 4 {
+		t.Errorf("} from Build went to line %d, want 4", m.viewport.YOffset)
+	}
+	m = m.jumpToStage(-1)
+	if m.viewport.YOffset != 1 {
+		t.Errorf("{ from Test went to line %d, want 1", m.viewport.YOffset)
+	}
+}
+
+func TestFoldingStages(t *testing.T) {
+	m := New(nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 7})
+	m, _ = m.Update(OpenRequestMsg{JobName: "app", JobFullName: "app", BuildNumber: 3, Pipeline: true})
+
+	var build, test strings.Builder
+	for i := 1; i <= 20; i++ {
+		fmt.Fprintf(&build, "compile step %d\n", i)
+		fmt.Fprintf(&test, "test step %d\n", i)
+	}
+	chunk := logsChunkMsg{
+		session: m.session.Current(),
+		nodes: []jenkins.LogNode{
+			{ID: "4", EnclosingID: "2", Label: "Build"},
+			{ID: "9", EnclosingID: "2", Label: "Test"},
+		},
+		segments: []jenkins.LogSegment{
+			{Text: "Started by user admin\n"},
+			{NodeID: "4", Text: build.String()},
+			{NodeID: "9", Text: test.String()},
+			{Text: "Finished: SUCCESS\n"},
+		},
+		nextOffset: 1,
+	}
+	chunk.content = jenkins.AnnotatedLog{Segments: chunk.segments}.Text()
+	m, _ = m.Update(chunk)
+
+	// Lines 1-20 ran in Build and 21-40 in Test.
+	m.viewport.GotoTop()
+	m = m.jumpToStage(1)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	if !m.folded["Build"] || m.topLine() != 1 {
+		t.Fatalf("z: folded %v with line %d on top (%q), want Build folded at line 1", m.folded, m.topLine(), m.statusMessage)
+	}
+	if view := m.View(); !strings.Contains(view, "▸ Build · 20 lines folded") || strings.Contains(view, "compile step") {
+		t.Errorf("view does not show Build folded:\n%s", view)
+	}
+	m.viewport.LineDown(1)
+	if got := m.topLine(); got != 21 || m.stageLabel(got) != "Test" {
+		t.Errorf("the row below the fold shows line %d, want Test's first line 21", got)
+	}
+
+	// Z unfolds everything while anything is folded, then folds it all.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	if len(m.folded) != 0 || m.rows != nil || m.topLine() != 21 {
+		t.Fatalf("Z: folded %v at line %d, want everything unfolded at line 21", m.folded, m.topLine())
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	if !m.folded["Build"] || !m.folded["Test"] || len(m.rows) != 5 {
+		t.Fatalf("Z: folded %v in %d rows, want both stages in 5", m.folded, len(m.rows))
+	}
+	// Jumping to a match in a folded stage unfolds it.
+	m.viewport.GotoTop()
+	m, _ = m.performSearch("compile step 7")
+	if m.folded["Build"] || !m.folded["Test"] || m.topLine() != 7 {
+		t.Errorf("search: folded %v at line %d, want Build unfolded at line 7", m.folded, m.topLine())
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Z")})
+	if len(m.folded) != 0 || m.rows != nil || m.topLine() != 7 {
+		t.Errorf("Z again: folded %v, rows %v at line %d; want everything unfolded at line 7", m.folded, m.rows, m.topLine())
+	}
+
+	m.viewport.GotoTop()
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	if len(m.folded) != 0 || m.statusMessage != "No stage here to fold" {
+		t.Errorf("z outside any stage: folded %v, status %q", m.folded, m.statusMessage)
+	}
+}
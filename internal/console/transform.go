@@ -0,0 +1,48 @@
+package console
+
+import (
+	"strings"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/logtransform"
+)
+
+// WithTransforms rewrites the logs of the jobs the rules apply to before
+// they are shown.
+func (m Model) WithTransforms(transforms logtransform.Set) Model {
+	m.transforms = transforms
+	return m
+}
+
+// transformChunk is sanitizeChunk for logs with transforms. The transformer
+// only passes on complete lines, so every line it returns starts one.
+func (m Model) transformChunk(msg logsChunkMsg) (Model, string) {
+	segments := msg.segments
+	if !m.pipeline {
+		segments = []jenkins.LogSegment{{Text: msg.content}}
+	}
+	m.stages.Add(msg.nodes...)
+
+	var lines []logtransform.Line
+	for _, segment := range segments {
+		if segment.NodeID != m.sanitizedNode {
+			lines = append(lines, m.transform.Feed(m.sanitizer.Flush(), m.sanitizedNode)...)
+			m.sanitizedNode = segment.NodeID
+		}
+		lines = append(lines, m.transform.Feed(m.sanitizer.Write(segment.Text), segment.NodeID)...)
+	}
+	if !msg.more {
+		lines = append(lines, m.transform.Feed(m.sanitizer.Flush(), m.sanitizedNode)...)
+		lines = append(lines, m.transform.Flush()...)
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		if m.pipeline {
+			m.lineNodes = append(m.lineNodes, line.Tag)
+		}
+		b.WriteString(line.Text)
+		b.WriteByte('\n')
+	}
+	return m, b.String()
+}
@@ -0,0 +1,53 @@
+package console
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/logtransform"
+)
+
+func TestTransformsKeepStagesAligned(t *testing.T) {
+	transforms, err := logtransform.Compile([]logtransform.Rule{
+		{Jobs: "app", Pattern: `^Download`, Action: logtransform.Collapse},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(nil).WithTransforms(transforms)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 7})
+	m, _ = m.Update(OpenRequestMsg{JobName: "app", JobFullName: "app", BuildNumber: 3, Pipeline: true})
+
+	chunks := []logsChunkMsg{
+		{
+			nodes: []jenkins.LogNode{{ID: "4", EnclosingID: "2", Label: "Build"}},
+			segments: []jenkins.LogSegment{
+				{Text: "Started by user admin\n"},
+				{NodeID: "4", Text: "Downloading a\nDownloaded a\nDownloading b\n"},
+			},
+			more: true,
+		},
+		{
+			nodes:    []jenkins.LogNode{{ID: "9", EnclosingID: "2", Label: "Test"}},
+			segments: []jenkins.LogSegment{{NodeID: "9", Text: "go test\nok"}},
+		},
+	}
+	for i, chunk := range chunks {
+		chunk.session = m.session.Current()
+		chunk.content = jenkins.AnnotatedLog{Segments: chunk.segments}.Text()
+		chunk.nextOffset = int64(i + 1)
+		m, _ = m.Update(chunk)
+	}
+
+	want := "Started by user admin\nDownloading b  (+2 similar lines)\ngo test\nok\n"
+	if got := string(m.content); got != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+	wantStages := []string{"", "Build", "Test", "Test"}
+	for line, stage := range wantStages {
+		if got := m.stageLabel(line); got != stage {
+			t.Errorf("stageLabel(%d) = %q, want %q", line, got, stage)
+		}
+	}
+}
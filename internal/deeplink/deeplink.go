@@ -0,0 +1,112 @@
+// Package deeplink describes a place in the dashboard to open on launch, given
+// either as command-line flags or as a shareable jdash:// link.
+package deeplink
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Scheme is the URL scheme of shareable links, e.g.
+// jdash://job/Production/api?build=123&logs
+const Scheme = "jdash"
+
+// ErrUsage is returned for malformed flags, which the flag set has already
+// reported to stderr together with the usage text.
+var ErrUsage = errors.New("invalid launch arguments")
+
+// Target is the job, and optionally the build, to open on launch.
+type Target struct {
+	Job   string
+	Build int
+	Logs  bool
+}
+
+// IsZero reports whether no target was requested.
+func (t Target) IsZero() bool {
+	return t.Job == ""
+}
+
+// OpensConsole reports whether the target asks for a console rather than just
+// the job details. A specific build always opens its console.
+func (t Target) OpensConsole() bool {
+	return t.Logs || t.Build > 0
+}
+
+// URL formats the target as a jdash:// link that Parse accepts.
+func (t Target) URL() string {
+	u := url.URL{Scheme: Scheme, Host: "job", Path: "/" + strings.Trim(t.Job, "/")}
+	var query []string
+	if t.Build > 0 {
+		query = append(query, "build="+strconv.Itoa(t.Build))
+	}
+	if t.Logs {
+		query = append(query, "logs")
+	}
+	u.RawQuery = strings.Join(query, "&")
+	return u.String()
+}
+
+// Parse reads launch arguments: --job, --build and --logs flags, or a single
+// jdash:// link. The flags are added to fs, so callers may register their own
+// launch flags on it first; fs should use flag.ContinueOnError. Empty args
+// yield the zero Target.
+func Parse(fs *flag.FlagSet, args []string) (Target, error) {
+	if len(args) == 1 && strings.HasPrefix(args[0], Scheme+"://") {
+		return ParseURL(args[0])
+	}
+
+	var t Target
+	fs.StringVar(&t.Job, "job", "", "full name of the job to select, e.g. Production/api")
+	fs.IntVar(&t.Build, "build", 0, "build number whose console to open (requires --job)")
+	fs.BoolVar(&t.Logs, "logs", false, "open the console for the build (latest if --build is omitted)")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return Target{}, err
+		}
+		return Target{}, ErrUsage
+	}
+	if fs.NArg() > 0 {
+		return Target{}, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	return t.validate()
+}
+
+// ParseURL reads a jdash://job/<full name>[?build=N][&logs] link.
+func ParseURL(raw string) (Target, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return Target{}, fmt.Errorf("invalid link: %w", err)
+	}
+	if u.Scheme != Scheme || u.Host != "job" {
+		return Target{}, fmt.Errorf("invalid link %q: expected %s://job/<job>", raw, Scheme)
+	}
+
+	t := Target{Job: strings.Trim(u.Path, "/")}
+	query := u.Query()
+	if build := query.Get("build"); build != "" {
+		t.Build, err = strconv.Atoi(build)
+		if err != nil {
+			return Target{}, fmt.Errorf("invalid build number %q", build)
+		}
+	}
+	if _, ok := query["logs"]; ok {
+		t.Logs = query.Get("logs") != "false" && query.Get("logs") != "0"
+	}
+	return t.validate()
+}
+
+func (t Target) validate() (Target, error) {
+	t.Job = strings.Trim(strings.TrimSpace(t.Job), "/")
+	if t.Job == "" && (t.Build != 0 || t.Logs) {
+		return Target{}, fmt.Errorf("--build and --logs require --job")
+	}
+	if t.Build < 0 {
+		return Target{}, fmt.Errorf("invalid build number %d", t.Build)
+	}
+	return t, nil
+}
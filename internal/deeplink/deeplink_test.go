@@ -0,0 +1,101 @@
+package deeplink
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    Target
+		wantErr bool
+	}{
+		{
+			name: "no arguments",
+			args: nil,
+			want: Target{},
+		},
+		{
+			name: "job only",
+			args: []string{"--job", "Production/api"},
+			want: Target{Job: "Production/api"},
+		},
+		{
+			name: "build and logs",
+			args: []string{"--job", "Production/api", "--build", "123", "--logs"},
+			want: Target{Job: "Production/api", Build: 123, Logs: true},
+		},
+		{
+			name: "link with build and logs",
+			args: []string{"jdash://job/Production/api?build=123&logs"},
+			want: Target{Job: "Production/api", Build: 123, Logs: true},
+		},
+		{
+			name: "link with escaped job name",
+			args: []string{"jdash://job/Team%20A/deploy"},
+			want: Target{Job: "Team A/deploy"},
+		},
+		{
+			name:    "build without job",
+			args:    []string{"--build", "5"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown flag",
+			args:    []string{"--jobs", "x"},
+			wantErr: true,
+		},
+		{
+			name:    "stray argument",
+			args:    []string{"--job", "a", "extra"},
+			wantErr: true,
+		},
+		{
+			name:    "link with bad build",
+			args:    []string{"jdash://job/a?build=latest"},
+			wantErr: true,
+		},
+		{
+			name:    "link without job host",
+			args:    []string{"jdash://build/a"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("jdash", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			got, err := Parse(fs, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetURLRoundTrip(t *testing.T) {
+	tests := []Target{
+		{Job: "Production/api"},
+		{Job: "Production/api", Build: 123, Logs: true},
+		{Job: "Team A/deploy", Logs: true},
+	}
+
+	for _, target := range tests {
+		t.Run(target.URL(), func(t *testing.T) {
+			got, err := ParseURL(target.URL())
+			if err != nil {
+				t.Fatalf("ParseURL(%q) error = %v", target.URL(), err)
+			}
+			if got != target {
+				t.Errorf("ParseURL(%q) = %+v, want %+v", target.URL(), got, target)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package depgraph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+type graphNode struct {
+	rel        jenkins.JobRelations
+	label      string
+	downstream []string
+	upstream   []string
+	external   []string
+}
+
+type graph struct {
+	nodes map[string]*graphNode
+	order []string
+}
+
+// newGraph indexes the jobs of a folder and keeps only edges between them;
+// upstream jobs that live elsewhere are remembered as external references.
+func newGraph(folder string, relations []jenkins.JobRelations) *graph {
+	g := &graph{nodes: make(map[string]*graphNode)}
+
+	for _, rel := range relations {
+		if rel.FullName == "" || isFolderClass(rel.Class) {
+			continue
+		}
+		g.nodes[rel.FullName] = &graphNode{
+			rel:   rel,
+			label: relativeName(folder, rel.FullName),
+		}
+		g.order = append(g.order, rel.FullName)
+	}
+	sort.Strings(g.order)
+
+	for _, name := range g.order {
+		node := g.nodes[name]
+		for _, ref := range node.rel.DownstreamProjects {
+			if _, ok := g.nodes[ref.FullName]; ok {
+				node.downstream = appendUnique(node.downstream, ref.FullName)
+				g.nodes[ref.FullName].upstream = appendUnique(g.nodes[ref.FullName].upstream, name)
+			}
+		}
+		for _, ref := range node.rel.UpstreamProjects {
+			if _, ok := g.nodes[ref.FullName]; ok {
+				node.upstream = appendUnique(node.upstream, ref.FullName)
+				g.nodes[ref.FullName].downstream = appendUnique(g.nodes[ref.FullName].downstream, name)
+			} else if ref.FullName != "" {
+				node.external = appendUnique(node.external, ref.FullName)
+			}
+		}
+	}
+
+	for _, node := range g.nodes {
+		sort.Strings(node.downstream)
+		sort.Strings(node.upstream)
+		sort.Strings(node.external)
+	}
+
+	return g
+}
+
+// blastRadius counts the distinct jobs reachable downstream of name.
+func (g *graph) blastRadius(name string) int {
+	seen := map[string]bool{name: true}
+	stack := []string{name}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, next := range g.nodes[current].downstream {
+			if !seen[next] {
+				seen[next] = true
+				stack = append(stack, next)
+			}
+		}
+	}
+	return len(seen) - 1
+}
+
+// Render draws the folder's dependency graph as box-drawing trees rooted at jobs
+// without in-folder upstreams. Failing jobs are highlighted together with the
+// number of downstream jobs they affect; jobs reached twice are marked instead
+// of being expanded again so diamonds and cycles stay readable.
+func Render(folder string, relations []jenkins.JobRelations) string {
+	g := newGraph(folder, relations)
+	if len(g.order) == 0 {
+		return ui.SubtleStyle.Render("No jobs in this folder")
+	}
+
+	var connected, isolated []string
+	for _, name := range g.order {
+		node := g.nodes[name]
+		if len(node.upstream) == 0 && len(node.downstream) == 0 {
+			isolated = append(isolated, name)
+		} else {
+			connected = append(connected, name)
+		}
+	}
+
+	var b strings.Builder
+	printed := make(map[string]bool)
+
+	var walk func(name, prefix string, last, root bool, path map[string]bool)
+	walk = func(name, prefix string, last, root bool, path map[string]bool) {
+		node := g.nodes[name]
+		branch, childPrefix := "", ""
+		if !root {
+			branch, childPrefix = "├── ", prefix+"│   "
+			if last {
+				branch, childPrefix = "└── ", prefix+"    "
+			}
+		}
+
+		b.WriteString(ui.SubtleStyle.Render(prefix + branch))
+		b.WriteString(g.renderLabel(node))
+
+		switch {
+		case path[name]:
+			b.WriteString(ui.SubtleStyle.Render(" ↺ cycle"))
+			b.WriteString("\n")
+			return
+		case printed[name] && len(node.downstream) > 0:
+			b.WriteString(ui.SubtleStyle.Render(" (see above)"))
+			b.WriteString("\n")
+			return
+		}
+		b.WriteString("\n")
+		printed[name] = true
+
+		path[name] = true
+		for i, child := range node.downstream {
+			walk(child, childPrefix, i == len(node.downstream)-1, false, path)
+		}
+		delete(path, name)
+	}
+
+	for _, name := range connected {
+		if len(g.nodes[name].upstream) == 0 {
+			walk(name, "", true, true, map[string]bool{})
+		}
+	}
+	// Jobs that only take part in cycles have no natural root.
+	for _, name := range connected {
+		if !printed[name] {
+			walk(name, "", true, true, map[string]bool{})
+		}
+	}
+
+	if len(isolated) > 0 {
+		if len(connected) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(ui.HighlightStyle.Render("─ No dependencies ─"))
+		b.WriteString("\n")
+		for _, name := range isolated {
+			b.WriteString(g.renderLabel(g.nodes[name]))
+			b.WriteString("\n")
+		}
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func (g *graph) renderLabel(node *graphNode) string {
+	status := jenkins.StatusFromColor(node.rel.Color)
+	if status == "" {
+		status = jenkins.StatusUnknown
+	}
+	style := ui.GetStatusStyle(status)
+
+	label := style.Render(ui.GetStatusIcon(status)) + " " + node.label
+	if status == jenkins.StatusFailed {
+		label = ui.ErrorStyle.Render(ui.GetStatusIcon(status) + " " + node.label)
+		if radius := g.blastRadius(node.rel.FullName); radius > 0 {
+			label += ui.ErrorStyle.Render(fmt.Sprintf("  ⚡ affects %d downstream", radius))
+		}
+	}
+	if len(node.external) > 0 {
+		label += ui.SubtleStyle.Render("  ← " + strings.Join(node.external, ", "))
+	}
+	return label
+}
+
+func relativeName(folder, fullName string) string {
+	if folder == "" {
+		return fullName
+	}
+	return strings.TrimPrefix(fullName, strings.TrimSuffix(folder, "/")+"/")
+}
+
+func isFolderClass(class string) bool {
+	return (&jenkins.Job{Class: class}).IsFolder()
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,56 @@
+package depgraph
+
+import (
+	"testing"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+func ref(fullName string) jenkins.JobRef {
+	return jenkins.JobRef{FullName: fullName}
+}
+
+func TestRender(t *testing.T) {
+	relations := []jenkins.JobRelations{
+		{FullName: "Team/build", Color: "red", DownstreamProjects: []jenkins.JobRef{ref("Team/test"), ref("Team/lint")}},
+		{FullName: "Team/test", Color: "blue", UpstreamProjects: []jenkins.JobRef{ref("Team/build")}, DownstreamProjects: []jenkins.JobRef{ref("Team/deploy")}},
+		{FullName: "Team/lint", Color: "blue", DownstreamProjects: []jenkins.JobRef{ref("Team/deploy")}},
+		{FullName: "Team/deploy", Color: "notbuilt", UpstreamProjects: []jenkins.JobRef{ref("Other/approve")}},
+		{FullName: "Team/docs", Color: "blue"},
+		{FullName: "Team/sub", Class: "com.cloudbees.hudson.plugins.folder.Folder"},
+	}
+
+	want := "✗ build  ⚡ affects 3 downstream\n" +
+		"├── ✓ lint\n" +
+		"│   └── ○ deploy  ← Other/approve\n" +
+		"└── ✓ test\n" +
+		"    └── ○ deploy  ← Other/approve\n" +
+		"\n" +
+		"─ No dependencies ─\n" +
+		"✓ docs"
+
+	if got := Render("Team", relations); got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderCycle(t *testing.T) {
+	relations := []jenkins.JobRelations{
+		{FullName: "a", Color: "blue", DownstreamProjects: []jenkins.JobRef{ref("b")}},
+		{FullName: "b", Color: "blue", DownstreamProjects: []jenkins.JobRef{ref("a")}},
+	}
+
+	want := "✓ a\n" +
+		"└── ✓ b\n" +
+		"    └── ✓ a ↺ cycle"
+
+	if got := Render("", relations); got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderEmpty(t *testing.T) {
+	if got := Render("Team", nil); got != "No jobs in this folder" {
+		t.Errorf("Render() = %q", got)
+	}
+}
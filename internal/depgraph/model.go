@@ -0,0 +1,183 @@
+package depgraph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+const (
+	maxModalWidth  = 100
+	minModalWidth  = 40
+	modalChromeRow = 8
+)
+
+// ClosedMsg is emitted when the user dismisses the dependency graph modal.
+type ClosedMsg struct{}
+
+type relationsFetchedMsg struct {
+	relations []jenkins.JobRelations
+	err       error
+}
+
+// Model is a modal showing the upstream/downstream graph of the jobs in a folder.
+type Model struct {
+	client jenkins.JenkinsClient
+	folder string
+
+	viewport viewport.Model
+	spinner  spinner.Model
+	loading  bool
+	err      error
+
+	relations []jenkins.JobRelations
+
+	width     int
+	height    int
+	maxHeight int
+}
+
+// New creates a dependency graph modal for the given folder ("" for the top level).
+func New(client jenkins.JenkinsClient, folder string) *Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.HighlightStyle
+
+	return &Model{
+		client:   client,
+		folder:   folder,
+		viewport: viewport.New(0, 0),
+		spinner:  s,
+		loading:  true,
+	}
+}
+
+// Init starts fetching the folder's job relations.
+func (m *Model) Init() tea.Cmd {
+	client := m.client
+	folder := m.folder
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		if client == nil {
+			return relationsFetchedMsg{err: fmt.Errorf("Jenkins client not configured")}
+		}
+		relations, err := client.GetFolderRelations(context.Background(), folder)
+		return relationsFetchedMsg{relations: relations, err: err}
+	})
+}
+
+// Update handles TEA messages for the modal.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.resize()
+		return m, nil
+
+	case relationsFetchedMsg:
+		m.loading = false
+		m.err = msg.err
+		m.relations = msg.relations
+		m.refreshContent()
+		return m, nil
+
+	case spinner.TickMsg:
+		if !m.loading {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "D":
+			return m, func() tea.Msg { return ClosedMsg{} }
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// View renders the modal.
+func (m *Model) View() string {
+	folderLabel := m.folder
+	if folderLabel == "" {
+		folderLabel = "(top level)"
+	}
+
+	var content strings.Builder
+	content.WriteString(ui.TitleStyle.Render("Dependencies: " + folderLabel))
+	content.WriteString("\n\n")
+
+	switch {
+	case m.loading:
+		content.WriteString(fmt.Sprintf("%s Loading job relations...", m.spinner.View()))
+	case m.err != nil:
+		content.WriteString(ui.ErrorStyle.Render("Failed to load dependencies"))
+		content.WriteString("\n")
+		content.WriteString(ui.SubtleStyle.Render(m.err.Error()))
+	default:
+		content.WriteString(m.viewport.View())
+	}
+
+	content.WriteString("\n\n")
+	content.WriteString(ui.SubtleStyle.Render("[j/k] Scroll  [Esc] Close"))
+
+	panel := lipgloss.NewStyle().
+		Width(m.modalWidth()).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(content.String())
+
+	if m.width == 0 || m.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
+}
+
+func (m *Model) modalWidth() int {
+	width := m.width - 10
+	if width > maxModalWidth {
+		width = maxModalWidth
+	}
+	if width < minModalWidth {
+		width = minModalWidth
+	}
+	return width
+}
+
+func (m *Model) resize() {
+	m.viewport.Width = m.modalWidth() - 6
+	m.maxHeight = m.height - modalChromeRow - 4
+	if m.maxHeight < 3 {
+		m.maxHeight = 3
+	}
+	m.refreshContent()
+}
+
+func (m *Model) refreshContent() {
+	if m.loading || m.err != nil {
+		return
+	}
+	content := Render(m.folder, m.relations)
+	m.viewport.SetContent(content)
+
+	// Shrink the viewport for small graphs so the modal hugs its content.
+	lines := strings.Count(content, "\n") + 1
+	height := m.maxHeight
+	if height <= 0 || lines < height {
+		height = lines
+	}
+	m.viewport.Height = height
+}
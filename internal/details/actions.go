@@ -1,11 +1,15 @@
 package details
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/browser"
+	"github.com/gorbach/jdash/internal/inflight"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/snippet"
 )
 
 type ActionKind string
@@ -14,11 +18,19 @@
 	ActionKindTriggerBuild           ActionKind = "trigger_build"
 	ActionKindTriggerBuildWithParams ActionKind = "trigger_build_with_parameters"
 	ActionKindAbortBuild             ActionKind = "abort_build"
+	ActionKindEnableJob              ActionKind = "enable_job"
+	ActionKindDisableJob             ActionKind = "disable_job"
+	ActionKindCopyJob                ActionKind = "copy_job"
+	ActionKindDeleteJob              ActionKind = "delete_job"
+	ActionKindScanBranches           ActionKind = "scan_branches"
 	ActionKindRefresh                ActionKind = "refresh"
 	ActionKindViewLogs               ActionKind = "view_logs"
 	ActionKindViewParameters         ActionKind = "view_parameters"
 	ActionKindViewHistory            ActionKind = "view_history"
 	ActionKindViewConfig             ActionKind = "view_config"
+	ActionKindEditNotes              ActionKind = "edit_notes"
+	ActionKindEditBuild              ActionKind = "edit_build"
+	ActionKindViewRunning            ActionKind = "view_running"
 )
 
 type actionResultMsg struct {
@@ -26,6 +38,29 @@ type actionResultMsg struct {
 	kind    ActionKind
 	message string
 	err     error
+	// queueID identifies the queue item a trigger created, 0 if unknown.
+	queueID int
+}
+
+// buildStartedMsg reports the build number a triggered queue item turned into.
+type buildStartedMsg struct {
+	queueID     int
+	jobName     string
+	jobFullName string
+	number      int
+	err         error
+}
+
+// snippetCopiedMsg reports whether the status snippet reached the clipboard.
+type snippetCopiedMsg struct {
+	jobName string
+	err     error
+}
+
+// browserOpenedMsg reports whether the page of a job or build was opened.
+type browserOpenedMsg struct {
+	label string
+	err   error
 }
 
 type actionMessageClearedMsg struct {
@@ -46,6 +81,21 @@ type ParameterSubmissionMsg struct {
 	Values      map[string]string
 }
 
+// NotesUpdatedMsg reports the outcome of persisting a job's note.
+type NotesUpdatedMsg struct {
+	JobFullName string
+	Err         error
+}
+
+// RunActionMsg asks the details panel to run a default action for a job as
+// soon as its details are loaded. Action is one of the DefaultActionRule
+// names: build, parameters, logs, history or config; toggleEnabled enables or
+// disables the job.
+type RunActionMsg struct {
+	JobFullName string
+	Action      string
+}
+
 // ParameterCancelledMsg indicates that the parameter collection modal was cancelled.
 type ParameterCancelledMsg struct {
 	JobFullName string
@@ -53,7 +103,24 @@ type ParameterCancelledMsg struct {
 
 const actionFeedbackDuration = 3 * time.Second
 
-func triggerBuildCmd(client jenkins.JenkinsClient, jobName, jobFullName string, ticket uint64) tea.Cmd {
+// trackedCmd registers an action described by label with tracker and builds
+// its command with the tracker's context, so quitting can wait for Jenkins'
+// answer or cancel the request.
+func trackedCmd(tracker *inflight.Tracker, label string, build func(ctx context.Context) tea.Cmd) tea.Cmd {
+	ctx, done := tracker.Start(label)
+	cmd := build(ctx)
+	return func() tea.Msg {
+		msg := cmd()
+		var err error
+		if result, ok := msg.(actionResultMsg); ok {
+			err = result.err
+		}
+		done(err)
+		return msg
+	}
+}
+
+func triggerBuildCmd(ctx context.Context, client jenkins.JenkinsClient, jobName, jobFullName string, ticket uint64) tea.Cmd {
 	return func() tea.Msg {
 		if client == nil {
 			return actionResultMsg{
@@ -63,7 +130,8 @@ func triggerBuildCmd(client jenkins.JenkinsClient, jobName, jobFullName string,
 			}
 		}
 
-		if err := client.TriggerBuild(jobFullName); err != nil {
+		queueID, err := client.TriggerBuild(ctx, jobFullName)
+		if err != nil {
 			return actionResultMsg{
 				ticket: ticket,
 				kind:   ActionKindTriggerBuild,
@@ -75,11 +143,79 @@ func triggerBuildCmd(client jenkins.JenkinsClient, jobName, jobFullName string,
 			ticket:  ticket,
 			kind:    ActionKindTriggerBuild,
 			message: fmt.Sprintf("✓ Build triggered for %s", jobName),
+			queueID: queueID,
+		}
+	}
+}
+
+// setJobEnabledCmd enables or disables a job depending on kind.
+func setJobEnabledCmd(ctx context.Context, client jenkins.JenkinsClient, jobName, jobFullName string, kind ActionKind, ticket uint64) tea.Cmd {
+	return func() tea.Msg {
+		if client == nil {
+			return actionResultMsg{
+				ticket: ticket,
+				kind:   kind,
+				err:    fmt.Errorf("Jenkins client not configured"),
+			}
+		}
+
+		var err error
+		message := fmt.Sprintf("✓ Disabled %s", jobName)
+		if kind == ActionKindEnableJob {
+			err = client.EnableJob(ctx, jobFullName)
+			message = fmt.Sprintf("✓ Enabled %s", jobName)
+		} else {
+			err = client.DisableJob(ctx, jobFullName)
+		}
+		if err != nil {
+			return actionResultMsg{ticket: ticket, kind: kind, err: err}
+		}
+		return actionResultMsg{ticket: ticket, kind: kind, message: message}
+	}
+}
+
+// scanBranchesCmd starts branch indexing of a multibranch project.
+func scanBranchesCmd(ctx context.Context, client jenkins.JenkinsClient, jobName, jobFullName string, ticket uint64) tea.Cmd {
+	return func() tea.Msg {
+		if client == nil {
+			return actionResultMsg{
+				ticket: ticket,
+				kind:   ActionKindScanBranches,
+				err:    fmt.Errorf("Jenkins client not configured"),
+			}
+		}
+		if err := client.ScanMultibranch(ctx, jobFullName); err != nil {
+			return actionResultMsg{ticket: ticket, kind: ActionKindScanBranches, err: err}
+		}
+		return actionResultMsg{
+			ticket:  ticket,
+			kind:    ActionKindScanBranches,
+			message: fmt.Sprintf("✓ Branch scan started for %s; refresh (r) once it finishes", jobName),
+		}
+	}
+}
+
+func deleteJobCmd(ctx context.Context, client jenkins.JenkinsClient, jobName, jobFullName string, ticket uint64) tea.Cmd {
+	return func() tea.Msg {
+		if client == nil {
+			return actionResultMsg{
+				ticket: ticket,
+				kind:   ActionKindDeleteJob,
+				err:    fmt.Errorf("Jenkins client not configured"),
+			}
+		}
+		if err := client.DeleteJob(ctx, jobFullName); err != nil {
+			return actionResultMsg{ticket: ticket, kind: ActionKindDeleteJob, err: err}
+		}
+		return actionResultMsg{
+			ticket:  ticket,
+			kind:    ActionKindDeleteJob,
+			message: fmt.Sprintf("✓ Deleted %s", jobName),
 		}
 	}
 }
 
-func abortBuildCmd(client jenkins.JenkinsClient, jobName, jobFullName string, buildNumber int, ticket uint64) tea.Cmd {
+func abortBuildCmd(ctx context.Context, client jenkins.JenkinsClient, jobName, jobFullName string, buildNumber int, ticket uint64) tea.Cmd {
 	return func() tea.Msg {
 		if client == nil {
 			return actionResultMsg{
@@ -96,7 +232,7 @@ func abortBuildCmd(client jenkins.JenkinsClient, jobName, jobFullName string, bu
 			}
 		}
 
-		if err := client.AbortBuild(jobFullName, buildNumber); err != nil {
+		if err := client.AbortBuild(ctx, jobFullName, buildNumber); err != nil {
 			return actionResultMsg{
 				ticket: ticket,
 				kind:   ActionKindAbortBuild,
@@ -112,7 +248,7 @@ func abortBuildCmd(client jenkins.JenkinsClient, jobName, jobFullName string, bu
 	}
 }
 
-func triggerBuildWithParamsCmd(client jenkins.JenkinsClient, jobName, jobFullName string, values map[string]string, ticket uint64) tea.Cmd {
+func triggerBuildWithParamsCmd(ctx context.Context, client jenkins.JenkinsClient, jobName, jobFullName string, values map[string]string, ticket uint64) tea.Cmd {
 	return func() tea.Msg {
 		if client == nil {
 			return actionResultMsg{
@@ -121,7 +257,8 @@ func triggerBuildWithParamsCmd(client jenkins.JenkinsClient, jobName, jobFullNam
 				err:    fmt.Errorf("Jenkins client not configured"),
 			}
 		}
-		if err := client.TriggerBuildWithParameters(jobFullName, values); err != nil {
+		queueID, err := client.TriggerBuildWithParameters(ctx, jobFullName, values)
+		if err != nil {
 			return actionResultMsg{
 				ticket: ticket,
 				kind:   ActionKindTriggerBuildWithParams,
@@ -132,10 +269,51 @@ func triggerBuildWithParamsCmd(client jenkins.JenkinsClient, jobName, jobFullNam
 			ticket:  ticket,
 			kind:    ActionKindTriggerBuildWithParams,
 			message: fmt.Sprintf("✓ Build triggered for %s", jobName),
+			queueID: queueID,
 		}
 	}
 }
 
+// buildStartTimeout bounds how long a triggered build is tracked through the queue.
+const buildStartTimeout = 30 * time.Minute
+
+// waitForBuildCmd follows a triggered queue item until Jenkins assigns it a build number.
+func waitForBuildCmd(client jenkins.JenkinsClient, jobName, jobFullName string, queueID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), buildStartTimeout)
+		defer cancel()
+
+		number, err := client.WaitForBuildNumber(ctx, queueID)
+		return buildStartedMsg{queueID: queueID, jobName: jobName, jobFullName: jobFullName, number: number, err: err}
+	}
+}
+
+func copySnippetCmd(job jenkins.Job, build *jenkins.Build) tea.Cmd {
+	text := snippet.Markdown(job, build)
+	return func() tea.Msg {
+		return snippetCopiedMsg{jobName: job.Name, err: snippet.Copy(text)}
+	}
+}
+
+// openInBrowserCmd opens the page of build, or of job when it has not been
+// built yet.
+func openInBrowserCmd(job jenkins.Job, build *jenkins.Build) tea.Cmd {
+	label, url := job.FullName, job.URL
+	if build != nil && build.URL != "" {
+		label, url = fmt.Sprintf("%s #%d", job.FullName, build.Number), build.URL
+	}
+	return func() tea.Msg {
+		return browserOpenedMsg{label: label, err: browser.Open(url)}
+	}
+}
+
+func resolveUserCmd(client jenkins.JenkinsClient, id string) tea.Cmd {
+	return func() tea.Msg {
+		user, err := client.GetUser(context.Background(), id)
+		return userResolvedMsg{id: id, user: user, err: err}
+	}
+}
+
 func actionRequestCmd(kind ActionKind, job jenkins.Job, build *jenkins.Build, params []jenkins.ParameterDefinition) tea.Cmd {
 	jobCopy := job
 	var buildCopy *jenkins.Build
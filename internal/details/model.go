@@ -1,20 +1,61 @@
 package details
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/inflight"
 	"github.com/gorbach/jdash/internal/jenkins"
 	"github.com/gorbach/jdash/internal/jobs"
+	"github.com/gorbach/jdash/internal/notes"
+	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/ticket"
+	"github.com/gorbach/jdash/internal/trend"
 	"github.com/gorbach/jdash/internal/ui"
 	"github.com/gorbach/jdash/internal/utils"
 )
 
 const maxRecentBuilds = 10
 
+// trendBuilds is how many recent builds (the latest included) feed the
+// anomaly check; console log sizes are fetched for each of them once.
+const trendBuilds = 6
+
+// maxLogSizes caps the cached console log sizes. Once it is reached only the
+// selected job's sizes are kept.
+const maxLogSizes = 240
+
+// maxChanges is how many commits of the last build the details panel lists.
+const maxChanges = 5
+
+// maxStageNameWidth caps the stage name column so durations stay aligned.
+const maxStageNameWidth = 30
+
+// detailsCacheTTL is how long a fetched job is shown without asking Jenkins
+// again. Older entries are still shown instantly while a refetch runs.
+const detailsCacheTTL = 15 * time.Second
+
+// maxCachedDetails caps the details cache on large instances; the entry
+// fetched longest ago makes room for a new one.
+const maxCachedDetails = 200
+
+// cachedDetails is a job details response and when it was fetched.
+type cachedDetails struct {
+	details   *jenkins.JobDetails
+	fetchedAt time.Time
+	// stages is the last build's stage graph for Pipeline jobs, once loaded.
+	stages *jenkins.PipelineGraph
+}
+
 type jobDetailsResultMsg struct {
 	ticket      uint64
 	jobFullName string
@@ -22,10 +63,46 @@ type jobDetailsResultMsg struct {
 	err         error
 }
 
+// pipelineStagesMsg carries the stages of a Pipeline job's last build.
+type pipelineStagesMsg struct {
+	ticket      uint64
+	jobFullName string
+	graph       *jenkins.PipelineGraph
+	err         error
+}
+
+// logSizesMsg carries console log sizes of finished builds, by build number.
+type logSizesMsg struct {
+	jobFullName string
+	sizes       map[int]int64
+}
+
+// selectionSettledMsg fires once the selection debounce elapses for ticket.
+type selectionSettledMsg struct {
+	ticket uint64
+	job    jenkins.Job
+}
+
+// buildPermissionMsg carries the outcome of checking Build permission on a job.
+type buildPermissionMsg struct {
+	jobFullName string
+	canBuild    bool
+	err         error
+}
+
+type userResolvedMsg struct {
+	id   string
+	user *jenkins.User
+	err  error
+}
+
 type inFlightAction struct {
 	kind   ActionKind
 	ticket uint64
 	label  string
+	// request is the details fetch a refresh waits for. Its ticket comes from
+	// a different counter than action tickets, so the two never meet.
+	request uint64
 }
 
 type actionFeedback struct {
@@ -34,9 +111,19 @@ type actionFeedback struct {
 	isError bool
 }
 
+// queuedTrigger is a build triggered from this panel that is still waiting
+// in the queue; position is 0 until a queue snapshot has listed it.
+type queuedTrigger struct {
+	jobFullName string
+	position    int
+	total       int
+}
+
 type confirmationState struct {
 	kind   ActionKind
-	prompt string
+	prompt confirm.Prompt
+	// values are the parameters of a parameterized trigger awaiting confirmation.
+	values map[string]string
 }
 
 // RefreshRequestedMsg asks the details panel to refresh the active job view.
@@ -45,6 +132,7 @@ type RefreshRequestedMsg struct{
 // Model represents the job details panel.
 type Model struct {
 	client jenkins.JenkinsClient
+	notes  *notes.Store
 
 	viewport viewport.Model
 	width    int
@@ -53,33 +141,120 @@ type Model struct {
 	selectedJob   *jenkins.Job
 	recentBuilds  []jenkins.Build
 	parameterDefs []jenkins.ParameterDefinition
-
-	loading   bool
-	err       error
-	requestID uint64
+	// views are the views defined inside a selected folder.
+	views []jenkins.View
+	// stages is the last build's stage graph; nil for non-Pipeline jobs.
+	stages *jenkins.PipelineGraph
+	// concurrent is set for jobs allowing concurrent builds, whose running
+	// builds are then listed in running, newest first.
+	concurrent bool
+	running    []jenkins.Build
+
+	// users caches resolved triggering users by ID; a nil entry marks a lookup in flight.
+	users map[string]*jenkins.User
+	// cache keeps recent details per job full name so moving the cursor back
+	// and forth doesn't refetch every job it passes.
+	cache map[string]cachedDetails
+	// logSizes caches console log sizes of finished builds by logSizeKey; they never change.
+	logSizes map[string]int64
+	// cancelLogSizes aborts the size lookups of the previous selection.
+	cancelLogSizes context.CancelFunc
+	// locked records per job whether the user lacks Build permission. Permissions
+	// rarely change, so each job is checked once per session.
+	locked map[string]bool
+	// streaks caches, by logSizeKey of the last build, the build its streak
+	// is measured from (see streakPermalink); nil when there is none.
+	streaks map[string]*jenkins.Build
+
+	loading  bool
+	err      error
+	requests ticket.Counter
+	// cancelRequest aborts the in-flight details fetch when the selection moves on,
+	// and cancelRunning the running builds lookup.
+	cancelRequest context.CancelFunc
+	cancelRunning context.CancelFunc
+	// selection is the context of the other lookups made for the selected
+	// job, cancelled with cancelSelection when the selection moves on.
+	selection       context.Context
+	cancelSelection context.CancelFunc
+	// debounce delays the details fetch after a selection change; zero fetches immediately.
+	debounce time.Duration
 
 	actionSpinner spinner.Model
 	inFlight      *inFlightAction
 	feedback      *actionFeedback
 	confirmation  *confirmationState
-	actionTicket  uint64
+	policy        confirm.Policy
+	actions       ticket.Counter
+	// queued follows builds triggered from here by queue item ID until they start.
+	queued map[int]queuedTrigger
+	// pendingAction waits for the selected job's details before it runs.
+	pendingAction *RunActionMsg
+	// tracker follows actions until Jenkins answers; see WithTracker.
+	tracker *inflight.Tracker
+	// user is the configured Jenkins user, whose builds get a "me" badge.
+	user string
 }
 
-// New creates a new details panel model.
-func New(client jenkins.JenkinsClient) Model {
+// New creates a new details panel model. notesStore may be nil to disable notes.
+func New(client jenkins.JenkinsClient, notesStore *notes.Store) Model {
 	vp := viewport.New(0, 0)
 	actSpinner := spinner.New()
 	actSpinner.Spinner = spinner.Dot
 	actSpinner.Style = ui.HighlightStyle
 	model := Model{
 		client:        client,
+		notes:         notesStore,
 		viewport:      vp,
 		actionSpinner: actSpinner,
+		users:         make(map[string]*jenkins.User),
+		cache:         make(map[string]cachedDetails),
+		logSizes:      make(map[string]int64),
+		locked:        make(map[string]bool),
+		streaks:       make(map[string]*jenkins.Build),
+		queued:        make(map[int]queuedTrigger),
 	}
 	model.refreshContent()
 	return model
 }
 
+// WithSelectionDebounce returns the model with details fetched only once the
+// selection has rested for d, so scrolling through the jobs list doesn't fetch
+// every job it passes. Zero or negative fetches as soon as a job is selected.
+func (m Model) WithSelectionDebounce(d time.Duration) Model {
+	if d < 0 {
+		d = 0
+	}
+	m.debounce = d
+	return m
+}
+
+// WithConfirmPolicy returns the model asking for confirmation before
+// triggering or aborting builds as policy requires.
+func (m Model) WithConfirmPolicy(policy confirm.Policy) Model {
+	m.policy = policy
+	return m
+}
+
+// WithTracker returns the model registering the changes it asks Jenkins to
+// make with tracker, so they can be waited for or cancelled on quit.
+func (m Model) WithTracker(tracker *inflight.Tracker) Model {
+	m.tracker = tracker
+	return m
+}
+
+// WithUser returns the model marking the recent builds user started.
+func (m Model) WithUser(user string) Model {
+	m.user = user
+	return m
+}
+
+// CapturesInput reports whether a type-the-name confirmation is open, during
+// which every key belongs to the prompt.
+func (m Model) CapturesInput() bool {
+	return m.confirmation != nil && m.confirmation.prompt.CapturesInput()
+}
+
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
 	return m.viewport.Init()
@@ -102,8 +277,15 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case jobs.JobSelectionClearedMsg:
 		m.handleJobCleared()
 
+	case selectionSettledMsg:
+		if m.requests.IsCurrent(msg.ticket) {
+			if cmd, _ := m.startJobDetailsRequest(msg.job); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+
 	case jobDetailsResultMsg:
-		if msg.ticket != m.requestID {
+		if !m.requests.IsCurrent(msg.ticket) {
 			// Outdated response, ignore.
 			return m, nil
 		}
@@ -113,8 +295,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.err = msg.err
 			m.recentBuilds = nil
 			m.parameterDefs = nil
-			if m.inFlight != nil && m.inFlight.ticket == msg.ticket {
-				cmds = append(cmds, m.setFeedbackWithTicket(msg.ticket, fmt.Sprintf("✗ %v", msg.err), true))
+			if m.inFlight != nil && m.inFlight.request == msg.ticket {
+				cmds = append(cmds, m.setFeedbackWithTicket(m.inFlight.ticket, fmt.Sprintf("✗ %v", msg.err), true))
 				m.inFlight = nil
 			}
 			break
@@ -122,15 +304,75 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 		m.err = nil
 		if msg.details != nil {
-			jobCopy := msg.details.Job
-			m.selectedJob = &jobCopy
-			m.recentBuilds = append([]jenkins.Build(nil), msg.details.Builds...)
-			m.parameterDefs = append([]jenkins.ParameterDefinition(nil), msg.details.ParameterDefinitions...)
+			m.cacheDetails(msg.jobFullName, msg.details)
+			m.applyDetails(msg.details)
+			cmds = append(cmds, m.resolveUsersCmds()...)
+			if cmd := m.fetchStagesCmd(msg.ticket); cmd != nil {
+				cmds = append(cmds, cmd)
+			} else if m.inFlight != nil && m.inFlight.request == msg.ticket {
+				// Without stages to wait for, a refresh is done now.
+				message := defaultSuccessMessage(m.selectedJob, m.inFlight.kind)
+				cmds = append(cmds, m.setFeedbackWithTicket(m.inFlight.ticket, message, false))
+				m.inFlight = nil
+			}
+			if cmd := m.fetchLogSizesCmd(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			if cmd := m.checkBuildPermissionCmd(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			if cmd := m.fetchStreakCmd(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			if cmd := m.fetchRunningCmd(msg.ticket); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+
+	case runningBuildsMsg:
+		if !m.requests.IsCurrent(msg.ticket) || m.selectedJob == nil || m.selectedJob.FullName != msg.jobFullName {
+			return m, nil
+		}
+		// Without an answer the count is left out; lastBuild still shows.
+		if msg.err == nil {
+			m.running = msg.builds
 		}
 
-		if m.inFlight != nil && m.inFlight.ticket == msg.ticket {
+	case buildPermissionMsg:
+		// Without an answer the job stays buildable; Jenkins still has the last word.
+		if msg.err == nil {
+			locked := !msg.canBuild
+			m.locked[msg.jobFullName] = locked
+			cmds = append(cmds, func() tea.Msg {
+				return jobs.BuildPermissionMsg{JobFullName: msg.jobFullName, Locked: locked}
+			})
+		}
+
+	case logSizesMsg:
+		m.storeLogSizes(msg.jobFullName, msg.sizes)
+
+	case streakMsg:
+		// Without an answer the line is left out; the next selection asks again.
+		if msg.err == nil {
+			m.streaks[msg.key] = msg.build
+		}
+
+	case pipelineStagesMsg:
+		if !m.requests.IsCurrent(msg.ticket) || m.selectedJob == nil || m.selectedJob.FullName != msg.jobFullName {
+			return m, nil
+		}
+		// Stage View is an optional plugin; without it the section is just left out.
+		if msg.err == nil {
+			m.stages = msg.graph
+			if cached, ok := m.cache[msg.jobFullName]; ok {
+				cached.stages = msg.graph
+				m.cache[msg.jobFullName] = cached
+			}
+		}
+
+		if m.inFlight != nil && m.inFlight.request == msg.ticket {
 			message := defaultSuccessMessage(m.selectedJob, m.inFlight.kind)
-			cmds = append(cmds, m.setFeedbackWithTicket(msg.ticket, message, false))
+			cmds = append(cmds, m.setFeedbackWithTicket(m.inFlight.ticket, message, false))
 			m.inFlight = nil
 		}
 
@@ -148,6 +390,78 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 		cmds = append(cmds, m.setFeedbackWithTicket(msg.ticket, feedbackMsg, msg.err != nil))
 		m.inFlight = nil
+		if msg.err == nil && m.selectedJob != nil {
+			// The action changed the job's builds; don't serve it from cache.
+			delete(m.cache, m.selectedJob.FullName)
+			if msg.queueID > 0 && m.client != nil {
+				m.queued[msg.queueID] = queuedTrigger{jobFullName: m.selectedJob.FullName}
+				cmds = append(cmds,
+					waitForBuildCmd(m.client, m.selectedJob.Name, m.selectedJob.FullName, msg.queueID),
+					// Poll now rather than on the next tick so the position shows up quickly.
+					func() tea.Msg { return queue.RefreshRequestedMsg{} },
+				)
+			}
+			if msg.kind == ActionKindDeleteJob {
+				// The job is gone; the refreshed tree moves the selection on.
+				cmds = append(cmds, func() tea.Msg { return jobs.RefreshRequestedMsg{} })
+			}
+			if msg.kind == ActionKindEnableJob || msg.kind == ActionKindDisableJob {
+				// Reload the details and the tree so both show the new status.
+				if cmd, _ := m.startJobDetailsRequest(*m.selectedJob); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+				cmds = append(cmds, func() tea.Msg { return jobs.RefreshRequestedMsg{} })
+			}
+		}
+
+	case snippetCopiedMsg:
+		if msg.err != nil {
+			cmds = append(cmds, m.setFeedback(fmt.Sprintf("✗ %v", msg.err), true))
+			break
+		}
+		cmds = append(cmds, m.setFeedback(fmt.Sprintf("✓ Copied status snippet for %s", msg.jobName), false))
+
+	case browserOpenedMsg:
+		if msg.err != nil {
+			cmds = append(cmds, m.setFeedback(fmt.Sprintf("✗ Failed to open %s: %v", msg.label, msg.err), true))
+			break
+		}
+		cmds = append(cmds, m.setFeedback(fmt.Sprintf("✓ Opened %s in the browser", msg.label), false))
+
+	case queue.SnapshotMsg:
+		for id, trigger := range m.queued {
+			if position, total := jenkins.QueuePosition(msg.Queued, id); position > 0 {
+				trigger.position, trigger.total = position, total
+				m.queued[id] = trigger
+			}
+		}
+		m.applyRunningSnapshot(msg.Running)
+
+	case buildStartedMsg:
+		delete(m.queued, msg.queueID)
+		delete(m.cache, msg.jobFullName)
+		if m.selectedJob == nil || m.selectedJob.FullName != msg.jobFullName {
+			break
+		}
+		if msg.err != nil {
+			cmds = append(cmds, m.setFeedback(fmt.Sprintf("✗ %s did not start: %v", msg.jobName, msg.err), true))
+			break
+		}
+		// Reload so lastBuild (and the console opened from it) is the new build.
+		cmds = append(cmds, m.setFeedback(fmt.Sprintf("✓ %s #%d started", msg.jobName, msg.number), false))
+		if m.inFlight == nil {
+			if cmd, _ := m.startJobDetailsRequest(*m.selectedJob); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		}
+
+	case userResolvedMsg:
+		if msg.err != nil {
+			// Fall back to the raw ID and allow a retry on the next details load.
+			delete(m.users, msg.id)
+			break
+		}
+		m.users[msg.id] = msg.user
 
 	case actionMessageClearedMsg:
 		if m.feedback != nil && m.feedback.ticket == msg.ticket {
@@ -161,6 +475,15 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			cmds = append(cmds, submitCmd)
 		}
 
+	case NotesUpdatedMsg:
+		if m.selectedJob != nil && msg.JobFullName == m.selectedJob.FullName {
+			if msg.Err != nil {
+				cmds = append(cmds, m.setFeedback(fmt.Sprintf("✗ Failed to save notes: %v", msg.Err), true))
+			} else {
+				cmds = append(cmds, m.setFeedback("✓ Notes saved", false))
+			}
+		}
+
 	case ParameterCancelledMsg:
 		if m.selectedJob != nil && (msg.JobFullName == "" || msg.JobFullName == m.selectedJob.FullName) {
 			if cancelCmd := m.setFeedback("Parameter entry cancelled", false); cancelCmd != nil {
@@ -168,6 +491,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 		}
 
+	case RunActionMsg:
+		pending := msg
+		m.pendingAction = &pending
+
 	case RefreshRequestedMsg:
 		if m.selectedJob != nil {
 			var refreshCmd tea.Cmd
@@ -194,16 +521,49 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 	}
 
-	var vpCmd tea.Cmd
-	m.viewport, vpCmd = m.viewport.Update(msg)
-	if vpCmd != nil {
-		cmds = append(cmds, vpCmd)
+	if m.pendingAction != nil {
+		var actionCmd tea.Cmd
+		m, actionCmd = m.runPendingAction()
+		if actionCmd != nil {
+			cmds = append(cmds, actionCmd)
+		}
+	}
+
+	// Keys were handled above: the viewport's own bindings (b, d, f, l, ...)
+	// would clash with the action keys.
+	if _, isKey := msg.(tea.KeyMsg); !isKey {
+		var vpCmd tea.Cmd
+		m.viewport, vpCmd = m.viewport.Update(msg)
+		if vpCmd != nil {
+			cmds = append(cmds, vpCmd)
+		}
 	}
 
 	m.refreshContent()
 	return m, tea.Batch(cmds...)
 }
 
+// TitleBar describes the details panel header for the selected job.
+func (m Model) TitleBar() ui.TitleBar {
+	bar := ui.TitleBar{Name: "Job Details"}
+	if m.selectedJob != nil {
+		bar.Name = fmt.Sprintf("Job: %s", m.selectedJob.Name)
+	}
+	if m.inFlight != nil {
+		bar.Spinner = m.actionSpinner.View()
+	}
+	if m.loading {
+		bar.Chips = append(bar.Chips, "loading")
+	}
+	if m.confirmation != nil {
+		bar.Chips = append(bar.Chips, "confirm")
+	}
+	if m.viewport.Height > 0 && m.viewport.TotalLineCount() > m.viewport.Height {
+		bar.Chips = append(bar.Chips, fmt.Sprintf("%d%%", int(m.viewport.ScrollPercent()*100)))
+	}
+	return bar
+}
+
 // View renders the details panel.
 func (m Model) View() string {
 	return m.viewport.View()
@@ -215,20 +575,81 @@ func (m *Model) handleJobSelected(job jenkins.Job, cmds *[]tea.Cmd) {
 	m.selectedJob = &jobCopy
 	m.recentBuilds = nil
 	m.parameterDefs = nil
+	m.views = nil
+	m.stages = nil
+	m.concurrent = false
+	m.running = nil
 	m.loading = true
 	m.err = nil
 	m.viewport.GotoTop()
-	if cmd, _ := m.startJobDetailsRequest(jobCopy); cmd != nil && cmds != nil {
+
+	if cached, ok := m.cache[job.FullName]; ok {
+		m.loading = false
+		m.applyDetails(cached.details)
+		m.stages = cached.stages
+		if cmds != nil {
+			*cmds = append(*cmds, m.resolveUsersCmds()...)
+		}
+		if time.Since(cached.fetchedAt) < detailsCacheTTL {
+			// Drop any fetch still running for the previous selection.
+			m.cancelDetailsRequest()
+			ticket := m.requests.Next()
+			if cached.stages == nil && cmds != nil {
+				if cmd := m.fetchStagesCmd(ticket); cmd != nil {
+					*cmds = append(*cmds, cmd)
+				}
+			}
+			if cmd := m.fetchRunningCmd(ticket); cmd != nil && cmds != nil {
+				*cmds = append(*cmds, cmd)
+			}
+			return
+		}
+	}
+
+	if cmd := m.scheduleJobDetailsRequest(jobCopy); cmd != nil && cmds != nil {
 		*cmds = append(*cmds, cmd)
 	}
 }
 
+// scheduleJobDetailsRequest fetches details once the selection has rested for
+// the debounce period. Superseded selections are dropped by ticket, and any
+// fetch still running for an earlier selection is cancelled right away.
+func (m *Model) scheduleJobDetailsRequest(job jenkins.Job) tea.Cmd {
+	if m.debounce <= 0 {
+		cmd, _ := m.startJobDetailsRequest(job)
+		return cmd
+	}
+
+	m.cancelDetailsRequest()
+	ticket := m.requests.Next()
+	return tea.Tick(m.debounce, func(time.Time) tea.Msg {
+		return selectionSettledMsg{ticket: ticket, job: job}
+	})
+}
+
+// applyDetails shows a details response for the selected job.
+func (m *Model) applyDetails(details *jenkins.JobDetails) {
+	jobCopy := details.Job
+	m.selectedJob = &jobCopy
+	m.recentBuilds = append([]jenkins.Build(nil), details.Builds...)
+	m.parameterDefs = append([]jenkins.ParameterDefinition(nil), details.ParameterDefinitions...)
+	m.views = append([]jenkins.View(nil), details.Views...)
+	m.concurrent = details.ConcurrentBuild
+}
+
 func (m *Model) handleJobCleared() {
+	m.cancelDetailsRequest()
+	// Invalidate the cancelled request so its error is not rendered.
+	m.requests.Invalidate()
 	m.loading = false
 	m.err = nil
 	m.selectedJob = nil
 	m.recentBuilds = nil
 	m.parameterDefs = nil
+	m.views = nil
+	m.stages = nil
+	m.concurrent = false
+	m.running = nil
 	m.resetActionState()
 	m.viewport.GotoTop()
 }
@@ -240,12 +661,43 @@ func (m *Model) resetActionState() {
 }
 
 func (m *Model) startJobDetailsRequest(job jenkins.Job) (tea.Cmd, uint64) {
-	m.requestID++
-	ticket := m.requestID
-	return m.fetchJobDetailsCmd(job, ticket), ticket
+	m.cancelDetailsRequest()
+	var ctx context.Context
+	ctx, m.cancelRequest = context.WithCancel(context.Background())
+
+	ticket := m.requests.Next()
+	return m.fetchJobDetailsCmd(ctx, job, ticket), ticket
+}
+
+func (m *Model) cancelDetailsRequest() {
+	if m.cancelRequest != nil {
+		m.cancelRequest()
+		m.cancelRequest = nil
+	}
+	if m.cancelLogSizes != nil {
+		m.cancelLogSizes()
+		m.cancelLogSizes = nil
+	}
+	if m.cancelRunning != nil {
+		m.cancelRunning()
+		m.cancelRunning = nil
+	}
+	if m.cancelSelection != nil {
+		m.cancelSelection()
+		m.selection, m.cancelSelection = nil, nil
+	}
+}
+
+// selectionContext returns the context for lookups about the selected job,
+// cancelled by cancelDetailsRequest.
+func (m *Model) selectionContext() context.Context {
+	if m.selection == nil {
+		m.selection, m.cancelSelection = context.WithCancel(context.Background())
+	}
+	return m.selection
 }
 
-func (m *Model) fetchJobDetailsCmd(job jenkins.Job, ticket uint64) tea.Cmd {
+func (m *Model) fetchJobDetailsCmd(ctx context.Context, job jenkins.Job, ticket uint64) tea.Cmd {
 	client := m.client
 	fullName := job.FullName
 
@@ -258,7 +710,7 @@ func (m *Model) fetchJobDetailsCmd(job jenkins.Job, ticket uint64) tea.Cmd {
 			}
 		}
 
-		details, err := client.GetJobDetails(fullName, maxRecentBuilds)
+		details, err := client.GetJobDetails(ctx, fullName, maxRecentBuilds)
 		if err != nil {
 			return jobDetailsResultMsg{
 				ticket:      ticket,
@@ -275,6 +727,183 @@ func (m *Model) fetchJobDetailsCmd(job jenkins.Job, ticket uint64) tea.Cmd {
 	}
 }
 
+// fetchStagesCmd loads the stage breakdown of the selected Pipeline job's last
+// build, cancelled with the selection. It returns nil for other job types.
+func (m *Model) fetchStagesCmd(ticket uint64) tea.Cmd {
+	job := m.selectedJob
+	if m.client == nil || job == nil || !job.IsPipeline() || job.LastBuild == nil {
+		return nil
+	}
+
+	ctx := m.selectionContext()
+	client := m.client
+	fullName := job.FullName
+	number := job.LastBuild.Number
+	return func() tea.Msg {
+		graph, err := client.GetPipelineGraph(ctx, fullName, number)
+		return pipelineStagesMsg{ticket: ticket, jobFullName: fullName, graph: graph, err: err}
+	}
+}
+
+// checkBuildPermissionCmd finds out whether the user may build the selected
+// job, unless that is already known, cancelled with the selection. Disabled
+// jobs and folders are skipped as the check can't tell them apart from locked
+// jobs.
+func (m *Model) checkBuildPermissionCmd() tea.Cmd {
+	job := m.selectedJob
+	if m.client == nil || job == nil || job.IsFolder() || job.GetStatus() == jenkins.StatusDisabled {
+		return nil
+	}
+	if _, known := m.locked[job.FullName]; known {
+		return nil
+	}
+
+	ctx := m.selectionContext()
+	client := m.client
+	fullName := job.FullName
+	return func() tea.Msg {
+		canBuild, err := client.CanBuild(ctx, fullName)
+		return buildPermissionMsg{jobFullName: fullName, canBuild: canBuild, err: err}
+	}
+}
+
+// triggerBlockedReason explains why the selected job can't be built from here,
+// or is empty when it can.
+func (m Model) triggerBlockedReason() string {
+	job := m.selectedJob
+	if job == nil {
+		return ""
+	}
+	if job.IsFolder() {
+		// Only folders that can be disabled, like multibranch projects, say so.
+		if job.IsDisabled() {
+			return fmt.Sprintf("✗ %s is disabled in Jenkins; its branches are neither scanned nor built", job.Name)
+		}
+		return ""
+	}
+	if job.GetStatus() == jenkins.StatusDisabled {
+		return fmt.Sprintf("✗ %s is disabled; press d to enable it", job.Name)
+	}
+	if m.locked[job.FullName] {
+		return fmt.Sprintf("%s You lack Build permission on %s", ui.IconLocked, job.Name)
+	}
+	return ""
+}
+
+// fetchLogSizesCmd fetches the console log sizes of recent finished builds that
+// aren't cached yet, all at once and cancelled with the selection. A failed
+// lookup just leaves that build out of the trend.
+func (m *Model) fetchLogSizesCmd() tea.Cmd {
+	job := m.selectedJob
+	if m.client == nil || job == nil {
+		return nil
+	}
+
+	var missing []int
+	for i := range m.recentBuilds[:min(len(m.recentBuilds), trendBuilds)] {
+		build := &m.recentBuilds[i]
+		if build.Building {
+			continue
+		}
+		if _, ok := m.logSizes[logSizeKey(job.FullName, build.Number)]; !ok {
+			missing = append(missing, build.Number)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if m.cancelLogSizes != nil {
+		m.cancelLogSizes()
+	}
+	var ctx context.Context
+	ctx, m.cancelLogSizes = context.WithCancel(context.Background())
+
+	client := m.client
+	fullName := job.FullName
+	return func() tea.Msg {
+		var (
+			wg    sync.WaitGroup
+			mu    sync.Mutex
+			sizes = make(map[int]int64, len(missing))
+		)
+		for _, number := range missing {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if size, err := client.GetConsoleLogSize(ctx, fullName, number); err == nil {
+					mu.Lock()
+					sizes[number] = size
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		return logSizesMsg{jobFullName: fullName, sizes: sizes}
+	}
+}
+
+// cacheDetails remembers a job's details, evicting the oldest entry once
+// maxCachedDetails is reached.
+func (m *Model) cacheDetails(fullName string, details *jenkins.JobDetails) {
+	if _, ok := m.cache[fullName]; !ok && len(m.cache) >= maxCachedDetails {
+		var oldest string
+		for name, cached := range m.cache {
+			if oldest == "" || cached.fetchedAt.Before(m.cache[oldest].fetchedAt) {
+				oldest = name
+			}
+		}
+		delete(m.cache, oldest)
+	}
+	m.cache[fullName] = cachedDetails{details: details, fetchedAt: time.Now()}
+}
+
+// storeLogSizes caches fetched log sizes, dropping other jobs' sizes once
+// maxLogSizes is reached.
+func (m *Model) storeLogSizes(fullName string, sizes map[int]int64) {
+	if len(m.logSizes)+len(sizes) > maxLogSizes {
+		prefix := fullName + "#"
+		for key := range m.logSizes {
+			if !strings.HasPrefix(key, prefix) {
+				delete(m.logSizes, key)
+			}
+		}
+	}
+	for number, size := range sizes {
+		m.logSizes[logSizeKey(fullName, number)] = size
+	}
+}
+
+func logSizeKey(fullName string, number int) string {
+	return fmt.Sprintf("%s#%d", fullName, number)
+}
+
+// anomalies compares the latest finished build with the ones before it.
+func (m *Model) anomalies() []trend.Anomaly {
+	if m.selectedJob == nil || len(m.recentBuilds) == 0 || m.recentBuilds[0].Building {
+		return nil
+	}
+
+	builds := m.recentBuilds[:min(len(m.recentBuilds), trendBuilds)]
+	samples := make([]trend.Sample, 0, len(builds))
+	for i := range builds {
+		build := &builds[i]
+		if build.Building {
+			continue
+		}
+		tests := -1
+		if total, _, _, ok := build.GetTestCounts(); ok {
+			tests = total
+		}
+		samples = append(samples, trend.Sample{
+			Duration: build.GetDuration(),
+			LogBytes: m.logSizes[logSizeKey(m.selectedJob.FullName, build.Number)],
+			Tests:    tests,
+		})
+	}
+	return trend.Detect(samples[0], samples[1:])
+}
+
 func (m *Model) refreshContent() {
 	m.viewport.SetContent(strings.TrimRight(m.composeContent(), "\n"))
 }
@@ -294,8 +923,6 @@ func (m *Model) composeContent() string {
 
 func (m *Model) renderPlaceholderContent() string {
 	var b strings.Builder
-	b.WriteString(ui.TitleStyle.Render("Job Details"))
-	b.WriteString("\n\n")
 	b.WriteString(ui.SubtleStyle.Render("Select a job to view details"))
 	b.WriteString("\n")
 	b.WriteString(ui.SubtleStyle.Render("Actions become available once a build job is selected."))
@@ -304,8 +931,6 @@ func (m *Model) renderPlaceholderContent() string {
 
 func (m *Model) renderLoadingContent() string {
 	var b strings.Builder
-	b.WriteString(ui.TitleStyle.Render("Job Details"))
-	b.WriteString("\n\n")
 	label := "Loading job details..."
 	if m.selectedJob != nil {
 		label = fmt.Sprintf("Loading details for %s...", m.selectedJob.Name)
@@ -316,12 +941,6 @@ func (m *Model) renderLoadingContent() string {
 
 func (m *Model) renderErrorContent() string {
 	var b strings.Builder
-	b.WriteString(ui.TitleStyle.Render("Job Details"))
-	if m.selectedJob != nil {
-		b.WriteString("\n")
-		b.WriteString(ui.HighlightStyle.Render(fmt.Sprintf("Job: %s", m.selectedJob.Name)))
-	}
-	b.WriteString("\n\n")
 	b.WriteString(ui.ErrorStyle.Render("Failed to load job details"))
 	if m.err != nil {
 		b.WriteString("\n")
@@ -337,17 +956,27 @@ func (m *Model) renderDetailsContent() string {
 	if job == nil {
 		return m.renderPlaceholderContent()
 	}
+	if job.IsFolder() {
+		return m.renderFolderContent()
+	}
 
 	var b strings.Builder
-	b.WriteString(ui.TitleStyle.Render(fmt.Sprintf("Job: %s", job.Name)))
-	b.WriteString("\n")
-
 	statusText := ui.GetStatusText(job.GetStatus())
 	durationText := ui.SubtleStyle.Render("Duration: —")
 	if job.LastBuild != nil {
 		durationText = ui.SubtleStyle.Render("Duration: " + formatDurationFromBuild(job.LastBuild))
 	}
 	b.WriteString(fmt.Sprintf("Status: %s    %s\n", statusText, durationText))
+	if m.locked[job.FullName] {
+		b.WriteString(ui.SubtleStyle.Render(ui.IconLocked + " Read-only: you lack Build permission on this job"))
+		b.WriteString("\n")
+	}
+	// Jenkins reports one health per source (build stability, test results,
+	// coverage...); the weather shown elsewhere is the worst of them.
+	for _, report := range job.HealthReport {
+		b.WriteString(fmt.Sprintf("Health: %s %d%%  %s\n",
+			ui.WeatherIcon(report.Score), report.Score, ui.SubtleStyle.Render(report.Description)))
+	}
 
 	if job.LastBuild != nil {
 		lastBuild := job.LastBuild
@@ -355,27 +984,70 @@ func (m *Model) renderDetailsContent() string {
 			lastBuild.Number,
 			ui.SubtleStyle.Render(formatRelativeTimeFromBuild(lastBuild)),
 		)
-		triggeredBy := lastBuild.GetTriggeredBy()
+		triggeredBy := m.triggeredByLabel(lastBuild)
 		if triggeredBy == "" {
-			triggeredBy = "—"
+			triggeredBy = ui.SubtleStyle.Render("—")
 		}
 		branch := lastBuild.GetBranch()
 		if branch == "" {
 			branch = "—"
 		}
 		actorsLine := fmt.Sprintf("By: %s    Branch: %s",
-			ui.SubtleStyle.Render(triggeredBy),
+			triggeredBy,
 			ui.SubtleStyle.Render(branch),
 		)
 		b.WriteString(lastBuildLine)
 		b.WriteString("\n")
 		b.WriteString(actorsLine)
 		b.WriteString("\n")
+		if streak := m.streakLine(); streak != "" {
+			b.WriteString(streak)
+			b.WriteString("\n")
+		}
+		if running := m.runningLine(); running != "" {
+			b.WriteString(running)
+			b.WriteString("\n")
+		}
+		if name := lastBuild.CustomDisplayName(); name != "" {
+			b.WriteString("Name: " + ui.HighlightStyle.Render(name))
+			b.WriteString("\n")
+		}
+		// Descriptions may be HTML with the CRLF line ends browsers submit.
+		if desc := plainDescription(lastBuild.Description); desc != "" {
+			b.WriteString("Description: " + ui.SubtleStyle.Render(utils.TruncateString(desc, maxDescriptionLength)))
+			b.WriteString("\n")
+		}
+		for _, anomaly := range m.anomalies() {
+			b.WriteString(ui.UnstableStyle.Render("⚠ " + anomaly.Message))
+			b.WriteString("\n")
+		}
 	} else {
 		b.WriteString("Last Build: —    Triggered: —\n")
 		b.WriteString("By: —    Branch: —\n")
 	}
 
+	if changes := job.LastBuild.GetChanges(); len(changes) > 0 {
+		b.WriteString("\n")
+		b.WriteString(ui.HighlightStyle.Render("─ Changes ─"))
+		b.WriteString("\n")
+		appendChanges(&b, changes)
+	}
+
+	if note, ok := m.notes.Get(job.FullName); ok {
+		b.WriteString("\n")
+		b.WriteString(ui.HighlightStyle.Render("─ Notes ─"))
+		b.WriteString("\n")
+		b.WriteString(notes.Render(note.Text))
+		b.WriteString("\n")
+	}
+
+	if m.stages != nil && len(m.stages.Nodes) > 0 {
+		b.WriteString("\n")
+		b.WriteString(ui.HighlightStyle.Render("─ Stages ─"))
+		b.WriteString("\n")
+		m.appendStages(&b)
+	}
+
 	b.WriteString("\n")
 	b.WriteString(ui.HighlightStyle.Render("─ Recent Builds ─"))
 	b.WriteString("\n")
@@ -390,6 +1062,118 @@ func (m *Model) renderDetailsContent() string {
 	return b.String()
 }
 
+// maxDescriptionLength bounds the folder description shown in the panel.
+const maxDescriptionLength = 400
+
+// renderFolderContent summarizes a folder: what it holds, how its jobs are
+// doing and which views it defines.
+func (m *Model) renderFolderContent() string {
+	job := m.selectedJob
+	var b strings.Builder
+
+	kind := "Folder"
+	if job.IsMultibranch() {
+		kind = "Multibranch Pipeline"
+	}
+	b.WriteString(fmt.Sprintf("%s: %s\n", kind, job.FullName))
+	if job.IsDisabled() {
+		b.WriteString(ui.GetStatusStyle(jenkins.StatusDisabled).Render("Disabled: nothing in it is scanned or built"))
+		b.WriteString("\n")
+	}
+	if desc := plainDescription(job.Description); desc != "" {
+		desc = utils.TruncateString(desc, maxDescriptionLength)
+		if m.viewport.Width > 0 {
+			desc = ansi.Wordwrap(desc, m.viewport.Width, "")
+		}
+		b.WriteString(ui.SubtleStyle.Render(desc))
+		b.WriteString("\n")
+	}
+
+	var folders int
+	for i := range job.Jobs {
+		if job.Jobs[i].IsFolder() {
+			folders++
+		}
+	}
+	b.WriteString(fmt.Sprintf("Contains: %d jobs, %d folders\n", len(job.Jobs)-folders, folders))
+	b.WriteString("Health: ")
+	b.WriteString(folderHealth(job.Jobs))
+	b.WriteString("\n")
+
+	if note, ok := m.notes.Get(job.FullName); ok {
+		b.WriteString("\n")
+		b.WriteString(ui.HighlightStyle.Render("─ Notes ─"))
+		b.WriteString("\n")
+		b.WriteString(notes.Render(note.Text))
+		b.WriteString("\n")
+	}
+
+	if len(m.views) > 0 {
+		b.WriteString("\n")
+		b.WriteString(ui.HighlightStyle.Render("─ Views ─"))
+		b.WriteString("\n")
+		names := make([]string, 0, len(m.views))
+		for _, view := range m.views {
+			names = append(names, view.Name)
+		}
+		b.WriteString(strings.Join(names, ", "))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(ui.HighlightStyle.Render("─ Actions ─"))
+	b.WriteString("\n")
+	m.appendActions(&b)
+
+	m.appendActionStatus(&b)
+	return b.String()
+}
+
+// folderHealth counts the direct child jobs of a folder by last result, e.g.
+// "✓ 8 passing  ✗ 2 failing". Subfolders are left out.
+func folderHealth(children []jenkins.Job) string {
+	counts := make(map[string]int)
+	for i := range children {
+		if children[i].IsFolder() {
+			continue
+		}
+		switch status := children[i].GetStatus(); status {
+		case jenkins.StatusSuccess, jenkins.StatusFailed, jenkins.StatusUnstable, jenkins.StatusBuilding, jenkins.StatusDisabled:
+			counts[status]++
+		default:
+			counts[jenkins.StatusPending]++
+		}
+	}
+
+	var parts []string
+	for _, entry := range []struct {
+		status string
+		label  string
+	}{
+		{jenkins.StatusSuccess, "passing"},
+		{jenkins.StatusFailed, "failing"},
+		{jenkins.StatusUnstable, "unstable"},
+		{jenkins.StatusBuilding, "building"},
+		{jenkins.StatusDisabled, "disabled"},
+		{jenkins.StatusPending, "not built"},
+	} {
+		if n := counts[entry.status]; n > 0 {
+			text := fmt.Sprintf("%s %d %s", ui.GetStatusIcon(entry.status), n, entry.label)
+			parts = append(parts, ui.GetStatusStyle(entry.status).Render(text))
+		}
+	}
+	if len(parts) == 0 {
+		return ui.SubtleStyle.Render("—")
+	}
+	return strings.Join(parts, "  ")
+}
+
+// plainDescription turns a Jenkins description, which may be HTML, into a
+// single line of plain text.
+func plainDescription(description string) string {
+	return strings.Join(strings.Fields(utils.HTMLToText(description)), " ")
+}
+
 func (m *Model) appendRecentBuilds(b *strings.Builder) {
 	if len(m.recentBuilds) == 0 {
 		b.WriteString(ui.SubtleStyle.Render("No build history available"))
@@ -412,21 +1196,84 @@ func (m *Model) appendRecentBuilds(b *strings.Builder) {
 			duration,
 			when,
 		)
+		if build.IsTriggeredBy(m.user) {
+			line += "  " + ui.MeBadge()
+		} else if by := m.triggeredByLabel(build); by != "" {
+			line += "  " + by
+		}
 		b.WriteString(line)
 		b.WriteString("\n")
 	}
 }
 
+func (m *Model) appendStages(b *strings.Builder) {
+	// Parallel branches are indented under their stage, so they need two more columns.
+	nameWidth := 0
+	for i := range m.stages.Nodes {
+		node := &m.stages.Nodes[i]
+		width := len([]rune(node.DisplayName))
+		if node.IsParallelBranch() {
+			width += 2
+		}
+		nameWidth = max(nameWidth, width)
+	}
+	nameWidth = min(nameWidth, maxStageNameWidth)
+
+	for i := range m.stages.Nodes {
+		node := &m.stages.Nodes[i]
+		status := node.GetStatus()
+		icon := ui.GetStatusStyle(status).Render(ui.GetStatusIcon(status))
+
+		duration := "—"
+		switch {
+		case status == jenkins.StatusBuilding:
+			duration = "running"
+		case status == jenkins.StatusPending:
+			duration = "waiting"
+		case node.DurationInMillis > 0:
+			duration = utils.FormatDuration(node.GetDuration())
+		}
+
+		name := node.DisplayName
+		if node.IsParallelBranch() {
+			name = "  " + name
+		}
+		name = utils.PadRight(utils.TruncateString(name, nameWidth), nameWidth)
+		b.WriteString(fmt.Sprintf("%s %s  %s\n", icon, name, ui.SubtleStyle.Render(duration)))
+	}
+}
+
+func appendChanges(b *strings.Builder, changes []jenkins.ChangeSetItem) {
+	for i := range changes[:min(len(changes), maxChanges)] {
+		change := &changes[i]
+		// Only the subject line; full messages would push the actions off screen.
+		subject, _, _ := strings.Cut(strings.TrimSpace(change.Msg), "\n")
+		line := fmt.Sprintf("%s %s", ui.SubtleStyle.Render(change.ShortCommitID()), subject)
+		if change.Author.FullName != "" {
+			line += ui.SubtleStyle.Render(" — " + change.Author.FullName)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	if more := len(changes) - maxChanges; more > 0 {
+		b.WriteString(ui.SubtleStyle.Render(fmt.Sprintf("… and %d more", more)))
+		b.WriteString("\n")
+	}
+}
+
 func (m *Model) appendActions(b *strings.Builder) {
 	job := m.selectedJob
 	hasParams := len(m.parameterDefs) > 0
-	labels := buildActionLabels(job, hasParams)
+	labels := buildActionLabels(job, hasParams, m.locked[job.FullName])
 	if len(labels) == 0 {
 		b.WriteString(ui.SubtleStyle.Render("No actions available"))
 		b.WriteString("\n")
 		return
 	}
 
+	if m.concurrent {
+		labels = append(labels, "B - Running builds")
+	}
 	b.WriteString(ui.SubtleStyle.Render(strings.Join(labels, "    ")))
 	b.WriteString("\n")
 }
@@ -439,7 +1286,7 @@ func (m *Model) appendActionStatus(b *strings.Builder) {
 			b.WriteString("\n")
 			wrote = true
 		}
-		b.WriteString(ui.ErrorStyle.Render(m.confirmation.prompt))
+		b.WriteString(ui.ErrorStyle.Render(m.confirmation.prompt.View()))
 		b.WriteString("\n")
 	}
 
@@ -455,6 +1302,15 @@ func (m *Model) appendActionStatus(b *strings.Builder) {
 		b.WriteString(fmt.Sprintf("%s %s\n", indicator, m.inFlight.label))
 	}
 
+	for _, line := range m.queuedLines() {
+		if !wrote {
+			b.WriteString("\n")
+			wrote = true
+		}
+		b.WriteString(ui.PendingStyle.Render(line))
+		b.WriteString("\n")
+	}
+
 	if m.feedback != nil {
 		if !wrote {
 			b.WriteString("\n")
@@ -471,6 +1327,89 @@ func (m *Model) appendActionStatus(b *strings.Builder) {
 	}
 }
 
+// queuedLines describes where the selected job's triggered builds stand in
+// the queue, oldest trigger first.
+func (m *Model) queuedLines() []string {
+	if m.selectedJob == nil {
+		return nil
+	}
+	var ids []int
+	for id, trigger := range m.queued {
+		if trigger.jobFullName == m.selectedJob.FullName {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	lines := make([]string, 0, len(ids))
+	for _, id := range ids {
+		trigger := m.queued[id]
+		if trigger.position == 0 {
+			lines = append(lines, "⏳ Queued")
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("⏳ Queued, position %d of %d", trigger.position, trigger.total))
+	}
+	return lines
+}
+
+// resolveUsersCmds requests profile lookups for triggering users that are not known yet.
+func (m *Model) resolveUsersCmds() []tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+
+	builds := make([]*jenkins.Build, 0, len(m.recentBuilds)+1)
+	if m.selectedJob != nil && m.selectedJob.LastBuild != nil {
+		builds = append(builds, m.selectedJob.LastBuild)
+	}
+	for i := range m.recentBuilds {
+		builds = append(builds, &m.recentBuilds[i])
+	}
+
+	var cmds []tea.Cmd
+	for _, build := range builds {
+		id := build.GetTriggeredByUserID()
+		if id == "" {
+			continue
+		}
+		if _, known := m.users[id]; known {
+			continue
+		}
+		m.users[id] = nil
+		cmds = append(cmds, resolveUserCmd(m.client, id))
+	}
+	return cmds
+}
+
+// triggeredByLabel renders who started the build, preferring the resolved
+// "Full Name (id)" form with an initials badge when the user is known.
+func (m *Model) triggeredByLabel(build *jenkins.Build) string {
+	if build == nil {
+		return ""
+	}
+
+	if id := build.GetTriggeredByUserID(); id != "" {
+		if user := m.users[id]; user != nil {
+			label := user.DisplayLabel()
+			badge := utils.Initials(user.FullName)
+			if badge == "" {
+				badge = utils.Initials(user.ID)
+			}
+			if badge == "" {
+				return ui.SubtleStyle.Render(label)
+			}
+			return ui.BadgeStyle.Render(" "+badge+" ") + " " + ui.SubtleStyle.Render(label)
+		}
+	}
+
+	triggeredBy := build.GetTriggeredBy()
+	if triggeredBy == "" {
+		return ""
+	}
+	return ui.SubtleStyle.Render(triggeredBy)
+}
+
 func (m *Model) updateViewportSize() {
 	if m.width < 0 {
 		m.width = 0
@@ -483,8 +1422,7 @@ func (m *Model) updateViewportSize() {
 }
 
 func (m *Model) nextActionTicket() uint64 {
-	m.actionTicket++
-	return m.actionTicket
+	return m.actions.Next()
 }
 
 func (m *Model) setFeedbackWithTicket(ticket uint64, message string, isError bool) tea.Cmd {
@@ -511,12 +1449,22 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m.handleConfirmationKey(msg)
 	}
 
+	if m.handleScrollKey(msg) {
+		return m, nil
+	}
+
 	if m.loading || m.selectedJob == nil {
 		return m, nil
 	}
 
 	switch msg.String() {
 	case "b":
+		if reason := m.triggerBlockedReason(); reason != "" {
+			return m, m.setFeedback(reason, true)
+		}
+		if m.selectedJob.IsMultibranch() {
+			return m.startScanBranchesAction()
+		}
 		if m.hasParameterDefinitions() {
 			return m.requestAction(ActionKindViewParameters)
 		}
@@ -528,37 +1476,180 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	case "l":
 		return m.requestAction(ActionKindViewLogs)
 	case "p":
+		if reason := m.triggerBlockedReason(); reason != "" {
+			return m, m.setFeedback(reason, true)
+		}
 		return m.requestAction(ActionKindViewParameters)
 	case "H":
 		return m.requestAction(ActionKindViewHistory)
+	case "B":
+		if !m.concurrent || m.selectedJob.IsFolder() {
+			return m, nil
+		}
+		return m.requestAction(ActionKindViewRunning)
 	case "c":
 		return m.requestAction(ActionKindViewConfig)
+	case "N":
+		if m.notes == nil {
+			return m, nil
+		}
+		return m.requestAction(ActionKindEditNotes)
+	case "e":
+		if m.selectedJob.IsFolder() {
+			return m, nil
+		}
+		if m.selectedJob.LastBuild == nil {
+			return m, m.setFeedback("No build to annotate yet", true)
+		}
+		return m.requestAction(ActionKindEditBuild)
+	case "y":
+		return m, copySnippetCmd(*m.selectedJob, m.selectedJob.LastBuild)
+	case "o":
+		return m, openInBrowserCmd(*m.selectedJob, m.selectedJob.LastBuild)
+	case "d":
+		return m.startToggleEnabledAction()
+	case "C":
+		if m.selectedJob.IsFolder() {
+			return m, nil
+		}
+		return m.requestAction(ActionKindCopyJob)
+	case "X":
+		return m.startDeletePrompt()
 	default:
 		return m, nil
 	}
 }
 
+// handleScrollKey scrolls the details for keys that no action uses: by line
+// with j/k, J/K, ctrl+e/ctrl+y or the arrows, by half a page with
+// ctrl+d/ctrl+u, by page with pgdown/pgup and to either end with home/end.
+func (m *Model) handleScrollKey(msg tea.KeyMsg) bool {
+	switch msg.String() {
+	case "down", "j", "J", "ctrl+e":
+		m.viewport.ScrollDown(1)
+	case "up", "k", "K", "ctrl+y":
+		m.viewport.ScrollUp(1)
+	case "ctrl+d":
+		m.viewport.HalfPageDown()
+	case "ctrl+u":
+		m.viewport.HalfPageUp()
+	case "pgdown":
+		m.viewport.PageDown()
+	case "pgup":
+		m.viewport.PageUp()
+	case "home":
+		m.viewport.GotoTop()
+	case "end":
+		m.viewport.GotoBottom()
+	default:
+		return false
+	}
+	return true
+}
+
+// runPendingAction runs the queued default action once the details of its job
+// are on screen. It is dropped when the selection moves to another job or the
+// details fail to load.
+func (m Model) runPendingAction() (Model, tea.Cmd) {
+	pending := m.pendingAction
+	if m.selectedJob == nil || m.selectedJob.FullName != pending.JobFullName {
+		m.pendingAction = nil
+		return m, nil
+	}
+	if m.loading {
+		return m, nil
+	}
+	m.pendingAction = nil
+	if m.err != nil || m.confirmation != nil {
+		return m, nil
+	}
+
+	switch pending.Action {
+	case "build", "parameters":
+		if reason := m.triggerBlockedReason(); reason != "" {
+			return m, m.setFeedback(reason, true)
+		}
+	}
+
+	switch pending.Action {
+	case "build":
+		if m.hasParameterDefinitions() {
+			return m.requestAction(ActionKindViewParameters)
+		}
+		return m.startTriggerBuildAction()
+	case "parameters":
+		if m.hasParameterDefinitions() {
+			return m.requestAction(ActionKindViewParameters)
+		}
+	case "logs":
+		return m.requestAction(ActionKindViewLogs)
+	case "history":
+		return m.requestAction(ActionKindViewHistory)
+	case "config":
+		return m.requestAction(ActionKindViewConfig)
+	case "toggleEnabled":
+		return m.startToggleEnabledAction()
+	}
+	return m, nil
+}
+
 func (m Model) handleConfirmationKey(msg tea.KeyMsg) (Model, tea.Cmd) {
 	if m.confirmation == nil {
 		return m, nil
 	}
 
-	switch msg.String() {
-	case "y", "Y", "enter":
-		kind := m.confirmation.kind
+	prompt, result := m.confirmation.prompt.HandleKey(msg)
+	switch result {
+	case confirm.Confirmed:
+		pending := *m.confirmation
 		m.confirmation = nil
-		if kind == ActionKindAbortBuild {
+		switch pending.kind {
+		case ActionKindAbortBuild:
 			return m.startAbortExecution()
+		case ActionKindTriggerBuild:
+			return m.startTriggerBuildExecution()
+		case ActionKindTriggerBuildWithParams:
+			return m.startParameterizedExecution(pending.values)
+		case ActionKindDisableJob:
+			return m.startSetEnabledExecution(ActionKindDisableJob)
+		case ActionKindDeleteJob:
+			return m.startDeleteExecution()
 		}
 		return m, nil
-	case "n", "N", "esc":
+	case confirm.Cancelled:
+		kind := m.confirmation.kind
 		m.confirmation = nil
-		return m, m.setFeedback("Abort cancelled", false)
+		switch kind {
+		case ActionKindAbortBuild:
+			return m, m.setFeedback("Abort cancelled", false)
+		case ActionKindDisableJob:
+			return m, m.setFeedback("Job left enabled", false)
+		case ActionKindDeleteJob:
+			return m, m.setFeedback("Job not deleted", false)
+		}
+		return m, m.setFeedback("Build not triggered", false)
 	default:
+		m.confirmation.prompt = prompt
 		return m, nil
 	}
 }
 
+// askConfirmation opens a prompt for kind when the policy wants one for action
+// on the selected job. It reports false when the action may run right away.
+func (m Model) askConfirmation(action confirm.Action, kind ActionKind, question string, values map[string]string) (Model, bool) {
+	job := m.selectedJob
+	level := m.policy.LevelFor(action, job.FullName)
+	if level == confirm.None {
+		return m, false
+	}
+	m.confirmation = &confirmationState{
+		kind:   kind,
+		prompt: confirm.NewPrompt(level, question, job.Name),
+		values: values,
+	}
+	return m, true
+}
+
 func (m Model) startTriggerBuildAction() (Model, tea.Cmd) {
 	if m.client == nil || m.inFlight != nil {
 		return m, nil
@@ -567,6 +1658,21 @@ func (m Model) startTriggerBuildAction() (Model, tea.Cmd) {
 	if job == nil || job.IsFolder() {
 		return m, nil
 	}
+	question := fmt.Sprintf("Trigger a build of %s?", job.Name)
+	if next, asked := m.askConfirmation(confirm.ActionTrigger, ActionKindTriggerBuild, question, nil); asked {
+		return next, nil
+	}
+	return m.startTriggerBuildExecution()
+}
+
+func (m Model) startTriggerBuildExecution() (Model, tea.Cmd) {
+	if m.client == nil || m.inFlight != nil {
+		return m, nil
+	}
+	job := m.selectedJob
+	if job == nil || job.IsFolder() {
+		return m, nil
+	}
 
 	ticket := m.nextActionTicket()
 	m.inFlight = &inFlightAction{
@@ -576,7 +1682,9 @@ func (m Model) startTriggerBuildAction() (Model, tea.Cmd) {
 	}
 	m.feedback = nil
 
-	cmd := triggerBuildCmd(m.client, job.Name, job.FullName, ticket)
+	cmd := trackedCmd(m.tracker, "Trigger a build of "+job.FullName, func(ctx context.Context) tea.Cmd {
+		return triggerBuildCmd(ctx, m.client, job.Name, job.FullName, ticket)
+	})
 	return m, tea.Batch(cmd, m.actionSpinner.Tick)
 }
 
@@ -585,11 +1693,11 @@ func (m Model) startAbortPrompt() (Model, tea.Cmd) {
 		return m, nil
 	}
 	job := m.selectedJob
-	m.confirmation = &confirmationState{
-		kind:   ActionKindAbortBuild,
-		prompt: fmt.Sprintf("Abort running build #%d for %s? (y/N)", job.LastBuild.Number, job.Name),
+	question := fmt.Sprintf("Abort running build #%d for %s?", job.LastBuild.Number, job.Name)
+	if next, asked := m.askConfirmation(confirm.ActionAbort, ActionKindAbortBuild, question, nil); asked {
+		return next, nil
 	}
-	return m, nil
+	return m.startAbortExecution()
 }
 
 func (m Model) startAbortExecution() (Model, tea.Cmd) {
@@ -604,7 +1712,99 @@ func (m Model) startAbortExecution() (Model, tea.Cmd) {
 		label:  fmt.Sprintf("Aborting build #%d...", job.LastBuild.Number),
 	}
 	m.feedback = nil
-	cmd := abortBuildCmd(m.client, job.Name, job.FullName, job.LastBuild.Number, ticket)
+	number := job.LastBuild.Number
+	cmd := trackedCmd(m.tracker, fmt.Sprintf("Abort %s #%d", job.FullName, number), func(ctx context.Context) tea.Cmd {
+		return abortBuildCmd(ctx, m.client, job.Name, job.FullName, number, ticket)
+	})
+	return m, tea.Batch(cmd, m.actionSpinner.Tick)
+}
+
+// startToggleEnabledAction disables an enabled job, asking first as the
+// policy requires, or enables a disabled one straight away.
+func (m Model) startToggleEnabledAction() (Model, tea.Cmd) {
+	job := m.selectedJob
+	if m.inFlight != nil || job == nil || job.IsFolder() {
+		return m, nil
+	}
+	if job.GetStatus() == jenkins.StatusDisabled {
+		return m.startSetEnabledExecution(ActionKindEnableJob)
+	}
+	question := fmt.Sprintf("Disable %s? It won't build until enabled again.", job.Name)
+	if next, asked := m.askConfirmation(confirm.ActionDisable, ActionKindDisableJob, question, nil); asked {
+		return next, nil
+	}
+	return m.startSetEnabledExecution(ActionKindDisableJob)
+}
+
+func (m Model) startSetEnabledExecution(kind ActionKind) (Model, tea.Cmd) {
+	job := m.selectedJob
+	if m.client == nil || m.inFlight != nil || job == nil {
+		return m, nil
+	}
+	label := fmt.Sprintf("Disabling %s...", job.Name)
+	if kind == ActionKindEnableJob {
+		label = fmt.Sprintf("Enabling %s...", job.Name)
+	}
+	ticket := m.nextActionTicket()
+	m.inFlight = &inFlightAction{kind: kind, ticket: ticket, label: label}
+	m.feedback = nil
+	action := "Disable "
+	if kind == ActionKindEnableJob {
+		action = "Enable "
+	}
+	cmd := trackedCmd(m.tracker, action+job.FullName, func(ctx context.Context) tea.Cmd {
+		return setJobEnabledCmd(ctx, m.client, job.Name, job.FullName, kind, ticket)
+	})
+	return m, tea.Batch(cmd, m.actionSpinner.Tick)
+}
+
+// startDeletePrompt asks before deleting the selected job; by default the
+// user has to type its name.
+func (m Model) startDeletePrompt() (Model, tea.Cmd) {
+	job := m.selectedJob
+	if m.inFlight != nil || job == nil || job.IsFolder() {
+		return m, nil
+	}
+	question := fmt.Sprintf("Delete %s and all of its builds? This cannot be undone.", job.Name)
+	if next, asked := m.askConfirmation(confirm.ActionDelete, ActionKindDeleteJob, question, nil); asked {
+		return next, nil
+	}
+	return m.startDeleteExecution()
+}
+
+func (m Model) startDeleteExecution() (Model, tea.Cmd) {
+	job := m.selectedJob
+	if m.client == nil || m.inFlight != nil || job == nil {
+		return m, nil
+	}
+	ticket := m.nextActionTicket()
+	m.inFlight = &inFlightAction{
+		kind:   ActionKindDeleteJob,
+		ticket: ticket,
+		label:  fmt.Sprintf("Deleting %s...", job.Name),
+	}
+	m.feedback = nil
+	cmd := trackedCmd(m.tracker, "Delete "+job.FullName, func(ctx context.Context) tea.Cmd {
+		return deleteJobCmd(ctx, m.client, job.Name, job.FullName, ticket)
+	})
+	return m, tea.Batch(cmd, m.actionSpinner.Tick)
+}
+
+func (m Model) startScanBranchesAction() (Model, tea.Cmd) {
+	job := m.selectedJob
+	if m.client == nil || m.inFlight != nil || job == nil || !job.IsMultibranch() {
+		return m, nil
+	}
+	ticket := m.nextActionTicket()
+	m.inFlight = &inFlightAction{
+		kind:   ActionKindScanBranches,
+		ticket: ticket,
+		label:  fmt.Sprintf("Starting branch scan of %s...", job.Name),
+	}
+	m.feedback = nil
+	cmd := trackedCmd(m.tracker, "Scan the branches of "+job.FullName, func(ctx context.Context) tea.Cmd {
+		return scanBranchesCmd(ctx, m.client, job.Name, job.FullName, ticket)
+	})
 	return m, tea.Batch(cmd, m.actionSpinner.Tick)
 }
 
@@ -615,11 +1815,12 @@ func (m Model) startRefreshAction() (Model, tea.Cmd) {
 	jobCopy := *m.selectedJob
 	m.loading = true
 	m.err = nil
-	cmd, ticket := m.startJobDetailsRequest(jobCopy)
+	cmd, request := m.startJobDetailsRequest(jobCopy)
 	m.inFlight = &inFlightAction{
-		kind:   ActionKindRefresh,
-		ticket: ticket,
-		label:  fmt.Sprintf("Refreshing %s...", jobCopy.Name),
+		kind:    ActionKindRefresh,
+		ticket:  m.nextActionTicket(),
+		label:   fmt.Sprintf("Refreshing %s...", jobCopy.Name),
+		request: request,
 	}
 	m.feedback = nil
 	return m, tea.Batch(cmd, m.actionSpinner.Tick)
@@ -662,6 +1863,17 @@ func (m Model) handleParameterSubmission(msg ParameterSubmissionMsg) (Model, tea
 	if m.selectedJob.FullName != msg.JobFullName {
 		return m, m.setFeedback("Job changed before submission", true)
 	}
+	question := fmt.Sprintf("Trigger a build of %s with these parameters?", m.selectedJob.Name)
+	if next, asked := m.askConfirmation(confirm.ActionTrigger, ActionKindTriggerBuildWithParams, question, msg.Values); asked {
+		return next, nil
+	}
+	return m.startParameterizedExecution(msg.Values)
+}
+
+func (m Model) startParameterizedExecution(values map[string]string) (Model, tea.Cmd) {
+	if m.client == nil || m.inFlight != nil || m.selectedJob == nil {
+		return m, nil
+	}
 
 	ticket := m.nextActionTicket()
 	m.inFlight = &inFlightAction{
@@ -671,7 +1883,10 @@ func (m Model) handleParameterSubmission(msg ParameterSubmissionMsg) (Model, tea
 	}
 	m.feedback = nil
 
-	command := triggerBuildWithParamsCmd(m.client, m.selectedJob.Name, msg.JobFullName, msg.Values, ticket)
+	job := *m.selectedJob
+	command := trackedCmd(m.tracker, "Trigger a build of "+job.FullName+" with parameters", func(ctx context.Context) tea.Cmd {
+		return triggerBuildWithParamsCmd(ctx, m.client, job.Name, job.FullName, values, ticket)
+	})
 	return m, tea.Batch(command, m.actionSpinner.Tick)
 }
 
@@ -700,6 +1915,12 @@ func requestMessage(kind ActionKind, job *jenkins.Job) string {
 		return fmt.Sprintf("→ Opening build history for %s", name)
 	case ActionKindViewConfig:
 		return fmt.Sprintf("→ Opening configuration for %s", name)
+	case ActionKindViewRunning:
+		return fmt.Sprintf("→ Opening running builds of %s", name)
+	case ActionKindEditNotes:
+		return fmt.Sprintf("→ Editing notes for %s", name)
+	case ActionKindCopyJob:
+		return fmt.Sprintf("→ Copying %s", name)
 	default:
 		return "→ Action requested"
 	}
@@ -712,11 +1933,18 @@ func jobDisplayName(job *jenkins.Job) string {
 	return job.Name
 }
 
-func buildActionLabels(job *jenkins.Job, hasParams bool) []string {
+func buildActionLabels(job *jenkins.Job, hasParams, locked bool) []string {
 	if job == nil {
 		return nil
 	}
 
+	if job.IsMultibranch() {
+		return []string{
+			"b - Scan branches",
+			"H - History",
+			"r - Refresh",
+		}
+	}
 	if job.IsFolder() {
 		return []string{
 			"H - History",
@@ -728,6 +1956,10 @@ func buildActionLabels(job *jenkins.Job, hasParams bool) []string {
 	if hasParams {
 		buildLabel = "b - Configure & build"
 	}
+	if locked {
+		buildLabel = ui.IconLocked + " Build not permitted"
+		hasParams = false
+	}
 
 	labels := []string{
 		buildLabel,
@@ -738,7 +1970,13 @@ func buildActionLabels(job *jenkins.Job, hasParams bool) []string {
 	if hasParams {
 		labels = append(labels, "p - Parameters")
 	}
-	labels = append(labels, "c - Config")
+	labels = append(labels, "c - Config", "N - Notes", "e - Edit build", "y - Copy snippet")
+	if job.GetStatus() == jenkins.StatusDisabled {
+		labels = append(labels, "d - Enable")
+	} else {
+		labels = append(labels, "d - Disable")
+	}
+	labels = append(labels, "C - Copy job", "X - Delete job")
 	if isBuildRunning(job) {
 		labels = append(labels, "a - Abort build")
 	}
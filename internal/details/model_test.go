@@ -0,0 +1,615 @@
+package details
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/inflight"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+	"github.com/gorbach/jdash/internal/jobs"
+	"github.com/gorbach/jdash/internal/queue"
+)
+
+func detailsFor(fullName string) *jenkins.JobDetails {
+	return &jenkins.JobDetails{Job: jenkins.Job{Name: fullName, FullName: fullName}}
+}
+
+func TestOutOfOrderDetailsResponses(t *testing.T) {
+	m := New(nil, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	first := m.requests.Current()
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "web", FullName: "web"}})
+	second := m.requests.Current()
+
+	// The newer selection answers first; the older one, arriving late, must
+	// not replace it.
+	m, _ = m.Update(jobDetailsResultMsg{ticket: second, jobFullName: "web", details: detailsFor("web")})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: first, jobFullName: "api", details: detailsFor("api")})
+	if m.selectedJob == nil || m.selectedJob.FullName != "web" {
+		t.Fatalf("selected job = %v, want web", m.selectedJob)
+	}
+
+	// A late error for the older selection is not shown either.
+	m, _ = m.Update(jobDetailsResultMsg{ticket: first, jobFullName: "api", err: errors.New("timeout")})
+	if m.err != nil {
+		t.Errorf("stale error shown: %v", m.err)
+	}
+}
+
+func TestClearedSelectionDropsPendingResponse(t *testing.T) {
+	m := New(nil, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	pending := m.requests.Current()
+	m, _ = m.Update(jobs.JobSelectionClearedMsg{})
+
+	m, _ = m.Update(jobDetailsResultMsg{ticket: pending, jobFullName: "api", err: errors.New("context canceled")})
+	if m.err != nil || m.selectedJob != nil {
+		t.Errorf("cancelled request applied: job %v, err %v", m.selectedJob, m.err)
+	}
+}
+
+func TestRefreshFinishesWithoutStages(t *testing.T) {
+	m := New(nil, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "api", details: detailsFor("api")})
+
+	m, _ = m.Update(RefreshRequestedMsg{})
+	if m.inFlight == nil || m.inFlight.kind != ActionKindRefresh {
+		t.Fatalf("in flight = %+v, want a refresh", m.inFlight)
+	}
+	refresh := *m.inFlight
+	if refresh.ticket == refresh.request {
+		t.Errorf("refresh action ticket %d reuses its details request ticket", refresh.ticket)
+	}
+
+	// A freestyle job has no stages to wait for, so the details end the refresh.
+	m, _ = m.Update(jobDetailsResultMsg{ticket: refresh.request, jobFullName: "api", details: detailsFor("api")})
+	if m.inFlight != nil {
+		t.Fatalf("refresh still in flight after its details arrived")
+	}
+	if m.feedback == nil || m.feedback.ticket != refresh.ticket {
+		t.Errorf("feedback = %+v, want the refresh's own ticket %d", m.feedback, refresh.ticket)
+	}
+
+	// The feedback's clear timer belongs to the refresh; an older timer
+	// does not clear it.
+	m, _ = m.Update(actionMessageClearedMsg{ticket: refresh.ticket - 1})
+	if m.feedback == nil {
+		t.Error("a stale clear timer removed the feedback")
+	}
+	m, _ = m.Update(actionMessageClearedMsg{ticket: refresh.ticket})
+	if m.feedback != nil {
+		t.Error("feedback kept after its clear timer fired")
+	}
+}
+
+func TestTriggerIsTrackedUntilJenkinsAnswers(t *testing.T) {
+	client := &jenkinstest.Client{
+		TriggerBuildFunc: func(context.Context, string) (int, error) { return 0, errors.New("status 500") },
+	}
+	tracker := inflight.New()
+	m := New(client, nil).WithTracker(tracker)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "api", details: detailsFor("api")})
+
+	m, cmd := m.startTriggerBuildExecution()
+	if got := tracker.Pending(); !slices.Equal(got, []string{"Trigger a build of api"}) {
+		t.Fatalf("Pending() = %v, want the trigger", got)
+	}
+
+	// The batch holds the trigger and the spinner; run the trigger.
+	result := cmd().(tea.BatchMsg)[0]()
+	m, _ = m.Update(result)
+	if len(tracker.Pending()) != 0 {
+		t.Errorf("Pending() = %v after Jenkins answered", tracker.Pending())
+	}
+	if m.feedback == nil || !m.feedback.isError {
+		t.Errorf("feedback = %+v, want the trigger error", m.feedback)
+	}
+}
+
+func TestFailingJobShowsWhenItLastSucceeded(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetLastSuccessfulBuildFunc: func(ctx context.Context, fullName string) (*jenkins.Build, error) {
+			return &jenkins.Build{Number: 480, Result: "SUCCESS"}, nil
+		},
+	}
+	m := New(client, nil)
+	job := jenkins.Job{Name: "api", FullName: "api", LastBuild: &jenkins.Build{Number: 483, Result: "FAILURE"}}
+	details := &jenkins.JobDetails{Job: job}
+	for number := 483; number >= 478; number-- {
+		details.Builds = append(details.Builds, jenkins.Build{Number: number, Result: "FAILURE"})
+	}
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: job})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "api", details: details})
+
+	fetch := m.fetchStreakCmd()
+	if fetch == nil {
+		t.Fatal("no lookup of the last success for a failing job")
+	}
+	m, _ = m.Update(fetch())
+	if line := m.streakLine(); !strings.Contains(line, "Failing since #481") || !strings.Contains(line, "last success #480") {
+		t.Errorf("streak line = %q", line)
+	}
+	if len(client.CallsTo("GetLastSuccessfulBuild")) != 1 || m.fetchStreakCmd() != nil {
+		t.Error("the last success is looked up again for the same last build")
+	}
+
+	// Build numbers skip deleted builds: the streak starts at the first
+	// build the history lists after the last success.
+	m.recentBuilds = []jenkins.Build{{Number: 483}, {Number: 482}, {Number: 480}}
+	if line := m.streakLine(); !strings.Contains(line, "Failing since #482") {
+		t.Errorf("streak line with #481 deleted = %q", line)
+	}
+	// Without history back to the last success, where the streak started
+	// is unknown.
+	m.recentBuilds = []jenkins.Build{{Number: 483}, {Number: 482}}
+	if line := m.streakLine(); strings.Contains(line, "since") || !strings.Contains(line, "Failing · last success #480") {
+		t.Errorf("streak line without history back to #480 = %q", line)
+	}
+}
+
+func TestLockedJobRefusesTriggerKeys(t *testing.T) {
+	client := &jenkinstest.Client{
+		CanBuildFunc: func(context.Context, string) (bool, error) { return false, nil },
+	}
+	m := New(client, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "api", details: detailsFor("api")})
+
+	var cmd tea.Cmd
+	m, cmd = m.Update(m.checkBuildPermissionCmd()())
+	if msg, ok := cmd().(jobs.BuildPermissionMsg); !ok || !msg.Locked || msg.JobFullName != "api" {
+		t.Errorf("permission check told the tree %+v, want api locked", msg)
+	}
+	if m.checkBuildPermissionCmd() != nil {
+		t.Error("the permission is checked again for the same job")
+	}
+
+	for _, key := range []string{"b", "p"} {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		if m.feedback == nil || !m.feedback.isError || !strings.Contains(m.feedback.message, "lack Build permission on api") {
+			t.Errorf("%s feedback = %+v, want the missing permission", key, m.feedback)
+		}
+	}
+	if calls := client.CallsTo("TriggerBuild"); len(calls) != 0 {
+		t.Errorf("TriggerBuild calls = %v on a locked job", calls)
+	}
+}
+
+func TestDisabledMultibranchIsNotScanned(t *testing.T) {
+	client := &jenkinstest.Client{}
+	job := jenkins.Job{Name: "mono", FullName: "mono", Disabled: true,
+		Class: "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"}
+	m := New(client, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: job})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "mono", details: &jenkins.JobDetails{Job: job}})
+
+	if content := ansi.Strip(m.renderDetailsContent()); !strings.Contains(content, "Disabled") {
+		t.Errorf("folder content does not say it is disabled:\n%s", content)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if m.feedback == nil || !m.feedback.isError || !strings.Contains(m.feedback.message, "mono is disabled") {
+		t.Errorf("feedback = %+v, want the folder disabled", m.feedback)
+	}
+	if calls := client.CallsTo("ScanMultibranch"); len(calls) != 0 {
+		t.Errorf("ScanMultibranch calls = %v on a disabled project", calls)
+	}
+}
+
+func TestConcurrentJobCountsRunningBuilds(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetBuildsFunc: func(context.Context, string, int, int) ([]jenkins.Build, error) {
+			return []jenkins.Build{{Number: 14, Building: true}, {Number: 13, Result: "SUCCESS"}, {Number: 12, Building: true}}, nil
+		},
+	}
+	m := New(client, nil)
+	job := jenkins.Job{Name: "api", FullName: "api", URL: "https://ci/job/api/", LastBuild: &jenkins.Build{Number: 14, Building: true}}
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: job})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "api",
+		details: &jenkins.JobDetails{Job: job, ConcurrentBuild: true}})
+
+	m, _ = m.Update(m.fetchRunningCmd(m.requests.Current())())
+	if line := m.runningLine(); !strings.Contains(line, "Running: 2 builds") || !strings.Contains(line, "#14, #12") {
+		t.Errorf("running line = %q", line)
+	}
+
+	// Queue polls keep the count current. A Pipeline build shows on its
+	// flyweight executor and on its node block's, counting once.
+	m, _ = m.Update(queue.SnapshotMsg{Running: []jenkins.RunningBuild{
+		{BuildNumber: 15, URL: "https://ci/job/api/15/"},
+		{BuildNumber: 14, URL: "https://ci/job/api/14/"},
+		{BuildNumber: 15, URL: "https://ci/job/api/15/", Node: "linux"},
+		{BuildNumber: 3, URL: "https://ci/job/web/3/"},
+	}})
+	if line := m.runningLine(); !strings.Contains(line, "Running: 2 builds") || !strings.Contains(line, "#15, #14") {
+		t.Errorf("running line after a queue poll = %q", line)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("B")})
+	if cmd == nil {
+		t.Fatal("B did not ask for the running builds")
+	}
+	// The batch holds the request and the feedback timer; run the request.
+	if req, ok := cmd().(tea.BatchMsg)[0]().(ActionRequestMsg); !ok || req.Kind != ActionKindViewRunning {
+		t.Errorf("requested %+v, want the running builds", req)
+	}
+}
+
+func TestSelectionDebounceFetchesOnlyTheJobItSettlesOn(t *testing.T) {
+	client := &jenkinstest.Client{}
+	m := New(client, nil).WithSelectionDebounce(time.Hour)
+
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	passed := m.requests.Current()
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "web", FullName: "web"}})
+	settled := m.requests.Current()
+	if calls := client.CallsTo("GetJobDetails"); len(calls) != 0 {
+		t.Fatalf("details fetched before the selection settled: %v", calls)
+	}
+
+	// The timer of the job scrolled past fires late and is dropped.
+	if _, cmd := m.Update(selectionSettledMsg{ticket: passed, job: jenkins.Job{Name: "api", FullName: "api"}}); cmd != nil {
+		t.Error("a superseded selection starts a fetch")
+	}
+
+	m, cmd := m.Update(selectionSettledMsg{ticket: settled, job: jenkins.Job{Name: "web", FullName: "web"}})
+	if cmd == nil {
+		t.Fatal("the settled selection starts no fetch")
+	}
+	if msg, ok := cmd().(jobDetailsResultMsg); !ok || msg.jobFullName != "web" || !m.requests.IsCurrent(msg.ticket) {
+		t.Errorf("fetch result = %+v, want the details of web", msg)
+	}
+	if calls := client.CallsTo("GetJobDetails"); len(calls) != 1 || calls[0].Args[0] != "web" {
+		t.Errorf("GetJobDetails calls = %v, want one for web", calls)
+	}
+}
+
+func TestLogSizeLookupsStopWithTheSelection(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetConsoleLogSizeFunc: func(ctx context.Context, _ string, number int) (int64, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		},
+	}
+	m := New(client, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	m.selectedJob = &jenkins.Job{Name: "api", FullName: "api"}
+	m.recentBuilds = []jenkins.Build{{Number: 3}, {Number: 2}, {Number: 1}}
+	fetch := m.fetchLogSizesCmd()
+	if fetch == nil {
+		t.Fatal("no log size lookup for uncached builds")
+	}
+
+	done := make(chan tea.Msg)
+	go func() { done <- fetch() }()
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "web", FullName: "web"}})
+	select {
+	case msg := <-done:
+		if sizes := msg.(logSizesMsg).sizes; len(sizes) != 0 {
+			t.Errorf("sizes = %v, want none from cancelled lookups", sizes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("log size lookups outlive the selection")
+	}
+	if calls := client.CallsTo("GetConsoleLogSize"); len(calls) != 3 {
+		t.Errorf("GetConsoleLogSize calls = %d, want one per build", len(calls))
+	}
+}
+
+// stopsWithTheSelection runs lookup, which blocks until its context is
+// done, and checks that selecting another job cancels it.
+func stopsWithTheSelection(t *testing.T, m Model, lookup tea.Cmd) {
+	t.Helper()
+	if lookup == nil {
+		t.Fatal("no lookup for the selected job")
+	}
+	done := make(chan tea.Msg)
+	go func() { done <- lookup() }()
+	m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "web", FullName: "web"}})
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the lookup outlives the selection")
+	}
+}
+
+func TestStageFetchStopsWithTheSelection(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetPipelineGraphFunc: func(ctx context.Context, _ string, _ int) (*jenkins.PipelineGraph, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	m := New(client, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	m.selectedJob = &jenkins.Job{Name: "api", FullName: "api", Class: "org.jenkinsci.plugins.workflow.job.WorkflowJob", LastBuild: &jenkins.Build{Number: 7}}
+	stopsWithTheSelection(t, m, m.fetchStagesCmd(m.requests.Current()))
+}
+
+func TestPermissionCheckStopsWithTheSelection(t *testing.T) {
+	client := &jenkinstest.Client{
+		CanBuildFunc: func(ctx context.Context, _ string) (bool, error) {
+			<-ctx.Done()
+			return false, ctx.Err()
+		},
+	}
+	m := New(client, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	m.selectedJob = &jenkins.Job{Name: "api", FullName: "api", Color: "blue"}
+	stopsWithTheSelection(t, m, m.checkBuildPermissionCmd())
+}
+
+func TestStreakLookupStopsWithTheSelection(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetLastSuccessfulBuildFunc: func(ctx context.Context, _ string) (*jenkins.Build, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+	m := New(client, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	m.selectedJob = &jenkins.Job{Name: "api", FullName: "api", LastBuild: &jenkins.Build{Number: 9, Result: "FAILURE"}}
+	stopsWithTheSelection(t, m, m.fetchStreakCmd())
+}
+
+// fetchesDetails runs cmd and reports whether it fetched job details.
+func fetchesDetails(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	switch msg := cmd().(type) {
+	case jobDetailsResultMsg:
+		return true
+	case tea.BatchMsg:
+		for _, c := range msg {
+			if fetchesDetails(c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestDetailsCache(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetJobDetailsFunc: func(_ context.Context, fullName string, _ int) (*jenkins.JobDetails, error) {
+			return detailsFor(fullName), nil
+		},
+	}
+	m := New(client, nil)
+	api := jenkins.Job{Name: "api", FullName: "api"}
+	web := jenkins.Job{Name: "web", FullName: "web"}
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: api})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "api", details: detailsFor("api")})
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: web})
+
+	// Back within the TTL: shown from the cache without asking Jenkins.
+	m, cmd := m.Update(jobs.JobSelectedMsg{Job: api})
+	if m.loading || m.selectedJob == nil || m.selectedJob.FullName != "api" {
+		t.Errorf("cached job not shown at once: loading %v, job %v", m.loading, m.selectedJob)
+	}
+	if fetchesDetails(cmd) {
+		t.Error("a fresh cache entry was fetched again")
+	}
+
+	// Past the TTL: still shown at once, while a refetch runs.
+	cached := m.cache["api"]
+	cached.fetchedAt = time.Now().Add(-2 * detailsCacheTTL)
+	m.cache["api"] = cached
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: web})
+	m, cmd = m.Update(jobs.JobSelectedMsg{Job: api})
+	if m.loading || m.selectedJob == nil || m.selectedJob.FullName != "api" {
+		t.Errorf("stale job not shown while refetching: loading %v, job %v", m.loading, m.selectedJob)
+	}
+	if !fetchesDetails(cmd) {
+		t.Error("a stale cache entry was not refetched")
+	}
+
+	// A successful action drops the entry, so the next visit asks Jenkins.
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "api", details: detailsFor("api")})
+	m, cmd = m.startTriggerBuildExecution()
+	m, _ = m.Update(cmd().(tea.BatchMsg)[0]())
+	if _, ok := m.cache["api"]; ok {
+		t.Error("the cache entry survived a trigger")
+	}
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: web})
+	if _, cmd = m.Update(jobs.JobSelectedMsg{Job: api}); !fetchesDetails(cmd) {
+		t.Error("the job was not fetched again after the trigger")
+	}
+}
+
+func TestDetailsCacheIsBounded(t *testing.T) {
+	m := New(nil, nil)
+	for i := range maxCachedDetails + 10 {
+		name := fmt.Sprintf("job-%d", i)
+		m.cacheDetails(name, detailsFor(name))
+	}
+	if len(m.cache) != maxCachedDetails {
+		t.Errorf("cache holds %d jobs, want at most %d", len(m.cache), maxCachedDetails)
+	}
+	if _, ok := m.cache[fmt.Sprintf("job-%d", maxCachedDetails+9)]; !ok {
+		t.Error("the newest entry was evicted")
+	}
+}
+
+func TestLogSizeCacheIsBounded(t *testing.T) {
+	m := New(nil, nil)
+	for job := 0; job*trendBuilds <= 2*maxLogSizes; job++ {
+		sizes := make(map[int]int64, trendBuilds)
+		for number := 1; number <= trendBuilds; number++ {
+			sizes[number] = int64(number)
+		}
+		m.storeLogSizes(fmt.Sprintf("job-%d", job), sizes)
+	}
+	if len(m.logSizes) > maxLogSizes {
+		t.Errorf("cached %d log sizes, want at most %d", len(m.logSizes), maxLogSizes)
+	}
+}
+
+func TestDeleteAsksForTheJobNameAndRefreshesTheTree(t *testing.T) {
+	client := &jenkinstest.Client{}
+	m := New(client, nil).WithConfirmPolicy(confirm.Default())
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "team/api"}})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "team/api",
+		details: &jenkins.JobDetails{Job: jenkins.Job{Name: "api", FullName: "team/api"}}})
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("X")})
+	if cmd != nil || m.confirmation == nil {
+		t.Fatal("X deleted the job without asking")
+	}
+	// A wrong name doesn't delete; the prompt waits for the right one.
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("ap")}, {Type: tea.KeyEnter}} {
+		m, _ = m.Update(key)
+	}
+	if calls := client.CallsTo("DeleteJob"); len(calls) != 0 || m.confirmation == nil {
+		t.Fatalf("a partial name deleted the job: %v", calls)
+	}
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("i")}, {Type: tea.KeyEnter}} {
+		m, cmd = m.Update(key)
+	}
+	if cmd == nil {
+		t.Fatal("typing the name did not delete the job")
+	}
+
+	// The batch holds the delete and the spinner; run the delete.
+	m, cmd = m.Update(cmd().(tea.BatchMsg)[0]())
+	if calls := client.CallsTo("DeleteJob"); len(calls) != 1 || calls[0].Args[0] != "team/api" {
+		t.Fatalf("DeleteJob calls = %v, want one for team/api", calls)
+	}
+	if m.feedback == nil || m.feedback.isError || !strings.Contains(m.feedback.message, "Deleted api") {
+		t.Errorf("feedback = %+v, want the deletion", m.feedback)
+	}
+	batch := cmd().(tea.BatchMsg)
+	if _, ok := batch[len(batch)-1]().(jobs.RefreshRequestedMsg); !ok {
+		t.Error("deleting did not refresh the job tree")
+	}
+}
+
+func TestCancelledDeleteKeepsTheJob(t *testing.T) {
+	client := &jenkinstest.Client{}
+	m := New(client, nil).WithConfirmPolicy(confirm.Default())
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "api", details: detailsFor("api")})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("X")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if calls := client.CallsTo("DeleteJob"); len(calls) != 0 {
+		t.Errorf("DeleteJob calls = %v after Esc", calls)
+	}
+	if m.feedback == nil || m.feedback.message != "Job not deleted" {
+		t.Errorf("feedback = %+v, want the cancellation", m.feedback)
+	}
+}
+
+func TestFolderHealthCountsChildJobs(t *testing.T) {
+	built := func(color string) jenkins.Job {
+		return jenkins.Job{Color: color, LastBuild: &jenkins.Build{Number: 1}}
+	}
+	tests := []struct {
+		name     string
+		children []jenkins.Job
+		want     string
+	}{
+		{name: "empty folder", want: "—"},
+		{
+			name:     "mixed results",
+			children: []jenkins.Job{built("blue"), built("blue"), built("red"), built("yellow"), {Name: "new"}},
+			want:     "2 passing  ✗ 1 failing",
+		},
+		{
+			name:     "subfolders are left out",
+			children: []jenkins.Job{built("blue"), {Class: "com.cloudbees.hudson.plugins.folder.Folder"}},
+			want:     "1 passing",
+		},
+		{name: "never built", children: []jenkins.Job{{Name: "new"}}, want: "1 not built"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ansi.Strip(folderHealth(tt.children)); !strings.Contains(got, tt.want) {
+				t.Errorf("folderHealth() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFolderContentSummarizesTheFolder(t *testing.T) {
+	folder := jenkins.Job{
+		Name:        "team",
+		FullName:    "platform/team",
+		Class:       "com.cloudbees.hudson.plugins.folder.Folder",
+		Description: "<p>Services of the <b>team</b> &amp; their tools</p>",
+		Jobs: []jenkins.Job{
+			{Name: "api", Color: "blue", LastBuild: &jenkins.Build{Number: 3}},
+			{Name: "web", Color: "red", LastBuild: &jenkins.Build{Number: 9}},
+			{Name: "tools", Class: "com.cloudbees.hudson.plugins.folder.Folder"},
+		},
+	}
+	m := New(nil, nil)
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: folder})
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "platform/team",
+		details: &jenkins.JobDetails{Job: folder, Views: []jenkins.View{{Name: "All"}, {Name: "Release"}}}})
+
+	content := ansi.Strip(m.renderDetailsContent())
+	for _, want := range []string{"Folder: platform/team", "Services of the team & their tools",
+		"Contains: 2 jobs, 1 folders", "1 passing", "1 failing", "All, Release"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("folder content does not show %q:\n%s", want, content)
+		}
+	}
+}
+
+func TestScrollKeysMoveTheViewportOnly(t *testing.T) {
+	client := &jenkinstest.Client{}
+	m := New(client, nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 60, Height: 8})
+	m, _ = m.Update(jobs.JobSelectedMsg{Job: jenkins.Job{Name: "api", FullName: "api"}})
+	details := detailsFor("api")
+	for number := maxRecentBuilds; number > 0; number-- {
+		details.Builds = append(details.Builds, jenkins.Build{Number: number, Result: "SUCCESS"})
+	}
+	details.Job.LastBuild = &details.Builds[0]
+	m, _ = m.Update(jobDetailsResultMsg{ticket: m.requests.Current(), jobFullName: "api", details: details})
+	if m.viewport.TotalLineCount() <= m.viewport.Height {
+		t.Fatalf("content of %d lines fits the viewport; nothing to scroll", m.viewport.TotalLineCount())
+	}
+
+	press := func(key tea.KeyMsg) {
+		t.Helper()
+		var cmd tea.Cmd
+		if m, cmd = m.Update(key); cmd != nil {
+			t.Errorf("%s started an action", key)
+		}
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J")})
+	press(tea.KeyMsg{Type: tea.KeyCtrlE})
+	if m.viewport.YOffset != 2 {
+		t.Errorf("offset after J and ctrl+e = %d, want 2", m.viewport.YOffset)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	if m.viewport.YOffset != 1 {
+		t.Errorf("offset after K = %d, want 1", m.viewport.YOffset)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEnd})
+	if !m.viewport.AtBottom() || !slices.Contains(m.TitleBar().Chips, "100%") {
+		t.Errorf("end left the viewport at %d with chips %v", m.viewport.YOffset, m.TitleBar().Chips)
+	}
+	press(tea.KeyMsg{Type: tea.KeyPgUp})
+	press(tea.KeyMsg{Type: tea.KeyHome})
+	if !m.viewport.AtTop() {
+		t.Errorf("home left the viewport at %d", m.viewport.YOffset)
+	}
+	if calls := client.Calls(); len(calls) != 0 {
+		t.Errorf("scrolling called Jenkins: %v", calls)
+	}
+}
@@ -0,0 +1,81 @@
+package details
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/concurrent"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+// maxListedRunning is how many running build numbers the details name.
+const maxListedRunning = 5
+
+// runningBuildsMsg carries the running builds of a job allowing concurrent
+// builds.
+type runningBuildsMsg struct {
+	ticket      uint64
+	jobFullName string
+	builds      []jenkins.Build
+	err         error
+}
+
+// fetchRunningCmd looks through the selected job's newest builds for running
+// ones when it allows concurrent builds, as lastBuild only shows the newest.
+func (m *Model) fetchRunningCmd(ticket uint64) tea.Cmd {
+	job := m.selectedJob
+	if m.client == nil || job == nil || !m.concurrent {
+		return nil
+	}
+	if m.cancelRunning != nil {
+		m.cancelRunning()
+	}
+	var ctx context.Context
+	ctx, m.cancelRunning = context.WithCancel(context.Background())
+	client := m.client
+	fullName := job.FullName
+	return func() tea.Msg {
+		builds, err := client.GetBuilds(ctx, fullName, 0, concurrent.ScanBuilds)
+		return runningBuildsMsg{ticket: ticket, jobFullName: fullName, builds: concurrent.Running(builds), err: err}
+	}
+}
+
+// applyRunningSnapshot keeps the running builds of a concurrent job current
+// from each queue poll, which sees every build on an executor, rather than
+// from the builds looked through when the job was selected.
+func (m *Model) applyRunningSnapshot(running []jenkins.RunningBuild) {
+	job := m.selectedJob
+	if job == nil || !m.concurrent || job.URL == "" {
+		return
+	}
+	m.running = nil
+	for _, build := range jenkins.RunningByJob(running)[strings.TrimSuffix(job.URL, "/")] {
+		m.running = append(m.running, jenkins.Build{
+			Number:    build.BuildNumber,
+			Timestamp: build.StartTime,
+			Building:  true,
+			URL:       build.URL,
+		})
+	}
+}
+
+// runningLine counts the running builds of a concurrent job, e.g. "Running:
+// 3 builds (#14, #13, #11)", or returns "" unless more than one runs.
+func (m *Model) runningLine() string {
+	if len(m.running) < 2 {
+		return ""
+	}
+	var numbers []string
+	for i, build := range m.running {
+		if i == maxListedRunning {
+			numbers = append(numbers, "…")
+			break
+		}
+		numbers = append(numbers, fmt.Sprintf("#%d", build.Number))
+	}
+	return ui.HighlightStyle.Render(fmt.Sprintf("Running: %d builds", len(m.running))) +
+		ui.SubtleStyle.Render(" ("+strings.Join(numbers, ", ")+") · B to list")
+}
@@ -0,0 +1,117 @@
+package details
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+// streakMsg carries the build a job's current streak is measured from.
+type streakMsg struct {
+	key   string
+	build *jenkins.Build
+	err   error
+}
+
+// streakPermalink picks the permalink that ends the current streak of a job
+// whose last build had result: the last success of a failing job, the last
+// stable build of an unstable one and the last failure of a passing one.
+func streakPermalink(client jenkins.JenkinsClient, result string) func(context.Context, string) (*jenkins.Build, error) {
+	switch result {
+	case "FAILURE":
+		return client.GetLastSuccessfulBuild
+	case "UNSTABLE":
+		return client.GetLastStableBuild
+	case "SUCCESS":
+		return client.GetLastFailedBuild
+	}
+	return nil
+}
+
+// fetchStreakCmd looks up where the selected job's streak started, once per
+// last build. The lookup is cancelled with the selection.
+func (m *Model) fetchStreakCmd() tea.Cmd {
+	job := m.selectedJob
+	if m.client == nil || job == nil || job.IsFolder() || job.LastBuild == nil || job.LastBuild.Building {
+		return nil
+	}
+	key := logSizeKey(job.FullName, job.LastBuild.Number)
+	if _, ok := m.streaks[key]; ok {
+		return nil
+	}
+	fetch := streakPermalink(m.client, job.LastBuild.Result)
+	if fetch == nil {
+		return nil
+	}
+
+	ctx := m.selectionContext()
+	fullName := job.FullName
+	return func() tea.Msg {
+		build, err := fetch(ctx, fullName)
+		return streakMsg{key: key, build: build, err: err}
+	}
+}
+
+// streakStart returns the first build after since among the recent builds,
+// where the streak since ended began. Build numbers skip deleted builds, so
+// it is only known when the recent builds reach back to since.
+func (m *Model) streakStart(since *jenkins.Build) (int, bool) {
+	start, reached := 0, false
+	for _, build := range m.recentBuilds {
+		if build.Number <= since.Number {
+			reached = true
+			break
+		}
+		start = build.Number
+	}
+	return start, reached && start != 0
+}
+
+// streakLine describes how long the last result has lasted, e.g. "Failing
+// since #481 · last success #480, 3 days ago", or returns "" when unknown.
+// The "since" build is left out when the recent builds don't reach back to
+// the end of the streak.
+func (m *Model) streakLine() string {
+	job := m.selectedJob
+	if job == nil || job.LastBuild == nil {
+		return ""
+	}
+	last := job.LastBuild
+	since, ok := m.streaks[logSizeKey(job.FullName, last.Number)]
+	if !ok {
+		return ""
+	}
+
+	switch last.Result {
+	case "FAILURE":
+		if since == nil {
+			return ui.ErrorStyle.Render("Never succeeded")
+		}
+		return ui.ErrorStyle.Render(m.streakLabel("Failing", since)) +
+			ui.SubtleStyle.Render(fmt.Sprintf(" · last success #%d, %s", since.Number, formatRelativeTimeFromBuild(since)))
+	case "UNSTABLE":
+		if since == nil {
+			return ui.UnstableStyle.Render("Never stable")
+		}
+		return ui.UnstableStyle.Render(m.streakLabel("Unstable", since)) +
+			ui.SubtleStyle.Render(fmt.Sprintf(" · last stable #%d, %s", since.Number, formatRelativeTimeFromBuild(since)))
+	case "SUCCESS":
+		if since == nil {
+			return ""
+		}
+		return ui.SubtleStyle.Render(fmt.Sprintf("Last failure #%d, %s", since.Number, formatRelativeTimeFromBuild(since)))
+	}
+	return ""
+}
+
+// streakLabel returns state with the build the streak began at, e.g.
+// "Failing since #481", or state alone when that build is unknown.
+func (m *Model) streakLabel(state string, since *jenkins.Build) string {
+	if start, ok := m.streakStart(since); ok {
+		return fmt.Sprintf("%s since #%d", state, start)
+	}
+	return state
+}
@@ -0,0 +1,179 @@
+// Package export renders jobs and queue snapshots as CSV for spreadsheets and reports.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/queuelog"
+)
+
+var (
+	jobsHeader  = []string{"job", "status", "last_build", "last_build_result", "last_build_started", "duration_seconds", "url"}
+	queueHeader = []string{"state", "job", "build", "since", "elapsed_seconds", "node", "reason"}
+
+	queueHistoryHeader = []string{"job", "build", "outcome", "enqueued_at", "started_at", "wait_seconds", "url"}
+)
+
+// Jobs writes one row per buildable job (folders are flattened) sorted by full name.
+func Jobs(w io.Writer, jobs []jenkins.Job) error {
+	var flat []jenkins.Job
+	flattenJobs(jobs, &flat)
+	sort.Slice(flat, func(i, j int) bool { return jobFullName(flat[i]) < jobFullName(flat[j]) })
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(jobsHeader); err != nil {
+		return err
+	}
+	for _, job := range flat {
+		row := []string{jobFullName(job), job.GetStatus(), "", "", "", "", job.URL}
+		if build := job.LastBuild; build != nil {
+			row[2] = strconv.Itoa(build.Number)
+			row[3] = build.Result
+			if build.Building && build.Result == "" {
+				row[3] = "BUILDING"
+			}
+			row[4] = formatTimestamp(build.Timestamp)
+			if build.Duration > 0 {
+				row[5] = strconv.FormatInt(build.Duration/1000, 10)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Queue writes running builds followed by queued items, as of now.
+func Queue(w io.Writer, running []jenkins.RunningBuild, queued []jenkins.QueueItem, now time.Time) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(queueHeader); err != nil {
+		return err
+	}
+	for _, build := range running {
+		row := []string{
+			"running",
+			build.JobName,
+			strconv.Itoa(build.BuildNumber),
+			formatTimestamp(build.StartTime),
+			elapsedSeconds(build.StartTime, now),
+			build.Node,
+			"",
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	for _, item := range queued {
+		state := "queued"
+		switch {
+		case item.Stuck:
+			state = "stuck"
+		case item.Blocked:
+			state = "blocked"
+		}
+		row := []string{
+			state,
+			item.Task.Name,
+			"",
+			formatTimestamp(item.InQueueSince),
+			elapsedSeconds(item.InQueueSince, now),
+			"",
+			item.Why,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// QueueHistory writes one row per logged queue wait, in the order given.
+func QueueHistory(w io.Writer, entries []queuelog.Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(queueHistoryHeader); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		row := []string{
+			entry.Job,
+			"",
+			entry.Outcome,
+			formatTimestamp(entry.EnqueuedAt.UnixMilli()),
+			formatTimestamp(entry.StartedAt.UnixMilli()),
+			strconv.FormatInt(int64(entry.Wait()/time.Second), 10),
+			entry.URL,
+		}
+		if entry.Build > 0 {
+			row[1] = strconv.Itoa(entry.Build)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteFile creates dir/jdash-<kind>-<timestamp>.csv using write and returns its path.
+func WriteFile(dir, kind string, now time.Time, write func(io.Writer) error) (string, error) {
+	path := filepath.Join(dir, fmt.Sprintf("jdash-%s-%s.csv", kind, now.Format("20060102-150405")))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		os.Remove(path)
+		return "", fmt.Errorf("failed to write export file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write export file: %w", err)
+	}
+	return path, nil
+}
+
+func flattenJobs(jobs []jenkins.Job, out *[]jenkins.Job) {
+	for _, job := range jobs {
+		if job.IsFolder() {
+			flattenJobs(job.Jobs, out)
+			continue
+		}
+		*out = append(*out, job)
+	}
+}
+
+func jobFullName(job jenkins.Job) string {
+	if job.FullName != "" {
+		return job.FullName
+	}
+	return job.Name
+}
+
+func formatTimestamp(millis int64) string {
+	if millis <= 0 {
+		return ""
+	}
+	return time.UnixMilli(millis).UTC().Format(time.RFC3339)
+}
+
+func elapsedSeconds(sinceMillis int64, now time.Time) string {
+	if sinceMillis <= 0 {
+		return ""
+	}
+	elapsed := now.Sub(time.UnixMilli(sinceMillis))
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return strconv.FormatInt(int64(elapsed/time.Second), 10)
+}
@@ -0,0 +1,128 @@
+package export
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/queuelog"
+)
+
+func TestJobs(t *testing.T) {
+	started := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	jobs := []jenkins.Job{
+		{
+			Name:  "team",
+			Class: "com.cloudbees.hudson.plugins.folder.Folder",
+			Jobs: []jenkins.Job{
+				{
+					Name:     "deploy",
+					FullName: "team/deploy",
+					Color:    "red",
+					URL:      "https://ci/job/team/job/deploy/",
+					LastBuild: &jenkins.Build{
+						Number:    12,
+						Result:    "FAILURE",
+						Timestamp: started.UnixMilli(),
+						Duration:  95_500,
+					},
+				},
+			},
+		},
+		{Name: "api, \"core\"", FullName: "api, \"core\"", Color: "notbuilt"},
+	}
+
+	var buf bytes.Buffer
+	if err := Jobs(&buf, jobs); err != nil {
+		t.Fatalf("Jobs() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"job,status,last_build,last_build_result,last_build_started,duration_seconds,url",
+		`"api, ""core""",NEVER_BUILT,,,,,`,
+		"team/deploy,FAILED,12,FAILURE,2024-03-01T12:00:00Z,95,https://ci/job/team/job/deploy/",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("Jobs() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 10, 0, 0, time.UTC)
+	running := []jenkins.RunningBuild{
+		{JobName: "deploy", BuildNumber: 7, StartTime: now.Add(-90 * time.Second).UnixMilli(), Node: "agent-1"},
+	}
+	queued := []jenkins.QueueItem{
+		{Why: "Waiting for next available executor", InQueueSince: now.Add(-time.Minute).UnixMilli()},
+		{Blocked: true, Why: "Build #6 is already in progress"},
+	}
+	queued[0].Task.Name = "lint"
+	queued[1].Task.Name = "deploy"
+
+	var buf bytes.Buffer
+	if err := Queue(&buf, running, queued, now); err != nil {
+		t.Fatalf("Queue() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"state,job,build,since,elapsed_seconds,node,reason",
+		"running,deploy,7,2024-03-01T12:08:30Z,90,agent-1,",
+		"queued,lint,,2024-03-01T12:09:00Z,60,,Waiting for next available executor",
+		"blocked,deploy,,,,,Build #6 is already in progress",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("Queue() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestQueueHistory(t *testing.T) {
+	queued := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []queuelog.Entry{
+		{Job: "team/deploy", URL: "https://ci/job/team/job/deploy/", Build: 12, Outcome: queuelog.Started,
+			EnqueuedAt: queued, StartedAt: queued.Add(95 * time.Second)},
+		{Job: "lint", URL: "https://ci/job/lint/", Outcome: queuelog.Left,
+			EnqueuedAt: queued, StartedAt: queued.Add(3 * time.Second)},
+	}
+
+	var buf bytes.Buffer
+	if err := QueueHistory(&buf, entries); err != nil {
+		t.Fatalf("QueueHistory() error = %v", err)
+	}
+
+	want := strings.Join([]string{
+		"job,build,outcome,enqueued_at,started_at,wait_seconds,url",
+		"team/deploy,12,started,2024-03-01T12:00:00Z,2024-03-01T12:01:35Z,95,https://ci/job/team/job/deploy/",
+		"lint,,left,2024-03-01T12:00:00Z,2024-03-01T12:00:03Z,3,https://ci/job/lint/",
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("QueueHistory() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 3, 1, 12, 0, 5, 0, time.UTC)
+
+	path, err := WriteFile(dir, "jobs", now, func(w io.Writer) error {
+		_, err := w.Write([]byte("job\n"))
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if want := filepath.Join(dir, "jdash-jobs-20240301-120005.csv"); path != want {
+		t.Errorf("WriteFile() path = %q, want %q", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "job\n" {
+		t.Errorf("exported file = %q, %v", data, err)
+	}
+}
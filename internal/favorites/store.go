@@ -0,0 +1,124 @@
+// Package favorites persists the jobs the user pinned to the top of the jobs
+// tree, so the few that matter on a large instance stay one keypress away.
+package favorites
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jsonstore"
+)
+
+const fileName = "favorites.json"
+
+// Store keeps the favorite jobs' full names in memory and persists them to
+// the config directory on Save. Changes apply in memory at once, so the UI
+// can show them while Save writes the file off the update loop. It is safe
+// for concurrent use; a nil Store is empty.
+type Store struct {
+	mu   sync.RWMutex
+	file *jsonstore.File
+	jobs map[string]bool
+}
+
+type storeFile struct {
+	Jobs []string `json:"jobs"`
+}
+
+// Load reads favorites from favorites.json in the config directory.
+func Load() (*Store, error) {
+	return LoadFrom(filepath.Join(auth.ConfigDir(), fileName))
+}
+
+// LoadFrom reads favorites from path; the store has none when the file is
+// missing or unreadable.
+func LoadFrom(path string) (*Store, error) {
+	var saved storeFile
+	file, err := jsonstore.Load(path, &saved)
+	store := &Store{file: file, jobs: make(map[string]bool, len(saved.Jobs))}
+	for _, name := range saved.Jobs {
+		store.jobs[name] = true
+	}
+	return store, err
+}
+
+// Has reports whether a job is a favorite.
+func (s *Store) Has(jobFullName string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.jobs[jobFullName]
+}
+
+// List returns the favorite jobs sorted by full name.
+func (s *Store) List() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Toggle adds a job to the favorites or removes it and reports whether the
+// job is now a favorite.
+func (s *Store) Toggle(jobFullName string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	favorite := !s.jobs[jobFullName]
+	if favorite {
+		s.jobs[jobFullName] = true
+	} else {
+		delete(s.jobs, jobFullName)
+	}
+	return favorite
+}
+
+// Add makes the jobs favorites.
+func (s *Store) Add(jobFullNames ...string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range jobFullNames {
+		s.jobs[name] = true
+	}
+}
+
+// Rename moves a favorite to a job's new full name and reports whether the
+// job was a favorite.
+func (s *Store) Rename(oldFullName, newFullName string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.jobs[oldFullName] || oldFullName == newFullName {
+		return false
+	}
+	delete(s.jobs, oldFullName)
+	s.jobs[newFullName] = true
+	return true
+}
+
+// Save persists the favorites as they are when it runs, so the last of
+// several saves writes the latest ones.
+func (s *Store) Save() error {
+	if s == nil {
+		return nil
+	}
+	return s.file.Save(func() any { return storeFile{Jobs: s.List()} })
+}
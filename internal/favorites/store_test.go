@@ -0,0 +1,44 @@
+package favorites
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.json")
+
+	store, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() on missing file error: %v", err)
+	}
+	for _, name := range []string{"Production/web", "Production/api", "Nightly/e2e"} {
+		if !store.Toggle(name) {
+			t.Fatalf("Toggle(%q) = false; want a new favorite", name)
+		}
+	}
+	if store.Toggle("Nightly/e2e") {
+		t.Fatal("second Toggle() = true; want it removed")
+	}
+	if !store.Rename("Production/api", "Production/api-v2") {
+		t.Fatal("Rename() = false for a favorite")
+	}
+	if store.Rename("Nightly/e2e", "Nightly/e2e-v2") {
+		t.Error("Rename() = true for a job that is not a favorite")
+	}
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error: %v", err)
+	}
+	if got, want := reloaded.List(), []string{"Production/api-v2", "Production/web"}; !slices.Equal(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+	if reloaded.Has("Production/api") {
+		t.Error("favorite still present under old name")
+	}
+}
@@ -0,0 +1,39 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+// filterText is what the filter matches a build against: its number and
+// display name, its parameters as NAME=value, and its causes.
+func filterText(build *jenkins.Build) string {
+	parts := []string{fmt.Sprintf("#%d", build.Number), build.DisplayName}
+	for _, action := range build.Actions {
+		for _, param := range action.Parameters {
+			value := ""
+			if param.Value != nil {
+				value = fmt.Sprint(param.Value)
+			}
+			parts = append(parts, param.Name+"="+value)
+		}
+		for _, cause := range action.Causes {
+			parts = append(parts, cause.ShortDescription, cause.UserID, cause.UserName)
+		}
+	}
+	return strings.ToLower(strings.Join(parts, "\n"))
+}
+
+// matchesFilter reports whether every word of query occurs in the build's
+// filter text, ignoring case, e.g. "version=2.4.1 alice".
+func matchesFilter(build *jenkins.Build, query string) bool {
+	text := filterText(build)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,709 @@
+// Package history is a modal browsing a job's whole build history, loaded a
+// page at a time as the user scrolls, with filters and promotions.
+package history
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/inflight"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+const (
+	// pageSize is how many builds are fetched at a time.
+	pageSize = 25
+	// prefetchRows is how close to the last loaded build the cursor gets
+	// before the next page is fetched.
+	prefetchRows = 5
+	// filterScanPages is how many pages in a row a filter may go without a
+	// new match before paging waits for the user, so a filter matching
+	// nothing does not load a long history whole.
+	filterScanPages = 4
+
+	maxModalWidth  = 100
+	minModalWidth  = 40
+	modalChromeRow = 10
+)
+
+// ClosedMsg is emitted when the user dismisses the history modal.
+type ClosedMsg struct{}
+
+// OpenLogsMsg asks for the console of a build picked from the history.
+type OpenLogsMsg struct {
+	Job   jenkins.Job
+	Build jenkins.Build
+}
+
+type pageFetchedMsg struct {
+	offset int
+	builds []jenkins.Build
+	err    error
+}
+
+type countFetchedMsg struct {
+	count int
+	err   error
+}
+
+type processesFetchedMsg struct {
+	processes []string
+	err       error
+}
+
+type promotedMsg struct {
+	build   int
+	process string
+	err     error
+}
+
+// promotion is the process chooser opened with P on a build.
+type promotion struct {
+	build  int
+	cursor int
+	// confirming is set once a process is chosen and the policy asks before
+	// forcing it.
+	confirming bool
+	prompt     confirm.Prompt
+}
+
+// Model is a modal listing a job's builds, newest first. Older builds are
+// fetched page by page as the cursor nears the end of the list; with a
+// filter, until enough builds match or every build is loaded.
+type Model struct {
+	client jenkins.JenkinsClient
+	job    jenkins.Job
+	// marker is the build parameter that marks deployments; see
+	// jenkins.Build.Promotions.
+	marker string
+	// user is the configured Jenkins user, whose builds get a "me" badge.
+	user string
+	// policy decides how to confirm a forced promotion, and tracker follows
+	// it until Jenkins answers.
+	policy  confirm.Policy
+	tracker *inflight.Tracker
+
+	spinner spinner.Model
+	builds  []jenkins.Build
+	// total is the number of builds Jenkins keeps; zero until known.
+	total int
+	// loading is set while a page is being fetched.
+	loading bool
+	// exhausted is set once a page came back short, i.e. every build is loaded.
+	exhausted bool
+	err       error
+
+	// filter narrows the list to the builds matching its words.
+	filter textinput.Model
+	// rows are the indexes into builds of the listed builds.
+	rows []int
+	// missedPages counts the pages fetched in a row for the filter that
+	// matched none of their builds; see filterScanPages.
+	missedPages int
+
+	// cursor is the selected row.
+	cursor int
+	// top is the first visible row.
+	top int
+
+	// processes are the job's promotion processes, fetched on the first P.
+	processes       []string
+	processesLoaded bool
+	promotion       *promotion
+	// notice reports the last promotion, e.g. "✓ Started Release for #42".
+	notice      string
+	noticeIsErr bool
+
+	width  int
+	height int
+}
+
+// New creates a history modal for job. Builds promoted with the
+// promoted-builds plugin, or whose marker parameter is set, get a star.
+func New(client jenkins.JenkinsClient, job jenkins.Job, marker string) *Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.HighlightStyle
+
+	filter := textinput.New()
+	filter.Placeholder = "parameter value, cause, name..."
+	filter.Prompt = "/ "
+	filter.PromptStyle = ui.HighlightStyle
+	filter.PlaceholderStyle = ui.SubtleStyle
+	filter.CharLimit = 128
+
+	return &Model{
+		client:  client,
+		job:     job,
+		marker:  marker,
+		spinner: s,
+		filter:  filter,
+	}
+}
+
+// WithUser returns the modal marking the builds user started.
+func (m *Model) WithUser(user string) *Model {
+	m.user = user
+	return m
+}
+
+// WithConfirmPolicy sets how forced promotions are confirmed; without it
+// the default policy applies.
+func (m *Model) WithConfirmPolicy(policy confirm.Policy) *Model {
+	m.policy = policy
+	return m
+}
+
+// WithTracker registers forced promotions with tracker, so quitting can wait
+// for Jenkins to answer.
+func (m *Model) WithTracker(tracker *inflight.Tracker) *Model {
+	m.tracker = tracker
+	return m
+}
+
+// CapturesInput reports whether the filter or a type-the-name confirmation
+// is being typed into.
+func (m *Model) CapturesInput() bool {
+	if m.promotion != nil && m.promotion.confirming {
+		return m.promotion.prompt.CapturesInput()
+	}
+	return m.filter.Focused()
+}
+
+// Init fetches the first page and the build count.
+func (m *Model) Init() tea.Cmd {
+	return tea.Batch(m.fetchPageCmd(), m.fetchCountCmd())
+}
+
+// Update handles TEA messages for the modal.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ensureCursorVisible()
+		return m, nil
+
+	case pageFetchedMsg:
+		if msg.offset != len(m.builds) {
+			return m, nil
+		}
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.builds = append(m.builds, msg.builds...)
+		if len(msg.builds) < pageSize {
+			m.exhausted = true
+		}
+		matched := len(m.rows)
+		m.applyFilter()
+		if m.filtering() && len(m.rows) == matched {
+			m.missedPages++
+		} else {
+			m.missedPages = 0
+		}
+		// The cursor may already sit near the end, e.g. after G.
+		return m, m.fetchMoreIfNeeded()
+
+	case countFetchedMsg:
+		if msg.err == nil {
+			m.total = msg.count
+		}
+		return m, nil
+
+	case processesFetchedMsg:
+		if msg.err != nil {
+			m.promotion = nil
+			m.setNotice("Failed to load promotion processes: "+msg.err.Error(), true)
+			return m, nil
+		}
+		m.processes = msg.processes
+		m.processesLoaded = true
+		return m, m.choosePromotion()
+
+	case promotedMsg:
+		if msg.err != nil {
+			m.setNotice(fmt.Sprintf("Failed to promote #%d: %v", msg.build, msg.err), true)
+			return m, nil
+		}
+		m.setNotice(fmt.Sprintf("✓ Started %s for #%d; press r to reload once it finishes", msg.process, msg.build), false)
+		return m, nil
+
+	case spinner.TickMsg:
+		if !m.loading {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.promotion != nil && m.processesLoaded {
+		return m.handlePromotionKey(msg)
+	}
+	if m.filter.Focused() {
+		return m.handleFilterKey(msg)
+	}
+
+	switch msg.String() {
+	case "esc", "H":
+		if msg.String() == "esc" && m.filter.Value() != "" {
+			m.filter.SetValue("")
+			m.missedPages = 0
+			m.applyFilter()
+			return m, m.fetchMoreIfNeeded()
+		}
+		return m, func() tea.Msg { return ClosedMsg{} }
+	case "/":
+		m.filter.CursorEnd()
+		return m, m.filter.Focus()
+	case "m":
+		if m.scanPaused() {
+			m.missedPages = 0
+			return m, m.fetchPageCmd()
+		}
+		return m, nil
+	case "enter":
+		if build := m.selected(); build != nil {
+			job, build := m.job, *build
+			return m, func() tea.Msg { return OpenLogsMsg{Job: job, Build: build} }
+		}
+		return m, nil
+	case "r":
+		if m.err != nil {
+			m.err = nil
+			return m, m.fetchPageCmd()
+		}
+		if !m.loading {
+			// Reload from the top, e.g. to see a finished promotion.
+			m.builds, m.cursor, m.top, m.exhausted = nil, 0, 0, false
+			m.rows, m.missedPages = nil, 0
+			m.notice = ""
+			return m, m.fetchPageCmd()
+		}
+		return m, nil
+	case "P":
+		build := m.selected()
+		if build == nil || m.promotion != nil {
+			return m, nil
+		}
+		m.promotion = &promotion{build: build.Number}
+		m.notice = ""
+		if !m.processesLoaded {
+			m.setNotice("Loading promotion processes...", false)
+			return m, m.fetchProcessesCmd()
+		}
+		return m, m.choosePromotion()
+	case "down", "j":
+		m.moveCursor(1)
+	case "up", "k":
+		m.moveCursor(-1)
+	case "pgdown", "ctrl+d":
+		m.moveCursor(m.visibleRows())
+	case "pgup", "ctrl+u":
+		m.moveCursor(-m.visibleRows())
+	case "home", "g":
+		m.moveCursor(-len(m.rows))
+	case "end", "G":
+		m.moveCursor(len(m.rows))
+	default:
+		return m, nil
+	}
+	return m, m.fetchMoreIfNeeded()
+}
+
+// handleFilterKey edits the filter: Enter keeps it and returns to the list,
+// Esc clears it.
+func (m *Model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.filter.Blur()
+		return m, nil
+	case "esc":
+		m.filter.Blur()
+		m.filter.SetValue("")
+		m.missedPages = 0
+		m.applyFilter()
+		return m, m.fetchMoreIfNeeded()
+	}
+
+	previous := m.filter.Value()
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(msg)
+	if m.filter.Value() != previous {
+		m.missedPages = 0
+		m.applyFilter()
+		return m, tea.Batch(cmd, m.fetchMoreIfNeeded())
+	}
+	return m, cmd
+}
+
+// applyFilter lists the loaded builds matching the filter, keeping the
+// cursor on the selected build while it is still listed.
+func (m *Model) applyFilter() {
+	selected := -1
+	if m.cursor < len(m.rows) {
+		selected = m.rows[m.cursor]
+	}
+
+	query := strings.TrimSpace(m.filter.Value())
+	m.rows = m.rows[:0]
+	for i := range m.builds {
+		if query == "" || matchesFilter(&m.builds[i], query) {
+			m.rows = append(m.rows, i)
+		}
+	}
+
+	m.cursor = 0
+	for row, index := range m.rows {
+		if index == selected {
+			m.cursor = row
+			break
+		}
+	}
+	m.top = 0
+	m.ensureCursorVisible()
+}
+
+// selected returns the build under the cursor, or nil.
+func (m *Model) selected() *jenkins.Build {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return nil
+	}
+	return &m.builds[m.rows[m.cursor]]
+}
+
+// choosePromotion shows the process chooser, or explains why there is none.
+func (m *Model) choosePromotion() tea.Cmd {
+	if m.promotion == nil {
+		return nil
+	}
+	if len(m.processes) == 0 {
+		m.promotion = nil
+		m.setNotice("This job has no promotion processes (promoted-builds plugin)", true)
+		return nil
+	}
+	m.notice = ""
+	return nil
+}
+
+func (m *Model) handlePromotionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.promotion.confirming {
+		prompt, result := m.promotion.prompt.HandleKey(msg)
+		m.promotion.prompt = prompt
+		switch result {
+		case confirm.Confirmed:
+			return m, m.promote()
+		case confirm.Cancelled:
+			m.promotion.confirming = false
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc", "P":
+		m.promotion = nil
+	case "down", "j":
+		if m.promotion.cursor < len(m.processes)-1 {
+			m.promotion.cursor++
+		}
+	case "up", "k":
+		if m.promotion.cursor > 0 {
+			m.promotion.cursor--
+		}
+	case "enter":
+		level := m.policy.LevelFor(confirm.ActionPromote, m.job.FullName)
+		if level == confirm.None {
+			return m, m.promote()
+		}
+		question := fmt.Sprintf("Force %s on #%d, skipping its conditions?", m.processes[m.promotion.cursor], m.promotion.build)
+		m.promotion.confirming = true
+		m.promotion.prompt = confirm.NewPrompt(level, question, m.job.Name)
+	}
+	return m, nil
+}
+
+// promote closes the chooser and forces the chosen process on its build.
+func (m *Model) promote() tea.Cmd {
+	build, process := m.promotion.build, m.processes[m.promotion.cursor]
+	m.promotion = nil
+	m.setNotice(fmt.Sprintf("Promoting #%d with %s...", build, process), false)
+	return m.promoteCmd(build, process)
+}
+
+func (m *Model) setNotice(text string, isErr bool) {
+	m.notice = text
+	m.noticeIsErr = isErr
+}
+
+func (m *Model) moveCursor(delta int) {
+	m.cursor += delta
+	if m.cursor > len(m.rows)-1 {
+		m.cursor = len(m.rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	m.ensureCursorVisible()
+}
+
+func (m *Model) ensureCursorVisible() {
+	rows := m.visibleRows()
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+rows {
+		m.top = m.cursor - rows + 1
+	}
+}
+
+// fetchMoreIfNeeded fetches the next page once the cursor is within
+// prefetchRows of the last listed build.
+func (m *Model) fetchMoreIfNeeded() tea.Cmd {
+	if m.loading || m.exhausted || m.err != nil {
+		return nil
+	}
+	if len(m.rows)-1-m.cursor >= prefetchRows || m.scanPaused() {
+		return nil
+	}
+	return m.fetchPageCmd()
+}
+
+// filtering reports whether the filter narrows the list.
+func (m *Model) filtering() bool {
+	return strings.TrimSpace(m.filter.Value()) != ""
+}
+
+// scanPaused reports whether paging for the filter stopped after
+// filterScanPages pages without a match, until the user asks for more.
+func (m *Model) scanPaused() bool {
+	return m.filtering() && !m.exhausted && m.missedPages >= filterScanPages
+}
+
+func (m *Model) fetchPageCmd() tea.Cmd {
+	m.loading = true
+	client := m.client
+	fullName := m.job.FullName
+	offset := len(m.builds)
+	return tea.Batch(m.spinner.Tick, func() tea.Msg {
+		if client == nil {
+			return pageFetchedMsg{offset: offset, err: fmt.Errorf("Jenkins client not configured")}
+		}
+		builds, err := client.GetBuilds(context.Background(), fullName, offset, pageSize)
+		return pageFetchedMsg{offset: offset, builds: builds, err: err}
+	})
+}
+
+func (m *Model) fetchCountCmd() tea.Cmd {
+	client := m.client
+	fullName := m.job.FullName
+	return func() tea.Msg {
+		if client == nil {
+			return countFetchedMsg{err: fmt.Errorf("Jenkins client not configured")}
+		}
+		count, err := client.GetBuildCount(context.Background(), fullName)
+		return countFetchedMsg{count: count, err: err}
+	}
+}
+
+func (m *Model) fetchProcessesCmd() tea.Cmd {
+	client := m.client
+	fullName := m.job.FullName
+	return func() tea.Msg {
+		if client == nil {
+			return processesFetchedMsg{err: fmt.Errorf("Jenkins client not configured")}
+		}
+		processes, err := client.GetPromotionProcesses(context.Background(), fullName)
+		return processesFetchedMsg{processes: processes, err: err}
+	}
+}
+
+func (m *Model) promoteCmd(build int, process string) tea.Cmd {
+	client := m.client
+	fullName := m.job.FullName
+	ctx, done := m.tracker.Start(fmt.Sprintf("Promote %s #%d with %s", fullName, build, process))
+	return func() tea.Msg {
+		err := fmt.Errorf("Jenkins client not configured")
+		if client != nil {
+			err = client.PromoteBuild(ctx, fullName, build, process)
+		}
+		done(err)
+		return promotedMsg{build: build, process: process, err: err}
+	}
+}
+
+// View renders the modal.
+func (m *Model) View() string {
+	var content strings.Builder
+	content.WriteString(ui.TitleStyle.Render("History: " + m.job.FullName))
+	content.WriteString("\n")
+	content.WriteString(ui.SubtleStyle.Render(m.countLabel()))
+	content.WriteString("\n")
+	if m.filter.Focused() || m.filter.Value() != "" {
+		content.WriteString(m.filter.View())
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	rows := m.visibleRows()
+	end := m.top + rows
+	if end > len(m.rows) {
+		end = len(m.rows)
+	}
+	for i := m.top; i < end; i++ {
+		line := renderBuild(&m.builds[m.rows[i]], m.marker, m.user)
+		if i == m.cursor {
+			line = ui.SelectedStyle.Render(line)
+		}
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+
+	switch {
+	case m.err != nil:
+		content.WriteString(ui.ErrorStyle.Render("✗ Failed to load builds: " + m.err.Error()))
+		content.WriteString("\n")
+	case m.loading:
+		content.WriteString(fmt.Sprintf("%s Loading builds...\n", m.spinner.View()))
+	case len(m.builds) == 0:
+		content.WriteString(ui.SubtleStyle.Render("No builds"))
+		content.WriteString("\n")
+	case m.scanPaused():
+		content.WriteString(ui.SubtleStyle.Render(fmt.Sprintf("No match in the last %d builds loaded; [m] Search older builds", filterScanPages*pageSize)))
+		content.WriteString("\n")
+	case len(m.rows) == 0:
+		content.WriteString(ui.SubtleStyle.Render("No matching builds"))
+		content.WriteString("\n")
+	}
+
+	if m.promotion != nil && m.processesLoaded {
+		content.WriteString("\n")
+		content.WriteString(ui.HighlightStyle.Render(fmt.Sprintf("Promote #%d with:", m.promotion.build)))
+		content.WriteString("\n")
+		for i, process := range m.processes {
+			line := "  " + process
+			if i == m.promotion.cursor {
+				line = ui.SelectedStyle.Render("> " + process)
+			}
+			content.WriteString(line)
+			content.WriteString("\n")
+		}
+		content.WriteString("\n")
+		if m.promotion.confirming {
+			content.WriteString(ui.ErrorStyle.Render(m.promotion.prompt.View()))
+		} else {
+			content.WriteString(ui.SubtleStyle.Render("Skips the process conditions  [Enter] Promote  [Esc] Cancel"))
+		}
+	} else {
+		if m.notice != "" {
+			style := ui.SubtleStyle
+			if m.noticeIsErr {
+				style = ui.ErrorStyle
+			}
+			content.WriteString(style.Render(m.notice))
+			content.WriteString("\n")
+		}
+		content.WriteString("\n")
+		help := "[j/k] Move  [Enter] Logs  [/] Filter  [P] Promote  [r] Reload  [Esc] Close"
+		switch {
+		case m.filter.Focused():
+			help = "Matches names, parameters (NAME=value) and causes  [Enter] Done  [Esc] Clear"
+		case m.filter.Value() != "":
+			help = "[j/k] Move  [Enter] Logs  [/] Filter  [P] Promote  [Esc] Clear filter"
+		case m.err != nil:
+			help = "[j/k] Move  [Enter] Logs  [r] Retry  [Esc] Close"
+		}
+		content.WriteString(ui.SubtleStyle.Render(help))
+	}
+
+	panel := lipgloss.NewStyle().
+		Width(m.modalWidth()).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(content.String())
+
+	if m.width == 0 || m.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
+}
+
+// countLabel summarizes how much of the history is loaded, e.g. "25 of 340
+// builds", and how much of it matches the filter.
+func (m *Model) countLabel() string {
+	loaded := len(m.builds)
+	if m.filtering() {
+		if m.exhausted {
+			return fmt.Sprintf("%d of %d builds match", len(m.rows), loaded)
+		}
+		return fmt.Sprintf("%d of %d loaded builds match", len(m.rows), loaded)
+	}
+	switch {
+	case m.exhausted:
+		return fmt.Sprintf("%d builds", loaded)
+	case m.total > 0:
+		return fmt.Sprintf("%d of %d builds", loaded, m.total)
+	default:
+		return fmt.Sprintf("%d builds loaded", loaded)
+	}
+}
+
+// renderBuild renders one history row; builds user started get a badge.
+func renderBuild(build *jenkins.Build, marker, user string) string {
+	status := build.GetStatus()
+	icon := ui.GetStatusStyle(status).Render(ui.GetStatusIcon(status))
+	line := fmt.Sprintf("%s #%-6d %-9s %8s  %s",
+		icon,
+		build.Number,
+		status,
+		utils.FormatDuration(build.GetDuration()),
+		ui.SubtleStyle.Render(utils.FormatRelativeTime(build.GetTimestamp())),
+	)
+	if build.IsTriggeredBy(user) {
+		line += "  " + ui.MeBadge()
+	}
+	if promotions := build.Promotions(marker); len(promotions) > 0 {
+		line += "  " + ui.HighlightStyle.Render(ui.IconPromoted+" "+strings.Join(promotions, ", "))
+	}
+	return line
+}
+
+func (m *Model) visibleRows() int {
+	if m.height == 0 {
+		return pageSize
+	}
+	rows := m.height - modalChromeRow - 4
+	if rows < 3 {
+		rows = 3
+	}
+	return rows
+}
+
+func (m *Model) modalWidth() int {
+	width := m.width - 10
+	if width > maxModalWidth {
+		width = maxModalWidth
+	}
+	if width < minModalWidth {
+		width = minModalWidth
+	}
+	return width
+}
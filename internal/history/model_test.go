@@ -0,0 +1,221 @@
+package history
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+func page(from, n int) []jenkins.Build {
+	builds := make([]jenkins.Build, n)
+	for i := range builds {
+		builds[i] = jenkins.Build{Number: from - i}
+	}
+	return builds
+}
+
+func TestScrollingNearTheEndFetchesTheNextPage(t *testing.T) {
+	m := New(nil, jenkins.Job{FullName: "Team/api"}, "")
+	m.Init()
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 60})
+	m.Update(pageFetchedMsg{offset: 0, builds: page(100, pageSize)})
+	if m.loading {
+		t.Fatal("loading after the first page, want idle until the cursor nears the end")
+	}
+
+	down := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}
+	for i := 0; i < pageSize-prefetchRows-1; i++ {
+		m.Update(down)
+	}
+	if m.loading {
+		t.Fatalf("loading with the cursor at %d, want idle", m.cursor)
+	}
+	if _, cmd := m.Update(down); cmd == nil || !m.loading {
+		t.Fatalf("cursor at %d of %d did not fetch the next page", m.cursor, len(m.builds))
+	}
+
+	// A stale page (e.g. a retried offset) is dropped.
+	m.Update(pageFetchedMsg{offset: 0, builds: page(100, pageSize)})
+	if len(m.builds) != pageSize {
+		t.Fatalf("stale page appended, have %d builds", len(m.builds))
+	}
+
+	m.Update(pageFetchedMsg{offset: pageSize, builds: page(75, 3)})
+	if len(m.builds) != pageSize+3 || !m.exhausted {
+		t.Fatalf("after a short page: %d builds, exhausted = %v", len(m.builds), m.exhausted)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if m.loading {
+		t.Error("fetching past the last build")
+	}
+	if got := m.builds[m.cursor].Number; got != 73 {
+		t.Errorf("G moved to #%d, want #73", got)
+	}
+}
+
+func TestCountLabel(t *testing.T) {
+	m := New(nil, jenkins.Job{FullName: "Team/api"}, "")
+	m.builds = page(100, pageSize)
+	if got := m.countLabel(); got != "25 builds loaded" {
+		t.Errorf("countLabel() = %q without a total", got)
+	}
+	m.total = 340
+	if got := m.countLabel(); got != "25 of 340 builds" {
+		t.Errorf("countLabel() = %q with a total", got)
+	}
+}
+
+func TestFilterMatchesParametersAndCauses(t *testing.T) {
+	m := New(nil, jenkins.Job{FullName: "Team/api"}, "")
+	m.Init()
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 60})
+
+	builds := page(30, pageSize)
+	builds[3].Actions = []jenkins.BuildAction{{Parameters: []jenkins.BuildParameter{{Name: "VERSION", Value: "2.4.1"}}}}
+	builds[7].Actions = []jenkins.BuildAction{
+		{Parameters: []jenkins.BuildParameter{{Name: "VERSION", Value: "2.4.1"}}},
+		{Causes: []jenkins.BuildCause{{ShortDescription: "Started by user Alice", UserID: "alice"}}},
+	}
+	builds[9].DisplayName = "release-2.4.1"
+	m.Update(pageFetchedMsg{offset: 0, builds: builds})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if !m.CapturesInput() {
+		t.Fatal("/ did not focus the filter")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("version=2.4.1")})
+	if len(m.rows) != 2 || m.selected().Number != 27 {
+		t.Fatalf("rows = %v, want the builds with VERSION=2.4.1", m.rows)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" alice")})
+	if len(m.rows) != 1 || m.selected().Number != 23 {
+		t.Fatalf("rows = %v, want the build Alice started", m.rows)
+	}
+
+	// Few matches keep older pages coming.
+	if !m.loading {
+		t.Error("not fetching more builds with fewer matches than rows")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.CapturesInput() {
+		t.Fatal("Enter left the filter focused")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if len(m.rows) != pageSize || m.selected().Number != 23 {
+		t.Errorf("Esc left %d rows on #%d, want the filter cleared and the build kept", len(m.rows), m.selected().Number)
+	}
+}
+
+func TestFilterWithoutMatchesStopsPaging(t *testing.T) {
+	m := New(nil, jenkins.Job{FullName: "Team/api"}, "")
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 60})
+	m.Update(pageFetchedMsg{offset: 0, builds: page(1000, pageSize)})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("VERSION=9.9.9")})
+
+	// Each page without a match fetches the next, up to filterScanPages.
+	for pages := 0; m.loading; pages++ {
+		if pages == filterScanPages {
+			t.Fatalf("still paging after %d pages without a match", pages)
+		}
+		m.Update(pageFetchedMsg{offset: len(m.builds), builds: page(1000-len(m.builds), pageSize)})
+	}
+	if !m.scanPaused() || !strings.Contains(m.View(), "[m] Search older builds") {
+		t.Fatalf("paging stopped without offering to go on:\n%s", m.View())
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if !m.loading {
+		t.Fatal("m did not fetch older builds")
+	}
+	for m.loading {
+		m.Update(pageFetchedMsg{offset: len(m.builds), builds: page(1000-len(m.builds), pageSize)})
+	}
+	// The first page, the pages the filter searched, and as many after m.
+	if want := (1 + 2*filterScanPages) * pageSize; len(m.builds) != want {
+		t.Errorf("loaded %d builds, want %d", len(m.builds), want)
+	}
+
+	// A new filter gets its own pages.
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("0")})
+	if m.scanPaused() {
+		t.Error("a changed filter is still paused")
+	}
+}
+
+func TestForcedPromotionAsksForTheJobName(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetPromotionProcessesFunc: func(ctx context.Context, fullName string) ([]string, error) {
+			return []string{"QA", "Release"}, nil
+		},
+	}
+	m := New(client, jenkins.Job{Name: "api", FullName: "Team/api"}, "")
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 60})
+	m.Update(pageFetchedMsg{offset: 0, builds: page(42, 3)})
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("P")})
+	if cmd == nil {
+		t.Fatal("P did not fetch the promotion processes")
+	}
+	m.Update(cmd())
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Fatal("Enter promoted without asking")
+	}
+	if !m.CapturesInput() {
+		t.Fatal("the confirmation does not capture typing")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("web")})
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Fatal("a wrong name promoted the build")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.promotion == nil || m.promotion.confirming {
+		t.Fatal("Esc did not return to the chooser")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("api")})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("typing the job name did not promote")
+	}
+	m.Update(cmd())
+	calls := client.CallsTo("PromoteBuild")
+	if len(calls) != 1 || calls[0].Args[1] != 42 || calls[0].Args[2] != "Release" {
+		t.Errorf("PromoteBuild calls = %v, want #42 with Release once", calls)
+	}
+	if m.promotion != nil {
+		t.Error("the chooser stayed open after promoting")
+	}
+}
+
+func TestPromotionWithoutConfirmation(t *testing.T) {
+	policy, err := confirm.Config{Promote: "none"}.Policy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &jenkinstest.Client{}
+	m := New(client, jenkins.Job{Name: "api", FullName: "Team/api"}, "").WithConfirmPolicy(policy)
+	m.Update(pageFetchedMsg{offset: 0, builds: page(42, 3)})
+	m.Update(processesFetchedMsg{processes: []string{"QA"}})
+	m.promotion = &promotion{build: 42}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("Enter did not promote with the none level")
+	}
+	cmd()
+	if calls := client.CallsTo("PromoteBuild"); len(calls) != 1 {
+		t.Errorf("PromoteBuild calls = %v, want one", calls)
+	}
+}
@@ -0,0 +1,136 @@
+// Package inflight tracks the changes the user asked Jenkins to make (build
+// triggers, aborts, ...) until Jenkins answers, so quitting can wait for them
+// or at least say which ones were left unconfirmed.
+package inflight
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// maxOutcomes caps the outcomes kept for the exit summary.
+const maxOutcomes = 20
+
+// Tracker records in-flight actions. It is safe for concurrent use; a nil
+// Tracker tracks nothing.
+type Tracker struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	next    int
+	pending map[int]string
+	// draining is set once the user chose to wait for pending actions;
+	// from then on their outcomes are kept for the exit summary.
+	draining bool
+	outcomes []string
+}
+
+// New creates an empty tracker.
+func New() *Tracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Tracker{ctx: ctx, cancel: cancel, pending: make(map[int]string)}
+}
+
+// Start records an action described by label, e.g. "Trigger a build of api".
+// The returned context is cancelled by Abandon; done must be called with the
+// action's result once Jenkins answered.
+func (t *Tracker) Start(label string) (context.Context, func(error)) {
+	if t == nil {
+		return context.Background(), func(error) {}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.next++
+	id := t.next
+	t.pending[id] = label
+
+	var once sync.Once
+	return t.ctx, func(err error) {
+		once.Do(func() { t.finish(id, err) })
+	}
+}
+
+func (t *Tracker) finish(id int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	label, ok := t.pending[id]
+	if !ok {
+		return
+	}
+	delete(t.pending, id)
+	if !t.draining {
+		return
+	}
+	if err != nil {
+		t.record(fmt.Sprintf("✗ %s: %v", label, err))
+		return
+	}
+	t.record(fmt.Sprintf("✓ %s: confirmed by Jenkins", label))
+}
+
+// Pending returns the labels of the actions still waiting for Jenkins, oldest first.
+func (t *Tracker) Pending() []string {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]int, 0, len(t.pending))
+	for id := range t.pending {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	labels := make([]string, len(ids))
+	for i, id := range ids {
+		labels[i] = t.pending[id]
+	}
+	return labels
+}
+
+// Drain starts keeping the outcomes of the pending actions for Summary.
+func (t *Tracker) Drain() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+}
+
+// Abandon cancels the pending actions' requests and notes them in the
+// summary; Jenkins may still have received and carried them out.
+func (t *Tracker) Abandon() {
+	if t == nil {
+		return
+	}
+	pending := t.Pending()
+	t.mu.Lock()
+	for _, label := range pending {
+		t.record(fmt.Sprintf("? %s: quit before Jenkins answered; it may still happen", label))
+	}
+	t.pending = make(map[int]string)
+	t.mu.Unlock()
+	t.cancel()
+}
+
+// Summary returns what became of the actions that were pending at quit,
+// one line each, for printing once the dashboard has exited.
+func (t *Tracker) Summary() []string {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.outcomes...)
+}
+
+// record keeps an outcome line. The caller holds the lock.
+func (t *Tracker) record(line string) {
+	if len(t.outcomes) >= maxOutcomes {
+		return
+	}
+	t.outcomes = append(t.outcomes, line)
+}
@@ -0,0 +1,52 @@
+package inflight
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestTrackerDrainAndAbandon(t *testing.T) {
+	tracker := New()
+	_, doneBefore := tracker.Start("Disable api")
+	doneBefore(nil)
+
+	_, doneTrigger := tracker.Start("Trigger a build of api")
+	_, doneAbort := tracker.Start("Abort web #7")
+	abortCtx, doneDelete := tracker.Start("Delete old-job")
+	if got := tracker.Pending(); !slices.Equal(got, []string{"Trigger a build of api", "Abort web #7", "Delete old-job"}) {
+		t.Fatalf("Pending() = %v", got)
+	}
+
+	tracker.Drain()
+	doneTrigger(nil)
+	doneTrigger(nil) // repeated calls are ignored
+	doneAbort(errors.New("status 404"))
+	tracker.Abandon()
+	if abortCtx.Err() == nil {
+		t.Error("Abandon() did not cancel the pending request")
+	}
+	doneDelete(nil) // answered after the quit; too late to report
+
+	want := []string{
+		"✓ Trigger a build of api: confirmed by Jenkins",
+		"✗ Abort web #7: status 404",
+		"? Delete old-job: quit before Jenkins answered; it may still happen",
+	}
+	if got := tracker.Summary(); !slices.Equal(got, want) {
+		t.Errorf("Summary() =\n%q\nwant\n%q", got, want)
+	}
+	if len(tracker.Pending()) != 0 {
+		t.Errorf("Pending() = %v after Abandon", tracker.Pending())
+	}
+}
+
+func TestNilTracker(t *testing.T) {
+	var tracker *Tracker
+	ctx, done := tracker.Start("Trigger a build of api")
+	done(nil)
+	tracker.Abandon()
+	if ctx.Err() != nil || tracker.Pending() != nil || tracker.Summary() != nil {
+		t.Error("a nil tracker should track nothing")
+	}
+}
@@ -1,48 +1,214 @@
 package jenkins
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
+	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins/tree"
 )
 
 // JenkinsClient defines the interface for interacting with Jenkins API
 type JenkinsClient interface {
 	// TestConnection tests the connection to Jenkins server
-	TestConnection() error
+	TestConnection(ctx context.Context) error
+
+	// GetInfo gets basic Jenkins information from the root API
+	GetInfo(ctx context.Context) (map[string]interface{}, error)
 
 	// GetAllJobs fetches all jobs from Jenkins, including nested jobs in folders
-	GetAllJobs() ([]Job, error)
+	GetAllJobs(ctx context.Context) ([]Job, error)
+
+	// GetFolderJobs fetches the direct children of a folder ("" for the top level)
+	GetFolderJobs(ctx context.Context, folderFullName string) ([]Job, error)
 
 	// GetJobDetails fetches detailed information about a specific job, including recent builds
-	GetJobDetails(fullName string, limit int) (*JobDetails, error)
+	GetJobDetails(ctx context.Context, fullName string, limit int) (*JobDetails, error)
+
+	// GetBuilds fetches a page of a job's build history, newest first
+	GetBuilds(ctx context.Context, fullName string, offset, limit int) ([]Build, error)
+
+	// GetBuildCount returns how many builds of a job are kept
+	GetBuildCount(ctx context.Context, fullName string) (int, error)
 
 	// GetBuildQueue fetches the current build queue from Jenkins
-	GetBuildQueue() ([]QueueItem, error)
+	GetBuildQueue(ctx context.Context) ([]QueueItem, error)
 
 	// GetRunningBuilds fetches currently executing builds from all Jenkins executors
-	GetRunningBuilds() ([]RunningBuild, error)
+	GetRunningBuilds(ctx context.Context) ([]RunningBuild, error)
+
+	// GetNodes lists the built-in node and agents with their executors and monitor data
+	GetNodes(ctx context.Context) ([]Computer, error)
+
+	// GetNode fetches a single node by name
+	GetNode(ctx context.Context, name string) (*Computer, error)
+
+	// SetNodeOffline takes a node offline, or updates the reason if it already is
+	SetNodeOffline(ctx context.Context, name, reason string) error
+
+	// SetNodeOnline brings a node taken offline by hand back online
+	SetNodeOnline(ctx context.Context, name string) error
+
+	// GetOverallLoad fetches executor and queue statistics across all nodes
+	GetOverallLoad(ctx context.Context) (*LoadStatistics, error)
+
+	// GetLabelLoad fetches executor and queue statistics for the nodes of one label
+	GetLabelLoad(ctx context.Context, label string) (*LabelLoad, error)
+
+	// GetInstalledPlugins lists the installed plugins with their version and update state
+	GetInstalledPlugins(ctx context.Context) ([]Plugin, error)
+
+	// GetFingerprint looks up which builds produced and used a file by its MD5 checksum
+	GetFingerprint(ctx context.Context, md5 string) (*Fingerprint, error)
+
+	// GetCredentials lists the IDs, types and descriptions of a system credentials domain, never secrets
+	GetCredentials(ctx context.Context, domain string) ([]Credential, error)
+
+	// GetCredentialChoices lists the credentials of a type that a job's credentials parameters may take
+	GetCredentialChoices(ctx context.Context, fullName, credentialType string) ([]Credential, error)
+
+	// TriggerBuild requests a new build for the specified job and returns its queue item ID
+	TriggerBuild(ctx context.Context, fullName string) (int, error)
 
-	// TriggerBuild requests a new build for the specified job
-	TriggerBuild(fullName string) error
+	// TriggerBuildWithParameters requests a new build providing parameter values and returns its queue item ID
+	TriggerBuildWithParameters(ctx context.Context, fullName string, params map[string]string) (int, error)
 
-	// TriggerBuildWithParameters requests a new build providing parameter values
-	TriggerBuildWithParameters(fullName string, params map[string]string) error
+	// CanBuild reports whether the current user may build a job
+	CanBuild(ctx context.Context, fullName string) (bool, error)
+
+	// ScanMultibranch starts branch indexing of a multibranch project
+	ScanMultibranch(ctx context.Context, fullName string) error
+
+	// GetQueueItem fetches a single queue item, including items that already left the queue
+	GetQueueItem(ctx context.Context, id int) (*QueueItem, error)
+
+	// WaitForBuildNumber polls a queue item until it starts and returns the build number
+	WaitForBuildNumber(ctx context.Context, queueID int) (int, error)
 
 	// AbortBuild sends a stop signal to a running build
-	AbortBuild(fullName string, buildNumber int) error
+	AbortBuild(ctx context.Context, fullName string, buildNumber int) error
+
+	// GetReplayScript fetches the main Pipeline script a build ran with, as offered for replay
+	GetReplayScript(ctx context.Context, fullName string, buildNumber int) (string, error)
+
+	// ReplayBuild reruns a Pipeline build, with a modified main script if one is given and the loaded scripts as they ran
+	ReplayBuild(ctx context.Context, fullName string, buildNumber int, script string) error
+	// GetPromotionProcesses lists the promotion processes of a job (promoted-builds plugin)
+	GetPromotionProcesses(ctx context.Context, fullName string) ([]string, error)
+
+	// PromoteBuild starts a promotion process for a build, skipping its conditions
+	PromoteBuild(ctx context.Context, fullName string, buildNumber int, process string) error
+
+	// SetBuildDescription replaces the description of a build
+	SetBuildDescription(ctx context.Context, fullName string, buildNumber int, description string) error
+	// SetBuildDisplayName renames a build and sets its description in one save
+	SetBuildDisplayName(ctx context.Context, fullName string, buildNumber int, displayName, description string) error
+
+	// CancelQueueItem removes a waiting item from the build queue
+	CancelQueueItem(ctx context.Context, id int) error
+
+	// EnableJob lets a disabled job build again
+	EnableJob(ctx context.Context, fullName string) error
+
+	// DisableJob stops a job from building until it is enabled again
+	DisableJob(ctx context.Context, fullName string) error
+
+	// CreateJob creates a job from a config.xml; folders in fullName must exist
+	CreateJob(ctx context.Context, fullName, configXML string) error
+
+	// CopyJob creates a new job with the configuration of an existing one
+	CopyJob(ctx context.Context, fromFullName, toFullName string) error
+
+	// DeleteJob permanently deletes a job or folder with all its builds
+	DeleteJob(ctx context.Context, fullName string) error
 
 	// GetBuild fetches build details for the given job
-	GetBuild(fullName string, number int) (*Build, error)
+	GetBuild(ctx context.Context, fullName string, number int) (*Build, error)
+
+	// GetLastSuccessfulBuild fetches a job's newest successful build, or nil if it never succeeded
+	GetLastSuccessfulBuild(ctx context.Context, fullName string) (*Build, error)
+
+	// GetLastFailedBuild fetches a job's newest failed build, or nil if it never failed
+	GetLastFailedBuild(ctx context.Context, fullName string) (*Build, error)
+
+	// GetLastStableBuild fetches a job's newest stable build, or nil if it never was stable
+	GetLastStableBuild(ctx context.Context, fullName string) (*Build, error)
+
+	// GetPipelineRuns fetches recent runs of a Pipeline job with their stages
+	GetPipelineRuns(ctx context.Context, fullName string) ([]PipelineRun, error)
+
+	// GetPipelineRunStages fetches the stages of one Pipeline build
+	GetPipelineRunStages(ctx context.Context, fullName string, buildNumber int) (*PipelineRun, error)
+
+	// GetPipelineGraph fetches a Pipeline build's stage graph from Blue Ocean, falling back to wfapi
+	GetPipelineGraph(ctx context.Context, fullName string, buildNumber int) (*PipelineGraph, error)
+
+	// GetPipelineSteps fetches the steps of one Blue Ocean pipeline node
+	GetPipelineSteps(ctx context.Context, fullName string, buildNumber int, nodeID string) ([]PipelineStep, error)
+
+	// GetPipelineStepLog fetches the log of a single Blue Ocean pipeline step
+	GetPipelineStepLog(ctx context.Context, fullName string, buildNumber int, nodeID, stepID string) (string, error)
+
+	// GetConsoleLog fetches the full console output for a specific build
+	GetConsoleLog(ctx context.Context, fullName string, buildNumber int) (string, error)
+
+	// GetTestReport fetches a build's JUnit test report; nil when the build has none
+	GetTestReport(ctx context.Context, fullName string, buildNumber int) (*TestReport, error)
+
+	// GetArtifacts lists the files archived by a build
+	GetArtifacts(ctx context.Context, fullName string, buildNumber int) ([]Artifact, error)
+
+	// DownloadArtifact streams an archived file to destPath, reporting progress
+	DownloadArtifact(ctx context.Context, fullName string, buildNumber int, relativePath, destPath string, progress ProgressFunc) error
+
+	// GetConsoleLogSize returns the size of a build's console log in bytes
+	GetConsoleLogSize(ctx context.Context, fullName string, buildNumber int) (int64, error)
 
 	// GetProgressiveLog fetches a chunk of console output using Jenkins' progressive log API
-	GetProgressiveLog(buildURL, fullName string, buildNumber int, start int64) (string, int64, bool, error)
+	GetProgressiveLog(ctx context.Context, buildURL, fullName string, buildNumber int, start int64) (string, int64, bool, error)
+
+	// GetAnnotatedLog fetches a chunk of a Pipeline build's console output attributed to the flow nodes that wrote it
+	GetAnnotatedLog(ctx context.Context, buildURL, fullName string, buildNumber int, start int64) (AnnotatedLog, error)
+
+	// GetJobConfig retrieves the raw job configuration (config.xml)
+	GetJobConfig(ctx context.Context, fullName string) (string, error)
+
+	// UpdateJobConfig replaces a job's configuration (config.xml)
+	UpdateJobConfig(ctx context.Context, fullName, configXML string) error
+
+	// GetFolderRelations fetches upstream/downstream relationships for the jobs directly inside a folder
+	GetFolderRelations(ctx context.Context, folderFullName string) ([]JobRelations, error)
+
+	// GetUser resolves a Jenkins user ID to its profile (cached per client)
+	GetUser(ctx context.Context, id string) (*User, error)
+
+	// GenerateAPIToken creates a new API token for the authenticated user
+	GenerateAPIToken(ctx context.Context, name string) (*APIToken, error)
+
+	// RevokeAPIToken revokes one of the authenticated user's API tokens by UUID
+	RevokeAPIToken(ctx context.Context, uuid string) error
+
+	// SetToken switches the API token used for subsequent requests
+	SetToken(token string)
+
+	// SetRequestObserver reports every request to observer; nil stops reporting
+	SetRequestObserver(observer RequestObserver)
 }
 
 // Client represents a Jenkins API client
@@ -52,9 +218,56 @@ type Client struct {
 	Token      string
 	HTTPClient *http.Client
 
+	// treeDepth is how many levels of jobs GetAllJobs fetches.
+	treeDepth int
+	// readOnly refuses every request that would change the server.
+	readOnly bool
+
+	// tokenMu guards Token, which changes when the token is rotated from the TUI.
+	tokenMu sync.RWMutex
+
 	crumb         *Crumb
 	crumbDisabled bool
 	crumbMu       sync.Mutex
+
+	users   map[string]*User
+	usersMu sync.Mutex
+
+	// conditional remembers validators and decoded bodies of large polled
+	// endpoints so unchanged refreshes are answered with 304 Not Modified.
+	conditional   map[string]*conditionalEntry
+	conditionalMu sync.Mutex
+
+	// blueOceanMissing is set once the Blue Ocean REST API turned out not to
+	// be installed, so pipeline graphs go straight to wfapi from then on.
+	// blueOceanFound is set once it answered, so a 404 for one run (e.g. a
+	// job Blue Ocean doesn't index) doesn't need checking again.
+	blueOceanMissing atomic.Bool
+	blueOceanFound   atomic.Bool
+
+	// htmlLogs remembers, by build path, the builds whose logs are read from
+	// progressiveHtml: those whose progressiveText came back empty and those
+	// read with their annotations.
+	htmlLogs   map[string]htmlLogState
+	htmlLogsMu sync.Mutex
+
+	observer   RequestObserver
+	observerMu sync.RWMutex
+}
+
+// htmlLogState is where a build's progressiveHtml log was read up to and
+// the annotator state Jenkins asked to be sent back for the next chunk.
+type htmlLogState struct {
+	next      int64
+	annotator string
+}
+
+// conditionalEntry is a previously decoded response together with the
+// validators Jenkins sent for it.
+type conditionalEntry struct {
+	etag         string
+	lastModified string
+	value        any
 }
 
 // Credentials holds Jenkins authentication information
@@ -62,18 +275,114 @@ type Credentials struct {
 	URL      string
 	Username string
 	Token    string
+	// Proxy overrides the proxy environment variables for this server; see
+	// setProxy.
+	Proxy string
+	// TLS trusts extra CAs or presents a client certificate to HTTPS servers.
+	TLS TLSOptions
+	// RateLimit caps the requests per second sent to the server, allowing
+	// bursts of RateBurst; zero or negative sends them unthrottled.
+	RateLimit float64
+	RateBurst int
+	// TreeDepth is how many levels of jobs GetAllJobs fetches; zero or less
+	// means defaultTreeDepth.
+	TreeDepth int
+	// ReadOnly refuses to trigger, abort or change anything on the server,
+	// returning ErrReadOnly instead.
+	ReadOnly bool
+	// SSH, when set, triggers builds, reads consoles and checks the
+	// connection through the Jenkins SSH CLI instead of HTTP.
+	SSH *SSHOptions
 }
 
 // NewClient creates a new Jenkins client
 func NewClient(creds Credentials) JenkinsClient {
-	return &Client{
-		BaseURL:  creds.URL,
-		Username: creds.Username,
-		Token:    creds.Token,
-		HTTPClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+	// Crumbs are only valid in the web session that issued them, so the
+	// session cookie is kept. cookiejar.New fails only for bad options.
+	jar, _ := cookiejar.New(nil)
+	client := &Client{
+		BaseURL:   creds.URL,
+		Username:  creds.Username,
+		Token:     creds.Token,
+		treeDepth: creds.TreeDepth,
+		readOnly:  creds.ReadOnly,
+	}
+
+	// Observers see the exchange with Jenkins, not the wait for the rate limit.
+	var transport http.RoundTripper = &observedTransport{
+		next:     newTransport(creds),
+		client:   client,
+		basePath: basePath(creds.URL),
+	}
+	if creds.RateLimit > 0 {
+		transport = &rateLimitedTransport{next: transport, limiter: newRateLimiter(creds.RateLimit, creds.RateBurst)}
+	}
+	client.HTTPClient = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		Jar:       jar,
+	}
+	if creds.SSH != nil {
+		return newSSHClient(client, *creds.SSH)
+	}
+	return client
+}
+
+// basePath returns the path of a server URL without a trailing slash, e.g.
+// "/jenkins" for a Jenkins served below a context path.
+func basePath(serverURL string) string {
+	parsed, err := url.Parse(serverURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(parsed.Path, "/")
+}
+
+// buildFields selects the build fields shown in the details and history views.
+var buildFields = tree.Must(tree.Fields("number", "displayName", "description", "result", "duration", "timestamp", "building", "url").Nested(
+	tree.New("actions").Fields("_class").Nested(
+		tree.New("causes").Fields("shortDescription", "userId", "userName"),
+		tree.New("parameters").Fields("name", "value"),
+		tree.New("lastBuiltRevision").Nested(tree.New("branch").Fields("SHA1", "name")),
+	).Fields("totalCount", "failCount", "skipCount").Nested(
+		tree.New("promotions").Fields("name"),
+	),
+	changeSetFields.As("changeSet"),
+	changeSetFields.As("changeSets"),
+))
+
+// changeSetFields selects the commits of a change set. Freestyle builds
+// report a single changeSet, Pipeline builds a changeSets list.
+var changeSetFields = tree.Fields("kind").Nested(
+	tree.New("items").Fields("commitId", "msg").Nested(
+		tree.New("author").Fields("fullName"),
+	).Fields("timestamp", "affectedPaths"),
+)
+
+// jobListFields selects a job as listed in its folder, with its last build.
+var jobListFields = tree.Fields("name", "fullName", "url", "color", "_class", "disabled").Nested(
+	tree.New("lastBuild").Fields("number", "result", "duration", "timestamp", "building", "url"),
+	healthReportFields,
+)
+
+// healthReportFields selects a job's weather.
+var healthReportFields = tree.New("healthReport").Fields("score", "description")
+
+// defaultTreeDepth is how many levels of jobs GetAllJobs fetches unless
+// configured otherwise, enough for most folder layouts without a request per
+// folder.
+const defaultTreeDepth = 3
+
+// allJobsTree selects depth levels of jobs, or defaultTreeDepth for zero.
+func allJobsTree(depth int) tree.Node {
+	if depth <= 0 {
+		depth = defaultTreeDepth
 	}
+	node := jobListFields.As("jobs")
+	for i := 1; i < depth; i++ {
+		node = jobListFields.As("jobs").Nested(node)
+	}
+	return tree.Must(node)
 }
 
 // Crumb represents a Jenkins CSRF token
@@ -82,16 +391,49 @@ type Crumb struct {
 	Crumb             string `json:"crumb"`
 }
 
-// doRequest performs an HTTP request with basic auth
-func (c *Client) doRequest(method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+// ErrUnauthorized is returned, wrapped, for requests Jenkins rejects because
+// the username or API token is wrong.
+var ErrUnauthorized = errors.New("authentication failed; check the username and API token")
+
+// ErrReadOnly is returned, wrapped, for requests that would change a server
+// configured as read-only.
+var ErrReadOnly = errors.New("the server is configured read-only")
+
+// doRequest performs an HTTP request with basic auth. A 401 response is
+// turned into ErrUnauthorized so callers can tell bad credentials apart.
+// Mutating requests rejected for a stale crumb are retried once with a new one.
+func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, path, body, headers)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if requiresCrumb(method) && resp.StatusCode == http.StatusForbidden {
+		resp, err = c.retryWithFreshCrumb(ctx, path, headers, req, resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		return nil, ErrUnauthorized
+	}
+	return resp, nil
+}
+
+// newRequest builds an authenticated request, attaching a crumb for mutating methods.
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader, headers map[string]string) (*http.Request, error) {
 	url := c.BaseURL + path
-	req, err := http.NewRequest(method, url, body)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
 	// Set basic auth
-	req.SetBasicAuth(c.Username, c.Token)
+	req.SetBasicAuth(c.Username, c.currentToken())
 
 	// Apply default headers
 	if headers == nil || headers["Accept"] == "" {
@@ -106,15 +448,79 @@ func (c *Client) doRequest(method, path string, body io.Reader, headers map[stri
 
 	// Attach crumb for mutating requests
 	if requiresCrumb(method) {
-		if err := c.ensureCrumb(); err != nil {
+		if c.readOnly {
+			return nil, ErrReadOnly
+		}
+		crumb, err := c.ensureCrumb(ctx)
+		if err != nil {
 			return nil, err
 		}
-		if c.crumb != nil {
-			req.Header.Set(c.crumb.CrumbRequestField, c.crumb.Crumb)
+		if crumb != nil {
+			req.Header.Set(crumb.CrumbRequestField, crumb.Crumb)
+		}
+	}
+
+	return req, nil
+}
+
+// crumbRejection is the message of the 403 Jenkins answers when a crumb is
+// missing or no longer valid, e.g. after the web session it belongs to expired.
+const crumbRejection = "No valid crumb"
+
+// retryWithFreshCrumb resends req with a newly issued crumb when resp is a
+// crumb rejection, so long sessions survive an expired crumb. Other 403s, and
+// requests whose body cannot be replayed, are returned as they are.
+func (c *Client) retryWithFreshCrumb(ctx context.Context, path string, headers map[string]string, req *http.Request, resp *http.Response) (*http.Response, error) {
+	page, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	// Callers still read the body for their error messages.
+	resp.Body = io.NopCloser(bytes.NewReader(page))
+	if err != nil || !bytes.Contains(page, []byte(crumbRejection)) {
+		return resp, nil
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	c.invalidateCrumb()
+	var body io.Reader
+	if req.GetBody != nil {
+		replay, err := req.GetBody()
+		if err != nil {
+			return resp, nil
 		}
+		body = replay
+	}
+	retry, err := c.newRequest(ctx, req.Method, path, body, headers)
+	if err != nil {
+		return nil, err
 	}
+	return c.HTTPClient.Do(retry)
+}
+
+// invalidateCrumb drops the cached crumb so the next mutating request fetches
+// a new one, and asks the issuer again even if it was unavailable before.
+func (c *Client) invalidateCrumb() {
+	c.crumbMu.Lock()
+	c.crumb = nil
+	c.crumbDisabled = false
+	c.crumbMu.Unlock()
+}
+
+func (c *Client) currentToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.Token
+}
+
+// SetToken switches the API token used for subsequent requests. Crumbs are
+// bound to the authenticated session, so the cached one is dropped.
+func (c *Client) SetToken(token string) {
+	c.tokenMu.Lock()
+	c.Token = token
+	c.tokenMu.Unlock()
 
-	return c.HTTPClient.Do(req)
+	c.invalidateCrumb()
 }
 
 func requiresCrumb(method string) bool {
@@ -126,24 +532,31 @@ func requiresCrumb(method string) bool {
 	}
 }
 
-func (c *Client) ensureCrumb() error {
+// ensureCrumb returns a copy of the cached crumb, fetching one first when
+// needed. It returns nil when the server does not use crumbs. The copy is
+// taken under crumbMu so a concurrent invalidateCrumb cannot change it.
+func (c *Client) ensureCrumb(ctx context.Context) (*Crumb, error) {
 	c.crumbMu.Lock()
 	defer c.crumbMu.Unlock()
 
-	if c.crumb != nil || c.crumbDisabled {
-		return nil
+	if c.crumbDisabled {
+		return nil, nil
+	}
+	if c.crumb != nil {
+		crumb := *c.crumb
+		return &crumb, nil
 	}
 
-	req, err := http.NewRequest(http.MethodGet, c.BaseURL+"/crumbIssuer/api/json", nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/crumbIssuer/api/json", nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req.SetBasicAuth(c.Username, c.Token)
+	req.SetBasicAuth(c.Username, c.currentToken())
 	req.Header.Set("Accept", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to request crumb: %w", err)
+		return nil, fmt.Errorf("failed to request crumb: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -151,30 +564,44 @@ func (c *Client) ensureCrumb() error {
 	case http.StatusOK:
 		var crumb Crumb
 		if err := json.NewDecoder(resp.Body).Decode(&crumb); err != nil {
-			return fmt.Errorf("failed to decode crumb: %w", err)
+			return nil, fmt.Errorf("failed to decode crumb: %w", err)
 		}
 		if crumb.CrumbRequestField == "" || crumb.Crumb == "" {
-			return fmt.Errorf("received empty crumb from Jenkins")
+			return nil, fmt.Errorf("received empty crumb from Jenkins")
 		}
-		c.crumb = &crumb
-		return nil
+		cached := crumb
+		c.crumb = &cached
+		return &crumb, nil
 
 	case http.StatusNotFound, http.StatusForbidden:
 		// Jenkins crumbs disabled or unsupported; continue without them.
 		c.crumbDisabled = true
-		return nil
+		return nil, nil
 
 	default:
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to fetch crumb: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to fetch crumb: status %d, body: %s", resp.StatusCode, string(body))
 	}
 }
 
 // TestConnection tests the connection to Jenkins server
 // Returns nil if successful, error otherwise
-func (c *Client) TestConnection() error {
-	resp, err := c.doRequest(http.MethodGet, "/api/json", nil, nil)
+func (c *Client) TestConnection(ctx context.Context) error {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/json", nil, nil)
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if errors.Is(err, ErrUnauthorized) {
+			return fmt.Errorf("authentication failed. Please check your username and token")
+		}
+		if errors.Is(err, ErrInvalidProxy) || errors.Is(err, ErrInvalidTLSConfig) {
+			return err
+		}
+		var certErr *tls.CertificateVerificationError
+		if errors.As(err, &certErr) {
+			return fmt.Errorf("the server certificate is not trusted (%v). Set caCertFile in the server config to its CA bundle", certErr.Err)
+		}
 		// Check for common network errors
 		if err, ok := err.(interface{ Timeout() bool }); ok && err.Timeout() {
 			return fmt.Errorf("connection timeout. Jenkins server is not responding")
@@ -203,8 +630,8 @@ func (c *Client) TestConnection() error {
 }
 
 // GetInfo gets basic Jenkins information
-func (c *Client) GetInfo() (map[string]interface{}, error) {
-	resp, err := c.doRequest(http.MethodGet, "/api/json", nil, nil)
+func (c *Client) GetInfo(ctx context.Context) (map[string]interface{}, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, "/api/json", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -224,62 +651,153 @@ func (c *Client) GetInfo() (map[string]interface{}, error) {
 
 // GetAllJobs fetches all jobs from Jenkins, including nested jobs in folders
 // Uses the tree parameter to efficiently fetch nested structures in a single request
-func (c *Client) GetAllJobs() ([]Job, error) {
+func (c *Client) GetAllJobs(ctx context.Context) ([]Job, error) {
 	// Use tree parameter to fetch nested job structure efficiently
 	// This fetches job name, fullName, url, color, lastBuild details, and nested jobs
-	path := "/api/json?tree=jobs[name,fullName,url,color,_class,lastBuild[number,result,duration,timestamp,building,url],jobs[name,fullName,url,color,_class,lastBuild[number,result,duration,timestamp,building,url],jobs[name,fullName,url,color,_class,lastBuild[number,result,duration,timestamp,building,url]]]]"
+	path := "/api/json?tree=" + allJobsTree(c.treeDepth).String()
+
+	response, err := getConditional[JobsResponse](ctx, c, path, "jobs")
+	if err != nil {
+		return nil, err
+	}
+	return response.Jobs, nil
+}
 
-	resp, err := c.doRequest(http.MethodGet, path, nil, nil)
+// folderJobsTree selects the direct children of a folder.
+var folderJobsTree = tree.Must(jobListFields.As("jobs"))
+
+// GetFolderJobs fetches the direct children of a folder ("" for the top level)
+// without descending further, so deep hierarchies can be loaded one level at a
+// time as folders are expanded. Nested folders are returned with no Jobs.
+func (c *Client) GetFolderJobs(ctx context.Context, folderFullName string) ([]Job, error) {
+	basePath := ""
+	if strings.TrimSpace(folderFullName) != "" {
+		basePath = buildJobAPIPath(folderFullName)
+		if basePath == "" {
+			return nil, fmt.Errorf("invalid folder path for %q", folderFullName)
+		}
+	}
+
+	params := url.Values{}
+	params.Set("tree", folderJobsTree.String())
+	path := fmt.Sprintf("%s/api/json?%s", basePath, params.Encode())
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch jobs: %w", err)
+		return nil, fmt.Errorf("failed to fetch folder jobs: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to fetch jobs: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to fetch folder jobs: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
 	var response JobsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode jobs response: %w", err)
+		return nil, fmt.Errorf("failed to decode folder jobs: %w", err)
 	}
 
 	return response.Jobs, nil
 }
 
+// queueTree selects the queue items with their job, who or what enqueued
+// them and, once started, build.
+var queueTree = tree.Must(tree.New("items").Fields("id", "blocked", "buildable", "stuck", "why", "inQueueSince").Nested(
+	tree.New("task").Fields("name", "url", "color"),
+	tree.New("actions").Nested(tree.New("causes").Fields("shortDescription", "userId", "userName")),
+	tree.New("executable").Fields("number", "url"),
+))
+
 // GetBuildQueue fetches the current build queue from Jenkins
 // This includes both items waiting in queue and items currently executing
-func (c *Client) GetBuildQueue() ([]QueueItem, error) {
+func (c *Client) GetBuildQueue(ctx context.Context) ([]QueueItem, error) {
 	// Fetch queue with tree parameter to get all necessary fields
-	path := "/queue/api/json?tree=items[id,blocked,buildable,stuck,why,inQueueSince,task[name,url,color],executable[number,url]]"
+	path := "/queue/api/json?tree=" + queueTree.String()
+
+	response, err := getConditional[QueueResponse](ctx, c, path, "build queue")
+	if err != nil {
+		return nil, err
+	}
+	return response.Items, nil
+}
+
+// getConditional fetches and decodes path, revalidating a previous response
+// with If-None-Match/If-Modified-Since. A 304 returns the cached value without
+// transferring or parsing the body again. Callers must not mutate the result,
+// as it is shared with later refreshes.
+func getConditional[T any](ctx context.Context, c *Client, path, what string) (T, error) {
+	var zero T
+
+	c.conditionalMu.Lock()
+	cached := c.conditional[path]
+	c.conditionalMu.Unlock()
+
+	headers := map[string]string{}
+	if cached != nil {
+		if cached.etag != "" {
+			headers["If-None-Match"] = cached.etag
+		}
+		if cached.lastModified != "" {
+			headers["If-Modified-Since"] = cached.lastModified
+		}
+	}
 
-	resp, err := c.doRequest(http.MethodGet, path, nil, nil)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, headers)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch build queue: %w", err)
+		return zero, fmt.Errorf("failed to fetch %s: %w", what, err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		if value, ok := cached.value.(T); ok {
+			return value, nil
+		}
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to fetch build queue: status %d, body: %s", resp.StatusCode, string(body))
+		return zero, fmt.Errorf("failed to fetch %s: status %d, body: %s", what, resp.StatusCode, string(body))
 	}
 
-	var response QueueResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode queue response: %w", err)
+	var value T
+	if err := json.NewDecoder(resp.Body).Decode(&value); err != nil {
+		return zero, fmt.Errorf("failed to decode %s response: %w", what, err)
 	}
 
-	return response.Items, nil
+	entry := &conditionalEntry{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		value:        value,
+	}
+	c.conditionalMu.Lock()
+	if entry.etag == "" && entry.lastModified == "" {
+		delete(c.conditional, path)
+	} else {
+		if c.conditional == nil {
+			c.conditional = make(map[string]*conditionalEntry)
+		}
+		c.conditional[path] = entry
+	}
+	c.conditionalMu.Unlock()
+
+	return value, nil
 }
 
+// executorFields selects a node's executors and the builds running on them.
+var executorFields = tree.New("executors").Fields("idle").Nested(
+	tree.New("currentExecutable").Fields("fullDisplayName", "number", "url", "timestamp"),
+)
+
+// runningBuildsTree selects the executors of every node.
+var runningBuildsTree = tree.Must(tree.New("computer").Fields("displayName").Nested(executorFields))
+
 // GetRunningBuilds fetches currently executing builds from all Jenkins executors
 // This checks all nodes (master and agents) and their executors
-func (c *Client) GetRunningBuilds() ([]RunningBuild, error) {
+func (c *Client) GetRunningBuilds(ctx context.Context) ([]RunningBuild, error) {
 	// Fetch computer information with executor details
-	path := "/computer/api/json?tree=computer[displayName,executors[idle,currentExecutable[fullDisplayName,number,url,timestamp]]]"
+	path := "/computer/api/json?tree=" + runningBuildsTree.String()
 
-	resp, err := c.doRequest(http.MethodGet, path, nil, nil)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch running builds: %w", err)
 	}
@@ -319,401 +837,2177 @@ func (c *Client) GetRunningBuilds() ([]RunningBuild, error) {
 	return builds, nil
 }
 
-// GetJobDetails fetches detailed information about a specific job, including recent builds.
-func (c *Client) GetJobDetails(fullName string, limit int) (*JobDetails, error) {
-	if fullName == "" {
-		return nil, fmt.Errorf("job name must not be empty")
-	}
+// nodeFields selects the node fields GetNodes and GetNode decode.
+var nodeFields = tree.Must(tree.Fields("displayName", "description", "offline", "temporarilyOffline", "offlineCauseReason", "idle", "numExecutors").Nested(
+	tree.New("assignedLabels").Fields("name"),
+	executorFields,
+	tree.New("monitorData").Fields("*"),
+))
 
-	if limit <= 0 || limit > 50 {
-		limit = 10
-	}
+// GetNodes lists the built-in node and all agents with their offline state,
+// labels, executors and monitor data.
+func (c *Client) GetNodes(ctx context.Context) ([]Computer, error) {
+	path := "/computer/api/json?tree=" + url.QueryEscape(nodeFields.As("computer").String())
 
-	jobPath := buildJobAPIPath(fullName)
-	if jobPath == "" {
-		return nil, fmt.Errorf("invalid job path for %q", fullName)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch nodes: %w", err)
 	}
+	defer resp.Body.Close()
 
-	tree := fmt.Sprintf(
-		"name,fullName,url,color,_class,description,"+
-			"lastBuild[number,result,duration,timestamp,building,url,actions[causes[shortDescription,userId,userName],parameters[name,value],lastBuiltRevision[branch[SHA1,name]]]],"+
-			"builds[number,result,duration,timestamp,building,url,actions[causes[shortDescription,userId,userName],parameters[name,value],lastBuiltRevision[branch[SHA1,name]]]]{%d},"+
-			"property[parameterDefinitions[_class,name,type,description,trim,defaultValue,projectName,referencedParameters[name],defaultParameterValue[name,value],choices]]",
-		limit,
-	)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch nodes: status %d, body: %s", resp.StatusCode, string(body))
+	}
 
-	params := url.Values{}
-	params.Set("tree", tree)
+	var response ComputerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode nodes response: %w", err)
+	}
+	return response.Computer, nil
+}
 
-	path := fmt.Sprintf("%s/api/json?%s", jobPath, params.Encode())
+// GetNode fetches a single node by its display name.
+func (c *Client) GetNode(ctx context.Context, name string) (*Computer, error) {
+	nodePath, err := computerPath(name)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := c.doRequest(http.MethodGet, path, nil, nil)
+	resp, err := c.doRequest(ctx, http.MethodGet, nodePath+"/api/json?tree="+url.QueryEscape(nodeFields.String()), nil, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch job details: %w", err)
+		return nil, fmt.Errorf("failed to fetch node: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to fetch job details: status %d, body: %s", resp.StatusCode, string(body))
-	}
-
-	var payload struct {
-		JobDetails
-		// Flatten property definitions to parameter list
-		Property []struct {
-			ParameterDefinitions []ParameterDefinition `json:"parameterDefinitions"`
-		} `json:"property"`
+		return nil, fmt.Errorf("failed to fetch node: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	decoder := json.NewDecoder(resp.Body)
-	if err := decoder.Decode(&payload); err != nil {
-		return nil, fmt.Errorf("failed to decode job details: %w", err)
+	var node Computer
+	if err := json.NewDecoder(resp.Body).Decode(&node); err != nil {
+		return nil, fmt.Errorf("failed to decode node response: %w", err)
 	}
+	return &node, nil
+}
 
-	details := payload.JobDetails
-	if len(details.Builds) > limit {
-		details.Builds = details.Builds[:limit]
+// SetNodeOffline marks a node temporarily offline with reason, so it takes no
+// new builds. Jenkins only offers a toggle, so the node's state is read first;
+// a node that is already offline just gets the new reason.
+func (c *Client) SetNodeOffline(ctx context.Context, name, reason string) error {
+	node, err := c.GetNode(ctx, name)
+	if err != nil {
+		return err
 	}
-	for _, prop := range payload.Property {
-		if len(prop.ParameterDefinitions) == 0 {
-			continue
-		}
-		details.ParameterDefinitions = append(details.ParameterDefinitions, prop.ParameterDefinitions...)
+	nodePath, err := computerPath(name)
+	if err != nil {
+		return err
 	}
 
-	return &details, nil
+	action := "toggleOffline"
+	if node.TemporarilyOffline {
+		action = "changeOfflineCause"
+	}
+	params := url.Values{}
+	params.Set("offlineMessage", reason)
+	return c.postNodeAction(ctx, fmt.Sprintf("%s/%s?%s", nodePath, action, params.Encode()), "take node offline")
 }
 
-// TriggerBuild requests a new build for the specified job.
-func (c *Client) TriggerBuild(fullName string) error {
-	if fullName == "" {
-		return fmt.Errorf("job name must not be empty")
+// SetNodeOnline brings a node that was taken offline by hand back online. It
+// does nothing for nodes that are online or merely disconnected.
+func (c *Client) SetNodeOnline(ctx context.Context, name string) error {
+	node, err := c.GetNode(ctx, name)
+	if err != nil {
+		return err
 	}
-
-	jobPath := buildJobAPIPath(fullName)
-	if jobPath == "" {
-		return fmt.Errorf("invalid job path for %q", fullName)
+	if !node.TemporarilyOffline {
+		return nil
+	}
+	nodePath, err := computerPath(name)
+	if err != nil {
+		return err
 	}
+	return c.postNodeAction(ctx, nodePath+"/toggleOffline", "bring node online")
+}
 
-	path := fmt.Sprintf("%s/build?delay=0sec", jobPath)
-	resp, err := c.doRequest(http.MethodPost, path, nil, map[string]string{
-		"Content-Type": "application/x-www-form-urlencoded",
-	})
+// loadFields selects the load statistics series, each sampled every 10
+// seconds, every minute and every hour.
+var loadFields = tree.Must(tree.Fields().Nested(
+	loadSeriesFields.As("busyExecutors"),
+	loadSeriesFields.As("idleExecutors"),
+	loadSeriesFields.As("onlineExecutors"),
+	loadSeriesFields.As("totalExecutors"),
+	loadSeriesFields.As("availableExecutors"),
+	loadSeriesFields.As("queueLength"),
+))
+
+// loadSeriesFields selects the latest value of each timescale and the history
+// of the per-minute one, enough for a gauge and a growth rate.
+var loadSeriesFields = tree.Fields().Nested(
+	tree.New("sec10").Fields("latest"),
+	tree.New("min").Fields("latest", "history"),
+	tree.New("hour").Fields("latest"),
+)
+
+// labelLoadTree selects a label's executors and nodes with its load statistics.
+var labelLoadTree = tree.Must(tree.Fields("name", "offline", "busyExecutors", "idleExecutors", "totalExecutors").Nested(
+	tree.New("nodes").Fields("nodeName"),
+	loadFields.As("loadStatistics"),
+))
+
+// GetOverallLoad fetches executor and queue statistics across all nodes, as
+// behind the Load Statistics page.
+func (c *Client) GetOverallLoad(ctx context.Context) (*LoadStatistics, error) {
+	path := "/overallLoad/api/json?tree=" + loadFields.String()
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
 	if err != nil {
-		return fmt.Errorf("failed to trigger build: %w", err)
+		return nil, fmt.Errorf("failed to fetch load statistics: %w", err)
 	}
 	defer resp.Body.Close()
 
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
-		return nil
-	default:
+	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to trigger build: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to fetch load statistics: status %d, body: %s", resp.StatusCode, string(body))
 	}
-}
 
-// TriggerBuildWithParameters requests a new build providing parameter values.
-func (c *Client) TriggerBuildWithParameters(fullName string, params map[string]string) error {
-	if fullName == "" {
-		return fmt.Errorf("job name must not be empty")
+	var load LoadStatistics
+	if err := json.NewDecoder(resp.Body).Decode(&load); err != nil {
+		return nil, fmt.Errorf("failed to decode load statistics response: %w", err)
 	}
+	return &load, nil
+}
 
-	jobPath := buildJobAPIPath(fullName)
-	if jobPath == "" {
-		return fmt.Errorf("invalid job path for %q", fullName)
+// GetLabelLoad fetches the executors of the nodes carrying label, and their
+// load statistics. label may be a node name or a label expression.
+func (c *Client) GetLabelLoad(ctx context.Context, label string) (*LabelLoad, error) {
+	if strings.TrimSpace(label) == "" {
+		return nil, fmt.Errorf("label must not be empty")
 	}
 
-	form := url.Values{}
-	for key, value := range params {
-		form.Set(key, value)
-	}
+	params := url.Values{}
+	params.Set("tree", labelLoadTree.String())
+	path := fmt.Sprintf("/label/%s/api/json?%s", url.PathEscape(label), params.Encode())
 
-	path := fmt.Sprintf("%s/buildWithParameters", jobPath)
-	resp, err := c.doRequest(
-		http.MethodPost,
-		path,
-		strings.NewReader(form.Encode()),
-		map[string]string{
-			"Content-Type": "application/x-www-form-urlencoded",
-		},
-	)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
 	if err != nil {
-		return fmt.Errorf("failed to trigger build with parameters: %w", err)
+		return nil, fmt.Errorf("failed to fetch label %s: %w", label, err)
 	}
 	defer resp.Body.Close()
 
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
-		return nil
-	default:
+	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to trigger build with parameters: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to fetch label %s: status %d, body: %s", label, resp.StatusCode, string(body))
+	}
+
+	var load LabelLoad
+	if err := json.NewDecoder(resp.Body).Decode(&load); err != nil {
+		return nil, fmt.Errorf("failed to decode label response: %w", err)
+	}
+	return &load, nil
+}
+
+// pluginsTree selects the installed plugins with their version and update state.
+var pluginsTree = tree.Must(tree.New("plugins").Fields("shortName", "longName", "version", "active", "enabled", "hasUpdate", "url"))
+
+// GetInstalledPlugins lists the installed plugins sorted by short name. It
+// needs Overall/Read only, but some instances restrict the plugin manager to
+// administrators, in which case the 403 is returned as an error.
+func (c *Client) GetInstalledPlugins(ctx context.Context) ([]Plugin, error) {
+	path := "/pluginManager/api/json?depth=1&tree=" + pluginsTree.String()
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch plugins: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch plugins: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Plugins []Plugin `json:"plugins"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode plugins response: %w", err)
+	}
+	sort.Slice(response.Plugins, func(i, j int) bool {
+		return response.Plugins[i].ShortName < response.Plugins[j].ShortName
+	})
+	return response.Plugins, nil
+}
+
+// ErrFingerprintNotFound is returned, wrapped, when Jenkins has no record of
+// a checksum, i.e. no fingerprinted build produced or used the file.
+var ErrFingerprintNotFound = errors.New("no build recorded this fingerprint")
+
+var md5Pattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// fingerprintTree selects a fingerprinted file with the build that produced it
+// and the build ranges that used it.
+var fingerprintTree = tree.Must(tree.Fields("fileName", "hash", "timestamp").Nested(
+	tree.New("original").Fields("name", "number"),
+	tree.New("usage").Fields("name").Nested(
+		tree.New("ranges").Nested(tree.New("ranges").Fields("start", "end")),
+	),
+))
+
+// GetFingerprint looks up a file by the MD5 checksum Jenkins recorded for it
+// with fingerprint(), archiveArtifacts(fingerprint: true) or copyArtifacts.
+func (c *Client) GetFingerprint(ctx context.Context, md5 string) (*Fingerprint, error) {
+	md5 = strings.ToLower(strings.TrimSpace(md5))
+	if !md5Pattern.MatchString(md5) {
+		return nil, fmt.Errorf("fingerprint must be an MD5 checksum of 32 hex digits, got %q", md5)
+	}
+
+	path := "/fingerprint/" + md5 + "/api/json?tree=" + fingerprintTree.String()
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch fingerprint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrFingerprintNotFound, md5)
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch fingerprint: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var fingerprint Fingerprint
+	if err := json.NewDecoder(resp.Body).Decode(&fingerprint); err != nil {
+		return nil, fmt.Errorf("failed to decode fingerprint response: %w", err)
+	}
+	return &fingerprint, nil
+}
+
+// credentialsTree selects what identifies a stored credential. The API never
+// exposes secrets, but nothing beyond these fields is asked for either.
+var credentialsTree = tree.Must(tree.New("credentials").Fields("id", "displayName", "description", "typeName"))
+
+// GlobalCredentialsDomain is the domain credentials are stored in unless an
+// administrator set up others.
+const GlobalCredentialsDomain = "_"
+
+// GetCredentials lists the credentials of one domain of the system
+// credentials store, sorted by ID; an empty domain means the global one.
+// Listing needs Credentials/View, so a 403 is common for non-administrators
+// and returned as an error, as is a 404 when the credentials plugin is not
+// installed or the domain does not exist.
+func (c *Client) GetCredentials(ctx context.Context, domain string) ([]Credential, error) {
+	if domain == "" {
+		domain = GlobalCredentialsDomain
+	}
+	path := "/credentials/store/system/domain/" + url.PathEscape(domain) + "/api/json?depth=1&tree=" + credentialsTree.String()
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch credentials: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch credentials: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Credentials []Credential `json:"credentials"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials response: %w", err)
+	}
+	sort.Slice(response.Credentials, func(i, j int) bool {
+		return response.Credentials[i].ID < response.Credentials[j].ID
+	})
+	return response.Credentials, nil
+}
+
+// credentialsParameterDescriptor serves the credentials a credentials
+// parameter offers on the web UI's build form.
+const credentialsParameterDescriptor = "com.cloudbees.plugins.credentials.CredentialsParameterDefinition"
+
+// GetCredentialChoices lists the credentials of credentialType (a class
+// name) that a credentials parameter of the job may take, as the build form
+// offers them: from the system store and the stores of the job's folders,
+// filtered by Jenkins by type and by what the user may use. Only IDs and
+// display names are returned, never secrets.
+func (c *Client) GetCredentialChoices(ctx context.Context, fullName, credentialType string) ([]Credential, error) {
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
+	}
+	if credentialType == "" {
+		credentialType = DefaultCredentialType
+	}
+	params := url.Values{}
+	params.Set("credentialType", credentialType)
+	params.Set("required", "true")
+	path := jobPath + "/descriptorByName/" + credentialsParameterDescriptor + "/fillValueItems?" + params.Encode()
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch credentials: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch credentials: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	// The answer is a list box model: the name is what the form shows, the
+	// value the credential's ID.
+	var response struct {
+		Values []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"values"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode credentials response: %w", err)
+	}
+	credentials := make([]Credential, 0, len(response.Values))
+	for _, value := range response.Values {
+		// Optional parameters are offered "- none -" with no ID.
+		if value.Value == "" {
+			continue
+		}
+		credentials = append(credentials, Credential{ID: value.Value, DisplayName: value.Name})
+	}
+	sort.Slice(credentials, func(i, j int) bool {
+		return credentials[i].ID < credentials[j].ID
+	})
+	return credentials, nil
+}
+
+func (c *Client) postNodeAction(ctx context.Context, path, what string) error {
+	resp, err := c.doRequest(ctx, http.MethodPost, path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to %s: status %d, body: %s", what, resp.StatusCode, string(body))
+	}
+}
+
+// computerPath returns the /computer/ URL path of a node. The built-in node
+// is listed by its display name but addressed by a fixed path segment.
+func computerPath(name string) (string, error) {
+	switch strings.TrimSpace(name) {
+	case "":
+		return "", fmt.Errorf("node name must not be empty")
+	case "Built-In Node":
+		return "/computer/(built-in)", nil
+	case "master":
+		return "/computer/(master)", nil
+	default:
+		return "/computer/" + url.PathEscape(name), nil
+	}
+}
+
+// jobDetailsTree selects a job with its last limit builds and parameters.
+func jobDetailsTree(limit int) tree.Node {
+	return tree.Fields("name", "fullName", "url", "color", "_class", "disabled", "description", "concurrentBuild").Nested(
+		buildFields.As("lastBuild"),
+		buildFields.As("builds").Limit(limit),
+		healthReportFields,
+		// Only folders have children and views; other jobs just omit them.
+		tree.New("jobs").Fields("name", "fullName", "url", "color", "_class"),
+		tree.New("views").Fields("name", "url"),
+		tree.New("property").Nested(parameterDefinitionFields),
+	)
+}
+
+// parameterDefinitionFields selects the parameters a job is built with.
+var parameterDefinitionFields = tree.Must(tree.New("parameterDefinitions").Fields("_class", "name", "type", "description", "trim", "defaultValue", "projectName", "credentialType").Nested(
+	tree.New("referencedParameters").Fields("name"),
+	tree.New("defaultParameterValue").Fields("name", "value"),
+).Fields("choices"))
+
+// GetJobDetails fetches detailed information about a specific job, including recent builds.
+func (c *Client) GetJobDetails(ctx context.Context, fullName string, limit int) (*JobDetails, error) {
+	if fullName == "" {
+		return nil, fmt.Errorf("job name must not be empty")
+	}
+
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	params := url.Values{}
+	params.Set("tree", jobDetailsTree(limit).String())
+
+	path := fmt.Sprintf("%s/api/json?%s", jobPath, params.Encode())
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job details: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch job details: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var payload struct {
+		JobDetails
+		// Flatten property definitions to parameter list
+		Property []struct {
+			ParameterDefinitions []ParameterDefinition `json:"parameterDefinitions"`
+		} `json:"property"`
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode job details: %w", err)
+	}
+
+	details := payload.JobDetails
+	if len(details.Builds) > limit {
+		details.Builds = details.Builds[:limit]
+	}
+	for _, prop := range payload.Property {
+		if len(prop.ParameterDefinitions) == 0 {
+			continue
+		}
+		details.ParameterDefinitions = append(details.ParameterDefinitions, prop.ParameterDefinitions...)
+	}
+
+	return &details, nil
+}
+
+// GetBuilds fetches a page of a job's build history, newest first. It uses the
+// allBuilds range selector ({M,N}) so history beyond the 100 builds Jenkins
+// lists under "builds" can be paged through.
+func (c *Client) GetBuilds(ctx context.Context, fullName string, offset, limit int) ([]Build, error) {
+	if fullName == "" {
+		return nil, fmt.Errorf("job name must not be empty")
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 25
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	params := url.Values{}
+	params.Set("tree", buildFields.As("allBuilds").Range(offset, offset+limit).String())
+	path := fmt.Sprintf("%s/api/json?%s", jobPath, params.Encode())
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch builds: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch builds: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var payload struct {
+		AllBuilds []Build `json:"allBuilds"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode builds: %w", err)
+	}
+
+	if len(payload.AllBuilds) > limit {
+		payload.AllBuilds = payload.AllBuilds[:limit]
+	}
+	return payload.AllBuilds, nil
+}
+
+// buildCountTree selects just the number of every kept build.
+var buildCountTree = tree.Must(tree.Fields().Nested(
+	tree.New("allBuilds").Fields("number"),
+))
+
+// GetBuildCount returns how many builds of a job are kept. The numbers of the
+// first and last build would overcount builds deleted in between, so every
+// build is listed by number alone, a few bytes each.
+func (c *Client) GetBuildCount(ctx context.Context, fullName string) (int, error) {
+	if fullName == "" {
+		return 0, fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return 0, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	params := url.Values{}
+	params.Set("tree", buildCountTree.String())
+	path := fmt.Sprintf("%s/api/json?%s", jobPath, params.Encode())
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch build count: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("failed to fetch build count: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var payload struct {
+		AllBuilds []struct {
+			Number int `json:"number"`
+		} `json:"allBuilds"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return 0, fmt.Errorf("failed to decode build count: %w", err)
+	}
+	return len(payload.AllBuilds), nil
+}
+
+// TriggerBuild requests a new build for the specified job. It returns the ID
+// of the queue item Jenkins created, or 0 if the response did not name one.
+func (c *Client) TriggerBuild(ctx context.Context, fullName string) (int, error) {
+	if fullName == "" {
+		return 0, fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return 0, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	path := fmt.Sprintf("%s/build?delay=0sec", jobPath)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, nil, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to trigger build: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		return queueIDFromLocation(resp.Header.Get("Location")), nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("failed to trigger build: status %d, body: %s", resp.StatusCode, string(body))
+	}
+}
+
+// CanBuild reports whether the current user has Build permission on a job.
+// Jenkins has no API for permissions, and a GET on /build would start a build
+// when authenticating with an API token, so this looks for the "Build Now"
+// task in the job's context menu, the small JSON list behind the breadcrumb
+// dropdown that only offers it to users who may build. Disabled jobs have no
+// such task and report false as well, as does every job of a read-only server.
+func (c *Client) CanBuild(ctx context.Context, fullName string) (bool, error) {
+	if fullName == "" {
+		return false, fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return false, fmt.Errorf("invalid job path for %q", fullName)
+	}
+	if c.readOnly {
+		return false, nil
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, jobPath+"/contextMenu", nil, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to check build permission: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("failed to check build permission: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var menu struct {
+		Items []struct {
+			URL string `json:"url"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&menu); err != nil {
+		return false, fmt.Errorf("failed to decode job context menu: %w", err)
+	}
+	for _, item := range menu.Items {
+		if task, _, _ := strings.Cut(item.URL, "?"); strings.HasSuffix(task, "/build") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ScanMultibranch starts branch indexing of a multibranch project (or an
+// organization folder), e.g. to pick up a branch that was just pushed. The
+// scan runs in the background; new branches show up once it finishes.
+func (c *Client) ScanMultibranch(ctx context.Context, fullName string) error {
+	if fullName == "" {
+		return fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	// On a computed folder /build schedules indexing rather than a build.
+	path := fmt.Sprintf("%s/build?delay=0", jobPath)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, nil, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start branch scan: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to start branch scan: status %d, body: %s", resp.StatusCode, string(body))
+	}
+}
+
+// TriggerBuildWithParameters requests a new build providing parameter values.
+// It returns the ID of the queue item Jenkins created, or 0 if unknown.
+func (c *Client) TriggerBuildWithParameters(ctx context.Context, fullName string, params map[string]string) (int, error) {
+	if fullName == "" {
+		return 0, fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return 0, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	form := url.Values{}
+	for key, value := range params {
+		form.Set(key, value)
+	}
+
+	path := fmt.Sprintf("%s/buildWithParameters", jobPath)
+	resp, err := c.doRequest(
+		ctx,
+		http.MethodPost,
+		path,
+		strings.NewReader(form.Encode()),
+		map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to trigger build with parameters: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		return queueIDFromLocation(resp.Header.Get("Location")), nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("failed to trigger build with parameters: status %d, body: %s", resp.StatusCode, string(body))
+	}
+}
+
+// queueIDFromLocation extracts N from a ".../queue/item/N/" Location header.
+func queueIDFromLocation(location string) int {
+	const marker = "/queue/item/"
+	idx := strings.LastIndex(location, marker)
+	if idx < 0 {
+		return 0
+	}
+	id, err := strconv.Atoi(strings.Trim(location[idx+len(marker):], "/"))
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return id
+}
+
+// GetQueueItem fetches a single queue item. Jenkins keeps items around for a
+// few minutes after they leave the queue, with Executable set once started.
+func (c *Client) GetQueueItem(ctx context.Context, id int) (*QueueItem, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("queue item id must be greater than zero")
+	}
+
+	path := fmt.Sprintf("/queue/item/%d/api/json", id)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch queue item: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch queue item: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var item QueueItem
+	if err := json.NewDecoder(resp.Body).Decode(&item); err != nil {
+		return nil, fmt.Errorf("failed to decode queue item: %w", err)
+	}
+	return &item, nil
+}
+
+// queueWaitInterval is how often WaitForBuildNumber checks a queue item.
+var queueWaitInterval = 2 * time.Second
+
+// WaitForBuildNumber polls a queue item until it has started and returns the
+// build number. It stops when the item is cancelled or ctx is done.
+func (c *Client) WaitForBuildNumber(ctx context.Context, queueID int) (int, error) {
+	for {
+		item, err := c.GetQueueItem(ctx, queueID)
+		if err != nil {
+			return 0, err
+		}
+		if item.Cancelled {
+			return 0, fmt.Errorf("queue item %d was cancelled", queueID)
+		}
+		if number := item.GetBuildNumber(); number > 0 {
+			return number, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(queueWaitInterval):
+		}
 	}
 }
 
 // AbortBuild sends a stop signal to a running build.
-func (c *Client) AbortBuild(fullName string, buildNumber int) error {
+func (c *Client) AbortBuild(ctx context.Context, fullName string, buildNumber int) error {
+	if fullName == "" {
+		return fmt.Errorf("job name must not be empty")
+	}
+	if buildNumber <= 0 {
+		return fmt.Errorf("build number must be greater than zero")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	path := fmt.Sprintf("%s/%d/stop", jobPath, buildNumber)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to abort build: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to abort build: status %d, body: %s", resp.StatusCode, string(body))
+	}
+}
+
+// replayScriptPattern finds the script editors on a build's replay page: the
+// main script as _.mainScript, and each script the build loaded with `load`
+// under its name with dots turned into underscores.
+var replayScriptPattern = regexp.MustCompile(`(?s)<textarea[^>]*name="_\.([^"]+)"[^>]*>(.*?)</textarea>`)
+
+// GetReplayScript fetches the main Pipeline script of a build from its replay
+// page, which has no JSON API. Only users who may replay the build can see it.
+func (c *Client) GetReplayScript(ctx context.Context, fullName string, buildNumber int) (string, error) {
+	scripts, err := c.replayScripts(ctx, fullName, buildNumber)
+	if err != nil {
+		return "", err
+	}
+	return scripts["mainScript"], nil
+}
+
+// replayScripts reads every script editor of a build's replay page by field
+// name. It fails for builds that cannot be replayed.
+func (c *Client) replayScripts(ctx context.Context, fullName string, buildNumber int) (map[string]string, error) {
+	path, err := replayPath(fullName, buildNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path+"/", nil, map[string]string{
+		"Accept": "text/html",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch replay script: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch replay script: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	page, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay page: %w", err)
+	}
+	scripts := make(map[string]string)
+	for _, match := range replayScriptPattern.FindAllSubmatch(page, -1) {
+		// The editor may start with a newline that browsers drop.
+		scripts[string(match[1])] = strings.TrimPrefix(html.UnescapeString(string(match[2])), "\n")
+	}
+	if _, ok := scripts["mainScript"]; !ok {
+		return nil, fmt.Errorf("build #%d of %s cannot be replayed", buildNumber, fullName)
+	}
+	return scripts, nil
+}
+
+// ReplayBuild reruns a Pipeline build. With an empty script it reruns the
+// same scripts; otherwise script replaces the main script for this run only.
+// The replay form is read first so the scripts loaded with `load` are sent
+// back as the build ran them.
+func (c *Client) ReplayBuild(ctx context.Context, fullName string, buildNumber int, script string) error {
+	path, err := replayPath(fullName, buildNumber)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	if script == "" {
+		path += "/rebuild"
+	} else {
+		scripts, err := c.replayScripts(ctx, fullName, buildNumber)
+		if err != nil {
+			return err
+		}
+		scripts["mainScript"] = script
+		// Jenkins reads the replay form from its json field.
+		form, err := json.Marshal(scripts)
+		if err != nil {
+			return fmt.Errorf("failed to encode replay script: %w", err)
+		}
+		path += "/run"
+		body = strings.NewReader(url.Values{"json": {string(form)}}.Encode())
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPost, path, body, map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replay build: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to replay build: status %d, body: %s", resp.StatusCode, string(body))
+	}
+}
+
+// replayPath returns the path of a build's replay action.
+func replayPath(fullName string, buildNumber int) (string, error) {
+	path, err := buildPath(fullName, buildNumber)
+	if err != nil {
+		return "", err
+	}
+	return path + "/replay", nil
+}
+
+// buildPath returns the path of a build, e.g. /job/Team/job/api/7.
+func buildPath(fullName string, buildNumber int) (string, error) {
+	if fullName == "" {
+		return "", fmt.Errorf("job name must not be empty")
+	}
+	if buildNumber <= 0 {
+		return "", fmt.Errorf("build number must be greater than zero")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return "", fmt.Errorf("invalid job path for %q", fullName)
+	}
+	return fmt.Sprintf("%s/%d", jobPath, buildNumber), nil
+}
+
+// promotionProcessesTree selects the names of a job's promotion processes.
+var promotionProcessesTree = tree.Must(tree.New("processes").Fields("name"))
+
+// GetPromotionProcesses lists the names of a job's promotion processes. Jobs
+// without any, and instances without the promoted-builds plugin, have none.
+func (c *Client) GetPromotionProcesses(ctx context.Context, fullName string) ([]string, error) {
+	if fullName == "" {
+		return nil, fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, jobPath+"/promotion/api/json?tree="+promotionProcessesTree.String(), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch promotion processes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch promotion processes: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Processes []struct {
+			Name string `json:"name"`
+		} `json:"processes"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode promotion processes: %w", err)
+	}
+	names := make([]string, 0, len(response.Processes))
+	for _, process := range response.Processes {
+		names = append(names, process.Name)
+	}
+	return names, nil
+}
+
+// PromoteBuild forces the promotion process on a build, as the "Force
+// promotion" button does: its conditions (e.g. manual approval) are skipped,
+// which needs the Promote permission. The promotion runs asynchronously.
+func (c *Client) PromoteBuild(ctx context.Context, fullName string, buildNumber int, process string) error {
+	path, err := buildPath(fullName, buildNumber)
+	if err != nil {
+		return err
+	}
+	if process == "" {
+		return fmt.Errorf("promotion process must not be empty")
+	}
+
+	params := url.Values{"name": {process}}
+	resp, err := c.doRequest(ctx, http.MethodPost, path+"/promotion/forcePromotion?"+params.Encode(), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to promote build: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to promote build: status %d, body: %s", resp.StatusCode, string(body))
+	}
+}
+
+// SetBuildDescription replaces the description of a build, e.g. "hotfix for
+// prod incident". An empty description clears it.
+func (c *Client) SetBuildDescription(ctx context.Context, fullName string, buildNumber int, description string) error {
+	path, err := buildPath(fullName, buildNumber)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{"description": {description}}
+	return c.postBuildForm(ctx, path+"/submitDescription", form, "set build description")
+}
+
+// SetBuildDisplayName renames a build; an empty name restores the default
+// "#N". Jenkins saves the display name and the description together from the
+// build's configure form, so the description is passed along to keep it.
+func (c *Client) SetBuildDisplayName(ctx context.Context, fullName string, buildNumber int, displayName, description string) error {
+	path, err := buildPath(fullName, buildNumber)
+	if err != nil {
+		return err
+	}
+
+	// Jenkins reads the configure form from its json field.
+	payload, err := json.Marshal(map[string]string{
+		"displayName": displayName,
+		"description": description,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode build settings: %w", err)
+	}
+	form := url.Values{"json": {string(payload)}}
+	return c.postBuildForm(ctx, path+"/configSubmit", form, "set build display name")
+}
+
+func (c *Client) postBuildForm(ctx context.Context, path string, form url.Values, action string) error {
+	resp, err := c.doRequest(ctx, http.MethodPost, path, strings.NewReader(form.Encode()), map[string]string{
+		"Content-Type": "application/x-www-form-urlencoded",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to %s: status %d, body: %s", action, resp.StatusCode, string(body))
+	}
+}
+
+// EnableJob lets a disabled job build again.
+func (c *Client) EnableJob(ctx context.Context, fullName string) error {
+	return c.setJobEnabled(ctx, fullName, "enable")
+}
+
+// DisableJob stops a job from building, by trigger or by hand, until it is
+// enabled again.
+func (c *Client) DisableJob(ctx context.Context, fullName string) error {
+	return c.setJobEnabled(ctx, fullName, "disable")
+}
+
+func (c *Client) setJobEnabled(ctx context.Context, fullName, action string) error {
+	if fullName == "" {
+		return fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPost, jobPath+"/"+action, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to %s job: %w", action, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to %s job: status %d, body: %s", action, resp.StatusCode, string(body))
+	}
+}
+
+// CreateJob creates fullName from configXML. The parent folders must already
+// exist; Jenkins refuses names that are taken.
+func (c *Client) CreateJob(ctx context.Context, fullName, configXML string) error {
+	createPath, err := createItemPath(fullName)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPost, createPath, strings.NewReader(configXML), map[string]string{
+		"Content-Type": "application/xml",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkCreateItemResponse(resp, "create job")
+}
+
+// CopyJob creates toFullName with the configuration of fromFullName, e.g. to
+// start a job from a template. Builds are not copied.
+func (c *Client) CopyJob(ctx context.Context, fromFullName, toFullName string) error {
+	if buildJobAPIPath(fromFullName) == "" {
+		return fmt.Errorf("invalid job path for %q", fromFullName)
+	}
+	createPath, err := createItemPath(toFullName)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("mode", "copy")
+	// A leading slash makes the source absolute rather than relative to the target folder.
+	params.Set("from", "/"+strings.Trim(fromFullName, "/"))
+	resp, err := c.doRequest(ctx, http.MethodPost, createPath+"&"+params.Encode(), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to copy job: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return checkCreateItemResponse(resp, "copy job")
+}
+
+// DeleteJob permanently deletes a job, or a folder with everything in it.
+func (c *Client) DeleteJob(ctx context.Context, fullName string) error {
+	if fullName == "" {
+		return fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPost, jobPath+"/doDelete", nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete job: status %d, body: %s", resp.StatusCode, string(body))
+	}
+}
+
+// createItemPath returns the createItem URL (with the name query parameter)
+// that creates fullName inside its parent folder.
+func createItemPath(fullName string) (string, error) {
+	fullName = strings.Trim(fullName, "/")
+	if fullName == "" {
+		return "", fmt.Errorf("job name must not be empty")
+	}
+
+	parent, name := "", fullName
+	if idx := strings.LastIndex(fullName, "/"); idx >= 0 {
+		parent, name = fullName[:idx], fullName[idx+1:]
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	parentPath := buildJobAPIPath(parent)
+	if parent != "" && parentPath == "" {
+		return "", fmt.Errorf("invalid job path for %q", fullName)
+	}
+	return parentPath + "/createItem?name=" + url.QueryEscape(name), nil
+}
+
+// checkCreateItemResponse accepts the redirect Jenkins answers createItem with
+// and reports anything else, e.g. 400 for a name that is already taken.
+func checkCreateItemResponse(resp *http.Response, what string) error {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to %s: status %d, body: %s", what, resp.StatusCode, string(body))
+	}
+}
+
+// CancelQueueItem removes a waiting item from the build queue. Jenkins answers
+// with a redirect (or 404 on older versions) even when the item is gone, so any
+// of those counts as success.
+func (c *Client) CancelQueueItem(ctx context.Context, id int) error {
+	if id <= 0 {
+		return fmt.Errorf("queue item id must be greater than zero")
+	}
+
+	path := fmt.Sprintf("/queue/cancelItem?id=%d", id)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to cancel queue item: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusFound, http.StatusNotFound:
+		return nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to cancel queue item: status %d, body: %s", resp.StatusCode, string(body))
+	}
+}
+
+// GetConsoleLog fetches the full console output for a specific build.
+func (c *Client) GetConsoleLog(ctx context.Context, fullName string, buildNumber int) (string, error) {
+	if fullName == "" {
+		return "", fmt.Errorf("job name must not be empty")
+	}
+	if buildNumber <= 0 {
+		return "", fmt.Errorf("build number must be greater than zero")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return "", fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	path := fmt.Sprintf("%s/%d/consoleText", jobPath, buildNumber)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, map[string]string{
+		"Accept": "text/plain",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch console log: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to fetch console log: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read console log: %w", err)
+	}
+	return string(data), nil
+}
+
+// GetConsoleLogSize returns the size of a build's console log in bytes. Jenkins
+// only reports it as the X-Text-Size header of progressive log responses, so
+// it is asked with a HEAD request that leaves the log itself behind.
+func (c *Client) GetConsoleLogSize(ctx context.Context, fullName string, buildNumber int) (int64, error) {
+	logPath, err := c.progressiveLogPath("", fullName, buildNumber, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodHead, logPath, nil, map[string]string{
+		"Accept": "text/plain",
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch console log size: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("failed to fetch console log size: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	size, err := strconv.ParseInt(resp.Header.Get("X-Text-Size"), 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("failed to fetch console log size: missing X-Text-Size header")
+	}
+	return size, nil
+}
+
+// testReportTree selects the test report fields, skipping passing cases' stdout/stderr.
+var testReportTree = tree.Must(tree.Fields("failCount", "passCount", "skipCount", "duration").Nested(
+	tree.New("suites").Fields("name", "duration").Nested(
+		tree.New("cases").Fields("className", "name", "status", "duration", "errorDetails", "errorStackTrace", "skipped", "skippedMessage", "age"),
+	),
+))
+
+// GetTestReport fetches the JUnit test report of a build. It returns a nil
+// report without error when the build published no test results.
+func (c *Client) GetTestReport(ctx context.Context, fullName string, buildNumber int) (*TestReport, error) {
+	if fullName == "" {
+		return nil, fmt.Errorf("job name must not be empty")
+	}
+	if buildNumber <= 0 {
+		return nil, fmt.Errorf("build number must be greater than zero")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	params := url.Values{}
+	params.Set("tree", testReportTree.String())
+	path := fmt.Sprintf("%s/%d/testReport/api/json?%s", jobPath, buildNumber, params.Encode())
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch test report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch test report: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var report TestReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to decode test report: %w", err)
+	}
+	return &report, nil
+}
+
+// artifactsTree selects the files a build archived.
+var artifactsTree = tree.Must(tree.New("artifacts").Fields("displayPath", "fileName", "relativePath"))
+
+// GetArtifacts lists the files a build archived.
+func (c *Client) GetArtifacts(ctx context.Context, fullName string, buildNumber int) ([]Artifact, error) {
+	if fullName == "" {
+		return nil, fmt.Errorf("job name must not be empty")
+	}
+	if buildNumber <= 0 {
+		return nil, fmt.Errorf("build number must be greater than zero")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	params := url.Values{}
+	params.Set("tree", artifactsTree.String())
+	path := fmt.Sprintf("%s/%d/api/json?%s", jobPath, buildNumber, params.Encode())
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch artifacts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch artifacts: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var payload struct {
+		Artifacts []Artifact `json:"artifacts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode artifacts: %w", err)
+	}
+	return payload.Artifacts, nil
+}
+
+// DownloadArtifact streams an archived file to destPath. The file is written
+// next to destPath with a .part suffix and renamed once complete, so an
+// interrupted download never leaves a truncated file under the final name.
+// progress may be nil; it is called as data arrives with total -1 when
+// Jenkins does not send a length. Only ctx bounds the transfer, not the
+// client's request timeout.
+func (c *Client) DownloadArtifact(ctx context.Context, fullName string, buildNumber int, relativePath, destPath string, progress ProgressFunc) error {
 	if fullName == "" {
 		return fmt.Errorf("job name must not be empty")
 	}
-	if buildNumber <= 0 {
-		return fmt.Errorf("build number must be greater than zero")
+	if buildNumber <= 0 {
+		return fmt.Errorf("build number must be greater than zero")
+	}
+	if destPath == "" {
+		return fmt.Errorf("destination path must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return fmt.Errorf("invalid job path for %q", fullName)
+	}
+	artifactPath := buildArtifactPath(relativePath)
+	if artifactPath == "" {
+		return fmt.Errorf("invalid artifact path %q", relativePath)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, fmt.Sprintf("%s/%d/artifact%s", jobPath, buildNumber, artifactPath), nil, map[string]string{
+		"Accept": "application/octet-stream",
+		// Uncompressed, so Content-Length gives the progress total.
+		"Accept-Encoding": "identity",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to download artifact: %w", err)
+	}
+
+	streaming := *c.HTTPClient
+	streaming.Timeout = 0
+	resp, err := streaming.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download artifact: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to download artifact: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	partPath := destPath + ".part"
+	file, err := os.Create(partPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", partPath, err)
+	}
+
+	var dst io.Writer = file
+	if progress != nil {
+		dst = &progressWriter{w: file, total: resp.ContentLength, report: progress}
+	}
+	if _, err := io.Copy(dst, resp.Body); err != nil {
+		file.Close()
+		os.Remove(partPath)
+		return fmt.Errorf("failed to download artifact: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(partPath)
+		return fmt.Errorf("failed to write %s: %w", partPath, err)
+	}
+	if err := os.Rename(partPath, destPath); err != nil {
+		os.Remove(partPath)
+		return fmt.Errorf("failed to move artifact into place: %w", err)
+	}
+	return nil
+}
+
+// progressWriter reports the running byte count of everything written through it.
+type progressWriter struct {
+	w       io.Writer
+	written int64
+	total   int64
+	report  ProgressFunc
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	p.report(p.written, p.total)
+	return n, err
+}
+
+// GetProgressiveLog fetches a chunk of console output using Jenkins' progressive log API.
+// It returns the new content, the next offset to request, and whether more data is available.
+// The lookup prefers the provided buildURL (if not empty) and falls back to job full name + build number.
+//
+// Some console annotators leave progressiveText empty while progressiveHtml
+// works; such builds are read from progressiveHtml, converted back to text.
+func (c *Client) GetProgressiveLog(ctx context.Context, buildURL, fullName string, buildNumber int, start int64) (string, int64, bool, error) {
+	if start < 0 {
+		start = 0
+	}
+
+	buildPath, err := c.progressiveBuildPath(buildURL, fullName, buildNumber)
+	if err != nil {
+		return "", 0, false, err
+	}
+	if state, ok := c.htmlLogState(buildPath); ok {
+		return c.getProgressiveHTMLLog(ctx, buildPath, start, state)
+	}
+
+	chunk, err := c.fetchProgressiveLog(ctx, fmt.Sprintf("%s/logText/progressiveText?start=%d", buildPath, start), start, map[string]string{
+		"Accept": "text/plain",
+	})
+	if err != nil {
+		return "", 0, false, err
+	}
+	if len(chunk.data) == 0 && chunk.next > start {
+		// The log grew but none of it came back as text.
+		return c.getProgressiveHTMLLog(ctx, buildPath, start, htmlLogState{})
+	}
+	return string(chunk.data), chunk.next, chunk.more, nil
+}
+
+// getProgressiveHTMLLog reads a chunk of progressiveHtml and remembers the
+// build as one to read that way.
+func (c *Client) getProgressiveHTMLLog(ctx context.Context, buildPath string, start int64, state htmlLogState) (string, int64, bool, error) {
+	chunk, err := c.fetchHTMLLog(ctx, buildPath, start, state)
+	if err != nil {
+		return "", 0, false, err
+	}
+	return htmlLogText(string(chunk.data)), chunk.next, chunk.more, nil
+}
+
+// GetAnnotatedLog fetches a chunk of console output like GetProgressiveLog,
+// from progressiveHtml so the Pipeline annotations survive: each segment of
+// text carries the flow node that wrote it, and the nodes announced in the
+// chunk tell which stage those belong to (see StageIndex). Builds that are
+// not Pipelines come back as a single segment without a node.
+func (c *Client) GetAnnotatedLog(ctx context.Context, buildURL, fullName string, buildNumber int, start int64) (AnnotatedLog, error) {
+	if start < 0 {
+		start = 0
+	}
+
+	buildPath, err := c.progressiveBuildPath(buildURL, fullName, buildNumber)
+	if err != nil {
+		return AnnotatedLog{}, err
+	}
+	state, _ := c.htmlLogState(buildPath)
+	chunk, err := c.fetchHTMLLog(ctx, buildPath, start, state)
+	if err != nil {
+		return AnnotatedLog{}, err
+	}
+
+	segments, nodes := decodeHTMLLog(string(chunk.data))
+	return AnnotatedLog{Segments: segments, Nodes: nodes, Next: chunk.next, More: chunk.more}, nil
+}
+
+// fetchHTMLLog reads a chunk of progressiveHtml, sending back the annotator
+// state of the previous chunk, and remembers the state for the next one.
+func (c *Client) fetchHTMLLog(ctx context.Context, buildPath string, start int64, state htmlLogState) (progressiveChunk, error) {
+	headers := map[string]string{"Accept": "text/html"}
+	// The annotator state only applies where the last chunk ended.
+	if state.annotator != "" && state.next == start {
+		headers["X-ConsoleAnnotator"] = state.annotator
+	}
+	chunk, err := c.fetchProgressiveLog(ctx, fmt.Sprintf("%s/logText/progressiveHtml?start=%d", buildPath, start), start, headers)
+	if err != nil {
+		return progressiveChunk{}, err
+	}
+
+	c.htmlLogsMu.Lock()
+	if c.htmlLogs == nil {
+		c.htmlLogs = make(map[string]htmlLogState)
+	}
+	c.htmlLogs[buildPath] = htmlLogState{next: chunk.next, annotator: chunk.annotator}
+	c.htmlLogsMu.Unlock()
+
+	return chunk, nil
+}
+
+func (c *Client) htmlLogState(buildPath string) (htmlLogState, bool) {
+	c.htmlLogsMu.Lock()
+	defer c.htmlLogsMu.Unlock()
+	state, ok := c.htmlLogs[buildPath]
+	return state, ok
+}
+
+// progressiveChunk is one response of a progressive log endpoint.
+type progressiveChunk struct {
+	data      []byte
+	next      int64
+	more      bool
+	annotator string
+}
+
+func (c *Client) fetchProgressiveLog(ctx context.Context, logPath string, start int64, headers map[string]string) (progressiveChunk, error) {
+	resp, err := c.doRequest(ctx, http.MethodGet, logPath, nil, headers)
+	if err != nil {
+		return progressiveChunk{}, fmt.Errorf("failed to fetch progressive console log: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return progressiveChunk{}, fmt.Errorf("failed to fetch progressive console log: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return progressiveChunk{}, fmt.Errorf("failed to read progressive console log: %w", err)
+	}
+
+	chunk := progressiveChunk{
+		data:      data,
+		next:      start + int64(len(data)),
+		more:      strings.EqualFold(resp.Header.Get("X-More-Data"), "true"),
+		annotator: resp.Header.Get("X-ConsoleAnnotator"),
+	}
+	if sizeHeader := resp.Header.Get("X-Text-Size"); sizeHeader != "" {
+		if parsed, parseErr := strconv.ParseInt(sizeHeader, 10, 64); parseErr == nil && parsed >= 0 {
+			chunk.next = parsed
+		}
+	}
+	return chunk, nil
+}
+
+func (c *Client) progressiveLogPath(buildURL, fullName string, buildNumber int, start int64) (string, error) {
+	buildPath, err := c.progressiveBuildPath(buildURL, fullName, buildNumber)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/logText/progressiveText?start=%d", buildPath, start), nil
+}
+
+func (c *Client) progressiveBuildPath(buildURL, fullName string, buildNumber int) (string, error) {
+	buildPath, err := c.resolveBuildPath(buildURL, fullName, buildNumber)
+	if err != nil {
+		return "", err
+	}
+	buildPath = strings.TrimSuffix(buildPath, "/")
+	if buildPath == "" {
+		return "", fmt.Errorf("resolved build path is empty")
+	}
+	return buildPath, nil
+}
+
+func (c *Client) resolveBuildPath(buildURL, fullName string, buildNumber int) (string, error) {
+	if trimmed := strings.TrimSpace(buildURL); trimmed != "" {
+		if path, err := c.relativeBuildPath(trimmed); err == nil {
+			return strings.TrimSuffix(path, "/"), nil
+		}
+	}
+
+	if fullName == "" {
+		return "", fmt.Errorf("job name must not be empty")
+	}
+	if buildNumber <= 0 {
+		return "", fmt.Errorf("build number must be greater than zero")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return "", fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	return fmt.Sprintf("%s/%d", strings.TrimSuffix(jobPath, "/"), buildNumber), nil
+}
+
+func (c *Client) relativeBuildPath(buildURL string) (string, error) {
+	trimmed := strings.TrimSpace(buildURL)
+	if trimmed == "" {
+		return "", fmt.Errorf("build URL must not be empty")
+	}
+
+	parsed, err := url.Parse(trimmed)
+	if err != nil {
+		return "", fmt.Errorf("invalid build URL %q: %w", trimmed, err)
+	}
+
+	// If the URL was already relative, parsed.Scheme and parsed.Host will be empty.
+	if parsed.Scheme == "" && parsed.Host == "" {
+		path := parsed.Path
+		if path == "" {
+			return "", fmt.Errorf("build URL %q does not contain a path", trimmed)
+		}
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		return path, nil
+	}
+
+	baseURL, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid Jenkins base URL %q: %w", c.BaseURL, err)
+	}
+
+	relPath := parsed.Path
+	if relPath == "" {
+		return "", fmt.Errorf("build URL %q does not contain a path", trimmed)
+	}
+
+	basePath := strings.TrimSuffix(baseURL.Path, "/")
+	if basePath != "" && strings.HasPrefix(relPath, basePath) {
+		relPath = strings.TrimPrefix(relPath, basePath)
+	}
+
+	if relPath == "" {
+		return "", fmt.Errorf("build URL %q does not contain a path", trimmed)
+	}
+
+	if !strings.HasPrefix(relPath, "/") {
+		relPath = "/" + relPath
+	}
+
+	return relPath, nil
+}
+
+// GetBuild fetches build details for the given job. When number <= 0 it returns the last (possibly running) build.
+func (c *Client) GetBuild(ctx context.Context, fullName string, number int) (*Build, error) {
+	if fullName == "" {
+		return nil, fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	var path string
+	if number <= 0 {
+		path = fmt.Sprintf("%s/lastBuild/api/json", jobPath)
+	} else {
+		path = fmt.Sprintf("%s/%d/api/json", jobPath, number)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch build details: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch build details: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var build Build
+	if err := json.NewDecoder(resp.Body).Decode(&build); err != nil {
+		return nil, fmt.Errorf("failed to decode build details: %w", err)
+	}
+
+	return &build, nil
+}
+
+// GetLastSuccessfulBuild fetches the newest build of a job that succeeded,
+// unstable builds included, or nil when there is none.
+func (c *Client) GetLastSuccessfulBuild(ctx context.Context, fullName string) (*Build, error) {
+	return c.getPermalinkBuild(ctx, fullName, "lastSuccessfulBuild")
+}
+
+// GetLastFailedBuild fetches the newest build of a job that failed, or nil
+// when there is none.
+func (c *Client) GetLastFailedBuild(ctx context.Context, fullName string) (*Build, error) {
+	return c.getPermalinkBuild(ctx, fullName, "lastFailedBuild")
+}
+
+// GetLastStableBuild fetches the newest build of a job that succeeded
+// without being unstable, or nil when there is none.
+func (c *Client) GetLastStableBuild(ctx context.Context, fullName string) (*Build, error) {
+	return c.getPermalinkBuild(ctx, fullName, "lastStableBuild")
+}
+
+// getPermalinkBuild fetches the build a job permalink such as
+// "lastSuccessfulBuild" points to. Jenkins answers 404 while the permalink
+// points nowhere, which is reported as a nil build.
+func (c *Client) getPermalinkBuild(ctx context.Context, fullName, permalink string) (*Build, error) {
+	if fullName == "" {
+		return nil, fmt.Errorf("job name must not be empty")
+	}
+
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, fmt.Sprintf("%s/%s/api/json", jobPath, permalink), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", permalink, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch %s: status %d, body: %s", permalink, resp.StatusCode, string(body))
+	}
+
+	var build Build
+	if err := json.NewDecoder(resp.Body).Decode(&build); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", permalink, err)
+	}
+	return &build, nil
+}
+
+// GetPipelineRuns fetches the recent runs of a Pipeline job, newest first,
+// from the Pipeline Stage View plugin's /wfapi/runs endpoint.
+func (c *Client) GetPipelineRuns(ctx context.Context, fullName string) ([]PipelineRun, error) {
+	if fullName == "" {
+		return nil, fmt.Errorf("job name must not be empty")
 	}
 
 	jobPath := buildJobAPIPath(fullName)
 	if jobPath == "" {
-		return fmt.Errorf("invalid job path for %q", fullName)
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
 	}
 
-	path := fmt.Sprintf("%s/%d/stop", jobPath, buildNumber)
-	resp, err := c.doRequest(http.MethodPost, path, nil, nil)
+	resp, err := c.doRequest(ctx, http.MethodGet, jobPath+"/wfapi/runs", nil, nil)
 	if err != nil {
-		return fmt.Errorf("failed to abort build: %w", err)
+		return nil, fmt.Errorf("failed to fetch pipeline runs: %w", err)
 	}
 	defer resp.Body.Close()
 
-	switch resp.StatusCode {
-	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusFound:
-		return nil
-	default:
+	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to abort build: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to fetch pipeline runs: status %d, body: %s", resp.StatusCode, string(body))
 	}
+
+	var runs []PipelineRun
+	if err := json.NewDecoder(resp.Body).Decode(&runs); err != nil {
+		return nil, fmt.Errorf("failed to decode pipeline runs: %w", err)
+	}
+	return runs, nil
 }
 
-// GetConsoleLog fetches the full console output for a specific build.
-func (c *Client) GetConsoleLog(fullName string, buildNumber int) (string, error) {
+// GetPipelineRunStages fetches one Pipeline build with per-stage status and
+// durations from /wfapi/describe. A 404 means the job is not a Pipeline or the
+// Stage View plugin is missing.
+func (c *Client) GetPipelineRunStages(ctx context.Context, fullName string, buildNumber int) (*PipelineRun, error) {
 	if fullName == "" {
-		return "", fmt.Errorf("job name must not be empty")
+		return nil, fmt.Errorf("job name must not be empty")
 	}
 	if buildNumber <= 0 {
-		return "", fmt.Errorf("build number must be greater than zero")
+		return nil, fmt.Errorf("build number must be greater than zero")
 	}
 
 	jobPath := buildJobAPIPath(fullName)
 	if jobPath == "" {
-		return "", fmt.Errorf("invalid job path for %q", fullName)
+		return nil, fmt.Errorf("invalid job path for %q", fullName)
 	}
 
-	path := fmt.Sprintf("%s/%d/consoleText", jobPath, buildNumber)
-	resp, err := c.doRequest(http.MethodGet, path, nil, map[string]string{
-		"Accept": "text/plain",
-	})
+	path := fmt.Sprintf("%s/%d/wfapi/describe", jobPath, buildNumber)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch console log: %w", err)
+		return nil, fmt.Errorf("failed to fetch pipeline stages: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to fetch console log: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to fetch pipeline stages: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read console log: %w", err)
+	var run PipelineRun
+	if err := json.NewDecoder(resp.Body).Decode(&run); err != nil {
+		return nil, fmt.Errorf("failed to decode pipeline stages: %w", err)
 	}
-	return string(data), nil
+	return &run, nil
 }
 
-// GetProgressiveLog fetches a chunk of console output using Jenkins' progressive log API.
-// It returns the new content, the next offset to request, and whether more data is available.
-// The lookup prefers the provided buildURL (if not empty) and falls back to job full name + build number.
-func (c *Client) GetProgressiveLog(buildURL, fullName string, buildNumber int, start int64) (string, int64, bool, error) {
-	if start < 0 {
-		start = 0
+// errBlueOceanMissing reports that the Blue Ocean plugin is not installed.
+var errBlueOceanMissing = errors.New("blue ocean REST API not available")
+
+// GetPipelineGraph fetches the stages and parallel branches of one Pipeline
+// build. It prefers the Blue Ocean REST API and falls back to the stage list
+// from wfapi when Blue Ocean is not installed or fails.
+func (c *Client) GetPipelineGraph(ctx context.Context, fullName string, buildNumber int) (*PipelineGraph, error) {
+	if !c.blueOceanMissing.Load() {
+		var nodes []PipelineNode
+		err := c.getBlueOcean(ctx, blueOceanRunPath(fullName, buildNumber)+"/nodes/", "pipeline nodes", &nodes)
+		if err == nil {
+			c.blueOceanFound.Store(true)
+			return &PipelineGraph{Source: "blueocean", Nodes: nodes}, nil
+		}
+		if errors.Is(err, errBlueOceanMissing) && !c.blueOceanFound.Load() {
+			c.probeBlueOcean(ctx)
+		}
 	}
 
-	logPath, err := c.progressiveLogPath(buildURL, fullName, buildNumber, start)
+	run, err := c.GetPipelineRunStages(ctx, fullName, buildNumber)
 	if err != nil {
-		return "", 0, false, err
+		return nil, err
+	}
+	graph := &PipelineGraph{Source: "wfapi", Nodes: make([]PipelineNode, 0, len(run.Stages))}
+	for _, stage := range run.Stages {
+		graph.Nodes = append(graph.Nodes, pipelineNodeFromStage(stage))
+	}
+	return graph, nil
+}
+
+// GetPipelineSteps fetches the steps of one node (stage or parallel branch)
+// of a Pipeline build from the Blue Ocean REST API.
+func (c *Client) GetPipelineSteps(ctx context.Context, fullName string, buildNumber int, nodeID string) ([]PipelineStep, error) {
+	if nodeID == "" {
+		return nil, fmt.Errorf("node id must not be empty")
+	}
+
+	var steps []PipelineStep
+	path := fmt.Sprintf("%s/nodes/%s/steps/", blueOceanRunPath(fullName, buildNumber), url.PathEscape(nodeID))
+	if err := c.getBlueOcean(ctx, path, "pipeline steps", &steps); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// GetPipelineStepLog fetches the console output of a single Pipeline step
+// from the Blue Ocean REST API.
+func (c *Client) GetPipelineStepLog(ctx context.Context, fullName string, buildNumber int, nodeID, stepID string) (string, error) {
+	if nodeID == "" || stepID == "" {
+		return "", fmt.Errorf("node and step ids must not be empty")
+	}
+	runPath := blueOceanRunPath(fullName, buildNumber)
+	if runPath == "" {
+		return "", fmt.Errorf("invalid pipeline run %q #%d", fullName, buildNumber)
 	}
 
-	resp, err := c.doRequest(http.MethodGet, logPath, nil, map[string]string{
+	path := fmt.Sprintf("%s/nodes/%s/steps/%s/log/", runPath, url.PathEscape(nodeID), url.PathEscape(stepID))
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, map[string]string{
 		"Accept": "text/plain",
 	})
 	if err != nil {
-		return "", 0, false, fmt.Errorf("failed to fetch progressive console log: %w", err)
+		return "", fmt.Errorf("failed to fetch step log: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", 0, false, fmt.Errorf("failed to fetch progressive console log: status %d, body: %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("failed to fetch step log: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", 0, false, fmt.Errorf("failed to read progressive console log: %w", err)
+		return "", fmt.Errorf("failed to read step log: %w", err)
 	}
+	return string(data), nil
+}
 
-	nextOffset := start + int64(len(data))
-	if sizeHeader := resp.Header.Get("X-Text-Size"); sizeHeader != "" {
-		if parsed, parseErr := strconv.ParseInt(sizeHeader, 10, 64); parseErr == nil && parsed >= 0 {
-			nextOffset = parsed
-		}
+// probeBlueOcean tells whether a 404 from Blue Ocean meant the plugin is
+// missing or just that it doesn't know the run, by asking for the
+// organization every pipeline lives in.
+func (c *Client) probeBlueOcean(ctx context.Context) {
+	resp, err := c.doRequest(ctx, http.MethodGet, blueOceanOrganizationPath+"/", nil, nil)
+	if err != nil {
+		return
 	}
-
-	more := false
-	if strings.EqualFold(resp.Header.Get("X-More-Data"), "true") {
-		more = true
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		c.blueOceanFound.Store(true)
+	case http.StatusNotFound:
+		c.blueOceanMissing.Store(true)
 	}
-
-	return string(data), nextOffset, more, nil
 }
 
-func (c *Client) progressiveLogPath(buildURL, fullName string, buildNumber int, start int64) (string, error) {
-	buildPath, err := c.resolveBuildPath(buildURL, fullName, buildNumber)
+// getBlueOcean decodes a Blue Ocean REST response into out. A 404 is reported
+// as errBlueOceanMissing so callers can fall back to the classic APIs.
+func (c *Client) getBlueOcean(ctx context.Context, path, what string, out any) error {
+	if !strings.HasPrefix(path, blueOceanPipelinesPath+"/") {
+		// blueOceanRunPath returned "" for an empty job name or build number.
+		return fmt.Errorf("invalid pipeline run for %s", what)
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
 	if err != nil {
-		return "", err
+		return fmt.Errorf("failed to fetch %s: %w", what, err)
 	}
-	buildPath = strings.TrimSuffix(buildPath, "/")
-	if buildPath == "" {
-		return "", fmt.Errorf("resolved build path is empty")
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("failed to fetch %s: %w", what, errBlueOceanMissing)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to fetch %s: status %d, body: %s", what, resp.StatusCode, string(body))
 	}
-	return fmt.Sprintf("%s/logText/progressiveText?start=%d", buildPath, start), nil
-}
 
-func (c *Client) resolveBuildPath(buildURL, fullName string, buildNumber int) (string, error) {
-	if trimmed := strings.TrimSpace(buildURL); trimmed != "" {
-		if path, err := c.relativeBuildPath(trimmed); err == nil {
-			return strings.TrimSuffix(path, "/"), nil
-		}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", what, err)
 	}
+	return nil
+}
 
+// GetJobConfig retrieves the raw job configuration (XML).
+func (c *Client) GetJobConfig(ctx context.Context, fullName string) (string, error) {
 	if fullName == "" {
 		return "", fmt.Errorf("job name must not be empty")
 	}
-	if buildNumber <= 0 {
-		return "", fmt.Errorf("build number must be greater than zero")
-	}
 
 	jobPath := buildJobAPIPath(fullName)
 	if jobPath == "" {
 		return "", fmt.Errorf("invalid job path for %q", fullName)
 	}
 
-	return fmt.Sprintf("%s/%d", strings.TrimSuffix(jobPath, "/"), buildNumber), nil
-}
+	path := fmt.Sprintf("%s/config.xml", jobPath)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, map[string]string{
+		"Accept": "application/xml",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch job config: %w", err)
+	}
+	defer resp.Body.Close()
 
-func (c *Client) relativeBuildPath(buildURL string) (string, error) {
-	trimmed := strings.TrimSpace(buildURL)
-	if trimmed == "" {
-		return "", fmt.Errorf("build URL must not be empty")
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to fetch job config: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	parsed, err := url.Parse(trimmed)
+	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("invalid build URL %q: %w", trimmed, err)
+		return "", fmt.Errorf("failed to read job config: %w", err)
 	}
+	return string(data), nil
+}
 
-	// If the URL was already relative, parsed.Scheme and parsed.Host will be empty.
-	if parsed.Scheme == "" && parsed.Host == "" {
-		path := parsed.Path
-		if path == "" {
-			return "", fmt.Errorf("build URL %q does not contain a path", trimmed)
-		}
-		if !strings.HasPrefix(path, "/") {
-			path = "/" + path
-		}
-		return path, nil
+// UpdateJobConfig replaces the job configuration with configXML, e.g. after
+// editing what GetJobConfig returned. Jenkins validates the XML and answers
+// 500 with the parse error when it cannot load it.
+func (c *Client) UpdateJobConfig(ctx context.Context, fullName, configXML string) error {
+	if fullName == "" {
+		return fmt.Errorf("job name must not be empty")
 	}
 
-	baseURL, err := url.Parse(c.BaseURL)
+	jobPath := buildJobAPIPath(fullName)
+	if jobPath == "" {
+		return fmt.Errorf("invalid job path for %q", fullName)
+	}
+
+	path := fmt.Sprintf("%s/config.xml", jobPath)
+	resp, err := c.doRequest(ctx, http.MethodPost, path, strings.NewReader(configXML), map[string]string{
+		"Content-Type": "application/xml",
+	})
 	if err != nil {
-		return "", fmt.Errorf("invalid Jenkins base URL %q: %w", c.BaseURL, err)
+		return fmt.Errorf("failed to update job config: %w", err)
 	}
+	defer resp.Body.Close()
 
-	relPath := parsed.Path
-	if relPath == "" {
-		return "", fmt.Errorf("build URL %q does not contain a path", trimmed)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update job config: status %d, body: %s", resp.StatusCode, string(body))
 	}
+	return nil
+}
 
-	basePath := strings.TrimSuffix(baseURL.Path, "/")
-	if basePath != "" && strings.HasPrefix(relPath, basePath) {
-		relPath = strings.TrimPrefix(relPath, basePath)
+// relationsTree selects the jobs of a folder with their upstream and
+// downstream projects.
+var relationsTree = tree.Must(tree.New("jobs").Fields("name", "fullName", "url", "color", "_class").Nested(
+	relatedProjectFields.As("upstreamProjects"),
+	relatedProjectFields.As("downstreamProjects"),
+))
+
+var relatedProjectFields = tree.Fields("name", "fullName", "url", "color")
+
+// GetFolderRelations fetches the upstream and downstream projects of every job directly
+// inside the given folder. An empty folder name means the top level of the instance.
+func (c *Client) GetFolderRelations(ctx context.Context, folderFullName string) ([]JobRelations, error) {
+	basePath := ""
+	if strings.TrimSpace(folderFullName) != "" {
+		basePath = buildJobAPIPath(folderFullName)
+		if basePath == "" {
+			return nil, fmt.Errorf("invalid folder path for %q", folderFullName)
+		}
 	}
 
-	if relPath == "" {
-		return "", fmt.Errorf("build URL %q does not contain a path", trimmed)
+	params := url.Values{}
+	params.Set("tree", relationsTree.String())
+	path := fmt.Sprintf("%s/api/json?%s", basePath, params.Encode())
+
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch job relations: %w", err)
 	}
+	defer resp.Body.Close()
 
-	if !strings.HasPrefix(relPath, "/") {
-		relPath = "/" + relPath
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to fetch job relations: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	return relPath, nil
+	var payload struct {
+		Jobs []JobRelations `json:"jobs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode job relations: %w", err)
+	}
+
+	return payload.Jobs, nil
 }
 
-// GetBuild fetches build details for the given job. When number <= 0 it returns the last (possibly running) build.
-func (c *Client) GetBuild(fullName string, number int) (*Build, error) {
-	if fullName == "" {
-		return nil, fmt.Errorf("job name must not be empty")
-	}
+// userTree selects a user's ID and display name.
+var userTree = tree.Must(tree.Fields("id", "fullName"))
 
-	jobPath := buildJobAPIPath(fullName)
-	if jobPath == "" {
-		return nil, fmt.Errorf("invalid job path for %q", fullName)
+// GetUser resolves a Jenkins user ID to its profile. Results are cached for the
+// lifetime of the client; unknown users are cached as a bare ID so repeated lookups
+// for deleted or external accounts do not hit the server again.
+func (c *Client) GetUser(ctx context.Context, id string) (*User, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return nil, fmt.Errorf("user ID must not be empty")
 	}
 
-	var path string
-	if number <= 0 {
-		path = fmt.Sprintf("%s/lastBuild/api/json", jobPath)
-	} else {
-		path = fmt.Sprintf("%s/%d/api/json", jobPath, number)
+	c.usersMu.Lock()
+	if cached, ok := c.users[id]; ok {
+		c.usersMu.Unlock()
+		userCopy := *cached
+		return &userCopy, nil
 	}
+	c.usersMu.Unlock()
 
-	resp, err := c.doRequest(http.MethodGet, path, nil, nil)
+	path := fmt.Sprintf("/user/%s/api/json?tree=%s", url.PathEscape(id), userTree)
+	resp, err := c.doRequest(ctx, http.MethodGet, path, nil, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch build details: %w", err)
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	var user User
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+			return nil, fmt.Errorf("failed to decode user: %w", err)
+		}
+		if user.ID == "" {
+			user.ID = id
+		}
+	case http.StatusNotFound:
+		user = User{ID: id}
+	default:
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to fetch build details: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to fetch user: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	var build Build
-	if err := json.NewDecoder(resp.Body).Decode(&build); err != nil {
-		return nil, fmt.Errorf("failed to decode build details: %w", err)
+	c.usersMu.Lock()
+	if c.users == nil {
+		c.users = make(map[string]*User)
 	}
+	cached := user
+	c.users[id] = &cached
+	c.usersMu.Unlock()
 
-	return &build, nil
+	return &user, nil
 }
 
-// GetJobConfig retrieves the raw job configuration (XML).
-func (c *Client) GetJobConfig(fullName string) (string, error) {
-	if fullName == "" {
-		return "", fmt.Errorf("job name must not be empty")
-	}
+const apiTokenDescriptorPath = "/me/descriptorByName/jenkins.security.ApiTokenProperty"
 
-	jobPath := buildJobAPIPath(fullName)
-	if jobPath == "" {
-		return "", fmt.Errorf("invalid job path for %q", fullName)
-	}
+// GenerateAPIToken creates a new API token named name for the authenticated user.
+// The token value is only returned once by Jenkins.
+func (c *Client) GenerateAPIToken(ctx context.Context, name string) (*APIToken, error) {
+	form := url.Values{}
+	form.Set("newTokenName", strings.TrimSpace(name))
 
-	path := fmt.Sprintf("%s/config.xml", jobPath)
-	resp, err := c.doRequest(http.MethodGet, path, nil, map[string]string{
-		"Accept": "application/xml",
-	})
+	resp, err := c.doRequest(
+		ctx,
+		http.MethodPost,
+		apiTokenDescriptorPath+"/generateNewToken",
+		strings.NewReader(form.Encode()),
+		map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+	)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch job config: %w", err)
+		return nil, fmt.Errorf("failed to generate API token: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to fetch job config: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("failed to generate API token: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
-	data, err := io.ReadAll(resp.Body)
+	var result struct {
+		Status string   `json:"status"`
+		Data   APIToken `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode API token: %w", err)
+	}
+	if result.Status != "ok" || result.Data.Value == "" {
+		return nil, fmt.Errorf("failed to generate API token: unexpected status %q", result.Status)
+	}
+	return &result.Data, nil
+}
+
+// RevokeAPIToken revokes the authenticated user's API token with the given UUID.
+func (c *Client) RevokeAPIToken(ctx context.Context, uuid string) error {
+	uuid = strings.TrimSpace(uuid)
+	if uuid == "" {
+		return fmt.Errorf("token UUID must not be empty")
+	}
+
+	form := url.Values{}
+	form.Set("tokenUuid", uuid)
+
+	resp, err := c.doRequest(
+		ctx,
+		http.MethodPost,
+		apiTokenDescriptorPath+"/revoke",
+		strings.NewReader(form.Encode()),
+		map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+	)
 	if err != nil {
-		return "", fmt.Errorf("failed to read job config: %w", err)
+		return fmt.Errorf("failed to revoke API token: %w", err)
 	}
-	return string(data), nil
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to revoke API token: status %d, body: %s", resp.StatusCode, string(body))
+	}
+	return nil
 }
 
 // buildJobAPIPath converts a Jenkins job full name (with / separators) into the /job/... API path.
@@ -735,3 +3029,55 @@ func buildJobAPIPath(fullName string) string {
 
 	return builder.String()
 }
+
+// blueOceanPipelinesPath is the root of the Blue Ocean pipelines REST API.
+const (
+	blueOceanOrganizationPath = "/blue/rest/organizations/jenkins"
+	blueOceanPipelinesPath    = blueOceanOrganizationPath + "/pipelines"
+)
+
+// blueOceanRunPath converts a job full name and build number into the Blue
+// Ocean run path, nesting folders as /pipelines/<folder>/pipelines/<job>.
+func blueOceanRunPath(fullName string, buildNumber int) string {
+	if buildNumber <= 0 {
+		return ""
+	}
+
+	var builder strings.Builder
+	for _, segment := range strings.Split(fullName, "/") {
+		segment = strings.TrimSpace(segment)
+		if segment == "" {
+			continue
+		}
+		if builder.Len() == 0 {
+			builder.WriteString(blueOceanPipelinesPath)
+		} else {
+			builder.WriteString("/pipelines")
+		}
+		builder.WriteString("/")
+		builder.WriteString(url.PathEscape(segment))
+	}
+	if builder.Len() == 0 {
+		return ""
+	}
+
+	fmt.Fprintf(&builder, "/runs/%d", buildNumber)
+	return builder.String()
+}
+
+// buildArtifactPath escapes an artifact's relative path segment by segment,
+// rejecting segments that would climb out of the artifact directory.
+func buildArtifactPath(relativePath string) string {
+	var builder strings.Builder
+	for _, segment := range strings.Split(relativePath, "/") {
+		if segment == "" || segment == "." {
+			continue
+		}
+		if segment == ".." {
+			return ""
+		}
+		builder.WriteString("/")
+		builder.WriteString(url.PathEscape(segment))
+	}
+	return builder.String()
+}
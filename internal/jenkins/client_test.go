@@ -1,7 +1,23 @@
 package jenkins
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestBuildJobAPIPath(t *testing.T) {
@@ -91,3 +107,1586 @@ func TestBuildJobAPIPath(t *testing.T) {
 		})
 	}
 }
+
+func TestGetAllJobsRevalidatesWithETag(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"jobs":[{"name":"build","fullName":"build"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	for i := 0; i < 2; i++ {
+		jobs, err := client.GetAllJobs(context.Background())
+		if err != nil {
+			t.Fatalf("GetAllJobs() call %d error = %v", i+1, err)
+		}
+		if len(jobs) != 1 || jobs[0].FullName != "build" {
+			t.Fatalf("GetAllJobs() call %d = %+v, want the cached build job", i+1, jobs)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("server saw %d requests, want 2", requests)
+	}
+}
+
+func TestGetAllJobsTreeDepth(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("tree")
+		w.Write([]byte(`{"jobs":[]}`))
+	}))
+	defer server.Close()
+
+	for depth, want := range map[int]int{0: defaultTreeDepth, 1: 1, 5: 5} {
+		client := NewClient(Credentials{URL: server.URL, TreeDepth: depth})
+		if _, err := client.GetAllJobs(context.Background()); err != nil {
+			t.Fatalf("GetAllJobs() with depth %d error = %v", depth, err)
+		}
+		if got := strings.Count(query, "jobs["); got != want {
+			t.Errorf("depth %d selected %d levels of jobs in %q, want %d", depth, got, query, want)
+		}
+	}
+}
+
+func TestGetBuildQueueWithoutValidatorsIsNotCached(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			t.Errorf("unexpected conditional headers on %s", r.URL)
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetBuildQueue(context.Background()); err != nil {
+			t.Fatalf("GetBuildQueue() error = %v", err)
+		}
+	}
+}
+
+func TestGetFolderJobs(t *testing.T) {
+	tests := []struct {
+		name     string
+		folder   string
+		wantPath string
+	}{
+		{
+			name:     "top level",
+			folder:   "",
+			wantPath: "/api/json",
+		},
+		{
+			name:     "nested folder",
+			folder:   "team/services/backend",
+			wantPath: "/job/team/job/services/job/backend/api/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.Write([]byte(`{"jobs":[{"name":"api","fullName":"team/services/backend/api"}]}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			jobs, err := client.GetFolderJobs(context.Background(), tt.folder)
+			if err != nil {
+				t.Fatalf("GetFolderJobs(%q) error = %v", tt.folder, err)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("GetFolderJobs(%q) requested %q, want %q", tt.folder, gotPath, tt.wantPath)
+			}
+			if len(jobs) != 1 || jobs[0].Name != "api" {
+				t.Errorf("GetFolderJobs(%q) = %+v, want the api job", tt.folder, jobs)
+			}
+		})
+	}
+}
+
+func TestGetBuildsRequestsRange(t *testing.T) {
+	var gotTree string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTree = r.URL.Query().Get("tree")
+		w.Write([]byte(`{"allBuilds":[{"number":80},{"number":79}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	builds, err := client.GetBuilds(context.Background(), "folder/app", 20, 2)
+	if err != nil {
+		t.Fatalf("GetBuilds() error = %v", err)
+	}
+	if want := "allBuilds[" + buildFields.String() + "]{20,22}"; gotTree != want {
+		t.Errorf("GetBuilds() tree = %q, want %q", gotTree, want)
+	}
+	if len(builds) != 2 || builds[0].Number != 80 {
+		t.Errorf("GetBuilds() = %+v, want builds 80 and 79", builds)
+	}
+}
+
+func TestGetBuildCount(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "rotated builds", body: `{"allBuilds":[{"number":80},{"number":79},{"number":78}]}`, want: 3},
+		{name: "deleted builds", body: `{"allBuilds":[{"number":80},{"number":41}]}`, want: 2},
+		{name: "never built", body: `{"allBuilds":[]}`, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			got, err := client.GetBuildCount(context.Background(), "folder/app")
+			if err != nil {
+				t.Fatalf("GetBuildCount() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("GetBuildCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCancelQueueItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "no content", status: http.StatusNoContent},
+		{name: "already gone", status: http.StatusNotFound},
+		{name: "forbidden", status: http.StatusForbidden, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMethod, gotQuery string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/crumbIssuer/api/json" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				gotMethod, gotQuery = r.Method, r.URL.RawQuery
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			err := client.CancelQueueItem(context.Background(), 42)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CancelQueueItem() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotMethod != http.MethodPost || gotQuery != "id=42" {
+				t.Errorf("CancelQueueItem() sent %s ?%s, want POST ?id=42", gotMethod, gotQuery)
+			}
+		})
+	}
+}
+
+func TestQueueIDFromLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		want     int
+	}{
+		{name: "absolute url", location: "https://ci.example.com/queue/item/1234/", want: 1234},
+		{name: "without trailing slash", location: "https://ci.example.com/queue/item/7", want: 7},
+		{name: "under a context path", location: "https://example.com/jenkins/queue/item/55/", want: 55},
+		{name: "missing header", location: "", want: 0},
+		{name: "not a queue item", location: "https://ci.example.com/job/app/", want: 0},
+		{name: "non numeric id", location: "https://ci.example.com/queue/item/abc/", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := queueIDFromLocation(tt.location); got != tt.want {
+				t.Errorf("queueIDFromLocation(%q) = %d, want %d", tt.location, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForBuildNumber(t *testing.T) {
+	previous := queueWaitInterval
+	queueWaitInterval = time.Millisecond
+	defer func() { queueWaitInterval = previous }()
+
+	tests := []struct {
+		name      string
+		responses []string
+		want      int
+		wantErr   bool
+	}{
+		{
+			name:      "starts after waiting",
+			responses: []string{`{"id":9}`, `{"id":9}`, `{"id":9,"executable":{"number":41}}`},
+			want:      41,
+		},
+		{
+			name:      "cancelled while waiting",
+			responses: []string{`{"id":9}`, `{"id":9,"cancelled":true}`},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			polls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/queue/item/9/api/json" {
+					t.Errorf("unexpected request to %s", r.URL.Path)
+				}
+				w.Write([]byte(tt.responses[min(polls, len(tt.responses)-1)]))
+				polls++
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			got, err := client.WaitForBuildNumber(context.Background(), 9)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WaitForBuildNumber() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("WaitForBuildNumber() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPipelineRunStages(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte(`{"id":"12","status":"FAILED","stages":[` +
+			`{"id":"6","name":"Build","status":"SUCCESS","durationMillis":61000},` +
+			`{"id":"15","name":"Test","status":"FAILED","durationMillis":4000}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	run, err := client.GetPipelineRunStages(context.Background(), "folder/app", 12)
+	if err != nil {
+		t.Fatalf("GetPipelineRunStages() error = %v", err)
+	}
+	if want := "/job/folder/job/app/12/wfapi/describe"; gotPath != want {
+		t.Errorf("GetPipelineRunStages() requested %q, want %q", gotPath, want)
+	}
+	if len(run.Stages) != 2 || run.Stages[1].Name != "Test" || run.Stages[1].GetStatus() != StatusFailed {
+		t.Errorf("GetPipelineRunStages() stages = %+v, want Build then a failed Test", run.Stages)
+	}
+	if got := run.Stages[0].GetDuration(); got != 61*time.Second {
+		t.Errorf("Build stage duration = %v, want 1m1s", got)
+	}
+}
+
+func TestGetPipelineRuns(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Write([]byte(`[{"id":"12","status":"IN_PROGRESS","stages":[]},{"id":"11","status":"SUCCESS","stages":[]}]`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	runs, err := client.GetPipelineRuns(context.Background(), "app")
+	if err != nil {
+		t.Fatalf("GetPipelineRuns() error = %v", err)
+	}
+	if gotPath != "/job/app/wfapi/runs" {
+		t.Errorf("GetPipelineRuns() requested %q, want /job/app/wfapi/runs", gotPath)
+	}
+	if len(runs) != 2 || runs[0].GetStatus() != StatusBuilding {
+		t.Errorf("GetPipelineRuns() = %+v, want a running build then a finished one", runs)
+	}
+}
+
+func TestBlueOceanRunPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		fullName    string
+		buildNumber int
+		want        string
+	}{
+		{name: "top level job", fullName: "app", buildNumber: 3, want: "/blue/rest/organizations/jenkins/pipelines/app/runs/3"},
+		{name: "nested folders", fullName: "team/services/api", buildNumber: 12, want: "/blue/rest/organizations/jenkins/pipelines/team/pipelines/services/pipelines/api/runs/12"},
+		{name: "spaces are escaped", fullName: "my job", buildNumber: 1, want: "/blue/rest/organizations/jenkins/pipelines/my%20job/runs/1"},
+		{name: "empty name", fullName: "", buildNumber: 1, want: ""},
+		{name: "no build number", fullName: "app", buildNumber: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := blueOceanRunPath(tt.fullName, tt.buildNumber); got != tt.want {
+				t.Errorf("blueOceanRunPath(%q, %d) = %q, want %q", tt.fullName, tt.buildNumber, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPipelineGraph(t *testing.T) {
+	tests := []struct {
+		name      string
+		blueOcean bool
+		// runUnknown makes Blue Ocean, though installed, answer 404 for the run.
+		runUnknown   bool
+		wantSource   string
+		wantRequests []string
+	}{
+		{
+			name:         "blue ocean installed",
+			blueOcean:    true,
+			wantSource:   "blueocean",
+			wantRequests: []string{"/blue/rest/organizations/jenkins/pipelines/app/runs/5/nodes/"},
+		},
+		{
+			name:       "falls back to wfapi",
+			wantSource: "wfapi",
+			wantRequests: []string{
+				"/blue/rest/organizations/jenkins/pipelines/app/runs/5/nodes/",
+				"/blue/rest/organizations/jenkins/",
+				"/job/app/5/wfapi/describe",
+				// Blue Ocean is not asked again once it turned out missing.
+				"/job/app/5/wfapi/describe",
+			},
+		},
+		{
+			name:       "blue ocean without the run",
+			runUnknown: true,
+			wantSource: "wfapi",
+			wantRequests: []string{
+				"/blue/rest/organizations/jenkins/pipelines/app/runs/5/nodes/",
+				"/blue/rest/organizations/jenkins/",
+				"/job/app/5/wfapi/describe",
+				// One unknown run doesn't rule Blue Ocean out for the others.
+				"/blue/rest/organizations/jenkins/pipelines/app/runs/5/nodes/",
+				"/job/app/5/wfapi/describe",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.URL.Path)
+				switch {
+				case r.URL.Path == "/job/app/5/wfapi/describe":
+					w.Write([]byte(`{"id":"5","stages":[{"id":"6","name":"Build","status":"IN_PROGRESS"}]}`))
+				case tt.runUnknown && r.URL.Path == "/blue/rest/organizations/jenkins/":
+					w.Write([]byte(`{"name":"jenkins"}`))
+				case tt.blueOcean:
+					w.Write([]byte(`[{"id":"6","displayName":"Build","state":"RUNNING","type":"STAGE"}]`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			calls := 1
+			if !tt.blueOcean {
+				calls = 2
+			}
+			for i := 0; i < calls; i++ {
+				graph, err := client.GetPipelineGraph(context.Background(), "app", 5)
+				if err != nil {
+					t.Fatalf("GetPipelineGraph() error = %v", err)
+				}
+				if graph.Source != tt.wantSource {
+					t.Errorf("GetPipelineGraph() source = %q, want %q", graph.Source, tt.wantSource)
+				}
+				if len(graph.Nodes) != 1 || graph.Nodes[0].DisplayName != "Build" || graph.Nodes[0].GetStatus() != StatusBuilding {
+					t.Errorf("GetPipelineGraph() nodes = %+v, want a running Build stage", graph.Nodes)
+				}
+			}
+			if strings.Join(requests, " ") != strings.Join(tt.wantRequests, " ") {
+				t.Errorf("requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDownloadArtifact(t *testing.T) {
+	const content = "binary artifact contents"
+	tests := []struct {
+		name         string
+		relativePath string
+		status       int
+		wantPath     string
+		wantErr      bool
+	}{
+		{name: "nested file", relativePath: "dist/app v2.tar.gz", status: http.StatusOK, wantPath: "/job/app/7/artifact/dist/app%20v2.tar.gz"},
+		{name: "missing artifact", relativePath: "dist/gone.zip", status: http.StatusNotFound, wantPath: "/job/app/7/artifact/dist/gone.zip", wantErr: true},
+		{name: "escapes the artifact directory", relativePath: "../secrets", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.EscapedPath()
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					w.Write([]byte(content))
+				}
+			}))
+			defer server.Close()
+
+			dest := filepath.Join(t.TempDir(), "artifact")
+			var lastWritten, lastTotal int64
+			client := NewClient(Credentials{URL: server.URL})
+			err := client.DownloadArtifact(context.Background(), "app", 7, tt.relativePath, dest, func(written, total int64) {
+				lastWritten, lastTotal = written, total
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DownloadArtifact() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("DownloadArtifact() requested %q, want %q", gotPath, tt.wantPath)
+			}
+			if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+				t.Errorf("partial file left behind: %v", err)
+			}
+
+			data, readErr := os.ReadFile(dest)
+			if tt.wantErr {
+				if readErr == nil {
+					t.Errorf("DownloadArtifact() created %s despite failing", dest)
+				}
+				return
+			}
+			if string(data) != content {
+				t.Errorf("downloaded %q, want %q", data, content)
+			}
+			if lastWritten != int64(len(content)) || lastTotal != int64(len(content)) {
+				t.Errorf("last progress = %d/%d, want %d/%d", lastWritten, lastTotal, len(content), len(content))
+			}
+		})
+	}
+}
+
+func TestGetConsoleLogSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int64
+		wantErr bool
+	}{
+		{name: "reported size", header: "48213", want: 48213},
+		{name: "missing header", header: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/job/app/4/logText/progressiveText" {
+					t.Errorf("unexpected request to %s", r.URL.Path)
+				}
+				if r.Method != http.MethodHead {
+					t.Errorf("method = %s, want HEAD so the log isn't downloaded", r.Method)
+				}
+				if tt.header != "" {
+					w.Header().Set("X-Text-Size", tt.header)
+				}
+				w.Write([]byte("Started by user admin\n"))
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			got, err := client.GetConsoleLogSize(context.Background(), "app", 4)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetConsoleLogSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GetConsoleLogSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetProgressiveLogFallsBackToHTML(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+"?"+r.URL.RawQuery+" "+r.Header.Get("X-ConsoleAnnotator"))
+		switch r.URL.Path {
+		case "/job/app/4/logText/progressiveText":
+			// The annotator swallows the text, but the log has grown.
+			w.Header().Set("X-Text-Size", "40")
+			w.Header().Set("X-More-Data", "true")
+		case "/job/app/4/logText/progressiveHtml":
+			if r.URL.Query().Get("start") == "0" {
+				w.Header().Set("X-Text-Size", "40")
+				w.Header().Set("X-More-Data", "true")
+				w.Header().Set("X-ConsoleAnnotator", "state-1")
+				w.Write([]byte(`Started by <a href="/user/admin">admin</a>` + "\n" + `<span style="color: #CD0000;">ERROR: &lt;boom&gt;</span>` + "\n"))
+				return
+			}
+			w.Header().Set("X-Text-Size", "52")
+			w.Write([]byte("Finished\n"))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	text, next, more, err := client.GetProgressiveLog(context.Background(), "", "app", 4, 0)
+	if err != nil {
+		t.Fatalf("GetProgressiveLog() error = %v", err)
+	}
+	want := "Started by admin\n\x1b[0m\x1b[38;2;205;0;0mERROR: <boom>\x1b[0m\n"
+	if text != want || next != 40 || !more {
+		t.Errorf("GetProgressiveLog() = %q, %d, %v; want %q, 40, true", text, next, more, want)
+	}
+
+	// The build is read from progressiveHtml from then on, resuming the annotator.
+	text, next, more, err = client.GetProgressiveLog(context.Background(), "", "app", 4, next)
+	if err != nil || text != "Finished\n" || next != 52 || more {
+		t.Errorf("second GetProgressiveLog() = %q, %d, %v, %v", text, next, more, err)
+	}
+	wantRequests := []string{
+		"/job/app/4/logText/progressiveText?start=0 ",
+		"/job/app/4/logText/progressiveHtml?start=0 ",
+		"/job/app/4/logText/progressiveHtml?start=40 state-1",
+	}
+	if strings.Join(requests, "\n") != strings.Join(wantRequests, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(wantRequests, "\n"))
+	}
+}
+
+func TestGetAnnotatedLog(t *testing.T) {
+	var annotators []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/job/app/5/logText/progressiveHtml" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			return
+		}
+		annotators = append(annotators, r.Header.Get("X-ConsoleAnnotator"))
+		if r.URL.Query().Get("start") == "0" {
+			w.Header().Set("X-Text-Size", "120")
+			w.Header().Set("X-More-Data", "true")
+			w.Header().Set("X-ConsoleAnnotator", "state-1")
+			w.Write([]byte(`<span class="pipeline-new-node" nodeId="4" enclosingId="2" label="Build">[Pipeline] { (Build)` + "\n" +
+				`</span><span class="pipeline-node-6">make</span>`))
+			return
+		}
+		w.Header().Set("X-Text-Size", "150")
+		w.Write([]byte(`<span class="pipeline-node-6"> all` + "\n" + `</span>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	log, err := client.GetAnnotatedLog(context.Background(), "", "app", 5, 0)
+	if err != nil {
+		t.Fatalf("GetAnnotatedLog() error = %v", err)
+	}
+	if log.Next != 120 || !log.More {
+		t.Errorf("Next, More = %d, %v; want 120, true", log.Next, log.More)
+	}
+	wantSegments := []LogSegment{
+		{NodeID: "4", Text: "[Pipeline] { (Build)\n"},
+		{NodeID: "6", Text: "make"},
+	}
+	if !reflect.DeepEqual(log.Segments, wantSegments) {
+		t.Errorf("Segments = %q, want %q", log.Segments, wantSegments)
+	}
+	if len(log.Nodes) != 1 || log.Nodes[0].Label != "Build" {
+		t.Errorf("Nodes = %+v, want the Build stage", log.Nodes)
+	}
+
+	log, err = client.GetAnnotatedLog(context.Background(), "", "app", 5, log.Next)
+	if err != nil || log.Text() != " all\n" || log.Next != 150 || log.More {
+		t.Errorf("second GetAnnotatedLog() = %+v, %v", log, err)
+	}
+	if strings.Join(annotators, ",") != ",state-1" {
+		t.Errorf("annotator states sent = %q, want the one of the previous chunk", annotators)
+	}
+}
+
+func TestGetPermalinkBuilds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/team/job/api/lastSuccessfulBuild/api/json":
+			w.Write([]byte(`{"number": 480, "result": "SUCCESS", "timestamp": 1700000000000}`))
+		case "/job/team/job/api/lastStableBuild/api/json":
+			w.Write([]byte(`{"number": 478, "result": "SUCCESS"}`))
+		case "/job/team/job/api/lastFailedBuild/api/json":
+			// Never failed: the permalink points nowhere.
+			http.NotFound(w, r)
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+
+	build, err := client.GetLastSuccessfulBuild(ctx, "team/api")
+	if err != nil || build == nil || build.Number != 480 || build.Timestamp != 1700000000000 {
+		t.Errorf("GetLastSuccessfulBuild() = %+v, %v; want #480", build, err)
+	}
+	build, err = client.GetLastStableBuild(ctx, "team/api")
+	if err != nil || build == nil || build.Number != 478 {
+		t.Errorf("GetLastStableBuild() = %+v, %v; want #478", build, err)
+	}
+	build, err = client.GetLastFailedBuild(ctx, "team/api")
+	if err != nil || build != nil {
+		t.Errorf("GetLastFailedBuild() = %+v, %v; want nil for a job that never failed", build, err)
+	}
+	if _, err := client.GetLastFailedBuild(ctx, "web"); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("GetLastFailedBuild() on a server error = %v, want the status", err)
+	}
+}
+
+func TestGetTestReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantNil    bool
+		wantFailed []string
+	}{
+		{
+			name:   "report with failures",
+			status: http.StatusOK,
+			body: `{"failCount":2,"passCount":1,"skipCount":1,"suites":[{"name":"api","cases":[` +
+				`{"className":"api.UserTest","name":"creates","status":"PASSED"},` +
+				`{"className":"api.UserTest","name":"deletes","status":"REGRESSION","errorDetails":"expected 204"},` +
+				`{"className":"api.UserTest","name":"lists","status":"FAILED","age":3},` +
+				`{"className":"api.UserTest","name":"pages","status":"SKIPPED","skipped":true}]}]}`,
+			wantFailed: []string{"deletes", "lists"},
+		},
+		{
+			name:    "no test report",
+			status:  http.StatusNotFound,
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/job/app/9/testReport/api/json" {
+					t.Errorf("unexpected request to %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			report, err := client.GetTestReport(context.Background(), "app", 9)
+			if err != nil {
+				t.Fatalf("GetTestReport() error = %v", err)
+			}
+			if tt.wantNil {
+				if report != nil {
+					t.Errorf("GetTestReport() = %+v, want nil", report)
+				}
+				return
+			}
+
+			if report.TotalCount() != 4 {
+				t.Errorf("TotalCount() = %d, want 4", report.TotalCount())
+			}
+			var failed []string
+			for _, c := range report.FailedCases() {
+				failed = append(failed, c.Name)
+			}
+			if strings.Join(failed, ",") != strings.Join(tt.wantFailed, ",") {
+				t.Errorf("FailedCases() = %v, want %v", failed, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestGetNodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/computer/api/json" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if strings.ContainsAny(r.URL.RawQuery, "[]*,") {
+			t.Errorf("tree query %q is not escaped", r.URL.RawQuery)
+		}
+		if got, want := r.URL.Query().Get("tree"), nodeFields.As("computer").String(); got != want {
+			t.Errorf("tree = %q, want %q", got, want)
+		}
+		w.Write([]byte(`{"computer":[
+			{"displayName":"Built-In Node","idle":true,"numExecutors":2,
+			 "assignedLabels":[{"name":"built-in"}],
+			 "executors":[{"idle":true},{"idle":false}],
+			 "monitorData":{"hudson.node_monitors.ArchitectureMonitor":"Linux (amd64)",
+			  "hudson.node_monitors.DiskSpaceMonitor":{"path":"/var/jenkins","size":1073741824},
+			  "hudson.node_monitors.ResponseTimeMonitor":{"average":12}}},
+			{"displayName":"agent-1","offline":true,"temporarilyOffline":true,"offlineCauseReason":"flaky disk",
+			 "assignedLabels":[{"name":"agent-1"},{"name":"linux"},{"name":"docker"}],
+			 "monitorData":{"hudson.node_monitors.DiskSpaceMonitor":null}}
+		]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	nodes, err := client.GetNodes(context.Background())
+	if err != nil {
+		t.Fatalf("GetNodes() error = %v", err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("GetNodes() returned %d nodes, want 2", len(nodes))
+	}
+
+	builtIn := nodes[0]
+	if builtIn.BusyExecutors() != 1 || builtIn.MonitorData.Architecture != "Linux (amd64)" {
+		t.Errorf("built-in node = %+v", builtIn)
+	}
+	if disk := builtIn.MonitorData.DiskSpace; disk == nil || disk.Size != 1<<30 {
+		t.Errorf("built-in disk space = %+v, want 1 GiB", disk)
+	}
+	if rt := builtIn.MonitorData.ResponseTime; rt == nil || rt.Average != 12 {
+		t.Errorf("built-in response time = %+v, want 12ms", rt)
+	}
+
+	agent := nodes[1]
+	if !agent.TemporarilyOffline || agent.OfflineCauseReason != "flaky disk" || agent.MonitorData.DiskSpace != nil {
+		t.Errorf("agent = %+v", agent)
+	}
+	if got := strings.Join(agent.Labels(), ","); got != "linux,docker" {
+		t.Errorf("agent.Labels() = %q, want linux,docker", got)
+	}
+}
+
+func TestSetNodeOffline(t *testing.T) {
+	tests := []struct {
+		name       string
+		node       string
+		offline    bool
+		wantAction string
+	}{
+		{name: "online agent is toggled", node: "agent 1", wantAction: "/computer/agent%201/toggleOffline"},
+		{name: "offline agent gets a new reason", node: "agent 1", offline: true, wantAction: "/computer/agent%201/changeOfflineCause"},
+		{name: "built-in node", node: "Built-In Node", wantAction: "/computer/(built-in)/toggleOffline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAction, gotMessage string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/crumbIssuer/api/json":
+					w.WriteHeader(http.StatusNotFound)
+				case r.Method == http.MethodGet:
+					fmt.Fprintf(w, `{"displayName":%q,"temporarilyOffline":%t}`, tt.node, tt.offline)
+				default:
+					gotAction, gotMessage = r.URL.EscapedPath(), r.URL.Query().Get("offlineMessage")
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			if err := client.SetNodeOffline(context.Background(), tt.node, "disk full"); err != nil {
+				t.Fatalf("SetNodeOffline() error = %v", err)
+			}
+			if gotAction != tt.wantAction || gotMessage != "disk full" {
+				t.Errorf("SetNodeOffline() posted %s (message %q), want %s", gotAction, gotMessage, tt.wantAction)
+			}
+		})
+	}
+}
+
+func TestSetNodeOnline(t *testing.T) {
+	for _, offline := range []bool{true, false} {
+		posted := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.URL.Path == "/crumbIssuer/api/json":
+				w.WriteHeader(http.StatusNotFound)
+			case r.Method == http.MethodGet:
+				fmt.Fprintf(w, `{"displayName":"agent-1","temporarilyOffline":%t}`, offline)
+			default:
+				posted = r.URL.Path == "/computer/agent-1/toggleOffline"
+			}
+		}))
+
+		client := NewClient(Credentials{URL: server.URL})
+		if err := client.SetNodeOnline(context.Background(), "agent-1"); err != nil {
+			t.Fatalf("SetNodeOnline() error = %v", err)
+		}
+		if posted != offline {
+			t.Errorf("SetNodeOnline() with temporarilyOffline=%t toggled = %t", offline, posted)
+		}
+		server.Close()
+	}
+}
+
+func TestSetJobEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		disable  bool
+		status   int
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "disable", disable: true, status: http.StatusFound, wantPath: "/job/Deploy/job/prod/disable"},
+		{name: "enable", status: http.StatusOK, wantPath: "/job/Deploy/job/prod/enable"},
+		{name: "forbidden", disable: true, status: http.StatusForbidden, wantPath: "/job/Deploy/job/prod/disable", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMethod, gotPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/crumbIssuer/api/json" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				gotMethod, gotPath = r.Method, r.URL.Path
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			var err error
+			if tt.disable {
+				err = client.DisableJob(context.Background(), "Deploy/prod")
+			} else {
+				err = client.EnableJob(context.Background(), "Deploy/prod")
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotMethod != http.MethodPost || gotPath != tt.wantPath {
+				t.Errorf("sent %s %s, want POST %s", gotMethod, gotPath, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestCreateItemPath(t *testing.T) {
+	tests := []struct {
+		fullName string
+		want     string
+		wantErr  bool
+	}{
+		{fullName: "api", want: "/createItem?name=api"},
+		{fullName: "Production/api v2", want: "/job/Production/createItem?name=api+v2"},
+		{fullName: "", wantErr: true},
+		{fullName: "Production/ ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := createItemPath(tt.fullName)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("createItemPath(%q) = %q, %v; want %q (error %v)", tt.fullName, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestJobLifecycle(t *testing.T) {
+	var requests []string
+	var body, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		if len(data) > 0 {
+			body, contentType = string(data), r.Header.Get("Content-Type")
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		if r.URL.Query().Get("name") == "taken" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+	if err := client.CreateJob(ctx, "Team/new", "<project/>"); err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	if err := client.CopyJob(ctx, "Templates/service", "Team/copy"); err != nil {
+		t.Fatalf("CopyJob() error = %v", err)
+	}
+	if err := client.DeleteJob(ctx, "Team/old"); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if err := client.CopyJob(ctx, "Templates/service", "Team/taken"); err == nil {
+		t.Error("CopyJob() onto a taken name succeeded, want error")
+	}
+
+	want := []string{
+		"POST /job/Team/createItem?name=new",
+		"POST /job/Team/createItem?name=copy&from=%2FTemplates%2Fservice&mode=copy",
+		"POST /job/Team/job/old/doDelete?",
+		"POST /job/Team/createItem?name=taken&from=%2FTemplates%2Fservice&mode=copy",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+	if body != "<project/>" || contentType != "application/xml" {
+		t.Errorf("CreateJob() sent %q as %q", body, contentType)
+	}
+}
+
+func TestUpdateJobConfig(t *testing.T) {
+	var method, path, body, contentType, crumb string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			fmt.Fprint(w, `{"crumb":"abc","crumbRequestField":"Jenkins-Crumb"}`)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.Path, string(data)
+		contentType, crumb = r.Header.Get("Content-Type"), r.Header.Get("Jenkins-Crumb")
+		if strings.Contains(body, "broken") {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, "Unable to parse config.xml")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	if err := client.UpdateJobConfig(context.Background(), "Team/api", "<project/>"); err != nil {
+		t.Fatalf("UpdateJobConfig() error = %v", err)
+	}
+	if method != http.MethodPost || path != "/job/Team/job/api/config.xml" {
+		t.Errorf("request = %s %s, want POST /job/Team/job/api/config.xml", method, path)
+	}
+	if body != "<project/>" || contentType != "application/xml" || crumb != "abc" {
+		t.Errorf("sent %q as %q with crumb %q", body, contentType, crumb)
+	}
+
+	err := client.UpdateJobConfig(context.Background(), "Team/api", "<broken")
+	if err == nil || !strings.Contains(err.Error(), "Unable to parse") {
+		t.Errorf("UpdateJobConfig() with invalid XML error = %v, want the Jenkins message", err)
+	}
+	if err := client.UpdateJobConfig(context.Background(), "", "<project/>"); err == nil {
+		t.Error("UpdateJobConfig() with an empty name succeeded, want error")
+	}
+}
+
+func TestScanMultibranch(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "redirect to indexing page", status: http.StatusFound},
+		{name: "ok", status: http.StatusOK},
+		{name: "not a multibranch project", status: http.StatusMethodNotAllowed, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/crumbIssuer/api/json" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if r.URL.Path == "/job/Team/job/service/indexing/" {
+					return
+				}
+				request = r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery
+				if tt.status == http.StatusFound {
+					w.Header().Set("Location", "/job/Team/job/service/indexing/")
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			err := client.ScanMultibranch(context.Background(), "Team/service")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ScanMultibranch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if request != "POST /job/Team/job/service/build?delay=0" {
+				t.Errorf("request = %q", request)
+			}
+		})
+	}
+}
+
+func TestCanBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		page    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "build now task", page: `{"items":[{"displayName":"Status","url":"/job/Team/job/api/"},
+			{"displayName":"Build Now","url":"/job/Team/job/api/build?delay=0sec","post":true}]}`, want: true},
+		{name: "parameterized", page: `{"items":[{"displayName":"Build with Parameters","url":"/job/Team/job/api/build"}]}`, want: true},
+		{name: "read only", page: `{"items":[{"displayName":"Changes","url":"/job/Team/job/api/changes"},
+			{"displayName":"Build History","url":"/job/Team/job/api/buildTimeTrend"}]}`, want: false},
+		{name: "forbidden", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/crumbIssuer/api/json" {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				if r.Method != http.MethodGet || r.URL.Path != "/job/Team/job/api/contextMenu" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if tt.page == "" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				fmt.Fprint(w, tt.page)
+			}))
+			defer server.Close()
+
+			client := NewClient(Credentials{URL: server.URL})
+			got, err := client.CanBuild(context.Background(), "Team/api")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CanBuild() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CanBuild() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayBuild(t *testing.T) {
+	var requests []string
+	var submitted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/job/Team/job/api/7/replay/":
+			fmt.Fprint(w, `<form><textarea name="_.mainScript" class="editor">
+pipeline { stage(&quot;a&quot;) { echo 'x &lt; y' } }</textarea>
+<textarea name="_.Script1" class="editor">
+def deploy() { sh 'make deploy' }</textarea></form>`)
+		case "/job/Team/job/api/8/replay/":
+			w.WriteHeader(http.StatusNotFound)
+		case "/job/Team/job/api/7/replay/run":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("ParseForm() error = %v", err)
+			}
+			submitted = r.PostForm.Get("json")
+			w.WriteHeader(http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+
+	script, err := client.GetReplayScript(ctx, "Team/api", 7)
+	if err != nil {
+		t.Fatalf("GetReplayScript() error = %v", err)
+	}
+	if want := `pipeline { stage("a") { echo 'x < y' } }`; script != want {
+		t.Errorf("GetReplayScript() = %q, want %q", script, want)
+	}
+	if _, err := client.GetReplayScript(ctx, "Team/api", 8); err == nil {
+		t.Error("GetReplayScript() of a build without replay succeeded, want error")
+	}
+
+	if err := client.ReplayBuild(ctx, "Team/api", 7, ""); err != nil {
+		t.Fatalf("ReplayBuild() without a script error = %v", err)
+	}
+	if err := client.ReplayBuild(ctx, "Team/api", 7, "echo 'changed'"); err != nil {
+		t.Fatalf("ReplayBuild() error = %v", err)
+	}
+	if submitted != `{"Script1":"def deploy() { sh 'make deploy' }","mainScript":"echo 'changed'"}` {
+		t.Errorf("submitted form json = %q", submitted)
+	}
+
+	want := []string{
+		"GET /job/Team/job/api/7/replay/",
+		"GET /job/Team/job/api/8/replay/",
+		"POST /job/Team/job/api/7/replay/rebuild",
+		"GET /job/Team/job/api/7/replay/",
+		"POST /job/Team/job/api/7/replay/run",
+	}
+	if strings.Join(requests, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests =\n%s\nwant\n%s", strings.Join(requests, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSetBuildDescriptionAndDisplayName(t *testing.T) {
+	forms := map[string]url.Values{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/crumbIssuer/api/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		forms[r.Method+" "+r.URL.Path] = r.PostForm
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+
+	if err := client.SetBuildDescription(ctx, "Team/api", 7, "hotfix for prod incident"); err != nil {
+		t.Fatalf("SetBuildDescription() error = %v", err)
+	}
+	if got := forms["POST /job/Team/job/api/7/submitDescription"].Get("description"); got != "hotfix for prod incident" {
+		t.Errorf("submitted description = %q", got)
+	}
+
+	if err := client.SetBuildDisplayName(ctx, "Team/api", 7, "v2.3.1", "hotfix"); err != nil {
+		t.Fatalf("SetBuildDisplayName() error = %v", err)
+	}
+	var submitted map[string]string
+	if err := json.Unmarshal([]byte(forms["POST /job/Team/job/api/7/configSubmit"].Get("json")), &submitted); err != nil {
+		t.Fatalf("configSubmit json: %v", err)
+	}
+	if submitted["displayName"] != "v2.3.1" || submitted["description"] != "hotfix" {
+		t.Errorf("submitted configure form = %v", submitted)
+	}
+
+	if err := client.SetBuildDescription(ctx, "Team/api", 0, "x"); err == nil {
+		t.Error("SetBuildDescription() of build #0 succeeded, want error")
+	}
+}
+
+func TestGetInstalledPlugins(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crumbIssuer/api/json":
+			w.WriteHeader(http.StatusNotFound)
+		case "/pluginManager/api/json":
+			if r.URL.Query().Get("depth") != "1" {
+				t.Errorf("depth = %q, want 1", r.URL.Query().Get("depth"))
+			}
+			fmt.Fprint(w, `{"plugins":[
+				{"shortName":"workflow-job","longName":"Pipeline: Job","version":"1400.v7fd111b_ec82f","active":true,"enabled":true,"hasUpdate":true},
+				{"shortName":"blueocean","longName":"Blue Ocean","version":"1.27.14","active":true,"enabled":false},
+				{"shortName":"pipeline-stage-view","longName":"Pipeline: Stage View","version":"2.34","active":true,"enabled":true}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	plugins, err := client.GetInstalledPlugins(context.Background())
+	if err != nil {
+		t.Fatalf("GetInstalledPlugins() error = %v", err)
+	}
+
+	var names []string
+	for _, plugin := range plugins {
+		names = append(names, plugin.ShortName)
+	}
+	if got := strings.Join(names, ","); got != "blueocean,pipeline-stage-view,workflow-job" {
+		t.Errorf("plugins = %s, want them sorted by short name", got)
+	}
+	if !plugins[2].HasUpdate || plugins[2].Version != "1400.v7fd111b_ec82f" {
+		t.Errorf("workflow-job = %+v", plugins[2])
+	}
+	if !HasPlugin(plugins, PluginPipelineStageView) {
+		t.Error("HasPlugin(pipeline-stage-view) = false, want true")
+	}
+	if HasPlugin(plugins, PluginBlueOcean) {
+		t.Error("HasPlugin(blueocean) = true for a disabled plugin")
+	}
+	if HasPlugin(plugins, "git") {
+		t.Error("HasPlugin(git) = true for a missing plugin")
+	}
+}
+
+func TestGetCredentials(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/credentials/store/system/domain/_/api/json":
+			query = r.URL.Query().Get("tree")
+			fmt.Fprint(w, `{"credentials":[
+				{"id":"github-token","displayName":"ci-bot/****","description":"GitHub bot","typeName":"Username with password"},
+				{"id":"deploy-key","displayName":"deploy","description":"","typeName":"SSH Username with private key"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+
+	credentials, err := client.GetCredentials(ctx, "")
+	if err != nil {
+		t.Fatalf("GetCredentials() error = %v", err)
+	}
+	if len(credentials) != 2 || credentials[0].ID != "deploy-key" || credentials[1].TypeName != "Username with password" {
+		t.Errorf("GetCredentials() = %+v, want both sorted by ID", credentials)
+	}
+	if query != "credentials[id,displayName,description,typeName]" {
+		t.Errorf("tree = %q, want only identifying fields", query)
+	}
+
+	if _, err := client.GetCredentials(ctx, "staging"); err == nil {
+		t.Error("GetCredentials() of a missing domain returned no error")
+	}
+}
+
+func TestGetCredentialChoices(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/job/Team/job/deploy/descriptorByName/com.cloudbees.plugins.credentials.CredentialsParameterDefinition/fillValueItems":
+			query = r.URL.Query()
+			fmt.Fprint(w, `{"_class":"hudson.util.ListBoxModel","values":[
+				{"name":"- none -","selected":false,"value":""},
+				{"name":"ci-bot/****** (GitHub bot)","selected":false,"value":"github-token"},
+				{"name":"deploy (team key)","selected":false,"value":"deploy-key"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+
+	credentials, err := client.GetCredentialChoices(ctx, "Team/deploy", "")
+	if err != nil {
+		t.Fatalf("GetCredentialChoices() error = %v", err)
+	}
+	want := []Credential{{ID: "deploy-key", DisplayName: "deploy (team key)"}, {ID: "github-token", DisplayName: "ci-bot/****** (GitHub bot)"}}
+	if !reflect.DeepEqual(credentials, want) {
+		t.Errorf("GetCredentialChoices() = %+v, want %+v", credentials, want)
+	}
+	if got := query.Get("credentialType"); got != DefaultCredentialType {
+		t.Errorf("credentialType = %q, want the plugin's default", got)
+	}
+
+	if _, err := client.GetCredentialChoices(ctx, "missing", DefaultCredentialType); err == nil {
+		t.Error("GetCredentialChoices() of a missing job returned no error")
+	}
+}
+
+func TestGetFingerprint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fingerprint/b1946ac92492d2347c6235b4d2611184/api/json":
+			fmt.Fprint(w, `{"fileName":"app.jar","hash":"b1946ac92492d2347c6235b4d2611184","timestamp":1700000000000,
+				"original":{"name":"Production/api","number":42},
+				"usage":[{"name":"Deploy/staging","ranges":{"ranges":[{"start":17,"end":20},{"start":25,"end":26}]}}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+
+	fingerprint, err := client.GetFingerprint(ctx, "B1946AC92492D2347C6235B4D2611184")
+	if err != nil {
+		t.Fatalf("GetFingerprint() error = %v", err)
+	}
+	if fingerprint.Original == nil || fingerprint.Original.Name != "Production/api" || fingerprint.Original.Number != 42 {
+		t.Errorf("Original = %+v", fingerprint.Original)
+	}
+	if len(fingerprint.Usage) != 1 {
+		t.Fatalf("Usage = %+v", fingerprint.Usage)
+	}
+	var ranges []string
+	for _, r := range fingerprint.Usage[0].Ranges.Ranges {
+		ranges = append(ranges, r.String())
+	}
+	if got := strings.Join(ranges, ", "); got != "#17-#19, #25" {
+		t.Errorf("usage ranges = %s", got)
+	}
+
+	if _, err := client.GetFingerprint(ctx, "00000000000000000000000000000000"); !errors.Is(err, ErrFingerprintNotFound) {
+		t.Errorf("GetFingerprint() of an unknown checksum error = %v, want ErrFingerprintNotFound", err)
+	}
+	if _, err := client.GetFingerprint(ctx, "../../script"); err == nil {
+		t.Error("GetFingerprint() accepted a non-checksum")
+	}
+}
+
+func TestGetOverallAndLabelLoad(t *testing.T) {
+	const series = `{"sec10":{"latest":%v},"min":{"latest":%v,"history":[%s]},"hour":{"latest":1}}`
+	statistics := fmt.Sprintf(`{"busyExecutors":`+series+`,"onlineExecutors":`+series+`,"queueLength":`+series+`}`,
+		6, 5.5, "5.5,5",
+		8, 8, "8,8",
+		4, 4, "4,3.5,3,2.5,2,1.5,1,0.5,0,0,0,0")
+
+	var labelPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/overallLoad/api/json":
+			fmt.Fprint(w, statistics)
+		case strings.HasPrefix(r.URL.Path, "/label/"):
+			labelPath = r.URL.EscapedPath()
+			fmt.Fprintf(w, `{"name":"linux && docker","busyExecutors":3,"idleExecutors":1,"totalExecutors":4,
+				"nodes":[{"nodeName":"agent-1"},{"nodeName":"agent-2"}],"loadStatistics":%s}`, statistics)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+
+	load, err := client.GetOverallLoad(ctx)
+	if err != nil {
+		t.Fatalf("GetOverallLoad() error = %v", err)
+	}
+	if got := load.Utilization(); got != 0.75 {
+		t.Errorf("Utilization() = %v, want 0.75", got)
+	}
+	// The last ten samples go from 0 to 4 items: 4 items over 9 minutes.
+	if got := load.QueueGrowth(); math.Abs(got-4.0/9) > 1e-9 {
+		t.Errorf("QueueGrowth() = %v, want %v", got, 4.0/9)
+	}
+
+	label, err := client.GetLabelLoad(ctx, "linux && docker")
+	if err != nil {
+		t.Fatalf("GetLabelLoad() error = %v", err)
+	}
+	if labelPath != "/label/linux%20&&%20docker/api/json" {
+		t.Errorf("label path = %s", labelPath)
+	}
+	if label.BusyExecutors != 3 || label.TotalExecutors != 4 || len(label.Nodes) != 2 {
+		t.Errorf("label = %+v", label)
+	}
+	if got := label.LoadStatistics.QueueLength.Current(); got != 4 {
+		t.Errorf("label queue length = %v, want 4", got)
+	}
+
+	if _, err := client.GetLabelLoad(ctx, " "); err == nil {
+		t.Error("GetLabelLoad() with an empty label succeeded")
+	}
+}
+
+func TestQueueGrowthWithoutHistory(t *testing.T) {
+	var load LoadStatistics
+	if got := load.QueueGrowth(); got != 0 {
+		t.Errorf("QueueGrowth() = %v without history, want 0", got)
+	}
+	if got := load.Utilization(); got != 0 {
+		t.Errorf("Utilization() = %v without executors, want 0", got)
+	}
+}
+
+func TestStaleCrumbIsRefreshed(t *testing.T) {
+	var issued int
+	var attempts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crumbIssuer/api/json":
+			issued++
+			fmt.Fprintf(w, `{"crumbRequestField":"Jenkins-Crumb","crumb":"crumb-%d"}`, issued)
+		case "/job/api/submitDescription":
+			body, _ := io.ReadAll(r.Body)
+			attempts = append(attempts, r.Header.Get("Jenkins-Crumb")+" "+string(body))
+			if r.Header.Get("Jenkins-Crumb") != "crumb-2" {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, "<html><body>No valid crumb was included in the request</body></html>")
+				return
+			}
+			w.WriteHeader(http.StatusFound)
+		case "/job/locked/enable":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "alice is missing the Job/Configure permission")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+
+	// The job path stands in for a build; only the crumb handling matters.
+	c := client.(*Client)
+	if err := c.postBuildForm(ctx, "/job/api/submitDescription", url.Values{"description": {"hotfix"}}, "set build description"); err != nil {
+		t.Fatalf("POST with a stale crumb error = %v, want a retry with a fresh one", err)
+	}
+	want := []string{"crumb-1 description=hotfix", "crumb-2 description=hotfix"}
+	if strings.Join(attempts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("attempts =\n%s\nwant\n%s", strings.Join(attempts, "\n"), strings.Join(want, "\n"))
+	}
+
+	// A permission error is not a crumb problem: no retry, and the body survives.
+	err := client.EnableJob(ctx, "locked")
+	if err == nil || !strings.Contains(err.Error(), "missing the Job/Configure permission") {
+		t.Errorf("EnableJob() error = %v, want the 403 body", err)
+	}
+	if issued != 2 {
+		t.Errorf("crumbs issued = %d, want 2", issued)
+	}
+}
+
+func TestReadOnlyClient(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		fmt.Fprint(w, `{"jobs":[{"name":"api","fullName":"api","color":"blue"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL, ReadOnly: true})
+	ctx := context.Background()
+	if _, err := client.GetAllJobs(ctx); err != nil {
+		t.Fatalf("GetAllJobs() error = %v, want reads to go through", err)
+	}
+	if _, err := client.TriggerBuild(ctx, "api"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("TriggerBuild() error = %v, want ErrReadOnly", err)
+	}
+	if err := client.DisableJob(ctx, "api"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("DisableJob() error = %v, want ErrReadOnly", err)
+	}
+	if ok, err := client.CanBuild(ctx, "api"); ok || err != nil {
+		t.Errorf("CanBuild() = %v, %v; want false without asking", ok, err)
+	}
+	if len(requests) != 1 || !strings.HasPrefix(requests[0], "GET ") {
+		t.Errorf("requests = %q, want the job list alone", requests)
+	}
+
+	ssh := NewClient(Credentials{URL: server.URL, ReadOnly: true, SSH: &SSHOptions{Endpoint: "ci.example.com:2222"}}).(*sshClient)
+	ssh.run = func(ctx context.Context, args ...string) ([]byte, error) {
+		t.Errorf("ran %q on a read-only server", args)
+		return nil, nil
+	}
+	if _, err := ssh.TriggerBuild(ctx, "api"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("TriggerBuild() over SSH error = %v, want ErrReadOnly", err)
+	}
+}
+
+func TestConcurrentCrumbRefresh(t *testing.T) {
+	var issued atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crumbIssuer/api/json":
+			fmt.Fprintf(w, `{"crumbRequestField":"Jenkins-Crumb","crumb":"crumb-%d"}`, issued.Add(1))
+		case "/job/api/submitDescription":
+			if r.Header.Get("Jenkins-Crumb") == "crumb-1" {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, "No valid crumb was included in the request")
+				return
+			}
+			w.WriteHeader(http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(Credentials{URL: server.URL}).(*Client)
+	if _, err := c.ensureCrumb(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Every POST is rejected once and invalidates the crumb the others are
+	// about to read.
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- c.postBuildForm(context.Background(), "/job/api/submitDescription", url.Values{"description": {"x"}}, "set build description")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("concurrent POST error = %v, want a retry with a fresh crumb", err)
+		}
+	}
+}
+
+func TestPromotions(t *testing.T) {
+	var promoted string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/crumbIssuer/api/json":
+			w.WriteHeader(http.StatusNotFound)
+		case "/job/api/promotion/api/json":
+			fmt.Fprint(w, `{"processes":[{"name":"Deploy to QA"},{"name":"Release"}]}`)
+		case "/job/api/7/promotion/forcePromotion":
+			promoted = r.Method + " " + r.URL.Query().Get("name")
+			w.WriteHeader(http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	ctx := context.Background()
+
+	processes, err := client.GetPromotionProcesses(ctx, "api")
+	if err != nil {
+		t.Fatalf("GetPromotionProcesses() error = %v", err)
+	}
+	if strings.Join(processes, ",") != "Deploy to QA,Release" {
+		t.Errorf("processes = %v", processes)
+	}
+	if processes, err := client.GetPromotionProcesses(ctx, "web"); err != nil || len(processes) != 0 {
+		t.Errorf("GetPromotionProcesses() without the plugin = %v, %v; want none", processes, err)
+	}
+
+	if err := client.PromoteBuild(ctx, "api", 7, "Deploy to QA"); err != nil {
+		t.Fatalf("PromoteBuild() error = %v", err)
+	}
+	if promoted != "POST Deploy to QA" {
+		t.Errorf("promotion request = %q", promoted)
+	}
+
+	var build Build
+	if err := json.Unmarshal([]byte(`{"number":7,"actions":[
+		{"_class":"hudson.model.ParametersAction","parameters":[{"name":"DEPLOY_ENV","value":"staging"},{"name":"DRY_RUN","value":false}]},
+		{"_class":"hudson.plugins.promoted_builds.PromotedBuildAction","promotions":[{"name":"Deploy to QA"}]}
+	]}`), &build); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(build.Promotions(""), ","); got != "Deploy to QA" {
+		t.Errorf("Promotions(\"\") = %s", got)
+	}
+	if got := strings.Join(build.Promotions("DEPLOY_ENV"), ","); got != "staging,Deploy to QA" {
+		t.Errorf("Promotions(DEPLOY_ENV) = %s", got)
+	}
+	if got := build.Promotions("DRY_RUN"); len(got) != 1 {
+		t.Errorf("Promotions(DRY_RUN) = %v, want a false marker ignored", got)
+	}
+}
@@ -0,0 +1,607 @@
+package jenkinstest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+// Client is a jenkins.JenkinsClient scripted per method: each method calls
+// the matching <Method>Func field if it is set, and otherwise returns zero
+// values and no error. Set the fields before handing the client out; calls
+// are recorded and may come from several goroutines.
+type Client struct {
+	TestConnectionFunc             func(ctx context.Context) error
+	GetInfoFunc                    func(ctx context.Context) (map[string]interface{}, error)
+	GetAllJobsFunc                 func(ctx context.Context) ([]jenkins.Job, error)
+	GetFolderJobsFunc              func(ctx context.Context, folderFullName string) ([]jenkins.Job, error)
+	GetJobDetailsFunc              func(ctx context.Context, fullName string, limit int) (*jenkins.JobDetails, error)
+	GetBuildsFunc                  func(ctx context.Context, fullName string, offset int, limit int) ([]jenkins.Build, error)
+	GetBuildCountFunc              func(ctx context.Context, fullName string) (int, error)
+	GetBuildQueueFunc              func(ctx context.Context) ([]jenkins.QueueItem, error)
+	GetRunningBuildsFunc           func(ctx context.Context) ([]jenkins.RunningBuild, error)
+	GetNodesFunc                   func(ctx context.Context) ([]jenkins.Computer, error)
+	GetNodeFunc                    func(ctx context.Context, name string) (*jenkins.Computer, error)
+	SetNodeOfflineFunc             func(ctx context.Context, name string, reason string) error
+	SetNodeOnlineFunc              func(ctx context.Context, name string) error
+	GetOverallLoadFunc             func(ctx context.Context) (*jenkins.LoadStatistics, error)
+	GetLabelLoadFunc               func(ctx context.Context, label string) (*jenkins.LabelLoad, error)
+	GetInstalledPluginsFunc        func(ctx context.Context) ([]jenkins.Plugin, error)
+	GetFingerprintFunc             func(ctx context.Context, md5 string) (*jenkins.Fingerprint, error)
+	GetCredentialsFunc             func(ctx context.Context, domain string) ([]jenkins.Credential, error)
+	GetCredentialChoicesFunc       func(ctx context.Context, fullName, credentialType string) ([]jenkins.Credential, error)
+	TriggerBuildFunc               func(ctx context.Context, fullName string) (int, error)
+	TriggerBuildWithParametersFunc func(ctx context.Context, fullName string, params map[string]string) (int, error)
+	CanBuildFunc                   func(ctx context.Context, fullName string) (bool, error)
+	ScanMultibranchFunc            func(ctx context.Context, fullName string) error
+	GetQueueItemFunc               func(ctx context.Context, id int) (*jenkins.QueueItem, error)
+	WaitForBuildNumberFunc         func(ctx context.Context, queueID int) (int, error)
+	AbortBuildFunc                 func(ctx context.Context, fullName string, buildNumber int) error
+	GetReplayScriptFunc            func(ctx context.Context, fullName string, buildNumber int) (string, error)
+	ReplayBuildFunc                func(ctx context.Context, fullName string, buildNumber int, script string) error
+	GetPromotionProcessesFunc      func(ctx context.Context, fullName string) ([]string, error)
+	PromoteBuildFunc               func(ctx context.Context, fullName string, buildNumber int, process string) error
+	SetBuildDescriptionFunc        func(ctx context.Context, fullName string, buildNumber int, description string) error
+	SetBuildDisplayNameFunc        func(ctx context.Context, fullName string, buildNumber int, displayName string, description string) error
+	CancelQueueItemFunc            func(ctx context.Context, id int) error
+	EnableJobFunc                  func(ctx context.Context, fullName string) error
+	DisableJobFunc                 func(ctx context.Context, fullName string) error
+	CreateJobFunc                  func(ctx context.Context, fullName string, configXML string) error
+	CopyJobFunc                    func(ctx context.Context, fromFullName string, toFullName string) error
+	DeleteJobFunc                  func(ctx context.Context, fullName string) error
+	GetBuildFunc                   func(ctx context.Context, fullName string, number int) (*jenkins.Build, error)
+	GetLastSuccessfulBuildFunc     func(ctx context.Context, fullName string) (*jenkins.Build, error)
+	GetLastFailedBuildFunc         func(ctx context.Context, fullName string) (*jenkins.Build, error)
+	GetLastStableBuildFunc         func(ctx context.Context, fullName string) (*jenkins.Build, error)
+	GetPipelineRunsFunc            func(ctx context.Context, fullName string) ([]jenkins.PipelineRun, error)
+	GetPipelineRunStagesFunc       func(ctx context.Context, fullName string, buildNumber int) (*jenkins.PipelineRun, error)
+	GetPipelineGraphFunc           func(ctx context.Context, fullName string, buildNumber int) (*jenkins.PipelineGraph, error)
+	GetPipelineStepsFunc           func(ctx context.Context, fullName string, buildNumber int, nodeID string) ([]jenkins.PipelineStep, error)
+	GetPipelineStepLogFunc         func(ctx context.Context, fullName string, buildNumber int, nodeID string, stepID string) (string, error)
+	GetConsoleLogFunc              func(ctx context.Context, fullName string, buildNumber int) (string, error)
+	GetTestReportFunc              func(ctx context.Context, fullName string, buildNumber int) (*jenkins.TestReport, error)
+	GetArtifactsFunc               func(ctx context.Context, fullName string, buildNumber int) ([]jenkins.Artifact, error)
+	DownloadArtifactFunc           func(ctx context.Context, fullName string, buildNumber int, relativePath string, destPath string, progress jenkins.ProgressFunc) error
+	GetConsoleLogSizeFunc          func(ctx context.Context, fullName string, buildNumber int) (int64, error)
+	GetProgressiveLogFunc          func(ctx context.Context, buildURL string, fullName string, buildNumber int, start int64) (string, int64, bool, error)
+	GetAnnotatedLogFunc            func(ctx context.Context, buildURL string, fullName string, buildNumber int, start int64) (jenkins.AnnotatedLog, error)
+	GetJobConfigFunc               func(ctx context.Context, fullName string) (string, error)
+	UpdateJobConfigFunc            func(ctx context.Context, fullName string, configXML string) error
+	GetFolderRelationsFunc         func(ctx context.Context, folderFullName string) ([]jenkins.JobRelations, error)
+	GetUserFunc                    func(ctx context.Context, id string) (*jenkins.User, error)
+	GenerateAPITokenFunc           func(ctx context.Context, name string) (*jenkins.APIToken, error)
+	RevokeAPITokenFunc             func(ctx context.Context, uuid string) error
+	SetTokenFunc                   func(token string)
+	SetRequestObserverFunc         func(observer jenkins.RequestObserver)
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ jenkins.JenkinsClient = (*Client)(nil)
+
+// Call is one recorded method call.
+type Call struct {
+	Method string
+	// Args are the arguments after the context.
+	Args []any
+}
+
+func (c *Client) record(method string, args ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns the calls made so far, oldest first.
+func (c *Client) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// CallsTo returns the calls made so far to one method, oldest first.
+func (c *Client) CallsTo(method string) []Call {
+	var calls []Call
+	for _, call := range c.Calls() {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func (c *Client) TestConnection(ctx context.Context) error {
+	c.record("TestConnection")
+	if c.TestConnectionFunc != nil {
+		return c.TestConnectionFunc(ctx)
+	}
+	return nil
+}
+
+func (c *Client) GetInfo(ctx context.Context) (map[string]interface{}, error) {
+	c.record("GetInfo")
+	if c.GetInfoFunc != nil {
+		return c.GetInfoFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetAllJobs(ctx context.Context) ([]jenkins.Job, error) {
+	c.record("GetAllJobs")
+	if c.GetAllJobsFunc != nil {
+		return c.GetAllJobsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetFolderJobs(ctx context.Context, folderFullName string) ([]jenkins.Job, error) {
+	c.record("GetFolderJobs", folderFullName)
+	if c.GetFolderJobsFunc != nil {
+		return c.GetFolderJobsFunc(ctx, folderFullName)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetJobDetails(ctx context.Context, fullName string, limit int) (*jenkins.JobDetails, error) {
+	c.record("GetJobDetails", fullName, limit)
+	if c.GetJobDetailsFunc != nil {
+		return c.GetJobDetailsFunc(ctx, fullName, limit)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetBuilds(ctx context.Context, fullName string, offset int, limit int) ([]jenkins.Build, error) {
+	c.record("GetBuilds", fullName, offset, limit)
+	if c.GetBuildsFunc != nil {
+		return c.GetBuildsFunc(ctx, fullName, offset, limit)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetBuildCount(ctx context.Context, fullName string) (int, error) {
+	c.record("GetBuildCount", fullName)
+	if c.GetBuildCountFunc != nil {
+		return c.GetBuildCountFunc(ctx, fullName)
+	}
+	return 0, nil
+}
+
+func (c *Client) GetBuildQueue(ctx context.Context) ([]jenkins.QueueItem, error) {
+	c.record("GetBuildQueue")
+	if c.GetBuildQueueFunc != nil {
+		return c.GetBuildQueueFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetRunningBuilds(ctx context.Context) ([]jenkins.RunningBuild, error) {
+	c.record("GetRunningBuilds")
+	if c.GetRunningBuildsFunc != nil {
+		return c.GetRunningBuildsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetNodes(ctx context.Context) ([]jenkins.Computer, error) {
+	c.record("GetNodes")
+	if c.GetNodesFunc != nil {
+		return c.GetNodesFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetNode(ctx context.Context, name string) (*jenkins.Computer, error) {
+	c.record("GetNode", name)
+	if c.GetNodeFunc != nil {
+		return c.GetNodeFunc(ctx, name)
+	}
+	return nil, nil
+}
+
+func (c *Client) SetNodeOffline(ctx context.Context, name string, reason string) error {
+	c.record("SetNodeOffline", name, reason)
+	if c.SetNodeOfflineFunc != nil {
+		return c.SetNodeOfflineFunc(ctx, name, reason)
+	}
+	return nil
+}
+
+func (c *Client) SetNodeOnline(ctx context.Context, name string) error {
+	c.record("SetNodeOnline", name)
+	if c.SetNodeOnlineFunc != nil {
+		return c.SetNodeOnlineFunc(ctx, name)
+	}
+	return nil
+}
+
+func (c *Client) GetOverallLoad(ctx context.Context) (*jenkins.LoadStatistics, error) {
+	c.record("GetOverallLoad")
+	if c.GetOverallLoadFunc != nil {
+		return c.GetOverallLoadFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetLabelLoad(ctx context.Context, label string) (*jenkins.LabelLoad, error) {
+	c.record("GetLabelLoad", label)
+	if c.GetLabelLoadFunc != nil {
+		return c.GetLabelLoadFunc(ctx, label)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetInstalledPlugins(ctx context.Context) ([]jenkins.Plugin, error) {
+	c.record("GetInstalledPlugins")
+	if c.GetInstalledPluginsFunc != nil {
+		return c.GetInstalledPluginsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetFingerprint(ctx context.Context, md5 string) (*jenkins.Fingerprint, error) {
+	c.record("GetFingerprint", md5)
+	if c.GetFingerprintFunc != nil {
+		return c.GetFingerprintFunc(ctx, md5)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetCredentials(ctx context.Context, domain string) ([]jenkins.Credential, error) {
+	c.record("GetCredentials", domain)
+	if c.GetCredentialsFunc != nil {
+		return c.GetCredentialsFunc(ctx, domain)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetCredentialChoices(ctx context.Context, fullName, credentialType string) ([]jenkins.Credential, error) {
+	c.record("GetCredentialChoices", fullName, credentialType)
+	if c.GetCredentialChoicesFunc != nil {
+		return c.GetCredentialChoicesFunc(ctx, fullName, credentialType)
+	}
+	return nil, nil
+}
+
+func (c *Client) TriggerBuild(ctx context.Context, fullName string) (int, error) {
+	c.record("TriggerBuild", fullName)
+	if c.TriggerBuildFunc != nil {
+		return c.TriggerBuildFunc(ctx, fullName)
+	}
+	return 0, nil
+}
+
+func (c *Client) TriggerBuildWithParameters(ctx context.Context, fullName string, params map[string]string) (int, error) {
+	c.record("TriggerBuildWithParameters", fullName, params)
+	if c.TriggerBuildWithParametersFunc != nil {
+		return c.TriggerBuildWithParametersFunc(ctx, fullName, params)
+	}
+	return 0, nil
+}
+
+func (c *Client) CanBuild(ctx context.Context, fullName string) (bool, error) {
+	c.record("CanBuild", fullName)
+	if c.CanBuildFunc != nil {
+		return c.CanBuildFunc(ctx, fullName)
+	}
+	return false, nil
+}
+
+func (c *Client) ScanMultibranch(ctx context.Context, fullName string) error {
+	c.record("ScanMultibranch", fullName)
+	if c.ScanMultibranchFunc != nil {
+		return c.ScanMultibranchFunc(ctx, fullName)
+	}
+	return nil
+}
+
+func (c *Client) GetQueueItem(ctx context.Context, id int) (*jenkins.QueueItem, error) {
+	c.record("GetQueueItem", id)
+	if c.GetQueueItemFunc != nil {
+		return c.GetQueueItemFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (c *Client) WaitForBuildNumber(ctx context.Context, queueID int) (int, error) {
+	c.record("WaitForBuildNumber", queueID)
+	if c.WaitForBuildNumberFunc != nil {
+		return c.WaitForBuildNumberFunc(ctx, queueID)
+	}
+	return 0, nil
+}
+
+func (c *Client) AbortBuild(ctx context.Context, fullName string, buildNumber int) error {
+	c.record("AbortBuild", fullName, buildNumber)
+	if c.AbortBuildFunc != nil {
+		return c.AbortBuildFunc(ctx, fullName, buildNumber)
+	}
+	return nil
+}
+
+func (c *Client) GetReplayScript(ctx context.Context, fullName string, buildNumber int) (string, error) {
+	c.record("GetReplayScript", fullName, buildNumber)
+	if c.GetReplayScriptFunc != nil {
+		return c.GetReplayScriptFunc(ctx, fullName, buildNumber)
+	}
+	return "", nil
+}
+
+func (c *Client) ReplayBuild(ctx context.Context, fullName string, buildNumber int, script string) error {
+	c.record("ReplayBuild", fullName, buildNumber, script)
+	if c.ReplayBuildFunc != nil {
+		return c.ReplayBuildFunc(ctx, fullName, buildNumber, script)
+	}
+	return nil
+}
+
+func (c *Client) GetPromotionProcesses(ctx context.Context, fullName string) ([]string, error) {
+	c.record("GetPromotionProcesses", fullName)
+	if c.GetPromotionProcessesFunc != nil {
+		return c.GetPromotionProcessesFunc(ctx, fullName)
+	}
+	return nil, nil
+}
+
+func (c *Client) PromoteBuild(ctx context.Context, fullName string, buildNumber int, process string) error {
+	c.record("PromoteBuild", fullName, buildNumber, process)
+	if c.PromoteBuildFunc != nil {
+		return c.PromoteBuildFunc(ctx, fullName, buildNumber, process)
+	}
+	return nil
+}
+
+func (c *Client) SetBuildDescription(ctx context.Context, fullName string, buildNumber int, description string) error {
+	c.record("SetBuildDescription", fullName, buildNumber, description)
+	if c.SetBuildDescriptionFunc != nil {
+		return c.SetBuildDescriptionFunc(ctx, fullName, buildNumber, description)
+	}
+	return nil
+}
+
+func (c *Client) SetBuildDisplayName(ctx context.Context, fullName string, buildNumber int, displayName string, description string) error {
+	c.record("SetBuildDisplayName", fullName, buildNumber, displayName, description)
+	if c.SetBuildDisplayNameFunc != nil {
+		return c.SetBuildDisplayNameFunc(ctx, fullName, buildNumber, displayName, description)
+	}
+	return nil
+}
+
+func (c *Client) CancelQueueItem(ctx context.Context, id int) error {
+	c.record("CancelQueueItem", id)
+	if c.CancelQueueItemFunc != nil {
+		return c.CancelQueueItemFunc(ctx, id)
+	}
+	return nil
+}
+
+func (c *Client) EnableJob(ctx context.Context, fullName string) error {
+	c.record("EnableJob", fullName)
+	if c.EnableJobFunc != nil {
+		return c.EnableJobFunc(ctx, fullName)
+	}
+	return nil
+}
+
+func (c *Client) DisableJob(ctx context.Context, fullName string) error {
+	c.record("DisableJob", fullName)
+	if c.DisableJobFunc != nil {
+		return c.DisableJobFunc(ctx, fullName)
+	}
+	return nil
+}
+
+func (c *Client) CreateJob(ctx context.Context, fullName string, configXML string) error {
+	c.record("CreateJob", fullName, configXML)
+	if c.CreateJobFunc != nil {
+		return c.CreateJobFunc(ctx, fullName, configXML)
+	}
+	return nil
+}
+
+func (c *Client) CopyJob(ctx context.Context, fromFullName string, toFullName string) error {
+	c.record("CopyJob", fromFullName, toFullName)
+	if c.CopyJobFunc != nil {
+		return c.CopyJobFunc(ctx, fromFullName, toFullName)
+	}
+	return nil
+}
+
+func (c *Client) DeleteJob(ctx context.Context, fullName string) error {
+	c.record("DeleteJob", fullName)
+	if c.DeleteJobFunc != nil {
+		return c.DeleteJobFunc(ctx, fullName)
+	}
+	return nil
+}
+
+func (c *Client) GetBuild(ctx context.Context, fullName string, number int) (*jenkins.Build, error) {
+	c.record("GetBuild", fullName, number)
+	if c.GetBuildFunc != nil {
+		return c.GetBuildFunc(ctx, fullName, number)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetLastSuccessfulBuild(ctx context.Context, fullName string) (*jenkins.Build, error) {
+	c.record("GetLastSuccessfulBuild", fullName)
+	if c.GetLastSuccessfulBuildFunc != nil {
+		return c.GetLastSuccessfulBuildFunc(ctx, fullName)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetLastFailedBuild(ctx context.Context, fullName string) (*jenkins.Build, error) {
+	c.record("GetLastFailedBuild", fullName)
+	if c.GetLastFailedBuildFunc != nil {
+		return c.GetLastFailedBuildFunc(ctx, fullName)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetLastStableBuild(ctx context.Context, fullName string) (*jenkins.Build, error) {
+	c.record("GetLastStableBuild", fullName)
+	if c.GetLastStableBuildFunc != nil {
+		return c.GetLastStableBuildFunc(ctx, fullName)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetPipelineRuns(ctx context.Context, fullName string) ([]jenkins.PipelineRun, error) {
+	c.record("GetPipelineRuns", fullName)
+	if c.GetPipelineRunsFunc != nil {
+		return c.GetPipelineRunsFunc(ctx, fullName)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetPipelineRunStages(ctx context.Context, fullName string, buildNumber int) (*jenkins.PipelineRun, error) {
+	c.record("GetPipelineRunStages", fullName, buildNumber)
+	if c.GetPipelineRunStagesFunc != nil {
+		return c.GetPipelineRunStagesFunc(ctx, fullName, buildNumber)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetPipelineGraph(ctx context.Context, fullName string, buildNumber int) (*jenkins.PipelineGraph, error) {
+	c.record("GetPipelineGraph", fullName, buildNumber)
+	if c.GetPipelineGraphFunc != nil {
+		return c.GetPipelineGraphFunc(ctx, fullName, buildNumber)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetPipelineSteps(ctx context.Context, fullName string, buildNumber int, nodeID string) ([]jenkins.PipelineStep, error) {
+	c.record("GetPipelineSteps", fullName, buildNumber, nodeID)
+	if c.GetPipelineStepsFunc != nil {
+		return c.GetPipelineStepsFunc(ctx, fullName, buildNumber, nodeID)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetPipelineStepLog(ctx context.Context, fullName string, buildNumber int, nodeID string, stepID string) (string, error) {
+	c.record("GetPipelineStepLog", fullName, buildNumber, nodeID, stepID)
+	if c.GetPipelineStepLogFunc != nil {
+		return c.GetPipelineStepLogFunc(ctx, fullName, buildNumber, nodeID, stepID)
+	}
+	return "", nil
+}
+
+func (c *Client) GetConsoleLog(ctx context.Context, fullName string, buildNumber int) (string, error) {
+	c.record("GetConsoleLog", fullName, buildNumber)
+	if c.GetConsoleLogFunc != nil {
+		return c.GetConsoleLogFunc(ctx, fullName, buildNumber)
+	}
+	return "", nil
+}
+
+func (c *Client) GetTestReport(ctx context.Context, fullName string, buildNumber int) (*jenkins.TestReport, error) {
+	c.record("GetTestReport", fullName, buildNumber)
+	if c.GetTestReportFunc != nil {
+		return c.GetTestReportFunc(ctx, fullName, buildNumber)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetArtifacts(ctx context.Context, fullName string, buildNumber int) ([]jenkins.Artifact, error) {
+	c.record("GetArtifacts", fullName, buildNumber)
+	if c.GetArtifactsFunc != nil {
+		return c.GetArtifactsFunc(ctx, fullName, buildNumber)
+	}
+	return nil, nil
+}
+
+func (c *Client) DownloadArtifact(ctx context.Context, fullName string, buildNumber int, relativePath string, destPath string, progress jenkins.ProgressFunc) error {
+	c.record("DownloadArtifact", fullName, buildNumber, relativePath, destPath, progress)
+	if c.DownloadArtifactFunc != nil {
+		return c.DownloadArtifactFunc(ctx, fullName, buildNumber, relativePath, destPath, progress)
+	}
+	return nil
+}
+
+func (c *Client) GetConsoleLogSize(ctx context.Context, fullName string, buildNumber int) (int64, error) {
+	c.record("GetConsoleLogSize", fullName, buildNumber)
+	if c.GetConsoleLogSizeFunc != nil {
+		return c.GetConsoleLogSizeFunc(ctx, fullName, buildNumber)
+	}
+	return 0, nil
+}
+
+func (c *Client) GetProgressiveLog(ctx context.Context, buildURL string, fullName string, buildNumber int, start int64) (string, int64, bool, error) {
+	c.record("GetProgressiveLog", buildURL, fullName, buildNumber, start)
+	if c.GetProgressiveLogFunc != nil {
+		return c.GetProgressiveLogFunc(ctx, buildURL, fullName, buildNumber, start)
+	}
+	return "", 0, false, nil
+}
+
+func (c *Client) GetAnnotatedLog(ctx context.Context, buildURL string, fullName string, buildNumber int, start int64) (jenkins.AnnotatedLog, error) {
+	c.record("GetAnnotatedLog", buildURL, fullName, buildNumber, start)
+	if c.GetAnnotatedLogFunc != nil {
+		return c.GetAnnotatedLogFunc(ctx, buildURL, fullName, buildNumber, start)
+	}
+	return jenkins.AnnotatedLog{}, nil
+}
+
+func (c *Client) GetJobConfig(ctx context.Context, fullName string) (string, error) {
+	c.record("GetJobConfig", fullName)
+	if c.GetJobConfigFunc != nil {
+		return c.GetJobConfigFunc(ctx, fullName)
+	}
+	return "", nil
+}
+
+func (c *Client) UpdateJobConfig(ctx context.Context, fullName string, configXML string) error {
+	c.record("UpdateJobConfig", fullName, configXML)
+	if c.UpdateJobConfigFunc != nil {
+		return c.UpdateJobConfigFunc(ctx, fullName, configXML)
+	}
+	return nil
+}
+
+func (c *Client) GetFolderRelations(ctx context.Context, folderFullName string) ([]jenkins.JobRelations, error) {
+	c.record("GetFolderRelations", folderFullName)
+	if c.GetFolderRelationsFunc != nil {
+		return c.GetFolderRelationsFunc(ctx, folderFullName)
+	}
+	return nil, nil
+}
+
+func (c *Client) GetUser(ctx context.Context, id string) (*jenkins.User, error) {
+	c.record("GetUser", id)
+	if c.GetUserFunc != nil {
+		return c.GetUserFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (c *Client) GenerateAPIToken(ctx context.Context, name string) (*jenkins.APIToken, error) {
+	c.record("GenerateAPIToken", name)
+	if c.GenerateAPITokenFunc != nil {
+		return c.GenerateAPITokenFunc(ctx, name)
+	}
+	return nil, nil
+}
+
+func (c *Client) RevokeAPIToken(ctx context.Context, uuid string) error {
+	c.record("RevokeAPIToken", uuid)
+	if c.RevokeAPITokenFunc != nil {
+		return c.RevokeAPITokenFunc(ctx, uuid)
+	}
+	return nil
+}
+
+func (c *Client) SetToken(token string) {
+	c.record("SetToken", token)
+	if c.SetTokenFunc != nil {
+		c.SetTokenFunc(token)
+	}
+}
+
+func (c *Client) SetRequestObserver(observer jenkins.RequestObserver) {
+	c.record("SetRequestObserver", observer)
+	if c.SetRequestObserverFunc != nil {
+		c.SetRequestObserverFunc(observer)
+	}
+}
@@ -0,0 +1,105 @@
+// Command fakejenkins serves a simulated Jenkins to try the dashboard
+// against without a real server:
+//
+//	go run ./internal/jenkins/jenkinstest/fakejenkins -addr :8080
+//
+// then log in to http://localhost:8080 with any username and token. Builds
+// triggered from the dashboard wait in the queue for a moment, then run,
+// writing their console log line by line, and pass or fail at random.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+// buildLines is how many log lines a simulated build writes before it ends.
+const buildLines = 20
+
+type running struct {
+	job    string
+	number int
+	lines  int
+}
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	large := flag.Bool("large", false, "serve about 20,000 jobs in nested folders instead of a handful")
+	flag.Parse()
+
+	server, err := jenkinstest.NewServerAt(*addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer server.Close()
+	seed(server)
+	if *large {
+		seedLarge(server, jenkinstest.JobTree(jenkinstest.LargeInstance))
+	}
+	log.Printf("fake Jenkins listening on %s", server.URL)
+
+	var builds []running
+	for range time.Tick(time.Second) {
+		for _, item := range server.Queued() {
+			if time.Since(time.UnixMilli(item.InQueueSince)) > 3*time.Second {
+				number := server.StartQueued(item.ID)
+				builds = append(builds, running{job: item.Task.Name, number: number})
+			}
+		}
+
+		active := builds[:0]
+		for _, build := range builds {
+			build.lines++
+			server.AppendLog(build.job, build.number, fmt.Sprintf("[step %d] working on %s\n", build.lines, build.job))
+			if build.lines < buildLines {
+				active = append(active, build)
+				continue
+			}
+			result := "SUCCESS"
+			if rand.IntN(3) == 0 {
+				server.AppendLog(build.job, build.number, "ERROR: simulated failure\n")
+				result = "FAILURE"
+			}
+			server.AppendLog(build.job, build.number, "Finished: "+result+"\n")
+			server.FinishBuild(build.job, build.number, result)
+		}
+		builds = active
+	}
+}
+
+// seed fills the server with a few jobs and some build history.
+func seed(server *jenkinstest.Server) {
+	history := map[string][]string{
+		"api":                        {"SUCCESS", "SUCCESS", "FAILURE", "SUCCESS"},
+		"web":                        {"SUCCESS", "UNSTABLE"},
+		"platform/deploy":            {"SUCCESS", "FAILURE", "FAILURE"},
+		"platform/infra/terraform":   {"SUCCESS"},
+		"platform/infra/nightly-gc":  {"ABORTED", "SUCCESS"},
+		"experiments/flaky-e2e-test": {"FAILURE", "SUCCESS", "FAILURE"},
+	}
+	for _, job := range []string{"api", "web", "platform/deploy", "platform/infra/terraform", "platform/infra/nightly-gc", "experiments/flaky-e2e-test"} {
+		for i, result := range history[job] {
+			server.AddBuild(job, result, fmt.Sprintf("Started by user admin\nBuilding %s #%d\nFinished: %s\n", job, i+1, result))
+		}
+	}
+	server.AddFolder("archive")
+	server.Enqueue("web", "Waiting for next available executor")
+}
+
+// seedLarge adds a generated job tree, to try the dashboard at scale.
+func seedLarge(server *jenkinstest.Server, jobs []jenkins.Job) {
+	for _, job := range jobs {
+		if job.IsFolder() {
+			server.AddFolder(job.FullName)
+			seedLarge(server, job.Jobs)
+			continue
+		}
+		server.AddJob(job.FullName)
+	}
+}
@@ -0,0 +1,621 @@
+// Package jenkinstest provides fakes of Jenkins for tests: Server, an HTTP
+// server speaking enough of the Jenkins REST API for jenkins.Client, and
+// Client, a jenkins.JenkinsClient whose answers are scripted per method.
+package jenkinstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+// Job classes the fake reports.
+const (
+	FolderClass    = "com.cloudbees.hudson.plugins.folder.Folder"
+	FreestyleClass = "hudson.model.FreeStyleProject"
+)
+
+// crumbField is the header the fake expects its crumb in.
+const crumbField = "Jenkins-Crumb"
+
+// Server is a fake Jenkins instance with a job tree, build history with
+// console logs, a build queue and a crumb issuer. Its state is changed with
+// the Add*/Start*/Finish* methods while clients poll it, so tests can play a
+// build through from the queue to its result.
+//
+// Tree parameters only select how deep job trees go and which range of
+// allBuilds is returned; every other field is always sent.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	jobs     map[string]*fakeJob
+	order    []string
+	queue    []jenkins.QueueItem
+	left     map[int]jenkins.QueueItem
+	nextID   int
+	crumb    int
+	requests []string
+}
+
+type fakeJob struct {
+	job    jenkins.Job
+	builds []*fakeBuild
+}
+
+type fakeBuild struct {
+	build jenkins.Build
+	log   string
+}
+
+// NewServer starts a fake Jenkins on a local port. Close it when done.
+func NewServer() *Server {
+	s := newServer()
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// NewServerAt starts a fake Jenkins listening on addr, e.g. ":8080", to run
+// the dashboard against.
+func NewServerAt(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := newServer()
+	s.Server = httptest.NewUnstartedServer(s)
+	s.Server.Listener.Close()
+	s.Server.Listener = listener
+	s.Server.Start()
+	return s, nil
+}
+
+func newServer() *Server {
+	return &Server{
+		jobs:   make(map[string]*fakeJob),
+		left:   make(map[int]jenkins.QueueItem),
+		nextID: 1,
+		crumb:  1,
+	}
+}
+
+// AddFolder adds a folder, and any missing parent folders.
+func (s *Server) AddFolder(fullName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addJob(fullName, FolderClass)
+}
+
+// AddJob adds a freestyle job, and any missing parent folders.
+func (s *Server) AddJob(fullName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addJob(fullName, FreestyleClass)
+}
+
+func (s *Server) addJob(fullName, class string) *fakeJob {
+	if job, ok := s.jobs[fullName]; ok {
+		return job
+	}
+	if i := strings.LastIndex(fullName, "/"); i >= 0 {
+		s.addJob(fullName[:i], FolderClass)
+	}
+	job := &fakeJob{job: jenkins.Job{
+		Name:     fullName[strings.LastIndex(fullName, "/")+1:],
+		FullName: fullName,
+		Class:    class,
+	}}
+	s.jobs[fullName] = job
+	s.order = append(s.order, fullName)
+	return job
+}
+
+// AddBuild adds a finished build with the given result ("SUCCESS",
+// "FAILURE", ...) and console log to a job, adding the job if needed, and
+// returns its number.
+func (s *Server) AddBuild(fullName, result, log string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	build := s.startBuild(fullName)
+	build.log = log
+	finish(build, result)
+	return build.build.Number
+}
+
+// StartBuild starts a build of a job, adding the job if needed, and returns
+// its number. It runs until FinishBuild.
+func (s *Server) StartBuild(fullName string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.startBuild(fullName).build.Number
+}
+
+func (s *Server) startBuild(fullName string) *fakeBuild {
+	job := s.addJob(fullName, FreestyleClass)
+	number := 1
+	if n := len(job.builds); n > 0 {
+		number = job.builds[n-1].build.Number + 1
+	}
+	build := &fakeBuild{build: jenkins.Build{
+		Number:    number,
+		Building:  true,
+		Timestamp: time.Now().UnixMilli(),
+	}}
+	job.builds = append(job.builds, build)
+	return build
+}
+
+// AppendLog adds text to the console log of a build.
+func (s *Server) AppendLog(fullName string, number int, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if build := s.build(fullName, number); build != nil {
+		build.log += text
+	}
+}
+
+// FinishBuild ends a running build with the given result.
+func (s *Server) FinishBuild(fullName string, number int, result string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if build := s.build(fullName, number); build != nil {
+		finish(build, result)
+	}
+}
+
+func finish(build *fakeBuild, result string) {
+	build.build.Building = false
+	build.build.Result = result
+	build.build.Duration = time.Now().UnixMilli() - build.build.Timestamp
+}
+
+func (s *Server) build(fullName string, number int) *fakeBuild {
+	job, ok := s.jobs[fullName]
+	if !ok {
+		return nil
+	}
+	for _, build := range job.builds {
+		if build.build.Number == number {
+			return build
+		}
+	}
+	return nil
+}
+
+// Enqueue puts a job in the build queue, adding the job if needed, and
+// returns the queue item's ID.
+func (s *Server) Enqueue(fullName, why string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enqueue(fullName, why)
+}
+
+func (s *Server) enqueue(fullName, why string) int {
+	s.addJob(fullName, FreestyleClass)
+	item := jenkins.QueueItem{
+		ID:           s.nextID,
+		Buildable:    true,
+		Why:          why,
+		InQueueSince: time.Now().UnixMilli(),
+	}
+	item.Task.Name = fullName
+	s.nextID++
+	s.queue = append(s.queue, item)
+	return item.ID
+}
+
+// StartQueued takes an item off the queue and starts its build, returning
+// the build number, or 0 if no such item is waiting.
+func (s *Server) StartQueued(id int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.dequeue(id)
+	if !ok {
+		return 0
+	}
+	build := s.startBuild(item.Task.Name)
+	item.Executable = &struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+	}{Number: build.build.Number}
+	s.left[id] = item
+	return build.build.Number
+}
+
+func (s *Server) dequeue(id int) (jenkins.QueueItem, bool) {
+	for i, item := range s.queue {
+		if item.ID == id {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return item, true
+		}
+	}
+	return jenkins.QueueItem{}, false
+}
+
+// Queued returns the items waiting in the build queue, oldest first.
+func (s *Server) Queued() []jenkins.QueueItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]jenkins.QueueItem(nil), s.queue...)
+}
+
+// ExpireCrumb issues a new crumb, so requests carrying the old one are
+// rejected as they are when a Jenkins session expires.
+func (s *Server) ExpireCrumb() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.crumb++
+}
+
+// Requests returns the requests served so far as "METHOD /path", without
+// the query.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// ServeHTTP answers a Jenkins REST API request.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if r.Method != http.MethodGet && r.Header.Get(crumbField) != strconv.Itoa(s.crumb) {
+		http.Error(w, "No valid crumb was included in the request", http.StatusForbidden)
+		return
+	}
+
+	base := "http://" + r.Host
+	switch r.URL.Path {
+	case "/api/json":
+		s.writeJSON(w, map[string]any{
+			"_class": "hudson.model.Hudson",
+			"mode":   "NORMAL",
+			"url":    base + "/",
+			"jobs":   s.children("", base, treeDepth(r.URL.Query().Get("tree"))),
+		})
+		return
+	case "/crumbIssuer/api/json":
+		s.writeJSON(w, map[string]string{"crumbRequestField": crumbField, "crumb": strconv.Itoa(s.crumb)})
+		return
+	case "/queue/api/json":
+		s.writeJSON(w, map[string]any{"items": s.queueItems(base)})
+		return
+	case "/queue/cancelItem":
+		id, _ := strconv.Atoi(r.URL.Query().Get("id"))
+		if item, ok := s.dequeue(id); ok {
+			item.Cancelled = true
+			s.left[id] = item
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "/computer/api/json":
+		s.writeJSON(w, map[string]any{"computer": []any{s.builtInNode(base)}})
+		return
+	}
+
+	if id, ok := queueItemID(r.URL.Path); ok {
+		s.serveQueueItem(w, base, id)
+		return
+	}
+
+	fullName, rest, ok := splitJobPath(r.URL.EscapedPath())
+	job := s.jobs[fullName]
+	if !ok || job == nil {
+		http.NotFound(w, r)
+		return
+	}
+	s.serveJob(w, r, base, job, rest)
+}
+
+func (s *Server) serveJob(w http.ResponseWriter, r *http.Request, base string, job *fakeJob, rest []string) {
+	fullName := job.job.FullName
+	switch {
+	case len(rest) == 2 && rest[0] == "api" && rest[1] == "json":
+		s.writeJSON(w, s.jobJSON(job, base, r.URL.Query().Get("tree")))
+		return
+	case len(rest) == 1 && (rest[0] == "build" || rest[0] == "buildWithParameters"):
+		id := s.enqueue(fullName, "Waiting for next available executor")
+		w.Header().Set("Location", fmt.Sprintf("%s/queue/item/%d/", base, id))
+		w.WriteHeader(http.StatusCreated)
+		return
+	case len(rest) == 0:
+		http.NotFound(w, r)
+		return
+	}
+
+	build := s.lookupBuild(job, rest[0])
+	if build == nil {
+		http.NotFound(w, r)
+		return
+	}
+	switch strings.Join(rest[1:], "/") {
+	case "api/json":
+		s.writeJSON(w, s.buildJSON(job, build, base))
+	case "consoleText":
+		w.Header().Set("Content-Type", "text/plain;charset=UTF-8")
+		fmt.Fprint(w, build.log)
+	case "logText/progressiveText":
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		start = min(max(start, 0), len(build.log))
+		w.Header().Set("Content-Type", "text/plain;charset=UTF-8")
+		w.Header().Set("X-Text-Size", strconv.Itoa(len(build.log)))
+		if build.build.Building {
+			w.Header().Set("X-More-Data", "true")
+		}
+		fmt.Fprint(w, build.log[start:])
+	case "stop":
+		if build.build.Building {
+			finish(build, "ABORTED")
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// lookupBuild resolves a build number or the lastBuild permalink.
+func (s *Server) lookupBuild(job *fakeJob, segment string) *fakeBuild {
+	if segment == "lastBuild" {
+		if n := len(job.builds); n > 0 {
+			return job.builds[n-1]
+		}
+		return nil
+	}
+	number, err := strconv.Atoi(segment)
+	if err != nil {
+		return nil
+	}
+	return s.build(job.job.FullName, number)
+}
+
+func (s *Server) serveQueueItem(w http.ResponseWriter, base string, id int) {
+	for _, item := range s.queueItems(base) {
+		if item.ID == id {
+			s.writeJSON(w, item)
+			return
+		}
+	}
+	if item, ok := s.left[id]; ok {
+		s.writeJSON(w, s.queueItemJSON(item, base))
+		return
+	}
+	http.Error(w, "404 page not found", http.StatusNotFound)
+}
+
+// allBuildsRange matches the {from,to} range selector of allBuilds.
+var allBuildsRange = regexp.MustCompile(`allBuilds\[.*\]\{(\d+),(\d+)\}`)
+
+func (s *Server) jobJSON(job *fakeJob, base, tree string) map[string]any {
+	out := s.jobSummary(job, base)
+	out["description"] = job.job.Description
+
+	var builds []jenkins.Build
+	for i := len(job.builds) - 1; i >= 0; i-- {
+		builds = append(builds, s.buildJSON(job, job.builds[i], base))
+	}
+	out["builds"] = builds
+	out["allBuilds"] = builds
+	if match := allBuildsRange.FindStringSubmatch(tree); match != nil {
+		from, _ := strconv.Atoi(match[1])
+		to, _ := strconv.Atoi(match[2])
+		from, to = min(from, len(builds)), min(to, len(builds))
+		out["allBuilds"] = builds[from:max(from, to)]
+	}
+	if len(job.builds) > 0 {
+		out["firstBuild"] = s.buildJSON(job, job.builds[0], base)
+	}
+	if job.job.Class == FolderClass {
+		out["jobs"] = s.children(job.job.FullName, base, treeDepth(tree))
+	}
+	return out
+}
+
+// jobSummary is a job as listed in its folder.
+func (s *Server) jobSummary(job *fakeJob, base string) map[string]any {
+	out := map[string]any{
+		"_class":   job.job.Class,
+		"name":     job.job.Name,
+		"fullName": job.job.FullName,
+		"url":      base + jobPath(job.job.FullName) + "/",
+	}
+	if job.job.Class == FolderClass {
+		return out
+	}
+	out["color"] = color(job)
+	if n := len(job.builds); n > 0 {
+		out["lastBuild"] = s.buildJSON(job, job.builds[n-1], base)
+	}
+	return out
+}
+
+// children lists the jobs directly inside a folder ("" for the top level),
+// with depth levels of nested folders filled in.
+func (s *Server) children(folder, base string, depth int) []map[string]any {
+	prefix := ""
+	if folder != "" {
+		prefix = folder + "/"
+	}
+	jobs := []map[string]any{}
+	for _, fullName := range s.order {
+		rest, ok := strings.CutPrefix(fullName, prefix)
+		if !ok || rest == "" || strings.Contains(rest, "/") {
+			continue
+		}
+		job := s.jobs[fullName]
+		summary := s.jobSummary(job, base)
+		if job.job.Class == FolderClass && depth > 1 {
+			summary["jobs"] = s.children(fullName, base, depth-1)
+		}
+		jobs = append(jobs, summary)
+	}
+	return jobs
+}
+
+func (s *Server) buildJSON(job *fakeJob, build *fakeBuild, base string) jenkins.Build {
+	out := build.build
+	out.URL = fmt.Sprintf("%s%s/%d/", base, jobPath(job.job.FullName), out.Number)
+	if out.DisplayName == "" {
+		out.DisplayName = fmt.Sprintf("#%d", out.Number)
+	}
+	return out
+}
+
+func (s *Server) queueItems(base string) []jenkins.QueueItem {
+	items := make([]jenkins.QueueItem, 0, len(s.queue))
+	for _, item := range s.queue {
+		items = append(items, s.queueItemJSON(item, base))
+	}
+	return items
+}
+
+func (s *Server) queueItemJSON(item jenkins.QueueItem, base string) jenkins.QueueItem {
+	fullName := item.Task.Name
+	item.Task.URL = base + jobPath(fullName) + "/"
+	if job, ok := s.jobs[fullName]; ok {
+		item.Task.Color = color(job)
+	}
+	if item.Executable != nil {
+		executable := *item.Executable
+		executable.URL = fmt.Sprintf("%s%s/%d/", base, jobPath(fullName), executable.Number)
+		item.Executable = &executable
+	}
+	return item
+}
+
+// builtInNode runs every running build, one executor each.
+func (s *Server) builtInNode(base string) map[string]any {
+	type executable struct {
+		FullDisplayName string `json:"fullDisplayName"`
+		Number          int    `json:"number"`
+		URL             string `json:"url"`
+		Timestamp       int64  `json:"timestamp"`
+	}
+	executors := []map[string]any{}
+	names := append([]string(nil), s.order...)
+	sort.Strings(names)
+	for _, fullName := range names {
+		job := s.jobs[fullName]
+		for _, build := range job.builds {
+			if !build.build.Building {
+				continue
+			}
+			executors = append(executors, map[string]any{
+				"idle": false,
+				"currentExecutable": executable{
+					FullDisplayName: fmt.Sprintf("%s #%d", strings.ReplaceAll(fullName, "/", " » "), build.build.Number),
+					Number:          build.build.Number,
+					URL:             s.buildJSON(job, build, base).URL,
+					Timestamp:       build.build.Timestamp,
+				},
+			})
+		}
+	}
+	return map[string]any{
+		"displayName":  "Built-In Node",
+		"numExecutors": len(executors),
+		"idle":         len(executors) == 0,
+		"executors":    executors,
+	}
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// color is a job's ball color: its last finished result, blinking while a
+// build runs.
+func color(job *fakeJob) string {
+	color := "notbuilt"
+	for i := len(job.builds) - 1; i >= 0; i-- {
+		if !job.builds[i].build.Building {
+			color = resultColor(job.builds[i].build.Result)
+			break
+		}
+	}
+	if n := len(job.builds); n > 0 && job.builds[n-1].build.Building {
+		color += "_anime"
+	}
+	return color
+}
+
+func resultColor(result string) string {
+	switch result {
+	case "SUCCESS":
+		return "blue"
+	case "FAILURE":
+		return "red"
+	case "UNSTABLE":
+		return "yellow"
+	case "ABORTED":
+		return "aborted"
+	default:
+		return "notbuilt"
+	}
+}
+
+// treeDepth counts the nested jobs[ selectors of a tree parameter, i.e. how
+// many folder levels the client asked for. Without a tree it is one.
+func treeDepth(tree string) int {
+	if tree == "" {
+		return 1
+	}
+	return strings.Count(tree, "jobs[")
+}
+
+// jobPath is the URL path of a job, e.g. "/job/team/job/api".
+func jobPath(fullName string) string {
+	var b strings.Builder
+	for _, segment := range strings.Split(fullName, "/") {
+		b.WriteString("/job/")
+		b.WriteString(url.PathEscape(segment))
+	}
+	return b.String()
+}
+
+// splitJobPath splits an escaped URL path like "/job/team/job/api/3/api/json"
+// into the job's full name and the remaining segments.
+func splitJobPath(escaped string) (string, []string, bool) {
+	segments := strings.Split(strings.Trim(escaped, "/"), "/")
+	var names []string
+	for len(segments) >= 2 && segments[0] == "job" {
+		name, err := url.PathUnescape(segments[1])
+		if err != nil {
+			return "", nil, false
+		}
+		names = append(names, name)
+		segments = segments[2:]
+	}
+	if len(names) == 0 {
+		return "", nil, false
+	}
+	return strings.Join(names, "/"), segments, true
+}
+
+// queueItemID parses "/queue/item/<id>/api/json".
+func queueItemID(path string) (int, bool) {
+	rest, ok := strings.CutPrefix(path, "/queue/item/")
+	if !ok {
+		return 0, false
+	}
+	idText, tail, _ := strings.Cut(rest, "/")
+	if tail != "api/json" {
+		return 0, false
+	}
+	id, err := strconv.Atoi(idText)
+	return id, err == nil
+}
@@ -0,0 +1,142 @@
+package jenkinstest
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+func TestServerJobTree(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.AddBuild("platform/infra/terraform", "FAILURE", "")
+	server.AddJob("api")
+
+	client := jenkins.NewClient(jenkins.Credentials{URL: server.URL})
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() error = %v", err)
+	}
+	jobs, err := client.GetAllJobs(context.Background())
+	if err != nil {
+		t.Fatalf("GetAllJobs() error = %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].FullName != "platform" || !jobs[0].IsFolder() || jobs[1].FullName != "api" {
+		t.Fatalf("top level = %+v, want the platform folder and api", jobs)
+	}
+	infra := jobs[0].Jobs[0]
+	if len(infra.Jobs) != 1 || infra.Jobs[0].Color != "red" || infra.Jobs[0].LastBuild.Number != 1 {
+		t.Errorf("platform/infra = %+v, want the failed terraform job", infra)
+	}
+
+	// Folders load one level at a time.
+	children, err := client.GetFolderJobs(context.Background(), "platform")
+	if err != nil {
+		t.Fatalf("GetFolderJobs() error = %v", err)
+	}
+	if len(children) != 1 || children[0].FullName != "platform/infra" || len(children[0].Jobs) != 0 {
+		t.Errorf("GetFolderJobs(platform) = %+v, want infra without its children", children)
+	}
+}
+
+func TestServerBuildLifecycle(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.AddBuild("api", "SUCCESS", "done\n")
+	ctx := context.Background()
+	client := jenkins.NewClient(jenkins.Credentials{URL: server.URL})
+
+	queueID, err := client.TriggerBuild(ctx, "api")
+	if err != nil {
+		t.Fatalf("TriggerBuild() error = %v", err)
+	}
+	queue, err := client.GetBuildQueue(ctx)
+	if err != nil || len(queue) != 1 || queue[0].ID != queueID || queue[0].Task.Name != "api" {
+		t.Fatalf("GetBuildQueue() = %+v, %v, want the triggered build", queue, err)
+	}
+
+	number := server.StartQueued(queueID)
+	if got, err := client.WaitForBuildNumber(ctx, queueID); err != nil || got != number || number != 2 {
+		t.Fatalf("WaitForBuildNumber() = %d, %v, want %d (build #2)", got, err, number)
+	}
+	running, err := client.GetRunningBuilds(ctx)
+	if err != nil || len(running) != 1 || running[0].BuildNumber != 2 {
+		t.Fatalf("GetRunningBuilds() = %+v, %v, want api #2", running, err)
+	}
+
+	// The progressive log follows the build until it finishes.
+	server.AppendLog("api", 2, "step 1\n")
+	text, next, more, err := client.GetProgressiveLog(ctx, "", "api", 2, 0)
+	if err != nil || text != "step 1\n" || !more {
+		t.Fatalf("GetProgressiveLog() = %q, more %v, err %v, want the first step and more", text, more, err)
+	}
+	server.AppendLog("api", 2, "step 2\n")
+	server.FinishBuild("api", 2, "FAILURE")
+	text, _, more, err = client.GetProgressiveLog(ctx, "", "api", 2, next)
+	if err != nil || text != "step 2\n" || more {
+		t.Fatalf("GetProgressiveLog() from %d = %q, more %v, err %v, want the rest and no more", next, text, more, err)
+	}
+
+	details, err := client.GetJobDetails(ctx, "api", 10)
+	if err != nil {
+		t.Fatalf("GetJobDetails() error = %v", err)
+	}
+	if details.Color != "red" || len(details.Builds) != 2 || details.Builds[0].Result != "FAILURE" {
+		t.Errorf("details = color %q, builds %+v, want the failed build first", details.Color, details.Builds)
+	}
+	if builds, err := client.GetBuilds(ctx, "api", 1, 5); err != nil || len(builds) != 1 || builds[0].Number != 1 {
+		t.Errorf("GetBuilds(offset 1) = %+v, %v, want build #1", builds, err)
+	}
+}
+
+func TestServerCrumbExpiry(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	server.AddJob("api")
+	ctx := context.Background()
+	client := jenkins.NewClient(jenkins.Credentials{URL: server.URL})
+
+	if _, err := client.TriggerBuild(ctx, "api"); err != nil {
+		t.Fatalf("TriggerBuild() error = %v", err)
+	}
+	server.ExpireCrumb()
+	if _, err := client.TriggerBuild(ctx, "api"); err != nil {
+		t.Fatalf("TriggerBuild() with an expired crumb error = %v", err)
+	}
+
+	crumbs := 0
+	for _, request := range server.Requests() {
+		if request == "GET /crumbIssuer/api/json" {
+			crumbs++
+		}
+	}
+	if crumbs != 2 {
+		t.Errorf("crumb fetched %d times, want once more after it expired", crumbs)
+	}
+	if queued := server.Queued(); len(queued) != 2 {
+		t.Errorf("queue has %d items, want both builds", len(queued))
+	}
+}
+
+func TestClientScripted(t *testing.T) {
+	client := &Client{
+		GetBuildQueueFunc: func(context.Context) ([]jenkins.QueueItem, error) {
+			return []jenkins.QueueItem{{ID: 7}}, nil
+		},
+	}
+	if items, err := client.GetBuildQueue(context.Background()); err != nil || len(items) != 1 || items[0].ID != 7 {
+		t.Errorf("scripted GetBuildQueue() = %+v, %v", items, err)
+	}
+	if number, err := client.TriggerBuild(context.Background(), "api"); err != nil || number != 0 {
+		t.Errorf("unscripted TriggerBuild() = %d, %v, want zero values", number, err)
+	}
+
+	calls := client.CallsTo("TriggerBuild")
+	if len(calls) != 1 || !slices.Equal(calls[0].Args, []any{"api"}) {
+		t.Errorf("TriggerBuild calls = %+v, want one for api", calls)
+	}
+	if len(client.Calls()) != 2 {
+		t.Errorf("recorded %d calls, want 2", len(client.Calls()))
+	}
+}
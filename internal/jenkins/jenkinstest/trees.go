@@ -0,0 +1,118 @@
+package jenkinstest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+// PipelineClass is the class of the Pipeline jobs in generated trees.
+const PipelineClass = "org.jenkinsci.plugins.workflow.job.WorkflowJob"
+
+// TreeShape describes a synthetic job tree for load tests: the top level and
+// every folder down to Depth levels of nesting hold Folders subfolders and
+// Jobs jobs.
+type TreeShape struct {
+	Folders int
+	Depth   int
+	Jobs    int
+}
+
+// LargeInstance is about 20,000 jobs in 110 folders nested two deep, the
+// size the jobs panel's performance budgets are set for.
+var LargeInstance = TreeShape{Folders: 10, Depth: 2, Jobs: 180}
+
+// JobCount returns the number of jobs, not counting folders, in the tree.
+func (s TreeShape) JobCount() int {
+	levels, folders := 0, 1
+	for depth := 0; depth <= s.Depth; depth++ {
+		levels += folders
+		folders *= s.Folders
+	}
+	return levels * s.Jobs
+}
+
+// generatedURL is the server generated trees pretend to come from.
+const generatedURL = "http://jenkins.example.com"
+
+// generatedColors cycles the statuses of generated jobs.
+var generatedColors = []string{"blue", "blue", "red", "yellow", "blue_anime", "notbuilt", "disabled", "aborted"}
+
+// JobTree generates a job tree of the given shape as the jobs fetch returns
+// it: folders with their jobs nested, and a last build on every job that has
+// been built. The same shape always yields the same tree.
+func JobTree(shape TreeShape) []jenkins.Job {
+	return generateJobs(shape, "", shape.Depth, new(int))
+}
+
+func generateJobs(shape TreeShape, parent string, depth int, seq *int) []jenkins.Job {
+	jobs := make([]jenkins.Job, 0, shape.Folders+shape.Jobs)
+	if depth > 0 {
+		for i := 0; i < shape.Folders; i++ {
+			name := fmt.Sprintf("team-%02d", i)
+			fullName := joinName(parent, name)
+			jobs = append(jobs, jenkins.Job{
+				Name:     name,
+				FullName: fullName,
+				URL:      generatedURL + jobPath(fullName) + "/",
+				Class:    FolderClass,
+				Jobs:     generateJobs(shape, fullName, depth-1, seq),
+			})
+		}
+	}
+	for i := 0; i < shape.Jobs; i++ {
+		*seq++
+		name := fmt.Sprintf("svc-%03d", i)
+		jobs = append(jobs, generatedJob(name, joinName(parent, name), *seq))
+	}
+	return jobs
+}
+
+func generatedJob(name, fullName string, seq int) jenkins.Job {
+	jobURL := generatedURL + jobPath(fullName) + "/"
+	job := jenkins.Job{
+		Name:     name,
+		FullName: fullName,
+		URL:      jobURL,
+		Color:    generatedColors[seq%len(generatedColors)],
+		Class:    FreestyleClass,
+	}
+	if seq%3 == 0 {
+		job.Class = PipelineClass
+	}
+	if job.Color == "notbuilt" {
+		return job
+	}
+	number := seq%500 + 1
+	job.LastBuild = &jenkins.Build{
+		Number:    number,
+		Result:    resultOf(job.Color),
+		Duration:  int64(seq%600+30) * 1000,
+		Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(seq) * time.Minute).UnixMilli(),
+		Building:  job.Color == "blue_anime",
+		URL:       fmt.Sprintf("%s%d/", jobURL, number),
+	}
+	return job
+}
+
+func resultOf(color string) string {
+	switch color {
+	case "red":
+		return "FAILURE"
+	case "yellow":
+		return "UNSTABLE"
+	case "aborted":
+		return "ABORTED"
+	case "blue_anime":
+		return ""
+	}
+	return "SUCCESS"
+}
+
+func joinName(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "/" + name
+}
@@ -0,0 +1,40 @@
+package jenkinstest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+func TestJobTree(t *testing.T) {
+	shape := TreeShape{Folders: 3, Depth: 2, Jobs: 4}
+	jobs := JobTree(shape)
+
+	var count func([]jenkins.Job) (int, int)
+	count = func(jobs []jenkins.Job) (folders, leaves int) {
+		for _, job := range jobs {
+			if job.IsFolder() {
+				f, l := count(job.Jobs)
+				folders, leaves = folders+1+f, leaves+l
+				continue
+			}
+			leaves++
+		}
+		return folders, leaves
+	}
+	folders, leaves := count(jobs)
+	// 3 top-level folders with 3 subfolders each; 1+3+9 levels of 4 jobs.
+	if folders != 12 || leaves != 52 || shape.JobCount() != 52 {
+		t.Errorf("generated %d folders and %d jobs (JobCount %d), want 12 and 52", folders, leaves, shape.JobCount())
+	}
+	if got := jobs[1].Jobs[2].Jobs[0].FullName; got != "team-01/team-02/svc-000" {
+		t.Errorf("nested job full name = %q", got)
+	}
+	if !reflect.DeepEqual(jobs, JobTree(shape)) {
+		t.Error("the same shape generated different trees")
+	}
+	if n := LargeInstance.JobCount(); n < 19000 || n > 21000 {
+		t.Errorf("LargeInstance has %d jobs, want about 20,000", n)
+	}
+}
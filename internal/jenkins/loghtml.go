@@ -0,0 +1,285 @@
+package jenkins
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// voidElements never have a closing tag.
+var voidElements = map[string]bool{
+	"br": true, "hr": true, "img": true, "input": true, "meta": true, "wbr": true,
+}
+
+// htmlFrame is an open element of a progressiveHtml chunk.
+type htmlFrame struct {
+	name   string
+	sgr    string
+	hidden bool
+	// node is the Pipeline flow node the element's text was written by.
+	node string
+}
+
+// htmlLogText converts a chunk of progressiveHtml console output back to the
+// text progressiveText would have returned. Console annotations are markup
+// around the original text: links and class spans are unwrapped, inline
+// colors and weights (e.g. from the AnsiColor plugin) become ANSI SGR
+// sequences, hidden spans are dropped and entities are unescaped. Elements
+// left open at the end of the chunk are closed.
+func htmlLogText(chunk string) string {
+	segments, _ := decodeHTMLLog(chunk)
+	if len(segments) == 1 {
+		return segments[0].Text
+	}
+	var b strings.Builder
+	b.Grow(len(chunk))
+	for _, segment := range segments {
+		b.WriteString(segment.Text)
+	}
+	return b.String()
+}
+
+// decodeHTMLLog converts a chunk of progressiveHtml like htmlLogText, split
+// into the segments written by each Pipeline flow node, together with the
+// nodes the chunk announces.
+func decodeHTMLLog(chunk string) ([]LogSegment, []LogNode) {
+	var segments []LogSegment
+	var nodes []LogNode
+	var b strings.Builder
+	b.Grow(len(chunk))
+	segmentNode := ""
+
+	var stack []htmlFrame
+	// flush ends the current segment when the text about to be written
+	// belongs to another node.
+	flush := func() {
+		node := ""
+		for _, frame := range stack {
+			if frame.node != "" {
+				node = frame.node
+			}
+		}
+		if node == segmentNode {
+			return
+		}
+		if b.Len() > 0 {
+			segments = append(segments, LogSegment{NodeID: segmentNode, Text: b.String()})
+			b.Reset()
+		}
+		segmentNode = node
+	}
+	applied := ""
+	restyle := func() {
+		sgr := ""
+		for _, frame := range stack {
+			sgr += frame.sgr
+		}
+		if sgr == applied {
+			return
+		}
+		if len(stack) > 0 {
+			flush()
+		}
+		b.WriteString("\x1b[0m")
+		b.WriteString(sgr)
+		applied = sgr
+	}
+	hidden := func() bool {
+		for _, frame := range stack {
+			if frame.hidden {
+				return true
+			}
+		}
+		return false
+	}
+
+	for len(chunk) > 0 {
+		lt := strings.IndexByte(chunk, '<')
+		if lt < 0 {
+			lt = len(chunk)
+		}
+		if lt > 0 {
+			if !hidden() {
+				flush()
+				b.WriteString(html.UnescapeString(chunk[:lt]))
+			}
+			chunk = chunk[lt:]
+			continue
+		}
+
+		if strings.HasPrefix(chunk, "<!--") {
+			end := strings.Index(chunk, "-->")
+			if end < 0 {
+				break
+			}
+			chunk = chunk[end+3:]
+			continue
+		}
+		gt := strings.IndexByte(chunk, '>')
+		if gt < 0 {
+			// A tag cut off at the end of the chunk.
+			break
+		}
+		tag := chunk[1:gt]
+		chunk = chunk[gt+1:]
+
+		if strings.HasPrefix(tag, "/") {
+			name := strings.ToLower(strings.TrimSpace(tag[1:]))
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].name == name {
+					stack = stack[:i]
+					restyle()
+					break
+				}
+			}
+			continue
+		}
+
+		name, attrs := parseTag(tag)
+		if name == "br" && !hidden() {
+			flush()
+			b.WriteByte('\n')
+		}
+		if voidElements[name] || strings.HasSuffix(tag, "/") {
+			continue
+		}
+		frame := htmlFrame{name: name}
+		switch name {
+		case "b", "strong":
+			frame.sgr = "\x1b[1m"
+		case "i", "em":
+			frame.sgr = "\x1b[3m"
+		case "u":
+			frame.sgr = "\x1b[4m"
+		}
+		if style, ok := attrs["style"]; ok {
+			sgr, hide := styleSGR(style)
+			frame.sgr += sgr
+			frame.hidden = hide
+		}
+		if node, ok := pipelineNode(attrs); ok {
+			frame.node = node.ID
+			nodes = append(nodes, node)
+		} else if id, ok := pipelineNodeClass(attrs["class"]); ok {
+			frame.node = id
+		}
+		stack = append(stack, frame)
+		restyle()
+	}
+
+	stack = nil
+	restyle()
+	if b.Len() > 0 {
+		segments = append(segments, LogSegment{NodeID: segmentNode, Text: b.String()})
+	}
+	return segments, nodes
+}
+
+// parseTag splits the inside of a start tag into its lowercased name and
+// attributes.
+func parseTag(tag string) (string, map[string]string) {
+	tag = strings.TrimSuffix(tag, "/")
+	name, rest, _ := strings.Cut(tag, " ")
+	attrs := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		eq := strings.IndexAny(rest, "= ")
+		if eq < 0 {
+			attrs[strings.ToLower(rest)] = ""
+			break
+		}
+		key := strings.ToLower(rest[:eq])
+		if rest[eq] == ' ' {
+			attrs[key] = ""
+			rest = rest[eq+1:]
+			continue
+		}
+		rest = rest[eq+1:]
+		var value string
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+		attrs[key] = html.UnescapeString(value)
+	}
+	return strings.ToLower(name), attrs
+}
+
+// styleSGR translates the inline CSS Jenkins annotators emit into SGR
+// parameters, and reports whether it hides the element.
+func styleSGR(style string) (string, bool) {
+	var sgr strings.Builder
+	hidden := false
+	for _, declaration := range strings.Split(style, ";") {
+		property, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		property = strings.ToLower(strings.TrimSpace(property))
+		value = strings.ToLower(strings.TrimSpace(value))
+		switch property {
+		case "color":
+			if r, g, bl, ok := parseCSSColor(value); ok {
+				fmt.Fprintf(&sgr, "\x1b[38;2;%d;%d;%dm", r, g, bl)
+			}
+		case "background-color":
+			if r, g, bl, ok := parseCSSColor(value); ok {
+				fmt.Fprintf(&sgr, "\x1b[48;2;%d;%d;%dm", r, g, bl)
+			}
+		case "font-weight":
+			if value == "bold" || value == "bolder" || value == "700" {
+				sgr.WriteString("\x1b[1m")
+			}
+		case "font-style":
+			if value == "italic" {
+				sgr.WriteString("\x1b[3m")
+			}
+		case "text-decoration":
+			if strings.Contains(value, "underline") {
+				sgr.WriteString("\x1b[4m")
+			}
+		case "display":
+			hidden = value == "none"
+		}
+	}
+	return sgr.String(), hidden
+}
+
+// parseCSSColor reads #rgb, #rrggbb and rgb(r, g, b) colors.
+func parseCSSColor(value string) (r, g, b uint8, ok bool) {
+	if hex, found := strings.CutPrefix(value, "#"); found {
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return 0, 0, 0, false
+		}
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		return uint8(n >> 16), uint8(n >> 8), uint8(n), true
+	}
+	if inner, found := strings.CutPrefix(value, "rgb("); found {
+		parts := strings.Split(strings.TrimSuffix(inner, ")"), ",")
+		if len(parts) != 3 {
+			return 0, 0, 0, false
+		}
+		var rgb [3]uint8
+		for i, part := range parts {
+			n, err := strconv.ParseUint(strings.TrimSpace(part), 10, 8)
+			if err != nil {
+				return 0, 0, 0, false
+			}
+			rgb[i] = uint8(n)
+		}
+		return rgb[0], rgb[1], rgb[2], true
+	}
+	return 0, 0, 0, false
+}
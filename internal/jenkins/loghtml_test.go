@@ -0,0 +1,45 @@
+package jenkins
+
+import "testing"
+
+func TestHTMLLogText(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "plain text and entities",
+			html: "a &amp;&amp; b &gt; c &#39;d&#39;\n",
+			want: "a && b > c 'd'\n",
+		},
+		{
+			name: "links and class spans are unwrapped",
+			html: `<span class="timestamp"><b>12:00:01</b> </span>Started by <a href="/user/admin" class="model-link">admin</a>` + "\n",
+			want: "\x1b[0m\x1b[1m12:00:01\x1b[0m Started by admin\n",
+		},
+		{
+			name: "nested inline styles",
+			html: `<span style="color: #00CD00;">ok <span style="font-weight: bold; background-color: rgb(0, 0, 238)">done</span></span>`,
+			want: "\x1b[0m\x1b[38;2;0;205;0mok \x1b[0m\x1b[38;2;0;205;0m\x1b[1m\x1b[48;2;0;0;238mdone\x1b[0m\x1b[38;2;0;205;0m\x1b[0m",
+		},
+		{
+			name: "hidden spans and comments are dropped",
+			html: `<!-- note --><span style="display: none">[Pipeline] node</span>visible<br>next`,
+			want: "visible\nnext",
+		},
+		{
+			name: "elements left open are closed",
+			html: `<span style="color:#f00">red` + "\n" + `<span class="cut`,
+			want: "\x1b[0m\x1b[38;2;255;0;0mred\n\x1b[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := htmlLogText(tt.html); got != tt.want {
+				t.Errorf("htmlLogText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
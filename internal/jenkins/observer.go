@@ -0,0 +1,81 @@
+package jenkins
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestInfo describes one HTTP request to Jenkins.
+type RequestInfo struct {
+	Method string
+	// Path is the request path below the server URL, with its query.
+	Path string
+	// Status is the response status, or 0 when no response arrived.
+	Status int
+	// Latency is the time until the response headers arrived.
+	Latency time.Duration
+	// Err is why no response arrived, e.g. a refused connection.
+	Err error
+	// Size is the length of the response body as announced by the server,
+	// or -1 when unknown (e.g. a chunked response or no response at all).
+	Size int64
+}
+
+// RequestObserver is told about every request a client sends, including
+// crumb fetches, retries and redirects, e.g. to record API traffic for
+// metrics or debugging. OnRequestStart gets only Method and Path. Calls come
+// from the goroutines running the requests, so implementations must be safe
+// for concurrent use and return quickly.
+type RequestObserver interface {
+	OnRequestStart(info RequestInfo)
+	OnRequestEnd(info RequestInfo)
+}
+
+// observedTransport reports the requests going through it to the client's
+// observer, if one is set.
+type observedTransport struct {
+	next   http.RoundTripper
+	client *Client
+	// basePath is the server URL's path (e.g. "/jenkins"), trimmed from
+	// reported paths.
+	basePath string
+}
+
+func (t *observedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	observer := t.client.requestObserver()
+	if observer == nil {
+		return t.next.RoundTrip(req)
+	}
+
+	info := RequestInfo{Method: req.Method, Path: req.URL.RequestURI(), Size: -1}
+	if t.basePath != "" && strings.HasPrefix(info.Path, t.basePath+"/") {
+		info.Path = strings.TrimPrefix(info.Path, t.basePath)
+	}
+	observer.OnRequestStart(info)
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	info.Latency = time.Since(start)
+	info.Err = err
+	if resp != nil {
+		info.Status = resp.StatusCode
+		info.Size = resp.ContentLength
+	}
+	observer.OnRequestEnd(info)
+	return resp, err
+}
+
+// SetRequestObserver reports every request from now on to observer; nil
+// stops reporting.
+func (c *Client) SetRequestObserver(observer RequestObserver) {
+	c.observerMu.Lock()
+	c.observer = observer
+	c.observerMu.Unlock()
+}
+
+func (c *Client) requestObserver() RequestObserver {
+	c.observerMu.RLock()
+	defer c.observerMu.RUnlock()
+	return c.observer
+}
@@ -0,0 +1,77 @@
+package jenkins
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type recordingObserver struct {
+	mu     sync.Mutex
+	starts []RequestInfo
+	ends   []RequestInfo
+}
+
+func (o *recordingObserver) OnRequestStart(info RequestInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.starts = append(o.starts, info)
+}
+
+func (o *recordingObserver) OnRequestEnd(info RequestInfo) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.ends = append(o.ends, info)
+}
+
+func TestRequestObserver(t *testing.T) {
+	// Jenkins served below a context path, as many installations are.
+	server := httptest.NewServer(http.StripPrefix("/jenkins", jenkinsStub()))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL + "/jenkins", RateLimit: 100, RateBurst: 5})
+	observer := &recordingObserver{}
+	client.SetRequestObserver(observer)
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() error = %v", err)
+	}
+
+	if len(observer.starts) != 1 || len(observer.ends) != 1 {
+		t.Fatalf("observed %d starts and %d ends, want 1 each", len(observer.starts), len(observer.ends))
+	}
+	start, end := observer.starts[0], observer.ends[0]
+	if start.Method != http.MethodGet || start.Path != "/api/json" {
+		t.Errorf("start = %s %s, want GET /api/json", start.Method, start.Path)
+	}
+	if end.Path != start.Path || end.Status != http.StatusOK || end.Err != nil || end.Latency <= 0 || end.Size <= 0 {
+		t.Errorf("end = %+v, want a timed 200 for %s", end, start.Path)
+	}
+
+	client.SetRequestObserver(nil)
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() error = %v", err)
+	}
+	if len(observer.ends) != 1 {
+		t.Errorf("observed %d requests after removing the observer, want 1", len(observer.ends))
+	}
+}
+
+func TestRequestObserverTransportError(t *testing.T) {
+	server := httptest.NewServer(jenkinsStub())
+	server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	observer := &recordingObserver{}
+	client.SetRequestObserver(observer)
+	if err := client.TestConnection(context.Background()); err == nil {
+		t.Fatal("TestConnection() to a closed server succeeded")
+	}
+	if len(observer.ends) == 0 {
+		t.Fatal("failed request not observed")
+	}
+	if end := observer.ends[0]; end.Status != 0 || end.Err == nil || end.Size != -1 {
+		t.Errorf("end = %+v, want no status or size and the connection error", end)
+	}
+}
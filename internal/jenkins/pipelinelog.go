@@ -0,0 +1,105 @@
+package jenkins
+
+import (
+	"slices"
+	"strings"
+)
+
+// LogSegment is a run of console text written by one Pipeline flow node.
+// NodeID is empty for text written outside any step, e.g. "Started by user".
+type LogSegment struct {
+	NodeID string
+	Text   string
+}
+
+// LogNode is a flow node announced in the console, such as the
+// "[Pipeline] { (Build)" that opens a stage. Label is set on the blocks of
+// stages and parallel branches.
+type LogNode struct {
+	ID          string
+	EnclosingID string
+	Label       string
+}
+
+// AnnotatedLog is a chunk of a Pipeline build's console output with its
+// console annotations decoded.
+type AnnotatedLog struct {
+	Segments []LogSegment
+	Nodes    []LogNode
+	// Next is the offset to request the following chunk from.
+	Next int64
+	More bool
+}
+
+// Text returns the chunk as GetProgressiveLog would have.
+func (l AnnotatedLog) Text() string {
+	var b strings.Builder
+	for _, segment := range l.Segments {
+		b.WriteString(segment.Text)
+	}
+	return b.String()
+}
+
+// pipelineNode reads the node a "pipeline-new-node" span announces.
+func pipelineNode(attrs map[string]string) (LogNode, bool) {
+	if !slices.Contains(strings.Fields(attrs["class"]), "pipeline-new-node") || attrs["nodeid"] == "" {
+		return LogNode{}, false
+	}
+	return LogNode{
+		ID:          attrs["nodeid"],
+		EnclosingID: attrs["enclosingid"],
+		Label:       attrs["label"],
+	}, true
+}
+
+// pipelineNodeClass reads the node ID from the "pipeline-node-<id>" class
+// Jenkins puts around the lines a step wrote.
+func pipelineNodeClass(class string) (string, bool) {
+	for _, name := range strings.Fields(class) {
+		if id, ok := strings.CutPrefix(name, "pipeline-node-"); ok && id != "" {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// parallelBranchPrefix is how Jenkins labels the block of a parallel branch.
+const parallelBranchPrefix = "Branch: "
+
+// StageIndex attributes flow nodes to the stages enclosing them, from the
+// nodes announced in a build's console. The zero value is ready to use.
+type StageIndex struct {
+	nodes map[string]LogNode
+}
+
+// Add records announced nodes.
+func (s *StageIndex) Add(nodes ...LogNode) {
+	if len(nodes) == 0 {
+		return
+	}
+	if s.nodes == nil {
+		s.nodes = make(map[string]LogNode, len(nodes))
+	}
+	for _, node := range nodes {
+		s.nodes[node.ID] = node
+	}
+}
+
+// Stage returns the stages and parallel branches enclosing node, outermost
+// first, e.g. ["Tests", "unit"]. It is empty for text outside any stage.
+func (s *StageIndex) Stage(nodeID string) []string {
+	var path []string
+	// Bounded by the node count in case of a cycle in malformed input.
+	for i := 0; nodeID != "" && i <= len(s.nodes); i++ {
+		node, ok := s.nodes[nodeID]
+		if !ok {
+			break
+		}
+		if node.Label != "" {
+			path = append(path, strings.TrimPrefix(node.Label, parallelBranchPrefix))
+		}
+		nodeID = node.EnclosingID
+	}
+	slices.Reverse(path)
+	return path
+}
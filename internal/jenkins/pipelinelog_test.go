@@ -0,0 +1,85 @@
+package jenkins
+
+import (
+	"reflect"
+	"testing"
+)
+
+// pipelineHTML is progressiveHtml as Jenkins renders it for a Pipeline with a
+// "Tests" stage running two parallel branches.
+const pipelineHTML = `Started by user admin
+<span class="pipeline-new-node" nodeId="3" enclosingId="2">[Pipeline] stage
+</span><span class="pipeline-new-node" nodeId="4" startId="3" enclosingId="2" label="Tests">[Pipeline] { (Tests)
+</span><span class="pipeline-new-node" nodeId="7" startId="5" enclosingId="4" label="Branch: unit">[Pipeline] { (Branch: unit)
+</span><span class="pipeline-new-node" nodeId="9" enclosingId="7">[Pipeline] sh
+</span><span class="pipeline-node-9">+ go test ./...
+ok  	app	0.1s
+</span><span class="pipeline-new-node" nodeId="10" enclosingId="4" label="Branch: lint">[Pipeline] { (Branch: lint)
+</span><span class="pipeline-node-11"><span style="color: #CD0000;">lint failed</span>
+</span>Finished: FAILURE
+`
+
+func TestDecodeHTMLLog(t *testing.T) {
+	segments, nodes := decodeHTMLLog(pipelineHTML)
+
+	wantNodes := []LogNode{
+		{ID: "3", EnclosingID: "2"},
+		{ID: "4", EnclosingID: "2", Label: "Tests"},
+		{ID: "7", EnclosingID: "4", Label: "Branch: unit"},
+		{ID: "9", EnclosingID: "7"},
+		{ID: "10", EnclosingID: "4", Label: "Branch: lint"},
+	}
+	if !reflect.DeepEqual(nodes, wantNodes) {
+		t.Errorf("nodes = %+v, want %+v", nodes, wantNodes)
+	}
+
+	wantSegments := []LogSegment{
+		{Text: "Started by user admin\n"},
+		{NodeID: "3", Text: "[Pipeline] stage\n"},
+		{NodeID: "4", Text: "[Pipeline] { (Tests)\n"},
+		{NodeID: "7", Text: "[Pipeline] { (Branch: unit)\n"},
+		{NodeID: "9", Text: "[Pipeline] sh\n+ go test ./...\nok  \tapp\t0.1s\n"},
+		{NodeID: "10", Text: "[Pipeline] { (Branch: lint)\n"},
+		{NodeID: "11", Text: "\x1b[0m\x1b[38;2;205;0;0mlint failed\x1b[0m\n"},
+		{Text: "Finished: FAILURE\n"},
+	}
+	if !reflect.DeepEqual(segments, wantSegments) {
+		t.Errorf("segments =\n%q\nwant\n%q", segments, wantSegments)
+	}
+
+	// The text is unchanged by the split.
+	log := AnnotatedLog{Segments: segments}
+	if log.Text() != htmlLogText(pipelineHTML) {
+		t.Errorf("Text() = %q, want %q", log.Text(), htmlLogText(pipelineHTML))
+	}
+}
+
+func TestStageIndex(t *testing.T) {
+	_, nodes := decodeHTMLLog(pipelineHTML)
+	var index StageIndex
+	index.Add(nodes...)
+	// A node announced in a later chunk.
+	index.Add(LogNode{ID: "11", EnclosingID: "10"})
+
+	tests := []struct {
+		node string
+		want []string
+	}{
+		{node: "", want: nil},
+		{node: "3", want: nil},
+		{node: "4", want: []string{"Tests"}},
+		{node: "9", want: []string{"Tests", "unit"}},
+		{node: "11", want: []string{"Tests", "lint"}},
+		{node: "99", want: nil},
+	}
+	for _, tt := range tests {
+		if got := index.Stage(tt.node); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Stage(%q) = %q, want %q", tt.node, got, tt.want)
+		}
+	}
+
+	var empty StageIndex
+	if got := empty.Stage("4"); got != nil {
+		t.Errorf("zero StageIndex Stage() = %q, want nil", got)
+	}
+}
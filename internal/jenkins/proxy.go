@@ -0,0 +1,52 @@
+package jenkins
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ProxyDirect as a server's proxy connects straight to Jenkins, ignoring the
+// HTTPS_PROXY and HTTP_PROXY environment variables.
+const ProxyDirect = "direct"
+
+// ErrInvalidProxy is returned, wrapped, for every request of a client whose
+// proxy setting cannot be used.
+var ErrInvalidProxy = errors.New("invalid proxy")
+
+// setProxy applies a server's proxy setting to a clone of the default
+// transport. Without one the transport keeps honoring the proxy environment
+// variables; the setting overrides them. Proxies are URLs with an http,
+// https, socks5 or socks5h scheme, e.g. socks5://127.0.0.1:1080 for an
+// `ssh -D 1080 bastion` tunnel.
+func setProxy(transport *http.Transport, proxy string) {
+	switch proxy {
+	case "":
+		return
+	case ProxyDirect:
+		transport.Proxy = nil
+		return
+	}
+
+	proxyURL, err := parseProxyURL(proxy)
+	transport.Proxy = func(*http.Request) (*url.URL, error) {
+		return proxyURL, err
+	}
+}
+
+func parseProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidProxy, proxy, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("%w %q: scheme must be http, https, socks5 or socks5h", ErrInvalidProxy, proxy)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("%w %q: missing host", ErrInvalidProxy, proxy)
+	}
+	return proxyURL, nil
+}
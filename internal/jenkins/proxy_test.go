@@ -0,0 +1,150 @@
+package jenkins
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func jenkinsStub() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"mode":"NORMAL"}`)
+	})
+}
+
+func TestHTTPProxy(t *testing.T) {
+	var hosts []string
+	jenkins := jenkinsStub()
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Requests through an HTTP proxy carry the absolute target URL.
+		hosts = append(hosts, r.URL.Host)
+		jenkins.ServeHTTP(w, r)
+	}))
+	defer proxy.Close()
+
+	client := NewClient(Credentials{URL: "http://jenkins.staging.invalid", Proxy: proxy.URL})
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() through the proxy error = %v", err)
+	}
+	if len(hosts) == 0 || hosts[len(hosts)-1] != "jenkins.staging.invalid" {
+		t.Errorf("proxy saw hosts %v, want jenkins.staging.invalid", hosts)
+	}
+}
+
+func TestSOCKS5Proxy(t *testing.T) {
+	backend := httptest.NewServer(jenkinsStub())
+	defer backend.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	requested := make(chan string, 4)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS5(conn, backend.Listener.Addr().String(), requested)
+		}
+	}()
+
+	client := NewClient(Credentials{
+		URL:   "http://jenkins.staging.invalid:8080",
+		Proxy: "socks5://" + listener.Addr().String(),
+	})
+	if err := client.TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() through the tunnel error = %v", err)
+	}
+	// The tunnel resolves the name, as ssh -D does on the bastion.
+	if got := <-requested; got != "jenkins.staging.invalid:8080" {
+		t.Errorf("SOCKS5 CONNECT to %q, want jenkins.staging.invalid:8080", got)
+	}
+}
+
+// serveSOCKS5 answers one no-auth CONNECT (RFC 1928) and relays it to target,
+// whatever address was asked for.
+func serveSOCKS5(conn net.Conn, target string, requested chan<- string) {
+	defer conn.Close()
+
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(conn, make([]byte, header[1])); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 0})
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(conn, request); err != nil {
+		return
+	}
+	var host string
+	switch request[3] {
+	case 1:
+		addr := make([]byte, 4)
+		io.ReadFull(conn, addr)
+		host = net.IP(addr).String()
+	case 3:
+		size := make([]byte, 1)
+		io.ReadFull(conn, size)
+		name := make([]byte, size[0])
+		io.ReadFull(conn, name)
+		host = string(name)
+	default:
+		return
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(conn, port); err != nil {
+		return
+	}
+	requested <- net.JoinHostPort(host, fmt.Sprint(binary.BigEndian.Uint16(port)))
+
+	upstream, err := net.Dial("tcp", target)
+	if err != nil {
+		conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go io.Copy(upstream, conn)
+	io.Copy(conn, upstream)
+}
+
+func TestInvalidProxy(t *testing.T) {
+	for _, proxy := range []string{"ftp://proxy:21", "socks5://", "http://[::1"} {
+		client := NewClient(Credentials{URL: "http://jenkins.invalid", Proxy: proxy})
+		err := client.TestConnection(context.Background())
+		if !errors.Is(err, ErrInvalidProxy) {
+			t.Errorf("TestConnection() with proxy %q error = %v, want ErrInvalidProxy", proxy, err)
+		}
+	}
+}
+
+func TestProxyTransport(t *testing.T) {
+	if newTransport(Credentials{}) != sharedTransport {
+		t.Error("newTransport() without a proxy is not the shared transport")
+	}
+	if sharedTransport.Proxy == nil {
+		t.Error("the shared transport ignores the proxy environment variables")
+	}
+	direct, ok := newTransport(Credentials{Proxy: ProxyDirect}).(*http.Transport)
+	if !ok || direct.Proxy != nil {
+		t.Error("newTransport() with a direct proxy still consults a proxy")
+	}
+}
@@ -0,0 +1,71 @@
+package jenkins
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(4, 2)
+	limiter.now = func() time.Time { return now }
+
+	// The burst goes out at once, then requests are spaced at the rate.
+	for i, want := range []time.Duration{0, 0, 250 * time.Millisecond, 500 * time.Millisecond} {
+		if got := limiter.reserve(); got != want {
+			t.Errorf("reserve() #%d = %v, want %v", i+1, got, want)
+		}
+	}
+
+	// A quiet second refills the bucket, but no further than the burst.
+	now = now.Add(2 * time.Second)
+	for i, want := range []time.Duration{0, 0, 250 * time.Millisecond} {
+		if got := limiter.reserve(); got != want {
+			t.Errorf("reserve() after a pause #%d = %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestRateLimiterWaitCanceled(t *testing.T) {
+	limiter := newRateLimiter(0.01, 1)
+	now := time.Now()
+	limiter.now = func() time.Time { return now }
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() with a token error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() on an empty bucket error = %v, want the context deadline", err)
+	}
+	if limiter.tokens != 0 {
+		t.Errorf("tokens after the canceled wait = %v, want the reservation returned", limiter.tokens)
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	server := httptest.NewServer(jenkinsStub())
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL, RateLimit: 50, RateBurst: 1})
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := client.TestConnection(context.Background()); err != nil {
+			t.Fatalf("TestConnection() error = %v", err)
+		}
+	}
+	// One request from the burst, three spaced 20ms apart.
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("4 requests at 50/s took %v, want at least 60ms", elapsed)
+	}
+	if _, ok := client.(*Client).HTTPClient.Transport.(*rateLimitedTransport); !ok {
+		t.Error("client transport is not rate limited")
+	}
+	if _, ok := NewClient(Credentials{URL: server.URL}).(*Client).HTTPClient.Transport.(*observedTransport); !ok {
+		t.Error("client without a rate limit is throttled")
+	}
+}
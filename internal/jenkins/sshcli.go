@@ -0,0 +1,372 @@
+package jenkins
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSHOptions send some operations through the Jenkins SSH CLI, for servers
+// behind reverse proxies that block them over HTTP.
+type SSHOptions struct {
+	// Endpoint is the host:port of Jenkins' SSH server. Empty asks Jenkins,
+	// which advertises it in the X-SSH-Endpoint header.
+	Endpoint string
+	// IdentityFile is the private key to offer; empty leaves it to ssh.
+	IdentityFile string
+}
+
+// sshEndpointHeader is the header Jenkins advertises its SSH server in.
+const sshEndpointHeader = "X-SSH-Endpoint"
+
+// sshFollowIdle is how long a followed log may go unread before its ssh
+// session is closed, e.g. after the console was left for another build.
+var sshFollowIdle = time.Minute
+
+// sshClient is a Client that triggers builds, reads console logs and checks
+// the connection (who-am-i) through the Jenkins SSH CLI with the system ssh
+// command, so the user's ssh configuration and agent apply. Everything else
+// goes over HTTP.
+type sshClient struct {
+	*Client
+	options SSHOptions
+
+	// run executes a CLI command on the server and returns its output.
+	run func(ctx context.Context, args ...string) ([]byte, error)
+	// stream executes a CLI command, copying its output to w as it comes.
+	stream func(ctx context.Context, w io.Writer, args ...string) error
+
+	// follows are the logs being streamed, by job and build number.
+	follows   map[string]*logFollow
+	followsMu sync.Mutex
+
+	// endpoint is the discovered SSH endpoint, once known.
+	endpoint   string
+	endpointMu sync.Mutex
+}
+
+func newSSHClient(client *Client, options SSHOptions) *sshClient {
+	c := &sshClient{Client: client, options: options, follows: make(map[string]*logFollow)}
+	c.run = c.runSSH
+	c.stream = c.streamSSH
+	return c
+}
+
+// ReportsQueueItems reports whether client's triggers return the queue item
+// of the build they start, which waiting for the build needs. Builds
+// triggered through the SSH CLI return none.
+func ReportsQueueItems(client JenkinsClient) bool {
+	_, ssh := client.(*sshClient)
+	return !ssh
+}
+
+// TestConnection checks that Jenkins accepts the SSH key as the configured user.
+func (c *sshClient) TestConnection(ctx context.Context) error {
+	out, err := c.run(ctx, "who-am-i")
+	if err != nil {
+		return err
+	}
+	user, _, _ := strings.Cut(strings.TrimPrefix(string(out), "Authenticated as: "), "\n")
+	user = strings.TrimSpace(user)
+	if user == "" || user == "anonymous" {
+		return fmt.Errorf("Jenkins did not accept the SSH key; add its public key to the user's SSH Public Keys")
+	}
+	return nil
+}
+
+// TriggerBuild starts a build with the CLI build command. The CLI does not
+// report the queue item, so the returned ID is always zero.
+func (c *sshClient) TriggerBuild(ctx context.Context, fullName string) (int, error) {
+	if fullName == "" {
+		return 0, fmt.Errorf("job name must not be empty")
+	}
+	if c.readOnly {
+		return 0, ErrReadOnly
+	}
+	if _, err := c.run(ctx, "build", fullName); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// TriggerBuildWithParameters starts a build with the CLI build command, passing
+// the values with -p. The returned queue item ID is always zero.
+func (c *sshClient) TriggerBuildWithParameters(ctx context.Context, fullName string, params map[string]string) (int, error) {
+	if fullName == "" {
+		return 0, fmt.Errorf("job name must not be empty")
+	}
+	if c.readOnly {
+		return 0, ErrReadOnly
+	}
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := []string{"build", fullName}
+	for _, name := range names {
+		args = append(args, "-p", name+"="+params[name])
+	}
+	if _, err := c.run(ctx, args...); err != nil {
+		return 0, err
+	}
+	return 0, nil
+}
+
+// GetConsoleLog reads a build's whole log with the CLI console command.
+func (c *sshClient) GetConsoleLog(ctx context.Context, fullName string, buildNumber int) (string, error) {
+	if fullName == "" || buildNumber <= 0 {
+		return c.Client.GetConsoleLog(ctx, fullName, buildNumber)
+	}
+	out, err := c.run(ctx, "console", fullName, fmt.Sprint(buildNumber))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// GetProgressiveLog reads the log from start on. The CLI has no offsets, so
+// the log is streamed with `console -f` in one ssh session, which prints
+// what the build writes until it ends, and each read returns what arrived
+// since start. Builds known only by URL are read over HTTP.
+func (c *sshClient) GetProgressiveLog(ctx context.Context, buildURL, fullName string, buildNumber int, start int64) (string, int64, bool, error) {
+	if fullName == "" || buildNumber <= 0 {
+		return c.Client.GetProgressiveLog(ctx, buildURL, fullName, buildNumber, start)
+	}
+	key := fmt.Sprintf("%s#%d", fullName, buildNumber)
+	follow := c.follow(key, fullName, buildNumber)
+	text, next, more, err := follow.read(start)
+	if !more {
+		// The log was read to its end; a later read streams it afresh.
+		c.followsMu.Lock()
+		if c.follows[key] == follow {
+			delete(c.follows, key)
+		}
+		c.followsMu.Unlock()
+	}
+	return text, next, more, err
+}
+
+// follow returns the stream of a build's log, starting it unless one is
+// running or holds output not read yet.
+func (c *sshClient) follow(key, fullName string, buildNumber int) *logFollow {
+	c.followsMu.Lock()
+	defer c.followsMu.Unlock()
+	if follow, ok := c.follows[key]; ok && !follow.isAbandoned() {
+		return follow
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	follow := &logFollow{lastRead: time.Now(), cancel: cancel}
+	c.follows[key] = follow
+	go func() {
+		err := c.stream(ctx, follow, "console", fullName, fmt.Sprint(buildNumber), "-f")
+		follow.finish(err)
+		cancel()
+	}()
+	go follow.closeWhenIdle(ctx)
+	return follow
+}
+
+// GetAnnotatedLog reads the log like GetProgressiveLog. The CLI strips the
+// Pipeline annotations, so lines are not attributed to flow nodes.
+func (c *sshClient) GetAnnotatedLog(ctx context.Context, buildURL, fullName string, buildNumber int, start int64) (AnnotatedLog, error) {
+	if fullName == "" || buildNumber <= 0 {
+		return c.Client.GetAnnotatedLog(ctx, buildURL, fullName, buildNumber, start)
+	}
+	text, next, more, err := c.GetProgressiveLog(ctx, buildURL, fullName, buildNumber, start)
+	if err != nil {
+		return AnnotatedLog{Next: start}, err
+	}
+	log := AnnotatedLog{Next: next, More: more}
+	if text != "" {
+		log.Segments = []LogSegment{{Text: text}}
+	}
+	return log, nil
+}
+
+// logFollow is a build's log as streamed by `console -f` so far.
+type logFollow struct {
+	mu        sync.Mutex
+	text      []byte
+	done      bool
+	err       error
+	abandoned bool
+	lastRead  time.Time
+	cancel    context.CancelFunc
+}
+
+// Write appends streamed output.
+func (f *logFollow) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.text = append(f.text, p...)
+	return len(p), nil
+}
+
+// finish records that the stream ended, with the build or with err.
+func (f *logFollow) finish(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.done = true
+	if !f.abandoned {
+		f.err = err
+	}
+}
+
+// read returns the log from start on and its end, and whether more is to
+// come. A start beyond what arrived yet, as after a restarted stream,
+// waits for the stream to catch up.
+func (f *logFollow) read(start int64) (string, int64, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastRead = time.Now()
+	end := int64(len(f.text))
+	if start < 0 {
+		start = end
+	}
+	switch {
+	case start < end:
+		// Report a failed stream on the next read, with this text delivered.
+		return string(f.text[start:]), end, !f.done || f.err != nil, nil
+	case f.done:
+		return "", start, false, f.err
+	}
+	return "", start, true, nil
+}
+
+// closeWhenIdle ends the stream once nobody read it for sshFollowIdle.
+func (f *logFollow) closeWhenIdle(ctx context.Context) {
+	ticker := time.NewTicker(sshFollowIdle / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.mu.Lock()
+			idle := time.Since(f.lastRead) > sshFollowIdle
+			f.abandoned = idle
+			f.mu.Unlock()
+			if idle {
+				f.cancel()
+				return
+			}
+		}
+	}
+}
+
+func (f *logFollow) isAbandoned() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.abandoned
+}
+
+// runSSH runs a CLI command with ssh as the configured user and returns its
+// output.
+func (c *sshClient) runSSH(ctx context.Context, args ...string) ([]byte, error) {
+	var stdout bytes.Buffer
+	if err := c.streamSSH(ctx, &stdout, args...); err != nil {
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// streamSSH runs a CLI command with ssh as the configured user, copying its
+// output to w. ssh runs in batch mode, so it fails rather than prompting
+// inside the dashboard.
+func (c *sshClient) streamSSH(ctx context.Context, w io.Writer, args ...string) error {
+	endpoint, err := c.sshEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid SSH endpoint %q: %w", endpoint, err)
+	}
+
+	sshArgs := []string{"-p", port, "-o", "BatchMode=yes"}
+	if c.options.IdentityFile != "" {
+		sshArgs = append(sshArgs, "-i", c.options.IdentityFile)
+	}
+	if c.Username != "" {
+		host = c.Username + "@" + host
+	}
+	sshArgs = append(sshArgs, host, "--")
+	for _, arg := range args {
+		sshArgs = append(sshArgs, quoteCLIArg(arg))
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "ssh", sshArgs...)
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to run %s over SSH: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// sshEndpoint returns the configured endpoint, or the one Jenkins advertises.
+func (c *sshClient) sshEndpoint(ctx context.Context) (string, error) {
+	if c.options.Endpoint != "" {
+		return c.options.Endpoint, nil
+	}
+	c.endpointMu.Lock()
+	defer c.endpointMu.Unlock()
+	if c.endpoint == "" {
+		endpoint, err := c.discoverSSHEndpoint(ctx)
+		if err != nil {
+			return "", err
+		}
+		c.endpoint = endpoint
+	}
+	return c.endpoint, nil
+}
+
+// errNoSSHEndpoint is returned when Jenkins does not advertise its
+// SSH server, usually because the SSH server port is disabled.
+var errNoSSHEndpoint = errors.New("Jenkins does not advertise an SSH endpoint; enable the SSH server or set ssh.endpoint")
+
+func (c *sshClient) discoverSSHEndpoint(ctx context.Context) (string, error) {
+	resp, err := c.doRequest(ctx, http.MethodHead, "/", nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to discover the SSH endpoint: %w", err)
+	}
+	resp.Body.Close()
+
+	endpoint := strings.TrimSpace(resp.Header.Get(sshEndpointHeader))
+	if endpoint == "" {
+		return "", errNoSSHEndpoint
+	}
+	// Listening on every interface, Jenkins advertises no host or a wildcard
+	// one; the port is then on the host the dashboard reaches it by.
+	if host, port, err := net.SplitHostPort(endpoint); err == nil && (host == "" || host == "0.0.0.0") {
+		if parsed, err := url.Parse(c.BaseURL); err == nil {
+			endpoint = net.JoinHostPort(parsed.Hostname(), port)
+		}
+	}
+	return endpoint, nil
+}
+
+// quoteCLIArg quotes an argument for the command line the Jenkins SSH
+// server tokenizes, as ssh joins the remote command's arguments with spaces.
+func quoteCLIArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\") {
+		return arg
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(arg) + `"`
+}
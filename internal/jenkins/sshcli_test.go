@@ -0,0 +1,114 @@
+package jenkins
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSSHClientRunsCLICommands(t *testing.T) {
+	client := NewClient(Credentials{URL: "https://ci.example.com", Username: "alice", SSH: &SSHOptions{Endpoint: "ci.example.com:2222"}}).(*sshClient)
+	var calls [][]string
+	client.run = func(ctx context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, args)
+		if args[0] == "who-am-i" {
+			return []byte("Authenticated as: alice\nAuthorities:\n  authenticated\n"), nil
+		}
+		return nil, nil
+	}
+	ctx := context.Background()
+
+	if err := client.TestConnection(ctx); err != nil {
+		t.Errorf("TestConnection() error = %v", err)
+	}
+	if _, err := client.TriggerBuildWithParameters(ctx, "Deploy/api", map[string]string{"ENV": "prod", "DRY_RUN": "false"}); err != nil {
+		t.Fatalf("TriggerBuildWithParameters() error = %v", err)
+	}
+	want := []string{"build", "Deploy/api", "-p", "DRY_RUN=false", "-p", "ENV=prod"}
+	if got := calls[len(calls)-1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("build command = %q, want %q", got, want)
+	}
+
+}
+
+func TestSSHClientStreamsConsoleLogs(t *testing.T) {
+	client := NewClient(Credentials{URL: "https://ci.example.com", SSH: &SSHOptions{Endpoint: "ci.example.com:2222"}}).(*sshClient)
+	var streams [][]string
+	written := make(chan struct{})
+	finish := make(chan struct{})
+	client.stream = func(ctx context.Context, w io.Writer, args ...string) error {
+		streams = append(streams, args)
+		io.WriteString(w, "Started by user alice\nBuilding...\n")
+		written <- struct{}{}
+		<-finish
+		io.WriteString(w, "Finished: SUCCESS\n")
+		return nil
+	}
+	ctx := context.Background()
+
+	// The first read starts the stream, which has nothing yet or the start.
+	_, next, more, err := client.GetProgressiveLog(ctx, "", "Deploy/api", 7, 0)
+	if err != nil || !more {
+		t.Fatalf("GetProgressiveLog() = %d, %v, %v; want the log to follow", next, more, err)
+	}
+	<-written
+	chunk, next, more, _ := client.GetProgressiveLog(ctx, "", "Deploy/api", 7, next)
+	if chunk == "" || !more {
+		t.Fatalf("GetProgressiveLog() = %q, more %v; want the running log", chunk, more)
+	}
+
+	close(finish)
+	for deadline := time.Now().Add(time.Second); more && time.Now().Before(deadline); {
+		_, next, more, err = client.GetProgressiveLog(ctx, "", "Deploy/api", 7, next)
+	}
+	if err != nil || more || next != int64(len("Started by user alice\nBuilding...\nFinished: SUCCESS\n")) {
+		t.Errorf("GetProgressiveLog() ended at %d, more %v, %v; want the whole log", next, more, err)
+	}
+	if want := []string{"console", "Deploy/api", "7", "-f"}; len(streams) != 1 || !reflect.DeepEqual(streams[0], want) {
+		t.Errorf("streams = %q, want one %q for every read", streams, want)
+	}
+	if len(client.follows) != 0 {
+		t.Error("the finished stream is kept after its end was read")
+	}
+}
+
+func TestSSHEndpointDiscovery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(sshEndpointHeader, "0.0.0.0:2222")
+	}))
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL, SSH: &SSHOptions{}}).(*sshClient)
+	endpoint, err := client.sshEndpoint(context.Background())
+	if err != nil {
+		t.Fatalf("sshEndpoint() error = %v", err)
+	}
+	parsed, _ := url.Parse(server.URL)
+	if want := parsed.Hostname() + ":2222"; endpoint != want {
+		t.Errorf("sshEndpoint() = %q, want %q", endpoint, want)
+	}
+
+	silent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer silent.Close()
+	if _, err := NewClient(Credentials{URL: silent.URL, SSH: &SSHOptions{}}).(*sshClient).sshEndpoint(context.Background()); err != errNoSSHEndpoint {
+		t.Errorf("sshEndpoint() without the header error = %v, want errNoSSHEndpoint", err)
+	}
+}
+
+func TestQuoteCLIArg(t *testing.T) {
+	for arg, want := range map[string]string{
+		"Deploy/api":      "Deploy/api",
+		"MESSAGE=ship it": `"MESSAGE=ship it"`,
+		`PATH=C:\tmp "x"`: `"PATH=C:\\tmp \"x\""`,
+		"":                `""`,
+	} {
+		if got := quoteCLIArg(arg); got != want {
+			t.Errorf("quoteCLIArg(%q) = %s, want %s", arg, got, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package jenkins
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Connection pool sizing for the polling pattern: the queue, details and
+// console panels poll the same host concurrently with more requests than the
+// two idle connections per host net/http keeps by default, so connections
+// (and TLS handshakes) were redone every round.
+const (
+	maxIdleConns        = 64
+	maxIdleConnsPerHost = 16
+	// idleConnTimeout outlasts the slowest regular poll, including the
+	// tenfold idle backoff of the 5 second refresh.
+	idleConnTimeout = 2 * time.Minute
+)
+
+// sharedTransport serves every client without per-server settings, so they
+// share one connection pool. Responses are gzip-compressed whenever Jenkins
+// supports it: the transport asks for gzip and decompresses transparently,
+// as long as requests don't set Accept-Encoding themselves.
+var sharedTransport = pooledTransport()
+
+func pooledTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.IdleConnTimeout = idleConnTimeout
+	return transport
+}
+
+// ErrInvalidTLSConfig is returned, wrapped, for every request of a client
+// whose CA bundle or client certificate cannot be loaded.
+var ErrInvalidTLSConfig = errors.New("invalid TLS config")
+
+// TLSOptions adjust how a client verifies and authenticates to an HTTPS
+// Jenkins, e.g. one behind a corporate CA or requiring mutual TLS.
+type TLSOptions struct {
+	// CAFile is a PEM bundle of extra CAs trusted besides the system ones.
+	CAFile string
+	// InsecureSkipVerify accepts any server certificate. Prefer CAFile.
+	InsecureSkipVerify bool
+	// CertFile and KeyFile are the PEM client certificate and key for mutual
+	// TLS. KeyFile may be empty when CertFile holds both.
+	CertFile string
+	KeyFile  string
+}
+
+func (o TLSOptions) isZero() bool {
+	return o == TLSOptions{}
+}
+
+// config builds the tls.Config for the options.
+func (o TLSOptions) config() (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: o.InsecureSkipVerify}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTLSConfig, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			// Minimal containers may have no system pool; trust CAFile alone.
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no PEM certificates in %s", ErrInvalidTLSConfig, o.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if o.CertFile != "" || o.KeyFile != "" {
+		if o.CertFile == "" {
+			return nil, fmt.Errorf("%w: client key %s has no certificate", ErrInvalidTLSConfig, o.KeyFile)
+		}
+		keyFile := o.KeyFile
+		if keyFile == "" {
+			keyFile = o.CertFile
+		}
+		cert, err := tls.LoadX509KeyPair(o.CertFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: client certificate: %v", ErrInvalidTLSConfig, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// newTransport returns the transport for a server's proxy and TLS settings,
+// or the shared one when neither is set. Settings that cannot be used fail
+// every request rather than the client construction, so the error shows up
+// where the connection is tested.
+func newTransport(creds Credentials) http.RoundTripper {
+	if creds.Proxy == "" && creds.TLS.isZero() {
+		return sharedTransport
+	}
+
+	transport := pooledTransport()
+	setProxy(transport, creds.Proxy)
+	if !creds.TLS.isZero() {
+		config, err := creds.TLS.config()
+		if err != nil {
+			return failingTransport{err: err}
+		}
+		transport.TLSClientConfig = config
+	}
+	return transport
+}
+
+// failingTransport fails every request with err.
+type failingTransport struct {
+	err error
+}
+
+func (t failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
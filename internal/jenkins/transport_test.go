@@ -0,0 +1,215 @@
+package jenkins
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeServerCA writes the test server's certificate as a PEM CA bundle.
+func writeServerCA(t *testing.T, server *httptest.Server) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeClientCert writes a self-signed client certificate and its key, and
+// returns their paths and the certificate.
+func writeClientCert(t *testing.T) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "jdash"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, cert
+}
+
+func TestTLSOptions(t *testing.T) {
+	server := httptest.NewTLSServer(jenkinsStub())
+	defer server.Close()
+	ctx := context.Background()
+
+	err := NewClient(Credentials{URL: server.URL}).TestConnection(ctx)
+	if err == nil || !strings.Contains(err.Error(), "caCertFile") {
+		t.Errorf("TestConnection() with an untrusted certificate error = %v, want a caCertFile hint", err)
+	}
+
+	trusted := NewClient(Credentials{URL: server.URL, TLS: TLSOptions{CAFile: writeServerCA(t, server)}})
+	if err := trusted.TestConnection(ctx); err != nil {
+		t.Errorf("TestConnection() with the CA bundle error = %v", err)
+	}
+
+	insecure := NewClient(Credentials{URL: server.URL, TLS: TLSOptions{InsecureSkipVerify: true}})
+	if err := insecure.TestConnection(ctx); err != nil {
+		t.Errorf("TestConnection() skipping verification error = %v", err)
+	}
+}
+
+func TestClientCertificate(t *testing.T) {
+	certFile, keyFile, cert := writeClientCert(t)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	server := httptest.NewUnstartedServer(jenkinsStub())
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	caFile := writeServerCA(t, server)
+	ctx := context.Background()
+
+	without := NewClient(Credentials{URL: server.URL, TLS: TLSOptions{CAFile: caFile}})
+	if err := without.TestConnection(ctx); err == nil {
+		t.Error("TestConnection() without a client certificate succeeded")
+	}
+
+	with := NewClient(Credentials{URL: server.URL, TLS: TLSOptions{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}})
+	if err := with.TestConnection(ctx); err != nil {
+		t.Errorf("TestConnection() with a client certificate error = %v", err)
+	}
+}
+
+func TestInvalidTLSOptions(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range []TLSOptions{
+		{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+		{CAFile: empty},
+		{KeyFile: empty},
+		{CertFile: empty, KeyFile: empty},
+	} {
+		client := NewClient(Credentials{URL: "https://jenkins.example.com", TLS: opts})
+		err := client.TestConnection(context.Background())
+		if !errors.Is(err, ErrInvalidTLSConfig) {
+			t.Errorf("TestConnection() with %+v error = %v, want ErrInvalidTLSConfig", opts, err)
+		}
+	}
+}
+
+func TestResponsesAreCompressed(t *testing.T) {
+	var gzipped atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			fmt.Fprint(w, `{"mode":"NORMAL"}`)
+			return
+		}
+		gzipped.Add(1)
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		fmt.Fprint(gz, `{"mode":"NORMAL"}`)
+		gz.Close()
+	}))
+	defer server.Close()
+
+	if err := NewClient(Credentials{URL: server.URL}).TestConnection(context.Background()); err != nil {
+		t.Fatalf("TestConnection() with a gzipped response error = %v", err)
+	}
+	if gzipped.Load() != 1 {
+		t.Error("the request did not ask for gzip")
+	}
+}
+
+func TestConcurrentPollsReuseConnections(t *testing.T) {
+	// As many concurrent requests as the panels poll with, each held until
+	// all of them arrived, so every round needs that many connections.
+	const concurrent = 6
+	var round sync.WaitGroup
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		round.Done()
+		round.Wait()
+		fmt.Fprint(w, `{"mode":"NORMAL"}`)
+	}))
+	var connections atomic.Int32
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(Credentials{URL: server.URL})
+	for i := 0; i < 3; i++ {
+		round.Add(concurrent)
+		var requests sync.WaitGroup
+		for j := 0; j < concurrent; j++ {
+			requests.Add(1)
+			go func() {
+				defer requests.Done()
+				if err := client.TestConnection(context.Background()); err != nil {
+					t.Errorf("TestConnection() error = %v", err)
+				}
+			}()
+		}
+		requests.Wait()
+	}
+	if connections.Load() != concurrent {
+		t.Errorf("3 rounds of %d concurrent requests opened %d connections, want %d", concurrent, connections.Load(), concurrent)
+	}
+}
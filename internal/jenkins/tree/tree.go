@@ -0,0 +1,165 @@
+// Package tree builds the tree parameter of the Jenkins REST API, which
+// selects the fields a response includes:
+//
+//	tree.New("jobs").Fields("name", "url").Nested(tree.New("lastBuild").Fields("number"))
+//
+// renders as "jobs[name,url,lastBuild[number]]". Selections can be reused
+// under other names with As, e.g. the same build fields for lastBuild and
+// builds.
+package tree
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Node is a selected field and the fields selected below it. Nodes are
+// values; every method returns a new one, so shared selections can be
+// extended without changing them.
+type Node struct {
+	name     string
+	children []Node
+	// limit renders {limit}; from and to render {from,to}.
+	limit    int
+	from, to int
+	ranged   bool
+}
+
+// New starts a selection of the field name.
+func New(name string) Node {
+	return Node{name: name}
+}
+
+// Fields starts an unnamed selection of top-level fields, e.g. for a job's
+// own fields, or for a group of fields reused with As.
+func Fields(names ...string) Node {
+	return Node{}.Fields(names...)
+}
+
+// Fields adds plain fields below n.
+func (n Node) Fields(names ...string) Node {
+	children := make([]Node, 0, len(names))
+	for _, name := range names {
+		children = append(children, New(name))
+	}
+	return n.Nested(children...)
+}
+
+// Nested adds fields with their own selections below n.
+func (n Node) Nested(children ...Node) Node {
+	n.children = append(n.children[:len(n.children):len(n.children)], children...)
+	return n
+}
+
+// As returns n's selection under another field name.
+func (n Node) As(name string) Node {
+	n.name = name
+	return n
+}
+
+// Limit returns the first count elements of a list field, e.g. builds{10}.
+func (n Node) Limit(count int) Node {
+	n.limit, n.ranged = count, false
+	return n
+}
+
+// Range returns the elements from index from up to to of a list field,
+// e.g. allBuilds{25,50}.
+func (n Node) Range(from, to int) Node {
+	n.from, n.to, n.ranged = from, to, true
+	n.limit = 0
+	return n
+}
+
+// String renders the tree parameter value.
+func (n Node) String() string {
+	var b strings.Builder
+	n.render(&b)
+	return b.String()
+}
+
+func (n Node) render(b *strings.Builder) {
+	b.WriteString(n.name)
+	if len(n.children) > 0 {
+		if n.name != "" {
+			b.WriteByte('[')
+		}
+		for i, child := range n.children {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			child.render(b)
+		}
+		if n.name != "" {
+			b.WriteByte(']')
+		}
+	}
+	switch {
+	case n.ranged:
+		fmt.Fprintf(b, "{%d,%d}", n.from, n.to)
+	case n.limit > 0:
+		fmt.Fprintf(b, "{%d}", n.limit)
+	}
+}
+
+// fieldName matches what Jenkins accepts as a field: a Java identifier, or
+// "*" for every field of a map like monitorData.
+var fieldName = regexp.MustCompile(`^(\*|[A-Za-z_$][A-Za-z0-9_$]*)$`)
+
+// Validate reports selections Jenkins would reject or silently ignore:
+// malformed or repeated field names, empty selections and bad ranges.
+func (n Node) Validate() error {
+	return n.validate("", false)
+}
+
+func (n Node) validate(parent string, nested bool) error {
+	path := n.name
+	if parent != "" {
+		path = parent + "." + n.name
+	}
+	if n.name == "" {
+		if nested {
+			return fmt.Errorf("unnamed selection nested in %s", describe(parent))
+		}
+		if len(n.children) == 0 {
+			return fmt.Errorf("empty tree")
+		}
+		if n.ranged || n.limit != 0 {
+			return fmt.Errorf("range on the top-level selection")
+		}
+	} else if !fieldName.MatchString(n.name) {
+		return fmt.Errorf("invalid field name %q", path)
+	}
+	if n.limit < 0 || (n.ranged && (n.from < 0 || n.to <= n.from)) {
+		return fmt.Errorf("invalid range on %s", path)
+	}
+
+	seen := make(map[string]bool, len(n.children))
+	for _, child := range n.children {
+		if seen[child.name] {
+			return fmt.Errorf("field %q selected twice in %s", child.name, describe(path))
+		}
+		seen[child.name] = true
+		if err := child.validate(path, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func describe(path string) string {
+	if path == "" {
+		return "the top level"
+	}
+	return path
+}
+
+// Must returns n, panicking if it is invalid. It is meant for queries
+// built once into package variables, so a mistake fails every test run.
+func Must(n Node) Node {
+	if err := n.Validate(); err != nil {
+		panic("tree: " + err.Error())
+	}
+	return n
+}
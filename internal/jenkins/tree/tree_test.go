@@ -0,0 +1,56 @@
+package tree
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	build := Fields("number", "result")
+	query := New("jobs").Fields("name", "url").Nested(
+		build.As("lastBuild"),
+		build.As("builds").Limit(10),
+		New("jobs").Fields("name"),
+	)
+	if got, want := query.String(), "jobs[name,url,lastBuild[number,result],builds[number,result]{10},jobs[name]]"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// Top-level fields render without brackets; ranges select a page.
+	if got, want := Fields("id").Nested(build.As("allBuilds").Range(25, 50)).String(), "id,allBuilds[number,result]{25,50}"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestNodesAreValues(t *testing.T) {
+	base := New("jobs").Fields("name")
+	extended := base.Fields("url")
+	other := base.Fields("color")
+	if base.String() != "jobs[name]" || extended.String() != "jobs[name,url]" || other.String() != "jobs[name,color]" {
+		t.Errorf("extending a shared node changed it: %s, %s, %s", base, extended, other)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		query Node
+		want  string
+	}{
+		{"valid", New("computer").Nested(New("monitorData").Fields("*")), ""},
+		{"empty", Fields(), "empty tree"},
+		{"bad name", New("jobs").Fields("name]"), `invalid field name "jobs.name]"`},
+		{"repeated", New("jobs").Fields("name", "url", "name"), `field "name" selected twice in jobs`},
+		{"unnamed nested", New("jobs").Nested(Fields("name")), "unnamed selection nested in jobs"},
+		{"bad range", New("allBuilds").Fields("number").Range(10, 10), "invalid range on allBuilds"},
+		{"top-level range", Fields("number").Range(0, 5), "range on the top-level selection"},
+	} {
+		err := tc.query.Validate()
+		switch {
+		case tc.want == "" && err != nil:
+			t.Errorf("%s: Validate() error = %v", tc.name, err)
+		case tc.want != "" && (err == nil || !strings.Contains(err.Error(), tc.want)):
+			t.Errorf("%s: Validate() error = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}
@@ -2,6 +2,10 @@
 
 import (
 	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 )
@@ -22,6 +26,20 @@ type Job struct {
 
 	// Class indicates the type (e.g., "hudson.model.FreeStyleProject", "com.cloudbees.hudson.plugins.folder.Folder")
 	Class string `json:"_class"`
+
+	// HealthReport is the job's weather: one report per health metric, such
+	// as build stability or test results.
+	HealthReport []HealthReport `json:"healthReport"`
+
+	// Disabled is reported by jobs and by folders that can be disabled, such
+	// as multibranch projects; folders have no color to tell.
+	Disabled bool `json:"disabled"`
+}
+
+// HealthReport is one health metric of a job, scored from 0 (worst) to 100.
+type HealthReport struct {
+	Score       int    `json:"score"`
+	Description string `json:"description"`
 }
 
 // Build represents a Jenkins build
@@ -33,6 +51,15 @@ type Build struct {
 	Building  bool          `json:"building"`
 	URL       string        `json:"url"`
 	Actions   []BuildAction `json:"actions"`
+
+	// DisplayName is "#N" unless the build was renamed.
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+
+	// ChangeSets lists the SCM changes of Pipeline builds, one set per checkout.
+	ChangeSets []ChangeSet `json:"changeSets"`
+	// ChangeSet is the single change set freestyle builds report instead.
+	ChangeSet *ChangeSet `json:"changeSet"`
 }
 
 // IsFolder returns true if this job is a folder containing other jobs
@@ -42,6 +69,34 @@ func (j *Job) IsFolder() bool {
 		j.Class == "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"
 }
 
+// IsDisabled returns true for disabled jobs and folders.
+func (j *Job) IsDisabled() bool {
+	return j.Disabled || j.Color == "disabled"
+}
+
+// IsMultibranch returns true for multibranch projects, whose children are branches
+func (j *Job) IsMultibranch() bool {
+	return j.Class == "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"
+}
+
+// IsPipeline returns true for Pipeline jobs, which expose stage data under wfapi
+func (j *Job) IsPipeline() bool {
+	return j.Class == "org.jenkinsci.plugins.workflow.job.WorkflowJob"
+}
+
+// HealthScore returns the job's weather score, the worst of its health
+// reports as in the web UI, and false when Jenkins reported none.
+func (j *Job) HealthScore() (int, bool) {
+	if len(j.HealthReport) == 0 {
+		return 0, false
+	}
+	score := j.HealthReport[0].Score
+	for _, report := range j.HealthReport[1:] {
+		score = min(score, report.Score)
+	}
+	return score, true
+}
+
 // GetStatus returns a normalized status string for display
 func (j *Job) GetStatus() string {
 	if j.IsFolder() {
@@ -56,28 +111,76 @@ func (j *Job) GetStatus() string {
 		return StatusBuilding
 	}
 
+	if status := StatusFromColor(j.Color); status != "" {
+		return status
+	}
+	if j.LastBuild.Result != "" {
+		return j.LastBuild.Result
+	}
+	return StatusUnknown
+}
+
+// StatusFromColor maps a Jenkins ball color to a normalized status, returning "" for unknown colors.
+func StatusFromColor(color string) string {
 	// Jenkins uses color codes: blue/blue_anime, red/red_anime, yellow/yellow_anime, grey, disabled, aborted, notbuilt
-	switch {
-	case j.Color == "blue" || j.Color == "blue_anime":
+	switch color {
+	case "blue", "blue_anime":
 		return StatusSuccess
-	case j.Color == "red" || j.Color == "red_anime":
+	case "red", "red_anime":
 		return StatusFailed
-	case j.Color == "yellow" || j.Color == "yellow_anime":
+	case "yellow", "yellow_anime":
 		return StatusUnstable
-	case j.Color == "grey":
+	case "grey":
 		return StatusPending
-	case j.Color == "disabled":
+	case "disabled":
 		return StatusDisabled
-	case j.Color == "aborted":
+	case "aborted":
 		return StatusAborted
-	case j.Color == "notbuilt":
+	case "notbuilt":
 		return StatusNotBuilt
 	default:
-		if j.LastBuild.Result != "" {
-			return j.LastBuild.Result
+		return ""
+	}
+}
+
+// CustomDisplayName returns the name the build was given, or "" when it still
+// has the default "#N".
+func (b *Build) CustomDisplayName() string {
+	if b == nil || b.DisplayName == fmt.Sprintf("#%d", b.Number) {
+		return ""
+	}
+	return b.DisplayName
+}
+
+// Promotions returns the promotions the build went through with the
+// promoted-builds plugin. Teams without the plugin can mark deployments with
+// a build parameter instead: when markerParameter is set, its value (e.g. the
+// target environment) counts as a promotion unless it is empty or "false".
+func (b *Build) Promotions(markerParameter string) []string {
+	if b == nil {
+		return nil
+	}
+	var promotions []string
+	for _, action := range b.Actions {
+		for _, promotion := range action.Promotions {
+			if promotion.Name != "" {
+				promotions = append(promotions, promotion.Name)
+			}
+		}
+		if markerParameter == "" {
+			continue
+		}
+		for _, param := range action.Parameters {
+			if param.Name != markerParameter || param.Value == nil {
+				continue
+			}
+			value := fmt.Sprint(param.Value)
+			if value != "" && !strings.EqualFold(value, "false") {
+				promotions = append(promotions, value)
+			}
 		}
-		return StatusUnknown
 	}
+	return promotions
 }
 
 // GetDuration returns the build duration as a time.Duration
@@ -125,6 +228,42 @@ func (b *Build) GetTriggeredBy() string {
 	return ""
 }
 
+// GetTriggeredByUserID returns the ID of the user who started the build, if any.
+func (b *Build) GetTriggeredByUserID() string {
+	if b == nil {
+		return ""
+	}
+	for _, action := range b.Actions {
+		for _, cause := range action.Causes {
+			if cause.UserID != "" {
+				return cause.UserID
+			}
+		}
+	}
+	return ""
+}
+
+// IsTriggeredBy reports whether the user with the given ID started the build.
+func (b *Build) IsTriggeredBy(userID string) bool {
+	return b != nil && causedByUser(b.Actions, userID)
+}
+
+// causedByUser reports whether any cause in actions names the user. Jenkins
+// treats user IDs case-insensitively by default.
+func causedByUser(actions []BuildAction, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	for _, action := range actions {
+		for _, cause := range action.Causes {
+			if strings.EqualFold(cause.UserID, userID) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // GetBranch tries to determine the source branch from build actions.
 func (b *Build) GetBranch() string {
 	if b == nil {
@@ -151,12 +290,79 @@ func (b *Build) GetBranch() string {
 	return ""
 }
 
+// GetTestCounts returns the totals of the build's test report, with ok false
+// when the build published none.
+func (b *Build) GetTestCounts() (total, failed, skipped int, ok bool) {
+	if b == nil {
+		return 0, 0, 0, false
+	}
+	for _, action := range b.Actions {
+		if strings.HasSuffix(action.Class, "TestResultAction") {
+			return action.TotalCount, action.FailCount, action.SkipCount, true
+		}
+	}
+	return 0, 0, 0, false
+}
+
+// GetChanges returns the commits that went into the build, across all change sets.
+func (b *Build) GetChanges() []ChangeSetItem {
+	if b == nil {
+		return nil
+	}
+	var items []ChangeSetItem
+	if b.ChangeSet != nil {
+		items = append(items, b.ChangeSet.Items...)
+	}
+	for _, set := range b.ChangeSets {
+		items = append(items, set.Items...)
+	}
+	return items
+}
+
+// ChangeSet is the list of SCM changes a build picked up from one repository.
+type ChangeSet struct {
+	Kind  string          `json:"kind"` // e.g. "git"
+	Items []ChangeSetItem `json:"items"`
+}
+
+// ChangeSetItem is a single commit in a change set.
+type ChangeSetItem struct {
+	CommitID      string       `json:"commitId"`
+	Msg           string       `json:"msg"`
+	Author        ChangeAuthor `json:"author"`
+	Timestamp     int64        `json:"timestamp"` // Unix timestamp in milliseconds
+	AffectedPaths []string     `json:"affectedPaths"`
+}
+
+// ChangeAuthor identifies who made a change.
+type ChangeAuthor struct {
+	FullName string `json:"fullName"`
+}
+
+// ShortCommitID returns the commit ID abbreviated to 7 characters
+func (c *ChangeSetItem) ShortCommitID() string {
+	if len(c.CommitID) > 7 {
+		return c.CommitID[:7]
+	}
+	return c.CommitID
+}
+
 // BuildAction represents additional metadata attached to a build.
 type BuildAction struct {
 	Class             string           `json:"_class"`
 	Causes            []BuildCause     `json:"causes"`
 	Parameters        []BuildParameter `json:"parameters"`
 	LastBuiltRevision *BuildRevision   `json:"lastBuiltRevision"`
+
+	// Test counts, set on the test result action (e.g. hudson.tasks.junit.TestResultAction)
+	TotalCount int `json:"totalCount"`
+	FailCount  int `json:"failCount"`
+	SkipCount  int `json:"skipCount"`
+
+	// Promotions are set on the promoted-builds plugin's PromotedBuildAction.
+	Promotions []struct {
+		Name string `json:"name"`
+	} `json:"promotions"`
 }
 
 // BuildCause describes what triggered a build.
@@ -183,11 +389,44 @@ type BuildBranch struct {
 	Name string `json:"name"`
 }
 
+// User represents a Jenkins user account.
+type User struct {
+	ID       string `json:"id"`
+	FullName string `json:"fullName"`
+}
+
+// DisplayLabel renders the user as "Full Name (id)", falling back to whichever part is known.
+func (u *User) DisplayLabel() string {
+	if u == nil {
+		return ""
+	}
+	fullName := strings.TrimSpace(u.FullName)
+	switch {
+	case fullName == "" || fullName == u.ID:
+		return u.ID
+	case u.ID == "":
+		return fullName
+	default:
+		return fmt.Sprintf("%s (%s)", fullName, u.ID)
+	}
+}
+
 // JobDetails provides expanded information about a Jenkins job.
 type JobDetails struct {
 	Job
 	Builds               []Build               `json:"builds"`
 	ParameterDefinitions []ParameterDefinition `json:"-"`
+	// Views lists the views defined inside a folder; empty for other jobs.
+	Views []View `json:"views"`
+	// ConcurrentBuild is set on jobs allowed to run several builds at once,
+	// so lastBuild may not be the only one running.
+	ConcurrentBuild bool `json:"concurrentBuild"`
+}
+
+// View is a Jenkins list view, such as "All" or a team's view inside a folder.
+type View struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
 }
 
 // ParameterDefinition describes a parameter configured on a Jenkins job.
@@ -202,6 +441,9 @@ type ParameterDefinition struct {
 	BooleanDefault       bool                     `json:"defaultValue"`
 	ProjectName          string                   `json:"projectName"`
 	ReferencedParameters []ReferencedParameterRef `json:"referencedParameters"`
+	// CredentialType is the class of credential a credentials parameter
+	// takes; see CredentialsType.
+	CredentialType string `json:"credentialType"`
 }
 
 // ParameterDefaultValue represents the default value object for parameter definitions.
@@ -242,6 +484,48 @@ func (p ParameterDefinition) DefaultValueString() string {
 	}
 }
 
+// IsCredentials reports whether the parameter takes the ID of a stored
+// credential (credentials plugin).
+func (p ParameterDefinition) IsCredentials() bool {
+	switch strings.ToLower(p.GetType()) {
+	case "credentialsparameterdefinition", "com.cloudbees.plugins.credentials.credentialsparameterdefinition":
+		return true
+	}
+	return false
+}
+
+// DefaultCredentialType is the credentials plugin's default type of
+// credentials parameter, which covers the common credentials.
+const DefaultCredentialType = "com.cloudbees.plugins.credentials.common.StandardCredentials"
+
+// CredentialsType returns the class of credential a credentials parameter
+// takes, the plugin's default when Jenkins did not say.
+func (p ParameterDefinition) CredentialsType() string {
+	if p.CredentialType == "" {
+		return DefaultCredentialType
+	}
+	return p.CredentialType
+}
+
+// JobRef is a lightweight reference to another job, as used in upstream/downstream lists.
+type JobRef struct {
+	Name     string `json:"name"`
+	FullName string `json:"fullName"`
+	URL      string `json:"url"`
+	Color    string `json:"color"`
+}
+
+// JobRelations describes a job together with its upstream and downstream projects.
+type JobRelations struct {
+	Name               string   `json:"name"`
+	FullName           string   `json:"fullName"`
+	URL                string   `json:"url"`
+	Color              string   `json:"color"`
+	Class              string   `json:"_class"`
+	UpstreamProjects   []JobRef `json:"upstreamProjects"`
+	DownstreamProjects []JobRef `json:"downstreamProjects"`
+}
+
 // JobsResponse represents the response from Jenkins API when fetching all jobs
 type JobsResponse struct {
 	Jobs []Job `json:"jobs"`
@@ -253,6 +537,7 @@ type QueueItem struct {
 	Blocked      bool   `json:"blocked"`
 	Buildable    bool   `json:"buildable"`
 	Stuck        bool   `json:"stuck"`
+	Cancelled    bool   `json:"cancelled"`
 	Why          string `json:"why"`          // Reason for being in queue
 	InQueueSince int64  `json:"inQueueSince"` // Unix timestamp in milliseconds
 
@@ -268,6 +553,10 @@ type QueueItem struct {
 		Number int    `json:"number"`
 		URL    string `json:"url"`
 	} `json:"executable"`
+
+	// Actions carry the parameters and causes the item was enqueued with.
+	// The queue listing only includes the causes; GetQueueItem returns both.
+	Actions []BuildAction `json:"actions"`
 }
 
 // QueueResponse represents the response from Jenkins queue API
@@ -285,6 +574,56 @@ func (q *QueueItem) GetJobName() string {
 	return q.Task.Name
 }
 
+// GetJobFullName returns the slash-separated full name of the queued job,
+// derived from the task URL's /job/ segments. It falls back to the task name
+// when the URL has none.
+func (q *QueueItem) GetJobFullName() string {
+	u, err := url.Parse(q.Task.URL)
+	if err != nil {
+		return q.Task.Name
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	var names []string
+	for i := 0; i+1 < len(segments); i++ {
+		if segments[i] != "job" {
+			continue
+		}
+		name, err := url.PathUnescape(segments[i+1])
+		if err != nil {
+			name = segments[i+1]
+		}
+		names = append(names, name)
+		i++
+	}
+	if len(names) == 0 {
+		return q.Task.Name
+	}
+	return strings.Join(names, "/")
+}
+
+// GetParameters returns the parameters the item was enqueued with.
+func (q *QueueItem) GetParameters() []BuildParameter {
+	var params []BuildParameter
+	for _, action := range q.Actions {
+		params = append(params, action.Parameters...)
+	}
+	return params
+}
+
+// GetCauses returns what enqueued the item, e.g. a user or an upstream build.
+func (q *QueueItem) GetCauses() []BuildCause {
+	var causes []BuildCause
+	for _, action := range q.Actions {
+		causes = append(causes, action.Causes...)
+	}
+	return causes
+}
+
+// IsTriggeredBy reports whether the user with the given ID enqueued the item.
+func (q *QueueItem) IsTriggeredBy(userID string) bool {
+	return causedByUser(q.Actions, userID)
+}
+
 // GetBuildNumber returns the build number if building, otherwise 0
 func (q *QueueItem) GetBuildNumber() int {
 	if q.Executable != nil {
@@ -299,6 +638,123 @@ func (q *QueueItem) GetInQueueDuration() time.Duration {
 	return time.Duration(now-q.InQueueSince) * time.Millisecond
 }
 
+// JobRename records a job or folder that moved to a new full name between two
+// fetches.
+type JobRename struct {
+	From string
+	To   string
+}
+
+// DetectRenames reports the jobs that disappeared from previous and reappeared
+// in current under another full name. Jenkins keeps a job's builds when it is
+// renamed or moved, so jobs are matched by their last build's number and start
+// time; jobs that were never built, and builds shared by more than one
+// candidate, are not matched. Folders are reported when a job inside them
+// moved along with them.
+func DetectRenames(previous, current []Job) []JobRename {
+	before, after := flattenJobs(previous), flattenJobs(current)
+
+	gone := make(map[string][]string)
+	for name, job := range before {
+		if _, ok := after[name]; !ok && !job.IsFolder() {
+			if key := lastBuildKey(job); key != "" {
+				gone[key] = append(gone[key], name)
+			}
+		}
+	}
+	added := make(map[string][]string)
+	for name, job := range after {
+		if _, ok := before[name]; !ok && !job.IsFolder() {
+			if key := lastBuildKey(job); key != "" {
+				added[key] = append(added[key], name)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var renames []JobRename
+	for key, from := range gone {
+		to := added[key]
+		if len(from) != 1 || len(to) != 1 {
+			continue
+		}
+		renames = append(renames, JobRename{From: from[0], To: to[0]})
+		seen[from[0]] = true
+
+		// Walk up while the trailing segments agree: "A/x/job" -> "B/x/job"
+		// means A became B and A/x became B/x.
+		fromParts, toParts := strings.Split(from[0], "/"), strings.Split(to[0], "/")
+		for len(fromParts) > 1 && len(toParts) > 1 && fromParts[len(fromParts)-1] == toParts[len(toParts)-1] {
+			fromParts, toParts = fromParts[:len(fromParts)-1], toParts[:len(toParts)-1]
+			fromFolder, toFolder := strings.Join(fromParts, "/"), strings.Join(toParts, "/")
+			if seen[fromFolder] {
+				continue
+			}
+			if _, ok := after[fromFolder]; ok {
+				continue
+			}
+			if _, ok := before[toFolder]; ok {
+				continue
+			}
+			if _, ok := after[toFolder]; !ok {
+				continue
+			}
+			renames = append(renames, JobRename{From: fromFolder, To: toFolder})
+			seen[fromFolder] = true
+		}
+	}
+
+	sort.Slice(renames, func(i, j int) bool { return renames[i].From < renames[j].From })
+	return renames
+}
+
+// flattenJobs indexes a job tree by full name.
+func flattenJobs(jobs []Job) map[string]Job {
+	index := make(map[string]Job)
+	var walk func([]Job)
+	walk = func(jobs []Job) {
+		for _, job := range jobs {
+			index[job.FullName] = job
+			walk(job.Jobs)
+		}
+	}
+	walk(jobs)
+	return index
+}
+
+// lastBuildKey identifies a job's build history, or is empty when it has none.
+func lastBuildKey(job Job) string {
+	if job.LastBuild == nil || job.LastBuild.Number <= 0 || job.LastBuild.Timestamp <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d@%d", job.LastBuild.Number, job.LastBuild.Timestamp)
+}
+
+// QueuePosition estimates where the item with the given ID stands in items,
+// 1 being next in line, and how many items are queued. Jenkins exposes no
+// order, so items are ranked by how long they have waited. position is 0 when
+// the item is not queued.
+func QueuePosition(items []QueueItem, id int) (position, total int) {
+	var mine *QueueItem
+	for i := range items {
+		if items[i].ID == id {
+			mine = &items[i]
+			break
+		}
+	}
+	if mine == nil {
+		return 0, len(items)
+	}
+
+	position = 1
+	for _, item := range items {
+		if item.InQueueSince < mine.InQueueSince || (item.InQueueSince == mine.InQueueSince && item.ID < mine.ID) {
+			position++
+		}
+	}
+	return position, len(items)
+}
+
 // Executor represents a Jenkins executor (build slot)
 type Executor struct {
 	Idle              bool `json:"idle"`
@@ -310,10 +766,24 @@ type Executor struct {
 	} `json:"currentExecutable"`
 }
 
-// Computer represents a Jenkins node (master or agent)
+// Computer represents a Jenkins node (master or agent). Only the fields the
+// request's tree selects are filled.
 type Computer struct {
 	DisplayName string     `json:"displayName"`
 	Executors   []Executor `json:"executors"`
+
+	Description string `json:"description"`
+	Offline     bool   `json:"offline"`
+	// TemporarilyOffline is set when someone took the node offline by hand,
+	// as opposed to it being disconnected.
+	TemporarilyOffline bool   `json:"temporarilyOffline"`
+	OfflineCauseReason string `json:"offlineCauseReason"`
+	Idle               bool   `json:"idle"`
+	NumExecutors       int    `json:"numExecutors"`
+	AssignedLabels     []struct {
+		Name string `json:"name"`
+	} `json:"assignedLabels"`
+	MonitorData NodeMonitorData `json:"monitorData"`
 }
 
 // ComputerResponse represents the response from Jenkins computer API
@@ -321,6 +791,61 @@ type ComputerResponse struct {
 	Computer []Computer `json:"computer"`
 }
 
+// Labels returns the node's label names, without the implicit label that
+// repeats the node name.
+func (c Computer) Labels() []string {
+	var labels []string
+	for _, label := range c.AssignedLabels {
+		if label.Name != "" && label.Name != c.DisplayName {
+			labels = append(labels, label.Name)
+		}
+	}
+	return labels
+}
+
+// BusyExecutors returns how many of the node's executors are running a build.
+func (c Computer) BusyExecutors() int {
+	busy := 0
+	for _, executor := range c.Executors {
+		if !executor.Idle {
+			busy++
+		}
+	}
+	return busy
+}
+
+// NodeMonitorData holds the built-in node monitors Jenkins reports. Monitors
+// that have not run yet, or can't run on an offline node, are nil.
+type NodeMonitorData struct {
+	Architecture string         `json:"hudson.node_monitors.ArchitectureMonitor"`
+	DiskSpace    *SpaceMonitor  `json:"hudson.node_monitors.DiskSpaceMonitor"`
+	TempSpace    *SpaceMonitor  `json:"hudson.node_monitors.TemporarySpaceMonitor"`
+	Memory       *MemoryMonitor `json:"hudson.node_monitors.SwapSpaceMonitor"`
+	ResponseTime *struct {
+		// Average is the round-trip time to the agent in milliseconds.
+		Average int64 `json:"average"`
+	} `json:"hudson.node_monitors.ResponseTimeMonitor"`
+	Clock *struct {
+		// Diff is the agent's clock offset from the controller in milliseconds.
+		Diff int64 `json:"diff"`
+	} `json:"hudson.node_monitors.ClockMonitor"`
+}
+
+// SpaceMonitor reports free space on one of a node's file systems.
+type SpaceMonitor struct {
+	Path string `json:"path"`
+	// Size is the free space in bytes.
+	Size int64 `json:"size"`
+}
+
+// MemoryMonitor reports a node's physical memory and swap in bytes.
+type MemoryMonitor struct {
+	AvailablePhysicalMemory int64 `json:"availablePhysicalMemory"`
+	TotalPhysicalMemory     int64 `json:"totalPhysicalMemory"`
+	AvailableSwapSpace      int64 `json:"availableSwapSpace"`
+	TotalSwapSpace          int64 `json:"totalSwapSpace"`
+}
+
 // RunningBuild represents a build currently executing on an executor
 type RunningBuild struct {
 	JobName     string
@@ -335,3 +860,443 @@ func (r *RunningBuild) GetElapsedTime() time.Duration {
 	now := time.Now().UnixMilli()
 	return time.Duration(now-r.StartTime) * time.Millisecond
 }
+
+// JobURL returns the URL of the build's job, e.g. ".../job/api/" for
+// ".../job/api/42/", or "" if the build has no URL.
+func (r *RunningBuild) JobURL() string {
+	trimmed := strings.TrimSuffix(r.URL, "/")
+	i := strings.LastIndex(trimmed, "/")
+	if i < 0 {
+		return ""
+	}
+	return trimmed[:i+1]
+}
+
+// RunningByJob groups running builds by the URL of their job, without the
+// trailing slash, newest first. Each build is listed once, although a
+// Pipeline build shows on its flyweight executor and on the executors of its
+// node blocks.
+func RunningByJob(running []RunningBuild) map[string][]RunningBuild {
+	byJob := make(map[string][]RunningBuild)
+	for _, build := range running {
+		jobURL := build.JobURL()
+		if jobURL == "" {
+			continue
+		}
+		key := strings.TrimSuffix(jobURL, "/")
+		if !slices.ContainsFunc(byJob[key], func(b RunningBuild) bool { return b.BuildNumber == build.BuildNumber }) {
+			byJob[key] = append(byJob[key], build)
+		}
+	}
+	for _, builds := range byJob {
+		sort.Slice(builds, func(i, j int) bool { return builds[i].BuildNumber > builds[j].BuildNumber })
+	}
+	return byJob
+}
+
+// APIToken is a freshly generated Jenkins API token.
+type APIToken struct {
+	Name  string `json:"tokenName"`
+	UUID  string `json:"tokenUuid"`
+	Value string `json:"tokenValue"`
+}
+
+// PipelineRun is a Pipeline build as described by the Pipeline Stage View
+// plugin's wfapi endpoints.
+type PipelineRun struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Status          string          `json:"status"` // SUCCESS, FAILED, IN_PROGRESS, ABORTED, UNSTABLE, NOT_EXECUTED, PAUSED_PENDING_INPUT
+	StartTimeMillis int64           `json:"startTimeMillis"`
+	DurationMillis  int64           `json:"durationMillis"`
+	Stages          []PipelineStage `json:"stages"`
+}
+
+// PipelineStage is one stage of a Pipeline run.
+type PipelineStage struct {
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	Status              string `json:"status"`
+	StartTimeMillis     int64  `json:"startTimeMillis"`
+	DurationMillis      int64  `json:"durationMillis"`
+	PauseDurationMillis int64  `json:"pauseDurationMillis"`
+}
+
+// GetStatus returns the run status normalized to the Status constants
+func (r *PipelineRun) GetStatus() string {
+	return statusFromWorkflow(r.Status)
+}
+
+// GetStatus returns the stage status normalized to the Status constants
+func (s *PipelineStage) GetStatus() string {
+	return statusFromWorkflow(s.Status)
+}
+
+// GetDuration returns how long the stage ran, excluding time paused for input
+func (s *PipelineStage) GetDuration() time.Duration {
+	return time.Duration(s.DurationMillis-s.PauseDurationMillis) * time.Millisecond
+}
+
+// statusFromWorkflow maps a wfapi status to the normalized Status constants.
+func statusFromWorkflow(status string) string {
+	switch status {
+	case "SUCCESS":
+		return StatusSuccess
+	case "FAILED":
+		return StatusFailed
+	case "UNSTABLE":
+		return StatusUnstable
+	case "ABORTED":
+		return StatusAborted
+	case "IN_PROGRESS":
+		return StatusBuilding
+	case "NOT_EXECUTED":
+		return StatusNotBuilt
+	case "QUEUED", "PAUSED_PENDING_INPUT":
+		return StatusPending
+	default:
+		return StatusUnknown
+	}
+}
+
+// PipelineGraph is the stage graph of one Pipeline build.
+type PipelineGraph struct {
+	// Source is "blueocean" or "wfapi", depending on which API answered.
+	Source string
+	Nodes  []PipelineNode
+}
+
+// PipelineNode is a stage or parallel branch of a Pipeline run, as described
+// by the Blue Ocean REST API.
+type PipelineNode struct {
+	ID               string         `json:"id"`
+	DisplayName      string         `json:"displayName"`
+	Result           string         `json:"result"` // SUCCESS, FAILURE, UNSTABLE, ABORTED, NOT_BUILT, UNKNOWN
+	State            string         `json:"state"`  // QUEUED, RUNNING, PAUSED, SKIPPED, NOT_BUILT, FINISHED
+	Type             string         `json:"type"`   // STAGE or PARALLEL
+	StartTime        string         `json:"startTime"`
+	DurationInMillis int64          `json:"durationInMillis"`
+	FirstParent      string         `json:"firstParent"`
+	Edges            []PipelineEdge `json:"edges"`
+}
+
+// PipelineEdge links a node to the node(s) that run after it.
+type PipelineEdge struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// PipelineStep is a single step (sh, echo, ...) inside a Pipeline node.
+type PipelineStep struct {
+	ID                 string `json:"id"`
+	DisplayName        string `json:"displayName"`
+	DisplayDescription string `json:"displayDescription"`
+	Result             string `json:"result"`
+	State              string `json:"state"`
+	DurationInMillis   int64  `json:"durationInMillis"`
+}
+
+// IsParallelBranch reports whether the node is one branch of a parallel block
+func (n *PipelineNode) IsParallelBranch() bool {
+	return n.Type == "PARALLEL"
+}
+
+// GetStatus returns the node status normalized to the Status constants
+func (n *PipelineNode) GetStatus() string {
+	return statusFromBlueOcean(n.Result, n.State)
+}
+
+// GetDuration returns how long the node ran
+func (n *PipelineNode) GetDuration() time.Duration {
+	return time.Duration(n.DurationInMillis) * time.Millisecond
+}
+
+// GetStatus returns the step status normalized to the Status constants
+func (s *PipelineStep) GetStatus() string {
+	return statusFromBlueOcean(s.Result, s.State)
+}
+
+// statusFromBlueOcean maps a Blue Ocean result and state to the normalized
+// Status constants; the state wins while a node has not finished.
+func statusFromBlueOcean(result, state string) string {
+	switch state {
+	case "RUNNING":
+		return StatusBuilding
+	case "QUEUED", "PAUSED":
+		return StatusPending
+	case "SKIPPED", "NOT_BUILT":
+		return StatusNotBuilt
+	}
+
+	switch result {
+	case "SUCCESS":
+		return StatusSuccess
+	case "FAILURE":
+		return StatusFailed
+	case "UNSTABLE":
+		return StatusUnstable
+	case "ABORTED":
+		return StatusAborted
+	case "NOT_BUILT":
+		return StatusNotBuilt
+	default:
+		return StatusUnknown
+	}
+}
+
+// pipelineNodeFromStage converts a wfapi stage to the Blue Ocean node shape.
+func pipelineNodeFromStage(stage PipelineStage) PipelineNode {
+	node := PipelineNode{
+		ID:               stage.ID,
+		DisplayName:      stage.Name,
+		Type:             "STAGE",
+		State:            "FINISHED",
+		DurationInMillis: stage.DurationMillis - stage.PauseDurationMillis,
+	}
+	switch stage.Status {
+	case "IN_PROGRESS":
+		node.State = "RUNNING"
+	case "PAUSED_PENDING_INPUT":
+		node.State = "PAUSED"
+	case "QUEUED":
+		node.State = "QUEUED"
+	case "NOT_EXECUTED":
+		node.State = "NOT_BUILT"
+	case "FAILED":
+		node.Result = "FAILURE"
+	default:
+		node.Result = stage.Status
+	}
+	return node
+}
+
+// Artifact is a file archived by a build.
+type Artifact struct {
+	DisplayPath  string `json:"displayPath"`
+	FileName     string `json:"fileName"`
+	RelativePath string `json:"relativePath"` // path below the build's artifact/ URL
+}
+
+// ProgressFunc receives the bytes transferred so far and the expected total,
+// which is -1 when unknown.
+type ProgressFunc func(written, total int64)
+
+// TestReport is a build's JUnit test report.
+type TestReport struct {
+	FailCount int         `json:"failCount"`
+	PassCount int         `json:"passCount"`
+	SkipCount int         `json:"skipCount"`
+	Duration  float64     `json:"duration"` // seconds
+	Suites    []TestSuite `json:"suites"`
+}
+
+// TestSuite is one suite (usually one report file) of a test report.
+type TestSuite struct {
+	Name     string     `json:"name"`
+	Duration float64    `json:"duration"` // seconds
+	Cases    []TestCase `json:"cases"`
+}
+
+// TestCase is a single test result.
+type TestCase struct {
+	ClassName       string  `json:"className"`
+	Name            string  `json:"name"`
+	Status          string  `json:"status"`   // PASSED, FIXED, SKIPPED, FAILED, REGRESSION
+	Duration        float64 `json:"duration"` // seconds
+	ErrorDetails    string  `json:"errorDetails"`
+	ErrorStackTrace string  `json:"errorStackTrace"`
+	Skipped         bool    `json:"skipped"`
+	SkippedMessage  string  `json:"skippedMessage"`
+	// Age is how many builds in a row the test has been failing.
+	Age int `json:"age"`
+}
+
+// TotalCount returns the number of tests in the report
+func (r *TestReport) TotalCount() int {
+	return r.FailCount + r.PassCount + r.SkipCount
+}
+
+// FailedCases returns the failing test cases across all suites
+func (r *TestReport) FailedCases() []TestCase {
+	var failed []TestCase
+	for _, suite := range r.Suites {
+		for _, c := range suite.Cases {
+			if c.IsFailed() {
+				failed = append(failed, c)
+			}
+		}
+	}
+	return failed
+}
+
+// IsFailed reports whether the test case failed in this build
+func (c *TestCase) IsFailed() bool {
+	return c.Status == "FAILED" || c.Status == "REGRESSION"
+}
+
+// GetDuration returns the test duration as a time.Duration
+func (c *TestCase) GetDuration() time.Duration {
+	return time.Duration(c.Duration * float64(time.Second))
+}
+
+// Well-known plugins, for feature detection with HasPlugin.
+const (
+	PluginPipelineStageView = "pipeline-stage-view"
+	PluginBlueOcean         = "blueocean"
+)
+
+// Plugin is an installed Jenkins plugin as listed by GetInstalledPlugins.
+type Plugin struct {
+	// ShortName is the plugin ID, e.g. "pipeline-stage-view".
+	ShortName string `json:"shortName"`
+	LongName  string `json:"longName"`
+	Version   string `json:"version"`
+	// Active is false until Jenkins restarts after the plugin is installed or
+	// enabled; Enabled is false when an administrator disabled it.
+	Active    bool   `json:"active"`
+	Enabled   bool   `json:"enabled"`
+	HasUpdate bool   `json:"hasUpdate"`
+	URL       string `json:"url"`
+}
+
+// HasPlugin reports whether the plugin shortName is installed, enabled and
+// active, i.e. its features can be used right now.
+func HasPlugin(plugins []Plugin, shortName string) bool {
+	for _, plugin := range plugins {
+		if plugin.ShortName == shortName {
+			return plugin.Active && plugin.Enabled
+		}
+	}
+	return false
+}
+
+// Credential identifies a credential of a credentials store. Jenkins never
+// returns secrets over the API; this is what a picker needs to show.
+type Credential struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	// TypeName is the kind of credential, e.g. "Username with password".
+	TypeName string `json:"typeName"`
+}
+
+// Fingerprint is Jenkins' record of a file by MD5 checksum: the build that
+// produced it and every build that used it since.
+type Fingerprint struct {
+	FileName string `json:"fileName"`
+	Hash     string `json:"hash"`
+	// Timestamp is when Jenkins first saw the file, in milliseconds.
+	Timestamp int64 `json:"timestamp"`
+	// Original is the build that produced the file, or nil when it came from
+	// outside Jenkins or that build was deleted.
+	Original *FingerprintOrigin `json:"original"`
+	Usage    []FingerprintUsage `json:"usage"`
+}
+
+// FingerprintOrigin identifies the build that produced a fingerprinted file.
+type FingerprintOrigin struct {
+	// Name is the job's full name.
+	Name   string `json:"name"`
+	Number int    `json:"number"`
+}
+
+// FingerprintUsage lists the builds of one job that used a fingerprinted file.
+type FingerprintUsage struct {
+	// Name is the job's full name.
+	Name   string `json:"name"`
+	Ranges struct {
+		Ranges []BuildRange `json:"ranges"`
+	} `json:"ranges"`
+}
+
+// BuildRange is a run of consecutive build numbers; End is exclusive.
+type BuildRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// GetTimestamp returns when Jenkins first saw the file as a time.Time
+func (f *Fingerprint) GetTimestamp() time.Time {
+	return time.UnixMilli(f.Timestamp)
+}
+
+// String formats the range as Jenkins does, e.g. "#12" or "#12-#14".
+func (r BuildRange) String() string {
+	if r.End-r.Start <= 1 {
+		return fmt.Sprintf("#%d", r.Start)
+	}
+	return fmt.Sprintf("#%d-#%d", r.Start, r.End-1)
+}
+
+// LoadStatistics holds Jenkins' executor and queue time series, for all
+// nodes (GetOverallLoad) or the nodes of a label (GetLabelLoad).
+type LoadStatistics struct {
+	BusyExecutors   LoadSeries `json:"busyExecutors"`
+	IdleExecutors   LoadSeries `json:"idleExecutors"`
+	OnlineExecutors LoadSeries `json:"onlineExecutors"`
+	TotalExecutors  LoadSeries `json:"totalExecutors"`
+	// AvailableExecutors are idle executors that can take a build now.
+	AvailableExecutors LoadSeries `json:"availableExecutors"`
+	QueueLength        LoadSeries `json:"queueLength"`
+}
+
+// LoadSeries is one statistic at three timescales. Jenkins keeps
+// exponentially decaying averages, so values are fractional.
+type LoadSeries struct {
+	Sec10 LoadTimeSeries `json:"sec10"`
+	Min   LoadTimeSeries `json:"min"`
+	Hour  LoadTimeSeries `json:"hour"`
+}
+
+// LoadTimeSeries is the latest value of a statistic and, when requested, its
+// past values, newest first.
+type LoadTimeSeries struct {
+	Latest  float64   `json:"latest"`
+	History []float64 `json:"history"`
+}
+
+// LabelLoad is the executor state of the nodes carrying a label.
+type LabelLoad struct {
+	Name           string `json:"name"`
+	Offline        bool   `json:"offline"`
+	BusyExecutors  int    `json:"busyExecutors"`
+	IdleExecutors  int    `json:"idleExecutors"`
+	TotalExecutors int    `json:"totalExecutors"`
+	Nodes          []struct {
+		// NodeName is empty for the built-in node.
+		NodeName string `json:"nodeName"`
+	} `json:"nodes"`
+	LoadStatistics LoadStatistics `json:"loadStatistics"`
+}
+
+// Current returns the most recent sample, the one a gauge should show.
+func (s LoadSeries) Current() float64 {
+	return s.Sec10.Latest
+}
+
+// Utilization returns busy executors as a fraction of online ones, between
+// 0 and 1; it is 0 when no executor is online.
+func (l *LoadStatistics) Utilization() float64 {
+	online := l.OnlineExecutors.Current()
+	if online <= 0 {
+		return 0
+	}
+	return math.Min(l.BusyExecutors.Current()/online, 1)
+}
+
+// queueGrowthWindow is how many per-minute samples QueueGrowth looks back.
+const queueGrowthWindow = 10
+
+// QueueGrowth returns how many items per minute the queue gained (or, when
+// negative, lost) on average over the last 10 minutes. It is 0 until Jenkins
+// has two minutes of history.
+func (l *LoadStatistics) QueueGrowth() float64 {
+	history := l.QueueLength.Min.History
+	if len(history) > queueGrowthWindow {
+		history = history[:queueGrowthWindow]
+	}
+	if len(history) < 2 {
+		return 0
+	}
+	return (history[0] - history[len(history)-1]) / float64(len(history)-1)
+}
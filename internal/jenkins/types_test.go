@@ -1,6 +1,8 @@
 package jenkins
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -233,3 +235,230 @@ func TestJob_GetStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestUser_DisplayLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		user *User
+		want string
+	}{
+		{name: "full name and id", user: &User{ID: "jdoe", FullName: "Jane Doe"}, want: "Jane Doe (jdoe)"},
+		{name: "id only", user: &User{ID: "jdoe"}, want: "jdoe"},
+		{name: "full name equals id", user: &User{ID: "jdoe", FullName: "jdoe"}, want: "jdoe"},
+		{name: "full name only", user: &User{FullName: "Jane Doe"}, want: "Jane Doe"},
+		{name: "nil user", user: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.user.DisplayLabel()
+			if got != tt.want {
+				t.Errorf("User.DisplayLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJob_HealthScore(t *testing.T) {
+	var job Job
+	if err := json.Unmarshal([]byte(`{"name":"api","healthReport":[
+		{"score":80,"description":"Build stability: 1 out of the last 5 builds failed."},
+		{"score":45,"description":"Test Result: 11 tests failing out of a total of 20 tests."}]}`), &job); err != nil {
+		t.Fatal(err)
+	}
+	if score, ok := job.HealthScore(); !ok || score != 45 {
+		t.Errorf("HealthScore() = %d, %v, want the worst report, 45", score, ok)
+	}
+	if _, ok := (&Job{Name: "new"}).HealthScore(); ok {
+		t.Error("HealthScore() reported a score for a job without health reports")
+	}
+}
+
+func TestPipelineStage_GetStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{status: "SUCCESS", want: StatusSuccess},
+		{status: "FAILED", want: StatusFailed},
+		{status: "IN_PROGRESS", want: StatusBuilding},
+		{status: "PAUSED_PENDING_INPUT", want: StatusPending},
+		{status: "NOT_EXECUTED", want: StatusNotBuilt},
+		{status: "", want: StatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			stage := PipelineStage{Status: tt.status}
+			if got := stage.GetStatus(); got != tt.want {
+				t.Errorf("GetStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild_GetChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "pipeline change sets",
+			body: `{"changeSets":[{"kind":"git","items":[{"commitId":"3f2a9c81d","msg":"Fix login"}]},` +
+				`{"kind":"git","items":[{"commitId":"77b1e02","msg":"Bump lib"}]}]}`,
+			want: []string{"3f2a9c8 Fix login", "77b1e02 Bump lib"},
+		},
+		{
+			name: "freestyle change set",
+			body: `{"changeSet":{"kind":"git","items":[{"commitId":"a1b2c3d4e5","msg":"Add CI"}]}}`,
+			want: []string{"a1b2c3d Add CI"},
+		},
+		{
+			name: "no changes",
+			body: `{"changeSets":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var build Build
+			if err := json.Unmarshal([]byte(tt.body), &build); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			var got []string
+			for _, change := range build.GetChanges() {
+				got = append(got, change.ShortCommitID()+" "+change.Msg)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueueItem_GetJobFullName(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "top level", url: "https://ci.example.com/job/build/", want: "build"},
+		{name: "nested", url: "https://ci.example.com/jenkins/job/Deploy/job/prod%20eu/", want: "Deploy/prod eu"},
+		{name: "no job segments", url: "", want: "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var item QueueItem
+			item.Task.Name = "fallback"
+			item.Task.URL = tt.url
+			if got := item.GetJobFullName(); got != tt.want {
+				t.Errorf("GetJobFullName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTriggeredBy(t *testing.T) {
+	actions := []BuildAction{
+		{Class: "hudson.model.ParametersAction"},
+		{Causes: []BuildCause{{ShortDescription: "Started by timer"}, {UserID: "Alice", UserName: "Alice Smith"}}},
+	}
+	build := &Build{Actions: actions}
+	item := &QueueItem{Actions: actions}
+
+	for _, tt := range []struct {
+		user string
+		want bool
+	}{
+		{user: "alice", want: true},
+		{user: "bob", want: false},
+		{user: "", want: false},
+	} {
+		if got := build.IsTriggeredBy(tt.user); got != tt.want {
+			t.Errorf("Build.IsTriggeredBy(%q) = %v, want %v", tt.user, got, tt.want)
+		}
+		if got := item.IsTriggeredBy(tt.user); got != tt.want {
+			t.Errorf("QueueItem.IsTriggeredBy(%q) = %v, want %v", tt.user, got, tt.want)
+		}
+	}
+	if (*Build)(nil).IsTriggeredBy("alice") {
+		t.Error("nil build reported as triggered by alice")
+	}
+}
+
+func TestQueuePosition(t *testing.T) {
+	items := []QueueItem{
+		{ID: 12, InQueueSince: 3000},
+		{ID: 10, InQueueSince: 1000},
+		{ID: 11, InQueueSince: 1000},
+	}
+
+	tests := []struct {
+		id           int
+		wantPosition int
+	}{
+		{id: 10, wantPosition: 1},
+		{id: 11, wantPosition: 2},
+		{id: 12, wantPosition: 3},
+		{id: 99, wantPosition: 0},
+	}
+
+	for _, tt := range tests {
+		position, total := QueuePosition(items, tt.id)
+		if position != tt.wantPosition || total != 3 {
+			t.Errorf("QueuePosition(%d) = %d of %d, want %d of 3", tt.id, position, total, tt.wantPosition)
+		}
+	}
+}
+
+func TestDetectRenames(t *testing.T) {
+	built := func(name, fullName string, number int) Job {
+		return Job{Name: name, FullName: fullName, LastBuild: &Build{Number: number, Timestamp: int64(number) * 1000}}
+	}
+	folder := func(name, fullName string, jobs ...Job) Job {
+		return Job{Name: name, FullName: fullName, Class: "com.cloudbees.hudson.plugins.folder.Folder", Jobs: jobs}
+	}
+
+	previous := []Job{
+		built("api", "api", 7),
+		folder("Team", "Team", built("web", "Team/web", 12)),
+		folder("Old", "Old", folder("svc", "Old/svc", built("worker", "Old/svc/worker", 3))),
+		{Name: "fresh", FullName: "fresh"},
+		built("twin-a", "twin-a", 5),
+		built("twin-b", "twin-b", 5),
+	}
+	current := []Job{
+		built("api-v2", "api-v2", 7),
+		folder("Team", "Team", built("web", "Team/web", 13)),
+		folder("New", "New", folder("svc", "New/svc", built("worker", "New/svc/worker", 3))),
+		{Name: "fresher", FullName: "fresher"},
+		built("twin-c", "twin-c", 5),
+		built("twin-d", "twin-d", 5),
+	}
+
+	got := DetectRenames(previous, current)
+	want := []JobRename{
+		{From: "Old", To: "New"},
+		{From: "Old/svc", To: "New/svc"},
+		{From: "Old/svc/worker", To: "New/svc/worker"},
+		{From: "api", To: "api-v2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DetectRenames() = %v, want %v", got, want)
+	}
+}
+
+func TestRunningBuildJobURL(t *testing.T) {
+	for _, tc := range []struct{ url, want string }{
+		{"https://ci.example.com/job/team/job/api/12/", "https://ci.example.com/job/team/job/api/"},
+		{"https://ci.example.com/job/api/3", "https://ci.example.com/job/api/"},
+		{"", ""},
+	} {
+		build := RunningBuild{URL: tc.url}
+		if got := build.JobURL(); got != tc.want {
+			t.Errorf("JobURL() for %q = %q, want %q", tc.url, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package jobcache
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+const fileName = "jobs.cache"
+
+// Path returns the location of the cached job names file.
+func Path() string {
+	return filepath.Join(auth.ConfigDir(), fileName)
+}
+
+// Save writes the full names of all buildable jobs (folders excluded) to the cache file.
+func Save(jobs []jenkins.Job) error {
+	names := FullNames(jobs)
+
+	if err := os.MkdirAll(auth.ConfigDir(), 0755); err != nil {
+		return err
+	}
+
+	tmp := Path() + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(names, "\n")+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, Path())
+}
+
+// Load returns the cached job names along with the time they were written.
+func Load() ([]string, time.Time, error) {
+	file, err := os.Open(Path())
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			names = append(names, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	return names, info.ModTime(), nil
+}
+
+// FullNames flattens a job tree into the sorted full names of its non-folder jobs.
+func FullNames(jobs []jenkins.Job) []string {
+	var names []string
+
+	var walk func(items []jenkins.Job)
+	walk = func(items []jenkins.Job) {
+		for i := range items {
+			job := &items[i]
+			if job.IsFolder() {
+				walk(job.Jobs)
+				continue
+			}
+			if job.FullName != "" {
+				names = append(names, job.FullName)
+			}
+		}
+	}
+
+	walk(jobs)
+	sort.Strings(names)
+	return names
+}
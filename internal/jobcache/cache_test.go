@@ -0,0 +1,37 @@
+package jobcache
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+func TestFullNames(t *testing.T) {
+	jobs := []jenkins.Job{
+		{Name: "zeta", FullName: "zeta"},
+		{
+			Name:     "Production",
+			FullName: "Production",
+			Class:    "com.cloudbees.hudson.plugins.folder.Folder",
+			Jobs: []jenkins.Job{
+				{Name: "api", FullName: "Production/api"},
+				{
+					Name:     "Backend",
+					FullName: "Production/Backend",
+					Jobs: []jenkins.Job{
+						{Name: "worker", FullName: "Production/Backend/worker"},
+					},
+				},
+			},
+		},
+		{Name: "empty-folder", FullName: "empty-folder", Class: "com.cloudbees.hudson.plugins.folder.Folder"},
+		{Name: "alpha", FullName: "alpha"},
+	}
+
+	got := FullNames(jobs)
+	want := []string{"Production/Backend/worker", "Production/api", "alpha", "zeta"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FullNames() = %v, want %v", got, want)
+	}
+}
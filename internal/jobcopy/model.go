@@ -0,0 +1,201 @@
+// Package jobcopy is a modal that creates a new job from an existing one,
+// typically a template.
+package jobcopy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/inflight"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+const modalWidth = 64
+
+// ClosedMsg is emitted when the modal is dismissed. Created is the full name
+// of the new job, or empty when nothing was copied.
+type ClosedMsg struct {
+	Created string
+}
+
+type copiedMsg struct {
+	to  string
+	err error
+}
+
+type state int
+
+const (
+	stateInput state = iota
+	stateCopying
+	stateDone
+)
+
+// Model asks for the new job's full name and copies the source job to it.
+type Model struct {
+	client  jenkins.JenkinsClient
+	tracker *inflight.Tracker
+	from    string
+	input   textinput.Model
+	spinner spinner.Model
+	state   state
+	created string
+	err     error
+
+	width  int
+	height int
+}
+
+// New creates a copy modal for the job fromFullName, suggesting a sibling
+// named after it. The copy is registered with tracker so quitting can wait
+// for it.
+func New(client jenkins.JenkinsClient, tracker *inflight.Tracker, fromFullName string) *Model {
+	input := textinput.New()
+	input.Prompt = "New job: "
+	input.CharLimit = 0
+	input.Width = modalWidth - 16
+	input.SetValue(fromFullName + "-copy")
+	input.CursorEnd()
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.HighlightStyle
+
+	return &Model{client: client, tracker: tracker, from: fromFullName, input: input, spinner: s}
+}
+
+// CapturesInput reports that plain keys are typed into the name while it is
+// being edited.
+func (m *Model) CapturesInput() bool {
+	return m.state == stateInput
+}
+
+// Init focuses the name input.
+func (m *Model) Init() tea.Cmd {
+	return m.input.Focus()
+}
+
+// Update handles TEA messages for the modal.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case copiedMsg:
+		m.state = stateDone
+		m.err = msg.err
+		if msg.err == nil {
+			m.created = msg.to
+		}
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state != stateCopying {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		switch m.state {
+		case stateInput:
+			switch msg.String() {
+			case "esc":
+				return m, closeCmd("")
+			case "enter":
+				to := strings.Trim(strings.TrimSpace(m.input.Value()), "/")
+				if to == "" || to == m.from {
+					m.err = fmt.Errorf("enter a new name for the copy")
+					return m, nil
+				}
+				m.state = stateCopying
+				m.err = nil
+				return m, tea.Batch(m.spinner.Tick, copyCmd(m.tracker, m.client, m.from, to))
+			}
+		case stateDone:
+			switch msg.String() {
+			case "enter", "esc":
+				return m, closeCmd(m.created)
+			}
+			return m, nil
+		default:
+			return m, nil
+		}
+	}
+
+	if m.state != stateInput {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// View renders the modal.
+func (m *Model) View() string {
+	var content strings.Builder
+	content.WriteString(ui.TitleStyle.Render("Copy Job"))
+	content.WriteString("\n\n")
+	content.WriteString(fmt.Sprintf("From: %s\n", m.from))
+
+	switch m.state {
+	case stateInput:
+		content.WriteString(m.input.View())
+		content.WriteString("\n\n")
+		if m.err != nil {
+			content.WriteString(ui.ErrorStyle.Render(m.err.Error()))
+			content.WriteString("\n\n")
+		}
+		content.WriteString(ui.SubtleStyle.Render("Folders must exist  [Enter] Copy  [Esc] Cancel"))
+	case stateCopying:
+		content.WriteString(fmt.Sprintf("%s Copying...", m.spinner.View()))
+	case stateDone:
+		if m.err != nil {
+			content.WriteString(ui.ErrorStyle.Render("Copy failed."))
+			content.WriteString("\n")
+			content.WriteString(ui.SubtleStyle.Render(m.err.Error()))
+		} else {
+			content.WriteString(ui.SuccessStyle.Render(fmt.Sprintf("✓ Created %s", m.created)))
+		}
+		content.WriteString("\n\n")
+		content.WriteString(ui.SubtleStyle.Render("[Enter] Close"))
+	}
+
+	panel := lipgloss.NewStyle().
+		Width(modalWidth).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(content.String())
+
+	if m.width == 0 || m.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
+}
+
+func copyCmd(tracker *inflight.Tracker, client jenkins.JenkinsClient, from, to string) tea.Cmd {
+	ctx, done := tracker.Start(fmt.Sprintf("Copy %s to %s", from, to))
+	return func() tea.Msg {
+		err := fmt.Errorf("Jenkins client not configured")
+		if client != nil {
+			err = client.CopyJob(ctx, from, to)
+		}
+		done(err)
+		return copiedMsg{to: to, err: err}
+	}
+}
+
+func closeCmd(created string) tea.Cmd {
+	return func() tea.Msg {
+		return ClosedMsg{Created: created}
+	}
+}
@@ -0,0 +1,81 @@
+package jobcopy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/inflight"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+func TestCopyCreatesTheNamedJob(t *testing.T) {
+	client := &jenkinstest.Client{}
+	tracker := inflight.New()
+	m := New(client, tracker, "templates/service")
+	m.Init()
+	m.input.SetValue("")
+
+	var cmd tea.Cmd
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune(" team/api/ ")}, {Type: tea.KeyEnter}} {
+		_, cmd = m.Update(key)
+	}
+	if cmd == nil || m.CapturesInput() {
+		t.Fatal("Enter did not start the copy")
+	}
+	if pending := tracker.Pending(); len(pending) != 1 || pending[0] != "Copy templates/service to team/api" {
+		t.Errorf("pending actions = %q, want the copy tracked until Jenkins answers", pending)
+	}
+	// The batch holds the spinner and the copy; run the copy.
+	m.Update(cmd().(tea.BatchMsg)[1]())
+	if pending := tracker.Pending(); len(pending) != 0 {
+		t.Errorf("pending actions = %q after Jenkins answered", pending)
+	}
+	if calls := client.CallsTo("CopyJob"); len(calls) != 1 || calls[0].Args[0] != "templates/service" || calls[0].Args[1] != "team/api" {
+		t.Fatalf("CopyJob calls = %v, want templates/service to team/api", calls)
+	}
+	if view := m.View(); !strings.Contains(view, "Created team/api") {
+		t.Errorf("view does not show the new job:\n%s", view)
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg, ok := cmd().(ClosedMsg); !ok || msg.Created != "team/api" {
+		t.Errorf("closing sent %+v, want the created job", msg)
+	}
+}
+
+func TestCopyNeedsANewName(t *testing.T) {
+	client := &jenkinstest.Client{}
+	m := New(client, nil, "service")
+	m.input.SetValue("service")
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Fatal("copying a job onto itself started")
+	}
+	if view := m.View(); !strings.Contains(view, "enter a new name") {
+		t.Errorf("view does not explain the refusal:\n%s", view)
+	}
+	if calls := client.CallsTo("CopyJob"); len(calls) != 0 {
+		t.Errorf("CopyJob calls = %v", calls)
+	}
+}
+
+func TestFailedCopyClosesWithoutAJob(t *testing.T) {
+	client := &jenkinstest.Client{
+		CopyJobFunc: func(context.Context, string, string) error { return errors.New("status 400: a job already exists") },
+	}
+	m := New(client, nil, "service")
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(cmd().(tea.BatchMsg)[1]())
+	if view := m.View(); !strings.Contains(view, "Copy failed") || !strings.Contains(view, "already exists") {
+		t.Errorf("view does not show the failure:\n%s", view)
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if msg, ok := cmd().(ClosedMsg); !ok || msg.Created != "" {
+		t.Errorf("closing sent %+v, want no created job", msg)
+	}
+}
@@ -0,0 +1,90 @@
+package jobs
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+// Performance budgets of the jobs panel on a large instance
+// (jenkinstest.LargeInstance, about 20,000 jobs), per operation on one core:
+//
+//	buildTree              25ms  once per jobs fetch
+//	flattenVisibleNodes     5ms  on every expand, collapse and refresh
+//	refreshListItems       10ms  likewise, with every folder expanded
+//	search                 50ms  per query, after the 120ms debounce
+//	folder fetched         10ms  per deep folder opened
+//	spinner tick          0.1ms  ten times a second while folders load
+//
+// Everything but buildTree runs while the user types or moves, so it must
+// stay well inside a frame's worth of latency. Check a change against them
+// with
+//
+//	go test -run '^$' -bench . -benchmem ./internal/jobs
+func largeModel(b *testing.B) Model {
+	b.Helper()
+	m := New(nil)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	m, _ = m.Update(JobsFetchedMsg{Jobs: jenkinstest.JobTree(jenkinstest.LargeInstance)})
+	return m
+}
+
+func BenchmarkBuildTree(b *testing.B) {
+	jobs := jenkinstest.JobTree(jenkinstest.LargeInstance)
+	b.ResetTimer()
+	for b.Loop() {
+		buildTree(jobs)
+	}
+}
+
+func BenchmarkFlattenVisibleNodes(b *testing.B) {
+	tree := buildTree(jenkinstest.JobTree(jenkinstest.LargeInstance))
+	expandAll(tree)
+	b.ResetTimer()
+	for b.Loop() {
+		flattenVisibleNodes(tree)
+	}
+}
+
+func BenchmarkRefreshListItems(b *testing.B) {
+	m := largeModel(b)
+	expandAll(m.tree)
+	b.ResetTimer()
+	for b.Loop() {
+		m.refreshListItems()
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	for _, query := range []string{"svc", "team-03/team-07/svc-042", "zzz"} {
+		b.Run(query, func(b *testing.B) {
+			m := largeModel(b)
+			b.ResetTimer()
+			for b.Loop() {
+				m.applySearch(query)
+			}
+		})
+	}
+}
+
+func BenchmarkFolderFetched(b *testing.B) {
+	m := largeModel(b)
+	folder := m.folderNode(m.searchCatalog[0].FullName)
+	msg := folderFetchedMsg{fullName: folder.FullName, jobs: folder.Job.Jobs}
+	b.ResetTimer()
+	for b.Loop() {
+		m.applyFolderFetched(msg)
+	}
+}
+
+func BenchmarkSpinnerTick(b *testing.B) {
+	m := largeModel(b)
+	m.fetching = map[string]bool{m.searchCatalog[0].FullName: true}
+	m.applyFetching()
+	tick := m.spinner.Tick()
+	b.ResetTimer()
+	for b.Loop() {
+		m, _ = m.Update(tick)
+	}
+}
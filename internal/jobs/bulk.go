@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/bulk"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+// bulkActionKeys are the actions offered on the marked jobs, by key.
+var bulkActionKeys = map[string]bulk.Action{
+	"b": bulk.ActionBuild,
+	"d": bulk.ActionDisable,
+	"e": bulk.ActionEnable,
+	"f": bulk.ActionFavorite,
+}
+
+// handleSelectKey enters visual-select mode on v and, in it, marks jobs and
+// requests bulk actions on them. It reports whether it used the key; moving
+// the cursor, expanding folders and searching work as usual.
+func (m *Model) handleSelectKey(msg tea.KeyMsg, node *JobTree) (bool, tea.Cmd) {
+	key := msg.String()
+	if !m.selecting {
+		if key != "v" {
+			return false, nil
+		}
+		m.selecting = true
+		m.marked = make(map[string]bool)
+		m.toggleMark(node)
+		m.updateListDimensions()
+		return true, nil
+	}
+
+	switch key {
+	case "v", "esc":
+		m.leaveSelect()
+		return true, nil
+	case " ":
+		m.toggleMark(node)
+		return true, nil
+	}
+	action, ok := bulkActionKeys[key]
+	if !ok {
+		return false, nil
+	}
+	jobs := m.markedJobs()
+	if len(jobs) == 0 {
+		return true, nil
+	}
+	return true, func() tea.Msg {
+		return BulkActionRequestedMsg{Action: action, Jobs: jobs}
+	}
+}
+
+// toggleMark marks the job of node, or every job in the folder of node,
+// unmarking them instead when all are marked already.
+func (m *Model) toggleMark(node *JobTree) {
+	var names []string
+	for _, n := range append([]*JobTree{node}, collectAllNodes(node)...) {
+		if !n.IsFolder && n.Job != nil {
+			names = append(names, n.FullName)
+		}
+	}
+	all := len(names) > 0
+	for _, name := range names {
+		all = all && m.marked[name]
+	}
+	for _, name := range names {
+		if all {
+			delete(m.marked, name)
+		} else {
+			m.marked[name] = true
+		}
+	}
+	m.applyMarks(m.statusNodes())
+	m.refreshListItems()
+}
+
+// leaveSelect ends visual-select mode, dropping the marks.
+func (m *Model) leaveSelect() {
+	m.selecting = false
+	m.marked = nil
+	m.applyMarks(m.statusNodes())
+	m.refreshListItems()
+	m.updateListDimensions()
+}
+
+// applyMarks flags the rows of marked jobs among nodes.
+func (m *Model) applyMarks(nodes []*JobTree) {
+	for _, node := range nodes {
+		node.Marked = m.marked[node.FullName]
+	}
+}
+
+// markedJobs returns the marked jobs in tree order.
+func (m Model) markedJobs() []jenkins.Job {
+	var jobs []jenkins.Job
+	for _, node := range m.searchCatalog {
+		if m.marked[node.FullName] && !node.IsFolder && node.Job != nil {
+			jobs = append(jobs, *node.Job)
+		}
+	}
+	return jobs
+}
+
+// applyBulkDone takes in the outcome of a bulk action: favorites are added
+// here, and jobs are fetched again after changes to Jenkins.
+func (m *Model) applyBulkDone(msg bulk.ClosedMsg) tea.Cmd {
+	if !msg.Confirmed {
+		return nil
+	}
+	m.leaveSelect()
+	if len(msg.Done) == 0 {
+		return nil
+	}
+	if msg.Action == bulk.ActionFavorite {
+		m.favorites.Add(msg.Done...)
+		m.applyJobs(m.allJobs, nil)
+		return saveFavoritesCmd(m.favorites)
+	}
+	return func() tea.Msg { return RefreshRequestedMsg{} }
+}
+
+// selectBarView renders the visual-select help line.
+func (m Model) selectBarView() string {
+	return ui.HighlightStyle.Render(fmt.Sprintf("VISUAL %d marked", len(m.markedJobs()))) + "  " +
+		ui.SubtleStyle.Render("Space mark · b build · d disable · e enable · f favorite · Esc done")
+}
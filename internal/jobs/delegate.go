@@ -7,6 +7,7 @@
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
 	"github.com/gorbach/jdash/internal/ui"
 	"github.com/gorbach/jdash/internal/utils"
 )
@@ -35,7 +36,7 @@ func (d jobDelegate) Update(msg tea.Msg, m *list.Model) tea.Cmd {
 
 // Render renders a single job tree node
 func (d jobDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
-	node, ok := item.(JobTree)
+	node, ok := item.(*JobTree)
 	if !ok {
 		return
 	}
@@ -64,7 +65,7 @@ func (d jobDelegate) Render(w io.Writer, m list.Model, index int, item list.Item
 	// Status icon and styling
 	var status string
 	if node.Job != nil {
-		jobStatus := node.Job.GetStatus()
+		jobStatus := nodeStatus(node)
 		icon := ui.GetStatusIcon(jobStatus)
 		statusStyle := ui.GetStatusStyle(jobStatus)
 
@@ -73,27 +74,47 @@ func (d jobDelegate) Render(w io.Writer, m list.Model, index int, item list.Item
 		} else {
 			status = statusStyle.Render(icon)
 		}
+	} else if node.Pinned {
+		status = ui.HighlightStyle.Render(ui.IconFavorite)
 	} else {
 		status = ui.SubtleStyle.Render(ui.IconFolder)
 	}
 
 	// Job name
 	name := node.Name
-	if node.SearchResult {
+	if node.SearchResult || (node.Pinned && !node.IsFolder) {
 		name = node.FullName
 	}
 	if len(node.MatchIndexes) > 0 {
 		name = renderHighlightedText(name, node.MatchIndexes)
 	}
+	if node.Locked {
+		name += " " + ui.IconLocked
+	}
+	if node.Favorite && !node.Pinned {
+		name += " " + ui.HighlightStyle.Render(ui.IconFavorite)
+	}
+	if node.Fetching != "" {
+		name += " " + node.Fetching + ui.SubtleStyle.Render(" loading")
+	}
 
 	// Metadata (status label, duration and timestamp for non-folders)
 	var metadata string
 	if node.Job != nil && !node.IsFolder {
-		jobStatus := node.Job.GetStatus()
+		jobStatus := nodeStatus(node)
 		statusStyle := ui.GetStatusStyle(jobStatus)
 		statusLabel := statusStyle.Render(fmt.Sprintf("[%s]", jobStatus))
 
-		if node.Job.LastBuild != nil {
+		if node.Running != nil {
+			// Live from the queue poll; the elapsed time ticks with each redraw.
+			number := fmt.Sprintf("#%d", node.Running.BuildNumber)
+			if node.RunningCount > 1 {
+				number += fmt.Sprintf(" ×%d", node.RunningCount)
+			}
+			metadata = fmt.Sprintf("  %s  %s  %s", statusLabel,
+				statusStyle.Render(number),
+				ui.SubtleStyle.Render(utils.FormatDuration(node.Running.GetElapsedTime())))
+		} else if node.Job.LastBuild != nil {
 			duration := utils.FormatDuration(node.Job.LastBuild.GetDuration())
 			timestamp := utils.FormatRelativeTime(node.Job.LastBuild.GetTimestamp())
 			metadata = fmt.Sprintf("  %s  %s  %s", statusLabel,
@@ -102,6 +123,24 @@ func (d jobDelegate) Render(w io.Writer, m list.Model, index int, item list.Item
 		} else {
 			metadata = fmt.Sprintf("  %s  %s", statusLabel, ui.SubtleStyle.Render("never built"))
 		}
+		if score, ok := node.Job.HealthScore(); ok {
+			metadata += "  " + ui.WeatherIcon(score)
+		}
+		if node.Queued > 0 {
+			badge := ui.IconPending + " queued"
+			if node.Queued > 1 {
+				badge = fmt.Sprintf("%s queued ×%d", ui.IconPending, node.Queued)
+			}
+			metadata += "  " + ui.PendingStyle.Render(badge)
+		}
+	} else if node.Job != nil && node.Job.IsDisabled() {
+		// Folders have no status of their own, but some can be disabled.
+		metadata = "  " + ui.GetStatusStyle(jenkins.StatusDisabled).Render(fmt.Sprintf("[%s]", jenkins.StatusDisabled))
+	}
+
+	// Marked jobs show the mark in place of the expansion icon they lack.
+	if node.Marked {
+		expandIcon = ui.HighlightStyle.Render(ui.IconMarked) + " "
 	}
 
 	// Combine parts
@@ -122,6 +161,15 @@ func (d jobDelegate) Render(w io.Writer, m list.Model, index int, item list.Item
 	fmt.Fprint(w, line)
 }
 
+// nodeStatus is a job's status, building while the latest queue poll saw it
+// on an executor even if the last jobs fetch predates the build.
+func nodeStatus(node *JobTree) string {
+	if node.Running != nil {
+		return jenkins.StatusBuilding
+	}
+	return node.Job.GetStatus()
+}
+
 func renderHighlightedText(text string, indexes []int) string {
 	if len(indexes) == 0 {
 		return text
@@ -0,0 +1,210 @@
+package jobs
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+// statusFilter is the set of statuses the tree is pruned to; empty shows
+// every job.
+type statusFilter uint8
+
+const (
+	filterFailed statusFilter = 1 << iota
+	filterBuilding
+	filterUnstable
+	filterDisabled
+	filterNeverBuilt
+)
+
+// statusFilterToggles are the filters of the filter bar in display order,
+// with the key that toggles each.
+var statusFilterToggles = []struct {
+	filter statusFilter
+	key    string
+	label  string
+}{
+	{filterFailed, "f", "failed"},
+	{filterBuilding, "b", "building"},
+	{filterUnstable, "u", "unstable"},
+	{filterDisabled, "d", "disabled"},
+	{filterNeverBuilt, "n", "never built"},
+}
+
+// matches reports whether the job of node has one of the filtered statuses.
+// Jobs running now count as building even before the next jobs fetch.
+func (f statusFilter) matches(node *JobTree) bool {
+	if node.IsFolder || node.Job == nil {
+		return false
+	}
+	if f&filterBuilding != 0 && node.Running != nil {
+		return true
+	}
+	switch node.Job.GetStatus() {
+	case jenkins.StatusFailed:
+		return f&filterFailed != 0
+	case jenkins.StatusBuilding:
+		return f&filterBuilding != 0
+	case jenkins.StatusUnstable:
+		return f&filterUnstable != 0
+	case jenkins.StatusDisabled:
+		return f&filterDisabled != 0
+	case jenkins.StatusNeverBuilt, jenkins.StatusNotBuilt:
+		return f&filterNeverBuilt != 0
+	}
+	return false
+}
+
+// String lists the filtered statuses, e.g. "failed, unstable".
+func (f statusFilter) String() string {
+	var labels []string
+	for _, toggle := range statusFilterToggles {
+		if f&toggle.filter != 0 {
+			labels = append(labels, toggle.label)
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+// flattenFilteredNodes returns the visible nodes like flattenVisibleNodes,
+// leaving out jobs the filter does not match and folders without any such
+// job inside, so matches keep the folders they are in.
+func flattenFilteredNodes(tree *JobTree, filter statusFilter) []*JobTree {
+	var result []*JobTree
+	var walk func(node *JobTree) bool
+	// walk appends node's rows and reports whether it holds a match. Rows
+	// are appended first and dropped again when nothing below matched.
+	walk = func(node *JobTree) bool {
+		if !node.IsFolder {
+			if filter.matches(node) {
+				result = append(result, node)
+				return true
+			}
+			return false
+		}
+		start := len(result)
+		if node.Level >= 0 {
+			result = append(result, node)
+		}
+		open := node.Expanded || node.Level < 0
+		found := false
+		for _, child := range node.Children {
+			if open {
+				found = walk(child) || found
+			} else if containsMatch(child, filter) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = result[:start]
+		}
+		return found
+	}
+	if tree != nil {
+		walk(tree)
+	}
+	return result
+}
+
+// containsMatch reports whether node is, or holds, a job the filter matches.
+func containsMatch(node *JobTree, filter statusFilter) bool {
+	if !node.IsFolder {
+		return filter.matches(node)
+	}
+	for _, child := range node.Children {
+		if containsMatch(child, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandMatches opens the folders holding jobs the filter matches, so
+// applying a filter shows its results right away.
+func expandMatches(tree *JobTree, filter statusFilter) {
+	for _, node := range collectAllNodes(tree) {
+		if filter.matches(node) {
+			expandPathToNode(node.Parent)
+		}
+	}
+}
+
+// filterBarView renders the filter bar: each status with its key, checked
+// when filtered.
+func (m Model) filterBarView() string {
+	parts := make([]string, 0, len(statusFilterToggles))
+	for _, toggle := range statusFilterToggles {
+		box := "[ ]"
+		style := ui.SubtleStyle
+		if m.statusFilter&toggle.filter != 0 {
+			box = "[x]"
+			style = ui.HighlightStyle
+		}
+		parts = append(parts, style.Render(box+" "+toggle.key+" "+toggle.label))
+	}
+	return strings.Join(parts, "  ") + "  " + ui.SubtleStyle.Render("x clear · Enter/Esc close")
+}
+
+// handleFilterKey opens the filter bar on F and, while it is open, toggles
+// the filters. It reports whether it used the key; other keys, such as
+// moving the cursor, work as usual with the bar open.
+func (m *Model) handleFilterKey(msg tea.KeyMsg) bool {
+	key := msg.String()
+	if !m.filterBar {
+		if key != "F" || m.searchMode {
+			return false
+		}
+		m.filterBar = true
+		m.updateListDimensions()
+		return true
+	}
+
+	switch key {
+	case "F", "enter", "esc":
+		m.filterBar = false
+		m.updateListDimensions()
+		return true
+	case "/":
+		// Searching takes the bar's line.
+		m.filterBar = false
+		m.updateListDimensions()
+		return false
+	case "x":
+		m.setStatusFilter(0)
+		return true
+	}
+	for _, toggle := range statusFilterToggles {
+		if key == toggle.key {
+			m.setStatusFilter(m.statusFilter ^ toggle.filter)
+			return true
+		}
+	}
+	return false
+}
+
+// setStatusFilter applies filter, keeping the cursor on the selected node
+// while it is still listed.
+func (m *Model) setStatusFilter(filter statusFilter) {
+	selected := m.currentSelectionNode()
+	m.statusFilter = filter
+	if filter != 0 {
+		expandMatches(m.tree, filter)
+	}
+	m.refreshListItems()
+	m.selectNode(selected)
+}
+
+// statusFilterMatches counts the jobs the status filter matches.
+func (m Model) statusFilterMatches() int {
+	count := 0
+	for _, node := range m.searchCatalog {
+		if m.statusFilter.matches(node) {
+			count++
+		}
+	}
+	return count
+}
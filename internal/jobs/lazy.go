@@ -0,0 +1,289 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/statusbar"
+)
+
+// folderFetchedMsg carries the children of a folder expanded below the levels
+// GetAllJobs returns.
+type folderFetchedMsg struct {
+	fullName string
+	jobs     []jenkins.Job
+	err      error
+}
+
+func fetchFolderCmd(ctx context.Context, client jenkins.JenkinsClient, fullName string) tea.Cmd {
+	return func() tea.Msg {
+		jobs, err := client.GetFolderJobs(ctx, fullName)
+		return folderFetchedMsg{fullName: fullName, jobs: jobs, err: err}
+	}
+}
+
+// needsChildren reports whether node is a folder whose children were never
+// fetched: GetAllJobs stops a few levels down, leaving the jobs of deeper
+// folders out, while empty folders it reached come back with an empty list.
+func needsChildren(node *JobTree) bool {
+	return node != nil && node.IsFolder && !node.Pinned && node.Job != nil && node.Job.Jobs == nil
+}
+
+// loadChildrenCmd starts fetching the children of node if it was expanded
+// without them.
+func (m *Model) loadChildrenCmd(node *JobTree) tea.Cmd {
+	if !node.Expanded {
+		return nil
+	}
+	return m.fetchChildrenCmd(node)
+}
+
+// fetchChildrenCmd starts fetching the children of node if they were never
+// fetched, showing a spinner on its row meanwhile.
+func (m *Model) fetchChildrenCmd(node *JobTree) tea.Cmd {
+	if m.client == nil || !needsChildren(node) {
+		return nil
+	}
+	if m.childrenLoaded(node) || m.fetching[node.FullName] {
+		return nil
+	}
+	if m.fetching == nil {
+		m.fetching = make(map[string]bool)
+	}
+	m.fetching[node.FullName] = true
+	m.applyFetching()
+	m.refreshListItems()
+	return tea.Batch(fetchFolderCmd(m.ctx, m.client, node.FullName), m.spinner.Tick)
+}
+
+// applyFolderFetched grafts the fetched children into the tree. When they
+// could not be fetched it reports why, collapsing the folder again unless it
+// still shows the children fetched before.
+func (m *Model) applyFolderFetched(msg folderFetchedMsg) tea.Cmd {
+	delete(m.fetching, msg.fullName)
+	if msg.err != nil {
+		if _, loaded := m.loadedFolders[msg.fullName]; !loaded {
+			collapseNode(m.folderNode(msg.fullName))
+		}
+		m.applyFetching()
+		m.refreshListItems()
+		feedback := statusbar.FeedbackMsg{Text: fmt.Sprintf("✗ Failed to load %s: %v", msg.fullName, msg.err), IsError: true}
+		return func() tea.Msg { return feedback }
+	}
+
+	if m.loadedFolders == nil {
+		m.loadedFolders = make(map[string][]jenkins.Job)
+	}
+	jobs := msg.jobs
+	if jobs == nil {
+		jobs = []jenkins.Job{}
+	}
+	m.loadedFolders[msg.fullName] = jobs
+	folder := m.folderNode(msg.fullName)
+	if folder == nil {
+		// The folder left the tree meanwhile; the children are grafted
+		// should the next fetch bring it back.
+		m.applyFetching()
+		m.refreshListItems()
+		return nil
+	}
+	m.graftChildren(folder, jobs)
+	return tea.Batch(m.loadExpandedCmd(folder), m.loadFavoritesCmd())
+}
+
+// graftChildren puts the fetched children of folder in place. Only the
+// folder's subtree is built and brought up to date: rebuilding the whole
+// tree for each folder opened was slow on large instances. The expanded
+// folders, search and cursor are kept as on refresh.
+func (m *Model) graftChildren(folder *JobTree, jobs []jenkins.Job) {
+	selected, selectedPinned := "", false
+	if node := m.currentSelectionNode(); node != nil {
+		selected, selectedPinned = node.FullName, node.Pinned
+	}
+	expanded := expandedFolders(folder)
+	stale := make(map[*JobTree]bool)
+	favoritesChanged := false
+	for _, node := range collectAllNodes(folder)[1:] {
+		stale[node] = true
+		favoritesChanged = favoritesChanged || node.Favorite
+	}
+
+	folder.Children = []*JobTree{}
+	for _, job := range graftFolders(jobs, m.loadedFolders) {
+		addJobToTree(folder, job, folder.Level+1)
+	}
+	restoreExpanded(folder, expanded)
+	m.applyRestore(folder)
+	added := collectAllNodes(folder)[1:]
+	for _, node := range added {
+		node.Favorite = !node.IsFolder && m.favorites.Has(node.FullName)
+		favoritesChanged = favoritesChanged || node.Favorite
+	}
+	m.applyLocks(added)
+	m.applyLive(added)
+	m.applyMarks(added)
+	sortTree(folder, m.sort)
+
+	// The catalog is replaced rather than edited in place, as searches
+	// still running hold the old one.
+	catalog := make([]*JobTree, 0, len(m.searchCatalog)-len(stale)+len(added))
+	for _, node := range m.searchCatalog {
+		if !stale[node] {
+			catalog = append(catalog, node)
+		}
+	}
+	m.searchCatalog = append(catalog, added...)
+	m.totalSearchable = len(m.searchCatalog)
+	m.pruneRestore()
+
+	if favoritesChanged {
+		expanded := true
+		if folder := favoritesFolder(m.tree); folder != nil {
+			expanded = folder.Expanded
+		}
+		addFavorites(m.tree, m.favorites.List(), expanded)
+		sortTree(favoritesFolder(m.tree), m.sort)
+	}
+	m.applyFetching()
+
+	if m.isFiltering() {
+		m.applySearch(m.searchQuery)
+	} else {
+		m.refreshListItems()
+	}
+	m.reselect(selected, selectedPinned)
+}
+
+// folderNode returns the folder named fullName in the tree, or nil.
+func (m *Model) folderNode(fullName string) *JobTree {
+	for _, node := range m.searchCatalog {
+		if node.IsFolder && node.FullName == fullName {
+			return node
+		}
+	}
+	return nil
+}
+
+// loadFavoritesCmd fetches the folders holding favorites that are not in
+// the tree: GetAllJobs stops a few levels down, and the favorites below
+// would otherwise be missing from the Favorites folder until their folder
+// was opened. Each fetch brings favorites one folder closer, until they
+// are in the tree or turn out to be gone.
+func (m *Model) loadFavoritesCmd() tea.Cmd {
+	names := m.favorites.List()
+	if m.client == nil || len(names) == 0 {
+		return nil
+	}
+	byName := m.catalogByName()
+	var cmds []tea.Cmd
+	for _, name := range names {
+		if byName[name] != nil {
+			continue
+		}
+		// A folder whose children were fetched without the favorite no
+		// longer holds it, and fetchChildrenCmd leaves it be.
+		if folder := nearestFolder(name, byName); folder != nil {
+			cmds = append(cmds, m.fetchChildrenCmd(folder))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// catalogByName indexes the nodes of the tree by full name.
+func (m *Model) catalogByName() map[string]*JobTree {
+	byName := make(map[string]*JobTree, len(m.searchCatalog))
+	for _, node := range m.searchCatalog {
+		byName[node.FullName] = node
+	}
+	return byName
+}
+
+// nearestFolder returns the innermost folder of fullName found in byName,
+// or nil when none is in the tree.
+func nearestFolder(fullName string, byName map[string]*JobTree) *JobTree {
+	for i := strings.LastIndex(fullName, "/"); i > 0; i = strings.LastIndex(fullName[:i], "/") {
+		if node := byName[fullName[:i]]; node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// childrenLoaded reports whether every child of folder is in the tree.
+func (m *Model) childrenLoaded(folder *JobTree) bool {
+	_, loaded := m.loadedFolders[folder.FullName]
+	return loaded || !needsChildren(folder)
+}
+
+// reloadFoldersCmd fetches the loaded folders still expanded or holding
+// favorites again after the jobs were fetched, and forgets the others so
+// they load afresh when next expanded.
+func (m *Model) reloadFoldersCmd() tea.Cmd {
+	if m.client == nil || len(m.loadedFolders) == 0 {
+		return nil
+	}
+	expanded := expandedFolders(m.tree)
+	favorites := m.favorites.List()
+	var cmds []tea.Cmd
+	for fullName := range m.loadedFolders {
+		holdsFavorite := slices.ContainsFunc(favorites, func(name string) bool {
+			return strings.HasPrefix(name, fullName+"/")
+		})
+		if !expanded[fullName] && !holdsFavorite {
+			delete(m.loadedFolders, fullName)
+			continue
+		}
+		cmds = append(cmds, fetchFolderCmd(m.ctx, m.client, fullName))
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyFetching shows the spinner on the rows of folders being fetched.
+func (m *Model) applyFetching() {
+	m.fetchingNodes = nil
+	for _, node := range m.searchCatalog {
+		node.Fetching = ""
+		if m.fetching[node.FullName] {
+			m.fetchingNodes = append(m.fetchingNodes, node)
+		}
+	}
+	m.showFetchingFrame()
+}
+
+// showFetchingFrame moves the spinner on along the rows of the folders
+// being fetched. It runs on each spinner tick, so it leaves the rest of
+// the tree alone.
+func (m *Model) showFetchingFrame() {
+	frame := m.spinner.View()
+	for _, node := range m.fetchingNodes {
+		node.Fetching = frame
+	}
+}
+
+// graftFolders returns jobs with the children fetched for deep folders put
+// in place. Jobs are copied along the way, as the fetched list is shared
+// with other panels.
+func graftFolders(jobs []jenkins.Job, loaded map[string][]jenkins.Job) []jenkins.Job {
+	if len(loaded) == 0 {
+		return jobs
+	}
+	grafted := make([]jenkins.Job, len(jobs))
+	for i, job := range jobs {
+		if job.IsFolder() {
+			if children, ok := loaded[job.FullName]; ok && job.Jobs == nil {
+				job.Jobs = children
+			}
+			// Folders still without children keep a nil list, which
+			// needsChildren tells from an empty one.
+			if job.Jobs != nil {
+				job.Jobs = graftFolders(job.Jobs, loaded)
+			}
+		}
+		grafted[i] = job
+	}
+	return grafted
+}
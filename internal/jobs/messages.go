@@ -1,8 +1,15 @@
 package jobs
 
 import (
+	"context"
+	"fmt"
+
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/browser"
+	"github.com/gorbach/jdash/internal/bulk"
+	"github.com/gorbach/jdash/internal/favorites"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/statusbar"
 )
 
 // JobsFetchedMsg is sent when jobs have been successfully fetched from Jenkins
@@ -10,6 +17,12 @@ type JobsFetchedMsg struct {
 	Jobs []jenkins.Job
 }
 
+// JobsRenamedMsg reports jobs and folders that were renamed or moved since the
+// previous fetch, so state keyed by full name can follow them.
+type JobsRenamedMsg struct {
+	Renames []jenkins.JobRename
+}
+
 // JobsErrorMsg is sent when there's an error fetching jobs
 type JobsErrorMsg struct {
 	Err error
@@ -20,16 +33,53 @@ type JobSelectedMsg struct {
 	Job jenkins.Job
 }
 
+// JobActivatedMsg reports that Enter was pressed on a job. It follows the
+// JobSelectedMsg for the same job.
+type JobActivatedMsg struct {
+	Job jenkins.Job
+}
+
+// JobActionRequestedMsg asks for a details panel action (see
+// details.RunActionMsg) on Job. It follows the JobSelectedMsg for the same job.
+type JobActionRequestedMsg struct {
+	Job    jenkins.Job
+	Action string
+}
+
+// BuildPermissionMsg reports whether the current user lacks Build permission
+// on a job, once the details panel has checked.
+type BuildPermissionMsg struct {
+	JobFullName string
+	Locked      bool
+}
+
 // JobSelectionClearedMsg indicates that no job is currently selected.
 type JobSelectionClearedMsg struct{}
 
+// DependencyGraphRequestedMsg asks the app to show the dependency graph of a folder.
+type DependencyGraphRequestedMsg struct {
+	FolderFullName string
+}
+
+// ExportRequestedMsg asks the app to export the loaded jobs as CSV.
+type ExportRequestedMsg struct {
+	Jobs []jenkins.Job
+}
+
+// BulkActionRequestedMsg asks the app to confirm and run Action on the jobs
+// marked in visual-select mode.
+type BulkActionRequestedMsg struct {
+	Action bulk.Action
+	Jobs   []jenkins.Job
+}
+
 // RefreshRequestedMsg asks the jobs panel to refetch jobs from Jenkins.
 type RefreshRequestedMsg struct{}
 
 // fetchJobsCmd creates a command to fetch all jobs from Jenkins
-func fetchJobsCmd(client jenkins.JenkinsClient) tea.Cmd {
+func fetchJobsCmd(ctx context.Context, client jenkins.JenkinsClient) tea.Cmd {
 	return func() tea.Msg {
-		jobs, err := client.GetAllJobs()
+		jobs, err := client.GetAllJobs(ctx)
 		if err != nil {
 			return JobsErrorMsg{Err: err}
 		}
@@ -37,6 +87,13 @@ func fetchJobsCmd(client jenkins.JenkinsClient) tea.Cmd {
 	}
 }
 
+// jobsRenamedCmd returns a command that emits a JobsRenamedMsg.
+func jobsRenamedCmd(renames []jenkins.JobRename) tea.Cmd {
+	return func() tea.Msg {
+		return JobsRenamedMsg{Renames: renames}
+	}
+}
+
 // jobSelectedCmd returns a command that emits a JobSelectedMsg.
 func jobSelectedCmd(job jenkins.Job) tea.Cmd {
 	jobCopy := job
@@ -45,9 +102,61 @@ func jobSelectedCmd(job jenkins.Job) tea.Cmd {
 	}
 }
 
+// jobActivatedCmd selects the job and then reports it as activated, in that order.
+func jobActivatedCmd(job jenkins.Job) tea.Cmd {
+	jobCopy := job
+	return tea.Sequence(jobSelectedCmd(job), func() tea.Msg {
+		return JobActivatedMsg{Job: jobCopy}
+	})
+}
+
+// jobActionCmd selects the job and then requests action on it, in that order.
+func jobActionCmd(job jenkins.Job, action string) tea.Cmd {
+	jobCopy := job
+	return tea.Sequence(jobSelectedCmd(job), func() tea.Msg {
+		return JobActionRequestedMsg{Job: jobCopy, Action: action}
+	})
+}
+
 // jobSelectionClearedCmd returns a command that emits a JobSelectionClearedMsg.
 func jobSelectionClearedCmd() tea.Cmd {
 	return func() tea.Msg {
 		return JobSelectionClearedMsg{}
 	}
 }
+
+// dependencyGraphRequestedCmd returns a command that emits a DependencyGraphRequestedMsg.
+func dependencyGraphRequestedCmd(folderFullName string) tea.Cmd {
+	return func() tea.Msg {
+		return DependencyGraphRequestedMsg{FolderFullName: folderFullName}
+	}
+}
+
+// openInBrowserCmd opens the Jenkins page of job, reporting the outcome in
+// the status bar.
+func openInBrowserCmd(job jenkins.Job) tea.Cmd {
+	return func() tea.Msg {
+		if err := browser.Open(job.URL); err != nil {
+			return statusbar.FeedbackMsg{Text: fmt.Sprintf("✗ Failed to open %s: %v", job.FullName, err), IsError: true}
+		}
+		return statusbar.FeedbackMsg{Text: fmt.Sprintf("✓ Opened %s in the browser", job.FullName)}
+	}
+}
+
+// saveFavoritesCmd persists the favorites, reporting a failure in the status
+// bar; the change then lasts until restart.
+func saveFavoritesCmd(store *favorites.Store) tea.Cmd {
+	return func() tea.Msg {
+		if err := store.Save(); err != nil {
+			return statusbar.FeedbackMsg{Text: fmt.Sprintf("✗ Favorites not saved: %v", err), IsError: true}
+		}
+		return nil
+	}
+}
+
+// exportRequestedCmd returns a command that emits an ExportRequestedMsg.
+func exportRequestedCmd(jobs []jenkins.Job) tea.Cmd {
+	return func() tea.Msg {
+		return ExportRequestedMsg{Jobs: jobs}
+	}
+}
@@ -1,37 +1,84 @@
 package jobs
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/bulk"
+	"github.com/gorbach/jdash/internal/favorites"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/ticket"
+	"github.com/gorbach/jdash/internal/treestate"
 	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
 )
 
 // Model represents the jobs list panel
 type Model struct {
-	client               jenkins.JenkinsClient
-	tree                 *JobTree
-	allJobs              []jenkins.Job
-	list                 list.Model
-	loading              bool
-	spinner              spinner.Model
-	err                  error
-	width                int
-	height               int
-	searchMode           bool
-	searchQuery          string
-	searchInput          textinput.Model
-	searchResults        []*JobTree
-	searchCatalog        []*JobTree
-	searchTicket         uint64
+	client        jenkins.JenkinsClient
+	ctx           context.Context
+	tree          *JobTree
+	allJobs       []jenkins.Job
+	list          list.Model
+	loading       bool
+	spinner       spinner.Model
+	err           error
+	width         int
+	height        int
+	searchMode    bool
+	searchQuery   string
+	searchInput   textinput.Model
+	searchResults []*JobTree
+	searchCatalog []*JobTree
+	searchTicket  ticket.Counter
+	// searchMatcher is toggled with ctrl+r; searchErr explains why a regex
+	// matches nothing.
+	searchMatcher        searchMatcher
+	searchErr            error
 	totalSearchable      int
 	preSearchSelection   string
 	lastSelectedFullName string
+	// exclusiveExpand collapses a folder's siblings when it is expanded.
+	exclusiveExpand bool
+	// locked holds the jobs the user lacks Build permission on, as reported by
+	// the details panel, so they keep their lock across refreshes.
+	locked map[string]bool
+	// queued and running hold the latest queue poll by job URL, so rows show
+	// jobs waiting or building between jobs fetches.
+	queued  map[string]int
+	running map[string][]jenkins.RunningBuild
+	// favorites are the jobs listed in the Favorites folder.
+	favorites *favorites.Store
+	// sort orders the entries of each folder.
+	sort sortMode
+	// statusFilter prunes the tree to jobs with the given statuses, set from
+	// the filter bar F opens while filterBar is set.
+	statusFilter statusFilter
+	filterBar    bool
+	// loadedFolders holds the children of folders deeper than GetAllJobs
+	// returns, fetched when they were first expanded or found to hold
+	// favorites, and fetching the folders still waiting for theirs, whose
+	// nodes are fetchingNodes.
+	loadedFolders map[string][]jenkins.Job
+	fetching      map[string]bool
+	fetchingNodes []*JobTree
+	// treeState saves the expanded folders and selection for the next start.
+	// restoreExpanded and restoreSelection are what it saved last time, until
+	// they are in the tree; moving the cursor drops the saved selection.
+	treeState        *treestate.Store
+	restoreExpanded  map[string]bool
+	restoreSelection string
+	// selecting is set in visual-select mode, which v toggles, and marked
+	// holds the full names of the jobs marked in it for bulk actions.
+	selecting bool
+	marked    map[string]bool
 }
 
 // New creates a new jobs panel model
@@ -43,12 +90,11 @@ func New(client jenkins.JenkinsClient) Model {
 	// Create empty list with custom delegate
 	delegate := newJobDelegate()
 	l := list.New([]list.Item{}, delegate, 0, 0)
-	l.Title = "Jobs"
+	l.SetShowTitle(false)
 	l.SetShowStatusBar(false)
 	l.SetShowHelp(false)
 	l.SetFilteringEnabled(false)
 	l.SetShowPagination(false)
-	l.Styles.Title = ui.TitleStyle
 
 	input := textinput.New()
 	input.Placeholder = "Search jobs..."
@@ -61,6 +107,7 @@ func New(client jenkins.JenkinsClient) Model {
 
 	return Model{
 		client:      client,
+		ctx:         context.Background(),
 		list:        l,
 		loading:     true,
 		spinner:     s,
@@ -68,6 +115,28 @@ func New(client jenkins.JenkinsClient) Model {
 	}
 }
 
+// WithExclusiveExpand returns the model collapsing the sibling folders of any
+// folder that is expanded, which keeps the tree shallow on instances with many
+// large top-level folders.
+func (m Model) WithExclusiveExpand(enabled bool) Model {
+	m.exclusiveExpand = enabled
+	return m
+}
+
+// WithContext returns the model fetching jobs with ctx, so the fetches
+// still running stop when it is cancelled.
+func (m Model) WithContext(ctx context.Context) Model {
+	m.ctx = ctx
+	return m
+}
+
+// WithFavorites returns the model listing the store's jobs in a Favorites
+// folder at the top of the tree, with f adding and removing them.
+func (m Model) WithFavorites(store *favorites.Store) Model {
+	m.favorites = store
+	return m
+}
+
 // Init initializes the model and starts fetching jobs
 func (m Model) Init() tea.Cmd {
 	if m.client == nil {
@@ -75,7 +144,7 @@ func (m Model) Init() tea.Cmd {
 	}
 	return tea.Batch(
 		m.spinner.Tick,
-		fetchJobsCmd(m.client),
+		fetchJobsCmd(m.ctx, m.client),
 	)
 }
 
@@ -93,13 +162,41 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case JobsFetchedMsg:
 		m.loading = false
 		m.err = nil
-		m.allJobs = msg.Jobs
-		m.tree = buildTree(msg.Jobs)
-		clearMatchHighlights(m.tree)
-		m.searchCatalog = collectAllNodes(m.tree)
-		m.totalSearchable = len(m.searchCatalog)
+		var renames []jenkins.JobRename
+		if m.allJobs != nil {
+			renames = jenkins.DetectRenames(m.allJobs, msg.Jobs)
+		}
+		renamedFavorite := false
+		for _, rename := range renames {
+			renamedFavorite = m.favorites.Rename(rename.From, rename.To) || renamedFavorite
+		}
+		if renamedFavorite {
+			cmds = append(cmds, saveFavoritesCmd(m.favorites))
+		}
+		m.applyJobs(msg.Jobs, renames)
+		if len(renames) > 0 {
+			cmds = append(cmds, jobsRenamedCmd(renames))
+		}
+		cmds = append(cmds, m.reloadFoldersCmd(), m.loadExpandedCmd(m.tree), m.loadFavoritesCmd())
+		return finalizeJobsModel(m, cmds)
+
+	case folderFetchedMsg:
+		cmds = append(cmds, m.applyFolderFetched(msg))
+		return finalizeJobsModel(m, cmds)
+
+	case BuildPermissionMsg:
+		if m.locked == nil {
+			m.locked = make(map[string]bool)
+		}
+		m.locked[msg.JobFullName] = msg.Locked
+		m.applyLocks(m.statusNodes())
+		m.refreshListItems()
+		return finalizeJobsModel(m, cmds)
+
+	case queue.SnapshotMsg:
+		m.queued, m.running = liveStatus(msg)
+		m.applyLive(m.statusNodes())
 		m.refreshListItems()
-		m.lastSelectedFullName = ""
 		return finalizeJobsModel(m, cmds)
 
 	case JobsErrorMsg:
@@ -111,22 +208,27 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return finalizeJobsModel(m, cmds)
 
 	case spinner.TickMsg:
-		if m.loading {
+		if m.loading || len(m.fetching) > 0 {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			if cmd != nil {
 				cmds = append(cmds, cmd)
 			}
+			m.showFetchingFrame()
 		}
 		return finalizeJobsModel(m, cmds)
 
 	case searchQueuedMsg:
-		if msg.Ticket != m.searchTicket {
+		if !m.searchTicket.IsCurrent(msg.Ticket) {
 			return finalizeJobsModel(m, cmds)
 		}
 		m.applySearch(msg.Query)
 		return finalizeJobsModel(m, cmds)
 
+	case bulk.ClosedMsg:
+		cmds = append(cmds, m.applyBulkDone(msg))
+		return finalizeJobsModel(m, cmds)
+
 	case RefreshRequestedMsg:
 		if m.client == nil {
 			return finalizeJobsModel(m, cmds)
@@ -134,10 +236,11 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.loading = true
 		m.err = nil
 		cmds = append(cmds, m.spinner.Tick)
-		cmds = append(cmds, fetchJobsCmd(m.client))
+		cmds = append(cmds, fetchJobsCmd(m.ctx, m.client))
 		return finalizeJobsModel(m, cmds)
 
 	case tea.KeyMsg:
+		m.restoreSelection = ""
 		var cmd tea.Cmd
 		m, cmd = m.handleKeyMsg(msg)
 		if cmd != nil {
@@ -159,6 +262,10 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if m.handleFilterKey(msg) {
+		return m, nil
+	}
+
 	if handled, cmd := m.handleSearchKey(msg); handled {
 		return m, cmd
 	}
@@ -198,6 +305,17 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 	}
 	currentNode := nodes[index]
 
+	if handled, cmd := m.handleSelectKey(msg, currentNode); handled {
+		return m, cmd
+	}
+
+	if msg.String() == "f" {
+		if m.favorites != nil && !currentNode.IsFolder && currentNode.Job != nil {
+			cmds = append(cmds, m.toggleFavorite(currentNode, index))
+		}
+		return m, tea.Batch(cmds...)
+	}
+
 	if m.isFiltering() {
 		switch msg.String() {
 		case "j", "down":
@@ -220,11 +338,11 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 			return m, tea.Batch(cmds...)
 		case "enter":
 			// Commit the selection and reveal it in the tree.
-			expandPathToNode(currentNode)
+			m.revealPath(currentNode)
 			m.exitSearchMode(false)
 			m.selectByFullName(currentNode.FullName)
 			if !currentNode.IsFolder && currentNode.Job != nil {
-				cmds = append(cmds, jobSelectedCmd(*currentNode.Job))
+				cmds = append(cmds, jobActivatedCmd(*currentNode.Job))
 			}
 			return m, tea.Batch(cmds...)
 		}
@@ -246,26 +364,58 @@ func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
 
 		case "l", "right":
 			if currentNode.IsFolder && !currentNode.Expanded {
-				expandNode(currentNode)
+				m.expandFolder(currentNode)
 				m.refreshListItems()
 				m.selectByFullName(currentNode.FullName)
+				cmds = append(cmds, m.loadChildrenCmd(currentNode))
 			}
 			return m, tea.Batch(cmds...)
 
 		case " ":
 			if currentNode.IsFolder {
 				toggleExpand(currentNode)
+				if currentNode.Expanded && m.exclusiveExpand {
+					collapseSiblings(currentNode)
+				}
 				m.refreshListItems()
 				m.selectByFullName(currentNode.FullName)
+				cmds = append(cmds, m.loadChildrenCmd(currentNode))
 			}
 			return m, tea.Batch(cmds...)
 
 		case "enter":
 			if !currentNode.IsFolder && currentNode.Job != nil {
-				cmds = append(cmds, jobSelectedCmd(*currentNode.Job))
+				cmds = append(cmds, jobActivatedCmd(*currentNode.Job))
+			}
+			return m, tea.Batch(cmds...)
+
+		case "d":
+			if !currentNode.IsFolder && currentNode.Job != nil {
+				cmds = append(cmds, jobActionCmd(*currentNode.Job, "toggleEnabled"))
 			}
 			return m, tea.Batch(cmds...)
 
+		case "D":
+			cmds = append(cmds, dependencyGraphRequestedCmd(dependencyFolderFor(currentNode)))
+			return m, tea.Batch(cmds...)
+
+		case "E":
+			cmds = append(cmds, exportRequestedCmd(m.allJobs))
+			return m, tea.Batch(cmds...)
+
+		case "o":
+			if currentNode.Job != nil {
+				cmds = append(cmds, openInBrowserCmd(*currentNode.Job))
+			}
+			return m, tea.Batch(cmds...)
+
+		case "s":
+			// Rebuilding restores Jenkins' order for the name mode and keeps
+			// the expanded folders and the selection.
+			m.sort = m.sort.next()
+			m.applyJobs(m.allJobs, nil)
+			return m, tea.Batch(cmds...)
+
 		case "j", "down":
 			m.moveCursor(1, nodes)
 			return m, tea.Batch(cmds...)
@@ -333,6 +483,11 @@ func (m *Model) handleSearchKey(msg tea.KeyMsg) (bool, tea.Cmd) {
 	}
 
 	switch msg.String() {
+	case "ctrl+r":
+		m.searchMatcher = m.searchMatcher.next()
+		m.searchTicket.Invalidate()
+		m.applySearch(m.searchInput.Value())
+		return true, nil
 	case "enter":
 		if m.searchInput.Focused() {
 			m.searchInput.Blur()
@@ -354,7 +509,7 @@ func (m *Model) handleSearchKey(msg tea.KeyMsg) (bool, tea.Cmd) {
 }
 
 func (m *Model) exitSearchMode(restorePrevious bool) {
-	m.searchTicket++
+	m.searchTicket.Invalidate()
 	m.searchMode = false
 	m.searchInput.Blur()
 	m.searchInput.SetValue("")
@@ -368,8 +523,7 @@ func (m *Model) exitSearchMode(restorePrevious bool) {
 
 func (m *Model) scheduleSearch(raw string) tea.Cmd {
 	normalized := strings.TrimSpace(raw)
-	m.searchTicket++
-	ticket := m.searchTicket
+	ticket := m.searchTicket.Next()
 
 	if normalized == "" {
 		m.applySearch("")
@@ -383,13 +537,15 @@ func (m *Model) applySearch(query string) {
 	clearMatchHighlights(m.tree)
 	m.searchQuery = strings.TrimSpace(query)
 	m.searchResults = nil
+	m.searchErr = nil
 
 	if m.searchQuery == "" {
 		m.refreshListItems()
 		return
 	}
 
-	matches := runFuzzySearch(m.searchQuery, m.searchCatalog)
+	matches, err := runSearch(m.searchMatcher, m.searchQuery, m.searchCatalog)
+	m.searchErr = err
 	if len(matches) == 0 {
 		m.refreshListItems()
 		return
@@ -406,6 +562,160 @@ func (m *Model) applySearch(query string) {
 	m.list.Select(0)
 }
 
+// applyJobs rebuilds the tree from a fresh job list. On refresh the expanded
+// folders, active search and cursor are carried over by full name, following
+// renames detected since the previous fetch, so the selection (and the list
+// page it sits on) stays put and no new JobSelectedMsg is emitted while the
+// same job remains selected.
+func (m *Model) applyJobs(jobs []jenkins.Job, renames []jenkins.JobRename) {
+	selected, selectedPinned := "", false
+	var expanded map[string]bool
+	favoritesExpanded := true
+	if m.tree != nil {
+		if node := m.currentSelectionNode(); node != nil {
+			selected, selectedPinned = node.FullName, node.Pinned
+		}
+		expanded = expandedFolders(m.tree)
+		if folder := favoritesFolder(m.tree); folder != nil {
+			favoritesExpanded = folder.Expanded
+		}
+	}
+	for _, rename := range renames {
+		if selected == rename.From {
+			selected = rename.To
+		}
+		if m.preSearchSelection == rename.From {
+			m.preSearchSelection = rename.To
+		}
+		if expanded[rename.From] {
+			expanded[rename.To] = true
+		}
+	}
+
+	m.allJobs = jobs
+	m.tree = buildTree(graftFolders(jobs, m.loadedFolders))
+	restoreExpanded(m.tree, expanded)
+	m.applyRestore(m.tree)
+	addFavorites(m.tree, m.favorites.List(), favoritesExpanded)
+	m.searchCatalog = collectAllNodes(m.tree)
+	nodes := m.statusNodes()
+	m.applyLocks(nodes)
+	m.applyLive(nodes)
+	m.applyMarks(nodes)
+	m.applyFetching()
+	sortTree(m.tree, m.sort)
+	m.totalSearchable = len(m.searchCatalog)
+	m.pruneRestore()
+
+	if m.isFiltering() {
+		m.applySearch(m.searchQuery)
+	} else {
+		clearMatchHighlights(m.tree)
+		m.refreshListItems()
+	}
+
+	m.reselect(selected, selectedPinned)
+}
+
+// reselect puts the cursor back on the node named selected once the tree
+// changed, or on the job saved for the next start while it has not loaded.
+func (m *Model) reselect(selected string, pinned bool) {
+	if m.restoreSelection != "" {
+		selected, pinned = m.restoreSelection, false
+	}
+	if selected == "" {
+		return
+	}
+	for idx, node := range m.currentNodes() {
+		if node.FullName == selected && node.Pinned == pinned {
+			m.list.Select(idx)
+			if selected == m.restoreSelection {
+				m.restoreSelection = ""
+			}
+			return
+		}
+	}
+}
+
+// toggleFavorite adds the job of node, shown at index, to the favorites or
+// removes it, and rebuilds the Favorites folder with the cursor kept on the
+// job, or on the same row once an entry left the folder. The returned
+// command saves the change.
+func (m *Model) toggleFavorite(node *JobTree, index int) tea.Cmd {
+	m.favorites.Toggle(node.FullName)
+	expanded := true
+	if folder := favoritesFolder(m.tree); folder != nil {
+		expanded = folder.Expanded
+	}
+	addFavorites(m.tree, m.favorites.List(), expanded)
+	nodes := m.statusNodes()
+	m.applyLocks(nodes)
+	m.applyLive(nodes)
+	sortTree(favoritesFolder(m.tree), m.sort)
+	m.refreshListItems()
+
+	if node.Pinned {
+		if count := len(m.currentNodes()); index >= count {
+			index = count - 1
+		}
+		m.list.Select(index)
+	} else {
+		m.selectNode(node)
+	}
+	return saveFavoritesCmd(m.favorites)
+}
+
+// statusNodes returns the nodes showing a job's status: the jobs in the
+// tree and their entries in the Favorites folder.
+func (m *Model) statusNodes() []*JobTree {
+	folder := favoritesFolder(m.tree)
+	if folder == nil {
+		return m.searchCatalog
+	}
+	return append(slices.Clone(m.searchCatalog), folder.Children...)
+}
+
+// applyLocks marks the nodes of jobs the user may not build among nodes.
+func (m *Model) applyLocks(nodes []*JobTree) {
+	for _, node := range nodes {
+		node.Locked = m.locked[node.FullName]
+	}
+}
+
+// applyLive marks the nodes of jobs that are queued or building among
+// nodes.
+func (m *Model) applyLive(nodes []*JobTree) {
+	for _, node := range nodes {
+		node.Queued, node.Running, node.RunningCount = 0, nil, 0
+		if node.Job == nil || node.Job.URL == "" {
+			continue
+		}
+		key := liveKey(node.Job.URL)
+		node.Queued = m.queued[key]
+		if builds := m.running[key]; len(builds) > 0 {
+			node.Running = &builds[0]
+			node.RunningCount = len(builds)
+		}
+	}
+}
+
+// liveStatus indexes a queue poll by job URL. The running builds of jobs that
+// run concurrently are listed newest first.
+func liveStatus(snapshot queue.SnapshotMsg) (map[string]int, map[string][]jenkins.RunningBuild) {
+	queued := make(map[string]int)
+	for _, item := range snapshot.Queued {
+		if item.Task.URL != "" {
+			queued[liveKey(item.Task.URL)]++
+		}
+	}
+	return queued, jenkins.RunningByJob(snapshot.Running)
+}
+
+// liveKey normalizes a job URL for matching queue and executor entries.
+func liveKey(jobURL string) string {
+	return strings.TrimSuffix(jobURL, "/")
+}
+
 func (m Model) isFiltering() bool {
 	return m.searchQuery != ""
 }
@@ -414,6 +724,15 @@ func (m Model) currentNodes() []*JobTree {
 	if m.isFiltering() {
 		return m.searchResults
 	}
+	return m.visibleNodes()
+}
+
+// visibleNodes returns the rows of the tree: the nodes inside expanded
+// folders, pruned to the status filter when one is set.
+func (m Model) visibleNodes() []*JobTree {
+	if m.statusFilter != 0 {
+		return flattenFilteredNodes(m.tree, m.statusFilter)
+	}
 	return flattenVisibleNodes(m.tree)
 }
 
@@ -440,6 +759,12 @@ func (m *Model) updateListDimensions() {
 	if m.shouldShowSearchBar() && height > 0 {
 		height--
 	}
+	if m.filterBar && height > 0 {
+		height--
+	}
+	if m.selecting && height > 0 {
+		height--
+	}
 	if height < 0 {
 		height = 0
 	}
@@ -452,7 +777,7 @@ func (m *Model) refreshListItems() {
 	if m.isFiltering() {
 		nodes = m.searchResults
 	} else if m.tree != nil {
-		nodes = flattenVisibleNodes(m.tree)
+		nodes = m.visibleNodes()
 	}
 
 	if len(nodes) == 0 {
@@ -462,7 +787,7 @@ func (m *Model) refreshListItems() {
 
 	items := make([]list.Item, len(nodes))
 	for i, node := range nodes {
-		items[i] = *node
+		items[i] = node
 	}
 	m.list.SetItems(items)
 
@@ -487,9 +812,10 @@ func (m *Model) selectByFullName(fullName string) {
 		return
 	}
 
-	nodes := flattenVisibleNodes(m.tree)
+	nodes := m.visibleNodes()
 	for idx, node := range nodes {
-		if node.FullName == fullName {
+		// Favorites entries share the full name of the job they stand for.
+		if node.FullName == fullName && !(node.Pinned && !node.IsFolder) {
 			m.list.Select(idx)
 			return
 		}
@@ -500,13 +826,64 @@ func (m *Model) selectByFullName(fullName string) {
 	}
 }
 
+// SelectJob moves the cursor to the job with the given full name, leaving any
+// search or status filter hiding it and expanding its folders so it is visible. It returns the job, or
+// nil when the loaded tree has no such job.
+func (m Model) SelectJob(fullName string) (Model, *jenkins.Job, tea.Cmd) {
+	var target *JobTree
+	for _, node := range collectAllNodes(m.tree) {
+		if node.FullName == fullName {
+			target = node
+			break
+		}
+	}
+	if target == nil || target.Job == nil {
+		return m, nil, nil
+	}
+	m.restoreSelection = ""
+
+	if m.searchMode || m.isFiltering() {
+		m.exitSearchMode(false)
+	}
+	if m.statusFilter != 0 && !m.statusFilter.matches(target) {
+		m.statusFilter = 0
+	}
+	m.revealPath(target.Parent)
+	m.refreshListItems()
+	m.selectNode(target)
+
+	m, cmd := finalizeJobsModel(m, nil)
+	job := *target.Job
+	return m, &job, cmd
+}
+
+// expandFolder expands node, collapsing its siblings in exclusive mode.
+func (m *Model) expandFolder(node *JobTree) {
+	expandNode(node)
+	if m.exclusiveExpand {
+		collapseSiblings(node)
+	}
+}
+
+// revealPath expands node and its ancestors; in exclusive mode every folder
+// off that path is collapsed.
+func (m *Model) revealPath(node *JobTree) {
+	expandPathToNode(node)
+	if !m.exclusiveExpand {
+		return
+	}
+	for current := node; current != nil; current = current.Parent {
+		collapseSiblings(current)
+	}
+}
+
 // selectNode selects the given node if it is currently visible.
 func (m *Model) selectNode(target *JobTree) {
 	if m.isFiltering() || target == nil || m.tree == nil {
 		return
 	}
 
-	nodes := flattenVisibleNodes(m.tree)
+	nodes := m.visibleNodes()
 	for idx, node := range nodes {
 		if node == target {
 			m.list.Select(idx)
@@ -612,6 +989,43 @@ func (m *Model) pageMove(direction int, nodes []*JobTree) {
 	m.list.Select(idx)
 }
 
+// FailingCount returns how many jobs' last build failed.
+func (m Model) FailingCount() int {
+	count := 0
+	for _, node := range collectAllNodes(m.tree) {
+		if node.Job != nil && !node.IsFolder && node.Job.GetStatus() == jenkins.StatusFailed {
+			count++
+		}
+	}
+	return count
+}
+
+// TitleBar describes the jobs panel header.
+func (m Model) TitleBar() ui.TitleBar {
+	bar := ui.TitleBar{Name: "Jobs"}
+	if m.loading {
+		bar.Spinner = m.spinner.View()
+		return bar
+	}
+	if m.tree == nil {
+		return bar
+	}
+
+	total := utils.FormatCount(int64(getTotalJobCount(m.tree)))
+	bar.Count = total
+	if m.isFiltering() {
+		bar.Count = fmt.Sprintf("%s/%s", utils.FormatCount(int64(len(m.searchResults))), total)
+		bar.Chips = append(bar.Chips, "search: "+m.searchQuery)
+	} else if m.statusFilter != 0 {
+		bar.Count = fmt.Sprintf("%s/%s", utils.FormatCount(int64(m.statusFilterMatches())), total)
+	}
+	if m.statusFilter != 0 {
+		bar.Chips = append(bar.Chips, "filter: "+m.statusFilter.String())
+	}
+	bar.Chips = append(bar.Chips, "sort: "+m.sort.String())
+	return bar
+}
+
 // View renders the jobs panel
 func (m Model) View() string {
 	if m.loading {
@@ -631,13 +1045,18 @@ func (m Model) View() string {
 		return title + help
 	}
 
-	// Update title with job count
-	totalJobs := getTotalJobCount(m.tree)
-	m.list.Title = fmt.Sprintf("Jobs (%d)", totalJobs)
-
 	content := m.list.View()
 	if m.isFiltering() && len(m.searchResults) == 0 {
 		content = ui.SubtleStyle.Render("No matches found")
+	} else if !m.isFiltering() && m.statusFilter != 0 && len(m.list.Items()) == 0 {
+		content = ui.SubtleStyle.Render("No jobs match the filter")
+	}
+
+	if m.filterBar {
+		content = strings.TrimRight(content, "\n") + "\n" + m.filterBarView()
+	}
+	if m.selecting {
+		content = strings.TrimRight(content, "\n") + "\n" + m.selectBarView()
 	}
 
 	if m.shouldShowSearchBar() {
@@ -646,7 +1065,11 @@ func (m Model) View() string {
 			matchCount = len(m.searchResults)
 		}
 		status := ui.SubtleStyle.Render(fmt.Sprintf("%d/%d matches", matchCount, m.totalSearchable))
-		searchLine := fmt.Sprintf("%s  %s", m.searchInput.View(), status)
+		if m.searchErr != nil {
+			status = ui.ErrorStyle.Render(m.searchErr.Error())
+		}
+		mode := ui.HighlightStyle.Render("["+m.searchMatcher.String()+"]") + ui.SubtleStyle.Render(" ^R")
+		searchLine := fmt.Sprintf("%s  %s  %s", m.searchInput.View(), mode, status)
 		content = strings.TrimRight(content, "\n")
 		content = content + "\n" + searchLine
 	}
@@ -0,0 +1,639 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/bulk"
+	"github.com/gorbach/jdash/internal/favorites"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/statusbar"
+	"github.com/gorbach/jdash/internal/treestate"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+const jenkinsURL = "https://ci.example.com"
+
+func fetched() JobsFetchedMsg {
+	return JobsFetchedMsg{Jobs: []jenkins.Job{
+		{Name: "api", FullName: "api", URL: jenkinsURL + "/job/api/", Color: "blue",
+			LastBuild: &jenkins.Build{Number: 7, Result: "SUCCESS"}},
+		{Name: "web", FullName: "web", URL: jenkinsURL + "/job/web/", Color: "red",
+			LastBuild: &jenkins.Build{Number: 3, Result: "FAILURE"}},
+	}}
+}
+
+// row renders the visible row of a job.
+func row(t *testing.T, m Model, fullName string) string {
+	t.Helper()
+	for i, item := range m.list.Items() {
+		if item.(*JobTree).FullName == fullName {
+			var b bytes.Buffer
+			newJobDelegate().Render(&b, m.list, i, item)
+			return b.String()
+		}
+	}
+	t.Fatalf("%s is not listed", fullName)
+	return ""
+}
+
+func TestQueueSnapshotMarksJobs(t *testing.T) {
+	m := New(nil)
+	m, _ = m.Update(fetched())
+
+	snapshot := queue.SnapshotMsg{
+		Running: []jenkins.RunningBuild{
+			{JobName: "api #8", BuildNumber: 8, URL: jenkinsURL + "/job/api/8/", StartTime: time.Now().Add(-90 * time.Second).UnixMilli()},
+		},
+	}
+	item := jenkins.QueueItem{ID: 1}
+	item.Task.URL = jenkinsURL + "/job/web/"
+	snapshot.Queued = []jenkins.QueueItem{item, item}
+	m, _ = m.Update(snapshot)
+
+	// The last jobs fetch still has api #7 finished; the poll shows #8 running.
+	api := row(t, m, "api")
+	for _, want := range []string{"[BUILDING]", "#8", "1m 30s"} {
+		if !strings.Contains(api, want) {
+			t.Errorf("api row %q does not show %q", api, want)
+		}
+	}
+	if web := row(t, m, "web"); !strings.Contains(web, "queued ×2") || !strings.Contains(web, "[FAILED]") {
+		t.Errorf("web row %q, want its last result and a queued badge", web)
+	}
+
+	// Concurrent builds are counted, with the newest shown. A Pipeline build
+	// on two executors counts once.
+	concurrent := snapshot
+	concurrent.Running = append([]jenkins.RunningBuild{
+		{JobName: "api #9", BuildNumber: 9, URL: jenkinsURL + "/job/api/9/", StartTime: time.Now().UnixMilli()},
+		{JobName: "api #9", BuildNumber: 9, URL: jenkinsURL + "/job/api/9/", StartTime: time.Now().UnixMilli(), Node: "linux"},
+	}, snapshot.Running...)
+	m, _ = m.Update(concurrent)
+	if api := row(t, m, "api"); !strings.Contains(api, "#9 ×2") {
+		t.Errorf("api row %q, want #9 and two builds running", api)
+	}
+	m, _ = m.Update(snapshot)
+
+	// The marks survive a jobs refresh and clear once the queue empties.
+	m, _ = m.Update(fetched())
+	if !strings.Contains(row(t, m, "api"), "#8") {
+		t.Error("running build lost on the jobs refresh")
+	}
+	m, _ = m.Update(queue.SnapshotMsg{})
+	if api := row(t, m, "api"); strings.Contains(api, "BUILDING") || strings.Contains(row(t, m, "web"), "queued") {
+		t.Errorf("marks kept after the queue emptied: %q", api)
+	}
+}
+
+func TestRowShowsJobWeather(t *testing.T) {
+	msg := fetched()
+	msg.Jobs[1].HealthReport = []jenkins.HealthReport{{Score: 20, Description: "Build stability: 4 out of the last 5 builds failed."}}
+	m := New(nil)
+	m, _ = m.Update(msg)
+
+	if web := row(t, m, "web"); !strings.Contains(web, "⚡") {
+		t.Errorf("web row %q does not show its weather", web)
+	}
+	if api := row(t, m, "api"); strings.ContainsAny(api, "🌞⛅🌂☔⚡") {
+		t.Errorf("api row %q shows weather without a health report", api)
+	}
+}
+
+func TestRowsMarkLockedJobsAndDisabledFolders(t *testing.T) {
+	msg := fetched()
+	msg.Jobs = append(msg.Jobs, jenkins.Job{Name: "mono", FullName: "mono", Disabled: true,
+		Class: "org.jenkinsci.plugins.workflow.multibranch.WorkflowMultiBranchProject"})
+	m, _ := New(nil).Update(msg)
+	m, _ = m.Update(BuildPermissionMsg{JobFullName: "web", Locked: true})
+
+	if web := row(t, m, "web"); !strings.Contains(web, ui.IconLocked) {
+		t.Errorf("web row %q does not show the lock", web)
+	}
+	if api := row(t, m, "api"); strings.Contains(api, ui.IconLocked) {
+		t.Errorf("api row %q shows a lock without a permission check", api)
+	}
+	if mono := row(t, m, "mono"); !strings.Contains(mono, "[DISABLED]") {
+		t.Errorf("mono row %q does not show that it is disabled", mono)
+	}
+
+	// The lock lifts once the check says otherwise.
+	m, _ = m.Update(BuildPermissionMsg{JobFullName: "web", Locked: false})
+	if web := row(t, m, "web"); strings.Contains(web, ui.IconLocked) {
+		t.Errorf("web row %q keeps the lock", web)
+	}
+}
+
+func TestFavoritesFolder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favorites.json")
+	store, err := favorites.LoadFrom(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(nil).WithFavorites(store)
+	m, _ = m.Update(fetched())
+
+	// Mark web, the second row, as a favorite.
+	m.list.Select(1)
+	m, save := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if !store.Has("web") {
+		t.Fatal("f did not add the job to the favorites")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("favorites written in Update")
+	}
+	if save == nil {
+		t.Fatal("f did not save the favorites")
+	}
+	messages(save)
+	if reloaded, _ := favorites.LoadFrom(path); !reloaded.Has("web") {
+		t.Error("the saved favorites lack web")
+	}
+	nodes := m.currentNodes()
+	if len(nodes) != 4 || nodes[0].FullName != favoritesFullName || nodes[1].FullName != "web" || !nodes[1].Pinned {
+		t.Fatalf("rows = %v, want the Favorites folder listing web first", fullNames(nodes))
+	}
+	if got := m.currentSelectionFullName(); got != "web" || m.currentSelectionNode().Pinned {
+		t.Errorf("cursor on %q in the favorites: %v, want it kept on the job", got, m.currentSelectionNode().Pinned)
+	}
+	if title := m.TitleBar(); title.Count != "2" {
+		t.Errorf("job count = %q, want favorites not counted twice", title.Count)
+	}
+
+	// The folder survives a refresh; removing the entry drops the folder.
+	m, _ = m.Update(fetched())
+	m.list.Select(1)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if store.Has("web") {
+		t.Fatal("f on the favorites entry did not remove it")
+	}
+	if nodes := m.currentNodes(); len(nodes) != 2 {
+		t.Errorf("rows = %v, want the Favorites folder gone", fullNames(nodes))
+	}
+}
+
+func TestFavoriteSaveFailureIsReported(t *testing.T) {
+	// A file where the config directory should be makes saving fail.
+	dir := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, _ := favorites.LoadFrom(filepath.Join(dir, "favorites.json"))
+	m := New(nil).WithFavorites(store)
+	m, _ = m.Update(fetched())
+
+	_, save := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	var feedback statusbar.FeedbackMsg
+	for _, msg := range messages(save) {
+		if msg, ok := msg.(statusbar.FeedbackMsg); ok {
+			feedback = msg
+		}
+	}
+	if !feedback.IsError || !strings.Contains(feedback.Text, "Favorites not saved") {
+		t.Errorf("save failure reported as %+v, want an error in the status bar", feedback)
+	}
+	if !store.Has("api") {
+		t.Error("the favorite is not kept for this session")
+	}
+}
+
+// messages runs cmd and the commands of a batch it returns.
+func messages(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, cmd := range batch {
+		msgs = append(msgs, messages(cmd)...)
+	}
+	return msgs
+}
+
+func fullNames(nodes []*JobTree) []string {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.FullName
+	}
+	return names
+}
+
+func TestSortModesCycle(t *testing.T) {
+	msg := JobsFetchedMsg{Jobs: []jenkins.Job{
+		{Name: "api", FullName: "api", Color: "blue",
+			LastBuild: &jenkins.Build{Number: 7, Result: "SUCCESS", Timestamp: 3000, Duration: 50}},
+		{Name: "docs", FullName: "docs", Color: "yellow",
+			LastBuild: &jenkins.Build{Number: 2, Result: "UNSTABLE", Timestamp: 1000, Duration: 900}},
+		{Name: "tools", FullName: "tools", Class: "com.cloudbees.hudson.plugins.folder.Folder"},
+		{Name: "web", FullName: "web", Color: "red",
+			LastBuild: &jenkins.Build{Number: 3, Result: "FAILURE", Timestamp: 2000, Duration: 10}},
+	}}
+	m, _ := New(nil).Update(msg)
+	m.list.Select(3)
+
+	for _, tc := range []struct {
+		mode string
+		want string
+	}{
+		{"last build", "tools api web docs"},
+		{"duration", "tools docs api web"},
+		{"status", "tools web docs api"},
+		{"name", "api docs tools web"},
+	} {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		if got := strings.Join(fullNames(m.currentNodes()), " "); got != tc.want {
+			t.Errorf("sorted by %s: rows = %s, want %s", tc.mode, got, tc.want)
+		}
+		if chips := m.TitleBar().Chips; len(chips) == 0 || chips[len(chips)-1] != "sort: "+tc.mode {
+			t.Errorf("title chips = %v, want sort: %s", chips, tc.mode)
+		}
+		if got := m.currentSelectionFullName(); got != "web" {
+			t.Errorf("sorted by %s: cursor on %q, want it kept on web", tc.mode, got)
+		}
+	}
+}
+
+func TestStatusFilterKeepsFolders(t *testing.T) {
+	msg := JobsFetchedMsg{Jobs: []jenkins.Job{
+		{Name: "api", FullName: "api", Color: "blue", LastBuild: &jenkins.Build{Number: 7, Result: "SUCCESS"}},
+		{Name: "team", FullName: "team", Class: "com.cloudbees.hudson.plugins.folder.Folder", Jobs: []jenkins.Job{
+			{Name: "web", FullName: "team/web", Color: "red", LastBuild: &jenkins.Build{Number: 3, Result: "FAILURE"}},
+			{Name: "docs", FullName: "team/docs", Color: "disabled", LastBuild: &jenkins.Build{Number: 1, Result: "SUCCESS"}},
+		}},
+		{Name: "old", FullName: "old", Class: "com.cloudbees.hudson.plugins.folder.Folder", Jobs: []jenkins.Job{
+			{Name: "cron", FullName: "old/cron", Color: "blue", LastBuild: &jenkins.Build{Number: 9, Result: "SUCCESS"}},
+		}},
+	}}
+	m, _ := New(nil).Update(msg)
+	key := func(k string) {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+	}
+
+	key("F")
+	key("f")
+	if got := strings.Join(fullNames(m.currentNodes()), " "); got != "team team/web" {
+		t.Errorf("failed filter rows = %s, want the failing job in its folder", got)
+	}
+	if title := m.TitleBar(); title.Count != "1/4" || title.Chips[0] != "filter: failed" {
+		t.Errorf("title = %+v, want 1/4 jobs and the filter chip", title)
+	}
+	if !strings.Contains(m.View(), "[x] f failed") {
+		t.Errorf("filter bar does not show failed checked:\n%s", m.View())
+	}
+
+	key("d")
+	if got := strings.Join(fullNames(m.currentNodes()), " "); got != "team team/web team/docs" {
+		t.Errorf("failed or disabled rows = %s", got)
+	}
+
+	// Closing the bar keeps the filter; clearing it shows every job again.
+	key("F")
+	if strings.Contains(m.View(), "x clear") || m.statusFilter == 0 {
+		t.Error("closing the bar dropped the filter or kept the bar")
+	}
+	key("F")
+	key("x")
+	if got := len(m.currentNodes()); got != 5 {
+		t.Errorf("rows after clearing = %v, want all 5", fullNames(m.currentNodes()))
+	}
+}
+
+func TestExpandDeepFolderFetchesChildren(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetFolderJobsFunc: func(_ context.Context, folder string) ([]jenkins.Job, error) {
+			return []jenkins.Job{
+				{Name: "api", FullName: folder + "/api", Color: "blue", LastBuild: &jenkins.Build{Number: 4, Result: "SUCCESS"}},
+			}, nil
+		},
+	}
+	folder := "com.cloudbees.hudson.plugins.folder.Folder"
+	msg := JobsFetchedMsg{Jobs: []jenkins.Job{
+		// GetAllJobs leaves out the jobs of "deep", past the levels it fetches.
+		{Name: "deep", FullName: "deep", Class: folder},
+		{Name: "empty", FullName: "empty", Class: folder, Jobs: []jenkins.Job{}},
+	}}
+	m, _ := New(client).Update(msg)
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if cmd == nil {
+		t.Fatal("expanding the folder did not fetch its children")
+	}
+	if !strings.Contains(row(t, m, "deep"), "loading") {
+		t.Errorf("row does not show the folder loading: %q", row(t, m, "deep"))
+	}
+	for _, msg := range cmd().(tea.BatchMsg) {
+		if fetched, ok := msg().(folderFetchedMsg); ok {
+			m, _ = m.Update(fetched)
+		}
+	}
+	if got := strings.Join(fullNames(m.currentNodes()), " "); got != "deep deep/api empty" {
+		t.Errorf("rows = %s, want the fetched job under its folder", got)
+	}
+	if strings.Contains(row(t, m, "deep"), "loading") {
+		t.Error("row still shows the folder loading")
+	}
+
+	// Folders GetAllJobs reached empty are not fetched again.
+	m.list.Select(2)
+	if _, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")}); cmd != nil {
+		t.Error("expanding an empty folder fetched it")
+	}
+
+	// A jobs refresh keeps the children and fetches them again.
+	m, cmd = m.Update(msg)
+	if got := strings.Join(fullNames(m.currentNodes()), " "); got != "deep deep/api empty" {
+		t.Errorf("rows after refresh = %s", got)
+	}
+	if cmd == nil {
+		t.Error("refresh did not fetch the expanded folder again")
+	}
+	if calls := client.CallsTo("GetFolderJobs"); len(calls) != 1 {
+		t.Errorf("GetFolderJobs called %d times before the refresh ran, want once", len(calls))
+	}
+}
+
+func TestFavoritesInDeepFoldersAreFetched(t *testing.T) {
+	store, err := favorites.LoadFrom(filepath.Join(t.TempDir(), "favorites.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Add("team/deep/api", "team/deep/gone")
+	folder := "com.cloudbees.hudson.plugins.folder.Folder"
+	client := &jenkinstest.Client{
+		GetFolderJobsFunc: func(_ context.Context, name string) ([]jenkins.Job, error) {
+			if name == "team" {
+				return []jenkins.Job{{Name: "deep", FullName: "team/deep", Class: folder}}, nil
+			}
+			return []jenkins.Job{{Name: "api", FullName: "team/deep/api", Color: "blue"}}, nil
+		},
+	}
+	// GetAllJobs leaves out the jobs of "team", past the levels it fetches.
+	m, cmd := New(client).WithFavorites(store).Update(JobsFetchedMsg{Jobs: []jenkins.Job{
+		{Name: "api", FullName: "api", Color: "blue"},
+		{Name: "team", FullName: "team", Class: folder},
+	}})
+	for cmd != nil {
+		var cmds []tea.Cmd
+		for _, msg := range messages(cmd) {
+			if fetched, ok := msg.(folderFetchedMsg); ok {
+				var next tea.Cmd
+				m, next = m.Update(fetched)
+				cmds = append(cmds, next)
+			}
+		}
+		cmd = tea.Batch(cmds...)
+	}
+
+	if got := strings.Join(fullNames(m.currentNodes()), " "); got != favoritesFullName+" team/deep/api api team" {
+		t.Errorf("rows = %q, want the favorite listed with its folders still closed", got)
+	}
+	if got := m.currentSelectionFullName(); got != "api" {
+		t.Errorf("cursor on %q, want it kept on api", got)
+	}
+	// Each folder on the way is fetched once; the one without "gone" ends it.
+	if calls := client.CallsTo("GetFolderJobs"); len(calls) != 2 {
+		t.Errorf("GetFolderJobs called %d times, want once per folder", len(calls))
+	}
+
+	// A refresh keeps the favorite while its folders are fetched again.
+	m, _ = m.Update(JobsFetchedMsg{Jobs: []jenkins.Job{
+		{Name: "api", FullName: "api", Color: "blue"},
+		{Name: "team", FullName: "team", Class: folder},
+	}})
+	if nodes := m.currentNodes(); len(nodes) != 4 || nodes[1].FullName != "team/deep/api" {
+		t.Errorf("rows after refresh = %v, want the favorite kept", fullNames(nodes))
+	}
+}
+
+func TestTreeStateRestoredAndSaved(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.json")
+	store, _ := treestate.LoadFrom(path)
+	saved := treestate.State{Expanded: []string{"gone", "team", "team/deep/inner", "team/gone"}, Selected: "team/web"}
+	if err := store.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	folder := "com.cloudbees.hudson.plugins.folder.Folder"
+	msg := JobsFetchedMsg{Jobs: []jenkins.Job{
+		{Name: "api", FullName: "api", Color: "blue"},
+		{Name: "team", FullName: "team", Class: folder, Jobs: []jenkins.Job{
+			// GetAllJobs leaves out the jobs of "deep", past the levels it
+			// fetches.
+			{Name: "deep", FullName: "team/deep", Class: folder},
+			{Name: "docs", FullName: "team/docs", Color: "blue"},
+			{Name: "web", FullName: "team/web", Color: "red"},
+		}},
+	}}
+
+	m, _ := New(nil).WithTreeState(store).Update(msg)
+	if got := strings.Join(fullNames(m.currentNodes()), " "); got != "api team team/deep team/docs team/web" {
+		t.Errorf("rows = %s, want team expanded", got)
+	}
+	if got := m.currentSelectionFullName(); got != "team/web" {
+		t.Errorf("cursor on %q, want the saved selection", got)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	save := m.SaveTreeState()
+	if reloaded, _ := treestate.LoadFrom(path); reloaded.State().Selected != saved.Selected {
+		t.Fatal("tree state written before the save command ran")
+	}
+	save()
+	reloaded, _ := treestate.LoadFrom(path)
+	state := reloaded.State()
+	// The folders missing from the loaded tree are forgotten, while the one
+	// inside "deep", whose jobs were not fetched, stays saved for then.
+	if got := strings.Join(state.Expanded, " "); got != "team team/deep/inner" || state.Selected != "team/docs" {
+		t.Errorf("saved %+v, want team and team/deep/inner expanded with team/docs selected", state)
+	}
+}
+
+func TestVisualSelectRequestsBulkActions(t *testing.T) {
+	store, err := favorites.LoadFrom(filepath.Join(t.TempDir(), "favorites.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := New(nil).WithFavorites(store)
+	m, _ = m.Update(fetched())
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	if !strings.Contains(row(t, m, "api"), ui.IconMarked) || !strings.Contains(row(t, m, "web"), ui.IconMarked) {
+		t.Fatal("v and Space did not mark both jobs")
+	}
+	if !strings.Contains(m.View(), "VISUAL 2 marked") {
+		t.Errorf("view lacks the visual-select bar:\n%s", m.View())
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	if cmd == nil {
+		t.Fatal("d did not request a bulk action")
+	}
+	req, ok := cmd().(BulkActionRequestedMsg)
+	if !ok || req.Action != bulk.ActionDisable || len(req.Jobs) != 2 || req.Jobs[0].FullName != "api" || req.Jobs[1].FullName != "web" {
+		t.Fatalf("requested %+v, want disabling api and web", req)
+	}
+
+	m, save := m.Update(bulk.ClosedMsg{Action: bulk.ActionFavorite, Confirmed: true, Done: []string{"api", "web"}})
+	if !store.Has("api") || !store.Has("web") {
+		t.Error("confirmed favorites were not added")
+	}
+	if save == nil {
+		t.Error("the added favorites are not saved")
+	}
+	if strings.Contains(m.View(), "VISUAL") || strings.Contains(row(t, m, "web"), ui.IconMarked) {
+		t.Error("visual select still on after the bulk action")
+	}
+}
+
+func TestSearchModesToggle(t *testing.T) {
+	m := New(nil)
+	m, _ = m.Update(JobsFetchedMsg{Jobs: []jenkins.Job{
+		{Name: "api-v2", FullName: "api-v2", Color: "blue"},
+		{Name: "api-gateway-v2", FullName: "api-gateway-v2", Color: "blue"},
+		{Name: "web", FullName: "web", Color: "blue"},
+	}})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m.searchInput.SetValue("api-v2")
+	m.applySearch("api-v2")
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{mode: "fuzzy", want: "api-v2 api-gateway-v2"},
+		{mode: "substring", want: "api-v2"},
+		{mode: "regex", want: "api-v2"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(fullNames(m.currentNodes()), " "); got != tt.want {
+			t.Errorf("%s results = %s, want %s", tt.mode, got, tt.want)
+		}
+		if !strings.Contains(m.View(), "["+tt.mode+"]") {
+			t.Errorf("search bar does not show the %s mode:\n%s", tt.mode, m.View())
+		}
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	}
+
+	// Back to fuzzy; a regex that does not compile says so.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m.searchInput.SetValue("^(api")
+	m.applySearch("^(api")
+	if !strings.Contains(m.View(), "invalid regex: missing closing )") {
+		t.Errorf("search bar does not show the regex error:\n%s", m.View())
+	}
+	m.applySearch("^api-.*v2$")
+	if got := strings.Join(fullNames(m.currentNodes()), " "); got != "api-v2 api-gateway-v2" {
+		t.Errorf("regex results = %s", got)
+	}
+}
+
+func TestExclusiveExpandCollapsesSiblingFolders(t *testing.T) {
+	folder := "com.cloudbees.hudson.plugins.folder.Folder"
+	msg := JobsFetchedMsg{Jobs: []jenkins.Job{
+		{Name: "apps", FullName: "apps", Class: folder, Jobs: []jenkins.Job{
+			{Name: "backend", FullName: "apps/backend", Class: folder, Jobs: []jenkins.Job{
+				{Name: "api", FullName: "apps/backend/api", Color: "blue"},
+			}},
+			{Name: "frontend", FullName: "apps/frontend", Class: folder, Jobs: []jenkins.Job{
+				{Name: "web", FullName: "apps/frontend/web", Color: "blue"},
+			}},
+		}},
+		{Name: "infra", FullName: "infra", Class: folder, Jobs: []jenkins.Job{
+			{Name: "terraform", FullName: "infra/terraform", Color: "blue"},
+		}},
+	}}
+	m, _ := New(nil).WithExclusiveExpand(true).Update(msg)
+	visible := func() string { return strings.Join(fullNames(m.currentNodes()), " ") }
+
+	// Jumping to a job opens its path and closes every folder off it.
+	m, _, _ = m.SelectJob("apps/frontend/web")
+	m, _, _ = m.SelectJob("apps/backend/api")
+	if got, want := visible(), "apps apps/backend apps/backend/api apps/frontend infra"; got != want {
+		t.Errorf("rows after jumping = %s, want %s", got, want)
+	}
+
+	// Expanding a folder by hand closes its siblings only.
+	m.selectByFullName("infra")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if got, want := visible(), "apps infra infra/terraform"; got != want {
+		t.Errorf("rows after expanding infra = %s, want %s", got, want)
+	}
+	m.selectByFullName("apps")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" ")})
+	if got, want := visible(), "apps apps/backend apps/frontend infra"; got != want {
+		t.Errorf("rows after toggling apps = %s, want %s", got, want)
+	}
+}
+
+func TestRefreshKeepsCursorFoldersAndSearch(t *testing.T) {
+	folder := "com.cloudbees.hudson.plugins.folder.Folder"
+	jobs := []jenkins.Job{
+		{Name: "team", FullName: "team", Class: folder, Jobs: []jenkins.Job{
+			{Name: "api", FullName: "team/api", Color: "blue"},
+			{Name: "web", FullName: "team/web", Color: "blue"},
+		}},
+		{Name: "docs", FullName: "docs", Color: "blue"},
+	}
+	m, _ := New(nil).Update(JobsFetchedMsg{Jobs: jobs})
+	m, _, _ = m.SelectJob("team/web")
+
+	// A new job sorting first shifts every row down by one.
+	jobs = append([]jenkins.Job{{Name: "alpha", FullName: "alpha", Color: "blue"}}, jobs...)
+	refresh := func() []tea.Msg {
+		t.Helper()
+		var cmd tea.Cmd
+		m, cmd = m.Update(JobsFetchedMsg{Jobs: jobs})
+		return messages(cmd)
+	}
+	reselected := func(msgs []tea.Msg) bool {
+		for _, msg := range msgs {
+			if _, ok := msg.(JobSelectedMsg); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	msgs := refresh()
+	if got := m.currentSelectionFullName(); got != "team/web" {
+		t.Errorf("selection after a refresh = %q, want team/web", got)
+	}
+	if got, want := strings.Join(fullNames(m.currentNodes()), " "), "alpha team team/api team/web docs"; got != want {
+		t.Errorf("rows after a refresh = %s, want %s", got, want)
+	}
+	if reselected(msgs) {
+		t.Error("a refresh that kept the selection sent JobSelectedMsg")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m.searchInput.SetValue("web")
+	m.applySearch("web")
+	m.selectByFullName("team/web")
+	msgs = refresh()
+	if m.searchQuery != "web" {
+		t.Errorf("search after a refresh = %q, want web", m.searchQuery)
+	}
+	if got := strings.Join(fullNames(m.currentNodes()), " "); got != "team/web" {
+		t.Errorf("search results after a refresh = %s, want team/web", got)
+	}
+	if got := m.currentSelectionFullName(); got != "team/web" {
+		t.Errorf("selection in the search after a refresh = %q, want team/web", got)
+	}
+	if reselected(msgs) {
+		t.Error("a refresh during a search sent JobSelectedMsg")
+	}
+}
@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/treestate"
+)
+
+// WithTreeState returns the model opening the folders and selecting the job
+// saved in store once jobs load, and saving them back with SaveTreeState.
+func (m Model) WithTreeState(store *treestate.Store) Model {
+	m.treeState = store
+	saved := store.State()
+	m.restoreExpanded = make(map[string]bool, len(saved.Expanded))
+	for _, fullName := range saved.Expanded {
+		m.restoreExpanded[fullName] = true
+	}
+	m.restoreSelection = saved.Selected
+	return m
+}
+
+// SaveTreeState returns a command saving the expanded folders and the
+// selected job for the next start; nil before jobs first loaded.
+func (m Model) SaveTreeState() tea.Cmd {
+	if m.treeState == nil || m.tree == nil {
+		return nil
+	}
+	state := treestate.State{Selected: m.restoreSelection}
+	if state.Selected == "" {
+		state.Selected = m.currentSelectionFullName()
+		if m.isFiltering() {
+			state.Selected = m.preSearchSelection
+		}
+	}
+	if state.Selected == favoritesFullName {
+		state.Selected = ""
+	}
+	for fullName := range expandedFolders(m.tree) {
+		if fullName != favoritesFullName {
+			state.Expanded = append(state.Expanded, fullName)
+		}
+	}
+	// Folders below ones not loaded yet stay saved.
+	for fullName := range m.restoreExpanded {
+		state.Expanded = append(state.Expanded, fullName)
+	}
+	sort.Strings(state.Expanded)
+	store := m.treeState
+	return func() tea.Msg {
+		// Best effort, like the other local state files.
+		_ = store.Save(state)
+		return nil
+	}
+}
+
+// applyRestore expands the saved folders that are in root. Folders nested
+// in ones not loaded yet wait for them.
+func (m *Model) applyRestore(root *JobTree) {
+	if len(m.restoreExpanded) == 0 {
+		return
+	}
+	for _, node := range collectAllNodes(root) {
+		if node.IsFolder && !node.Pinned && m.restoreExpanded[node.FullName] {
+			node.Expanded = true
+			delete(m.restoreExpanded, node.FullName)
+		}
+	}
+}
+
+// pruneRestore forgets the saved folders that can no longer show up: those
+// already in the tree as jobs, and those whose nearest folder in the tree
+// has its children loaded without them or which have no folder there at
+// all. The others wait for their folders to be fetched.
+func (m *Model) pruneRestore() {
+	if len(m.restoreExpanded) == 0 {
+		return
+	}
+	byName := m.catalogByName()
+	for fullName := range m.restoreExpanded {
+		if byName[fullName] != nil {
+			delete(m.restoreExpanded, fullName)
+			continue
+		}
+		if folder := nearestFolder(fullName, byName); folder == nil || m.childrenLoaded(folder) {
+			delete(m.restoreExpanded, fullName)
+		}
+	}
+}
+
+// loadExpandedCmd fetches the children of the expanded folders in root
+// still without them, as after restoring the tree.
+func (m *Model) loadExpandedCmd(root *JobTree) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, node := range collectAllNodes(root) {
+		cmds = append(cmds, m.loadChildrenCmd(node))
+	}
+	return tea.Batch(cmds...)
+}
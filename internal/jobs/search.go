@@ -1,8 +1,15 @@
 package jobs
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/sahilm/fuzzy"
@@ -10,6 +17,38 @@
 
 const searchDebounceInterval = 120 * time.Millisecond
 
+// searchMatcher is how the / search matches the full names of jobs.
+type searchMatcher int
+
+const (
+	// matchFuzzy finds the letters of the query in order, best matches first.
+	matchFuzzy searchMatcher = iota
+	// matchSubstring finds the query as typed, ignoring case, in tree order;
+	// "api-v2" then leaves out "api-gateway/v2".
+	matchSubstring
+	// matchRegex matches a case-insensitive regular expression, in tree order.
+	matchRegex
+
+	searchMatcherCount
+)
+
+// String names the matcher for the search bar.
+func (s searchMatcher) String() string {
+	switch s {
+	case matchSubstring:
+		return "substring"
+	case matchRegex:
+		return "regex"
+	default:
+		return "fuzzy"
+	}
+}
+
+// next returns the matcher the toggle key cycles to.
+func (s searchMatcher) next() searchMatcher {
+	return (s + 1) % searchMatcherCount
+}
+
 type searchQueuedMsg struct {
 	Query  string
 	Ticket uint64
@@ -28,6 +67,7 @@ func debounceSearchCmd(query string, ticket uint64) tea.Cmd {
 type matchResult struct {
 	node    *JobTree
 	indexes []int
+	score   int // fuzzy score, higher first; unused by the other matchers
 }
 
 type jobNodeSource struct {
@@ -51,22 +91,84 @@ func (s jobNodeSource) String(i int) string {
 	return s.lower[i]
 }
 
+// runSearch matches query against nodes with matcher. It fails only on a
+// regular expression that does not compile.
+func runSearch(matcher searchMatcher, query string, nodes []*JobTree) ([]matchResult, error) {
+	switch matcher {
+	case matchSubstring:
+		return runSubstringSearch(query, nodes), nil
+	case matchRegex:
+		pattern, err := regexp.Compile("(?i)" + query)
+		if err != nil {
+			// The syntax error quotes the expression with the flag prepended.
+			var syntaxErr *syntax.Error
+			if errors.As(err, &syntaxErr) {
+				return nil, fmt.Errorf("invalid regex: %s", syntaxErr.Code)
+			}
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		return runRegexSearch(pattern, nodes), nil
+	default:
+		return runFuzzySearch(query, nodes), nil
+	}
+}
+
+func runSubstringSearch(query string, nodes []*JobTree) []matchResult {
+	if query == "" {
+		return nil
+	}
+	query = strings.ToLower(query)
+	var results []matchResult
+	for _, node := range nodes {
+		name := strings.ToLower(node.FullName)
+		if start := strings.Index(name, query); start >= 0 {
+			results = append(results, matchResult{node: node, indexes: runeIndexes(name, start, start+len(query))})
+		}
+	}
+	return results
+}
+
+func runRegexSearch(pattern *regexp.Regexp, nodes []*JobTree) []matchResult {
+	var results []matchResult
+	for _, node := range nodes {
+		if loc := pattern.FindStringIndex(node.FullName); loc != nil {
+			results = append(results, matchResult{node: node, indexes: runeIndexes(node.FullName, loc[0], loc[1])})
+		}
+	}
+	return results
+}
+
+// runeIndexes returns the rune indexes of the bytes start to end of s, which
+// is how matches are highlighted.
+func runeIndexes(s string, start, end int) []int {
+	first := utf8.RuneCountInString(s[:start])
+	indexes := make([]int, utf8.RuneCountInString(s[start:end]))
+	for i := range indexes {
+		indexes[i] = first + i
+	}
+	return indexes
+}
+
 func runFuzzySearch(query string, nodes []*JobTree) []matchResult {
 	if query == "" || len(nodes) == 0 {
 		return nil
 	}
 
 	source := newJobNodeSource(nodes)
-	matches := fuzzy.FindFrom(strings.ToLower(query), source)
+	// fuzzy.FindFrom's stable sort swaps whole matches through an interface
+	// and dominates broad queries on large instances. The matches come in
+	// tree order, so a stable sort of the smaller results by score ranks
+	// them the same for a fraction of the cost.
+	matches := fuzzy.FindFromNoSort(strings.ToLower(query), source)
 
 	results := make([]matchResult, len(matches))
 	for i, match := range matches {
-		node := source.nodes[match.Index]
-		results[i] = matchResult{
-			node:    node,
-			indexes: append([]int(nil), match.MatchedIndexes...),
-		}
+		// Every match owns its MatchedIndexes, so they need no copy.
+		results[i] = matchResult{node: source.nodes[match.Index], indexes: match.MatchedIndexes, score: match.Score}
 	}
+	slices.SortStableFunc(results, func(a, b matchResult) int {
+		return cmp.Compare(b.score, a.score)
+	})
 
 	return results
 }
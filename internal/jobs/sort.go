@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+// sortMode orders the entries of each folder in the tree.
+type sortMode int
+
+const (
+	// sortByName keeps the order Jenkins lists entries in, which is by name.
+	sortByName sortMode = iota
+	// sortByLastBuild lists the most recently started builds first.
+	sortByLastBuild
+	// sortByDuration lists the longest last builds first.
+	sortByDuration
+	// sortBySeverity lists failed jobs first, then unstable, aborted,
+	// building, passing, never built and disabled ones.
+	sortBySeverity
+
+	sortModeCount
+)
+
+// String names the mode for the panel title.
+func (s sortMode) String() string {
+	switch s {
+	case sortByLastBuild:
+		return "last build"
+	case sortByDuration:
+		return "duration"
+	case sortBySeverity:
+		return "status"
+	default:
+		return "name"
+	}
+}
+
+// next returns the mode the sort key cycles to.
+func (s sortMode) next() sortMode {
+	return (s + 1) % sortModeCount
+}
+
+// severityRank orders statuses from the most to the least alarming.
+var severityRank = map[string]int{
+	jenkins.StatusFailed:     0,
+	jenkins.StatusUnstable:   1,
+	jenkins.StatusAborted:    2,
+	jenkins.StatusBuilding:   3,
+	jenkins.StatusSuccess:    4,
+	jenkins.StatusPending:    5,
+	jenkins.StatusUnknown:    5,
+	jenkins.StatusNotBuilt:   6,
+	jenkins.StatusNeverBuilt: 6,
+	jenkins.StatusDisabled:   7,
+}
+
+// sortTree orders the entries of every folder below tree by mode. Folders
+// stay above jobs in name order, and the Favorites folder stays first.
+func sortTree(tree *JobTree, mode sortMode) {
+	if tree == nil || mode == sortByName {
+		return
+	}
+	sort.SliceStable(tree.Children, func(i, j int) bool {
+		return lessNode(tree.Children[i], tree.Children[j], mode)
+	})
+	for _, child := range tree.Children {
+		if child.IsFolder {
+			sortTree(child, mode)
+		}
+	}
+}
+
+func lessNode(a, b *JobTree, mode sortMode) bool {
+	if a.Pinned != b.Pinned {
+		return a.Pinned
+	}
+	if a.IsFolder != b.IsFolder {
+		return a.IsFolder
+	}
+	if a.IsFolder {
+		return lessName(a, b)
+	}
+
+	switch mode {
+	case sortByLastBuild:
+		if ta, tb := lastBuildTime(a), lastBuildTime(b); ta != tb {
+			return ta > tb
+		}
+	case sortByDuration:
+		if da, db := lastDuration(a), lastDuration(b); da != db {
+			return da > db
+		}
+	case sortBySeverity:
+		if ra, rb := severity(a), severity(b); ra != rb {
+			return ra < rb
+		}
+		if ta, tb := lastBuildTime(a), lastBuildTime(b); ta != tb {
+			return ta > tb
+		}
+	}
+	return lessName(a, b)
+}
+
+// lessName compares names case-insensitively; entries in the Favorites
+// folder are compared by full name, which they show.
+func lessName(a, b *JobTree) bool {
+	na, nb := a.Name, b.Name
+	if a.Pinned && b.Pinned {
+		na, nb = a.FullName, b.FullName
+	}
+	return strings.ToLower(na) < strings.ToLower(nb)
+}
+
+// lastBuildTime is when the job's newest build started, in Unix
+// milliseconds, or 0 for jobs never built.
+func lastBuildTime(node *JobTree) int64 {
+	if node.Running != nil {
+		return node.Running.StartTime
+	}
+	if node.Job == nil || node.Job.LastBuild == nil {
+		return 0
+	}
+	return node.Job.LastBuild.Timestamp
+}
+
+// lastDuration is how long the job's last build took in milliseconds, or
+// has taken so far while it runs.
+func lastDuration(node *JobTree) int64 {
+	if node.Running != nil {
+		return node.Running.GetElapsedTime().Milliseconds()
+	}
+	if node.Job == nil || node.Job.LastBuild == nil {
+		return 0
+	}
+	return node.Job.LastBuild.Duration
+}
+
+func severity(node *JobTree) int {
+	if node.Job == nil {
+		return severityRank[jenkins.StatusUnknown]
+	}
+	if rank, ok := severityRank[node.Job.GetStatus()]; ok {
+		return rank
+	}
+	return severityRank[jenkins.StatusUnknown]
+}
@@ -18,8 +18,30 @@ type JobTree struct {
 	Parent       *JobTree     // Parent reference (nil for root)
 	MatchIndexes []int        // Rune indexes of fuzzy match for highlighting
 	SearchResult bool         // True when node is part of current search results
+	Locked       bool         // True when the user lacks Build permission on the job
+	Queued       int          // Queue items waiting for the job, from the latest queue poll
+	// Running is the job's newest running build from the latest queue poll,
+	// which is fresher than Job.LastBuild from the jobs fetch.
+	Running *jenkins.RunningBuild
+	// RunningCount is how many builds of the job that poll saw running; more
+	// than one for jobs allowing concurrent builds.
+	RunningCount int
+	// Favorite is set on the jobs the user marked as favorites.
+	Favorite bool
+	// Pinned is set on the Favorites folder and the job entries listed in it,
+	// which stand in for jobs elsewhere in the tree.
+	Pinned bool
+	// Marked is set on the jobs marked in visual-select mode.
+	Marked bool
+	// Fetching is the spinner frame shown on a folder while its children are
+	// fetched, empty otherwise.
+	Fetching string
 }
 
+// favoritesFullName is the full name of the Favorites folder. Jenkins names
+// never contain a NUL, so it can't clash with a real folder.
+const favoritesFullName = "\x00favorites"
+
 // FilterValue implements list.Item interface for bubbles/list filtering
 func (j JobTree) FilterValue() string {
 	return j.FullName
@@ -73,19 +95,24 @@ func flattenVisibleNodes(tree *JobTree) []*JobTree {
 		return []*JobTree{}
 	}
 
+	// Append into one slice rather than joining a slice per subtree, which
+	// allocated once per folder on large instances.
 	result := []*JobTree{}
+	var walk func(node *JobTree)
+	walk = func(node *JobTree) {
+		// Don't include root node in the result
+		if node.Level >= 0 {
+			result = append(result, node)
+		}
 
-	// Don't include root node in the result
-	if tree.Level >= 0 {
-		result = append(result, tree)
-	}
-
-	// If expanded (or is root), include children
-	if tree.Expanded || tree.Level < 0 {
-		for _, child := range tree.Children {
-			result = append(result, flattenVisibleNodes(child)...)
+		// If expanded (or is root), include children
+		if node.Expanded || node.Level < 0 {
+			for _, child := range node.Children {
+				walk(child)
+			}
 		}
 	}
+	walk(tree)
 
 	return result
 }
@@ -150,6 +177,64 @@ func collapseAll(tree *JobTree) {
 	}
 }
 
+// addFavorites lists the named jobs in a Favorites folder at the top of the
+// tree, replacing the one there, and marks them where they live. Favorites
+// missing from the tree are skipped, and without any the folder is left out.
+func addFavorites(tree *JobTree, names []string, expanded bool) {
+	if tree == nil {
+		return
+	}
+	if favoritesFolder(tree) != nil {
+		tree.Children = tree.Children[1:]
+	}
+
+	favorite := make(map[string]bool, len(names))
+	for _, name := range names {
+		favorite[name] = true
+	}
+	byName := make(map[string]*JobTree, len(names))
+	for _, node := range collectAllNodes(tree) {
+		node.Favorite = !node.IsFolder && favorite[node.FullName]
+		if node.Favorite {
+			byName[node.FullName] = node
+		}
+	}
+
+	folder := &JobTree{
+		Name:     "Favorites",
+		FullName: favoritesFullName,
+		IsFolder: true,
+		Expanded: expanded,
+		Pinned:   true,
+		Parent:   tree,
+	}
+	for _, name := range names {
+		node, ok := byName[name]
+		if !ok {
+			continue
+		}
+		entry := *node
+		entry.Children = nil
+		entry.Level = 1
+		entry.Parent = folder
+		entry.Pinned = true
+		entry.MatchIndexes, entry.SearchResult = nil, false
+		folder.Children = append(folder.Children, &entry)
+	}
+	if len(folder.Children) == 0 {
+		return
+	}
+	tree.Children = append([]*JobTree{folder}, tree.Children...)
+}
+
+// favoritesFolder returns the tree's Favorites folder, or nil.
+func favoritesFolder(tree *JobTree) *JobTree {
+	if tree == nil || len(tree.Children) == 0 || !tree.Children[0].Pinned {
+		return nil
+	}
+	return tree.Children[0]
+}
+
 // getIndentation returns the indentation string for a node based on its level
 func getIndentation(level int) string {
 	if level <= 0 {
@@ -169,6 +254,10 @@ func getTotalJobCount(tree *JobTree) int {
 		return 0
 	}
 
+	if tree.Pinned {
+		return 0
+	}
+
 	count := 0
 	if !tree.IsFolder && tree.Level >= 0 {
 		count = 1
@@ -181,7 +270,8 @@ func getTotalJobCount(tree *JobTree) int {
 	return count
 }
 
-// collectAllNodes returns all tree nodes excluding the synthetic root.
+// collectAllNodes returns all tree nodes excluding the synthetic root and the
+// Favorites folder.
 func collectAllNodes(tree *JobTree) []*JobTree {
 	if tree == nil {
 		return nil
@@ -191,6 +281,9 @@ func collectAllNodes(tree *JobTree) []*JobTree {
 
 	var walk func(node *JobTree)
 	walk = func(node *JobTree) {
+		if node.Pinned {
+			return
+		}
 		if node.Level >= 0 {
 			nodes = append(nodes, node)
 		}
@@ -216,6 +309,19 @@ func clearMatchHighlights(tree *JobTree) {
 	}
 }
 
+// collapseSiblings collapses the other folders next to node, and everything
+// inside them, so only node's branch stays open at its level.
+func collapseSiblings(node *JobTree) {
+	if node == nil || node.Parent == nil || node.Pinned {
+		return
+	}
+	for _, sibling := range node.Parent.Children {
+		if sibling != node && sibling.IsFolder && !sibling.Pinned {
+			collapseAll(sibling)
+		}
+	}
+}
+
 // expandPathToNode ensures all ancestors of the node are expanded so the node is visible.
 func expandPathToNode(node *JobTree) {
 	for current := node; current != nil; current = current.Parent {
@@ -224,3 +330,46 @@ func expandPathToNode(node *JobTree) {
 		}
 	}
 }
+
+// dependencyFolderFor returns the folder whose dependency graph is relevant for node:
+// the node itself when it is a folder, otherwise its parent ("" at the top level).
+func dependencyFolderFor(node *JobTree) string {
+	if node == nil {
+		return ""
+	}
+	if node.Pinned {
+		// The Favorites folder is no folder in Jenkins; its entries stand
+		// for jobs in theirs.
+		if i := strings.LastIndex(node.FullName, "/"); i >= 0 && !node.IsFolder {
+			return node.FullName[:i]
+		}
+		return ""
+	}
+	if node.IsFolder {
+		return node.FullName
+	}
+	if node.Parent != nil && node.Parent.Level >= 0 {
+		return node.Parent.FullName
+	}
+	return ""
+}
+
+// expandedFolders returns the full names of all expanded folders in the tree.
+func expandedFolders(tree *JobTree) map[string]bool {
+	expanded := make(map[string]bool)
+	for _, node := range collectAllNodes(tree) {
+		if node.IsFolder && node.Expanded {
+			expanded[node.FullName] = true
+		}
+	}
+	return expanded
+}
+
+// restoreExpanded re-applies a set of expanded folder names to a freshly built tree.
+func restoreExpanded(tree *JobTree, expanded map[string]bool) {
+	for _, node := range collectAllNodes(tree) {
+		if node.IsFolder && expanded[node.FullName] {
+			node.Expanded = true
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package jsonstore reads and writes the small JSON files jdash keeps local
+// state in, such as notes, bookmarks and favorites, in the config directory.
+package jsonstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// backupSuffix is appended to the name of a file that could not be parsed
+// when it is moved aside.
+const backupSuffix = ".corrupt"
+
+// File is one JSON state file. Saves are atomic, written next to the file and
+// renamed over it, and never run at the same time. A nil File saves nothing.
+type File struct {
+	path string
+
+	// mu keeps saves from writing the file at the same time.
+	mu sync.Mutex
+	// unreadable is why an existing file could not be read; saving over it
+	// would lose what it holds, so saves are refused while it is set.
+	unreadable error
+}
+
+// Load decodes the file at path into v and returns the File to save it
+// with. A missing file leaves v untouched. A file that is not valid JSON is
+// moved aside to path+".corrupt", so the next save starts afresh without
+// losing it, and the error says so. The File is usable in every case.
+func Load(path string, v any) (*File, error) {
+	file := &File{path: path}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return file, nil
+		}
+		file.unreadable = err
+		return file, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		backup := path + backupSuffix
+		if renameErr := os.Rename(path, backup); renameErr != nil {
+			file.unreadable = fmt.Errorf("%s: %w", path, err)
+			return file, file.unreadable
+		}
+		return file, fmt.Errorf("%s: %w; moved it to %s", path, err, backup)
+	}
+	return file, nil
+}
+
+// Save writes the value state returns. state is called once earlier saves
+// are done, so the last of several concurrent saves writes the latest state.
+func (f *File) Save(state func() any) error {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.unreadable != nil {
+		return fmt.Errorf("not saving over %s, which could not be read: %w", f.path, f.unreadable)
+	}
+
+	data, err := json.MarshalIndent(state(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
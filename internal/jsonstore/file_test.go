@@ -0,0 +1,97 @@
+package jsonstore
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type state struct {
+	Jobs []string `json:"jobs"`
+}
+
+func TestLoadAndSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "jobs.json")
+
+	var loaded state
+	file, err := Load(path, &loaded)
+	if err != nil || len(loaded.Jobs) != 0 {
+		t.Fatalf("Load() on a missing file = %+v, %v; want an empty state", loaded, err)
+	}
+	if err := file.Save(func() any { return state{Jobs: []string{"api"}} }); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := Load(path, &loaded); err != nil || len(loaded.Jobs) != 1 || loaded.Jobs[0] != "api" {
+		t.Errorf("reloaded %+v, %v; want the saved job", loaded, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("the temporary file was left behind: %v", err)
+	}
+}
+
+func TestConcurrentSavesWriteTheLatestState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	file, _ := Load(path, &state{})
+
+	var mu sync.Mutex
+	var jobs []string
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			jobs = append(jobs, fmt.Sprintf("job-%d", i))
+			mu.Unlock()
+			err := file.Save(func() any {
+				mu.Lock()
+				defer mu.Unlock()
+				return state{Jobs: append([]string(nil), jobs...)}
+			})
+			if err != nil {
+				t.Errorf("Save() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var loaded state
+	if _, err := Load(path, &loaded); err != nil || len(loaded.Jobs) != 20 {
+		t.Errorf("reloaded %d jobs, %v; want all 20", len(loaded.Jobs), err)
+	}
+}
+
+func TestCorruptFileIsMovedAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	if err := os.WriteFile(path, []byte(`{"jobs": ["api"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var loaded state
+	file, err := Load(path, &loaded)
+	if err == nil || !strings.Contains(err.Error(), path+".corrupt") {
+		t.Fatalf("Load() error = %v, want it to name the backup", err)
+	}
+	if data, _ := os.ReadFile(path + ".corrupt"); string(data) != `{"jobs": ["api"` {
+		t.Errorf("backup holds %q, want the corrupt file", data)
+	}
+	if err := file.Save(func() any { return state{Jobs: []string{"web"}} }); err != nil {
+		t.Errorf("Save() after the backup error = %v", err)
+	}
+}
+
+func TestUnreadableFileIsNotSavedOver(t *testing.T) {
+	// A directory stands in for a file that exists but cannot be read.
+	path := t.TempDir()
+
+	file, err := Load(path, &state{})
+	if err == nil {
+		t.Fatal("Load() of a directory succeeded")
+	}
+	if err := file.Save(func() any { return state{} }); err == nil || !strings.Contains(err.Error(), "not saving over") {
+		t.Errorf("Save() error = %v, want it refused", err)
+	}
+}
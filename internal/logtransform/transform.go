@@ -0,0 +1,206 @@
+// Package logtransform rewrites noisy console output before it is shown.
+// Rules are regular expressions configured per job that strip prefixes, drop
+// lines or collapse runs of similar lines, such as Gradle progress or Maven
+// download spam, into the last one.
+package logtransform
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Actions a rule takes on the lines its pattern matches.
+const (
+	// Replace rewrites the matches with the rule's Replace text, which may
+	// refer to groups as $1 or ${name}. It is the default.
+	Replace = "replace"
+	// Drop hides the line.
+	Drop = "drop"
+	// Collapse shows a run of consecutive matching lines as its last line
+	// and how many were folded into it.
+	Collapse = "collapse"
+)
+
+// Rule is one configured transform.
+type Rule struct {
+	// Jobs selects the jobs the rule applies to: a path.Match glob against
+	// the job full name, or a folder ending in "/" for every job below it.
+	// Empty applies to every job.
+	Jobs    string `json:"jobs"`
+	Pattern string `json:"pattern"`
+	Action  string `json:"action"`
+	Replace string `json:"replace"`
+}
+
+type rule struct {
+	jobs    string
+	re      *regexp.Regexp
+	action  string
+	replace string
+}
+
+// Set is a compiled list of rules.
+type Set struct {
+	rules []rule
+}
+
+// Compile checks and compiles rules. On error the returned Set is empty.
+func Compile(rules []Rule) (Set, error) {
+	compiled := make([]rule, 0, len(rules))
+	for _, r := range rules {
+		action := strings.ToLower(strings.TrimSpace(r.Action))
+		switch action {
+		case "":
+			action = Replace
+		case Replace, Drop, Collapse:
+		default:
+			return Set{}, fmt.Errorf("invalid log transform action %q (use replace, drop or collapse)", r.Action)
+		}
+		if _, err := path.Match(r.Jobs, ""); err != nil {
+			return Set{}, fmt.Errorf("invalid log transform jobs pattern %q: %w", r.Jobs, err)
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return Set{}, fmt.Errorf("invalid log transform pattern %q: %w", r.Pattern, err)
+		}
+		compiled = append(compiled, rule{jobs: r.Jobs, re: re, action: action, replace: r.Replace})
+	}
+	return Set{rules: compiled}, nil
+}
+
+// ForJob returns a transformer with the rules that apply to the job, or nil
+// when none do.
+func (s Set) ForJob(fullName string) *Transformer {
+	var rules []rule
+	for _, r := range s.rules {
+		if matchesJob(r.jobs, fullName) {
+			rules = append(rules, r)
+		}
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return &Transformer{rules: rules}
+}
+
+func matchesJob(pattern, fullName string) bool {
+	switch {
+	case pattern == "":
+		return true
+	case strings.HasSuffix(pattern, "/"):
+		return strings.HasPrefix(fullName, pattern)
+	default:
+		ok, _ := path.Match(pattern, fullName)
+		return ok
+	}
+}
+
+// Line is a line of output, without its newline. Tag is carried over from
+// the input the line came from, e.g. the Pipeline flow node that wrote it.
+type Line struct {
+	Text string
+	Tag  string
+}
+
+// Transformer applies rules to one log as it streams in. Only complete lines
+// are transformed: the end of a line still being written, and a run of lines
+// being collapsed, are held back until they are complete or Flush is called.
+type Transformer struct {
+	rules []rule
+
+	partial    strings.Builder
+	partialTag string
+	// run is the open collapse run, if any.
+	run *run
+}
+
+type run struct {
+	rule  int
+	last  Line
+	count int
+}
+
+// Feed transforms the complete lines of text, which follows the text fed
+// before, and returns them.
+func (t *Transformer) Feed(text, tag string) []Line {
+	var out []Line
+	for text != "" {
+		nl := strings.IndexByte(text, '\n')
+		if nl < 0 {
+			if t.partial.Len() == 0 {
+				t.partialTag = tag
+			}
+			t.partial.WriteString(text)
+			break
+		}
+		line := Line{Text: text[:nl], Tag: tag}
+		if t.partial.Len() > 0 {
+			t.partial.WriteString(line.Text)
+			line = Line{Text: t.partial.String(), Tag: t.partialTag}
+			t.partial.Reset()
+		}
+		text = text[nl+1:]
+		out = t.transform(out, line)
+	}
+	return out
+}
+
+// Flush returns what was held back, for when the log is complete.
+func (t *Transformer) Flush() []Line {
+	var out []Line
+	if t.partial.Len() > 0 {
+		out = t.transform(out, Line{Text: t.partial.String(), Tag: t.partialTag})
+		t.partial.Reset()
+	}
+	return t.closeRun(out)
+}
+
+// Collapsing returns how many lines the open collapse run holds back.
+func (t *Transformer) Collapsing() int {
+	if t == nil || t.run == nil {
+		return 0
+	}
+	return t.run.count
+}
+
+// transform runs line through the rules in order and appends the result.
+func (t *Transformer) transform(out []Line, line Line) []Line {
+	for i, r := range t.rules {
+		if !r.re.MatchString(line.Text) {
+			continue
+		}
+		switch r.action {
+		case Drop:
+			return out
+		case Collapse:
+			if t.run != nil && t.run.rule == i {
+				t.run.last = line
+				t.run.count++
+				return out
+			}
+			out = t.closeRun(out)
+			t.run = &run{rule: i, last: line, count: 1}
+			return out
+		default:
+			line.Text = r.re.ReplaceAllString(line.Text, r.replace)
+		}
+	}
+	out = t.closeRun(out)
+	return append(out, line)
+}
+
+// closeRun appends the open collapse run's last line, noting how many lines
+// it stands for.
+func (t *Transformer) closeRun(out []Line) []Line {
+	if t.run == nil {
+		return out
+	}
+	line := t.run.last
+	if t.run.count > 1 {
+		line.Text += fmt.Sprintf("  (+%d similar lines)", t.run.count-1)
+	}
+	t.run = nil
+	return append(out, line)
+}
@@ -0,0 +1,61 @@
+package logtransform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTransformerStreamsRules(t *testing.T) {
+	set, err := Compile([]Rule{
+		{Jobs: "Backend/", Pattern: `^\[\d{4}-\d\d-\d\dT[\d:.]+Z\] `},
+		{Jobs: "Backend/", Pattern: `^(Downloading|Downloaded) from \w+: `, Action: "collapse"},
+		{Pattern: `^\[Pipeline\] (\}|// )`, Action: "drop"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.ForJob("Frontend/web").rules[0].action != Drop {
+		t.Error("Frontend/web picked up the Backend/ rules")
+	}
+
+	tr := set.ForJob("Backend/api")
+	var got []string
+	feed := func(text string) {
+		for _, line := range tr.Feed(text, "") {
+			got = append(got, line.Text)
+		}
+	}
+	feed("[2024-05-01T10:00:00.123Z] [INFO] Scanning\n[2024-05-01T10:00:01.000Z] Downloading from central: a.pom\nDownloaded from central: a.pom\n")
+	feed("Downloading from central: b.jar\n[Pipeline] }\nDownloaded from cen")
+	if tr.Collapsing() != 3 {
+		t.Errorf("Collapsing() = %d, want 3 held-back downloads", tr.Collapsing())
+	}
+	feed("tral: b.jar\n[INFO] BUILD SUCCESS")
+	for _, line := range tr.Flush() {
+		got = append(got, line.Text)
+	}
+
+	want := []string{
+		"[INFO] Scanning",
+		"Downloaded from central: b.jar  (+3 similar lines)",
+		"[INFO] BUILD SUCCESS",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("transformed log = %q, want %q", got, want)
+	}
+}
+
+func TestCompileRejectsBadRules(t *testing.T) {
+	for _, rule := range []Rule{
+		{Pattern: `(`},
+		{Pattern: `x`, Action: "fold"},
+		{Jobs: `[`, Pattern: `x`},
+	} {
+		if _, err := Compile([]Rule{rule}); err == nil {
+			t.Errorf("Compile(%+v) accepted a bad rule", rule)
+		}
+	}
+	if tr := (Set{}).ForJob("api"); tr != nil {
+		t.Error("an empty set returned a transformer")
+	}
+}
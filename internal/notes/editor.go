@@ -0,0 +1,125 @@
+package notes
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+const (
+	editorMaxWidth  = 90
+	editorMinWidth  = 40
+	editorMaxHeight = 16
+)
+
+// SavedMsg is emitted when the user saves a note from the editor.
+type SavedMsg struct {
+	JobFullName string
+	Text        string
+}
+
+// CancelledMsg is emitted when the user closes the editor without saving.
+type CancelledMsg struct {
+	JobFullName string
+}
+
+// Editor is a modal for writing the markdown note of a job.
+type Editor struct {
+	jobName     string
+	jobFullName string
+	input       textarea.Model
+
+	width  int
+	height int
+}
+
+// NewEditor creates a note editor pre-filled with the existing note text.
+func NewEditor(jobName, jobFullName, text string) *Editor {
+	input := textarea.New()
+	input.Placeholder = "e.g. If this fails, restart the flaky agent first"
+	input.ShowLineNumbers = false
+	input.CharLimit = 0
+	input.SetValue(text)
+
+	return &Editor{
+		jobName:     jobName,
+		jobFullName: jobFullName,
+		input:       input,
+	}
+}
+
+// CapturesInput reports that the editor consumes plain keys (like q) as text.
+func (e *Editor) CapturesInput() bool {
+	return true
+}
+
+// Init focuses the text area.
+func (e *Editor) Init() tea.Cmd {
+	return e.input.Focus()
+}
+
+// Update handles TEA messages for the editor.
+func (e *Editor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.width = msg.Width
+		e.height = msg.Height
+		e.input.SetWidth(e.modalWidth() - 6)
+		height := e.height - 12
+		if height > editorMaxHeight {
+			height = editorMaxHeight
+		}
+		if height < 3 {
+			height = 3
+		}
+		e.input.SetHeight(height)
+		return e, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			fullName := e.jobFullName
+			return e, func() tea.Msg { return CancelledMsg{JobFullName: fullName} }
+		case "ctrl+s":
+			saved := SavedMsg{JobFullName: e.jobFullName, Text: e.input.Value()}
+			return e, func() tea.Msg { return saved }
+		}
+	}
+
+	var cmd tea.Cmd
+	e.input, cmd = e.input.Update(msg)
+	return e, cmd
+}
+
+// View renders the editor modal.
+func (e *Editor) View() string {
+	title := ui.TitleStyle.Render(fmt.Sprintf("Notes: %s", e.jobName))
+	help := ui.SubtleStyle.Render("Markdown supported  [Ctrl+S] Save  [Esc] Cancel  (empty note deletes)")
+	body := lipgloss.JoinVertical(lipgloss.Left, title, "", e.input.View(), "", help)
+
+	panel := lipgloss.NewStyle().
+		Width(e.modalWidth()).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(body)
+
+	if e.width == 0 || e.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(e.width, e.height, lipgloss.Center, lipgloss.Center, panel)
+}
+
+func (e *Editor) modalWidth() int {
+	width := e.width - 10
+	if width > editorMaxWidth {
+		width = editorMaxWidth
+	}
+	if width < editorMinWidth {
+		width = editorMinWidth
+	}
+	return width
+}
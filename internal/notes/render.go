@@ -0,0 +1,26 @@
+package notes
+
+import (
+	"strings"
+
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+// Render applies lightweight markdown styling suitable for the terminal:
+// headings are highlighted, list bullets normalised, and `code` spans kept verbatim.
+func Render(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+
+		switch {
+		case strings.HasPrefix(trimmed, "#"):
+			heading := strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			lines[i] = ui.HighlightStyle.Render(heading)
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			lines[i] = indent + "• " + strings.TrimSpace(trimmed[2:])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
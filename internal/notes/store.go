@@ -0,0 +1,92 @@
+package notes
+
+import (
+	"maps"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jsonstore"
+)
+
+const fileName = "notes.json"
+
+// Note is a free-form markdown note attached to a job.
+type Note struct {
+	Text    string    `json:"text"`
+	Updated time.Time `json:"updated"`
+}
+
+// Store keeps per-job notes in memory and persists them to the config directory.
+// It is safe for concurrent use so saves can run inside tea.Cmds.
+type Store struct {
+	mu    sync.RWMutex
+	file  *jsonstore.File
+	notes map[string]Note
+}
+
+type storeFile struct {
+	Jobs map[string]Note `json:"jobs"`
+}
+
+// Load reads notes from notes.json in the config directory.
+func Load() (*Store, error) {
+	return LoadFrom(filepath.Join(auth.ConfigDir(), fileName))
+}
+
+// LoadFrom reads notes from path. The store is usable even on error, empty
+// when the file was missing or had to be moved aside; see jsonstore.Load.
+func LoadFrom(path string) (*Store, error) {
+	var saved storeFile
+	file, err := jsonstore.Load(path, &saved)
+	store := &Store{file: file, notes: make(map[string]Note, len(saved.Jobs))}
+	maps.Copy(store.notes, saved.Jobs)
+	return store, err
+}
+
+// Get returns the note for a job, if any.
+func (s *Store) Get(jobFullName string) (Note, bool) {
+	if s == nil {
+		return Note{}, false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	note, ok := s.notes[jobFullName]
+	return note, ok
+}
+
+// Set stores (or, for blank text, removes) the note for a job and persists the store.
+func (s *Store) Set(jobFullName, text string) error {
+	s.mu.Lock()
+	if strings.TrimSpace(text) == "" {
+		delete(s.notes, jobFullName)
+	} else {
+		s.notes[jobFullName] = Note{Text: strings.TrimRight(text, "\n"), Updated: time.Now()}
+	}
+	s.mu.Unlock()
+	return s.save()
+}
+
+// Rename moves a note to a job's new full name and persists the store.
+func (s *Store) Rename(oldFullName, newFullName string) error {
+	s.mu.Lock()
+	note, ok := s.notes[oldFullName]
+	if !ok || oldFullName == newFullName {
+		s.mu.Unlock()
+		return nil
+	}
+	delete(s.notes, oldFullName)
+	s.notes[newFullName] = note
+	s.mu.Unlock()
+	return s.save()
+}
+
+func (s *Store) save() error {
+	return s.file.Save(func() any {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		return storeFile{Jobs: maps.Clone(s.notes)}
+	})
+}
@@ -0,0 +1,65 @@
+package notes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+
+	store, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() on missing file error: %v", err)
+	}
+	if err := store.Set("Production/api", "restart the agent first\n"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if err := store.Set("Production/web", "temporary"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if err := store.Set("Production/web", "   "); err != nil {
+		t.Fatalf("Set() blank error: %v", err)
+	}
+	if err := store.Rename("Production/api", "Production/api-v2"); err != nil {
+		t.Fatalf("Rename() error: %v", err)
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error: %v", err)
+	}
+	if _, ok := reloaded.Get("Production/api"); ok {
+		t.Errorf("note still present under old name")
+	}
+	if _, ok := reloaded.Get("Production/web"); ok {
+		t.Errorf("blank note was not deleted")
+	}
+	note, ok := reloaded.Get("Production/api-v2")
+	if !ok {
+		t.Fatalf("renamed note missing")
+	}
+	if note.Text != "restart the agent first" {
+		t.Errorf("note text = %q, want trailing newline trimmed", note.Text)
+	}
+}
+
+func TestCorruptFileIsKeptAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	corrupt := `{"jobs": {"Production/api": {"text": "restart`
+	if err := os.WriteFile(path, []byte(corrupt), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := LoadFrom(path)
+	if err == nil {
+		t.Fatal("LoadFrom() of a corrupt file succeeded")
+	}
+	if err := store.Set("Production/web", "new note"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if data, _ := os.ReadFile(path + ".corrupt"); string(data) != corrupt {
+		t.Errorf("the corrupt notes were not kept: %q", data)
+	}
+}
@@ -0,0 +1,38 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Send raises a desktop notification using the platform's notifier and always
+// rings the terminal bell as a fallback. It returns an error only when no
+// notifier could be run; callers typically treat that as non-fatal.
+func Send(title, body string) error {
+	fmt.Fprint(os.Stderr, "\a")
+
+	// notifierCommand is defined per platform.
+	name, args := notifierCommand(title, body)
+	if name == "" {
+		return fmt.Errorf("no desktop notifier available on %s", runtime.GOOS)
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("desktop notifier %q not found: %w", name, err)
+	}
+	return exec.Command(name, args...).Run()
+}
+
+func appleScriptQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// powerShellQuote returns s as a single-quoted PowerShell string, in which
+// only the quote itself needs escaping (by doubling it).
+func powerShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
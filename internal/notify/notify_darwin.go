@@ -0,0 +1,8 @@
+package notify
+
+import "fmt"
+
+func notifierCommand(title, body string) (string, []string) {
+	script := fmt.Sprintf("display notification %s with title %s", appleScriptQuote(body), appleScriptQuote(title))
+	return "osascript", []string{"-e", script}
+}
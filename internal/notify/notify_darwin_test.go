@@ -0,0 +1,19 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNotifierCommandDarwin(t *testing.T) {
+	name, args := notifierCommand(`jdash: "deadline" missed`, "No successful build of Nightly/e2e by 07:00")
+	if name != "osascript" {
+		t.Fatalf("notifier = %q, want osascript", name)
+	}
+	script := args[len(args)-1]
+	for _, want := range []string{`with title "jdash: \"deadline\" missed"`, `"No successful build of Nightly/e2e by 07:00"`} {
+		if !strings.Contains(script, want) {
+			t.Errorf("AppleScript does not contain %s:\n%s", want, script)
+		}
+	}
+}
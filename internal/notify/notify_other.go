@@ -0,0 +1,7 @@
+//go:build !darwin && !windows && !linux && !freebsd && !openbsd && !netbsd
+
+package notify
+
+func notifierCommand(title, body string) (string, []string) {
+	return "", nil
+}
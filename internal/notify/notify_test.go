@@ -0,0 +1,22 @@
+package notify
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name  string
+		quote func(string) string
+		in    string
+		want  string
+	}{
+		{name: "applescript", quote: appleScriptQuote, in: `say "hi" \o/`, want: `"say \"hi\" \\o/"`},
+		{name: "powershell", quote: powerShellQuote, in: `it's $env:USER`, want: `'it''s $env:USER'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.quote(tt.in); got != tt.want {
+				t.Errorf("quote(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
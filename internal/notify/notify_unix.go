@@ -0,0 +1,7 @@
+//go:build linux || freebsd || openbsd || netbsd
+
+package notify
+
+func notifierCommand(title, body string) (string, []string) {
+	return "notify-send", []string{"--app-name=jdash", title, body}
+}
@@ -0,0 +1,19 @@
+package notify
+
+import "fmt"
+
+// toastScript shows a toast through the WinRT notification API, which
+// Windows PowerShell can load without extra modules. Notifications are
+// attributed to PowerShell, since jdash has no registered AppUserModelID.
+const toastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode(%s)) | Out-Null
+$text.Item(1).AppendChild($xml.CreateTextNode(%s)) | Out-Null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe').Show($toast)`
+
+func notifierCommand(title, body string) (string, []string) {
+	script := fmt.Sprintf(toastScript, powerShellQuote(title), powerShellQuote(body))
+	return "powershell.exe", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+}
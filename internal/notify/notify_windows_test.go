@@ -0,0 +1,19 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNotifierCommandWindows(t *testing.T) {
+	name, args := notifierCommand("jdash: deadline missed", "No successful build of Nightly/e2e by 07:00")
+	if name != "powershell.exe" {
+		t.Fatalf("notifier = %q, want powershell.exe", name)
+	}
+	script := args[len(args)-1]
+	for _, want := range []string{"'jdash: deadline missed'", "'No successful build of Nightly/e2e by 07:00'"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("toast script does not contain %s:\n%s", want, script)
+		}
+	}
+}
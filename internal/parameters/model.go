@@ -1,6 +1,7 @@
 package parameters
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -9,10 +10,15 @@
 	"github.com/charmbracelet/lipgloss"
 	"github.com/gorbach/jdash/internal/jenkins"
 	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
 )
 
+// pickerRows is how many credentials the picker lists at once.
+const pickerRows = 5
+
 // Model represents the modal used to collect parameter values before triggering a build.
 type Model struct {
+	client      jenkins.JenkinsClient
 	jobName     string
 	jobFullName string
 
@@ -24,6 +30,23 @@ type Model struct {
 	height int
 
 	errMessage string
+
+	// credentials are offered for credentials parameters once loaded, by
+	// the type of credential the parameter takes; a type is missing while
+	// it loads. A user who may not list them types an ID instead.
+	credentials map[string]credentialChoices
+}
+
+// credentialChoices are the credentials of one type the job may take.
+type credentialChoices struct {
+	credentials []jenkins.Credential
+	err         error
+}
+
+type credentialsMsg struct {
+	credentialType string
+	credentials    []jenkins.Credential
+	err            error
 }
 
 // SubmittedMsg is emitted when the user confirms the trigger with parameter values.
@@ -39,12 +62,15 @@ type CancelledMsg struct {
 }
 
 // New creates a parameter modal model seeded with parameter definitions.
-func New(jobName, jobFullName string, defs []jenkins.ParameterDefinition) *Model {
+// Credentials parameters get a picker of the credentials client can list.
+func New(client jenkins.JenkinsClient, jobName, jobFullName string, defs []jenkins.ParameterDefinition) *Model {
 	model := &Model{
+		client:      client,
 		jobName:     jobName,
 		jobFullName: jobFullName,
 		definitions: append([]jenkins.ParameterDefinition(nil), defs...),
 		inputs:      make([]textinput.Model, len(defs)),
+		credentials: make(map[string]credentialChoices),
 	}
 
 	for i := range model.definitions {
@@ -66,13 +92,41 @@ func New(jobName, jobFullName string, defs []jenkins.ParameterDefinition) *Model
 	return model
 }
 
-// Init focuses the first field (if present).
+// Init focuses the first field (if present) and loads the credentials to
+// pick from, once per type of credential the parameters take.
 func (m *Model) Init() tea.Cmd {
 	if len(m.inputs) == 0 {
 		return nil
 	}
-	cmd := m.inputs[m.focusIndex].Focus()
-	return cmd
+	cmds := []tea.Cmd{m.inputs[m.focusIndex].Focus()}
+	if m.client != nil {
+		loading := make(map[string]bool)
+		for _, def := range m.definitions {
+			if credentialType := def.CredentialsType(); def.IsCredentials() && !loading[credentialType] {
+				loading[credentialType] = true
+				cmds = append(cmds, loadCredentialsCmd(m.client, m.jobFullName, credentialType))
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// loadCredentialsCmd lists the credentials of credentialType the job's
+// parameters may take, from every store the job sees, folders included.
+func loadCredentialsCmd(client jenkins.JenkinsClient, jobFullName, credentialType string) tea.Cmd {
+	return func() tea.Msg {
+		credentials, err := client.GetCredentialChoices(context.Background(), jobFullName, credentialType)
+		return credentialsMsg{credentialType: credentialType, credentials: credentials, err: err}
+	}
+}
+
+func (m *Model) hasCredentialsParameter() bool {
+	for _, def := range m.definitions {
+		if def.IsCredentials() {
+			return true
+		}
+	}
+	return false
 }
 
 // Update handles TEA messages for the modal.
@@ -83,6 +137,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		return m, nil
 
+	case credentialsMsg:
+		m.credentials[msg.credentialType] = credentialChoices{credentials: msg.credentials, err: msg.err}
+		return m, nil
+
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "esc":
@@ -93,6 +151,10 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.shiftFocus(-1)
 		case "enter":
 			return m, submitCmd(m.jobName, m.jobFullName, m.collectValues())
+		case "up", "down":
+			if m.pickCredential(msg.String() == "down") {
+				return m, nil
+			}
 		}
 	}
 
@@ -123,7 +185,8 @@ func (m *Model) View() string {
 			content.WriteString(label)
 			content.WriteString("\n")
 
-			if desc := strings.TrimSpace(def.Description); desc != "" {
+			// Descriptions are often HTML, e.g. with the Safe HTML formatter.
+			if desc := utils.HTMLToText(strings.TrimSpace(def.Description)); desc != "" {
 				content.WriteString(ui.SubtleStyle.Render(desc))
 				content.WriteString("\n")
 			}
@@ -135,21 +198,28 @@ func (m *Model) View() string {
 			}
 
 			content.WriteString(m.inputs[i].View())
-			content.WriteString("\n\n")
+			content.WriteString("\n")
+			if def.IsCredentials() {
+				content.WriteString(m.credentialsView(i))
+			}
+			content.WriteString("\n")
 		}
 	}
 
-	content.WriteString(ui.SubtleStyle.Render("[Tab] Next  [Shift+Tab] Previous  [Enter] Trigger  [Esc] Cancel"))
+	hints := "[Tab] Next  [Shift+Tab] Previous  [Enter] Trigger  [Esc] Cancel"
+	if m.hasCredentialsParameter() {
+		hints = "[↑/↓] Pick credential  " + hints
+	}
+	content.WriteString(ui.SubtleStyle.Render(hints))
 	if strings.TrimSpace(m.errMessage) != "" {
 		content.WriteString("\n")
 		content.WriteString(ui.ErrorStyle.Render(m.errMessage))
 	}
 
-	modalWidth := clampInt(48, 80, m.longestInputWidth()+8)
 	panel := lipgloss.NewStyle().
-		Width(modalWidth).
+		Width(m.modalWidth()).
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("10")).
+		BorderForeground(ui.ColorBorderActive).
 		Padding(1, 2).
 		Render(strings.TrimRight(content.String(), "\n"))
 
@@ -166,6 +236,100 @@ func (m *Model) View() string {
 	)
 }
 
+// pickCredential moves the focused credentials parameter to the next or
+// previous listed credential, reporting whether the field takes one.
+func (m *Model) pickCredential(next bool) bool {
+	if len(m.inputs) == 0 || !m.definitions[m.focusIndex].IsCredentials() {
+		return false
+	}
+	credentials := m.credentialsOf(m.focusIndex)
+	if len(credentials) == 0 {
+		return true
+	}
+	index := credentialIndex(credentials, m.inputs[m.focusIndex].Value())
+	switch {
+	case index < 0:
+		index = 0
+	case next:
+		index = min(index+1, len(credentials)-1)
+	default:
+		index = max(index-1, 0)
+	}
+	m.inputs[m.focusIndex].SetValue(credentials[index].ID)
+	m.inputs[m.focusIndex].CursorEnd()
+	return true
+}
+
+// credentialsOf returns the credentials listed for the parameter at index
+// i, none while they load.
+func (m *Model) credentialsOf(i int) []jenkins.Credential {
+	return m.credentials[m.definitions[i].CredentialsType()].credentials
+}
+
+// credentialIndex finds the credential with the given ID, or -1.
+func credentialIndex(credentials []jenkins.Credential, id string) int {
+	id = strings.TrimSpace(id)
+	for i, credential := range credentials {
+		if credential.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// credentialsView lists the credentials around the one picked for the
+// parameter at index i while it has focus, and otherwise describes the
+// picked one.
+func (m *Model) credentialsView(i int) string {
+	// The panel's padding takes 4 columns.
+	width := m.modalWidth() - 4
+	choices, loaded := m.credentials[m.definitions[i].CredentialsType()]
+	credentials := choices.credentials
+	switch {
+	case !loaded:
+		return ui.SubtleStyle.Render("Loading credentials...") + "\n"
+	case choices.err != nil:
+		return ui.SubtleStyle.Render(utils.TruncateString("Can't list credentials; type an ID ("+choices.err.Error()+")", width)) + "\n"
+	case len(credentials) == 0:
+		return ui.SubtleStyle.Render("No credentials of this type for the job; type an ID") + "\n"
+	}
+
+	picked := credentialIndex(credentials, m.inputs[i].Value())
+	if i != m.focusIndex {
+		if picked < 0 {
+			return ""
+		}
+		return ui.SubtleStyle.Render(utils.TruncateString(credentialLabel(credentials[picked]), width)) + "\n"
+	}
+
+	top := clampInt(0, max(len(credentials)-pickerRows, 0), picked-pickerRows/2)
+	var b strings.Builder
+	for j := top; j < min(top+pickerRows, len(credentials)); j++ {
+		line := utils.TruncateString(credentials[j].ID+"  "+credentialLabel(credentials[j]), width-2)
+		if j == picked {
+			b.WriteString(ui.HighlightStyle.Render("▸ " + line))
+		} else {
+			b.WriteString(ui.SubtleStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// credentialLabel reads e.g. "Username with password · GitHub bot", or is
+// the name the build form shows, e.g. "ci-bot/****** (GitHub bot)", when the
+// type is unknown.
+func credentialLabel(credential jenkins.Credential) string {
+	if credential.TypeName == "" {
+		return credential.DisplayName
+	}
+	label := credential.TypeName
+	if desc := strings.TrimSpace(credential.Description); desc != "" {
+		label += " · " + desc
+	}
+	return label
+}
+
 func (m *Model) shiftFocus(delta int) tea.Cmd {
 	if len(m.inputs) == 0 {
 		return nil
@@ -202,6 +366,16 @@ func (m *Model) collectValues() map[string]string {
 	return values
 }
 
+// modalWidth fits the widest input, leaving credentials room for their
+// type and description.
+func (m *Model) modalWidth() int {
+	minWidth := 48
+	if m.hasCredentialsParameter() {
+		minWidth = 64
+	}
+	return clampInt(minWidth, 80, m.longestInputWidth()+8)
+}
+
 func (m *Model) longestInputWidth() int {
 	width := 32
 	for i := range m.definitions {
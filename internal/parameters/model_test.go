@@ -0,0 +1,82 @@
+package parameters
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+)
+
+func TestCredentialsParameterPicksFromStore(t *testing.T) {
+	const sshType = "com.cloudbees.jenkins.plugins.sshcredentials.SSHUserPrivateKey"
+	client := &jenkinstest.Client{
+		GetCredentialChoicesFunc: func(ctx context.Context, fullName, credentialType string) ([]jenkins.Credential, error) {
+			if credentialType == sshType {
+				return []jenkins.Credential{{ID: "deploy-key", DisplayName: "deploy (team key)"}}, nil
+			}
+			return []jenkins.Credential{
+				{ID: "deploy-key", DisplayName: "deploy (team key)"},
+				{ID: "github-token", DisplayName: "ci-bot/****** (GitHub bot)"},
+			}, nil
+		},
+	}
+	m := New(client, "deploy", "Team/deploy", []jenkins.ParameterDefinition{
+		{Name: "GIT_CREDENTIALS", Type: "CredentialsParameterDefinition"},
+		{Name: "SSH_CREDENTIALS", Type: "CredentialsParameterDefinition", CredentialType: sshType},
+		{Name: "TARGET", Type: "StringParameterDefinition"},
+	})
+	// The batch focuses the first field, then loads each type once.
+	batch := m.Init()().(tea.BatchMsg)
+	for _, cmd := range batch[1:] {
+		m.Update(cmd())
+	}
+	calls := client.CallsTo("GetCredentialChoices")
+	if len(calls) != 2 || calls[0].Args[0] != "Team/deploy" || calls[0].Args[1] != jenkins.DefaultCredentialType || calls[1].Args[1] != sshType {
+		t.Fatalf("GetCredentialChoices calls = %v, want one per credential type of Team/deploy", calls)
+	}
+
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	m.Update(down)
+	m.Update(down)
+	if view := m.View(); !strings.Contains(view, "▸ github-token") || !strings.Contains(view, "GitHub bot") {
+		t.Errorf("picker does not show the picked credential:\n%s", view)
+	}
+
+	// The SSH parameter offers only the credentials of its type.
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m.Update(down)
+	m.Update(down)
+	if view := m.View(); !strings.Contains(view, "▸ deploy-key") || strings.Contains(view, "github-token  ci-bot") {
+		t.Errorf("SSH picker does not list only SSH credentials:\n%s", view)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	submitted := cmd().(SubmittedMsg)
+	if got := submitted.Values["GIT_CREDENTIALS"]; got != "github-token" {
+		t.Errorf("submitted credential = %q, want github-token", got)
+	}
+	if got := submitted.Values["SSH_CREDENTIALS"]; got != "deploy-key" {
+		t.Errorf("submitted SSH credential = %q, want deploy-key", got)
+	}
+}
+
+func TestHTMLDescriptionRenderedAsText(t *testing.T) {
+	m := New(&jenkinstest.Client{}, "deploy", "deploy", []jenkins.ParameterDefinition{
+		{Name: "REGION", Type: "ChoiceParameterDefinition", Choices: []string{"eu", "us"},
+			Description: `<p>Where to deploy:</p><ul><li>eu</li><li>us</li></ul>See <a href="https://wiki/regions">regions</a>`},
+	})
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	view := m.View()
+	for _, want := range []string{"Where to deploy:", "• eu", "• us", "regions (https://wiki/regions)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not show %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "<li>") {
+		t.Errorf("view shows raw tags:\n%s", view)
+	}
+}
@@ -0,0 +1,92 @@
+// Package profiling records a CPU profile, heap profile or execution trace of
+// a dashboard session, for diagnosing reports of a slow or stuttering UI with
+// go tool pprof and go tool trace.
+package profiling
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"time"
+)
+
+// Mode is what a session records.
+type Mode string
+
+const (
+	// CPU samples where the process spends its time.
+	CPU Mode = "cpu"
+	// Mem writes the live heap once the session ends.
+	Mem Mode = "mem"
+	// Trace records scheduler, GC and goroutine events.
+	Trace Mode = "trace"
+)
+
+// ParseMode reads the value of the --profile flag.
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(s); mode {
+	case CPU, Mem, Trace:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown profile %q (want cpu, mem or trace)", s)
+}
+
+// Session is a profile being recorded.
+type Session struct {
+	mode Mode
+	file *os.File
+	// Path is the file the profile is written to.
+	Path string
+}
+
+// Start begins recording mode into a new file in dir, named after the mode
+// and the time, e.g. jdash-cpu-20240102-150405.pprof.
+func Start(mode Mode, dir string) (*Session, error) {
+	ext := ".pprof"
+	if mode == Trace {
+		ext = ".out"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("jdash-%s-%s%s", mode, time.Now().Format("20060102-150405"), ext))
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile: %w", err)
+	}
+
+	switch mode {
+	case CPU:
+		err = pprof.StartCPUProfile(file)
+	case Trace:
+		err = trace.Start(file)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to start %s profile: %w", mode, err)
+	}
+	return &Session{mode: mode, file: file, Path: path}, nil
+}
+
+// Stop ends the recording and closes the file.
+func (s *Session) Stop() error {
+	var err error
+	switch s.mode {
+	case CPU:
+		pprof.StopCPUProfile()
+	case Trace:
+		trace.Stop()
+	case Mem:
+		// Collect first so the profile shows what is still live.
+		runtime.GC()
+		err = pprof.WriteHeapProfile(s.file)
+	}
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s profile: %w", s.mode, err)
+	}
+	return nil
+}
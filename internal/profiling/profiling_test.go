@@ -0,0 +1,37 @@
+package profiling
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSessionWritesProfile(t *testing.T) {
+	for _, mode := range []Mode{CPU, Mem, Trace} {
+		t.Run(string(mode), func(t *testing.T) {
+			session, err := Start(mode, t.TempDir())
+			if err != nil {
+				t.Fatalf("Start: %v", err)
+			}
+			if err := session.Stop(); err != nil {
+				t.Fatalf("Stop: %v", err)
+			}
+			info, err := os.Stat(session.Path)
+			if err != nil || info.Size() == 0 {
+				t.Errorf("profile %s is missing or empty: %v", session.Path, err)
+			}
+			if !strings.Contains(session.Path, "jdash-"+string(mode)+"-") {
+				t.Errorf("profile path %s does not name the mode", session.Path)
+			}
+		})
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	if mode, err := ParseMode("trace"); err != nil || mode != Trace {
+		t.Errorf("ParseMode(trace) = %q, %v", mode, err)
+	}
+	if _, err := ParseMode("gpu"); err == nil {
+		t.Error("ParseMode accepted gpu")
+	}
+}
@@ -1,21 +1,38 @@
 package queue
 
 import (
+	"fmt"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/inflight"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/queuelog"
 )
 
 // tickMsg is sent every second to update elapsed times
 type tickMsg time.Time
 
-// pollQueueMsg triggers a poll of the Jenkins build queue
-type pollQueueMsg struct{}
+// pollQueueMsg triggers a poll of the Jenkins build queue; stale tickets are ignored
+// so that rescheduling (e.g. leaving idle mode) never leaves two polling loops running.
+type pollQueueMsg struct {
+	ticket uint64
+}
 
 // queueUpdateMsg contains the fetched queue data
 type queueUpdateMsg struct {
 	queuedItems   []jenkins.QueueItem
 	runningBuilds []jenkins.RunningBuild
+	polledAt      time.Time
+	// logErr is why the poll could not be recorded in the queue log.
+	logErr error
+}
+
+// SnapshotMsg shares every successful queue poll with the other panels, e.g.
+// to follow where a triggered build stands or mark jobs queued and building.
+type SnapshotMsg struct {
+	Queued  []jenkins.QueueItem
+	Running []jenkins.RunningBuild
 }
 
 // queueErrorMsg contains error information from queue polling
@@ -23,5 +40,54 @@ type queueErrorMsg struct {
 	err error
 }
 
+// queueItemCancelledMsg reports the outcome of cancelling a queued item.
+type queueItemCancelledMsg struct {
+	jobName string
+	err     error
+}
+
+// ItemSelectedMsg is emitted when a queued item is opened with Enter, so
+// the bottom panel shows it and takes the keyboard. Moving the cursor does
+// not emit it, which would fetch every item passed over.
+type ItemSelectedMsg struct {
+	Item jenkins.QueueItem
+}
+
+// itemSelectedCmd returns a command that emits an ItemSelectedMsg.
+func itemSelectedCmd(item jenkins.QueueItem) tea.Cmd {
+	return func() tea.Msg {
+		return ItemSelectedMsg{Item: item}
+	}
+}
+
 // RefreshRequestedMsg asks the queue panel to poll Jenkins immediately.
 type RefreshRequestedMsg struct{}
+
+// ExportRequestedMsg asks the app to export the current queue snapshot as CSV.
+type ExportRequestedMsg struct {
+	Running []jenkins.RunningBuild
+	Queued  []jenkins.QueueItem
+}
+
+// HistoryRequestedMsg asks the app to show the logged queue waits.
+type HistoryRequestedMsg struct {
+	Entries []queuelog.Entry
+}
+
+// exportRequestedCmd returns a command that emits an ExportRequestedMsg.
+func exportRequestedCmd(running []jenkins.RunningBuild, queued []jenkins.QueueItem) tea.Cmd {
+	return func() tea.Msg {
+		return ExportRequestedMsg{Running: running, Queued: queued}
+	}
+}
+
+// cancelQueueItemCmd removes a waiting item from the Jenkins queue, tracked
+// by tracker until Jenkins answers.
+func cancelQueueItemCmd(tracker *inflight.Tracker, client jenkins.JenkinsClient, item jenkins.QueueItem) tea.Cmd {
+	ctx, done := tracker.Start(fmt.Sprintf("Remove %s from the queue", item.GetJobFullName()))
+	return func() tea.Msg {
+		err := client.CancelQueueItem(ctx, item.ID)
+		done(err)
+		return queueItemCancelledMsg{jobName: item.GetJobName(), err: err}
+	}
+}
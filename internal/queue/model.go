@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -8,8 +9,23 @@
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/activity"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/inflight"
 	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/queuelog"
+	"github.com/gorbach/jdash/internal/statusbar"
+	"github.com/gorbach/jdash/internal/ticket"
 	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+const (
+	// defaultPollInterval is the time between queue polls unless the server
+	// config sets another.
+	defaultPollInterval = 3 * time.Second
+	// errorPollInterval is the shortest wait before retrying a failed poll.
+	errorPollInterval = 5 * time.Second
 )
 
 // Model represents the build queue panel
@@ -20,24 +36,93 @@ type Model struct {
 	runningBuilds []jenkins.RunningBuild
 	spinner       spinner.Model
 	client        jenkins.JenkinsClient
+	ctx           context.Context
+	tracker       *inflight.Tracker
 	polling       bool
+	pollTicket    ticket.Counter
+	idle          bool
+	paused        bool
 	lastPoll      time.Time
 	err           error
+	// pollInterval is the time between polls while Jenkins answers.
+	pollInterval time.Duration
+	// log records how long items waited, from one poll to the next; logErr
+	// is the last error saving it, already reported.
+	log    *queuelog.Log
+	logErr string
+
+	// cursor indexes queuedItems; confirmCancel holds the item awaiting
+	// confirmation and prompt the question asked about it.
+	cursor        int
+	confirmCancel *jenkins.QueueItem
+	prompt        confirm.Prompt
+	policy        confirm.Policy
+	// user is the configured Jenkins user, whose items get a "me" badge.
+	user string
 }
 
 // New creates a new queue panel model
 func New(client jenkins.JenkinsClient) Model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("11")) // Yellow
+	s.Style = ui.BuildingStyle
 
 	return Model{
-		client:  client,
-		spinner: s,
-		polling: true,
+		client:       client,
+		ctx:          context.Background(),
+		spinner:      s,
+		polling:      true,
+		pollInterval: defaultPollInterval,
 	}
 }
 
+// WithConfirmPolicy returns the model asking before removing queue items as
+// policy requires.
+func (m Model) WithConfirmPolicy(policy confirm.Policy) Model {
+	m.policy = policy
+	return m
+}
+
+// WithPollInterval returns the model polling the queue every interval
+// instead of the default; zero keeps the default.
+func (m Model) WithPollInterval(interval time.Duration) Model {
+	if interval > 0 {
+		m.pollInterval = interval
+	}
+	return m
+}
+
+// WithContext returns the model polling with ctx, so the poll still running
+// stops when it is cancelled.
+func (m Model) WithContext(ctx context.Context) Model {
+	m.ctx = ctx
+	return m
+}
+
+// WithTracker returns the model registering the items it removes from the
+// queue with tracker, so quitting can wait for Jenkins to answer.
+func (m Model) WithTracker(tracker *inflight.Tracker) Model {
+	m.tracker = tracker
+	return m
+}
+
+// WithUser returns the model marking the items user enqueued.
+func (m Model) WithUser(user string) Model {
+	m.user = user
+	return m
+}
+
+// WithLog returns the model recording queue waits into log.
+func (m Model) WithLog(log *queuelog.Log) Model {
+	m.log = log
+	return m
+}
+
+// CapturesInput reports whether a type-the-name confirmation is open.
+func (m Model) CapturesInput() bool {
+	return m.confirmCancel != nil && m.prompt.CapturesInput()
+}
+
 // Init initializes the model and starts polling
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
@@ -55,6 +140,19 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.height = msg.Height
 		return m, nil
 
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+
+	case queueItemCancelledMsg:
+		feedback := statusbar.FeedbackMsg{Text: fmt.Sprintf("✓ Removed %s from the queue", msg.jobName)}
+		if msg.err != nil {
+			feedback = statusbar.FeedbackMsg{Text: fmt.Sprintf("✗ %v", msg.err), IsError: true}
+		}
+		return m, tea.Batch(
+			func() tea.Msg { return feedback },
+			m.pollQueueCmd(),
+		)
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -69,35 +167,61 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	case pollQueueMsg:
 		// Trigger a queue poll
+		if !m.pollTicket.IsCurrent(msg.ticket) || m.paused {
+			return m, nil
+		}
 		return m, m.pollQueueCmd()
 
+	case activity.IdleChangedMsg:
+		m.idle = msg.Idle
+		if !m.idle && m.polling {
+			// Resume the normal cadence right away instead of waiting out the idle interval.
+			m.pollTicket.Invalidate()
+			return m, m.pollQueueCmd()
+		}
+		return m, nil
+
+	case activity.PausedChangedMsg:
+		m.paused = msg.Paused
+		if !m.paused && m.polling {
+			m.pollTicket.Invalidate()
+			return m, m.pollQueueCmd()
+		}
+		return m, nil
+
 	case RefreshRequestedMsg:
 		return m, m.pollQueueCmd()
 
 	case queueUpdateMsg:
 		// Queue data fetched successfully
 		m.queuedItems = msg.queuedItems
+		m.clampCursor()
 		m.runningBuilds = msg.runningBuilds
-		m.lastPoll = time.Now()
+		m.lastPoll = msg.polledAt
 		m.err = nil
 
-		// Schedule next poll in 3 seconds
+		snapshot := SnapshotMsg{Queued: msg.queuedItems, Running: msg.runningBuilds}
+		shareCmd := func() tea.Msg { return snapshot }
+		// Report each way the queue log fails once, not on every poll.
+		if msg.logErr != nil && msg.logErr.Error() != m.logErr {
+			m.logErr = msg.logErr.Error()
+			feedback := statusbar.FeedbackMsg{Text: fmt.Sprintf("✗ Queue history not saved: %v", msg.logErr), IsError: true}
+			shareCmd = tea.Batch(shareCmd, func() tea.Msg { return feedback })
+		}
+
+		// Schedule the next poll (slower while idle)
 		if m.polling {
-			return m, tea.Tick(3*time.Second, func(t time.Time) tea.Msg {
-				return pollQueueMsg{}
-			})
+			return m, tea.Batch(shareCmd, m.schedulePoll(m.pollInterval))
 		}
-		return m, nil
+		return m, shareCmd
 
 	case queueErrorMsg:
 		// Error fetching queue
 		m.err = msg.err
 
-		// Retry in 5 seconds on error
+		// Retry in at least 5 seconds on error (slower while idle)
 		if m.polling {
-			return m, tea.Tick(5*time.Second, func(t time.Time) tea.Msg {
-				return pollQueueMsg{}
-			})
+			return m, m.schedulePoll(max(errorPollInterval, m.pollInterval))
 		}
 		return m, nil
 	}
@@ -105,33 +229,111 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m Model) handleKeyMsg(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.confirmCancel != nil {
+		item := *m.confirmCancel
+		prompt, result := m.prompt.HandleKey(msg)
+		m.prompt = prompt
+		switch result {
+		case confirm.Confirmed:
+			m.confirmCancel = nil
+			return m, cancelQueueItemCmd(m.tracker, m.client, item)
+		case confirm.Cancelled:
+			m.confirmCancel = nil
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "E":
+		return m, exportRequestedCmd(m.runningBuilds, m.queuedItems)
+	case "H":
+		entries := m.log.Entries()
+		return m, func() tea.Msg { return HistoryRequestedMsg{Entries: entries} }
+	case "j", "down":
+		return m.moveCursor(1)
+	case "k", "up":
+		return m.moveCursor(-1)
+	case "enter":
+		if item := m.selectedQueueItem(); item != nil {
+			return m, itemSelectedCmd(*item)
+		}
+	case "x":
+		if item := m.selectedQueueItem(); item != nil {
+			itemCopy := *item
+			level := m.policy.LevelFor(confirm.ActionCancelQueue, itemCopy.GetJobFullName())
+			if level == confirm.None {
+				return m, cancelQueueItemCmd(m.tracker, m.client, itemCopy)
+			}
+			m.confirmCancel = &itemCopy
+			m.prompt = confirm.NewPrompt(level, fmt.Sprintf("Remove %s from the queue?", itemCopy.GetJobName()), itemCopy.GetJobName())
+		}
+	}
+	return m, nil
+}
+
+// selectedQueueItem returns the queued item under the cursor, if any.
+func (m Model) selectedQueueItem() *jenkins.QueueItem {
+	if m.cursor < 0 || m.cursor >= len(m.queuedItems) {
+		return nil
+	}
+	return &m.queuedItems[m.cursor]
+}
+
+// moveCursor moves the cursor by delta.
+func (m Model) moveCursor(delta int) (Model, tea.Cmd) {
+	m.cursor += delta
+	m.clampCursor()
+	return m, nil
+}
+
+func (m *Model) clampCursor() {
+	if m.cursor >= len(m.queuedItems) {
+		m.cursor = len(m.queuedItems) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// TitleBar describes the queue panel header with the total count (running + queued).
+func (m Model) TitleBar() ui.TitleBar {
+	bar := ui.TitleBar{
+		Name:  "Build Queue",
+		Count: utils.FormatCount(int64(len(m.runningBuilds) + len(m.queuedItems))),
+	}
+	if len(m.runningBuilds) > 0 {
+		bar.Chips = append(bar.Chips, fmt.Sprintf("running: %d", len(m.runningBuilds)))
+	}
+	if len(m.queuedItems) > 0 {
+		bar.Chips = append(bar.Chips, fmt.Sprintf("queued: %d", len(m.queuedItems)))
+	}
+	if m.err != nil {
+		bar.Chips = append(bar.Chips, "error")
+	}
+	return bar
+}
+
 // View renders the queue panel
 func (m Model) View() string {
 	var b strings.Builder
 
-	// Title with total count (running + queued)
 	totalCount := len(m.runningBuilds) + len(m.queuedItems)
-	title := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("14")). // Cyan
-		Render(fmt.Sprintf("Build Queue (%d)", totalCount))
-
-	b.WriteString(title)
-	b.WriteString("\n\n")
 
 	// Show error if present
 	if m.err != nil {
-		errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9")) // Red
-		b.WriteString(errStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+		b.WriteString(ui.FailedStyle.Render(fmt.Sprintf("Error: %s", m.err.Error())))
+		b.WriteString("\n\n")
+	}
+
+	if m.confirmCancel != nil {
+		b.WriteString(ui.HighlightStyle.Render(m.prompt.View()))
 		b.WriteString("\n\n")
 	}
 
 	// Show items or empty state
 	if totalCount == 0 {
-		emptyStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("8")).
-			Italic(true)
-		b.WriteString(emptyStyle.Render("[Empty queue]"))
+		b.WriteString(ui.SubtleStyle.Italic(true).Render("[Empty queue]"))
 	} else {
 		// First show running builds
 		for _, build := range m.runningBuilds {
@@ -140,8 +342,8 @@ func (m Model) View() string {
 		}
 
 		// Then show queued items
-		for _, item := range m.queuedItems {
-			b.WriteString(m.renderQueueItem(item))
+		for i, item := range m.queuedItems {
+			b.WriteString(m.renderQueueItem(item, i == m.cursor))
 			b.WriteString("\n")
 		}
 	}
@@ -149,10 +351,9 @@ func (m Model) View() string {
 	// Add polling indicator at bottom if there's space
 	if m.height > 10 {
 		b.WriteString("\n")
-		lastPollStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
 		if !m.lastPoll.IsZero() {
 			elapsed := time.Since(m.lastPoll).Round(time.Second)
-			b.WriteString(lastPollStyle.Render(fmt.Sprintf("Last poll: %s ago", elapsed)))
+			b.WriteString(ui.SubtleStyle.Render(fmt.Sprintf("Last poll: %s ago", elapsed)))
 		}
 	}
 
@@ -169,8 +370,7 @@ func (m Model) renderRunningBuild(build jenkins.RunningBuild) string {
 
 	// Build number
 	buildNum := fmt.Sprintf("#%d", build.BuildNumber)
-	buildStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("11")) // Yellow
-	b.WriteString(buildStyle.Render(buildNum))
+	b.WriteString(ui.BuildingStyle.Render(buildNum))
 	b.WriteString(" ")
 
 	// Job name
@@ -180,14 +380,14 @@ func (m Model) renderRunningBuild(build jenkins.RunningBuild) string {
 
 	// Elapsed time
 	elapsed := build.GetElapsedTime()
-	elapsedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
-	b.WriteString(elapsedStyle.Render(formatDuration(elapsed)))
+	b.WriteString(ui.SubtleStyle.Render(formatDuration(elapsed)))
 
 	return b.String()
 }
 
-// renderQueueItem renders a single queued item (not yet executing)
-func (m Model) renderQueueItem(item jenkins.QueueItem) string {
+// renderQueueItem renders a single queued item (not yet executing);
+// the selected item is the one "x" cancels.
+func (m Model) renderQueueItem(item jenkins.QueueItem, selected bool) string {
 	var b strings.Builder
 
 	// Queued but not building yet - show pending icon
@@ -200,20 +400,24 @@ func (m Model) renderQueueItem(item jenkins.QueueItem) string {
 		jobName = item.Task.URL // Fallback to URL
 	}
 	nameStyle := lipgloss.NewStyle().Bold(true)
+	if selected {
+		nameStyle = ui.SelectedStyle
+	}
 	b.WriteString(nameStyle.Render(jobName))
 	b.WriteString("  ")
+	if item.IsTriggeredBy(m.user) {
+		b.WriteString(ui.MeBadge())
+		b.WriteString(" ")
+	}
 
 	// Time in queue
 	elapsed := item.GetInQueueDuration()
-	elapsedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
-	b.WriteString(elapsedStyle.Render(formatDuration(elapsed)))
+	b.WriteString(ui.SubtleStyle.Render(formatDuration(elapsed)))
 
 	// Show reason if blocked or stuck
 	if item.Blocked || item.Stuck {
 		b.WriteString(" ")
-		reasonStyle := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("9")).
-			Italic(true)
+		reasonStyle := ui.FailedStyle.Italic(true)
 		if item.Stuck {
 			b.WriteString(reasonStyle.Render("[STUCK]"))
 		} else if item.Blocked {
@@ -231,24 +435,43 @@ func (m Model) tickCmd() tea.Cmd {
 	})
 }
 
+// schedulePoll starts the next poll after base (stretched while idle),
+// invalidating any poll scheduled earlier.
+func (m *Model) schedulePoll(base time.Duration) tea.Cmd {
+	ticket := m.pollTicket.Next()
+	return tea.Tick(activity.PollInterval(base, m.idle), func(time.Time) tea.Msg {
+		return pollQueueMsg{ticket: ticket}
+	})
+}
+
+// HasRunningBuilds reports whether any executor is currently busy.
+func (m Model) HasRunningBuilds() bool {
+	return len(m.runningBuilds) > 0
+}
+
 // pollQueueCmd returns a command that fetches both queued and running builds
 func (m Model) pollQueueCmd() tea.Cmd {
+	client, ctx, log := m.client, m.ctx, m.log
 	return func() tea.Msg {
 		// Fetch queued items (waiting to start)
-		queuedItems, err := m.client.GetBuildQueue()
+		queuedItems, err := client.GetBuildQueue(ctx)
 		if err != nil {
 			return queueErrorMsg{err: err}
 		}
 
 		// Fetch running builds (currently executing)
-		runningBuilds, err := m.client.GetRunningBuilds()
+		runningBuilds, err := client.GetRunningBuilds(ctx)
 		if err != nil {
 			return queueErrorMsg{err: err}
 		}
 
+		// Recorded here rather than in Update, as it writes the log file.
+		now := time.Now()
 		return queueUpdateMsg{
 			queuedItems:   queuedItems,
 			runningBuilds: runningBuilds,
+			polledAt:      now,
+			logErr:        log.Observe(queuedItems, runningBuilds, now),
 		}
 	}
 }
@@ -0,0 +1,167 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/activity"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+	"github.com/gorbach/jdash/internal/queuelog"
+	"github.com/gorbach/jdash/internal/statusbar"
+)
+
+// poll runs one queue poll against the panel's client and applies the result.
+func poll(t *testing.T, m Model) Model {
+	t.Helper()
+	m, cmd := m.Update(RefreshRequestedMsg{})
+	if cmd == nil {
+		t.Fatal("refresh did not poll")
+	}
+	m, _ = m.Update(cmd())
+	return m
+}
+
+func TestPollShowsQueueAndRunningBuilds(t *testing.T) {
+	server := jenkinstest.NewServer()
+	defer server.Close()
+	server.StartBuild("platform/deploy")
+	server.Enqueue("api", "Waiting for next available executor")
+
+	m := poll(t, New(jenkins.NewClient(jenkins.Credentials{URL: server.URL})))
+	view := m.View()
+	for _, want := range []string{"#1", "platform » deploy", "api"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not show %q:\n%s", want, view)
+		}
+	}
+	if !m.HasRunningBuilds() {
+		t.Error("HasRunningBuilds() = false with a build running")
+	}
+}
+
+func TestPollErrorKeepsPolling(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetRunningBuildsFunc: func(context.Context) ([]jenkins.RunningBuild, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	m := poll(t, New(client))
+	if !strings.Contains(m.View(), "connection refused") {
+		t.Errorf("view does not show the poll error:\n%s", m.View())
+	}
+	if len(client.CallsTo("GetBuildQueue")) != 1 {
+		t.Errorf("GetBuildQueue called %d times, want once", len(client.CallsTo("GetBuildQueue")))
+	}
+}
+
+func TestPausedSkipsPollsAndResumes(t *testing.T) {
+	client := &jenkinstest.Client{}
+	m := poll(t, New(client))
+
+	m, _ = m.Update(activity.PausedChangedMsg{Paused: true})
+	if _, cmd := m.Update(pollQueueMsg{ticket: m.pollTicket.Current()}); cmd != nil {
+		t.Error("scheduled poll ran while paused")
+	}
+
+	m, cmd := m.Update(activity.PausedChangedMsg{Paused: false})
+	if cmd == nil {
+		t.Fatal("resuming did not poll")
+	}
+	m.Update(cmd())
+	if len(client.CallsTo("GetBuildQueue")) != 2 {
+		t.Errorf("GetBuildQueue called %d times, want twice", len(client.CallsTo("GetBuildQueue")))
+	}
+}
+
+func TestCancelQueueItemAsksFirst(t *testing.T) {
+	var item jenkins.QueueItem
+	item.ID = 42
+	item.Task.Name = "api"
+	item.Task.URL = "https://jenkins.example.com/job/api/"
+	client := &jenkinstest.Client{
+		GetBuildQueueFunc: func(context.Context) ([]jenkins.QueueItem, error) { return []jenkins.QueueItem{item}, nil },
+	}
+	m := poll(t, New(client))
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if cmd != nil || !strings.Contains(m.View(), "Remove api from the queue?") {
+		t.Fatalf("x did not ask before cancelling:\n%s", m.View())
+	}
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if cmd == nil {
+		t.Fatal("confirming did not cancel the item")
+	}
+	m, cmd = m.Update(cmd())
+	if calls := client.CallsTo("CancelQueueItem"); len(calls) != 1 || calls[0].Args[0] != 42 {
+		t.Errorf("CancelQueueItem calls = %v, want one for item 42", calls)
+	}
+	if feedback, ok := cmd().(tea.BatchMsg)[0]().(statusbar.FeedbackMsg); !ok || feedback.IsError {
+		t.Errorf("feedback = %+v, want the item removed", feedback)
+	}
+
+	// Esc leaves the item alone.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil || m.confirmCancel != nil || len(client.CallsTo("CancelQueueItem")) != 1 {
+		t.Error("Esc cancelled the item anyway")
+	}
+}
+
+func TestQueueLogFailureIsReportedOnce(t *testing.T) {
+	notADir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notADir, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	log, _ := queuelog.LoadFrom(filepath.Join(notADir, "queue-log.json"))
+
+	var item jenkins.QueueItem
+	item.ID = 7
+	item.Task.Name = "api"
+	item.InQueueSince = time.Now().Add(-time.Minute).UnixMilli()
+	queued := []jenkins.QueueItem{item}
+	client := &jenkinstest.Client{
+		GetBuildQueueFunc: func(context.Context) ([]jenkins.QueueItem, error) { return queued, nil },
+	}
+	m := New(client).WithLog(log)
+	m.polling = false
+
+	// feedback polls once and returns the error feedback the poll produced.
+	feedback := func() []statusbar.FeedbackMsg {
+		t.Helper()
+		var cmd tea.Cmd
+		m, cmd = m.Update(RefreshRequestedMsg{})
+		m, cmd = m.Update(cmd())
+		var found []statusbar.FeedbackMsg
+		if batch, ok := cmd().(tea.BatchMsg); ok {
+			for _, c := range batch {
+				if msg, ok := c().(statusbar.FeedbackMsg); ok {
+					found = append(found, msg)
+				}
+			}
+		}
+		return found
+	}
+
+	if got := feedback(); len(got) != 0 {
+		t.Fatalf("feedback = %v with nothing to log yet", got)
+	}
+	// The item left the queue: logging it fails to save.
+	queued = nil
+	if got := feedback(); len(got) != 1 || !got[0].IsError || !strings.Contains(got[0].Text, "Queue history not saved") {
+		t.Fatalf("feedback = %v, want the failed save reported", got)
+	}
+	queued = []jenkins.QueueItem{item}
+	feedback()
+	queued = nil
+	if got := feedback(); len(got) != 0 {
+		t.Errorf("feedback = %v, want the failure reported only once", got)
+	}
+}
@@ -0,0 +1,366 @@
+// Package queueitem shows one queued item in the bottom panel: the parameters
+// and causes it was enqueued with, its task URL and why it is waiting, with
+// an action to remove it from the queue.
+package queueitem
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/inflight"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/statusbar"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+// ClosedMsg is emitted when the user leaves the item, to show the job
+// details again.
+type ClosedMsg struct{}
+
+// RefreshRequestedMsg asks the view to fetch the item again.
+type RefreshRequestedMsg struct{}
+
+// fetchedMsg carries the item as /queue/item/<id>/api/json returned it.
+type fetchedMsg struct {
+	id   int
+	item *jenkins.QueueItem
+	err  error
+}
+
+// cancelledMsg reports the outcome of removing the item from the queue.
+type cancelledMsg struct {
+	id      int
+	jobName string
+	err     error
+}
+
+// Model is the bottom panel view of one queued item.
+type Model struct {
+	client   jenkins.JenkinsClient
+	ctx      context.Context
+	tracker  *inflight.Tracker
+	policy   confirm.Policy
+	viewport viewport.Model
+
+	// item is the last known state of the shown item. loaded is set once it
+	// was fetched on its own, with the parameters and causes the queue
+	// listing leaves out.
+	item    jenkins.QueueItem
+	loaded  bool
+	loading bool
+	err     error
+	// left is set once a queue poll no longer lists the item.
+	left bool
+
+	confirming bool
+	prompt     confirm.Prompt
+}
+
+// New creates the view with nothing shown.
+func New(client jenkins.JenkinsClient) Model {
+	return Model{
+		client:   client,
+		ctx:      context.Background(),
+		viewport: viewport.New(0, 0),
+	}
+}
+
+// WithContext returns the model fetching with ctx, so the fetch still
+// running stops when it is cancelled.
+func (m Model) WithContext(ctx context.Context) Model {
+	m.ctx = ctx
+	return m
+}
+
+// WithTracker returns the model registering the removal of the item with
+// tracker, so quitting can wait for Jenkins to answer.
+func (m Model) WithTracker(tracker *inflight.Tracker) Model {
+	m.tracker = tracker
+	return m
+}
+
+// WithConfirmPolicy returns the model asking before removing the item as
+// policy requires.
+func (m Model) WithConfirmPolicy(policy confirm.Policy) Model {
+	m.policy = policy
+	return m
+}
+
+// Show switches to item and fetches its details. Showing the item already
+// shown only takes in the newer queue state.
+func (m Model) Show(item jenkins.QueueItem) (Model, tea.Cmd) {
+	if item.ID == m.item.ID && m.loaded {
+		m.applySnapshot(item)
+		m.render()
+		return m, nil
+	}
+	m.item = item
+	m.loaded = false
+	m.loading = true
+	m.err = nil
+	m.left = false
+	m.confirming = false
+	m.viewport.GotoTop()
+	m.render()
+	return m, m.fetchCmd()
+}
+
+// CapturesInput reports whether a type-the-name confirmation is open.
+func (m Model) CapturesInput() bool {
+	return m.confirming && m.prompt.CapturesInput()
+}
+
+// Update handles messages for the view.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.viewport.Width = msg.Width
+		m.viewport.Height = msg.Height
+		m.render()
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+
+	case RefreshRequestedMsg:
+		if m.item.ID == 0 {
+			return m, nil
+		}
+		m.loading = true
+		m.render()
+		return m, m.fetchCmd()
+
+	case fetchedMsg:
+		if msg.id != m.item.ID {
+			return m, nil
+		}
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.item = *msg.item
+			m.loaded = true
+			m.left = m.left || msg.item.Cancelled || msg.item.Executable != nil
+		}
+		m.render()
+		return m, nil
+
+	case cancelledMsg:
+		feedback := statusbar.FeedbackMsg{Text: fmt.Sprintf("✓ Removed %s from the queue", msg.jobName)}
+		if msg.err != nil {
+			feedback = statusbar.FeedbackMsg{Text: fmt.Sprintf("✗ %v", msg.err), IsError: true}
+		} else if msg.id == m.item.ID {
+			m.item.Cancelled = true
+			m.left = true
+			m.render()
+		}
+		return m, tea.Batch(
+			func() tea.Msg { return feedback },
+			func() tea.Msg { return queue.RefreshRequestedMsg{} },
+		)
+
+	case queue.SnapshotMsg:
+		if m.item.ID == 0 || m.left {
+			return m, nil
+		}
+		for _, item := range msg.Queued {
+			if item.ID == m.item.ID {
+				m.applySnapshot(item)
+				m.render()
+				return m, nil
+			}
+		}
+		// Gone from the queue: fetch it once more to learn whether it started
+		// or was cancelled.
+		m.left = true
+		m.render()
+		return m, m.fetchCmd()
+	}
+	return m, nil
+}
+
+func (m Model) handleKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.confirming {
+		prompt, result := m.prompt.HandleKey(msg)
+		m.prompt = prompt
+		switch result {
+		case confirm.Confirmed:
+			m.confirming = false
+			m.render()
+			return m, m.cancelCmd()
+		case confirm.Cancelled:
+			m.confirming = false
+		}
+		m.render()
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "x":
+		if m.item.ID == 0 || m.left {
+			return m, nil
+		}
+		level := m.policy.LevelFor(confirm.ActionCancelQueue, m.item.GetJobFullName())
+		if level == confirm.None {
+			return m, m.cancelCmd()
+		}
+		m.confirming = true
+		m.prompt = confirm.NewPrompt(level, fmt.Sprintf("Remove %s from the queue?", m.item.GetJobName()), m.item.GetJobName())
+		m.render()
+		return m, nil
+	case "esc":
+		return m, func() tea.Msg { return ClosedMsg{} }
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// applySnapshot takes in the state a queue poll reported for the item,
+// keeping the parameters and causes only a fetch returns.
+func (m *Model) applySnapshot(item jenkins.QueueItem) {
+	m.item.Blocked = item.Blocked
+	m.item.Buildable = item.Buildable
+	m.item.Stuck = item.Stuck
+	m.item.Why = item.Why
+}
+
+func (m Model) fetchCmd() tea.Cmd {
+	client, ctx, id := m.client, m.ctx, m.item.ID
+	return func() tea.Msg {
+		item, err := client.GetQueueItem(ctx, id)
+		return fetchedMsg{id: id, item: item, err: err}
+	}
+}
+
+func (m Model) cancelCmd() tea.Cmd {
+	client, item := m.client, m.item
+	ctx, done := m.tracker.Start(fmt.Sprintf("Remove %s from the queue", item.GetJobFullName()))
+	return func() tea.Msg {
+		err := client.CancelQueueItem(ctx, item.ID)
+		done(err)
+		return cancelledMsg{id: item.ID, jobName: item.GetJobName(), err: err}
+	}
+}
+
+// TitleBar describes the panel header for the shown item.
+func (m Model) TitleBar() ui.TitleBar {
+	bar := ui.TitleBar{Name: "Queued: " + m.item.GetJobName()}
+	if m.loading {
+		bar.Chips = append(bar.Chips, "loading")
+	}
+	if m.confirming {
+		bar.Chips = append(bar.Chips, "confirm")
+	}
+	return bar
+}
+
+// View renders the item.
+func (m Model) View() string {
+	return m.viewport.View()
+}
+
+func (m *Model) render() {
+	m.viewport.SetContent(m.content())
+}
+
+func (m Model) content() string {
+	var b strings.Builder
+	item := m.item
+
+	b.WriteString(ui.TitleStyle.Render(item.GetJobFullName()))
+	b.WriteString("\n")
+
+	state := fmt.Sprintf("Queue item #%d · waiting %s", item.ID, utils.FormatDuration(item.GetInQueueDuration()))
+	switch {
+	case item.Cancelled:
+		state = fmt.Sprintf("Queue item #%d · cancelled", item.ID)
+	case item.Executable != nil:
+		state = fmt.Sprintf("Queue item #%d · started as #%d", item.ID, item.Executable.Number)
+	case m.left:
+		state = fmt.Sprintf("Queue item #%d · left the queue", item.ID)
+	case item.Stuck:
+		state += " · " + ui.FailedStyle.Render("stuck")
+	case item.Blocked:
+		state += " · " + ui.FailedStyle.Render("blocked")
+	case item.Buildable:
+		state += " · waiting for an executor"
+	}
+	b.WriteString(state)
+	b.WriteString("\n")
+
+	if why := strings.TrimSpace(item.Why); why != "" && !m.left {
+		b.WriteString(ui.SubtleStyle.Render("Why: ") + why + "\n")
+	}
+	if item.Task.URL != "" {
+		b.WriteString(ui.SubtleStyle.Render("Task: ") + item.Task.URL + "\n")
+	}
+
+	if m.err != nil {
+		b.WriteString("\n")
+		b.WriteString(ui.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n")
+	}
+
+	if m.loaded {
+		if causes := causeLines(item.GetCauses()); len(causes) > 0 {
+			b.WriteString(ui.SubtleStyle.Render("Caused by: ") + strings.Join(causes, "; ") + "\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(ui.HighlightStyle.Render("Parameters"))
+		b.WriteString("\n")
+		params := item.GetParameters()
+		if len(params) == 0 {
+			b.WriteString(ui.SubtleStyle.Render("  No parameters"))
+			b.WriteString("\n")
+		}
+		for _, param := range params {
+			value := ""
+			if param.Value != nil {
+				value = fmt.Sprint(param.Value)
+			}
+			fmt.Fprintf(&b, "  %s = %s\n", param.Name, value)
+		}
+	} else if m.loading {
+		b.WriteString("\n")
+		b.WriteString(ui.SubtleStyle.Render("Loading parameters..."))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.confirming {
+		b.WriteString(ui.HighlightStyle.Render(m.prompt.View()))
+	} else if m.left {
+		b.WriteString(ui.SubtleStyle.Render("[Esc] Back to details"))
+	} else {
+		b.WriteString(ui.SubtleStyle.Render("[x] Remove from queue  [Esc] Back to details"))
+	}
+	return b.String()
+}
+
+// causeLines describes each cause once, preferring who started it.
+func causeLines(causes []jenkins.BuildCause) []string {
+	var lines []string
+	seen := make(map[string]bool)
+	for _, cause := range causes {
+		line := cause.ShortDescription
+		if line == "" {
+			line = cause.UserName
+		}
+		if line == "" {
+			line = cause.UserID
+		}
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,87 @@
+package queueitem
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gorbach/jdash/internal/confirm"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jenkins/jenkinstest"
+	"github.com/gorbach/jdash/internal/queue"
+	"github.com/gorbach/jdash/internal/statusbar"
+)
+
+func queuedItem() jenkins.QueueItem {
+	item := jenkins.QueueItem{ID: 42, Buildable: true, Why: "Waiting for next available executor"}
+	item.Task.Name = "deploy"
+	item.Task.URL = "http://jenkins/job/platform/job/deploy/"
+	return item
+}
+
+func TestShowFetchesParametersAndCauses(t *testing.T) {
+	client := &jenkinstest.Client{
+		GetQueueItemFunc: func(_ context.Context, id int) (*jenkins.QueueItem, error) {
+			item := queuedItem()
+			item.Actions = []jenkins.BuildAction{
+				{Parameters: []jenkins.BuildParameter{{Name: "ENV", Value: "staging"}, {Name: "DRY_RUN", Value: true}}},
+				{Causes: []jenkins.BuildCause{{ShortDescription: "Started by user Alice", UserID: "alice"}}},
+			}
+			return &item, nil
+		},
+	}
+
+	m, _ := New(client).Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	m, cmd := m.Show(queuedItem())
+	if cmd == nil {
+		t.Fatal("Show did not fetch the item")
+	}
+	m, _ = m.Update(cmd())
+
+	view := m.View()
+	for _, want := range []string{"platform/deploy", "Queue item #42", "http://jenkins/job/platform/job/deploy/",
+		"ENV = staging", "DRY_RUN = true", "Started by user Alice", "Waiting for next available executor"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not show %q:\n%s", want, view)
+		}
+	}
+	if calls := client.CallsTo("GetQueueItem"); len(calls) != 1 {
+		t.Errorf("GetQueueItem called %d times, want once", len(calls))
+	}
+}
+
+func TestCancelRemovesItemAndRefreshesQueue(t *testing.T) {
+	client := &jenkinstest.Client{}
+	m, _ := New(client).WithConfirmPolicy(confirm.Default()).Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	m, _ = m.Show(queuedItem())
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if cmd != nil || !strings.Contains(m.View(), "Remove deploy from the queue?") {
+		t.Fatalf("x did not ask before removing the item:\n%s", m.View())
+	}
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if cmd == nil {
+		t.Fatal("confirming did not cancel the item")
+	}
+	m, cmd = m.Update(cmd())
+	if calls := client.CallsTo("CancelQueueItem"); len(calls) != 1 {
+		t.Fatalf("CancelQueueItem called %d times, want once", len(calls))
+	}
+
+	var refreshed, feedback bool
+	for _, msg := range cmd().(tea.BatchMsg) {
+		switch msg := msg().(type) {
+		case queue.RefreshRequestedMsg:
+			refreshed = true
+		case statusbar.FeedbackMsg:
+			feedback = !msg.IsError
+		}
+	}
+	if !refreshed || !feedback {
+		t.Errorf("cancel refreshed queue = %v, reported success = %v; want both", refreshed, feedback)
+	}
+	if !strings.Contains(m.View(), "cancelled") {
+		t.Errorf("view does not show the item was cancelled:\n%s", m.View())
+	}
+}
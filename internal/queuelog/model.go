@@ -0,0 +1,245 @@
+package queuelog
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+const (
+	maxModalWidth  = 100
+	minModalWidth  = 40
+	modalChromeRow = 10
+	defaultRows    = 20
+)
+
+// ClosedMsg is emitted when the user dismisses the queue history.
+type ClosedMsg struct{}
+
+// ExportRequestedMsg asks the app to export the logged entries as CSV.
+type ExportRequestedMsg struct {
+	Entries []Entry
+}
+
+// Summary describes the waits of the builds that were seen starting.
+type Summary struct {
+	Started int
+	Left    int
+	Median  time.Duration
+	P90     time.Duration
+	Max     time.Duration
+	// Since is when the oldest entry was queued.
+	Since time.Time
+}
+
+// Summarize computes the wait statistics of entries.
+func Summarize(entries []Entry) Summary {
+	var summary Summary
+	var waits []time.Duration
+	for _, entry := range entries {
+		if summary.Since.IsZero() || entry.EnqueuedAt.Before(summary.Since) {
+			summary.Since = entry.EnqueuedAt
+		}
+		if entry.Outcome != Started {
+			summary.Left++
+			continue
+		}
+		waits = append(waits, entry.Wait())
+	}
+	summary.Started = len(waits)
+	if len(waits) == 0 {
+		return summary
+	}
+	slices.Sort(waits)
+	summary.Median = waits[len(waits)/2]
+	summary.P90 = waits[(len(waits)*9)/10]
+	summary.Max = waits[len(waits)-1]
+	return summary
+}
+
+// Model is a modal listing logged queue waits, most recent first.
+type Model struct {
+	entries []Entry
+	summary Summary
+
+	cursor int
+	// top is the first visible row.
+	top int
+
+	width  int
+	height int
+}
+
+// New creates a queue history modal for entries, which are listed as given.
+func New(entries []Entry) *Model {
+	return &Model{entries: entries, summary: Summarize(entries)}
+}
+
+// Init implements tea.Model.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles TEA messages for the modal.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.ensureCursorVisible()
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "H":
+		return m, func() tea.Msg { return ClosedMsg{} }
+	case "E":
+		if len(m.entries) == 0 {
+			return m, nil
+		}
+		entries := m.entries
+		return m, func() tea.Msg { return ExportRequestedMsg{Entries: entries} }
+	case "down", "j":
+		m.moveCursor(1)
+	case "up", "k":
+		m.moveCursor(-1)
+	case "pgdown", "ctrl+d":
+		m.moveCursor(m.visibleRows())
+	case "pgup", "ctrl+u":
+		m.moveCursor(-m.visibleRows())
+	case "home", "g":
+		m.moveCursor(-len(m.entries))
+	case "end", "G":
+		m.moveCursor(len(m.entries))
+	}
+	return m, nil
+}
+
+func (m *Model) moveCursor(delta int) {
+	m.cursor += delta
+	if m.cursor > len(m.entries)-1 {
+		m.cursor = len(m.entries) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	m.ensureCursorVisible()
+}
+
+func (m *Model) ensureCursorVisible() {
+	rows := m.visibleRows()
+	if m.cursor < m.top {
+		m.top = m.cursor
+	}
+	if m.cursor >= m.top+rows {
+		m.top = m.cursor - rows + 1
+	}
+}
+
+// View renders the modal.
+func (m *Model) View() string {
+	var content strings.Builder
+	content.WriteString(ui.TitleStyle.Render("Queue History"))
+	content.WriteString("\n")
+	content.WriteString(ui.SubtleStyle.Render(m.summaryLabel()))
+	content.WriteString("\n\n")
+
+	if len(m.entries) == 0 {
+		content.WriteString(ui.SubtleStyle.Render("Nothing logged yet; waits are recorded while the dashboard runs"))
+		content.WriteString("\n")
+	} else {
+		content.WriteString(ui.SubtleStyle.Render(fmt.Sprintf("%-12s %-12s %8s  %s", "QUEUED", "STARTED", "WAIT", "JOB")))
+		content.WriteString("\n")
+	}
+
+	rows := m.visibleRows()
+	end := min(m.top+rows, len(m.entries))
+	for i := m.top; i < end; i++ {
+		line := renderEntry(m.entries[i])
+		if i == m.cursor {
+			line = ui.SelectedStyle.Render(line)
+		}
+		content.WriteString(line)
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(ui.SubtleStyle.Render("[j/k] Move  [E] Export CSV  [Esc] Close"))
+
+	panel := lipgloss.NewStyle().
+		Width(m.modalWidth()).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(content.String())
+
+	if m.width == 0 || m.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
+}
+
+// summaryLabel reads e.g. "128 started since Oct 2 · wait median 14s, p90 3m 2s, max 21m 40s · 3 left unseen".
+func (m *Model) summaryLabel() string {
+	s := m.summary
+	if s.Started == 0 && s.Left == 0 {
+		return "0 builds"
+	}
+	parts := []string{fmt.Sprintf("%d started since %s", s.Started, s.Since.Format("Jan 2"))}
+	if s.Started > 0 {
+		parts = append(parts, fmt.Sprintf("wait median %s, p90 %s, max %s",
+			utils.FormatDuration(s.Median), utils.FormatDuration(s.P90), utils.FormatDuration(s.Max)))
+	}
+	if s.Left > 0 {
+		parts = append(parts, fmt.Sprintf("%d left unseen", s.Left))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func renderEntry(entry Entry) string {
+	job := entry.Job
+	if entry.Build > 0 {
+		job += fmt.Sprintf(" #%d", entry.Build)
+	}
+	if entry.Outcome != Started {
+		job += " " + ui.SubtleStyle.Render("(left unseen)")
+	}
+	return fmt.Sprintf("%-12s %-12s %8s  %s",
+		entry.EnqueuedAt.Local().Format("Jan 02 15:04"),
+		entry.StartedAt.Local().Format("Jan 02 15:04"),
+		utils.FormatDuration(entry.Wait()),
+		job,
+	)
+}
+
+func (m *Model) visibleRows() int {
+	if m.height == 0 {
+		return defaultRows
+	}
+	rows := m.height - modalChromeRow - 4
+	if rows < 3 {
+		rows = 3
+	}
+	return rows
+}
+
+func (m *Model) modalWidth() int {
+	width := m.width - 10
+	if width > maxModalWidth {
+		width = maxModalWidth
+	}
+	if width < minModalWidth {
+		width = minModalWidth
+	}
+	return width
+}
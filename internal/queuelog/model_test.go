@@ -0,0 +1,86 @@
+package queuelog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func loggedEntries(n int) []Entry {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := make([]Entry, n)
+	for i := range entries {
+		queuedAt := start.Add(time.Duration(i) * time.Minute)
+		entries[i] = Entry{
+			ID:         i + 1,
+			Job:        fmt.Sprintf("api-%d", i),
+			Build:      i + 1,
+			EnqueuedAt: queuedAt,
+			StartedAt:  queuedAt.Add(time.Duration(i+1) * 10 * time.Second),
+			Outcome:    Started,
+		}
+	}
+	entries[n-1].Outcome = Left
+	entries[n-1].Build = 0
+	return entries
+}
+
+func TestModalListsWaitsAndSummary(t *testing.T) {
+	m := New(loggedEntries(4))
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	view := m.View()
+	for _, want := range []string{
+		"3 started since Mar 1",
+		"wait median 20s, p90 30s, max 30s",
+		"1 left unseen",
+		"api-0 #1",
+		"api-3 (left unseen)",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not show %q:\n%s", want, view)
+		}
+	}
+
+	if !strings.Contains(New(nil).View(), "Nothing logged yet") {
+		t.Error("an empty log does not say nothing was logged")
+	}
+}
+
+func TestModalScrollsAndExports(t *testing.T) {
+	entries := loggedEntries(40)
+	m := New(entries)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	rows := m.visibleRows()
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if m.cursor != len(entries)-1 || m.top != len(entries)-rows {
+		t.Errorf("G: cursor %d, top %d; want %d, %d", m.cursor, m.top, len(entries)-1, len(entries)-rows)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	if m.cursor != 0 || m.top != 0 {
+		t.Errorf("g then k: cursor %d, top %d; want the first row", m.cursor, m.top)
+	}
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	if cmd == nil {
+		t.Fatal("E did not export")
+	}
+	if msg, ok := cmd().(ExportRequestedMsg); !ok || len(msg.Entries) != len(entries) {
+		t.Errorf("E sent %T, want every entry exported", cmd())
+	}
+	if _, cmd := New(nil).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")}); cmd != nil {
+		t.Error("E exported an empty log")
+	}
+
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("Esc did not close the modal")
+	}
+	if _, ok := cmd().(ClosedMsg); !ok {
+		t.Errorf("Esc sent %T, want ClosedMsg", cmd())
+	}
+}
@@ -0,0 +1,193 @@
+// Package queuelog keeps a rolling local log of how long builds waited in the
+// Jenkins queue. It is filled passively from the queue panel's polls, so
+// capacity discussions can rest on real wait times rather than impressions.
+package queuelog
+
+import (
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/jsonstore"
+)
+
+const fileName = "queue-log.json"
+
+const (
+	// maxEntries caps the log; the oldest entries are dropped first.
+	maxEntries = 5000
+	// maxAge is how long an entry is kept.
+	maxAge = 30 * 24 * time.Hour
+)
+
+// Outcomes of a queue item.
+const (
+	// Started means a build of the job was seen starting after the item was queued.
+	Started = "started"
+	// Left means the item left the queue without a build being seen, e.g. it
+	// was cancelled or its build finished between two polls.
+	Left = "left"
+)
+
+// Entry is one item's stay in the queue.
+type Entry struct {
+	ID  int    `json:"id"`
+	Job string `json:"job"`
+	// URL is the job's URL; it tells servers apart.
+	URL        string    `json:"url"`
+	Build      int       `json:"build,omitempty"`
+	EnqueuedAt time.Time `json:"enqueuedAt"`
+	// StartedAt is when the build started or, for items that Left, the
+	// poll that first missed them.
+	StartedAt time.Time `json:"startedAt"`
+	Outcome   string    `json:"outcome"`
+}
+
+// Wait is how long the item spent in the queue.
+func (e Entry) Wait() time.Duration {
+	wait := e.StartedAt.Sub(e.EnqueuedAt)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// Log records queue waits in memory and persists finished entries to the
+// config directory. It is safe for concurrent use.
+type Log struct {
+	mu      sync.RWMutex
+	file    *jsonstore.File
+	entries []Entry // oldest first
+	// pending are the items in the queue as of the last poll, by ID.
+	pending map[int]jenkins.QueueItem
+}
+
+type logFile struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Load reads the log from queue-log.json in the config directory.
+func Load() (*Log, error) {
+	return LoadFrom(filepath.Join(auth.ConfigDir(), fileName))
+}
+
+// LoadFrom reads the log from path. Without a readable file the log starts
+// empty.
+func LoadFrom(path string) (*Log, error) {
+	var saved logFile
+	file, err := jsonstore.Load(path, &saved)
+	log := &Log{file: file, entries: saved.Entries, pending: make(map[int]jenkins.QueueItem)}
+	sort.SliceStable(log.entries, func(i, j int) bool {
+		return log.entries[i].StartedAt.Before(log.entries[j].StartedAt)
+	})
+	return log, err
+}
+
+// Entries returns the logged entries, most recently started first.
+func (l *Log) Entries() []Entry {
+	if l == nil {
+		return nil
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	entries := slices.Clone(l.entries)
+	slices.Reverse(entries)
+	return entries
+}
+
+// Observe compares a queue poll taken at now with the previous one. Items
+// that left the queue since are logged, matched to the running build of
+// their job that started after they were queued, and the log is saved.
+func (l *Log) Observe(queued []jenkins.QueueItem, running []jenkins.RunningBuild, now time.Time) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	current := make(map[int]jenkins.QueueItem, len(queued))
+	for _, item := range queued {
+		if item.InQueueSince > 0 {
+			current[item.ID] = item
+		}
+	}
+
+	var left []jenkins.QueueItem
+	for id, item := range l.pending {
+		if _, ok := current[id]; !ok {
+			left = append(left, item)
+		}
+	}
+	l.pending = current
+	if len(left) == 0 {
+		l.mu.Unlock()
+		return nil
+	}
+
+	// Oldest first, so each takes the earliest build that could be its own.
+	sort.Slice(left, func(i, j int) bool { return left[i].InQueueSince < left[j].InQueueSince })
+	claimed := make(map[string]bool)
+	for _, item := range left {
+		entry := Entry{
+			ID:         item.ID,
+			Job:        item.GetJobFullName(),
+			URL:        item.Task.URL,
+			EnqueuedAt: time.UnixMilli(item.InQueueSince),
+			StartedAt:  now,
+			Outcome:    Left,
+		}
+		if build, ok := startedBuild(item, running, claimed); ok {
+			claimed[build.URL] = true
+			entry.Build = build.BuildNumber
+			entry.StartedAt = time.UnixMilli(build.StartTime)
+			entry.Outcome = Started
+		}
+		l.entries = append(l.entries, entry)
+	}
+	l.prune(now)
+	l.mu.Unlock()
+	return l.save()
+}
+
+// startedBuild finds the earliest unclaimed running build of item's job that
+// started after it was queued.
+func startedBuild(item jenkins.QueueItem, running []jenkins.RunningBuild, claimed map[string]bool) (jenkins.RunningBuild, bool) {
+	var found jenkins.RunningBuild
+	ok := false
+	for _, build := range running {
+		if claimed[build.URL] || build.JobURL() != item.Task.URL || build.StartTime < item.InQueueSince {
+			continue
+		}
+		if !ok || build.StartTime < found.StartTime {
+			found, ok = build, true
+		}
+	}
+	return found, ok
+}
+
+// prune drops entries older than maxAge and the oldest beyond maxEntries.
+// The caller holds the write lock.
+func (l *Log) prune(now time.Time) {
+	sort.SliceStable(l.entries, func(i, j int) bool {
+		return l.entries[i].StartedAt.Before(l.entries[j].StartedAt)
+	})
+	cutoff := now.Add(-maxAge)
+	first := sort.Search(len(l.entries), func(i int) bool {
+		return !l.entries[i].StartedAt.Before(cutoff)
+	})
+	if over := len(l.entries) - first - maxEntries; over > 0 {
+		first += over
+	}
+	l.entries = slices.Delete(l.entries, 0, first)
+}
+
+func (l *Log) save() error {
+	return l.file.Save(func() any {
+		l.mu.RLock()
+		defer l.mu.RUnlock()
+		return logFile{Entries: slices.Clone(l.entries)}
+	})
+}
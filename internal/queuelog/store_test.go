@@ -0,0 +1,85 @@
+package queuelog
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+const jobURL = "https://ci.example.com/job/api/"
+
+func queued(id int, since time.Time) jenkins.QueueItem {
+	item := jenkins.QueueItem{ID: id, InQueueSince: since.UnixMilli()}
+	item.Task.Name = "api"
+	item.Task.URL = jobURL
+	return item
+}
+
+func TestObserveLogsWaits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue-log.json")
+	log, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() on missing file error: %v", err)
+	}
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Two builds of api queue up; one starts, the other is cancelled.
+	if err := log.Observe([]jenkins.QueueItem{queued(1, start), queued(2, start.Add(time.Second))}, nil, start.Add(3*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	running := []jenkins.RunningBuild{
+		// A build that started before the items were queued is not theirs.
+		{JobName: "api #41", BuildNumber: 41, URL: jobURL + "41/", StartTime: start.Add(-time.Minute).UnixMilli()},
+		{JobName: "api #42", BuildNumber: 42, URL: jobURL + "42/", StartTime: start.Add(95 * time.Second).UnixMilli()},
+	}
+	if err := log.Observe(nil, running, start.Add(97*time.Second)); err != nil {
+		t.Fatalf("Observe() error: %v", err)
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error: %v", err)
+	}
+	entries := reloaded.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("logged %d entries, want 2: %+v", len(entries), entries)
+	}
+	cancelled, started := entries[0], entries[1]
+	if started.ID != 1 || started.Outcome != Started || started.Build != 42 || started.Wait() != 95*time.Second || started.Job != "api" {
+		t.Errorf("first item = %+v, want api #42 started after 95s", started)
+	}
+	if cancelled.ID != 2 || cancelled.Outcome != Left || cancelled.Wait() != 96*time.Second {
+		t.Errorf("second item = %+v, want it left unseen when the poll missed it", cancelled)
+	}
+
+	summary := Summarize(entries)
+	if summary.Started != 1 || summary.Left != 1 || summary.Median != 95*time.Second || !summary.Since.Equal(start) {
+		t.Errorf("Summarize() = %+v", summary)
+	}
+}
+
+func TestObservePrunes(t *testing.T) {
+	log, err := LoadFrom(filepath.Join(t.TempDir(), "queue-log.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-maxAge - time.Hour)
+	log.entries = []Entry{{ID: 1, EnqueuedAt: old, StartedAt: old}}
+	for i := 0; i < maxEntries; i++ {
+		log.entries = append(log.entries, Entry{ID: 100 + i, StartedAt: now.Add(-time.Duration(maxEntries-i) * time.Second)})
+	}
+
+	if err := log.Observe([]jenkins.QueueItem{queued(2, now)}, nil, now); err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Observe(nil, nil, now.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	entries := log.Entries()
+	if len(entries) != maxEntries || entries[0].ID != 2 || entries[len(entries)-1].ID != 101 {
+		t.Errorf("kept %d entries from #%d to #%d, want the newest %d", len(entries), entries[len(entries)-1].ID, entries[0].ID, maxEntries)
+	}
+}
@@ -0,0 +1,156 @@
+// Package release tells the user about jdash itself: an About overlay with
+// the running version, and an opt-in, once-a-day check of the GitHub releases
+// whose changelogs the overlay then shows.
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gorbach/jdash/internal/auth"
+)
+
+const (
+	// releasesURL lists the published releases, newest first.
+	releasesURL = "https://api.github.com/repos/gorbach/jdash/releases?per_page=20"
+	// checkInterval is how long an answer from GitHub is reused.
+	checkInterval = 24 * time.Hour
+	fileName      = "version-check.json"
+)
+
+// Info identifies the running binary, as set by the release build.
+type Info struct {
+	Version string
+	Commit  string
+	Date    string
+}
+
+// Release is a published jdash release.
+type Release struct {
+	Tag         string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	URL         string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+}
+
+// Checker asks GitHub for the releases at most once per checkInterval,
+// keeping the answer in the config directory in between.
+type Checker struct {
+	path       string
+	url        string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+type checkFile struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Releases  []Release `json:"releases"`
+}
+
+// NewChecker creates a checker caching its answers in the default location.
+func NewChecker() *Checker {
+	return &Checker{
+		path:       filepath.Join(auth.ConfigDir(), fileName),
+		url:        releasesURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// Newer returns the stable releases newer than current, newest first.
+// Development builds, whose version isn't a release tag, never check.
+func (c *Checker) Newer(ctx context.Context, current string) ([]Release, error) {
+	if _, ok := parseVersion(current); !ok {
+		return nil, nil
+	}
+	releases, err := c.releases(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var newer []Release
+	for _, release := range releases {
+		if release.Draft || release.Prerelease {
+			continue
+		}
+		if compareVersions(release.Tag, current) > 0 {
+			newer = append(newer, release)
+		}
+	}
+	sort.SliceStable(newer, func(i, j int) bool {
+		return compareVersions(newer[i].Tag, newer[j].Tag) > 0
+	})
+	return newer, nil
+}
+
+// releases returns the cached releases while they are fresh, and fetches
+// them from GitHub otherwise.
+func (c *Checker) releases(ctx context.Context) ([]Release, error) {
+	// A missing or corrupt cache just means asking GitHub again.
+	var cached checkFile
+	if data, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(data, &cached)
+	}
+	now := c.now()
+	if !cached.CheckedAt.IsZero() && now.Sub(cached.CheckedAt) < checkInterval && now.After(cached.CheckedAt) {
+		return cached.Releases, nil
+	}
+
+	releases, err := c.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Failing to cache only costs another request tomorrow.
+	_ = c.save(checkFile{CheckedAt: now, Releases: releases})
+	return releases, nil
+}
+
+func (c *Checker) fetch(ctx context.Context) ([]Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for a new version: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("failed to check for a new version: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to decode releases: %w", err)
+	}
+	return releases, nil
+}
+
+func (c *Checker) save(file checkFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
@@ -0,0 +1,76 @@
+package release
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.4.0", "v1.3.9", 1},
+		{"1.4.0", "v1.4.0", 0},
+		{"v1.10.0", "v1.9.2", 1},
+		{"v2", "v1.99.99", 1},
+		{"v1.5.0-rc.1", "v1.5.0", -1},
+		{"v1.5.0-rc.2", "v1.5.0-rc.1", 1},
+		{"dev", "v0.1.0", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCheckerNewer(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`[
+			{"tag_name": "v1.6.0-rc.1", "prerelease": true},
+			{"tag_name": "v1.5.0", "body": "Faster search", "html_url": "https://github.com/gorbach/jdash/releases/tag/v1.5.0"},
+			{"tag_name": "v1.4.1", "body": "Fixes"},
+			{"tag_name": "v1.4.0"},
+			{"tag_name": "v1.7.0", "draft": true}
+		]`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	checker := &Checker{
+		path:       filepath.Join(t.TempDir(), fileName),
+		url:        server.URL,
+		httpClient: server.Client(),
+		now:        func() time.Time { return now },
+	}
+
+	newer, err := checker.Newer(context.Background(), "v1.4.0")
+	if err != nil {
+		t.Fatalf("Newer() error = %v", err)
+	}
+	if len(newer) != 2 || newer[0].Tag != "v1.5.0" || newer[1].Tag != "v1.4.1" {
+		t.Fatalf("Newer() = %+v, want v1.5.0 and v1.4.1", newer)
+	}
+
+	// The answer is reused for a day.
+	now = now.Add(23 * time.Hour)
+	if newer, _ := checker.Newer(context.Background(), "v1.4.1"); len(newer) != 1 || requests != 1 {
+		t.Errorf("within a day: Newer() = %+v after %d requests, want v1.5.0 from the cache", newer, requests)
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := checker.Newer(context.Background(), "v1.5.0"); err != nil || requests != 2 {
+		t.Errorf("a day later: %d requests, %v; want GitHub asked again", requests, err)
+	}
+
+	// Development builds never ask.
+	if newer, err := checker.Newer(context.Background(), "dev"); newer != nil || err != nil || requests != 2 {
+		t.Errorf("dev build: Newer() = %+v, %v after %d requests", newer, err, requests)
+	}
+}
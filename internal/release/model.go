@@ -0,0 +1,156 @@
+package release
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+const (
+	maxModalWidth = 90
+	minModalWidth = 40
+	// modalChrome is the border, padding, header and footer around the changelog.
+	modalChrome = 14
+)
+
+// ClosedMsg is emitted when the user dismisses the About overlay.
+type ClosedMsg struct{}
+
+// Model is the About overlay: the running version and, when the version
+// check found newer releases, their changelogs.
+type Model struct {
+	info      Info
+	serverURL string
+	newer     []Release
+	checkErr  error
+
+	changelog viewport.Model
+	width     int
+	height    int
+}
+
+// New creates the About overlay. newer are the releases the version check
+// found, newest first, and checkErr why the last check failed, if it did.
+func New(info Info, serverURL string, newer []Release, checkErr error) *Model {
+	m := &Model{info: info, serverURL: serverURL, newer: newer, checkErr: checkErr}
+	m.changelog = viewport.New(minModalWidth, 5)
+	m.changelog.SetContent(renderChangelog(newer, minModalWidth))
+	return m
+}
+
+// Init implements tea.Model.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles TEA messages for the overlay.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.changelog.Width = m.modalWidth() - 6
+		m.changelog.Height = max(msg.Height-modalChrome, 3)
+		m.changelog.SetContent(renderChangelog(m.newer, m.changelog.Width))
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "A":
+			return m, func() tea.Msg { return ClosedMsg{} }
+		case "g", "home":
+			m.changelog.GotoTop()
+			return m, nil
+		case "G", "end":
+			m.changelog.GotoBottom()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.changelog, cmd = m.changelog.Update(msg)
+	return m, cmd
+}
+
+// View renders the overlay.
+func (m *Model) View() string {
+	var content strings.Builder
+	content.WriteString(ui.TitleStyle.Render("About jdash"))
+	content.WriteString("\n\n")
+	fmt.Fprintf(&content, "Version   %s\n", m.info.Version)
+	if m.info.Commit != "" && m.info.Commit != "none" {
+		fmt.Fprintf(&content, "Commit    %s\n", m.info.Commit)
+	}
+	if m.info.Date != "" && m.info.Date != "unknown" {
+		fmt.Fprintf(&content, "Built     %s\n", m.info.Date)
+	}
+	fmt.Fprintf(&content, "Go        %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if m.serverURL != "" {
+		fmt.Fprintf(&content, "Jenkins   %s\n", m.serverURL)
+	}
+	content.WriteString("\n")
+
+	switch {
+	case len(m.newer) > 0:
+		content.WriteString(ui.HighlightStyle.Render(fmt.Sprintf("%s is available: %s", m.newer[0].Tag, m.newer[0].URL)))
+		content.WriteString("\n\n")
+		content.WriteString(m.changelog.View())
+		content.WriteString("\n\n")
+		content.WriteString(ui.SubtleStyle.Render("[j/k] Scroll changelog  [Esc] Close"))
+	case m.checkErr != nil:
+		content.WriteString(ui.ErrorStyle.Render(fmt.Sprintf("Version check failed: %v", m.checkErr)))
+		content.WriteString("\n\n")
+		content.WriteString(ui.SubtleStyle.Render("[Esc] Close"))
+	default:
+		content.WriteString(ui.SubtleStyle.Render("No newer release known. Set \"checkForUpdates\" under ui to check once a day."))
+		content.WriteString("\n\n")
+		content.WriteString(ui.SubtleStyle.Render("[Esc] Close"))
+	}
+
+	panel := lipgloss.NewStyle().
+		Width(m.modalWidth()).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(content.String())
+
+	if m.width == 0 || m.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
+}
+
+// renderChangelog lists the release notes of newer, newest first, wrapped
+// to width.
+func renderChangelog(newer []Release, width int) string {
+	wrap := lipgloss.NewStyle().Width(width)
+	var b strings.Builder
+	for i, release := range newer {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		title := release.Tag
+		if release.Name != "" && release.Name != release.Tag {
+			title += " — " + release.Name
+		}
+		if !release.PublishedAt.IsZero() {
+			title += " (" + release.PublishedAt.Format("Jan 2, 2006") + ")"
+		}
+		b.WriteString(ui.TitleStyle.Render(title))
+		b.WriteString("\n")
+		body := strings.TrimSpace(strings.ReplaceAll(release.Body, "\r\n", "\n"))
+		if body == "" {
+			body = "No release notes."
+		}
+		b.WriteString(wrap.Render(body))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (m *Model) modalWidth() int {
+	return min(max(m.width-10, minModalWidth), maxModalWidth)
+}
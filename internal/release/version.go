@@ -0,0 +1,64 @@
+package release
+
+import (
+	"strconv"
+	"strings"
+)
+
+// version is a parsed release tag such as "v1.4.0" or "1.5.0-rc.1".
+type version struct {
+	parts      [3]int
+	prerelease string
+}
+
+func parseVersion(tag string) (version, bool) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "v")
+	core, prerelease, _ := strings.Cut(tag, "-")
+	core, _, _ = strings.Cut(core, "+")
+	fields := strings.Split(core, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return version{}, false
+	}
+	v := version{prerelease: prerelease}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return version{}, false
+		}
+		v.parts[i] = n
+	}
+	return v, true
+}
+
+// compareVersions orders release tags like semantic versions: -1 if a is
+// older than b, 1 if newer and 0 if equal. Tags that aren't versions sort
+// before every version.
+func compareVersions(a, b string) int {
+	va, okA := parseVersion(a)
+	vb, okB := parseVersion(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return -1
+	case !okB:
+		return 1
+	}
+	for i := range va.parts {
+		if va.parts[i] != vb.parts[i] {
+			if va.parts[i] < vb.parts[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case va.prerelease == vb.prerelease:
+		return 0
+	case va.prerelease == "":
+		return 1
+	case vb.prerelease == "":
+		return -1
+	}
+	return strings.Compare(va.prerelease, vb.prerelease)
+}
@@ -0,0 +1,74 @@
+// Package snippet formats shareable Markdown status lines for jobs and builds
+// and copies them to the clipboard.
+package snippet
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+// Markdown renders a one-line summary of job and, when given, one of its
+// builds, ready to paste into a PR description or chat:
+//
+//	[![Production/api](…/123/badge/icon)](…/123/) **Production/api** [#123](…/123/) ✓ SUCCESS in 4m 12s
+//
+// The badge image is served by the Embeddable Build Status plugin; everything
+// else links straight to Jenkins.
+func Markdown(job jenkins.Job, build *jenkins.Build) string {
+	name := job.FullName
+	if name == "" {
+		name = job.Name
+	}
+
+	link := strings.TrimSuffix(job.URL, "/") + "/"
+	if build != nil && build.URL != "" {
+		link = strings.TrimSuffix(build.URL, "/") + "/"
+	}
+
+	parts := []string{
+		fmt.Sprintf("[![%s](%sbadge/icon)](%s)", name, link, link),
+		fmt.Sprintf("**%s**", name),
+	}
+	if build == nil {
+		status := job.GetStatus()
+		return strings.Join(append(parts, ui.GetStatusIcon(status)+" "+status), " ")
+	}
+
+	status := build.GetStatus()
+	parts = append(parts,
+		fmt.Sprintf("[#%d](%s)", build.Number, link),
+		ui.GetStatusIcon(status)+" "+status,
+	)
+	switch {
+	case build.Building:
+		parts = append(parts, "(running)")
+	case build.Duration > 0:
+		parts = append(parts, "in "+utils.FormatDuration(build.GetDuration()))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Copy puts text on the system clipboard. Without a clipboard utility (e.g.
+// over SSH) it falls back to an OSC 52 escape sequence, which most terminals
+// and tmux forward to the local clipboard.
+func Copy(text string) error {
+	if err := clipboard.WriteAll(text); err == nil {
+		return nil
+	}
+
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	}
+	if _, err := seq.WriteTo(os.Stderr); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package snippet
+
+import (
+	"testing"
+
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+func TestMarkdown(t *testing.T) {
+	job := jenkins.Job{
+		Name:     "api",
+		FullName: "Production/api",
+		URL:      "https://ci.example.com/job/Production/job/api/",
+		Color:    "notbuilt",
+	}
+
+	tests := []struct {
+		name  string
+		build *jenkins.Build
+		want  string
+	}{
+		{
+			name: "finished build",
+			build: &jenkins.Build{
+				Number:   123,
+				Result:   "SUCCESS",
+				Duration: 252000,
+				URL:      "https://ci.example.com/job/Production/job/api/123/",
+			},
+			want: "[![Production/api](https://ci.example.com/job/Production/job/api/123/badge/icon)](https://ci.example.com/job/Production/job/api/123/)" +
+				" **Production/api** [#123](https://ci.example.com/job/Production/job/api/123/) ✓ SUCCESS in 4m 12s",
+		},
+		{
+			name:  "running build",
+			build: &jenkins.Build{Number: 124, Building: true, URL: "https://ci.example.com/job/Production/job/api/124"},
+			want: "[![Production/api](https://ci.example.com/job/Production/job/api/124/badge/icon)](https://ci.example.com/job/Production/job/api/124/)" +
+				" **Production/api** [#124](https://ci.example.com/job/Production/job/api/124/) ⟳ BUILDING (running)",
+		},
+		{
+			name: "job without builds",
+			want: "[![Production/api](https://ci.example.com/job/Production/job/api/badge/icon)](https://ci.example.com/job/Production/job/api/)" +
+				" **Production/api** ○ NEVER_BUILT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Markdown(job, tt.build); got != tt.want {
+				t.Errorf("Markdown() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package snippet
+
+import (
+	"testing"
+
+	"github.com/atotto/clipboard"
+)
+
+// Windows always has a clipboard, so Copy must reach it rather than fall
+// back to OSC 52.
+func TestCopyWindows(t *testing.T) {
+	saved, _ := clipboard.ReadAll()
+	t.Cleanup(func() { _ = clipboard.WriteAll(saved) })
+
+	text := "✓ Production/api #481 passed in 3m 12s\r\nhttps://ci.example.com/job/Production/job/api/481/"
+	if err := Copy(text); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	got, err := clipboard.ReadAll()
+	if err != nil {
+		t.Fatalf("clipboard.ReadAll() error = %v", err)
+	}
+	if got != text {
+		t.Errorf("clipboard = %q, want %q", got, text)
+	}
+}
@@ -7,6 +7,10 @@
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/activity"
+	"github.com/gorbach/jdash/internal/ticket"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
 )
 
 // messageKind allows us to render temporary feedback with basic styling.
@@ -30,8 +34,12 @@ type messageExpiredMsg struct {
 
 type heartbeatMsg struct{}
 
-// RefreshStartedMsg tells the status bar that a global refresh has kicked off.
-type RefreshStartedMsg struct{}
+// RefreshStartedMsg tells the status bar that a refresh has kicked off.
+type RefreshStartedMsg struct {
+	// Scope names the one panel being refreshed, e.g. "queue"; empty is
+	// everything.
+	Scope string
+}
 
 // RefreshFinishedMsg tells the status bar that a refresh completed (successfully or not).
 type RefreshFinishedMsg struct {
@@ -39,6 +47,17 @@ type RefreshFinishedMsg struct {
 	Err      error
 }
 
+// FeedbackMsg shows a transient message in the status bar.
+type FeedbackMsg struct {
+	Text    string
+	IsError bool
+}
+
+// UpdateAvailableMsg tells the status bar that a newer jdash was released.
+type UpdateAvailableMsg struct {
+	Version string
+}
+
 // Model represents the status bar state and rendering logic.
 type Model struct {
 	serverURL string
@@ -47,10 +66,15 @@ type Model struct {
 
 	message       string
 	messageStyle  messageKind
-	messageTicket uint64
+	messageTicket ticket.Counter
 
 	width   int
 	loading bool
+	idle    bool
+	// update is the newer release to point out, if any.
+	update string
+	// readOnly marks a server jdash will not change.
+	readOnly bool
 }
 
 // New creates a new status bar model.
@@ -61,6 +85,12 @@ func New(serverURL string) Model {
 	}
 }
 
+// WithReadOnly returns the model pointing out that the server is read-only.
+func (m Model) WithReadOnly(readOnly bool) Model {
+	m.readOnly = readOnly
+	return m
+}
+
 // Init initializes the model.
 func (m Model) Init() tea.Cmd {
 	return tea.Tick(statusHeartbeatInterval, func(time.Time) tea.Msg {
@@ -76,6 +106,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m, nil
 
 	case RefreshStartedMsg:
+		if msg.Scope != "" {
+			// Only a jobs refresh reports back, so the bar isn't held loading.
+			return m.setMessage(messageInfo, fmt.Sprintf("Refreshing %s only…", msg.Scope))
+		}
 		m.loading = true
 		m.message = ""
 		m.messageStyle = messageNone
@@ -92,8 +126,22 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		}
 		return m.setMessage(messageSuccess, "✓ Refreshed")
 
+	case activity.IdleChangedMsg:
+		m.idle = msg.Idle
+		return m, nil
+
+	case UpdateAvailableMsg:
+		m.update = msg.Version
+		return m, nil
+
+	case FeedbackMsg:
+		if msg.IsError {
+			return m.setMessage(messageError, msg.Text)
+		}
+		return m.setMessage(messageSuccess, msg.Text)
+
 	case messageExpiredMsg:
-		if msg.ticket == m.messageTicket {
+		if m.messageTicket.IsCurrent(msg.ticket) {
 			m.message = ""
 			m.messageStyle = messageNone
 		}
@@ -109,11 +157,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 }
 
 func (m Model) setMessage(kind messageKind, text string) (Model, tea.Cmd) {
-	m.messageTicket++
+	ticket := m.messageTicket.Next()
 	m.message = text
 	m.messageStyle = kind
 
-	ticket := m.messageTicket
 	cmd := tea.Tick(messageDuration, func(time.Time) tea.Msg {
 		return messageExpiredMsg{ticket: ticket}
 	})
@@ -124,8 +171,8 @@ func (m Model) setMessage(kind messageKind, text string) (Model, tea.Cmd) {
 // View renders the status bar.
 func (m Model) View() string {
 	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("0")).
-		Background(lipgloss.Color("12")).
+		Foreground(ui.ColorTextInverse).
+		Background(ui.ColorTitle).
 		Width(m.width).
 		Padding(0, 1)
 
@@ -133,11 +180,22 @@ func (m Model) View() string {
 		"jdash",
 		fmt.Sprintf("Connected: %s", formatServerURL(m.serverURL)),
 	}
+	if m.readOnly {
+		parts = append(parts, "Read-only")
+	}
 
 	if m.loading {
-		parts = append(parts, "Refreshing…")
+		parts = append(parts, "Refreshing all…")
 	} else {
-		parts = append(parts, fmt.Sprintf("%d jobs", m.jobCount))
+		parts = append(parts, fmt.Sprintf("%s jobs", utils.FormatCount(int64(m.jobCount))))
+	}
+
+	if m.idle {
+		parts = append(parts, "Idle: polling slowed")
+	}
+
+	if m.update != "" {
+		parts = append(parts, fmt.Sprintf("%s available (A)", m.update))
 	}
 
 	parts = append(parts, "? for help")
@@ -168,13 +226,13 @@ func renderMessage(text string, kind messageKind) string {
 
 	switch kind {
 	case messageError:
-		style = style.Foreground(lipgloss.Color("1"))
+		style = style.Foreground(ui.ColorFailed)
 	case messageSuccess:
-		style = style.Foreground(lipgloss.Color("10"))
+		style = style.Foreground(ui.ColorSuccess)
 	case messageInfo:
-		style = style.Foreground(lipgloss.Color("11"))
+		style = style.Foreground(ui.ColorBuilding)
 	default:
-		style = style.Foreground(lipgloss.Color("7"))
+		style = style.Foreground(ui.ColorText)
 	}
 
 	return style.Render(text)
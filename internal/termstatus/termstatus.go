@@ -0,0 +1,81 @@
+// Package termstatus publishes a one-line summary of the dashboard to the
+// terminal title and, optionally, to a tmux user option for status lines.
+package termstatus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// TmuxOption is the tmux user option jdash writes, for use in status lines:
+//
+//	set -g status-right '#{@jdash_status}'
+const TmuxOption = "@jdash_status"
+
+// Status is what the terminal title and tmux string summarize.
+type Status struct {
+	// Job is the full name of the selected job, if any.
+	Job string
+	// Failing is the number of jobs whose last build failed.
+	Failing int
+}
+
+// Title renders the terminal window title, e.g. "jdash · Production/api · 3 failing".
+func (s Status) Title() string {
+	parts := []string{"jdash"}
+	if s.Job != "" {
+		parts = append(parts, s.Job)
+	}
+	if s.Failing > 0 {
+		parts = append(parts, fmt.Sprintf("%d failing", s.Failing))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Tmux renders a compact string for a tmux status line, e.g. "✗3 api". Only
+// the last path segment of the job is kept since status lines are narrow.
+func (s Status) Tmux() string {
+	var parts []string
+	if s.Failing > 0 {
+		parts = append(parts, fmt.Sprintf("#[fg=red]✗%d#[default]", s.Failing))
+	} else {
+		parts = append(parts, "#[fg=green]✓#[default]")
+	}
+	if s.Job != "" {
+		name := s.Job
+		if idx := strings.LastIndex(name, "/"); idx >= 0 {
+			name = name[idx+1:]
+		}
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// InTmux reports whether jdash is running inside a tmux session.
+func InTmux() bool {
+	return os.Getenv("TMUX") != ""
+}
+
+// PublishTmux stores text in the TmuxOption of the current tmux server; an
+// empty text unsets it. Outside tmux it does nothing.
+func PublishTmux(text string) error {
+	if !InTmux() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	args := []string{"set-option", "-gq", TmuxOption, text}
+	if text == "" {
+		args = []string{"set-option", "-gqu", TmuxOption}
+	}
+	if out, err := exec.CommandContext(ctx, "tmux", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update tmux status: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package termstatus
+
+import "testing"
+
+func TestStatusTitle(t *testing.T) {
+	tests := []struct {
+		name   string
+		status Status
+		want   string
+	}{
+		{name: "nothing selected", status: Status{}, want: "jdash"},
+		{name: "selected job", status: Status{Job: "Production/api"}, want: "jdash · Production/api"},
+		{name: "failing jobs", status: Status{Job: "Production/api", Failing: 3}, want: "jdash · Production/api · 3 failing"},
+		{name: "failing without selection", status: Status{Failing: 1}, want: "jdash · 1 failing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.Title(); got != tt.want {
+				t.Errorf("Title() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusTmux(t *testing.T) {
+	tests := []struct {
+		name   string
+		status Status
+		want   string
+	}{
+		{name: "all green", status: Status{}, want: "#[fg=green]✓#[default]"},
+		{name: "failing with nested job", status: Status{Job: "Production/api", Failing: 2}, want: "#[fg=red]✗2#[default] api"},
+		{name: "top level job", status: Status{Job: "deploy"}, want: "#[fg=green]✓#[default] deploy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.Tmux(); got != tt.want {
+				t.Errorf("Tmux() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
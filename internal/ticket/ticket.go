@@ -0,0 +1,34 @@
+// Package ticket tells the response to a panel's latest asynchronous request
+// apart from stale ones. Each request takes a ticket and its response carries
+// it back; only the response bearing the current ticket is applied, however
+// the responses are ordered when they arrive.
+package ticket
+
+// Counter issues tickets. The zero value is ready to use, and 0 is never
+// issued, so a zero ticket can mean "none".
+type Counter struct {
+	last uint64
+}
+
+// Next issues a ticket, making every earlier one stale.
+func (c *Counter) Next() uint64 {
+	c.last++
+	return c.last
+}
+
+// Invalidate makes every ticket issued so far stale, e.g. when the request
+// was cancelled or its result is no longer wanted.
+func (c *Counter) Invalidate() {
+	c.last++
+}
+
+// Current returns the latest ticket issued, or 0 if there is none.
+func (c Counter) Current() uint64 {
+	return c.last
+}
+
+// IsCurrent reports whether t is the latest ticket issued, i.e. whether a
+// response carrying it should be applied.
+func (c Counter) IsCurrent(t uint64) bool {
+	return t != 0 && t == c.last
+}
@@ -0,0 +1,53 @@
+package ticket
+
+import "testing"
+
+func TestCounterOutOfOrderResponses(t *testing.T) {
+	var c Counter
+	if c.IsCurrent(0) || c.Current() != 0 {
+		t.Fatal("a fresh counter treats the zero ticket as current")
+	}
+
+	first := c.Next()
+	second := c.Next()
+
+	// The second response overtakes the first: it is applied, and the first,
+	// arriving late, is dropped.
+	if !c.IsCurrent(second) {
+		t.Error("the latest response is stale")
+	}
+	if c.IsCurrent(first) {
+		t.Error("the overtaken response is current")
+	}
+	if c.Current() != second {
+		t.Errorf("Current() = %d, want %d", c.Current(), second)
+	}
+}
+
+func TestCounterInvalidate(t *testing.T) {
+	var c Counter
+	pending := c.Next()
+	c.Invalidate()
+	if c.IsCurrent(pending) {
+		t.Error("the response to a cancelled request is current")
+	}
+
+	// Tickets issued after invalidation are current again, and never reuse
+	// the invalidated one.
+	next := c.Next()
+	if next == pending || !c.IsCurrent(next) {
+		t.Errorf("Next() after Invalidate() = %d (pending %d), want a new current ticket", next, pending)
+	}
+}
+
+func TestCounterCopiesShareNothing(t *testing.T) {
+	// Panels are values; a copy taken for a command must not move the
+	// original's tickets.
+	var c Counter
+	issued := c.Next()
+	copied := c
+	copied.Next()
+	if !c.IsCurrent(issued) {
+		t.Error("issuing from a copy made the original's ticket stale")
+	}
+}
@@ -0,0 +1,181 @@
+package tokenrotate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jenkins"
+	"github.com/gorbach/jdash/internal/ui"
+)
+
+const modalWidth = 64
+
+// ClosedMsg is emitted when the user dismisses the token rotation modal.
+type ClosedMsg struct{}
+
+type rotatedMsg struct {
+	result Result
+	err    error
+}
+
+type state int
+
+const (
+	stateConfirm state = iota
+	stateRotating
+	stateDone
+)
+
+// Model is a modal that confirms and performs an API token rotation.
+type Model struct {
+	client  jenkins.JenkinsClient
+	spinner spinner.Model
+	state   state
+	result  Result
+	err     error
+
+	width  int
+	height int
+}
+
+// New creates a token rotation modal for the given client.
+func New(client jenkins.JenkinsClient) *Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = ui.HighlightStyle
+
+	return &Model{client: client, spinner: s}
+}
+
+// Init implements tea.Model; the rotation only starts after confirmation.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles TEA messages for the modal.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case rotatedMsg:
+		m.state = stateDone
+		m.result = msg.result
+		m.err = msg.err
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.state != stateRotating {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		switch m.state {
+		case stateConfirm:
+			switch msg.String() {
+			case "y", "enter":
+				m.state = stateRotating
+				return m, tea.Batch(m.spinner.Tick, rotateCmd(m.client))
+			case "n", "esc":
+				return m, closeCmd
+			}
+		case stateDone:
+			switch msg.String() {
+			case "enter", "esc":
+				return m, closeCmd
+			}
+		}
+	}
+	return m, nil
+}
+
+// View renders the modal.
+func (m *Model) View() string {
+	var content strings.Builder
+	content.WriteString(ui.TitleStyle.Render("Rotate API Token"))
+	content.WriteString("\n\n")
+
+	switch m.state {
+	case stateConfirm:
+		content.WriteString("Generate a new API token, save it to ~/.jdash/config.json\nand revoke the current one?")
+		content.WriteString("\n\n")
+		content.WriteString(ui.SubtleStyle.Render("[y] Rotate  [Esc] Cancel"))
+	case stateRotating:
+		content.WriteString(fmt.Sprintf("%s Rotating token...", m.spinner.View()))
+	case stateDone:
+		content.WriteString(m.renderResult())
+		content.WriteString("\n\n")
+		content.WriteString(ui.SubtleStyle.Render("[Enter] Close"))
+	}
+
+	panel := lipgloss.NewStyle().
+		Width(modalWidth).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ColorTitle).
+		Padding(1, 2).
+		Render(content.String())
+
+	if m.width == 0 || m.height == 0 {
+		return panel
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, panel)
+}
+
+func (m *Model) renderResult() string {
+	if m.err != nil {
+		return ui.ErrorStyle.Render("Rotation failed; the current token is unchanged.") +
+			"\n" + ui.SubtleStyle.Render(m.err.Error())
+	}
+
+	lines := []string{ui.SuccessStyle.Render(fmt.Sprintf("✓ Now using token %q", m.result.TokenName))}
+	switch {
+	case m.result.RevokedOld:
+		lines = append(lines, "The previous token was revoked.")
+	case m.result.RevokeErr != nil:
+		lines = append(lines,
+			ui.ErrorStyle.Render("Could not revoke the previous token:"),
+			ui.SubtleStyle.Render(m.result.RevokeErr.Error()),
+			"Revoke it manually at:",
+			ui.HighlightStyle.Render(m.result.ManualRevokeURL),
+		)
+	case m.result.ManualRevokeURL != "":
+		lines = append(lines,
+			"The previous token was not created by jdash, so it",
+			"cannot be identified. Revoke it manually at:",
+			ui.HighlightStyle.Render(m.result.ManualRevokeURL),
+		)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func rotateCmd(client jenkins.JenkinsClient) tea.Cmd {
+	return func() tea.Msg {
+		if client == nil {
+			return rotatedMsg{err: fmt.Errorf("Jenkins client not configured")}
+		}
+		server, err := auth.GetServerConfig()
+		if err != nil {
+			return rotatedMsg{err: fmt.Errorf("failed to load server config: %w", err)}
+		}
+		if server == nil {
+			return rotatedMsg{err: fmt.Errorf("no server configured")}
+		}
+		result, err := Rotate(context.Background(), client, *server, time.Now())
+		return rotatedMsg{result: result, err: err}
+	}
+}
+
+func closeCmd() tea.Msg {
+	return ClosedMsg{}
+}
@@ -0,0 +1,81 @@
+// Package tokenrotate replaces the saved Jenkins API token with a freshly generated one.
+package tokenrotate
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+// Result describes a completed rotation.
+type Result struct {
+	TokenName string
+	// RevokedOld is set when the previous token was revoked in Jenkins.
+	RevokedOld bool
+	// RevokeErr holds the error if revoking the previous token failed.
+	RevokeErr error
+	// ManualRevokeURL points to the Jenkins page where a token that jdash did
+	// not create (and therefore cannot identify) must be revoked by hand.
+	ManualRevokeURL string
+}
+
+// TokenName returns the name given to tokens generated at now.
+func TokenName(now time.Time) string {
+	return auth.TokenName(now)
+}
+
+// Rotate generates a new token with client, verifies it, saves it to the
+// config, switches client over to it and revokes the previous token. The old
+// token stays valid until the new one has been verified and saved.
+func Rotate(ctx context.Context, client jenkins.JenkinsClient, server auth.ServerConfig, now time.Time) (Result, error) {
+	token, err := client.GenerateAPIToken(ctx, TokenName(now))
+	if err != nil {
+		return Result{}, err
+	}
+
+	updated := server
+	updated.Token = token.Value
+	updated.TokenUUID = token.UUID
+
+	if err := auth.CreateJenkinsClient(&updated).TestConnection(ctx); err != nil {
+		_ = client.RevokeAPIToken(ctx, token.UUID)
+		return Result{}, fmt.Errorf("new token failed verification: %w", err)
+	}
+	if err := auth.SaveServerConfig(updated); err != nil {
+		_ = client.RevokeAPIToken(ctx, token.UUID)
+		return Result{}, fmt.Errorf("failed to save new token: %w", err)
+	}
+
+	client.SetToken(token.Value)
+	result := Result{TokenName: token.Name}
+	if result.TokenName == "" {
+		result.TokenName = TokenName(now)
+	}
+
+	if server.TokenUUID == "" {
+		result.ManualRevokeURL = securityPageURL(server)
+		return result, nil
+	}
+	if err := client.RevokeAPIToken(ctx, server.TokenUUID); err != nil {
+		result.RevokeErr = err
+		result.ManualRevokeURL = securityPageURL(server)
+		return result, nil
+	}
+	result.RevokedOld = true
+	return result, nil
+}
+
+// securityPageURL returns the user page listing API tokens (Security on
+// current Jenkins, Configure on older releases).
+func securityPageURL(server auth.ServerConfig) string {
+	base := strings.TrimSuffix(server.URL, "/")
+	if server.Username == "" {
+		return base + "/me/security/"
+	}
+	return fmt.Sprintf("%s/user/%s/security/", base, url.PathEscape(server.Username))
+}
@@ -0,0 +1,39 @@
+package tokenrotate
+
+import (
+	"testing"
+
+	"github.com/gorbach/jdash/internal/auth"
+)
+
+func TestSecurityPageURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		server auth.ServerConfig
+		want   string
+	}{
+		{
+			name:   "username",
+			server: auth.ServerConfig{URL: "https://ci.example.com/", Username: "jdoe"},
+			want:   "https://ci.example.com/user/jdoe/security/",
+		},
+		{
+			name:   "username needing escape",
+			server: auth.ServerConfig{URL: "https://ci.example.com/jenkins", Username: "jane doe"},
+			want:   "https://ci.example.com/jenkins/user/jane%20doe/security/",
+		},
+		{
+			name:   "no username",
+			server: auth.ServerConfig{URL: "https://ci.example.com"},
+			want:   "https://ci.example.com/me/security/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := securityPageURL(tt.server); got != tt.want {
+				t.Errorf("securityPageURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
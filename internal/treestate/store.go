@@ -0,0 +1,65 @@
+// Package treestate persists the folders expanded in the jobs tree and the
+// selected job when jdash quits, so the next start opens where the user left
+// off instead of with every folder collapsed.
+package treestate
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/jsonstore"
+)
+
+const fileName = "tree.json"
+
+// State is the tree as the user left it.
+type State struct {
+	// Expanded lists the full names of the expanded folders.
+	Expanded []string `json:"expanded"`
+	// Selected is the full name of the selected job or folder.
+	Selected string `json:"selected,omitempty"`
+}
+
+// Store holds the state read at startup and saves the state at exit. A nil
+// Store is empty and saves nothing.
+type Store struct {
+	file  *jsonstore.File
+	state State
+}
+
+// Load reads the state from tree.json in the config directory.
+func Load() (*Store, error) {
+	return LoadFrom(filepath.Join(auth.ConfigDir(), fileName))
+}
+
+// LoadFrom reads the state from path. Without a readable file the tree
+// opens collapsed.
+func LoadFrom(path string) (*Store, error) {
+	store := &Store{}
+	var err error
+	store.file, err = jsonstore.Load(path, &store.state)
+	return store, err
+}
+
+// State returns the state read at startup, or last saved.
+func (s *Store) State() State {
+	if s == nil {
+		return State{}
+	}
+	return s.state
+}
+
+// Save persists state, with the folders sorted so the file diffs cleanly.
+func (s *Store) Save(state State) error {
+	if s == nil {
+		return nil
+	}
+	state.Expanded = append([]string(nil), state.Expanded...)
+	sort.Strings(state.Expanded)
+	if err := s.file.Save(func() any { return state }); err != nil {
+		return err
+	}
+	s.state = state
+	return nil
+}
@@ -0,0 +1,36 @@
+package treestate
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.json")
+
+	store, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() on missing file error: %v", err)
+	}
+	if state := store.State(); len(state.Expanded) != 0 || state.Selected != "" {
+		t.Fatalf("missing file loaded %+v, want an empty state", state)
+	}
+
+	saved := State{Expanded: []string{"Production/Team", "Production"}, Selected: "Production/Team/service"}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	reloaded, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom() error: %v", err)
+	}
+	state := reloaded.State()
+	if want := []string{"Production", "Production/Team"}; !slices.Equal(state.Expanded, want) {
+		t.Errorf("Expanded = %v, want %v", state.Expanded, want)
+	}
+	if state.Selected != saved.Selected {
+		t.Errorf("Selected = %q, want %q", state.Selected, saved.Selected)
+	}
+}
@@ -0,0 +1,118 @@
+// Package trend flags builds that look unlike the ones before them.
+//
+// A build whose duration, console log size or test count is far from the
+// trailing median is often the first sign of a misconfigured job: a skipped
+// test stage, a retry loop flooding the log, a cache that stopped working.
+package trend
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gorbach/jdash/internal/utils"
+)
+
+const (
+	// minHistory is how many earlier samples a metric needs before it is judged.
+	minHistory = 4
+	// ratioThreshold is how many times larger (or smaller) than the median a
+	// duration or log size must be to count as an anomaly.
+	ratioThreshold = 3.0
+	// minDuration ignores speed-ups of jobs that usually finish in seconds.
+	minDuration = time.Minute
+	// testDropRatio and minTestDrop define a suspicious fall in the test count.
+	testDropRatio = 0.8
+	minTestDrop   = 5
+)
+
+// Sample is one build's metrics. Zero or negative values mean unknown and are
+// left out of the comparison.
+type Sample struct {
+	Duration time.Duration
+	LogBytes int64
+	// Tests is the total test count; -1 when the build published no report.
+	Tests int
+}
+
+// Anomaly describes one metric of the latest build that is out of line.
+type Anomaly struct {
+	Metric  string // "duration", "log" or "tests"
+	Message string
+}
+
+// Detect compares latest against the median of history and returns the
+// metrics that stand out, in a stable order.
+func Detect(latest Sample, history []Sample) []Anomaly {
+	var found []Anomaly
+
+	durations := make([]float64, 0, len(history))
+	logs := make([]float64, 0, len(history))
+	tests := make([]float64, 0, len(history))
+	for _, s := range history {
+		if s.Duration > 0 {
+			durations = append(durations, float64(s.Duration))
+		}
+		if s.LogBytes > 0 {
+			logs = append(logs, float64(s.LogBytes))
+		}
+		if s.Tests >= 0 {
+			tests = append(tests, float64(s.Tests))
+		}
+	}
+
+	if latest.Duration > 0 && len(durations) >= minHistory {
+		usual := time.Duration(median(durations))
+		ratio := float64(latest.Duration) / float64(usual)
+		switch {
+		case ratio >= ratioThreshold:
+			found = append(found, Anomaly{Metric: "duration", Message: fmt.Sprintf("took %s longer than usual (%s vs %s)",
+				formatRatio(ratio), utils.FormatDuration(latest.Duration), utils.FormatDuration(usual))})
+		case ratio > 0 && 1/ratio >= ratioThreshold && usual >= minDuration:
+			found = append(found, Anomaly{Metric: "duration", Message: fmt.Sprintf("finished %s faster than usual (%s vs %s)",
+				formatRatio(1/ratio), utils.FormatDuration(latest.Duration), utils.FormatDuration(usual))})
+		}
+	}
+
+	if latest.LogBytes > 0 && len(logs) >= minHistory {
+		ratio := float64(latest.LogBytes) / median(logs)
+		switch {
+		case ratio >= ratioThreshold:
+			found = append(found, Anomaly{Metric: "log", Message: fmt.Sprintf("log %s larger than usual", formatRatio(ratio))})
+		case 1/ratio >= ratioThreshold:
+			found = append(found, Anomaly{Metric: "log", Message: fmt.Sprintf("log %s smaller than usual", formatRatio(1/ratio))})
+		}
+	}
+
+	if len(tests) >= minHistory {
+		usual := int(median(tests))
+		switch {
+		case latest.Tests < 0 && usual > 0:
+			found = append(found, Anomaly{Metric: "tests", Message: fmt.Sprintf("no test report (usually %d tests)", usual)})
+		case latest.Tests >= 0 && usual-latest.Tests >= minTestDrop && float64(latest.Tests) < float64(usual)*testDropRatio:
+			found = append(found, Anomaly{Metric: "tests", Message: fmt.Sprintf("%d fewer tests than usual (%d vs %d)",
+				usual-latest.Tests, latest.Tests, usual)})
+		}
+	}
+
+	return found
+}
+
+// median returns the middle value of values, which must not be empty.
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// formatRatio renders a ratio as "4x", keeping one decimal below 10.
+func formatRatio(ratio float64) string {
+	if ratio >= 10 {
+		return fmt.Sprintf("%.0fx", ratio)
+	}
+	return fmt.Sprintf("%.1fx", ratio)
+}
@@ -0,0 +1,78 @@
+package trend
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	steady := []Sample{
+		{Duration: 2 * time.Minute, LogBytes: 10_000, Tests: 500},
+		{Duration: 2 * time.Minute, LogBytes: 11_000, Tests: 500},
+		{Duration: 3 * time.Minute, LogBytes: 9_000, Tests: 498},
+		{Duration: 2 * time.Minute, LogBytes: 10_000, Tests: 502},
+		{Duration: 2 * time.Minute, LogBytes: 12_000, Tests: 500},
+	}
+
+	tests := []struct {
+		name    string
+		latest  Sample
+		history []Sample
+		want    []string
+	}{
+		{
+			name:    "ordinary build",
+			latest:  Sample{Duration: 150 * time.Second, LogBytes: 10_500, Tests: 501},
+			history: steady,
+			want:    nil,
+		},
+		{
+			name:    "log flood",
+			latest:  Sample{Duration: 2 * time.Minute, LogBytes: 40_000, Tests: 500},
+			history: steady,
+			want:    []string{"log 4.0x larger than usual"},
+		},
+		{
+			name:    "slow build with missing tests",
+			latest:  Sample{Duration: 8 * time.Minute, LogBytes: 10_000, Tests: 120},
+			history: steady,
+			want:    []string{"took 4.0x longer than usual (8m vs 2m)", "380 fewer tests than usual (120 vs 500)"},
+		},
+		{
+			name:    "test report disappeared",
+			latest:  Sample{Duration: 30 * time.Second, LogBytes: 2_000, Tests: -1},
+			history: steady,
+			want:    []string{"finished 4.0x faster than usual (30s vs 2m)", "log 5.0x smaller than usual", "no test report (usually 500 tests)"},
+		},
+		{
+			name:    "too little history",
+			latest:  Sample{Duration: time.Hour, LogBytes: 1_000_000, Tests: 0},
+			history: steady[:3],
+			want:    nil,
+		},
+		{
+			name:   "unknown metrics are skipped",
+			latest: Sample{Duration: 10 * time.Minute, Tests: -1},
+			history: []Sample{
+				{Duration: 10 * time.Minute, Tests: -1},
+				{Duration: 11 * time.Minute, Tests: -1},
+				{Duration: 9 * time.Minute, Tests: -1},
+				{Duration: 10 * time.Minute, Tests: -1},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, a := range Detect(tt.latest, tt.history) {
+				got = append(got, a.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
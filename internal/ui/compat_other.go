@@ -0,0 +1,9 @@
+//go:build !windows
+
+package ui
+
+// DetectCompatMode reports whether the terminal needs compatibility mode.
+// Only the classic Windows console host does.
+func DetectCompatMode() bool {
+	return false
+}
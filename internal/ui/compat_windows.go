@@ -0,0 +1,16 @@
+package ui
+
+import "os"
+
+// DetectCompatMode reports whether jdash runs in the classic Windows console
+// host (conhost), whose default fonts lack emoji and many symbols. Windows
+// Terminal, ConEmu, VS Code and mintty all announce themselves in the
+// environment; a bare console does not.
+func DetectCompatMode() bool {
+	for _, name := range []string{"WT_SESSION", "ConEmuANSI", "TERM_PROGRAM", "TERM"} {
+		if os.Getenv(name) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -1,9 +1,14 @@
 package ui
 
-import "github.com/gorbach/jdash/internal/jenkins"
+import (
+	"fmt"
 
-// Status icons as specified in docs2.md
-const (
+	"github.com/gorbach/jdash/internal/jenkins"
+)
+
+// Status icons as specified in docs2.md. UseFallbackIcons swaps them for
+// ASCII in compatibility mode.
+var (
 	IconSuccess  = "✓"
 	IconFailed   = "✗"
 	IconBuilding = "⟳"
@@ -12,12 +17,62 @@
 	IconUnstable = "⚠"
 	IconAborted  = "◯"
 	IconFolder   = "📁"
+	IconLocked   = "🔒"
+	IconPromoted = "★"
+	IconFavorite = "★"
+	IconMarked   = "●"
 
 	// Tree expansion icons
 	IconExpanded  = "▼"
 	IconCollapsed = "▶"
 )
 
+// weatherIcons stand for health scores above 80, 61-80, 41-60, 21-40 and 20
+// or less, the web UI's bands for its sun, clouds, rain and storm. Nil shows
+// scores.
+var weatherIcons = []string{"🌞", "⛅", "🌂", "☔", "⚡"}
+
+// WeatherIcon returns the weather icon of a job health score (0-100). In
+// compatibility mode it is the score itself, e.g. "80%".
+func WeatherIcon(score int) string {
+	if weatherIcons == nil {
+		return fmt.Sprintf("%d%%", score)
+	}
+	switch {
+	case score > 80:
+		return weatherIcons[0]
+	case score > 60:
+		return weatherIcons[1]
+	case score > 40:
+		return weatherIcons[2]
+	case score > 20:
+		return weatherIcons[3]
+	default:
+		return weatherIcons[4]
+	}
+}
+
+// UseFallbackIcons replaces the icons with ASCII, for terminals whose font
+// lacks the glyphs or emoji (e.g. the Windows console host). Wide icons keep
+// their two-cell width so columns stay aligned. Call it before the UI starts.
+func UseFallbackIcons() {
+	IconSuccess = "+"
+	IconFailed = "x"
+	IconBuilding = "*"
+	IconPending = "o"
+	IconDisabled = "-"
+	IconUnstable = "!"
+	IconAborted = "~"
+	IconFolder = "[]"
+	IconLocked = "RO"
+	IconPromoted = "^"
+	IconFavorite = "*"
+	IconMarked = "#"
+	IconExpanded = "v"
+	IconCollapsed = ">"
+	weatherIcons = nil
+}
+
 // GetStatusIcon returns the appropriate icon for a given status
 func GetStatusIcon(status string) string {
 	switch status {
@@ -0,0 +1,26 @@
+package ui
+
+import "testing"
+
+func TestWeatherIconBands(t *testing.T) {
+	// The web UI's bands: a score on a boundary gets the worse weather.
+	for _, tt := range []struct {
+		score int
+		want  string
+	}{
+		{100, "🌞"},
+		{81, "🌞"},
+		{80, "⛅"},
+		{61, "⛅"},
+		{60, "🌂"},
+		{41, "🌂"},
+		{40, "☔"},
+		{21, "☔"},
+		{20, "⚡"},
+		{0, "⚡"},
+	} {
+		if got := WeatherIcon(tt.score); got != tt.want {
+			t.Errorf("WeatherIcon(%d) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
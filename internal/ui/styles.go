@@ -23,44 +23,108 @@
 	ColorSubtle          = lipgloss.Color("8")  // Dim gray
 	ColorHighlight       = lipgloss.Color("14") // Bright cyan
 	ColorSearchHighlight = lipgloss.Color("11") // Bright yellow
+	// ColorSelected is the background of the selected row and of chips;
+	// ColorSelectedText is the text on it, empty to keep the text's own.
+	ColorSelected     = lipgloss.Color("237")
+	ColorSelectedText lipgloss.Color
+	// ColorText is plain text and ColorTextInverse text on colored bars and
+	// badges.
+	ColorText        = lipgloss.Color("7")
+	ColorTextInverse = lipgloss.Color("0")
+	// ColorDim greys out the panels behind an overlay and ColorOverlay is the
+	// overlay's background; ColorAccent frames the developer overlays.
+	ColorDim     = lipgloss.Color("240")
+	ColorOverlay = lipgloss.Color("235")
+	ColorAccent  = lipgloss.Color("13")
 )
 
 // Status styles
 var (
-	SuccessStyle  = lipgloss.NewStyle().Foreground(ColorSuccess)
-	FailedStyle   = lipgloss.NewStyle().Foreground(ColorFailed)
-	BuildingStyle = lipgloss.NewStyle().Foreground(ColorBuilding)
-	DisabledStyle = lipgloss.NewStyle().Foreground(ColorDisabled)
-	UnstableStyle = lipgloss.NewStyle().Foreground(ColorUnstable)
-	AbortedStyle  = lipgloss.NewStyle().Foreground(ColorAborted)
-	PendingStyle  = lipgloss.NewStyle().Foreground(ColorPending)
+	SuccessStyle  lipgloss.Style
+	FailedStyle   lipgloss.Style
+	BuildingStyle lipgloss.Style
+	DisabledStyle lipgloss.Style
+	UnstableStyle lipgloss.Style
+	AbortedStyle  lipgloss.Style
+	PendingStyle  lipgloss.Style
 )
 
 // UI component styles
 var (
+	TitleStyle           lipgloss.Style
+	SubtleStyle          lipgloss.Style
+	HighlightStyle       lipgloss.Style
+	SearchHighlightStyle lipgloss.Style
+	ErrorStyle           lipgloss.Style
+	SelectedStyle        lipgloss.Style
+	BadgeStyle           lipgloss.Style
+	ChipStyle            lipgloss.Style
+	// MeBadgeStyle marks the builds and queue items the configured user started.
+	MeBadgeStyle lipgloss.Style
+)
+
+func init() {
+	buildStyles()
+}
+
+// buildStyles derives the styles from the current colors, so UseTheme can
+// swap the palette.
+func buildStyles() {
+	SuccessStyle = lipgloss.NewStyle().Foreground(ColorSuccess)
+	FailedStyle = lipgloss.NewStyle().Foreground(ColorFailed)
+	BuildingStyle = lipgloss.NewStyle().Foreground(ColorBuilding)
+	DisabledStyle = lipgloss.NewStyle().Foreground(ColorDisabled)
+	UnstableStyle = lipgloss.NewStyle().Foreground(ColorUnstable)
+	AbortedStyle = lipgloss.NewStyle().Foreground(ColorAborted)
+	PendingStyle = lipgloss.NewStyle().Foreground(ColorPending)
+
 	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(ColorTitle)
+		Bold(true).
+		Foreground(ColorTitle)
 
 	SubtleStyle = lipgloss.NewStyle().
-			Foreground(ColorSubtle)
+		Foreground(ColorSubtle)
 
 	HighlightStyle = lipgloss.NewStyle().
-			Foreground(ColorHighlight).
-			Bold(true)
+		Foreground(ColorHighlight).
+		Bold(true)
 
 	SearchHighlightStyle = lipgloss.NewStyle().
-				Foreground(ColorSearchHighlight).
-				Bold(true)
+		Foreground(ColorSearchHighlight).
+		Bold(true)
 
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(ColorFailed).
-			Bold(true)
+		Foreground(ColorFailed).
+		Bold(true)
 
 	SelectedStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("237")).
-			Bold(true)
-)
+		Background(ColorSelected).
+		Bold(true)
+	if ColorSelectedText != "" {
+		SelectedStyle = SelectedStyle.Foreground(ColorSelectedText)
+	}
+
+	BadgeStyle = lipgloss.NewStyle().
+		Foreground(ColorTextInverse).
+		Background(ColorTitle).
+		Bold(true)
+
+	ChipStyle = lipgloss.NewStyle().
+		Foreground(ColorHighlight).
+		Background(ColorSelected).
+		Padding(0, 1)
+
+	MeBadgeStyle = lipgloss.NewStyle().
+		Foreground(ColorTextInverse).
+		Background(ColorHighlight).
+		Bold(true)
+}
+
+// MeBadge renders the "me" badge of builds and queue items the configured
+// user started.
+func MeBadge() string {
+	return MeBadgeStyle.Render(" me ")
+}
 
 // GetStatusStyle returns the appropriate style for a given status
 func GetStatusStyle(status string) lipgloss.Style {
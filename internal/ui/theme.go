@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Palette is the set of colors a theme paints the UI with.
+type Palette struct {
+	Success, Failed, Building, Disabled, Unstable, Aborted, Pending lipgloss.Color
+
+	Border, BorderActive, Title, Subtle, Highlight, SearchHighlight lipgloss.Color
+	// Selected is the background of the selected row and SelectedText the
+	// text on it; empty keeps the text's own color.
+	Selected, SelectedText lipgloss.Color
+	// Text is plain text and TextInverse text on colored bars and badges.
+	// Dim greys out the panels behind an overlay, drawn on Overlay; Accent
+	// frames the developer overlays.
+	Text, TextInverse, Dim, Overlay, Accent lipgloss.Color
+}
+
+// DefaultTheme is the theme used when none is configured.
+const DefaultTheme = "dark"
+
+// themes are the built-in palettes. Statuses never differ by color alone:
+// each has its own icon and label, and the color-blind palettes also keep
+// failed and passing builds apart in brightness, using orange and blue
+// (from the Okabe-Ito palette) rather than red and green.
+var themes = map[string]Palette{
+	"dark": {
+		Success: "10", Failed: "9", Building: "11", Disabled: "8", Unstable: "11", Aborted: "8", Pending: "8",
+		Border: "8", BorderActive: "10", Title: "12", Subtle: "8", Highlight: "14", SearchHighlight: "11",
+		Selected: "237", Text: "7", TextInverse: "0", Dim: "240", Overlay: "235", Accent: "13",
+	},
+	// high-contrast uses only bright colors on the terminal's background,
+	// light gray instead of dim gray, and inverts the selected row.
+	"high-contrast": {
+		Success: "10", Failed: "9", Building: "11", Disabled: "7", Unstable: "13", Aborted: "7", Pending: "7",
+		Border: "7", BorderActive: "15", Title: "15", Subtle: "7", Highlight: "14", SearchHighlight: "11",
+		Selected: "15", SelectedText: "0", Text: "15", TextInverse: "0", Dim: "8", Overlay: "0", Accent: "13",
+	},
+	// deuteranopia: blue for success, vermillion for failures.
+	"deuteranopia": {
+		Success: "#56B4E9", Failed: "#D55E00", Building: "#F0E442", Disabled: "8", Unstable: "#CC79A7", Aborted: "8", Pending: "8",
+		Border: "8", BorderActive: "#56B4E9", Title: "#0072B2", Subtle: "8", Highlight: "#56B4E9", SearchHighlight: "#F0E442",
+		Selected: "237", Text: "7", TextInverse: "0", Dim: "240", Overlay: "235", Accent: "#CC79A7",
+	},
+	// protanopia: reds look dark, so failures are a bright orange instead.
+	"protanopia": {
+		Success: "#0072B2", Failed: "#E69F00", Building: "#F0E442", Disabled: "8", Unstable: "#CC79A7", Aborted: "8", Pending: "8",
+		Border: "8", BorderActive: "#56B4E9", Title: "#56B4E9", Subtle: "8", Highlight: "#56B4E9", SearchHighlight: "#F0E442",
+		Selected: "237", Text: "7", TextInverse: "0", Dim: "240", Overlay: "235", Accent: "#CC79A7",
+	},
+}
+
+// Themes lists the names of the built-in themes.
+func Themes() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseTheme switches to the named built-in palette; an empty name keeps the
+// default. Like UseFallbackIcons, call it before the UI starts.
+func UseTheme(name string) error {
+	if name == "" {
+		name = DefaultTheme
+	}
+	palette, ok := themes[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(Themes(), ", "))
+	}
+	ColorSuccess = palette.Success
+	ColorFailed = palette.Failed
+	ColorBuilding = palette.Building
+	ColorDisabled = palette.Disabled
+	ColorUnstable = palette.Unstable
+	ColorAborted = palette.Aborted
+	ColorPending = palette.Pending
+	ColorBorder = palette.Border
+	ColorBorderActive = palette.BorderActive
+	ColorTitle = palette.Title
+	ColorSubtle = palette.Subtle
+	ColorHighlight = palette.Highlight
+	ColorSearchHighlight = palette.SearchHighlight
+	ColorSelected = palette.Selected
+	ColorSelectedText = palette.SelectedText
+	ColorText = palette.Text
+	ColorTextInverse = palette.TextInverse
+	ColorDim = palette.Dim
+	ColorOverlay = palette.Overlay
+	ColorAccent = palette.Accent
+	buildStyles()
+	return nil
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUseTheme(t *testing.T) {
+	t.Cleanup(func() { UseTheme("") })
+
+	for _, name := range Themes() {
+		if err := UseTheme(strings.ToUpper(name)); err != nil {
+			t.Fatalf("UseTheme(%q) = %v", name, err)
+		}
+		palette := themes[name]
+		if ColorSuccess != palette.Success || ColorFailed != palette.Failed || ColorText != palette.Text || ColorOverlay != palette.Overlay {
+			t.Errorf("%s: colors not switched to the palette", name)
+		}
+		if got := SuccessStyle.GetForeground(); got != palette.Success {
+			t.Errorf("%s: SuccessStyle foreground = %v, want the styles rebuilt", name, got)
+		}
+		if palette.Success == palette.Failed {
+			t.Errorf("%s: success and failure share a color", name)
+		}
+		for field, color := range map[string]string{
+			"Text": string(palette.Text), "TextInverse": string(palette.TextInverse), "Dim": string(palette.Dim),
+			"Overlay": string(palette.Overlay), "Accent": string(palette.Accent), "Border": string(palette.Border),
+		} {
+			if color == "" {
+				t.Errorf("%s: %s is empty", name, field)
+			}
+		}
+	}
+
+	if err := UseTheme(""); err != nil || ColorSuccess != themes[DefaultTheme].Success {
+		t.Errorf("UseTheme(\"\") = %v, want the default theme", err)
+	}
+	before := ColorFailed
+	err := UseTheme("solarized")
+	if err == nil || !strings.Contains(err.Error(), "high-contrast") {
+		t.Errorf("UseTheme(unknown) = %v, want an error listing the themes", err)
+	}
+	if ColorFailed != before {
+		t.Error("an unknown theme changed the colors")
+	}
+}
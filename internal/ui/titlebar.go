@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TitleBar describes a panel header rendered by the app layout: the panel name,
+// an item count, state chips such as "filter: failed", and a spinner slot.
+type TitleBar struct {
+	Name    string
+	Count   string
+	Chips   []string
+	Spinner string
+}
+
+// Render draws the title bar on a single line no wider than width.
+// Chips that do not fit are dropped from the end.
+func (t TitleBar) Render(width int) string {
+	title := t.Name
+	if t.Count != "" {
+		title += " (" + t.Count + ")"
+	}
+	line := TitleStyle.Render(title)
+	if t.Spinner != "" {
+		line += " " + t.Spinner
+	}
+
+	for _, chip := range t.Chips {
+		if strings.TrimSpace(chip) == "" {
+			continue
+		}
+		next := line + " " + ChipStyle.Render(chip)
+		if width > 0 && lipgloss.Width(next) > width {
+			break
+		}
+		line = next
+	}
+
+	if width > 0 && lipgloss.Width(line) > width {
+		return TitleStyle.Render(truncateRunes(title, width))
+	}
+	return line
+}
+
+func truncateRunes(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -1,9 +1,15 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
 )
 
 // FormatDuration formats a duration into a human-readable string like "2m 34s"
@@ -34,84 +40,200 @@ func FormatDuration(d time.Duration) string {
 	return strings.Join(parts, " ")
 }
 
-// FormatRelativeTime formats a timestamp into a relative time string like "1h ago"
+// RelativeTimeOptions decide when relative timestamps give way to dates.
+type RelativeTimeOptions struct {
+	// AbsoluteAfter shows the date of timestamps older than this instead of
+	// how long ago they were; zero keeps them relative at any age.
+	AbsoluteAfter time.Duration
+	// FutureTolerance is how far ahead of the local clock a timestamp may be
+	// (clock skew with the server) and still read "in 2 minutes"; further
+	// ahead it shows the date.
+	FutureTolerance time.Duration
+	// DateLayout is the time.Format layout of dates; empty means
+	// DefaultDateLayout.
+	DateLayout string
+	// Language words relative times, one of RelativeTimeLanguages; empty
+	// means English.
+	Language string
+}
+
+// DefaultDateLayout writes dates shown in place of relative times.
+const DefaultDateLayout = "2006-01-02 15:04"
+
+// relativePhrases word relative times in one language. Ago and in wrap the
+// span, e.g. "%s ago"; units are minute, hour, day, week, month and year,
+// each singular then plural, in the case ago and in take.
+type relativePhrases struct {
+	justNow, never, ago, in string
+	units                   [6][2]string
+}
+
+var relativeLanguages = map[string]relativePhrases{
+	"en": {"just now", "never", "%s ago", "in %s", [6][2]string{
+		{"minute", "minutes"}, {"hour", "hours"}, {"day", "days"},
+		{"week", "weeks"}, {"month", "months"}, {"year", "years"}}},
+	"de": {"gerade eben", "nie", "vor %s", "in %s", [6][2]string{
+		{"Minute", "Minuten"}, {"Stunde", "Stunden"}, {"Tag", "Tagen"},
+		{"Woche", "Wochen"}, {"Monat", "Monaten"}, {"Jahr", "Jahren"}}},
+	"es": {"ahora mismo", "nunca", "hace %s", "dentro de %s", [6][2]string{
+		{"minuto", "minutos"}, {"hora", "horas"}, {"día", "días"},
+		{"semana", "semanas"}, {"mes", "meses"}, {"año", "años"}}},
+	"fr": {"à l'instant", "jamais", "il y a %s", "dans %s", [6][2]string{
+		{"minute", "minutes"}, {"heure", "heures"}, {"jour", "jours"},
+		{"semaine", "semaines"}, {"mois", "mois"}, {"an", "ans"}}},
+}
+
+// RelativeTimeLanguages lists the languages relative times can be written in.
+func RelativeTimeLanguages() []string {
+	languages := make([]string, 0, len(relativeLanguages))
+	for language := range relativeLanguages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// DefaultRelativeTimeOptions keep timestamps relative and put up with an
+// hour of clock skew.
+var DefaultRelativeTimeOptions = RelativeTimeOptions{FutureTolerance: time.Hour}
+
+var relativeTimeOptions atomic.Pointer[RelativeTimeOptions]
+
+func init() {
+	SetRelativeTimeOptions(DefaultRelativeTimeOptions)
+}
+
+// SetRelativeTimeOptions replaces the thresholds FormatRelativeTime uses. A
+// date layout without any date or time in it, like "DD.MM.YYYY" rather than
+// Go's "02.01.2006", or an unknown language is an error; the default takes
+// its place and the other options still apply.
+func SetRelativeTimeOptions(opts RelativeTimeOptions) error {
+	var errs []error
+	if opts.DateLayout == "" {
+		opts.DateLayout = DefaultDateLayout
+	}
+	// Formatting two instants apart in every field alike means the layout
+	// is all literal text.
+	first := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	second := time.Date(2012, 11, 24, 16, 37, 48, 0, time.UTC)
+	if first.Format(opts.DateLayout) == second.Format(opts.DateLayout) {
+		errs = append(errs, fmt.Errorf("date format %q holds no date or time (write a Go layout like %q); using %q", opts.DateLayout, "02.01.2006 15:04", DefaultDateLayout))
+		opts.DateLayout = DefaultDateLayout
+	}
+	if opts.Language == "" {
+		opts.Language = "en"
+	}
+	opts.Language = strings.ToLower(opts.Language)
+	if _, ok := relativeLanguages[opts.Language]; !ok {
+		errs = append(errs, fmt.Errorf("unknown language %q (want one of %s); using en", opts.Language, strings.Join(RelativeTimeLanguages(), ", ")))
+		opts.Language = "en"
+	}
+	relativeTimeOptions.Store(&opts)
+	return errors.Join(errs...)
+}
+
+// FormatRelativeTime formats a timestamp into a relative time string like
+// "1 hour ago", or "in 2 minutes" when the server clock runs ahead, in the
+// language set with SetRelativeTimeOptions. Past the thresholds set there it
+// writes the date instead.
 func FormatRelativeTime(t time.Time) string {
+	return formatRelativeTime(t, time.Now(), *relativeTimeOptions.Load())
+}
+
+func formatRelativeTime(t, now time.Time, opts RelativeTimeOptions) string {
+	phrases, ok := relativeLanguages[opts.Language]
+	if !ok {
+		phrases = relativeLanguages["en"]
+	}
 	if t.IsZero() {
-		return "never"
+		return phrases.never
 	}
 
-	now := time.Now()
 	diff := now.Sub(t)
-
-	if diff < 0 {
-		return "just now"
+	switch {
+	case diff > -time.Minute && diff < time.Minute:
+		return phrases.justNow
+	case diff < 0 && -diff > opts.FutureTolerance:
+		return t.Local().Format(opts.DateLayout)
+	case diff < 0:
+		return fmt.Sprintf(phrases.in, phrases.span(-diff))
+	case opts.AbsoluteAfter > 0 && diff >= opts.AbsoluteAfter:
+		return t.Local().Format(opts.DateLayout)
+	default:
+		return fmt.Sprintf(phrases.ago, phrases.span(diff))
 	}
+}
 
+// span writes d, at least a minute, in its largest whole unit.
+func (p relativePhrases) span(d time.Duration) string {
+	var n, unit int
 	switch {
-	case diff < time.Minute:
-		return "just now"
-	case diff < time.Hour:
-		minutes := int(diff.Minutes())
-		if minutes == 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", minutes)
-	case diff < 24*time.Hour:
-		hours := int(diff.Hours())
-		if hours == 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", hours)
-	case diff < 7*24*time.Hour:
-		days := int(diff.Hours() / 24)
-		if days == 1 {
-			return "1 day ago"
-		}
-		return fmt.Sprintf("%d days ago", days)
-	case diff < 30*24*time.Hour:
-		weeks := int(diff.Hours() / 24 / 7)
-		if weeks == 1 {
-			return "1 week ago"
-		}
-		return fmt.Sprintf("%d weeks ago", weeks)
-	case diff < 365*24*time.Hour:
-		months := int(diff.Hours() / 24 / 30)
-		if months == 1 {
-			return "1 month ago"
-		}
-		return fmt.Sprintf("%d months ago", months)
+	case d < time.Hour:
+		n, unit = int(d.Minutes()), 0
+	case d < 24*time.Hour:
+		n, unit = int(d.Hours()), 1
+	case d < 7*24*time.Hour:
+		n, unit = int(d.Hours()/24), 2
+	case d < 30*24*time.Hour:
+		n, unit = int(d.Hours()/24/7), 3
+	case d < 365*24*time.Hour:
+		n, unit = int(d.Hours()/24/30), 4
 	default:
-		years := int(diff.Hours() / 24 / 365)
-		if years == 1 {
-			return "1 year ago"
-		}
-		return fmt.Sprintf("%d years ago", years)
+		n, unit = int(d.Hours()/24/365), 5
+	}
+	if n == 1 {
+		return "1 " + p.units[unit][0]
 	}
+	return fmt.Sprintf("%d %s", n, p.units[unit][1])
 }
 
-// TruncateString truncates a string to the specified length and adds ellipsis if needed
+// TruncateString truncates a string to the specified display width and adds ellipsis if needed.
+// Width is measured in terminal cells, so wide characters (CJK, emoji) count as two and
+// ANSI styling is preserved without being counted.
 func TruncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if maxLen <= 0 {
+		return ""
+	}
+	if ansi.StringWidth(s) <= maxLen {
 		return s
 	}
 	if maxLen <= 3 {
-		return s[:maxLen]
+		return ansi.Truncate(s, maxLen, "")
 	}
-	return s[:maxLen-3] + "..."
+	return ansi.Truncate(s, maxLen, "...")
 }
 
-// PadRight pads a string with spaces on the right to reach the specified length
+// PadRight pads a string with spaces on the right to reach the specified display width
 func PadRight(s string, length int) string {
-	if len(s) >= length {
+	width := ansi.StringWidth(s)
+	if width >= length {
 		return s
 	}
-	return s + strings.Repeat(" ", length-len(s))
+	return s + strings.Repeat(" ", length-width)
 }
 
-// PadLeft pads a string with spaces on the left to reach the specified length
+// PadLeft pads a string with spaces on the left to reach the specified display width
 func PadLeft(s string, length int) string {
-	if len(s) >= length {
+	width := ansi.StringWidth(s)
+	if width >= length {
 		return s
 	}
-	return strings.Repeat(" ", length-len(s)) + s
+	return strings.Repeat(" ", length-width) + s
+}
+
+// Initials returns up to two uppercase initials for a person's name, e.g. "Jane Doe" -> "JD".
+func Initials(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '.' || r == '_' || r == '-'
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	first := []rune(words[0])[0]
+	if len(words) == 1 {
+		return strings.ToUpper(string(first))
+	}
+	last := []rune(words[len(words)-1])[0]
+	return strings.ToUpper(string([]rune{first, last}))
 }
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -203,6 +204,36 @@ func TestTruncateString(t *testing.T) {
 			maxLen: 1,
 			want:   "a",
 		},
+		{
+			name:   "wide characters fit by display width",
+			input:  "世界",
+			maxLen: 4,
+			want:   "世界",
+		},
+		{
+			name:   "wide characters truncated by display width",
+			input:  "構築パイプライン",
+			maxLen: 9,
+			want:   "構築パ...",
+		},
+		{
+			name:   "emoji counts as two cells",
+			input:  "🚀 deploy",
+			maxLen: 6,
+			want:   "🚀 ...",
+		},
+		{
+			name:   "styled string keeps escapes out of the width",
+			input:  "\x1b[31mhello\x1b[0m",
+			maxLen: 5,
+			want:   "\x1b[31mhello\x1b[0m",
+		},
+		{
+			name:   "styled string truncated",
+			input:  "\x1b[31mhello world\x1b[0m",
+			maxLen: 8,
+			want:   "\x1b[31mhello...\x1b[0m",
+		},
 	}
 
 	for _, tt := range tests {
@@ -214,3 +245,115 @@ func TestTruncateString(t *testing.T) {
 		})
 	}
 }
+
+func TestInitials(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "first and last name", input: "Jane Doe", want: "JD"},
+		{name: "middle name ignored", input: "Jane Q. Public", want: "JP"},
+		{name: "single word", input: "jdoe", want: "J"},
+		{name: "dotted id", input: "jane.doe", want: "JD"},
+		{name: "lowercase words", input: "build bot", want: "BB"},
+		{name: "unicode name", input: "Élodie Łukasz", want: "ÉŁ"},
+		{name: "empty", input: "", want: ""},
+		{name: "whitespace only", input: "   ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Initials(tt.input)
+			if got != tt.want {
+				t.Errorf("Initials(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatRelativeTime(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name string
+		t    time.Time
+		opts RelativeTimeOptions
+		want string
+	}{
+		{name: "zero", t: time.Time{}, opts: DefaultRelativeTimeOptions, want: "never"},
+		{name: "seconds ago", t: now.Add(-30 * time.Second), opts: DefaultRelativeTimeOptions, want: "just now"},
+		{name: "one minute ago", t: now.Add(-time.Minute), opts: DefaultRelativeTimeOptions, want: "1 minute ago"},
+		{name: "hours ago", t: now.Add(-5 * time.Hour), opts: DefaultRelativeTimeOptions, want: "5 hours ago"},
+		{name: "years ago", t: now.AddDate(-2, 0, -1), opts: DefaultRelativeTimeOptions, want: "2 years ago"},
+		{name: "seconds ahead", t: now.Add(20 * time.Second), opts: DefaultRelativeTimeOptions, want: "just now"},
+		{name: "minutes ahead", t: now.Add(2*time.Minute + 10*time.Second), opts: DefaultRelativeTimeOptions, want: "in 2 minutes"},
+		{
+			name: "beyond the future tolerance",
+			t:    now.Add(3 * time.Hour),
+			opts: DefaultRelativeTimeOptions,
+			want: "2024-03-15 15:00",
+		},
+		{
+			name: "older than absolute after",
+			t:    now.Add(-26 * time.Hour),
+			opts: RelativeTimeOptions{AbsoluteAfter: 24 * time.Hour, DateLayout: "02.01.2006 15:04"},
+			want: "14.03.2024 10:00",
+		},
+		{
+			name: "younger than absolute after",
+			t:    now.Add(-90 * time.Minute),
+			opts: RelativeTimeOptions{AbsoluteAfter: 24 * time.Hour, DateLayout: DefaultDateLayout},
+			want: "1 hour ago",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			if opts.DateLayout == "" {
+				opts.DateLayout = DefaultDateLayout
+			}
+			if got := formatRelativeTime(tt.t, now, opts); got != tt.want {
+				t.Errorf("formatRelativeTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeTimeLanguages(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		language string
+		t        time.Time
+		want     string
+	}{
+		{language: "de", t: now.Add(-3 * 24 * time.Hour), want: "vor 3 Tagen"},
+		{language: "de", t: now.Add(-time.Hour), want: "vor 1 Stunde"},
+		{language: "es", t: now.Add(5 * time.Minute), want: "dentro de 5 minutos"},
+		{language: "fr", t: now.AddDate(0, -2, 0), want: "il y a 2 mois"},
+		{language: "fr", t: time.Time{}, want: "jamais"},
+	}
+	for _, tt := range tests {
+		opts := DefaultRelativeTimeOptions
+		opts.DateLayout, opts.Language = DefaultDateLayout, tt.language
+		if got := formatRelativeTime(tt.t, now, opts); got != tt.want {
+			t.Errorf("formatRelativeTime() in %s = %q, want %q", tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestSetRelativeTimeOptionsRejectsBadSettings(t *testing.T) {
+	t.Cleanup(func() { SetRelativeTimeOptions(DefaultRelativeTimeOptions) })
+
+	if err := SetRelativeTimeOptions(RelativeTimeOptions{DateLayout: "02.01.2006", Language: "DE"}); err != nil {
+		t.Errorf("SetRelativeTimeOptions(valid) = %v", err)
+	}
+	err := SetRelativeTimeOptions(RelativeTimeOptions{AbsoluteAfter: time.Hour, DateLayout: "DD.MM.YYYY", Language: "xx"})
+	if err == nil || !strings.Contains(err.Error(), "DD.MM.YYYY") || !strings.Contains(err.Error(), `"xx"`) {
+		t.Errorf("SetRelativeTimeOptions(bad) = %v, want both settings reported", err)
+	}
+	opts := *relativeTimeOptions.Load()
+	if opts.DateLayout != DefaultDateLayout || opts.Language != "en" || opts.AbsoluteAfter != time.Hour {
+		t.Errorf("options = %+v, want the defaults in place of the bad settings and the rest kept", opts)
+	}
+}
@@ -0,0 +1,225 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	htmlTagPattern  = regexp.MustCompile(`<!--[\s\S]*?-->|<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	htmlHrefPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// htmlTags are the elements HTMLToText strips: those of the "Safe HTML"
+// markup formatter, plus <script> and <style>, whose content is dropped.
+// Anything else in angle brackets, like the "<branch>" of "origin/<branch>",
+// is text.
+var htmlTags = map[string]bool{
+	"a": true, "abbr": true, "b": true, "big": true, "blockquote": true, "br": true,
+	"caption": true, "center": true, "cite": true, "code": true, "col": true, "colgroup": true,
+	"dd": true, "del": true, "dfn": true, "div": true, "dl": true, "dt": true, "em": true,
+	"font": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"hr": true, "i": true, "img": true, "ins": true, "kbd": true, "li": true, "ol": true,
+	"p": true, "pre": true, "q": true, "s": true, "samp": true, "small": true, "span": true,
+	"strike": true, "strong": true, "sub": true, "sup": true, "table": true, "tbody": true,
+	"td": true, "tfoot": true, "th": true, "thead": true, "tr": true, "tt": true, "u": true,
+	"ul": true, "var": true, "script": true, "style": true,
+}
+
+// HTMLToText renders the HTML Jenkins allows in descriptions (its "Safe HTML"
+// markup formatter, Active Choices help and the like) as plain terminal text:
+// paragraphs and line breaks become newlines, list items get bullets or
+// numbers, links keep their target after the text, and entities are decoded.
+// Text without tags keeps its line breaks and spacing.
+func HTMLToText(s string) string {
+	var t htmlText
+	tagged := false
+	last := 0
+	for _, match := range htmlTagPattern.FindAllStringSubmatchIndex(s, -1) {
+		name := ""
+		if match[4] >= 0 {
+			name = strings.ToLower(s[match[4]:match[5]])
+			if !htmlTags[name] {
+				continue
+			}
+		}
+		tagged = true
+		t.text(s[last:match[0]])
+		last = match[1]
+		if name == "" {
+			continue // comment
+		}
+		closing := match[3] > match[2]
+		t.tag(name, s[match[6]:match[7]], closing)
+	}
+	if !tagged {
+		return html.UnescapeString(s)
+	}
+	t.text(s[last:])
+
+	lines := strings.Split(t.b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// htmlList is an open <ul> or <ol>.
+type htmlList struct {
+	ordered bool
+	items   int
+}
+
+// htmlLink is an open <a>, with where its text starts in the output.
+type htmlLink struct {
+	href  string
+	start int
+}
+
+// htmlText collects the text of an HTML fragment, collapsing whitespace as a
+// browser would outside <pre>.
+type htmlText struct {
+	b strings.Builder
+	// newlines and space are the breaks to write before the next word.
+	newlines int
+	space    bool
+	lists    []htmlList
+	links    []htmlLink
+	// skip counts open <script> and <style> elements, pre open <pre>s.
+	skip int
+	pre  int
+}
+
+func (t *htmlText) text(s string) {
+	if t.skip > 0 || s == "" {
+		return
+	}
+	s = html.UnescapeString(s)
+	if t.pre > 0 {
+		t.write(s)
+		return
+	}
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		t.space = true
+		return
+	}
+	if s[0] == ' ' || s[0] == '\t' || s[0] == '\n' || s[0] == '\r' {
+		t.space = true
+	}
+	for i, word := range words {
+		if i > 0 {
+			t.space = true
+		}
+		t.write(word)
+	}
+	if last := s[len(s)-1]; last == ' ' || last == '\t' || last == '\n' || last == '\r' {
+		t.space = true
+	}
+}
+
+// write appends s after the pending breaks; breaks before the first word
+// are dropped.
+func (t *htmlText) write(s string) {
+	if t.b.Len() > 0 {
+		if t.newlines > 0 {
+			t.b.WriteString(strings.Repeat("\n", t.newlines))
+		} else if t.space {
+			t.b.WriteByte(' ')
+		}
+	}
+	t.newlines = 0
+	t.space = false
+	t.b.WriteString(s)
+}
+
+// block ends the current line, leaving blank lines-1 lines before the next
+// text.
+func (t *htmlText) block(lines int) {
+	t.newlines = max(t.newlines, lines)
+}
+
+func (t *htmlText) tag(name, attrs string, closing bool) {
+	switch name {
+	case "script", "style":
+		if closing {
+			t.skip = max(t.skip-1, 0)
+		} else {
+			t.skip++
+		}
+	case "br":
+		t.newlines++
+	case "p", "h1", "h2", "h3", "h4", "h5", "h6", "blockquote":
+		t.block(2)
+	case "pre":
+		t.block(2)
+		if closing {
+			t.pre = max(t.pre-1, 0)
+		} else {
+			t.pre++
+		}
+	case "div", "tr", "table", "hr", "dl", "dt", "dd":
+		t.block(1)
+	case "td", "th":
+		t.space = true
+	case "ul", "ol":
+		t.block(1)
+		if closing {
+			if len(t.lists) > 0 {
+				t.lists = t.lists[:len(t.lists)-1]
+			}
+		} else {
+			t.lists = append(t.lists, htmlList{ordered: name == "ol"})
+		}
+	case "li":
+		t.block(1)
+		if closing || len(t.lists) == 0 {
+			return
+		}
+		list := &t.lists[len(t.lists)-1]
+		list.items++
+		bullet := "•"
+		if list.ordered {
+			bullet = strconv.Itoa(list.items) + "."
+		}
+		t.write(strings.Repeat("  ", len(t.lists)-1) + bullet)
+		t.space = true
+	case "a":
+		if !closing {
+			t.links = append(t.links, htmlLink{href: linkTarget(attrs), start: t.b.Len()})
+			return
+		}
+		if len(t.links) == 0 {
+			return
+		}
+		link := t.links[len(t.links)-1]
+		t.links = t.links[:len(t.links)-1]
+		if link.href == "" {
+			return
+		}
+		text := strings.TrimSpace(t.b.String()[link.start:])
+		switch {
+		case text == "":
+			t.write(link.href)
+		case text != link.href:
+			t.space = true
+			t.write("(" + link.href + ")")
+		}
+	}
+}
+
+// linkTarget is the href of an <a>, unless it only points within the page
+// or runs script.
+func linkTarget(attrs string) string {
+	match := htmlHrefPattern.FindStringSubmatch(attrs)
+	if match == nil {
+		return ""
+	}
+	href := html.UnescapeString(match[1] + match[2] + match[3])
+	if strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return ""
+	}
+	return href
+}
@@ -0,0 +1,32 @@
+package utils
+
+import "testing"
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain text kept", in: "Branch to build\n(default: main)", want: "Branch to build\n(default: main)"},
+		{name: "paragraphs and breaks", in: "<p>Target  environment.</p><p>Use <b>prod</b> with care<br/>only on weekdays</p>",
+			want: "Target environment.\n\nUse prod with care\nonly on weekdays"},
+		{name: "lists", in: "Pick one:<ul><li>dev</li><li>staging<ol><li>eu</li><li>us</li></ol></li></ul>done",
+			want: "Pick one:\n• dev\n• staging\n  1. eu\n  2. us\ndone"},
+		{name: "links", in: `See <a href="https://wiki/deploy?a=1&amp;b=2">the runbook</a> or <a href="https://x.io">https://x.io</a>.`,
+			want: "See the runbook (https://wiki/deploy?a=1&b=2) or https://x.io."},
+		{name: "entities, comments and scripts", in: "<!-- help --><script>alert(1)</script>a &lt; b &amp;&amp; c&nbsp;d",
+			want: "a < b && c d"},
+		{name: "entities without tags", in: "Tom &amp; Jerry\n&quot;quoted&quot;", want: "Tom & Jerry\n\"quoted\""},
+		{name: "unknown tags are text", in: "Push to origin/<branch> for <b>release</b> <version>",
+			want: "Push to origin/<branch> for release <version>"},
+		{name: "placeholders only", in: "Use <env>-<region>, e.g. eu-west", want: "Use <env>-<region>, e.g. eu-west"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTMLToText(tt.in); got != tt.want {
+				t.Errorf("HTMLToText(%q) =\n%q\nwant\n%q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -6,11 +6,48 @@
 
 const esc = 0x1b
 
+// LogSanitizer runs StripANSISecrets over a log streamed in chunks. It holds
+// back the unfinished last line of each chunk until the rest of it arrives,
+// so a secret split across two chunks is still redacted. The zero value is
+// ready to use.
+type LogSanitizer struct {
+	concealActive bool
+	// pending is the stripped but not yet redacted unfinished line.
+	pending string
+}
+
+// Write sanitizes the next chunk of the log and returns the lines it
+// completes, each ending in a newline.
+func (s *LogSanitizer) Write(chunk string) string {
+	text, conceal := stripANSI(chunk, s.concealActive)
+	s.concealActive = conceal
+	text = s.pending + text
+	end := strings.LastIndexByte(text, '\n') + 1
+	s.pending = text[end:]
+	return RedactSecrets(text[:end])
+}
+
+// Flush returns the unfinished last line, for when the log has ended or
+// the next chunk can't continue it.
+func (s *LogSanitizer) Flush() string {
+	text := s.pending
+	s.pending = ""
+	return RedactSecrets(text)
+}
+
 // StripANSISecrets removes ANSI escape sequences from Jenkins log output while keeping
 // regular text, and it strips concealed segments emitted by the Jenkins credentials
-// masking plugin. It returns the cleaned string and whether a conceal sequence
-// is still active (spanning across chunks).
+// masking plugin. Anything matching the redaction rules (see SetRedactionPatterns)
+// is masked as well. It returns the cleaned string and whether a conceal sequence
+// is still active (spanning across chunks). Streamed logs should go through a
+// LogSanitizer instead, which also catches secrets split between chunks.
 func StripANSISecrets(input string, concealActive bool) (string, bool) {
+	text, conceal := stripANSI(input, concealActive)
+	return RedactSecrets(text), conceal
+}
+
+// stripANSI is StripANSISecrets without the redaction rules.
+func stripANSI(input string, concealActive bool) (string, bool) {
 	if input == "" && !concealActive {
 		return input, false
 	}
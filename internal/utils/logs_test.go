@@ -24,6 +24,20 @@ func TestStripANSISecrets(t *testing.T) {
 			want:          "line1line2",
 			wantActive:    false,
 		},
+		{
+			name:          "windows line endings",
+			input:         "Building on agent win-01\r\nDone\r\n",
+			initialActive: false,
+			want:          "Building on agent win-01\nDone\n",
+			wantActive:    false,
+		},
+		{
+			name:          "line ending split across chunks",
+			input:         "\nnext line\r",
+			initialActive: false,
+			want:          "\nnext line",
+			wantActive:    false,
+		},
 		{
 			name:          "color sequence stripped",
 			input:         "\x1b[31mhello\x1b[0m",
@@ -81,3 +95,18 @@ func TestStripANSISecrets(t *testing.T) {
 	}
 }
 
+func TestLogSanitizerRedactsSecretsSplitAcrossChunks(t *testing.T) {
+	var s LogSanitizer
+	if got := s.Write("Started\n\x1b[32mauth: Bearer abc"); got != "Started\n" {
+		t.Errorf("first chunk = %q, want only the finished line", got)
+	}
+	if got := s.Write("defghij123\x1b[0m\nnext"); got != "auth: Bearer ****\n" {
+		t.Errorf("second chunk = %q, want the token masked", got)
+	}
+	if got := s.Flush(); got != "next" {
+		t.Errorf("Flush() = %q, want the unfinished line", got)
+	}
+	if got := s.Flush(); got != "" {
+		t.Errorf("second Flush() = %q, want nothing", got)
+	}
+}
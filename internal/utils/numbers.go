@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// NumberLocale describes how numbers are grouped and how decimals are written.
+type NumberLocale struct {
+	Thousands string
+	Decimal   string
+}
+
+var (
+	localeEnglish = NumberLocale{Thousands: ",", Decimal: "."}
+	localeGerman  = NumberLocale{Thousands: ".", Decimal: ","}
+	localeFrench  = NumberLocale{Thousands: " ", Decimal: ","}
+	localeSwiss   = NumberLocale{Thousands: "'", Decimal: "."}
+)
+
+// numberLocalesByLanguage maps language (or language_REGION) codes to separators.
+var numberLocalesByLanguage = map[string]NumberLocale{
+	"en": localeEnglish, "ja": localeEnglish, "zh": localeEnglish, "ko": localeEnglish, "he": localeEnglish,
+	"de": localeGerman, "nl": localeGerman, "it": localeGerman, "es": localeGerman, "pt": localeGerman,
+	"da": localeGerman, "id": localeGerman, "tr": localeGerman, "el": localeGerman,
+	"fr": localeFrench, "ru": localeFrench, "uk": localeFrench, "pl": localeFrench, "cs": localeFrench,
+	"sv": localeFrench, "fi": localeFrench, "nb": localeFrench, "no": localeFrench, "hu": localeFrench,
+	"de_CH": localeSwiss, "it_CH": localeSwiss,
+}
+
+var numberLocale atomic.Pointer[NumberLocale]
+
+func init() {
+	SetNumberLocale(detectLocaleTag())
+}
+
+// detectLocaleTag returns the POSIX locale governing number formatting.
+func detectLocaleTag() string {
+	for _, key := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// SetNumberLocale selects number separators from a locale tag such as
+// "de_DE.UTF-8" or "fr-FR". Unknown or empty tags fall back to English.
+func SetNumberLocale(tag string) {
+	locale := lookupNumberLocale(tag)
+	numberLocale.Store(&locale)
+}
+
+// CurrentNumberLocale returns the separators in effect.
+func CurrentNumberLocale() NumberLocale {
+	return *numberLocale.Load()
+}
+
+func lookupNumberLocale(tag string) NumberLocale {
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ReplaceAll(tag, "-", "_")
+
+	if locale, ok := numberLocalesByLanguage[tag]; ok {
+		return locale
+	}
+	if i := strings.Index(tag, "_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if locale, ok := numberLocalesByLanguage[strings.ToLower(tag)]; ok {
+		return locale
+	}
+	return localeEnglish
+}
+
+// FormatCount renders an integer with locale thousands separators, e.g. 12345 -> "12,345".
+func FormatCount(n int64) string {
+	return groupDigits(n, CurrentNumberLocale().Thousands)
+}
+
+// FormatBytes renders a byte size using binary units, e.g. 1536 -> "1.5 KB".
+// Values below 10 units keep one decimal; larger values are rounded to whole units.
+func FormatBytes(n int64) string {
+	if n < 0 {
+		// Negating math.MinInt64 overflows, so take the magnitude unsigned.
+		return "-" + formatBytes(uint64(-(n+1))+1)
+	}
+	return formatBytes(uint64(n))
+}
+
+func formatBytes(n uint64) string {
+	locale := CurrentNumberLocale()
+	if n < 1024 {
+		return groupDigits(int64(n), locale.Thousands) + " B"
+	}
+
+	units := []string{"KB", "MB", "GB", "TB", "PB"}
+	value := float64(n)
+	unit := ""
+	for _, u := range units {
+		value /= 1024
+		unit = u
+		// Move up a unit when rounding would print 1024 of this one.
+		if value < 1023.5 {
+			break
+		}
+	}
+
+	if value < 10 {
+		text := strconv.FormatFloat(value, 'f', 1, 64)
+		text = strings.TrimSuffix(text, ".0")
+		return strings.Replace(text, ".", locale.Decimal, 1) + " " + unit
+	}
+	return groupDigits(int64(value+0.5), locale.Thousands) + " " + unit
+}
+
+func groupDigits(n int64, separator string) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= 3 {
+		return sign + digits
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if i > 0 {
+			b.WriteString(separator)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func withNumberLocale(t *testing.T, tag string) {
+	t.Helper()
+	previous := CurrentNumberLocale()
+	SetNumberLocale(tag)
+	t.Cleanup(func() {
+		numberLocale.Store(&previous)
+	})
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		input  int64
+		want   string
+	}{
+		{name: "small number", locale: "en_US.UTF-8", input: 999, want: "999"},
+		{name: "thousands", locale: "en_US.UTF-8", input: 12345, want: "12,345"},
+		{name: "millions", locale: "en_US.UTF-8", input: 1234567, want: "1,234,567"},
+		{name: "negative", locale: "en_US.UTF-8", input: -1234, want: "-1,234"},
+		{name: "german", locale: "de_DE.UTF-8", input: 1234567, want: "1.234.567"},
+		{name: "french space", locale: "fr_FR", input: 20000, want: "20 000"},
+		{name: "swiss german", locale: "de-CH", input: 20000, want: "20'000"},
+		{name: "unknown locale falls back", locale: "xx_YY", input: 20000, want: "20,000"},
+		{name: "posix locale", locale: "C", input: 20000, want: "20,000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withNumberLocale(t, tt.locale)
+			if got := FormatCount(tt.input); got != tt.want {
+				t.Errorf("FormatCount(%d) with %s = %q, want %q", tt.input, tt.locale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		input  int64
+		want   string
+	}{
+		{name: "zero", locale: "en_US", input: 0, want: "0 B"},
+		{name: "bytes", locale: "en_US", input: 512, want: "512 B"},
+		{name: "exact kilobyte", locale: "en_US", input: 1024, want: "1 KB"},
+		{name: "fractional kilobytes", locale: "en_US", input: 1536, want: "1.5 KB"},
+		{name: "whole kilobytes above ten", locale: "en_US", input: 150 * 1024, want: "150 KB"},
+		{name: "megabytes", locale: "en_US", input: 5*1024*1024 + 300*1024, want: "5.3 MB"},
+		{name: "gigabytes", locale: "en_US", input: 3 * 1024 * 1024 * 1024, want: "3 GB"},
+		{name: "rounds up to the next unit", locale: "en_US", input: 1024*1024 - 1, want: "1 MB"},
+		{name: "just below the rounding boundary", locale: "en_US", input: 1023*1024 + 511, want: "1,023 KB"},
+		{name: "grouped large value", locale: "en_US", input: 2000 * 1024 * 1024 * 1024 * 1024, want: "2 PB"},
+		{name: "german decimal comma", locale: "de_DE", input: 1536, want: "1,5 KB"},
+		{name: "negative", locale: "en_US", input: -2048, want: "-2 KB"},
+		{name: "most negative", locale: "en_US", input: math.MinInt64, want: "-8,192 PB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withNumberLocale(t, tt.locale)
+			if got := FormatBytes(tt.input); got != tt.want {
+				t.Errorf("FormatBytes(%d) with %s = %q, want %q", tt.input, tt.locale, got, tt.want)
+			}
+		})
+	}
+}
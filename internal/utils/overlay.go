@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// sgrReset clears any styling so neither layer's colors bleed into the other.
+const sgrReset = "\x1b[0m"
+
+// Overlay composites overlay on top of base with its top-left corner at cell
+// (x, y). Base cells outside the overlay's footprint stay visible with their
+// original styling; widths are measured in terminal cells so wide characters
+// and ANSI escape sequences keep both layers aligned.
+func Overlay(base, overlay string, x, y int) string {
+	if overlay == "" {
+		return base
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+
+	baseLines := strings.Split(base, "\n")
+	overlayLines := strings.Split(overlay, "\n")
+	for len(baseLines) < y+len(overlayLines) {
+		baseLines = append(baseLines, "")
+	}
+
+	for i, overlayLine := range overlayLines {
+		baseLine := baseLines[y+i]
+		baseWidth := ansi.StringWidth(baseLine)
+		end := x + ansi.StringWidth(overlayLine)
+
+		var b strings.Builder
+		left := ansi.Truncate(baseLine, x, "")
+		b.WriteString(left)
+		if gap := x - ansi.StringWidth(left); gap > 0 {
+			b.WriteString(strings.Repeat(" ", gap))
+		}
+		b.WriteString(sgrReset)
+		b.WriteString(overlayLine)
+		b.WriteString(sgrReset)
+		if baseWidth > end {
+			b.WriteString(dropCells(baseLine, end))
+		}
+		baseLines[y+i] = b.String()
+	}
+	return strings.Join(baseLines, "\n")
+}
+
+// OverlayCenter composites overlay in the middle of a width x height base.
+func OverlayCenter(base, overlay string, width, height int) string {
+	lines := strings.Split(overlay, "\n")
+	overlayWidth := 0
+	for _, line := range lines {
+		if w := ansi.StringWidth(line); w > overlayWidth {
+			overlayWidth = w
+		}
+	}
+	return Overlay(base, overlay, (width-overlayWidth)/2, (height-len(lines))/2)
+}
+
+// dropCells removes the first n display cells of s. Escape sequences are kept so
+// styling still applies to the visible remainder, and a wide character split by
+// the cut is replaced with spaces to preserve the column of everything after it.
+func dropCells(s string, n int) string {
+	var b strings.Builder
+	var state byte
+	dropped := 0
+	for len(s) > 0 {
+		seq, width, size, newState := ansi.DecodeSequence(s, state, nil)
+		state = newState
+		s = s[size:]
+		switch {
+		case width == 0 || dropped >= n:
+			b.WriteString(seq)
+		case dropped+width > n:
+			b.WriteString(strings.Repeat(" ", dropped+width-n))
+		}
+		dropped += width
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package utils
+
+import "testing"
+
+func TestOverlay(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    string
+		overlay string
+		x, y    int
+		want    string
+	}{
+		{
+			name:    "empty overlay keeps base",
+			base:    "abc\ndef",
+			overlay: "",
+			want:    "abc\ndef",
+		},
+		{
+			name:    "overlay at origin",
+			base:    "abcdef\nghijkl",
+			overlay: "XY\nZ",
+			want:    "\x1b[0mXY\x1b[0mcdef\n\x1b[0mZ\x1b[0mhijkl",
+		},
+		{
+			name:    "overlay offset keeps base on both sides",
+			base:    "abcdef\nghijkl\nmnopqr",
+			overlay: "XY",
+			x:       2,
+			y:       1,
+			want:    "abcdef\ngh\x1b[0mXY\x1b[0mkl\nmnopqr",
+		},
+		{
+			name:    "overlay past the base is padded",
+			base:    "ab",
+			overlay: "X",
+			x:       4,
+			y:       1,
+			want:    "ab\n    \x1b[0mX\x1b[0m",
+		},
+		{
+			name:    "wide overlay characters use two cells",
+			base:    "abcdef",
+			overlay: "世",
+			x:       1,
+			want:    "a\x1b[0m世\x1b[0mdef",
+		},
+		{
+			name:    "wide base characters split on both edges",
+			base:    "世界ab",
+			overlay: "XY",
+			x:       1,
+			want:    " \x1b[0mXY\x1b[0m ab",
+		},
+		{
+			name:    "styled base keeps its style after the overlay",
+			base:    "\x1b[2mabcdef\x1b[0m",
+			overlay: "XY",
+			x:       1,
+			want:    "\x1b[2ma\x1b[0m\x1b[0mXY\x1b[0m\x1b[2mdef\x1b[0m",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Overlay(tt.base, tt.overlay, tt.x, tt.y)
+			if got != tt.want {
+				t.Errorf("Overlay(%q, %q, %d, %d) = %q, want %q", tt.base, tt.overlay, tt.x, tt.y, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverlayCenter(t *testing.T) {
+	base := "......\n......\n......"
+	got := OverlayCenter(base, "XX", 6, 3)
+	want := "......\n..\x1b[0mXX\x1b[0m..\n......"
+	if got != want {
+		t.Errorf("OverlayCenter() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sync/atomic"
+)
+
+// RedactionMask replaces secrets found in log output.
+const RedactionMask = "****"
+
+// DefaultRedactionPatterns catch common credentials in pipelines that do not
+// use the credentials masking plugin. When a pattern has a group named
+// "secret", only that group is masked so the surrounding key stays readable.
+var DefaultRedactionPatterns = []string{
+	// AWS access key IDs
+	`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16}\b`,
+	// AWS secret access keys assigned to a well-known variable name
+	`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?(?P<secret>[A-Za-z0-9/+=]{40,})`,
+	// HTTP bearer tokens
+	`(?i)\bbearer\s+(?P<secret>[A-Za-z0-9\-._~+/]{8,}=*)`,
+	// GitHub tokens
+	`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+	// Slack tokens
+	`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`,
+}
+
+type redactionRule struct {
+	re     *regexp.Regexp
+	secret int // index of the "secret" group, or 0 to mask the whole match
+}
+
+var redactionRules atomic.Pointer[[]redactionRule]
+
+func init() {
+	rules, err := compileRedactionRules(DefaultRedactionPatterns)
+	if err != nil {
+		panic(err)
+	}
+	redactionRules.Store(&rules)
+}
+
+// SetRedactionPatterns replaces the active redaction rules. Invalid patterns
+// leave the current rules untouched.
+func SetRedactionPatterns(patterns []string) error {
+	rules, err := compileRedactionRules(patterns)
+	if err != nil {
+		return err
+	}
+	redactionRules.Store(&rules)
+	return nil
+}
+
+func compileRedactionRules(patterns []string) ([]redactionRule, error) {
+	rules := make([]redactionRule, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		rules = append(rules, redactionRule{re: re, secret: re.SubexpIndex("secret")})
+	}
+	return rules, nil
+}
+
+// RedactSecrets masks every match of the active redaction rules in text.
+func RedactSecrets(text string) string {
+	for _, rule := range *redactionRules.Load() {
+		text = rule.apply(text)
+	}
+	return text
+}
+
+func (r redactionRule) apply(text string) string {
+	if r.secret <= 0 {
+		return r.re.ReplaceAllLiteralString(text, RedactionMask)
+	}
+
+	matches := r.re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var out []byte
+	last := 0
+	for _, m := range matches {
+		start, end := m[2*r.secret], m[2*r.secret+1]
+		if start < 0 {
+			continue
+		}
+		out = append(out, text[last:start]...)
+		out = append(out, RedactionMask...)
+		last = end
+	}
+	out = append(out, text[last:]...)
+	return string(out)
+}
@@ -1,12 +1,23 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/gorbach/jdash/internal/app"
 	"github.com/gorbach/jdash/internal/auth"
+	"github.com/gorbach/jdash/internal/cli"
+	"github.com/gorbach/jdash/internal/deeplink"
+	"github.com/gorbach/jdash/internal/logtransform"
+	"github.com/gorbach/jdash/internal/profiling"
+	"github.com/gorbach/jdash/internal/release"
+	"github.com/gorbach/jdash/internal/termstatus"
+	"github.com/gorbach/jdash/internal/ui"
+	"github.com/gorbach/jdash/internal/utils"
 )
 
 // Version information set by goreleaser at build time
@@ -25,6 +36,48 @@ func main() {
 		return
 	}
 
+	// Headless subcommands (build, jobs, completion, ...) run without the TUI
+	if handled, code := cli.Run(os.Args[1:]); handled {
+		os.Exit(code)
+	}
+
+	// Remaining arguments are TUI flags and an optional deep link into the dashboard
+	flags := flag.NewFlagSet("jdash", flag.ContinueOnError)
+	paneMode := flags.Bool("pane-mode", false, "minimal chrome for small tmux panes: one panel at a time, no borders")
+	compat := flags.Bool("compat", false, "ASCII icons for terminals whose font lacks symbols and emoji (auto-detected on the Windows console host)")
+	theme := flags.String("theme", "", "color theme for this run: "+strings.Join(ui.Themes(), ", "))
+	profile := flags.String("profile", "", "record a cpu, mem or trace profile of the session into the current directory and show frame times")
+	launch, err := deeplink.Parse(flags, os.Args[1:])
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+		return
+	case errors.Is(err, deeplink.ErrUsage):
+		os.Exit(2)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	var profileMode profiling.Mode
+	if *profile != "" {
+		if profileMode, err = profiling.ParseMode(*profile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	// UI preferences fall back to defaults when the config cannot be read
+	config, _ := auth.LoadConfig()
+	if *compat || config.UI.Compat(ui.DetectCompatMode()) {
+		ui.UseFallbackIcons()
+	}
+	themeName := config.UI.Theme
+	if *theme != "" {
+		themeName = *theme
+	}
+	if err := ui.UseTheme(themeName); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using the default theme\n", err)
+	}
+
 	// Check if we already have server config
 	hasConfig := auth.HasServerConfig()
 
@@ -60,10 +113,80 @@ func main() {
 	// Create Jenkins client
 	client := auth.CreateJenkinsClient(serverConfig)
 
+	// Reload: the auth screen may have just written the config
+	config, _ = auth.LoadConfig()
+	if err := utils.SetRedactionPatterns(config.Logs.RedactionPatterns()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using default log redaction\n", err)
+	}
+	if err := utils.SetRelativeTimeOptions(config.UI.RelativeTime()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	logTransforms, err := logtransform.Compile(config.Logs.Transforms)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; showing console logs as is\n", err)
+	}
+	confirmPolicy, err := config.Confirm.Policy()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; using default confirmations\n", err)
+	}
+	panels, err := serverConfig.EnabledPanels()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
 	// Launch main application
-	appModel := app.New(serverConfig.URL, client)
+	appModel := app.New(serverConfig.URL, client, app.Options{
+		IdleAfter:          config.UI.IdleAfter(),
+		SelectionDebounce:  config.UI.SelectionDebounce(),
+		QueuePollInterval:  serverConfig.PollInterval(config.UI),
+		Launch:             launch,
+		PaneMode:           *paneMode || config.UI.PaneMode,
+		HideQueue:          !panels.Queue,
+		HideDetails:        !panels.Details,
+		ReadOnly:           serverConfig.ReadOnly,
+		TerminalTitle:      !config.UI.DisableTerminalTitle,
+		TmuxStatus:         config.UI.TmuxStatus,
+		DefaultAction:      config.UI.DefaultAction,
+		ConfirmPolicy:      confirmPolicy,
+		ExclusiveExpand:    config.UI.ExclusiveExpand,
+		PromotionParameter: config.UI.PromotionParameter,
+		Version:            release.Info{Version: version, Commit: commit, Date: date},
+		CheckForUpdates:    config.UI.CheckForUpdates,
+		LogTransforms:      logTransforms,
+		RefreshKey:         config.Keybindings.Refresh,
+		RefreshAllKey:      config.Keybindings.RefreshAll,
+		User:               serverConfig.Username,
+		FrameTimes:         profileMode != "",
+	})
+
+	// Profile the dashboard alone, not the login screen before it.
+	var session *profiling.Session
+	if profileMode != "" {
+		if session, err = profiling.Start(profileMode, "."); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	p := tea.NewProgram(appModel, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if session != nil {
+		if stopErr := session.Stop(); stopErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", stopErr)
+		} else {
+			fmt.Fprintf(os.Stderr, "Wrote %s profile to %s\n", profileMode, session.Path)
+		}
+	}
+	if finalModel, ok := final.(app.Model); ok {
+		// Say what became of builds triggered or aborted right before quitting.
+		for _, line := range finalModel.ExitSummary() {
+			fmt.Fprintln(os.Stderr, line)
+		}
+	}
+	if config.UI.TmuxStatus {
+		// Don't leave a stale summary in the status line after quitting.
+		_ = termstatus.PublishTmux("")
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}